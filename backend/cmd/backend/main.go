@@ -2,33 +2,95 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"google.golang.org/api/option"
+
 	app "github.com/matti777/my-countries/backend"
+	"github.com/matti777/my-countries/backend/internal/accountpurge"
 	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/backfill"
+	"github.com/matti777/my-countries/backend/internal/backup"
+	"github.com/matti777/my-countries/backend/internal/cache"
 	"github.com/matti777/my-countries/backend/internal/config"
 	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/database/indexes"
+	"github.com/matti777/my-countries/backend/internal/database/memory"
+	"github.com/matti777/my-countries/backend/internal/database/sqldb"
+	"github.com/matti777/my-countries/backend/internal/faults"
+	"github.com/matti777/my-countries/backend/internal/imageproxy"
+	"github.com/matti777/my-countries/backend/internal/insights"
 	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/mailer"
+	"github.com/matti777/my-countries/backend/internal/metrics"
+	"github.com/matti777/my-countries/backend/internal/overrides"
+	"github.com/matti777/my-countries/backend/internal/proofs"
+	"github.com/matti777/my-countries/backend/internal/residency"
 	"github.com/matti777/my-countries/backend/internal/server"
 	"github.com/matti777/my-countries/backend/internal/tracing"
+	"github.com/matti777/my-countries/backend/internal/ttl"
+	"github.com/matti777/my-countries/backend/internal/turnstile"
+	"github.com/matti777/my-countries/backend/internal/writequeue"
 )
 
+// imageMemoryCacheBytes bounds the per-instance cache of resized images.
+const imageMemoryCacheBytes = 32 << 20
+
+// ogImageMemoryCacheBytes bounds the per-instance cache of share preview images.
+const ogImageMemoryCacheBytes = 16 << 20
+
+// demoProjectID is the project ID of --demo mode when none is set; Firebase reserves the demo-
+// prefix for projects that only exist in its emulators.
+const demoProjectID = "demo-my-countries"
+
+// store is the database of the server and the background workers: the Firestore client, or an
+// in-memory database in --demo mode.
+type store interface {
+	server.Database
+	backfill.Store
+	overrides.Store
+	accountpurge.Store
+	ttl.Store
+	RebuildUserVisitStats(ctx context.Context, userID string) error
+	RebuildFollowers(ctx context.Context, userID string) error
+	MigrateUserSchema(ctx context.Context, userID string) error
+}
+
 func main() {
+	demo := flag.Bool("demo", false,
+		"run with an in-memory database and Auth emulator tokens, without GCP services")
+	flag.Parse()
 	ctx := context.Background()
 
+	if *demo && os.Getenv("GOOGLE_CLOUD_PROJECT") == "" && os.Getenv("GCP_PROJECT_ID") == "" {
+		os.Setenv("GOOGLE_CLOUD_PROJECT", demoProjectID)
+	}
+
 	// Load configuration first (needed for project ID)
 	cfg, err := config.Load(ctx)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if *demo {
+		// No GCS buckets, session cookies (Identity Toolkit) or location checks without GCP
+		cfg.ImageCacheBucket, cfg.ProofBucket, cfg.Backup.Bucket = "", "", ""
+		cfg.SessionTTL = 0
+		cfg.DataResidency = ""
+	}
 
-	// Initialize Cloud Trace client
-	traceClient, err := tracing.NewClient(ctx, cfg.ProjectID, cfg.IsDebug)
-	if err != nil {
+	// Initialize Cloud Trace client. Trace storage cannot be pinned to a region, so a data
+	// residency mode disables it.
+	var traceClient *tracing.Client
+	if *demo {
+		log.Printf("Demo mode: Cloud Trace disabled.")
+	} else if cfg.DataResidency.Restricted() {
+		log.Printf("Data residency %s: Cloud Trace disabled.", cfg.DataResidency)
+	} else if traceClient, err = tracing.NewClient(ctx, cfg.ProjectID, cfg.IsDebug); err != nil {
 		log.Printf("Warning: Failed to initialize trace client: %v. Continuing without tracing.", err)
 		traceClient = nil
 	} else {
@@ -55,37 +117,267 @@ func main() {
 	slog := logging.FromContext(ctx)
 	slog.Info("Starting application initialization")
 
-	// Initialize Firestore client with trace span
-	var dbClient *database.Client
-	err = tracing.SafeSpan(ctx, nil, "database.NewClient", func(spanCtx context.Context) error {
-		var err error
-		dbClient, err = database.NewClient(spanCtx, cfg.ProjectID)
-		return err
-	})
-	if err != nil {
-		slog.Error("Failed to initialize Firestore client", logging.Error, err)
-		log.Fatalf("Failed to initialize Firestore client: %v", err)
+	// Fault injection for resilience testing; config.Load allows it only outside production
+	var faultInjector *faults.Injector
+	if cfg.Faults.Enabled() {
+		faultInjector = faults.New(cfg.Faults)
+		slog.Warn("Fault injection enabled",
+			"latency", cfg.Faults.Latency.String(),
+			"latency_rate", cfg.Faults.LatencyRate,
+			"firestore_error_rate", cfg.Faults.FirestoreErrorRate)
 	}
-	defer dbClient.Close()
 
-	slog.Info("Firestore client initialized successfully")
+	if cfg.ReadOnly {
+		slog.Warn("Read-only maintenance mode: mutating requests outside /admin are refused")
+	}
 
-	// Firebase ID token verification (JWKS cache 1h). Use FIREBASE_PROJECT_ID or FIREBASE_AUDIENCE when backend GCP project differs from frontend Firebase project.
-	authenticator, err := auth.NewAuthenticator(cfg.ProjectID, cfg.FirebaseProjectID)
-	if err != nil {
-		slog.Error("Failed to create authenticator", logging.Error, err)
-		log.Fatalf("Failed to create authenticator: %v", err)
+	// Prometheus metrics, served on their own listener (below) so they are not public
+	var appMetrics *metrics.Metrics
+	if cfg.MetricsPort != "" {
+		appMetrics = metrics.New()
+	}
+
+	// Initialize the database: Firestore (client init in a trace span) unless DB_DRIVER selects
+	// PostgreSQL or SQLite; demo data lives in memory until exit
+	var db store
+	var visitWatcher server.VisitWatcher // snapshot listeners exist in Firestore only
+	switch {
+	case *demo:
+		db = memory.New()
+		slog.Warn("Demo mode: using an in-memory database; all data is lost on exit")
+	case cfg.DBDriver == config.DBDriverPostgres || cfg.DBDriver == config.DBDriverSQLite:
+		var sqlDB *sqldb.DB
+		if cfg.DBDriver == config.DBDriverPostgres {
+			sqlDB, err = sqldb.OpenPostgres(ctx, cfg.DatabaseURL)
+		} else {
+			sqlDB, err = sqldb.OpenSQLite(ctx, cfg.SQLitePath)
+		}
+		if err != nil {
+			slog.Error("Failed to initialize SQL database",
+				"driver", cfg.DBDriver, logging.Error, err)
+			log.Fatalf("Failed to initialize %s database: %v", cfg.DBDriver, err)
+		}
+		defer sqlDB.Close()
+		db = sqlDB
+
+		slog.Info("SQL database initialized successfully", "driver", cfg.DBDriver)
+	default:
+		var dbClient *database.Client
+		err = tracing.SafeSpan(ctx, nil, "database.NewClient", func(spanCtx context.Context) error {
+			var err error
+			var opts []option.ClientOption
+			if faultInjector != nil {
+				opts = faultInjector.ClientOptions()
+			}
+			if appMetrics != nil {
+				opts = append(opts, appMetrics.ClientOptions()...)
+			}
+			dbClient, err = database.NewClient(spanCtx, cfg.ProjectID,
+				cfg.FirestoreEmulatorHost, opts...)
+			return err
+		})
+		if err != nil {
+			slog.Error("Failed to initialize Firestore client", logging.Error, err)
+			log.Fatalf("Failed to initialize Firestore client: %v", err)
+		}
+		defer dbClient.Close()
+		// Reads of the server and the background workers alike get deadlines and retries
+		db = database.NewRetryingClient(dbClient, cfg.DBTimeout, cfg.DBReadRetries)
+		visitWatcher = dbClient
+
+		slog.Info("Firestore client initialized successfully")
 	}
+
+	// Data residency: refuse to start with data stored outside the allowed region
+	if err := residency.Check(ctx, cfg.DataResidency, cfg.ProjectID,
+		cfg.ImageCacheBucket, cfg.ProofBucket, cfg.Backup.Bucket); err != nil {
+		slog.Error("Data residency check failed", logging.Error, err)
+		log.Fatalf("Data residency check failed: %v", err)
+	}
+
+	// ID token verification (JWKS cache 1h): Firebase by default, or a generic OIDC provider
+	var verifier auth.TokenVerifier
 	effectiveFirebaseProject := cfg.ProjectID
 	if cfg.FirebaseProjectID != "" {
 		effectiveFirebaseProject = cfg.FirebaseProjectID
 	}
-	slog.Info("Firebase token verification configured", "firebase_project_id", effectiveFirebaseProject)
+	switch {
+	case cfg.AuthEmulatorHost != "" || *demo:
+		verifier, err = auth.NewEmulatorVerifier(effectiveFirebaseProject)
+		if err != nil {
+			slog.Error("Failed to create emulator verifier", logging.Error, err)
+			log.Fatalf("Failed to create emulator verifier: %v", err)
+		}
+		slog.Warn("Trusting unsigned Firebase Auth emulator tokens",
+			"emulator_host", cfg.AuthEmulatorHost, "firebase_project_id", effectiveFirebaseProject)
+	case cfg.AuthProvider == config.AuthProviderOIDC:
+		verifier, err = auth.NewOIDCVerifier(ctx, cfg.OIDC)
+		if err != nil {
+			slog.Error("Failed to create OIDC verifier", logging.Error, err)
+			log.Fatalf("Failed to create OIDC verifier: %v", err)
+		}
+		slog.Info("OIDC token verification configured", "issuer", cfg.OIDC.Issuer)
+	default:
+		// FIREBASE_PROJECT_ID or FIREBASE_AUDIENCE name the frontend's Firebase project when it
+		// differs from the backend's GCP project
+		verifier, err = auth.NewAuthenticator(cfg.ProjectID, cfg.FirebaseProjectID)
+		if err != nil {
+			slog.Error("Failed to create authenticator", logging.Error, err)
+			log.Fatalf("Failed to create authenticator: %v", err)
+		}
+		slog.Info("Firebase token verification configured",
+			"firebase_project_id", effectiveFirebaseProject)
+	}
+
+	// Session cookies (POST /session) when enabled
+	var sessions auth.SessionCookies
+	if cfg.SessionTTL > 0 {
+		if sessions, err = auth.NewFirebaseSessions(ctx, effectiveFirebaseProject); err != nil {
+			slog.Error("Failed to create session cookie support", logging.Error, err)
+			log.Fatalf("Failed to create session cookie support: %v", err)
+		}
+		slog.Info("Session cookies enabled", "ttl", cfg.SessionTTL)
+	}
+
+	// Image proxy for GET /img: per-instance memory cache, backed by a GCS bucket when configured
+	var imageCache imageproxy.Cache = imageproxy.NewMemoryCache(imageMemoryCacheBytes)
+	if cfg.ImageCacheBucket != "" {
+		gcsCache, err := imageproxy.NewGCSCache(ctx, cfg.ImageCacheBucket, "img/")
+		if err != nil {
+			slog.Error("Failed to create image cache; using memory only", logging.Error, err)
+		} else {
+			imageCache = imageproxy.TieredCache{Local: imageCache, Shared: gcsCache}
+		}
+	}
+	imageProxy := imageproxy.New(cfg.ImageProxyHosts, imageCache)
+
+	// Share preview images (GET /share/og/...), cached the same way under their own prefix
+	var ogImageCache imageproxy.Cache = imageproxy.NewMemoryCache(ogImageMemoryCacheBytes)
+	if cfg.ImageCacheBucket != "" {
+		gcsCache, err := imageproxy.NewGCSCache(ctx, cfg.ImageCacheBucket, "og/")
+		if err != nil {
+			slog.Error("Failed to create preview image cache; using memory only", logging.Error, err)
+		} else {
+			ogImageCache = imageproxy.TieredCache{Local: ogImageCache, Shared: gcsCache}
+		}
+	}
+
+	// Visit proof files (POST /visits/:id/proofs); uploads are unavailable without a bucket
+	var proofStore proofs.Store
+	if cfg.ProofBucket != "" {
+		gcsStore, err := proofs.NewGCSStore(ctx, cfg.ProofBucket)
+		if err != nil {
+			slog.Error("Failed to create proof store; proof uploads disabled", logging.Error, err)
+		} else {
+			proofStore = gcsStore
+		}
+	}
+
+	// Admin backfill jobs rebuilding derived per-user data (POST /admin/backfills/:name/start)
+	backfillRunner := backfill.NewRunner(ctx, db, cfg.BackfillRate)
+	backfillRunner.Register(backfill.JobVisitStats, db.RebuildUserVisitStats)
+	backfillRunner.Register(backfill.JobFollowers, db.RebuildFollowers)
+	backfillRunner.Register(backfill.JobInsights, insights.NewGenerator(db).Rebuild)
+	backfillRunner.Register(backfill.JobSchemaMigrations, db.MigrateUserSchema)
+	if !cfg.ReadOnly {
+		go insights.NewScheduler(backfillRunner, cfg.InsightsInterval).Run(ctx)
+	}
+
+	// Admin country overrides merged over the bundled country lists, reloaded periodically.
+	// Without them at startup the bundled lists are served until a refresh succeeds.
+	overridesRefresher := overrides.NewRefresher(db, cfg.OverridesRefresh)
+	if err := overridesRefresher.Refresh(ctx); err != nil {
+		slog.Error("Failed to load country overrides; using bundled lists", logging.Error, err)
+	}
+	go overridesRefresher.Run(ctx)
+
+	// Outbound email (friend invitations, account deletion notices); unavailable without SMTP_HOST
+	var mail mailer.Mailer
+	if cfg.Mail.Enabled() {
+		smtpMailer, err := mailer.NewSMTPMailer(cfg.Mail)
+		if err != nil {
+			slog.Error("Failed to create mailer; email disabled", logging.Error, err)
+		} else {
+			mail = smtpMailer
+		}
+	}
+
+	// Bot check for clients over the share quota; unavailable without TURNSTILE_SECRET_KEY
+	var shareChallenge *turnstile.Verifier
+	if cfg.Turnstile.Enabled() {
+		shareChallenge = turnstile.New(cfg.Turnstile)
+	}
+
+	// Final purge of accounts whose deletion grace period has ended (DELETE /account)
+	if !cfg.ReadOnly {
+		go accountpurge.NewWorker(db, proofStore, mail, cfg.AccountPurge).Run(ctx)
+	}
+
+	// Expiry of ephemeral collections: Firestore TTL policies, with a purge job as fallback
+	if *demo || cfg.FirestoreEmulatorHost != "" || cfg.DBDriver != config.DBDriverFirestore {
+		slog.Info("No Firestore TTL policies; expired documents are deleted by the purge job")
+	} else if err := ttl.Apply(ctx, cfg.ProjectID, ttl.Policies); err != nil {
+		slog.Warn("Failed to apply TTL policies; relying on the purge job", logging.Error, err)
+	}
+	if !cfg.ReadOnly {
+		go ttl.NewPurger(db, ttl.Policies, cfg.TTLPurge).Run(ctx)
+	}
+
+	// Declared Firestore indexes: missing ones are logged now rather than failing their queries
+	if !*demo && cfg.FirestoreEmulatorHost == "" && cfg.DBDriver == config.DBDriverFirestore {
+		if missing, err := indexes.Verify(ctx, cfg.ProjectID); err != nil {
+			slog.Warn("Failed to verify Firestore indexes", logging.Error, err)
+		} else if missing > 0 {
+			slog.Warn("Firestore indexes missing; deploy firestore.indexes.json",
+				logging.Count, missing)
+		}
+	}
+
+	// Exports of the users collection tree to BACKUP_BUCKET, scheduled and via POST /admin/backup
+	var backups *backup.Service
+	if cfg.Backup.Enabled() {
+		backups, err = backup.New(ctx, cfg.ProjectID, cfg.Backup)
+		if err != nil {
+			slog.Error("Failed to initialize backups", logging.Error, err)
+			log.Fatalf("Failed to initialize backups: %v", err)
+		}
+		defer backups.Close()
+		go backups.Run(ctx)
+	}
+
+	// Share token -> user ID lookups, saving the users query on share views; off when size is 0
+	var shareTokenCache cache.Cache
+	if cfg.ShareTokenCacheSize > 0 {
+		shareTokenCache = cache.NewLRU(cfg.ShareTokenCacheSize, cfg.ShareTokenCacheTTL)
+	}
 
 	// Create server with trace span
 	var srv *server.Server
 	err = tracing.SafeSpan(ctx, nil, "server.NewServer", func(spanCtx context.Context) error {
-		srv = server.NewServer(spanCtx, dbClient, authenticator, app.StaticFiles)
+		srv = server.NewServer(spanCtx, db, verifier, app.StaticFiles, imageProxy,
+			server.WithJSONTimeFormat(cfg.JSONTimeFormat),
+			server.WithAdminUserIDs(cfg.AdminUserIDs),
+			server.WithBackfillRunner(backfillRunner),
+			server.WithBackups(backups),
+			server.WithCountryOverrides(overridesRefresher),
+			server.WithCountriesAccess(cfg.CountriesAppTokens, cfg.CountriesAnonRate),
+			server.WithProofStore(proofStore),
+			server.WithFaultInjector(faultInjector),
+			server.WithDataResidency(cfg.DataResidency),
+			server.WithWriteQueue(writequeue.New(cfg.BatchWriteRate)),
+			server.WithMailer(mail, cfg.PublicBaseURL),
+			server.WithMaxFriends(cfg.MaxFriends),
+			server.WithMaxVisits(cfg.MaxVisits),
+			server.WithOGImageCache(ogImageCache),
+			server.WithShareAccess(cfg.ShareRate, shareChallenge),
+			server.WithRateLimits(cfg.UserRate, cfg.PublicRate),
+			server.WithTrustedProxies(cfg.TrustedProxies),
+			server.WithSessionCookies(sessions, cfg.SessionTTL, !cfg.IsDebug),
+			server.WithRevocationCheck(cfg.CheckRevoked),
+			server.WithReadOnly(cfg.ReadOnly),
+			server.WithVisitWatcher(visitWatcher),
+			server.WithMetrics(appMetrics),
+			server.WithShareTokenCache(shareTokenCache))
 		srv.RegisterRoutes()
 		return nil
 	})
@@ -101,6 +393,8 @@ func main() {
 		Addr:    ":" + cfg.Port,
 		Handler: srv.Router,
 	}
+	// Streams last until the client leaves; end them so Shutdown need not wait for that
+	httpServer.RegisterOnShutdown(srv.CloseStreams)
 
 	// Start server in a goroutine with trace span
 	go func() {
@@ -117,6 +411,21 @@ func main() {
 		}
 	}()
 
+	// Metrics listener: a port of its own, not routed by Cloud Run, for a Prometheus sidecar
+	var metricsServer *http.Server
+	if appMetrics != nil {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", appMetrics.Handler())
+		metricsServer = &http.Server{Addr: ":" + cfg.MetricsPort, Handler: mux}
+		go func() {
+			slog.Info("Metrics server starting on port", logging.Port, cfg.MetricsPort)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("Metrics server failed", logging.Error, err)
+				log.Fatalf("Metrics server failed: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -132,6 +441,9 @@ func main() {
 		if err := httpServer.Shutdown(shutdownCtx); err != nil {
 			return err
 		}
+		if metricsServer != nil {
+			return metricsServer.Shutdown(shutdownCtx)
+		}
 		return nil
 	})
 	if err != nil {
@@ -0,0 +1,28 @@
+// Command staticmanifest writes static/manifest.json for the embedded frontend (see package
+// staticmanifest). Run from the backend module root after copying the frontend build into
+// static/, e.g. with go generate.
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/matti777/my-countries/backend/internal/staticmanifest"
+)
+
+func main() {
+	const root = "static"
+	m, err := staticmanifest.Build(os.DirFS("."), root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := m.Marshal()
+	if err != nil {
+		log.Fatalf("failed to encode manifest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, staticmanifest.FileName), out, 0o644); err != nil {
+		log.Fatalf("failed to write manifest: %v", err)
+	}
+	log.Printf("Wrote %s/%s (%d files)", root, staticmanifest.FileName, len(m.Files))
+}
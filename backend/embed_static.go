@@ -2,7 +2,10 @@ package backend
 
 import "embed"
 
-// StaticFiles is the embedded frontend (static/index.html, static/assets/...).
+//go:generate go run ./cmd/staticmanifest
+
+// StaticFiles is the embedded frontend (static/index.html, static/assets/...) with
+// static/manifest.json, the file hashes written by go generate after the frontend is copied in.
 //
 //go:embed all:static
 var StaticFiles embed.FS
@@ -0,0 +1,128 @@
+// Package accountpurge permanently deletes accounts whose deletion grace period has ended.
+// DELETE /account only schedules the deletion (models.AccountDeletionGracePeriod), so users can
+// change their mind; every instance runs a Worker that purges the due accounts periodically.
+// Purges are idempotent, so instances racing on the same account do no harm.
+package accountpurge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/mailer"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/proofs"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// batchSize is the most accounts purged per run; the rest wait for the next run.
+const batchSize = 20
+
+// Store lists and purges accounts. Implemented by database.Client.
+type Store interface {
+	GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]string, error)
+	GetUserByID(ctx context.Context, userID string) (*models.User, error)
+	GetCountryVisitsByUser(ctx context.Context, userID string) ([]models.CountryVisit, error)
+	PurgeUser(ctx context.Context, userID string, now time.Time) error
+}
+
+// Worker purges accounts due for deletion.
+type Worker struct {
+	store    Store
+	proofs   proofs.Store
+	mailer   mailer.Mailer
+	interval time.Duration
+}
+
+// NewWorker returns a Worker that Run calls PurgeDue on every interval. proofStore may be nil
+// when proofs are not configured, and mail when email is not; with mail, users are told when
+// their account has been purged.
+func NewWorker(store Store, proofStore proofs.Store, mail mailer.Mailer,
+	interval time.Duration) *Worker {
+	return &Worker{store: store, proofs: proofStore, mailer: mail, interval: interval}
+}
+
+// PurgeDue purges up to batchSize accounts whose deletion is due and returns how many it
+// purged. It stops at the first failing account, which is retried on the next call.
+func (w *Worker) PurgeDue(ctx context.Context) (int, error) {
+	ctx, span := tracing.New(ctx, "accountpurge.PurgeDue")
+	defer span.End()
+
+	now := time.Now().UTC()
+	userIDs, err := w.store.GetUsersDueForDeletion(ctx, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, userID := range userIDs {
+		if err := w.purge(ctx, userID, now); err != nil {
+			return i, fmt.Errorf("failed to purge user %s: %w", userID, err)
+		}
+		logging.FromContext(ctx).Info("Purged account", logging.UserID, userID)
+	}
+	return len(userIDs), nil
+}
+
+func (w *Worker) purge(ctx context.Context, userID string, now time.Time) error {
+	// Re-checked so proofs survive a deletion cancelled since the listing
+	u, err := w.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil || !u.PendingDeletion() || u.DeletionScheduledAt.After(now) {
+		return nil
+	}
+	if w.proofs != nil {
+		visits, err := w.store.GetCountryVisitsByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, v := range visits {
+			for _, p := range v.Proofs {
+				if err := w.proofs.Delete(ctx, proofs.ObjectName(userID, v.ID, p.ID)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := w.store.PurgeUser(ctx, userID, now); err != nil {
+		return err
+	}
+	w.notify(ctx, u)
+	return nil
+}
+
+// notify emails u that their account has been deleted. The account is gone already, so
+// failures are only logged.
+func (w *Worker) notify(ctx context.Context, u *models.User) {
+	if w.mailer == nil || u.Email == "" {
+		return
+	}
+	msg := mailer.Message{
+		To:      u.Email,
+		Subject: "Your My Travel: Visited Countries account has been deleted",
+		Body: "Your account has been deleted as you requested, together with your visits, " +
+			"friends and shares. This cannot be undone.\n\n" +
+			"Thank you for using My Travel: Visited Countries.\n",
+	}
+	if err := w.mailer.Send(ctx, msg); err != nil {
+		logging.FromContext(ctx).Warn("Failed to send account purge notice",
+			logging.UserID, u.ID, logging.Error, err)
+	}
+}
+
+// Run calls PurgeDue every interval until ctx is cancelled. Failures are logged.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.PurgeDue(ctx); err != nil {
+				logging.FromContext(ctx).Error("Account purge failed", logging.Error, err)
+			}
+		}
+	}
+}
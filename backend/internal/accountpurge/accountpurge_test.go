@@ -0,0 +1,88 @@
+package accountpurge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database/memory"
+	"github.com/matti777/my-countries/backend/internal/mailer"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// testMailer records the messages sent, failing with err when set.
+type testMailer struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mailer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// newDueUser adds the user userID to db with a deletion due an hour ago.
+func newDueUser(t *testing.T, db *memory.DB, userID, email string) {
+	t.Helper()
+	ctx := context.Background()
+	if err := db.EnsureUser(ctx, &models.User{ID: userID, Email: email}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.ScheduleAccountDeletion(ctx, userID, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeDueNotifies(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	newDueUser(t, db, "u1", "u1@example.com")
+	newDueUser(t, db, "u2", "")
+	if err := db.EnsureUser(ctx, &models.User{ID: "u3", Email: "u3@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	mail := &testMailer{}
+
+	n, err := NewWorker(db, nil, mail, time.Hour).PurgeDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("purged %d, want 2", n)
+	}
+	if len(mail.sent) != 1 || mail.sent[0].To != "u1@example.com" {
+		t.Fatalf("sent = %+v, want one message to u1@example.com", mail.sent)
+	}
+	if u, err := db.GetUserByID(ctx, "u1"); err != nil || u != nil {
+		t.Errorf("GetUserByID(u1) = %+v, %v; want purged", u, err)
+	}
+}
+
+func TestPurgeDueNoticeFailure(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	newDueUser(t, db, "u1", "u1@example.com")
+	newDueUser(t, db, "u2", "u2@example.com")
+	mail := &testMailer{err: errors.New("smtp down")}
+
+	n, err := NewWorker(db, nil, mail, time.Hour).PurgeDue(ctx)
+	if err != nil {
+		t.Fatalf("PurgeDue: %v; notice failures must not fail the purge", err)
+	}
+	if n != 2 {
+		t.Errorf("purged %d, want 2", n)
+	}
+}
+
+func TestPurgeDueWithoutMailer(t *testing.T) {
+	db := memory.New()
+	newDueUser(t, db, "u1", "u1@example.com")
+
+	if _, err := NewWorker(db, nil, nil, time.Hour).PurgeDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// apiKeyPrefix starts every API key so that leaked keys are easy to recognize, e.g. by secret
+// scanners.
+const apiKeyPrefix = "mc_"
+
+// NewAPIKey returns a new random API key and the display prefix stored with it. Only the hash
+// of the key (HashAPIKey) is stored; the key itself is shown to the user once.
+func NewAPIKey() (key, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:len(apiKeyPrefix)+6], nil
+}
+
+// HashAPIKey returns the hex SHA-256 hash under which key is stored. A fast hash is enough
+// since keys are 256-bit random values, and it lets keys be looked up by hash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LooksLikeAPIKey reports whether key has the format of NewAPIKey keys, so malformed keys are
+// refused without a database lookup.
+func LooksLikeAPIKey(key string) bool {
+	rest, ok := strings.CutPrefix(key, apiKeyPrefix)
+	return ok && base64.RawURLEncoding.EncodedLen(32) == len(rest)
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+const jwksRefreshInterval = 1 * time.Hour
+
+// jwksForcedRefreshInterval is the shortest time between key set fetches forced by tokens with
+// an unknown key ID (or by a failed first fetch), so forged tokens cannot trigger a fetch per
+// request.
+const jwksForcedRefreshInterval = 1 * time.Minute
+
+// acceptableSkew is the clock skew allowed between client and server for exp/nbf validation.
+const acceptableSkew = 1 * time.Minute
+
+// jwksVerifier verifies JWTs of one issuer and audience against a JWKS URL, caching the key
+// set for an hour. A token signed with a key missing from the cached set forces a fetch, for
+// keys rotated within the hour; when a fetch fails the cached set is used however old it is,
+// so a JWKS outage only affects tokens signed with new keys. Shared by the TokenVerifier
+// implementations.
+type jwksVerifier struct {
+	jwksURL   string
+	issuer    string
+	audience  string
+	cache     *jwk.Cache
+	cacheOnce sync.Once
+	cacheErr  error
+	whitelist jwk.Whitelist
+
+	// fetchedAt is when the key set was last fetched (Unix nanoseconds; 0 before).
+	fetchedAt atomic.Int64
+
+	// forcedAt is when a fetch was last forced (Unix nanoseconds; 0 before).
+	forcedAt atomic.Int64
+
+	// fetches counts the key set fetches.
+	fetches atomic.Int64
+}
+
+func newJWKSVerifier(jwksURL, issuer, audience string) *jwksVerifier {
+	return &jwksVerifier{
+		jwksURL:   jwksURL,
+		issuer:    issuer,
+		audience:  audience,
+		whitelist: jwk.NewMapWhitelist().Add(jwksURL),
+	}
+}
+
+// ensureCache initializes the JWKS cache once (1-hour TTL). Only a failed registration is
+// permanent; a failed first fetch is retried by keySet.
+func (v *jwksVerifier) ensureCache(ctx context.Context) error {
+	v.cacheOnce.Do(func() {
+		// The cache refreshes in the background until its context ends, so it must outlive the
+		// request that created it
+		v.cache = jwk.NewCache(context.WithoutCancel(ctx),
+			jwk.WithRefreshWindow(jwksRefreshInterval))
+		v.cacheErr = v.cache.Register(v.jwksURL,
+			jwk.WithMinRefreshInterval(jwksRefreshInterval),
+			jwk.WithFetchWhitelist(v.whitelist),
+			jwk.WithPostFetcher(jwk.PostFetchFunc(func(_ string, set jwk.Set) (jwk.Set, error) {
+				v.fetchedAt.Store(time.Now().UnixNano())
+				v.fetches.Add(1)
+				return set, nil
+			})),
+		)
+	})
+	return v.cacheErr
+}
+
+// keySet returns the cached key set. When it has no key kid, or no set was fetched yet, the set
+// is fetched again unless a fetch was forced within jwksForcedRefreshInterval. A failed fetch
+// falls back to the cached set.
+func (v *jwksVerifier) keySet(ctx context.Context, kid string) (jwk.Set, error) {
+	// Get fails only when no set was fetched yet; a failed background refresh keeps the old set
+	cached, err := v.cache.Get(ctx, v.jwksURL)
+	if err == nil {
+		if _, ok := cached.LookupKeyID(kid); ok {
+			return cached, nil
+		}
+	}
+	if !v.claimForcedRefresh() {
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("get jwks: %w", err)
+	}
+	fresh, refreshErr := v.cache.Refresh(ctx, v.jwksURL)
+	if refreshErr == nil {
+		return fresh, nil
+	}
+	if cached != nil {
+		return cached, nil
+	}
+	return nil, fmt.Errorf("refresh jwks: %w", refreshErr)
+}
+
+// claimForcedRefresh reports whether a forced fetch may start now, recording it if so.
+func (v *jwksVerifier) claimForcedRefresh() bool {
+	last := v.forcedAt.Load()
+	now := time.Now().UnixNano()
+	if last != 0 && time.Duration(now-last) < jwksForcedRefreshInterval {
+		return false
+	}
+	return v.forcedAt.CompareAndSwap(last, now)
+}
+
+// JWKSFetchedAt returns when the signing keys were last fetched, or the zero time before the
+// first token was verified.
+func (v *jwksVerifier) JWKSFetchedAt() time.Time {
+	ns := v.fetchedAt.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
+
+// PrimeJWKS fetches the signing keys unless a key set was fetched already.
+func (v *jwksVerifier) PrimeJWKS(ctx context.Context) error {
+	if err := v.ensureCache(ctx); err != nil {
+		return fmt.Errorf("jwks cache: %w", err)
+	}
+	// Get fetches only before the first successful fetch; later it returns the cached set
+	if _, err := v.cache.Get(ctx, v.jwksURL); err != nil {
+		return fmt.Errorf("get jwks: %w", err)
+	}
+	return nil
+}
+
+// JWKSFetches returns how many times the signing keys were fetched.
+func (v *jwksVerifier) JWKSFetches() int64 {
+	return v.fetches.Load()
+}
+
+// VerifyIDToken verifies the signature, issuer, audience and validity period of idToken and
+// returns its claims.
+func (v *jwksVerifier) VerifyIDToken(ctx context.Context, idToken string) (*Claims, error) {
+	if idToken == "" {
+		return nil, fmt.Errorf("token is empty")
+	}
+	if err := v.ensureCache(ctx); err != nil {
+		return nil, fmt.Errorf("jwks cache: %w", err)
+	}
+	msg, err := jws.Parse([]byte(idToken))
+	if err != nil {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+	if len(msg.Signatures()) != 1 {
+		return nil, fmt.Errorf("token must have exactly one signature")
+	}
+	keySet, err := v.keySet(ctx, msg.Signatures()[0].ProtectedHeaders().KeyID())
+	if err != nil {
+		return nil, err
+	}
+	tok, err := jwt.Parse([]byte(idToken),
+		jwt.WithKeySet(keySet),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithAcceptableSkew(acceptableSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("verify token: %w", err)
+	}
+	return claimsFromToken(tok), nil
+}
+
+// claimsFromToken reads the standard OpenID Connect claims, the Firebase sign-in provider and
+// the custom claims (tester, admin or role) of tok.
+func claimsFromToken(tok jwt.Token) *Claims {
+	claims := &Claims{
+		Sub: tok.Subject(),
+	}
+	if v, ok := tok.Get("name"); ok {
+		if s, ok := v.(string); ok {
+			claims.Name = s
+		}
+	}
+	if v, ok := tok.Get("email"); ok {
+		if s, ok := v.(string); ok {
+			claims.Email = s
+		}
+	}
+	if v, ok := tok.Get("email_verified"); ok {
+		if b, ok := v.(bool); ok {
+			claims.EmailVerified = b
+		}
+	}
+	if v, ok := tok.Get("picture"); ok {
+		if s, ok := v.(string); ok {
+			claims.Picture = s
+		}
+	}
+	if v, ok := tok.Get("tester"); ok {
+		if b, ok := v.(bool); ok {
+			claims.Tester = b
+		}
+	}
+	if v, ok := tok.Get("admin"); ok {
+		if b, ok := v.(bool); ok {
+			claims.Admin = b
+		}
+	}
+	if v, ok := tok.Get("role"); ok {
+		if s, ok := v.(string); ok && s == "admin" {
+			claims.Admin = true
+		}
+	}
+	if v, ok := tok.Get("firebase"); ok {
+		if m, ok := v.(map[string]interface{}); ok && m["sign_in_provider"] == "anonymous" {
+			claims.Anonymous = true
+		}
+	}
+	if v, ok := tok.Get("auth_time"); ok {
+		if n, ok := v.(float64); ok {
+			claims.AuthTime = time.Unix(int64(n), 0).UTC()
+		}
+	}
+	return claims
+}
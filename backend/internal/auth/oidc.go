@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// discoveryTimeout bounds fetching the OpenID Connect discovery document at startup.
+const discoveryTimeout = 10 * time.Second
+
+// OIDCConfig configures an OIDCVerifier.
+type OIDCConfig struct {
+	// Issuer is the provider's issuer URL, e.g. https://keycloak.example.com/realms/travel;
+	// tokens must carry it as "iss".
+	Issuer string
+
+	// Audience is the "aud" tokens must carry, usually the client ID of the frontend.
+	Audience string
+
+	// JWKSURL is where the signing keys are fetched; discovered from the issuer when empty.
+	JWKSURL string
+}
+
+// Enabled reports whether an OIDC provider is configured.
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != ""
+}
+
+// OIDCVerifier verifies ID tokens of a generic OpenID Connect provider (Keycloak, Auth0, ...)
+// for self-hosted deployments without Firebase. The provider's user IDs ("sub") become user IDs.
+type OIDCVerifier struct {
+	*jwksVerifier
+}
+
+// NewOIDCVerifier creates a verifier for cfg. Without cfg.JWKSURL the key set URL is read from
+// the issuer's /.well-known/openid-configuration.
+func NewOIDCVerifier(ctx context.Context, cfg OIDCConfig) (*OIDCVerifier, error) {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, fmt.Errorf("OIDC issuer and audience are required")
+	}
+	jwksURL := cfg.JWKSURL
+	if jwksURL == "" {
+		var err error
+		if jwksURL, err = discoverJWKSURL(ctx, cfg.Issuer); err != nil {
+			return nil, err
+		}
+	}
+	return &OIDCVerifier{jwksVerifier: newJWKSVerifier(jwksURL, cfg.Issuer, cfg.Audience)}, nil
+}
+
+// discoverJWKSURL returns the jwks_uri of the OpenID Connect discovery document of issuer.
+func discoverJWKSURL(ctx context.Context, issuer string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid OIDC issuer: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch OIDC discovery document: status %d",
+			resp.StatusCode)
+	}
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", fmt.Errorf("failed to decode OIDC discovery document: %w", err)
+	}
+	if doc.Issuer != issuer || doc.JWKSURI == "" {
+		return "", fmt.Errorf("OIDC discovery document does not match issuer %s", issuer)
+	}
+	return doc.JWKSURI, nil
+}
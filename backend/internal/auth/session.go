@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"google.golang.org/api/identitytoolkit/v1"
+)
+
+// Session cookies are signed with these keys, published as X.509 certificates by key ID.
+// See https://firebase.google.com/docs/auth/admin/manage-cookies
+const sessionCookieCertsURL = "https://www.googleapis.com/identitytoolkit/v3/relyingparty/publicKeys"
+
+const (
+	// MinSessionDuration and MaxSessionDuration bound the lifetime of a session cookie.
+	MinSessionDuration = 5 * time.Minute
+	MaxSessionDuration = 14 * 24 * time.Hour
+)
+
+// SessionCookies creates and verifies session cookies, which let the browser stay signed in
+// without refreshing ID tokens. Implemented by FirebaseSessions.
+type SessionCookies interface {
+	// Create exchanges a valid ID token for a session cookie valid for validFor.
+	Create(ctx context.Context, idToken string, validFor time.Duration) (string, error)
+
+	// Verify verifies a session cookie and returns the claims of the ID token it was made from.
+	Verify(ctx context.Context, cookie string) (*Claims, error)
+}
+
+// FirebaseSessions manages Firebase Authentication session cookies of a project. Creating them
+// calls the Identity Toolkit API with the default credentials (the service account needs the
+// Firebase Authentication Admin role); verifying them uses Google's public certificates, cached
+// for an hour.
+type FirebaseSessions struct {
+	service   *identitytoolkit.Service
+	projectID string
+	issuer    string
+	client    *http.Client
+
+	mu        sync.Mutex
+	keys      jwk.Set
+	fetchedAt time.Time
+}
+
+// NewFirebaseSessions creates session cookie support for the Firebase project ID used by the
+// frontend.
+func NewFirebaseSessions(ctx context.Context, projectID string) (*FirebaseSessions, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("project ID is required")
+	}
+	service, err := identitytoolkit.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity toolkit client: %w", err)
+	}
+	return &FirebaseSessions{
+		service:   service,
+		projectID: projectID,
+		issuer:    "https://session.firebase.google.com/" + projectID,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Create exchanges idToken for a session cookie valid for validFor, which must be between
+// MinSessionDuration and MaxSessionDuration.
+func (f *FirebaseSessions) Create(
+	ctx context.Context,
+	idToken string,
+	validFor time.Duration,
+) (string, error) {
+	if validFor < MinSessionDuration || validFor > MaxSessionDuration {
+		return "", fmt.Errorf("session duration must be between %s and %s",
+			MinSessionDuration, MaxSessionDuration)
+	}
+	resp, err := f.service.Projects.CreateSessionCookie(f.projectID,
+		&identitytoolkit.GoogleCloudIdentitytoolkitV1CreateSessionCookieRequest{
+			IdToken:       idToken,
+			ValidDuration: int64(validFor / time.Second),
+		}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to create session cookie: %w", err)
+	}
+	return resp.SessionCookie, nil
+}
+
+// Verify verifies the signature, issuer, audience and validity period of cookie.
+func (f *FirebaseSessions) Verify(ctx context.Context, cookie string) (*Claims, error) {
+	if cookie == "" {
+		return nil, fmt.Errorf("session cookie is empty")
+	}
+	keys, err := f.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := jwt.Parse([]byte(cookie),
+		jwt.WithKeySet(keys),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(f.issuer),
+		jwt.WithAudience(f.projectID),
+		jwt.WithAcceptableSkew(acceptableSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("verify session cookie: %w", err)
+	}
+	return claimsFromToken(tok), nil
+}
+
+// keySet returns the cached session cookie keys, fetching them when older than
+// jwksRefreshInterval. A failed refresh keeps using the previous keys.
+func (f *FirebaseSessions) keySet(ctx context.Context) (jwk.Set, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.keys != nil && time.Since(f.fetchedAt) < jwksRefreshInterval {
+		return f.keys, nil
+	}
+	keys, err := f.fetchKeys(ctx)
+	if err != nil {
+		if f.keys != nil {
+			return f.keys, nil
+		}
+		return nil, err
+	}
+	f.keys = keys
+	f.fetchedAt = time.Now()
+	return keys, nil
+}
+
+// fetchKeys downloads the session cookie certificates and returns their public keys.
+func (f *FirebaseSessions) fetchKeys(ctx context.Context) (jwk.Set, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sessionCookieCertsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch session cookie keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch session cookie keys: status %d", resp.StatusCode)
+	}
+	var certs map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&certs); err != nil {
+		return nil, fmt.Errorf("failed to decode session cookie keys: %w", err)
+	}
+	set := jwk.NewSet()
+	for kid, certPEM := range certs {
+		block, _ := pem.Decode([]byte(certPEM))
+		if block == nil {
+			return nil, fmt.Errorf("invalid session cookie certificate %s", kid)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse session cookie certificate %s: %w", kid, err)
+		}
+		key, err := jwk.FromRaw(cert.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert session cookie key %s: %w", kid, err)
+		}
+		if err := key.Set(jwk.KeyIDKey, kid); err != nil {
+			return nil, err
+		}
+		if err := key.Set(jwk.AlgorithmKey, jwa.RS256); err != nil {
+			return nil, err
+		}
+		if err := set.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
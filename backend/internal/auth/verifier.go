@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// TokenVerifier verifies the ID tokens of an identity provider. Implemented by Authenticator
+// (Firebase Authentication) and OIDCVerifier (any OpenID Connect provider, e.g. Keycloak or
+// Auth0); the server only depends on this interface.
+type TokenVerifier interface {
+	// VerifyIDToken verifies idToken and returns its claims.
+	VerifyIDToken(ctx context.Context, idToken string) (*Claims, error)
+
+	// JWKSFetchedAt returns when the signing keys were last fetched, or the zero time before
+	// the first token was verified.
+	JWKSFetchedAt() time.Time
+
+	// JWKSFetches returns how many times the signing keys were fetched, scheduled refreshes
+	// and ones forced by unknown key IDs alike.
+	JWKSFetches() int64
+
+	// PrimeJWKS fetches the signing keys unless they were fetched already, so the first
+	// requests of an instance need not wait for them.
+	PrimeJWKS(ctx context.Context) error
+}
+
+// UnverifiedSubject returns the sub claim of idToken without verifying the token, or "" when it
+// cannot be parsed. Only for attributing rejected tokens (see the audit log); never trust it.
+func UnverifiedSubject(idToken string) string {
+	tok, err := jwt.ParseInsecure([]byte(idToken))
+	if err != nil {
+		return ""
+	}
+	return tok.Subject()
+}
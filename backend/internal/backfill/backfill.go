@@ -0,0 +1,280 @@
+// Package backfill runs resumable jobs that walk all users and rebuild derived data (counters,
+// stats) from their source documents, e.g. after a new denormalized field is introduced.
+// Jobs run in the background of one instance, rate limited so they do not compete with
+// request traffic for Firestore quota, and checkpoint their cursor after every batch so an
+// interrupted job resumes where it stopped.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// JobVisitStats rebuilds User.VisitCount and User.DistinctCountries.
+const JobVisitStats = "visit-stats"
+
+// JobFollowers adds missing entries to the followers index from the users' friends.
+const JobFollowers = "followers"
+
+// JobInsights regenerates the users' insights documents; started weekly by insights.Scheduler.
+const JobInsights = "insights"
+
+// JobSchemaMigrations upgrades the users' documents to the latest schema version (see
+// internal/migrations), which reads otherwise do lazily.
+const JobSchemaMigrations = "schema-migrations"
+
+const (
+	// batchSize is the number of users listed and rebuilt between checkpoints.
+	batchSize = 50
+
+	// staleAfter is how long a running job may go without a checkpoint before another
+	// instance may take it over; a batch at the slowest configured rate finishes well within.
+	staleAfter = 5 * time.Minute
+)
+
+var (
+	// ErrUnknownJob is returned for a job name that was not registered.
+	ErrUnknownJob = errors.New("unknown backfill job")
+	// ErrAlreadyRunning is returned by Start when the job is running on this or another instance.
+	ErrAlreadyRunning = errors.New("backfill job already running")
+	// ErrNotRunning is returned by Pause when the job is not running on this instance.
+	ErrNotRunning = errors.New("backfill job not running")
+)
+
+// Store persists job checkpoints and lists users. Implemented by database.Client.
+type Store interface {
+	GetBackfillJob(ctx context.Context, name string) (*models.BackfillJob, error)
+	SaveBackfillJob(ctx context.Context, job *models.BackfillJob) error
+	ListUserIDs(ctx context.Context, afterID string, limit int) ([]string, error)
+}
+
+// RebuildFunc rebuilds the derived data of one user. It must be idempotent: after an
+// interruption the last unfinished batch is rebuilt again.
+type RebuildFunc func(ctx context.Context, userID string) error
+
+// Runner runs registered backfill jobs, at most one goroutine per job on this instance.
+type Runner struct {
+	ctx            context.Context
+	store          Store
+	usersPerSecond float64
+
+	mu      sync.Mutex
+	jobs    map[string]RebuildFunc
+	running map[string]*runningJob
+}
+
+// runningJob is a job goroutine on this instance. done is closed after its final checkpoint.
+type runningJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRunner returns a Runner that rebuilds at most usersPerSecond users per second per job.
+// Jobs run with ctx (carrying the logger and tracer), not with the context of the request that
+// started them.
+func NewRunner(ctx context.Context, store Store, usersPerSecond float64) *Runner {
+	return &Runner{
+		ctx:            ctx,
+		store:          store,
+		usersPerSecond: usersPerSecond,
+		jobs:           make(map[string]RebuildFunc),
+		running:        make(map[string]*runningJob),
+	}
+}
+
+// Register adds a job. Registering the same name twice replaces the earlier function.
+func (r *Runner) Register(name string, fn RebuildFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[name] = fn
+}
+
+// Status returns the job's checkpoint, or a pending job when it has never been started.
+func (r *Runner) Status(ctx context.Context, name string) (*models.BackfillJob, error) {
+	r.mu.Lock()
+	_, ok := r.jobs[name]
+	r.mu.Unlock()
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	job, err := r.store.GetBackfillJob(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		job = &models.BackfillJob{Name: name, Status: models.BackfillPending}
+	}
+	return job, nil
+}
+
+// Start runs the job in the background and returns its checkpoint as of the start. A paused or
+// interrupted job resumes after its cursor; a completed or pending job, or any job when
+// restart is set, starts over from the first user with zeroed counts.
+func (r *Runner) Start(
+	ctx context.Context,
+	name string,
+	restart bool,
+) (*models.BackfillJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fn, ok := r.jobs[name]
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	if _, running := r.running[name]; running {
+		return nil, ErrAlreadyRunning
+	}
+	job, err := r.store.GetBackfillJob(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if job != nil && job.Status == models.BackfillRunning && now.Sub(job.UpdatedAt) < staleAfter {
+		return nil, ErrAlreadyRunning
+	}
+	if job == nil || restart || job.Status == models.BackfillCompleted {
+		job = &models.BackfillJob{Name: name, StartedAt: now}
+	}
+	job.Status = models.BackfillRunning
+	job.UpdatedAt = now
+	job.CompletedAt = nil
+	if err := r.store.SaveBackfillJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(r.ctx)
+	rj := &runningJob{cancel: cancel, done: make(chan struct{})}
+	r.running[name] = rj
+	out := *job
+	go r.run(runCtx, rj, *job, fn)
+	return &out, nil
+}
+
+// Running returns the number of jobs running on this instance.
+func (r *Runner) Running() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.running)
+}
+
+// Pause stops the job on this instance after the user being rebuilt and returns once the paused
+// checkpoint, which keeps the cursor of the last finished batch, has been saved.
+func (r *Runner) Pause(name string) error {
+	r.mu.Lock()
+	if _, ok := r.jobs[name]; !ok {
+		r.mu.Unlock()
+		return ErrUnknownJob
+	}
+	rj, ok := r.running[name]
+	r.mu.Unlock()
+	if !ok {
+		return ErrNotRunning
+	}
+	rj.cancel()
+	<-rj.done
+	return nil
+}
+
+// run walks the users after job.Cursor in batches and checkpoints after each batch.
+func (r *Runner) run(ctx context.Context, rj *runningJob, job models.BackfillJob, fn RebuildFunc) {
+	ctx, span := tracing.New(ctx, "backfill."+job.Name)
+	defer span.End()
+
+	log := logging.FromContext(ctx).WithParams(logging.BackfillJob, job.Name)
+	ctx = logging.WithContext(ctx, log)
+	log.Info("Backfill job started")
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, job.Name)
+		r.mu.Unlock()
+		rj.cancel()
+		close(rj.done)
+	}()
+
+	limiter := rate.NewLimiter(rate.Limit(r.usersPerSecond), 1)
+	err := r.walk(ctx, &job, fn, limiter)
+
+	// Checkpoints are written with a fresh context: ctx is already cancelled when paused.
+	saveCtx := context.WithoutCancel(ctx)
+	switch {
+	case err == nil:
+		completed := time.Now().UTC()
+		job.Status = models.BackfillCompleted
+		job.CompletedAt = &completed
+		log.Info("Backfill job completed", logging.Count, job.Processed)
+	case ctx.Err() != nil:
+		job.Status = models.BackfillPaused
+		log.Info("Backfill job paused")
+	default:
+		job.Status = models.BackfillPaused
+		job.LastError = err.Error()
+		log.Error("Backfill job stopped", logging.Error, err)
+	}
+	job.UpdatedAt = time.Now().UTC()
+	if err := r.store.SaveBackfillJob(saveCtx, &job); err != nil {
+		log.Error("Failed to save backfill job", logging.Error, err)
+	}
+}
+
+// walk rebuilds batches of users until none are left, ctx is cancelled or the store fails.
+// A failing rebuild of a single user is counted in job.Failed and does not stop the job.
+func (r *Runner) walk(
+	ctx context.Context,
+	job *models.BackfillJob,
+	fn RebuildFunc,
+	limiter *rate.Limiter,
+) error {
+	log := logging.FromContext(ctx)
+	for {
+		ids, err := r.store.ListUserIDs(ctx, job.Cursor, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+
+		// Counts are committed with the cursor, so a batch redone after a pause or
+		// interruption is not counted twice.
+		var processed, failed int64
+		lastError := ""
+		for _, id := range ids {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+			if err := fn(ctx, id); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Warn("Backfill rebuild failed", logging.UserID, id, logging.Error, err)
+				failed++
+				lastError = err.Error()
+				continue
+			}
+			processed++
+		}
+		job.Processed += processed
+		job.Failed += failed
+		if lastError != "" {
+			job.LastError = lastError
+		}
+		if len(ids) < batchSize {
+			return nil
+		}
+
+		job.Cursor = ids[len(ids)-1]
+		job.UpdatedAt = time.Now().UTC()
+		if err := r.store.SaveBackfillJob(ctx, job); err != nil {
+			return fmt.Errorf("failed to checkpoint: %w", err)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Package backup exports the users collection tree (users and all their subcollections) to a
+// Cloud Storage bucket with the Firestore managed export, on a schedule and on demand
+// (POST /admin/backup). Each backup is a folder backups/{ID}/ holding the export and a
+// backup.json record; creating the record claims the backup, so instances racing to start a
+// scheduled one start it once. Completed backups beyond the retained count are deleted.
+//
+// Restore with `gcloud firestore import gs://BUCKET/backups/ID`.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	admin "cloud.google.com/go/firestore/apiv1/admin"
+	"cloud.google.com/go/firestore/apiv1/admin/adminpb"
+	"google.golang.org/api/googleapi"
+	storage "google.golang.org/api/storage/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+const (
+	// folder is the object prefix of all backups in the bucket.
+	folder = "backups/"
+
+	// recordName is the object name of a backup's record within its folder.
+	recordName = "backup.json"
+
+	// idFormat formats a backup's start time as its ID.
+	idFormat = "20060102T150405Z"
+
+	// checkInterval is how often Run checks whether a scheduled backup is due.
+	checkInterval = time.Hour
+)
+
+// CollectionIDs are the collections exported: users and the IDs of their subcollections (and
+// of the history of country visits). Firestore matches collection IDs at every level.
+var CollectionIDs = []string{"users", "country_visits", "history", "friends", "followers",
+	"blocked", "friend_invites", "insights", "overlaps", "share_links", "share_stats",
+	"api_keys", "audit"}
+
+// ErrAlreadyStarted is returned by Start when a backup with the same ID exists.
+var ErrAlreadyStarted = errors.New("backup already started")
+
+// Config configures backups. The zero value disables them.
+type Config struct {
+	// Bucket is the Cloud Storage bucket holding the backups.
+	Bucket string
+
+	// Interval is how often a backup is made (0: only on demand).
+	Interval time.Duration
+
+	// Retain is how many completed backups are kept.
+	Retain int
+}
+
+// Enabled reports whether backups are configured.
+func (c Config) Enabled() bool {
+	return c.Bucket != ""
+}
+
+// Service starts, lists and prunes the backups of the default database of a project.
+type Service struct {
+	cfg       Config
+	database  string
+	firestore *admin.FirestoreAdminClient
+	storage   *storage.Service
+}
+
+// New returns a Service for the default database of projectID, using application default
+// credentials. Close it when done.
+func New(ctx context.Context, projectID string, cfg Config) (*Service, error) {
+	fs, err := admin.NewFirestoreAdminClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firestore admin client: %w", err)
+	}
+	st, err := storage.NewService(ctx)
+	if err != nil {
+		fs.Close()
+		return nil, fmt.Errorf("failed to create storage service: %w", err)
+	}
+	return &Service{
+		cfg:       cfg,
+		database:  fmt.Sprintf("projects/%s/databases/(default)", projectID),
+		firestore: fs,
+		storage:   st,
+	}, nil
+}
+
+// Close closes the Firestore admin client.
+func (s *Service) Close() error {
+	return s.firestore.Close()
+}
+
+// Start claims the backup starting at now (truncated to the second) and starts its export. The
+// export runs in Firestore; Start returns once it has been accepted. ErrAlreadyStarted when a
+// backup with the same ID exists.
+func (s *Service) Start(
+	ctx context.Context,
+	trigger string,
+	now time.Time,
+) (*models.Backup, error) {
+	ctx, span := tracing.New(ctx, "backup.Start")
+	defer span.End()
+
+	now = now.UTC().Truncate(time.Second)
+	id := now.Format(idFormat)
+	b := &models.Backup{
+		ID:        id,
+		URI:       "gs://" + s.cfg.Bucket + "/" + folder + id,
+		Trigger:   trigger,
+		StartedAt: now,
+	}
+	if err := s.writeRecord(ctx, b, true); err != nil {
+		return nil, err
+	}
+	op, err := s.firestore.ExportDocuments(ctx, &adminpb.ExportDocumentsRequest{
+		Name:            s.database,
+		CollectionIds:   CollectionIDs,
+		OutputUriPrefix: b.URI,
+	})
+	if err != nil {
+		b.Error = err.Error()
+		if werr := s.writeRecord(ctx, b, false); werr != nil {
+			logging.FromContext(ctx).Error("Failed to record backup error", logging.Error, werr)
+		}
+		return nil, fmt.Errorf("failed to start export: %w", err)
+	}
+	b.Operation = op.Name()
+	if err := s.writeRecord(ctx, b, false); err != nil {
+		return nil, err
+	}
+	b.Status = models.BackupRunning
+	logging.FromContext(ctx).Info("Started backup", "backup", b.ID, "trigger", trigger)
+	return b, nil
+}
+
+// List returns the backups in the bucket with their status, newest first.
+func (s *Service) List(ctx context.Context) ([]models.Backup, error) {
+	ctx, span := tracing.New(ctx, "backup.List")
+	defer span.End()
+
+	var ids []string
+	err := s.storage.Objects.List(s.cfg.Bucket).Prefix(folder).Delimiter("/").
+		Pages(ctx, func(objs *storage.Objects) error {
+			for _, p := range objs.Prefixes {
+				ids = append(ids, p[len(folder):len(p)-1])
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	backups := make([]models.Backup, 0, len(ids))
+	for _, id := range ids {
+		b, err := s.readRecord(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			continue
+		}
+		if err := s.fillStatus(ctx, b); err != nil {
+			return nil, err
+		}
+		backups = append(backups, *b)
+	}
+	return backups, nil
+}
+
+// fillStatus sets the status, progress and completion of b from its export operation.
+func (s *Service) fillStatus(ctx context.Context, b *models.Backup) error {
+	if b.Operation == "" {
+		b.Status = models.BackupFailed
+		if b.Error == "" {
+			// Claimed, but the instance stopped before the export started
+			b.Error = "export not started"
+		}
+		return nil
+	}
+	op := s.firestore.ExportDocumentsOperation(b.Operation)
+	_, err := op.Poll(ctx)
+	if !op.Done() && status.Code(err) == codes.NotFound {
+		// Firestore keeps finished operations for a few days only
+		exported, err := s.exists(ctx, folder+b.ID+"/"+b.ID+".overall_export_metadata")
+		if err != nil {
+			return err
+		}
+		b.Status = models.BackupUnknown
+		if exported {
+			b.Status = models.BackupCompleted
+		}
+		return nil
+	}
+	if !op.Done() && err != nil {
+		return fmt.Errorf("failed to get export operation: %w", err)
+	}
+	meta, merr := op.Metadata()
+	if merr == nil && meta != nil {
+		b.Documents = meta.GetProgressDocuments().GetCompletedWork()
+		if end := meta.GetEndTime(); end != nil {
+			t := end.AsTime()
+			b.CompletedAt = &t
+		}
+	}
+	switch {
+	case !op.Done():
+		b.Status = models.BackupRunning
+	case err != nil:
+		b.Status = models.BackupFailed
+		b.Error = err.Error()
+	default:
+		b.Status = models.BackupCompleted
+	}
+	return nil
+}
+
+// Prune deletes the backups older than the newest Config.Retain completed ones and returns
+// how many it deleted. Running backups and newer failed ones are kept.
+func (s *Service) Prune(ctx context.Context) (int, error) {
+	ctx, span := tracing.New(ctx, "backup.Prune")
+	defer span.End()
+
+	backups, err := s.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	completed, deleted := 0, 0
+	for _, b := range backups {
+		if completed < s.cfg.Retain || b.Status == models.BackupRunning {
+			if b.Status == models.BackupCompleted {
+				completed++
+			}
+			continue
+		}
+		if err := s.deleteFolder(ctx, folder+b.ID+"/"); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Check starts a scheduled backup when the newest backup that did not fail started at least
+// Config.Interval ago, then prunes. Backups of the same interval get the same ID, so at most
+// one instance starts each.
+func (s *Service) Check(ctx context.Context, now time.Time) error {
+	backups, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	due := true
+	for _, b := range backups {
+		if b.Status != models.BackupFailed {
+			due = now.Sub(b.StartedAt) >= s.cfg.Interval
+			break
+		}
+	}
+	if due {
+		_, err := s.Start(ctx, models.BackupTriggerSchedule, now.Truncate(s.cfg.Interval))
+		if err != nil && !errors.Is(err, ErrAlreadyStarted) {
+			return err
+		}
+	}
+	if n, err := s.Prune(ctx); err != nil {
+		return err
+	} else if n > 0 {
+		logging.FromContext(ctx).Info("Deleted old backups", logging.Count, n)
+	}
+	return nil
+}
+
+// Run calls Check at once and then hourly until ctx is cancelled. Failures are logged. Without
+// Config.Interval it returns at once.
+func (s *Service) Run(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.Check(ctx, time.Now().UTC()); err != nil {
+			logging.FromContext(ctx).Error("Backup schedule check failed", logging.Error, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeRecord stores the record of b. With create it fails with ErrAlreadyStarted when the
+// record exists.
+func (s *Service) writeRecord(ctx context.Context, b *models.Backup, create bool) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup: %w", err)
+	}
+	obj := &storage.Object{Name: folder + b.ID + "/" + recordName, ContentType: "application/json"}
+	call := s.storage.Objects.Insert(s.cfg.Bucket, obj).Media(bytes.NewReader(data)).Context(ctx)
+	if create {
+		call = call.IfGenerationMatch(0)
+	}
+	if _, err := call.Do(); err != nil {
+		if hasCode(err, http.StatusPreconditionFailed) {
+			return ErrAlreadyStarted
+		}
+		return fmt.Errorf("failed to write backup record: %w", err)
+	}
+	return nil
+}
+
+// readRecord returns the record of backup id, or nil for a folder without one.
+func (s *Service) readRecord(ctx context.Context, id string) (*models.Backup, error) {
+	resp, err := s.storage.Objects.Get(s.cfg.Bucket, folder+id+"/"+recordName).
+		Context(ctx).Download()
+	if err != nil {
+		if hasCode(err, http.StatusNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup record: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup record: %w", err)
+	}
+	var b models.Backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backup record %s: %w", id, err)
+	}
+	return &b, nil
+}
+
+func (s *Service) exists(ctx context.Context, name string) (bool, error) {
+	_, err := s.storage.Objects.Get(s.cfg.Bucket, name).Context(ctx).Do()
+	if hasCode(err, http.StatusNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// deleteFolder deletes all objects whose name starts with prefix.
+func (s *Service) deleteFolder(ctx context.Context, prefix string) error {
+	return s.storage.Objects.List(s.cfg.Bucket).Prefix(prefix).
+		Pages(ctx, func(objs *storage.Objects) error {
+			for _, obj := range objs.Items {
+				err := s.storage.Objects.Delete(s.cfg.Bucket, obj.Name).Context(ctx).Do()
+				if err != nil && !hasCode(err, http.StatusNotFound) {
+					return fmt.Errorf("failed to delete %s: %w", obj.Name, err)
+				}
+			}
+			return nil
+		})
+}
+
+func hasCode(err error, code int) bool {
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && gerr.Code == code
+}
@@ -0,0 +1,30 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the application version. Set at build time with
+// -ldflags "-X github.com/matti777/my-countries/backend/internal/buildinfo.Version=<version>".
+var Version = "dev"
+
+// Revision returns the VCS revision embedded by the Go toolchain, or "" when unavailable
+// (e.g. when building outside a git checkout such as in the Docker build stage).
+func Revision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// GoVersion returns the Go runtime version the binary was built with.
+func GoVersion() string {
+	return runtime.Version()
+}
@@ -0,0 +1,107 @@
+// Package cache keeps small string values in memory in front of slow lookups, such as the user
+// ID of a share token. Entries may be evicted at any time, so callers must be able to load a
+// missing value again.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache maps string keys to string values. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value of key and true, or false when key is not cached (or expired).
+	Get(key string) (string, bool)
+	Set(key, value string)
+	Delete(key string)
+}
+
+// LRU is an in-process Cache holding at most maxEntries entries, each for at most ttl. When
+// full, the least recently used entry is evicted.
+type LRU struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // front = most recently used; values are *lruEntry
+	entries map[string]*list.Element
+	hits    int64
+	misses  int64
+}
+
+type lruEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// NewLRU returns an LRU of at most maxEntries (must be positive) entries expiring ttl after
+// they were set.
+func NewLRU(maxEntries int, ttl time.Duration) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache.
+func (l *LRU) Get(key string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.entries[key]
+	if !ok {
+		l.misses++
+		return "", false
+	}
+	e := el.Value.(*lruEntry)
+	if !time.Now().Before(e.expiresAt) {
+		l.order.Remove(el)
+		delete(l.entries, key)
+		l.misses++
+		return "", false
+	}
+	l.order.MoveToFront(el)
+	l.hits++
+	return e.value, true
+}
+
+// Set implements Cache.
+func (l *LRU) Set(key, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	expiresAt := time.Now().Add(l.ttl)
+	if el, ok := l.entries[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value = value
+		e.expiresAt = expiresAt
+		l.order.MoveToFront(el)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for l.order.Len() > l.maxEntries {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Delete implements Cache.
+func (l *LRU) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.entries[key]; ok {
+		l.order.Remove(el)
+		delete(l.entries, key)
+	}
+}
+
+// Stats returns the hit and miss counts of Get since NewLRU and the number of cached entries,
+// including expired ones not yet evicted.
+func (l *LRU) Stats() (hits, misses int64, entries int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.hits, l.misses, l.order.Len()
+}
@@ -0,0 +1,50 @@
+package changelog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// changelogJSON is the API changelog, embedded at build time. Append an entry (with a bumped
+// version) whenever a change to the REST API ships.
+//
+//go:embed changelog.json
+var changelogJSON []byte
+
+var (
+	loadOnce sync.Once
+	entries  []models.ChangelogEntry
+	loadErr  error
+)
+
+func load() {
+	loadOnce.Do(func() {
+		if err := json.Unmarshal(changelogJSON, &entries); err != nil {
+			loadErr = fmt.Errorf("failed to parse embedded changelog: %w", err)
+			return
+		}
+		if len(entries) == 0 {
+			loadErr = fmt.Errorf("embedded changelog is empty")
+		}
+	})
+}
+
+// Entries returns all changelog entries, oldest first.
+func Entries() ([]models.ChangelogEntry, error) {
+	load()
+	return entries, loadErr
+}
+
+// CurrentVersion returns the API version of the newest changelog entry, or "" if the
+// embedded changelog cannot be parsed.
+func CurrentVersion() string {
+	load()
+	if loadErr != nil {
+		return ""
+	}
+	return entries[len(entries)-1].Version
+}
@@ -0,0 +1,100 @@
+// Package cluster groups visit locations for map rendering: at a given zoom level, locations
+// that would be drawn close to each other on a Web Mercator map are merged into one cluster,
+// so clients can render thousands of visits without a clustering library of their own.
+package cluster
+
+import (
+	"math"
+	"sort"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const (
+	// MaxZoom is the highest accepted zoom level (street level on common tile maps).
+	MaxZoom = 22
+
+	// tileSize is the size in pixels of one map tile; the world is tileSize*2^zoom pixels wide.
+	tileSize = 256
+
+	// cellSize is the side in pixels of a grid cell; locations in the same cell are clustered.
+	cellSize = 64
+
+	// maxMercatorLatitude is the latitude limit of the Web Mercator projection.
+	maxMercatorLatitude = 85.05112878
+)
+
+// cell is a grid cell at one zoom level.
+type cell struct {
+	x, y int64
+}
+
+// Visits clusters the located visits on a grid of cellSize pixel cells at zoom (0 shows the
+// whole world in one tile) and returns the clusters, largest first, and the number of visits
+// without a Location.
+func Visits(visits []models.CountryVisit, zoom int) ([]models.VisitCluster, int) {
+	worldSize := float64(tileSize) * math.Exp2(float64(zoom))
+
+	type group struct {
+		latSum, lngSum float64
+		visitIDs       []string
+		countries      map[string]struct{}
+	}
+	groups := make(map[cell]*group)
+	var order []cell
+	unlocated := 0
+	for _, v := range visits {
+		if v.Location == nil {
+			unlocated++
+			continue
+		}
+		x, y := project(*v.Location)
+		key := cell{x: int64(x * worldSize / cellSize), y: int64(y * worldSize / cellSize)}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{countries: make(map[string]struct{})}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.latSum += v.Location.Latitude
+		g.lngSum += v.Location.Longitude
+		g.visitIDs = append(g.visitIDs, v.ID)
+		g.countries[v.CountryCode] = struct{}{}
+	}
+
+	clusters := make([]models.VisitCluster, 0, len(groups))
+	for _, key := range order {
+		g := groups[key]
+		n := len(g.visitIDs)
+		cl := models.VisitCluster{
+			Latitude:     g.latSum / float64(n),
+			Longitude:    g.lngSum / float64(n),
+			Count:        n,
+			CountryCodes: make([]string, 0, len(g.countries)),
+		}
+		for code := range g.countries {
+			cl.CountryCodes = append(cl.CountryCodes, code)
+		}
+		sort.Strings(cl.CountryCodes)
+		if n == 1 {
+			cl.VisitID = g.visitIDs[0]
+		}
+		clusters = append(clusters, cl)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Count > clusters[j].Count })
+	return clusters, unlocated
+}
+
+// project returns the Web Mercator position of l, both coordinates in [0, 1) from the top left.
+func project(l models.Location) (float64, float64) {
+	lat := math.Max(-maxMercatorLatitude, math.Min(maxMercatorLatitude, l.Latitude))
+	sin := math.Sin(lat * math.Pi / 180)
+	x := (l.Longitude + 180) / 360
+	y := 0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)
+	return clamp(x), clamp(y)
+}
+
+// clamp keeps v within [0, 1) so longitude 180 falls in the last cell rather than a new one.
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(v, math.Nextafter(1, 0)))
+}
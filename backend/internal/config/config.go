@@ -3,7 +3,19 @@ package config
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/backup"
+	"github.com/matti777/my-countries/backend/internal/faults"
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+	"github.com/matti777/my-countries/backend/internal/mailer"
+	"github.com/matti777/my-countries/backend/internal/residency"
+	"github.com/matti777/my-countries/backend/internal/turnstile"
 )
 
 // Config holds application configuration
@@ -11,9 +23,162 @@ type Config struct {
 	ProjectID          string
 	Port               string
 	IsDebug            bool
-	FirebaseProjectID  string // optional; Firebase project ID for JWT verification (must match frontend VITE_FIREBASE_PROJECT_ID). Falls back to FIREBASE_AUDIENCE then GOOGLE_CLOUD_PROJECT.
+	FirebaseProjectID  string          // optional; Firebase project ID for JWT verification (must match frontend VITE_FIREBASE_PROJECT_ID). Falls back to FIREBASE_AUDIENCE then GOOGLE_CLOUD_PROJECT.
+	ImageCacheBucket   string          // optional; GCS bucket shared by instances for GET /img results (memory-only when empty)
+	ProofBucket        string          // optional; GCS bucket for visit proof files (PROOF_BUCKET; proof uploads respond 503 when empty)
+	ImageProxyHosts    []string        // optional; source hosts allowed by GET /img (IMAGE_PROXY_ALLOWED_HOSTS, comma-separated)
+	JSONTimeFormat     jsontime.Format // optional; default response time format (JSON_TIME_FORMAT: rfc3339 or unix)
+	AdminUserIDs       []string        // optional; users allowed on /admin routes (ADMIN_USER_IDS, comma-separated)
+	BackfillRate       float64         // optional; backfill users/second (BACKFILL_USERS_PER_SECOND, default 10)
+	OverridesRefresh   time.Duration   // optional; country overrides reload interval (COUNTRY_OVERRIDES_REFRESH, default 5m)
+	CountriesAppTokens []string        // optional; X-App-Token values required from anonymous /countries clients (COUNTRIES_APP_TOKENS, comma-separated)
+	CountriesAnonRate  float64         // optional; anonymous /countries requests per minute per IP (COUNTRIES_ANON_PER_MINUTE, default 120; 0 disables)
+	Faults             faults.Config   // optional; injected faults (FAULT_*), only with APP_ENV debug or staging
+	BatchWriteRate     float64         // optional; batch (import) Firestore writes/second per instance (BATCH_WRITES_PER_SECOND, default 50)
+	DataResidency      residency.Mode  // optional; where user data may be stored and processed (DATA_RESIDENCY: eu; unrestricted when empty)
+	AccountPurge       time.Duration   // optional; how often accounts due for deletion are purged (ACCOUNT_PURGE_INTERVAL, default 1h)
+	TTLPurge           time.Duration   // optional; how often expired documents of ephemeral collections are purged (TTL_PURGE_INTERVAL, default 6h)
+	Mail               mailer.Config   // optional; SMTP relay for outbound email (SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, MAIL_FROM); friend invitations respond 503 without SMTP_HOST
+	PublicBaseURL      string          // required with SMTP_HOST; frontend origin (and base path) for links in email, e.g. https://example.com (PUBLIC_BASE_URL)
+	MaxFriends         int             // optional; most friends per user; accepting a request beyond it responds 422 (MAX_FRIENDS, default 1000)
+	MaxVisits          int             // optional; most visits per user; creating one beyond it responds 422 (MAX_VISITS, default 10000)
+	InsightsInterval   time.Duration   // optional; how often the insights job regenerates all users' insights (INSIGHTS_INTERVAL, default 168h)
+	ShareRate          float64         // optional; public share route requests per minute per IP (SHARE_PER_MINUTE, default 60; 0 disables)
+	UserRate           float64         // optional; authenticated requests per minute per user (USER_PER_MINUTE, default 300; 0 disables)
+	PublicRate         float64         // optional; requests per minute per IP to the other public routes (PUBLIC_PER_MINUTE, default 600; 0 disables)
+
+	// ShareTokenCacheSize is the most share tokens whose user ID each instance keeps in memory
+	// (SHARE_TOKEN_CACHE_SIZE, default 10000; 0 disables), each for ShareTokenCacheTTL
+	// (SHARE_TOKEN_CACHE_TTL, default 10m).
+	ShareTokenCacheSize int
+	ShareTokenCacheTTL  time.Duration
+
+	// AuthProvider is the identity provider whose ID tokens are accepted (AUTH_PROVIDER:
+	// firebase, the default, or oidc). OIDC holds the provider settings for oidc (OIDC_ISSUER,
+	// OIDC_AUDIENCE and optionally OIDC_JWKS_URL, discovered from the issuer when unset).
+	AuthProvider string
+	OIDC         auth.OIDCConfig
+
+	// AuthEmulatorHost is the Firebase Auth emulator's host:port (FIREBASE_AUTH_EMULATOR_HOST)
+	// for local development: its unsigned tokens are trusted instead of verifying Firebase ID
+	// tokens. Only allowed with APP_ENV=debug and the firebase AuthProvider.
+	AuthEmulatorHost string
+
+	// FirestoreEmulatorHost is the Firestore emulator's host:port (FIRESTORE_EMULATOR_HOST), e.g.
+	// a docker-compose service name: the database client connects to it without credentials
+	// instead of the project's Firestore. Only allowed with APP_ENV=debug and no DATA_RESIDENCY.
+	FirestoreEmulatorHost string
+
+	// DBDriver selects the database (DB_DRIVER: firestore, the default, postgres or sqlite).
+	// DatabaseURL is the PostgreSQL connection URL (DATABASE_URL), required with postgres;
+	// SQLitePath is the database file of sqlite (SQLITE_PATH, default my-countries.db), e.g. for
+	// a single-binary install on a Raspberry Pi. SQL schemas are migrated at startup.
+	DBDriver    string
+	DatabaseURL string
+	SQLitePath  string
+
+	// DBTimeout is the deadline of each attempt of a Firestore read (DB_TIMEOUT, Go duration,
+	// default 30s; 0 disables) and DBReadRetries how often a read failing with a transient error
+	// such as Unavailable is retried with backoff (DB_READ_RETRIES, default 3; 0 disables).
+	DBTimeout     time.Duration
+	DBReadRetries int
+
+	// SessionTTL enables Firebase session cookies (POST /session) valid for this long
+	// (SESSION_COOKIE_TTL, Go duration between 5m and 336h; disabled when unset). Requires the
+	// firebase AuthProvider without the emulator.
+	SessionTTL time.Duration
+
+	// CheckRevoked enables strict auth mode (AUTH_CHECK_REVOKED=true): tokens and session
+	// cookies from sign-ins before the user's POST /me/revoke-sessions are refused, at the cost
+	// of a User document read per authenticated request.
+	CheckRevoked bool
+
+	// ReadOnly puts the API into read-only maintenance mode (READ_ONLY=true): mutating requests
+	// outside /admin get 503 and the scheduled background jobs writing data do not run.
+	ReadOnly bool
+
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxies in front of the
+	// server (TRUSTED_PROXIES, comma-separated), e.g. 169.254.0.0/16 for the Cloud Run front end.
+	// Only X-Forwarded-For entries appended by them count: the client IP keying per-IP quotas
+	// and audit events is the rightmost untrusted entry. Without any, it is the peer address
+	// and X-Forwarded-For is ignored, so clients cannot choose their IP.
+	TrustedProxies []string
+
+	// MetricsPort enables Prometheus metrics on GET /metrics of a separate listener on this port
+	// (METRICS_PORT; disabled when unset), reachable only from inside the deployment.
+	MetricsPort string
+
+	// Backup enables exports of the users collection tree to the Cloud Storage bucket
+	// BACKUP_BUCKET, every BACKUP_INTERVAL (Go duration, default 24h; 0: only via POST
+	// /admin/backup), keeping the last BACKUP_RETAIN completed ones (default 7). Requires
+	// DB_DRIVER=firestore without the emulator.
+	Backup backup.Config
+
+	// Turnstile holds the optional Cloudflare Turnstile keys (TURNSTILE_SITE_KEY,
+	// TURNSTILE_SECRET_KEY) letting clients over ShareRate continue after a bot check; both or
+	// neither must be set.
+	Turnstile turnstile.Config
 }
 
+const (
+	// defaultBackfillRate is the default BackfillRate.
+	defaultBackfillRate = 10
+
+	// defaultOverridesRefresh is the default OverridesRefresh.
+	defaultOverridesRefresh = 5 * time.Minute
+
+	// defaultCountriesAnonRate is the default CountriesAnonRate.
+	defaultCountriesAnonRate = 120
+
+	// defaultBatchWriteRate is the default BatchWriteRate.
+	defaultBatchWriteRate = 50
+
+	// defaultAccountPurge is the default AccountPurge.
+	defaultAccountPurge = time.Hour
+
+	// defaultTTLPurge is the default TTLPurge.
+	defaultTTLPurge = 6 * time.Hour
+
+	// defaultSMTPPort is the default Mail.Port (submission with STARTTLS).
+	defaultSMTPPort = 587
+
+	// defaultMaxFriends is the default MaxFriends.
+	defaultMaxFriends = 1000
+
+	// defaultMaxVisits is the default MaxVisits.
+	defaultMaxVisits = 10000
+
+	// defaultInsightsInterval is the default InsightsInterval (weekly).
+	defaultInsightsInterval = 7 * 24 * time.Hour
+
+	// defaultShareRate is the default ShareRate.
+	defaultShareRate = 60
+
+	// defaultUserRate is the default UserRate.
+	defaultUserRate = 300
+
+	// defaultPublicRate is the default PublicRate; a page may proxy many images through GET /img.
+	defaultPublicRate = 600
+
+	// defaultShareTokenCacheSize is the default ShareTokenCacheSize.
+	defaultShareTokenCacheSize = 10000
+
+	// defaultShareTokenCacheTTL is the default ShareTokenCacheTTL.
+	defaultShareTokenCacheTTL = 10 * time.Minute
+
+	// defaultDBTimeout is the default DBTimeout; long enough for account merges.
+	defaultDBTimeout = 30 * time.Second
+
+	// defaultDBReadRetries is the default DBReadRetries.
+	defaultDBReadRetries = 3
+
+	// defaultBackupInterval is the default Backup.Interval.
+	defaultBackupInterval = 24 * time.Hour
+
+	// defaultBackupRetain is the default Backup.Retain.
+	defaultBackupRetain = 7
+)
+
 // Load loads configuration from environment variables
 func Load(ctx context.Context) (*Config, error) {
 	projectID := os.Getenv("GOOGLE_CLOUD_PROJECT")
@@ -31,7 +196,8 @@ func Load(ctx context.Context) (*Config, error) {
 
 	// Determine if we're in debug/local mode
 	// If APP_ENV=debug or GOOGLE_CLOUD_PROJECT is not set, we're in debug mode
-	isDebug := os.Getenv("APP_ENV") == "debug" || projectID == ""
+	appEnv := os.Getenv("APP_ENV")
+	isDebug := appEnv == "debug" || projectID == ""
 
 	// Firebase project ID for token verification: same as frontend's VITE_FIREBASE_PROJECT_ID when backend runs under a different GCP project
 	firebaseProjectID := os.Getenv("FIREBASE_PROJECT_ID")
@@ -39,10 +205,471 @@ func Load(ctx context.Context) (*Config, error) {
 		firebaseProjectID = os.Getenv("FIREBASE_AUDIENCE")
 	}
 
+	var imageProxyHosts []string
+	for _, h := range strings.Split(os.Getenv("IMAGE_PROXY_ALLOWED_HOSTS"), ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			imageProxyHosts = append(imageProxyHosts, h)
+		}
+	}
+
+	jsonTimeFormat := jsontime.RFC3339
+	if raw := os.Getenv("JSON_TIME_FORMAT"); raw != "" {
+		f, err := jsontime.ParseFormat(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON_TIME_FORMAT: %w", err)
+		}
+		jsonTimeFormat = f
+	}
+
+	var adminUserIDs []string
+	for _, id := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			adminUserIDs = append(adminUserIDs, id)
+		}
+	}
+
+	backfillRate := float64(defaultBackfillRate)
+	if raw := os.Getenv("BACKFILL_USERS_PER_SECOND"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid BACKFILL_USERS_PER_SECOND %q: must be a positive number", raw)
+		}
+		backfillRate = v
+	}
+
+	overridesRefresh := defaultOverridesRefresh
+	if raw := os.Getenv("COUNTRY_OVERRIDES_REFRESH"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid COUNTRY_OVERRIDES_REFRESH %q: must be a positive duration", raw)
+		}
+		overridesRefresh = v
+	}
+
+	accountPurge := defaultAccountPurge
+	if raw := os.Getenv("ACCOUNT_PURGE_INTERVAL"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid ACCOUNT_PURGE_INTERVAL %q: must be a positive duration", raw)
+		}
+		accountPurge = v
+	}
+
+	ttlPurge := defaultTTLPurge
+	if raw := os.Getenv("TTL_PURGE_INTERVAL"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid TTL_PURGE_INTERVAL %q: must be a positive duration", raw)
+		}
+		ttlPurge = v
+	}
+
+	insightsInterval := defaultInsightsInterval
+	if raw := os.Getenv("INSIGHTS_INTERVAL"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid INSIGHTS_INTERVAL %q: must be a positive duration", raw)
+		}
+		insightsInterval = v
+	}
+
+	var countriesAppTokens []string
+	for _, t := range strings.Split(os.Getenv("COUNTRIES_APP_TOKENS"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			countriesAppTokens = append(countriesAppTokens, t)
+		}
+	}
+
+	countriesAnonRate := float64(defaultCountriesAnonRate)
+	if raw := os.Getenv("COUNTRIES_ANON_PER_MINUTE"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid COUNTRIES_ANON_PER_MINUTE %q: must be a non-negative number", raw)
+		}
+		countriesAnonRate = v
+	}
+
+	batchWriteRate := float64(defaultBatchWriteRate)
+	if raw := os.Getenv("BATCH_WRITES_PER_SECOND"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid BATCH_WRITES_PER_SECOND %q: must be a positive number", raw)
+		}
+		batchWriteRate = v
+	}
+
+	maxFriends := defaultMaxFriends
+	if raw := os.Getenv("MAX_FRIENDS"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid MAX_FRIENDS %q: must be a positive integer", raw)
+		}
+		maxFriends = v
+	}
+
+	maxVisits := defaultMaxVisits
+	if raw := os.Getenv("MAX_VISITS"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid MAX_VISITS %q: must be a positive integer", raw)
+		}
+		maxVisits = v
+	}
+
+	shareRate := float64(defaultShareRate)
+	if raw := os.Getenv("SHARE_PER_MINUTE"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid SHARE_PER_MINUTE %q: must be a non-negative number", raw)
+		}
+		shareRate = v
+	}
+
+	userRate := float64(defaultUserRate)
+	if raw := os.Getenv("USER_PER_MINUTE"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid USER_PER_MINUTE %q: must be a non-negative number", raw)
+		}
+		userRate = v
+	}
+
+	publicRate := float64(defaultPublicRate)
+	if raw := os.Getenv("PUBLIC_PER_MINUTE"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid PUBLIC_PER_MINUTE %q: must be a non-negative number", raw)
+		}
+		publicRate = v
+	}
+
+	shareTokenCacheSize := defaultShareTokenCacheSize
+	if raw := os.Getenv("SHARE_TOKEN_CACHE_SIZE"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid SHARE_TOKEN_CACHE_SIZE %q: must be a non-negative integer", raw)
+		}
+		shareTokenCacheSize = v
+	}
+
+	shareTokenCacheTTL := defaultShareTokenCacheTTL
+	if raw := os.Getenv("SHARE_TOKEN_CACHE_TTL"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid SHARE_TOKEN_CACHE_TTL %q: must be a positive duration", raw)
+		}
+		shareTokenCacheTTL = v
+	}
+
+	dbTimeout := defaultDBTimeout
+	if raw := os.Getenv("DB_TIMEOUT"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid DB_TIMEOUT %q: must be a non-negative duration", raw)
+		}
+		dbTimeout = v
+	}
+
+	dbReadRetries := defaultDBReadRetries
+	if raw := os.Getenv("DB_READ_RETRIES"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid DB_READ_RETRIES %q: must be a non-negative integer", raw)
+		}
+		dbReadRetries = v
+	}
+	turnstileCfg := turnstile.Config{
+		SiteKey:   os.Getenv("TURNSTILE_SITE_KEY"),
+		SecretKey: os.Getenv("TURNSTILE_SECRET_KEY"),
+	}
+	if (turnstileCfg.SiteKey == "") != (turnstileCfg.SecretKey == "") {
+		return nil, fmt.Errorf("TURNSTILE_SITE_KEY and TURNSTILE_SECRET_KEY must be set together")
+	}
+
+	faultsCfg, err := loadFaults()
+	if err != nil {
+		return nil, err
+	}
+	if faultsCfg.Enabled() && appEnv != "debug" && appEnv != "staging" {
+		return nil, fmt.Errorf("FAULT_* settings require APP_ENV=debug or APP_ENV=staging")
+	}
+
+	dataResidency, err := residency.ParseMode(os.Getenv("DATA_RESIDENCY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DATA_RESIDENCY: %w", err)
+	}
+
+	authProvider, oidcCfg, err := loadAuthProvider()
+	if err != nil {
+		return nil, err
+	}
+	authEmulatorHost := os.Getenv("FIREBASE_AUTH_EMULATOR_HOST")
+	if authEmulatorHost != "" && (appEnv != "debug" || authProvider != AuthProviderFirebase) {
+		return nil, fmt.Errorf("FIREBASE_AUTH_EMULATOR_HOST requires APP_ENV=debug and " +
+			"AUTH_PROVIDER=firebase")
+	}
+
+	firestoreEmulatorHost := os.Getenv("FIRESTORE_EMULATOR_HOST")
+	if firestoreEmulatorHost != "" && (appEnv != "debug" || dataResidency.Restricted()) {
+		return nil, fmt.Errorf("FIRESTORE_EMULATOR_HOST requires APP_ENV=debug and no " +
+			"DATA_RESIDENCY")
+	}
+
+	dbDriver := os.Getenv("DB_DRIVER")
+	if dbDriver == "" {
+		dbDriver = DBDriverFirestore
+	}
+	databaseURL := os.Getenv("DATABASE_URL")
+	sqlitePath := os.Getenv("SQLITE_PATH")
+	if sqlitePath == "" {
+		sqlitePath = "my-countries.db"
+	}
+	switch dbDriver {
+	case DBDriverFirestore:
+	case DBDriverPostgres, DBDriverSQLite:
+		if dbDriver == DBDriverPostgres && databaseURL == "" {
+			return nil, fmt.Errorf("DATABASE_URL is required with DB_DRIVER=postgres")
+		}
+		if firestoreEmulatorHost != "" {
+			return nil, fmt.Errorf("FIRESTORE_EMULATOR_HOST requires DB_DRIVER=firestore")
+		}
+	default:
+		return nil, fmt.Errorf("invalid DB_DRIVER %q: must be firestore, postgres or sqlite",
+			dbDriver)
+	}
+
+	backupCfg, err := loadBackup()
+	if err != nil {
+		return nil, err
+	}
+	if backupCfg.Enabled() && (dbDriver != DBDriverFirestore || firestoreEmulatorHost != "") {
+		return nil, fmt.Errorf("BACKUP_BUCKET requires DB_DRIVER=firestore and no " +
+			"FIRESTORE_EMULATOR_HOST")
+	}
+
+	var sessionTTL time.Duration
+	if raw := os.Getenv("SESSION_COOKIE_TTL"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v < auth.MinSessionDuration || v > auth.MaxSessionDuration {
+			return nil, fmt.Errorf("invalid SESSION_COOKIE_TTL %q: must be a duration between "+
+				"5m and 336h", raw)
+		}
+		if authProvider != AuthProviderFirebase || authEmulatorHost != "" {
+			return nil, fmt.Errorf("SESSION_COOKIE_TTL requires AUTH_PROVIDER=firebase without " +
+				"FIREBASE_AUTH_EMULATOR_HOST")
+		}
+		sessionTTL = v
+	}
+
+	checkRevoked := false
+	if raw := os.Getenv("AUTH_CHECK_REVOKED"); raw != "" {
+		if checkRevoked, err = strconv.ParseBool(raw); err != nil {
+			return nil, fmt.Errorf("invalid AUTH_CHECK_REVOKED %q: must be true or false", raw)
+		}
+	}
+
+	readOnly := false
+	if raw := os.Getenv("READ_ONLY"); raw != "" {
+		if readOnly, err = strconv.ParseBool(raw); err != nil {
+			return nil, fmt.Errorf("invalid READ_ONLY %q: must be true or false", raw)
+		}
+	}
+
+	var trustedProxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: must be an IP or CIDR", p)
+		}
+		trustedProxies = append(trustedProxies, p)
+	}
+
+	metricsPort := os.Getenv("METRICS_PORT")
+	if metricsPort != "" {
+		if v, err := strconv.Atoi(metricsPort); err != nil || v <= 0 || v > 65535 {
+			return nil, fmt.Errorf("invalid METRICS_PORT %q: must be a port number", metricsPort)
+		}
+		if metricsPort == port {
+			return nil, fmt.Errorf("METRICS_PORT must differ from PORT %s", port)
+		}
+	}
+
+	mailCfg, err := loadMail()
+	if err != nil {
+		return nil, err
+	}
+	publicBaseURL := strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/")
+	if mailCfg.Enabled() && publicBaseURL == "" {
+		return nil, fmt.Errorf("PUBLIC_BASE_URL is required with SMTP_HOST")
+	}
+
 	return &Config{
-		ProjectID:         projectID,
-		Port:              port,
-		IsDebug:           isDebug,
-		FirebaseProjectID: firebaseProjectID,
+		ProjectID:          projectID,
+		Port:               port,
+		IsDebug:            isDebug,
+		FirebaseProjectID:  firebaseProjectID,
+		ImageCacheBucket:   os.Getenv("IMAGE_CACHE_BUCKET"),
+		ProofBucket:        os.Getenv("PROOF_BUCKET"),
+		ImageProxyHosts:    imageProxyHosts,
+		JSONTimeFormat:     jsonTimeFormat,
+		AdminUserIDs:       adminUserIDs,
+		BackfillRate:       backfillRate,
+		OverridesRefresh:   overridesRefresh,
+		CountriesAppTokens: countriesAppTokens,
+		CountriesAnonRate:  countriesAnonRate,
+		BatchWriteRate:     batchWriteRate,
+		Faults:             faultsCfg,
+		DataResidency:      dataResidency,
+		AccountPurge:       accountPurge,
+		TTLPurge:           ttlPurge,
+		Mail:               mailCfg,
+		PublicBaseURL:      publicBaseURL,
+		MaxFriends:         maxFriends,
+		MaxVisits:          maxVisits,
+		InsightsInterval:   insightsInterval,
+		ShareRate:          shareRate,
+		UserRate:           userRate,
+		PublicRate:         publicRate,
+		Turnstile:          turnstileCfg,
+		AuthProvider:       authProvider,
+		OIDC:               oidcCfg,
+		AuthEmulatorHost:   authEmulatorHost,
+		SessionTTL:         sessionTTL,
+		CheckRevoked:       checkRevoked,
+		ReadOnly:           readOnly,
+		MetricsPort:        metricsPort,
+		TrustedProxies:     trustedProxies,
+
+		FirestoreEmulatorHost: firestoreEmulatorHost,
+		DBDriver:              dbDriver,
+		DatabaseURL:           databaseURL,
+		SQLitePath:            sqlitePath,
+		DBTimeout:             dbTimeout,
+		DBReadRetries:         dbReadRetries,
+		Backup:                backupCfg,
+		ShareTokenCacheSize:   shareTokenCacheSize,
+		ShareTokenCacheTTL:    shareTokenCacheTTL,
 	}, nil
 }
+
+// DBDriver values.
+const (
+	DBDriverFirestore = "firestore"
+	DBDriverPostgres  = "postgres"
+	DBDriverSQLite    = "sqlite"
+)
+
+// AuthProvider values.
+const (
+	AuthProviderFirebase = "firebase"
+	AuthProviderOIDC     = "oidc"
+)
+
+// loadAuthProvider reads AUTH_PROVIDER (default firebase) and, for oidc, OIDC_ISSUER and
+// OIDC_AUDIENCE (required) and OIDC_JWKS_URL.
+func loadAuthProvider() (string, auth.OIDCConfig, error) {
+	provider := strings.ToLower(os.Getenv("AUTH_PROVIDER"))
+	switch provider {
+	case "", AuthProviderFirebase:
+		return AuthProviderFirebase, auth.OIDCConfig{}, nil
+	case AuthProviderOIDC:
+	default:
+		return "", auth.OIDCConfig{},
+			fmt.Errorf("invalid AUTH_PROVIDER %q: must be firebase or oidc", provider)
+	}
+	cfg := auth.OIDCConfig{
+		Issuer:   os.Getenv("OIDC_ISSUER"),
+		Audience: os.Getenv("OIDC_AUDIENCE"),
+		JWKSURL:  os.Getenv("OIDC_JWKS_URL"),
+	}
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return "", cfg, fmt.Errorf("OIDC_ISSUER and OIDC_AUDIENCE are required with " +
+			"AUTH_PROVIDER=oidc")
+	}
+	return provider, cfg, nil
+}
+
+// loadMail reads SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME, SMTP_PASSWORD and
+// MAIL_FROM. The rest is ignored without SMTP_HOST; with it MAIL_FROM is required.
+func loadMail() (mailer.Config, error) {
+	cfg := mailer.Config{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     defaultSMTPPort,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+	if !cfg.Enabled() {
+		return mailer.Config{}, nil
+	}
+	if raw := os.Getenv("SMTP_PORT"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 || v > 65535 {
+			return cfg, fmt.Errorf("invalid SMTP_PORT %q: must be a port number", raw)
+		}
+		cfg.Port = v
+	}
+	if cfg.From == "" {
+		return cfg, fmt.Errorf("MAIL_FROM is required with SMTP_HOST")
+	}
+	return cfg, nil
+}
+
+// loadBackup reads BACKUP_BUCKET, BACKUP_INTERVAL and BACKUP_RETAIN.
+func loadBackup() (backup.Config, error) {
+	cfg := backup.Config{
+		Bucket:   os.Getenv("BACKUP_BUCKET"),
+		Interval: defaultBackupInterval,
+		Retain:   defaultBackupRetain,
+	}
+	if raw := os.Getenv("BACKUP_INTERVAL"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v < 0 {
+			return cfg, fmt.Errorf("invalid BACKUP_INTERVAL %q: must be a non-negative duration", raw)
+		}
+		cfg.Interval = v
+	}
+	if raw := os.Getenv("BACKUP_RETAIN"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return cfg, fmt.Errorf("invalid BACKUP_RETAIN %q: must be a positive integer", raw)
+		}
+		cfg.Retain = v
+	}
+	return cfg, nil
+}
+
+// loadFaults reads FAULT_LATENCY (Go duration), FAULT_LATENCY_PERCENT and
+// FAULT_FIRESTORE_ERROR_PERCENT (0-100). FAULT_LATENCY without a percent delays every request.
+func loadFaults() (faults.Config, error) {
+	var cfg faults.Config
+	if raw := os.Getenv("FAULT_LATENCY"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil || v < 0 {
+			return cfg, fmt.Errorf("invalid FAULT_LATENCY %q: must be a non-negative duration", raw)
+		}
+		cfg.Latency = v
+		cfg.LatencyRate = 1
+	}
+	for _, p := range []struct {
+		name string
+		rate *float64
+	}{
+		{"FAULT_LATENCY_PERCENT", &cfg.LatencyRate},
+		{"FAULT_FIRESTORE_ERROR_PERCENT", &cfg.FirestoreErrorRate},
+	} {
+		raw := os.Getenv(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v > 100 {
+			return cfg, fmt.Errorf("invalid %s %q: must be a number from 0 to 100", p.name, raw)
+		}
+		*p.rate = v / 100
+	}
+	return cfg, nil
+}
@@ -17,7 +17,7 @@ type contextKey int
 const (
 	_ contextKey = iota
 	// CurrentUserKey stores the authenticated *models.User in request context.
-	// Used by auth middleware and read by handlers and logging.
+	// Set and read through WithCurrentUser, CurrentUser and MustCurrentUser.
 	CurrentUserKey
 )
 
@@ -34,4 +34,16 @@ const (
 
 	// TraceContextKey stores parsed Traceparent (trace ID, span ID) for connecting logs to request trace
 	TraceContextKey Key = "trace_context"
+
+	// FeaturePreviewKey stores the features.Set enabled by a tester via X-Feature-Preview
+	FeaturePreviewKey Key = "feature_preview"
+
+	// DryRunKey stores true when the request asked for ?dryRun=true (no database writes)
+	DryRunKey Key = "dry_run"
+
+	// JSONTimeFormatKey stores the jsontime.Format negotiated for the response
+	JSONTimeFormatKey Key = "json_time_format"
+
+	// RequestIDKey stores the request's ID string (X-Request-ID), accepted or generated
+	RequestIDKey Key = "request_id"
 )
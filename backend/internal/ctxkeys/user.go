@@ -0,0 +1,29 @@
+package ctxkeys
+
+import (
+	"context"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// WithCurrentUser returns a context carrying the authenticated user under CurrentUserKey.
+func WithCurrentUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, CurrentUserKey, user)
+}
+
+// CurrentUser returns the authenticated user stored by WithCurrentUser, or false when the
+// request is unauthenticated.
+func CurrentUser(ctx context.Context) (*models.User, bool) {
+	user, _ := ctx.Value(CurrentUserKey).(*models.User)
+	return user, user != nil
+}
+
+// MustCurrentUser returns the authenticated user and panics when there is none. Only use it in
+// handlers registered with server.RequireUser, where a missing user is a programming error.
+func MustCurrentUser(ctx context.Context) *models.User {
+	user, ok := CurrentUser(ctx)
+	if !ok {
+		panic("ctxkeys: no current user in context")
+	}
+	return user
+}
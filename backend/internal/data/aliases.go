@@ -0,0 +1,260 @@
+package data
+
+// Aliases maps a CountryCode to alternative names used to resolve free-text country names
+// (search, imports): endonyms, former or common English names and German, French, Spanish
+// and Finnish exonyms. Names are matched after textnorm.Fold, so variants that differ only
+// in case, diacritics or apostrophes ("Türkiye" / "Turkiye") need to be listed only once.
+var Aliases = map[string][]string{
+	"AF": {"Afganistan", "Afghanistan Islamic Emirate", "Afganistán"},
+	"AL": {"Shqipëria", "Albanien", "Albanie", "Албания"},
+	"DZ": {"Algérie", "Algerien", "Argelia", "Al Jazair"},
+	"AD": {"Andorre"},
+	"AO": {"Angóla"},
+	"AG": {"Antigua", "Antigua & Barbuda", "Antigua und Barbuda", "Antigua-et-Barbuda", "Antigua y Barbuda"},
+	"AR": {"Argentinien", "Argentine", "Argentiina"},
+	"AM": {"Hayastan", "Armenien", "Arménie"},
+	"AU": {"Australien", "Australie", "Aussie"},
+	"AT": {"Österreich", "Autriche", "Itävalta"},
+	"AZ": {"Azərbaycan", "Aserbaidschan", "Azerbaïdjan", "Azerbaiyán"},
+	"BS": {"The Bahamas", "Bahamas Islands"},
+	"BH": {"Bahrein", "Bahreïn"},
+	"BD": {"Bangladesch"},
+	"BB": {"Barbade"},
+	"BY": {
+		"Belarus", "Belorussia", "Byelorussia", "Weißrussland", "Biélorussie", "Bielorrusia",
+		"Valko-Venäjä",
+	},
+	"BE": {"België", "Belgique", "Belgien", "Bélgica", "Belgia"},
+	"BZ": {"Bélize", "British Honduras"},
+	"BJ": {"Bénin", "Dahomey"},
+	"BT": {"Druk Yul", "Bhoutan", "Bután"},
+	"BO": {"Bolivien", "Bolivie", "Plurinational State of Bolivia"},
+	"BA": {
+		"Bosnia", "Bosna i Hercegovina", "Bosnien und Herzegowina", "Bosnie-Herzégovine",
+		"Bosnia y Herzegovina", "Bosnia ja Hertsegovina",
+	},
+	"BW": {"Botsuana"},
+	"BR": {"Brasil", "Brasilien", "Brésil", "Brasilia"},
+	"BN": {"Brunei Darussalam", "Brunéi"},
+	"BG": {"Bulgarien", "Bulgarie", "Bulgaria", "България"},
+	"BF": {"Upper Volta", "Haute-Volta"},
+	"BI": {"Urundi"},
+	"CV": {"Cape Verde", "Kap Verde", "Cap-Vert", "Cabo Verde Islands", "Kap Verde Inseln"},
+	"KH": {"Kampuchea", "Kambodscha", "Cambodge", "Camboya", "Kambodža"},
+	"CM": {"Kamerun", "Cameroun", "Camerún"},
+	"CA": {"Kanada"},
+	"CF": {
+		"CAR", "Zentralafrikanische Republik", "République centrafricaine",
+		"República Centroafricana", "Keski-Afrikan tasavalta",
+	},
+	"TD": {"Tschad", "Tchad", "Chad Republic", "Tšad"},
+	"CL": {"Chili"},
+	"CN": {"Zhongguo", "People's Republic of China", "PRC", "Chine", "Kiina"},
+	"CO": {"Kolumbien", "Colombie", "Kolumbia"},
+	"KM": {"Komoren", "Comores", "Komorit"},
+	"CG": {
+		"Republic of the Congo", "Congo-Brazzaville", "Kongo", "Republik Kongo",
+		"République du Congo",
+	},
+	"CD": {
+		"DRC", "DR Congo", "Democratic Republic of the Congo", "Congo-Kinshasa", "Zaire",
+		"Demokratische Republik Kongo", "République démocratique du Congo",
+		"Kongon demokraattinen tasavalta",
+	},
+	"CR": {"Kostarika"},
+	"HR": {"Hrvatska", "Kroatien", "Croatie", "Croacia", "Kroatia"},
+	"CU": {"Kuba"},
+	"CY": {"Kypros", "Kıbrıs", "Zypern", "Chypre", "Chipre"},
+	"CZ": {
+		"Czechia", "Česko", "Česká republika", "Tschechien", "Tchéquie", "República Checa",
+		"Chequia", "Tšekki",
+	},
+	"DK": {"Danmark", "Dänemark", "Danemark", "Dinamarca", "Tanska"},
+	"DJ": {"Dschibuti", "Yibuti"},
+	"DM": {"Dominique"},
+	"DO": {
+		"República Dominicana", "Dominikanische Republik", "République dominicaine",
+		"Dominikaaninen tasavalta",
+	},
+	"EC": {"Équateur", "Ecuador Republic"},
+	"EG": {"Misr", "Ägypten", "Égypte", "Egipto", "Egypti"},
+	"SV": {"Salvador"},
+	"GQ": {"Guinea Ecuatorial", "Äquatorialguinea", "Guinée équatoriale", "Päiväntasaajan Guinea"},
+	"ER": {"Érythrée"},
+	"EE": {"Eesti", "Estland", "Estonie", "Viro"},
+	"SZ": {"Swaziland", "eSwatini", "Swasiland"},
+	"ET": {"Äthiopien", "Éthiopie", "Etiopía", "Etiopia", "Abyssinia"},
+	"FJ": {"Fidschi", "Fidji", "Fiyi", "Fidži"},
+	"FI": {"Suomi", "Finnland", "Finlande", "Finlandia"},
+	"FR": {"Frankreich", "Francia", "Ranska"},
+	"GA": {"Gabun", "Gabón"},
+	"GM": {"The Gambia", "Gambie"},
+	"GE": {"Sakartvelo", "Georgien", "Géorgie", "Gruusia"},
+	"DE": {"Deutschland", "Allemagne", "Alemania", "Saksa"},
+	"GH": {"Gold Coast"},
+	"GR": {"Hellas", "Ellada", "Ελλάδα", "Griechenland", "Grèce", "Grecia", "Kreikka"},
+	"GD": {"Grenade"},
+	"GN": {"Guinée", "Guinea-Conakry"},
+	"GW": {"Guinée-Bissau", "Guinea Bissau"},
+	"GY": {"Guyane", "British Guiana"},
+	"HT": {"Haïti", "Haití"},
+	"HN": {"Honduras Republic"},
+	"HU": {"Magyarország", "Ungarn", "Hongrie", "Hungría", "Unkari"},
+	"IS": {"Ísland", "Island", "Islande", "Islandia", "Islanti"},
+	"IN": {"Bharat", "Indien", "Inde", "Intia"},
+	"ID": {"Indonesien", "Indonésie", "Indonesia Republic"},
+	"IR": {"Persia", "Islamic Republic of Iran", "Irán"},
+	"IQ": {"Irak"},
+	"IE": {"Éire", "Eire", "Irland", "Irlande", "Irlanda", "Irlanti"},
+	"IL": {"Yisrael", "Israël"},
+	"IT": {"Italia", "Italien", "Italie"},
+	"CI": {"Côte d'Ivoire", "Elfenbeinküste", "Costa de Marfil", "Norsunluurannikko"},
+	"JM": {"Jamaïque", "Jamaika"},
+	"JP": {"Nippon", "Nihon", "Japan", "Japon", "Japón", "Japani"},
+	"JO": {"Jordanien", "Jordanie", "Jordania"},
+	"KZ": {"Qazaqstan", "Kasachstan", "Kazakhstan Republic", "Kazajistán", "Kazakstan"},
+	"KE": {"Kenia"},
+	"KI": {"Gilbert Islands"},
+	"KP": {
+		"DPRK", "Democratic People's Republic of Korea", "Nordkorea", "Corée du Nord",
+		"Corea del Norte", "Pohjois-Korea",
+	},
+	"KR": {
+		"Korea", "Republic of Korea", "Korea, Republic of", "Hanguk", "Südkorea", "Corée du Sud", "Corea del Sur",
+		"Etelä-Korea",
+	},
+	"KW": {"Koweït", "Kuwaitin"},
+	"KG": {"Kirgisistan", "Kirghizistan", "Kirguistán", "Kirgisia", "Kyrgyz Republic"},
+	"LA": {"Lao", "Lao PDR", "Lao People's Democratic Republic"},
+	"LV": {"Latvija", "Lettland", "Lettonie", "Letonia", "Latvia"},
+	"LB": {"Lubnan", "Libanon", "Liban", "Líbano"},
+	"LS": {"Basutoland"},
+	"LR": {"Libéria"},
+	"LY": {"Libyen", "Libye", "Libia"},
+	"LI": {"Liechtenstein Principality"},
+	"LT": {"Lietuva", "Litauen", "Lituanie", "Lituania", "Liettua"},
+	"LU": {"Lëtzebuerg", "Luxemburg", "Luxemburgo"},
+	"MO": {"Macao", "Aomen"},
+	"MG": {"Madagaskar"},
+	"MW": {"Nyasaland"},
+	"MY": {"Malaisie", "Malasia", "Malesia"},
+	"MV": {"Maldiven", "Maldives Islands", "Malediven", "Maldivas", "Malediivit"},
+	"ML": {"Malí"},
+	"MT": {"Malte"},
+	"MH": {"Marshallinseln", "Îles Marshall", "Islas Marshall", "Marshallinsaaret"},
+	"MR": {"Mauretanien", "Mauritanie"},
+	"MU": {"Maurice", "Mauricio"},
+	"MX": {"México", "Mexiko", "Mexique", "Meksiko"},
+	"FM": {"Federated States of Micronesia", "Mikronesien", "Micronésie", "Mikronesia"},
+	"MD": {"Moldawien", "Moldavie", "Moldavia", "Moldova Republic"},
+	"MC": {"Monaco Principality", "Mónaco"},
+	"MN": {"Mongolei", "Mongolie", "Mongolia"},
+	"ME": {"Crna Gora", "Monténégro", "Montenegro Republic"},
+	"MA": {"Maroc", "Marokko", "Marruecos", "Al Maghrib"},
+	"MZ": {"Mosambik"},
+	"MM": {"Burma", "Birma", "Birmanie", "Birmania"},
+	"NA": {"Namibie", "South West Africa"},
+	"NR": {"Naoero"},
+	"NP": {"Népal"},
+	"NL": {
+		"Holland", "The Netherlands", "Nederland", "Niederlande", "Pays-Bas", "Países Bajos", "Alankomaat",
+		"Hollanti",
+	},
+	"NZ": {"Aotearoa", "Neuseeland", "Nouvelle-Zélande", "Nueva Zelanda", "Uusi-Seelanti"},
+	"NI": {"Nicaragua Republic"},
+	"NE": {"Níger"},
+	"NG": {"Nigéria"},
+	"MK": {
+		"Macedonia", "Makedonija", "Nordmazedonien", "Macédoine du Nord", "Macedonia del Norte",
+		"Pohjois-Makedonia",
+	},
+	"NO": {"Norge", "Noreg", "Norwegen", "Norvège", "Noruega", "Norja"},
+	"OM": {"Oman Sultanate", "Omán"},
+	"PK": {"Pakistán"},
+	"PW": {"Belau", "Palaos"},
+	"PA": {"Panamá"},
+	"PG": {
+		"PNG", "Papua-Neuguinea", "Papouasie-Nouvelle-Guinée", "Papúa Nueva Guinea",
+		"Papua-Uusi-Guinea",
+	},
+	"PY": {"Paraguái"},
+	"PE": {"Perú", "Pérou"},
+	"PH": {"Pilipinas", "Philippinen", "Philippines", "Filipinas", "Filippiinit"},
+	"PL": {"Polska", "Polen", "Pologne", "Polonia", "Puola"},
+	"PT": {"Portugal Republic", "Portugali"},
+	"QA": {"Katar"},
+	"RO": {"România", "Rumänien", "Roumanie", "Rumania", "Romania"},
+	"RU": {"Rossiya", "Russian Federation", "Россия", "Russland", "Russie", "Rusia", "Venäjä"},
+	"RW": {"Ruanda"},
+	"KN": {
+		"St Kitts and Nevis", "St. Kitts", "Saint Kitts", "Saint-Christophe-et-Niévès",
+		"San Cristóbal y Nieves",
+	},
+	"LC": {"St Lucia", "Sainte-Lucie", "Santa Lucía"},
+	"VC": {
+		"St Vincent", "Saint Vincent", "Saint-Vincent-et-les-Grenadines",
+		"San Vicente y las Granadinas",
+	},
+	"WS": {"Western Samoa", "Samoa i Sisifo"},
+	"SM": {"Saint-Marin"},
+	"ST": {"São Tomé and Príncipe", "Sao Tome", "São Tomé e Príncipe", "Santo Tomé y Príncipe"},
+	"SA": {"KSA", "Saudi-Arabien", "Arabie saoudite", "Arabia Saudita", "Saudi-Arabia"},
+	"SN": {"Sénégal"},
+	"RS": {"Srbija", "Serbien", "Serbie", "Serbia"},
+	"SC": {"Seychellen", "Seychelles Islands"},
+	"SL": {"Sierra Leona"},
+	"SG": {"Singapur", "Singapour", "Singapura"},
+	"SK": {"Slovak Republic", "Slovensko", "Slowakei", "Slovaquie", "Eslovaquia", "Slovakia"},
+	"SI": {"Slovenija", "Slowenien", "Slovénie", "Eslovenia", "Slovenia"},
+	"SB": {"Salomonen", "Îles Salomon", "Islas Salomón", "Salomonsaaret"},
+	"SO": {"Soomaaliya", "Somalie"},
+	"ZA": {"RSA", "Südafrika", "Afrique du Sud", "Sudáfrica", "Etelä-Afrikka", "Suid-Afrika"},
+	"SS": {"Südsudan", "Soudan du Sud", "Sudán del Sur", "Etelä-Sudan"},
+	"ES": {"España", "Spanien", "Espagne", "Espanja"},
+	"LK": {"Ceylon", "Sri Lanka Republic"},
+	"SD": {"Soudan", "Sudán"},
+	"SR": {"Surinam", "Dutch Guiana"},
+	"SE": {"Sverige", "Schweden", "Suède", "Suecia", "Ruotsi"},
+	"CH": {"Schweiz", "Suisse", "Svizzera", "Svizra", "Suiza", "Sveitsi", "Helvetia"},
+	"SY": {"Syrien", "Syrie", "Siria", "Syyria"},
+	"TW": {"Republic of China", "ROC", "Chinese Taipei", "Formosa", "Taïwan", "Taiwán"},
+	"TJ": {"Tadschikistan", "Tadjikistan", "Tayikistán", "Tadžikistan"},
+	"TZ": {"Tansania", "Tanzanie"},
+	"TH": {"Thailand", "Prathet Thai", "Siam", "Thaïlande", "Tailandia", "Thaimaa"},
+	"TL": {"East Timor", "Timor Leste", "Osttimor", "Timor oriental", "Itä-Timor"},
+	"TG": {"Togolese Republic"},
+	"TO": {"Tongan"},
+	"TT": {"Trinidad", "Trinidad & Tobago", "Trinité-et-Tobago", "Trinidad y Tobago"},
+	"TN": {"Tunesien", "Tunisie", "Túnez", "Tunisia"},
+	"TR": {"Türkiye", "Türkei", "Turquie", "Turquía", "Turkki"},
+	"TM": {"Türkmenistan", "Turkménistan", "Turkmenistán"},
+	"TV": {"Ellice Islands"},
+	"UG": {"Ouganda"},
+	"UA": {"Ukraina", "Україна", "The Ukraine", "Ukrainie", "Ucrania"},
+	"AE": {
+		"UAE", "Emirates", "Vereinigte Arabische Emirate", "Émirats arabes unis",
+		"Emiratos Árabes Unidos", "Arabiemiirikunnat",
+	},
+	"GB": {
+		"UK", "Great Britain", "Britain", "England", "Scotland", "Wales", "Northern Ireland",
+		"Vereinigtes Königreich", "Großbritannien", "Royaume-Uni", "Reino Unido", "Iso-Britannia",
+		"Yhdistynyt kuningaskunta",
+	},
+	"US": {
+		"USA", "United States of America", "America", "Vereinigte Staaten", "États-Unis",
+		"Estados Unidos", "Yhdysvallat",
+	},
+	"UY": {"Uruguai"},
+	"UZ": {"Oʻzbekiston", "Usbekistan", "Ouzbékistan", "Uzbekistán"},
+	"VU": {"New Hebrides"},
+	"VA": {
+		"Holy See", "Vatican", "Vatikanstadt", "Cité du Vatican", "Ciudad del Vaticano",
+		"Vatikaani", "Città del Vaticano",
+	},
+	"VE": {"Venezuela Bolivarian Republic"},
+	"VI": {"US Virgin Islands", "U.S. Virgin Islands"},
+	"VN": {"Viet Nam", "Việt Nam"},
+	"YE": {"Jemen", "Yémen"},
+	"ZM": {"Sambia", "Zambie", "Northern Rhodesia"},
+	"ZW": {"Simbabwe", "Rhodesia", "Southern Rhodesia"},
+}
@@ -1,8 +1,8 @@
+// Code generated by gen_countries.go from countries.csv; DO NOT EDIT.
+
 package data
 
-import (
-	"github.com/matti777/my-countries/backend/internal/models"
-)
+import "github.com/matti777/my-countries/backend/internal/models"
 
 // List is a Go slice of every sovereign country on earth.
 // It matches the Country model (CountryCode, Name, RegionCode).
@@ -204,3 +204,234 @@ var List = []models.Country{
 	{CountryCode: "ZM", Name: "Zambia", RegionCode: "AF"},
 	{CountryCode: "ZW", Name: "Zimbabwe", RegionCode: "AF"},
 }
+
+// dependentTerritories are inhabited or administered areas under another country's sovereignty.
+var dependentTerritories = []models.Country{
+	{CountryCode: "AI", Name: "Anguilla", RegionCode: "NA"},
+	{CountryCode: "AQ", Name: "Antarctica", RegionCode: "AN"},
+	{CountryCode: "AS", Name: "American Samoa", RegionCode: "OC"},
+	{CountryCode: "AW", Name: "Aruba", RegionCode: "NA"},
+	{CountryCode: "AX", Name: "Åland Islands", RegionCode: "EU"},
+	{CountryCode: "BL", Name: "Saint Barthélemy", RegionCode: "NA"},
+	{CountryCode: "BM", Name: "Bermuda", RegionCode: "NA"},
+	{CountryCode: "BQ", Name: "Caribbean Netherlands", RegionCode: "NA"},
+	{CountryCode: "BV", Name: "Bouvet Island", RegionCode: "AN"},
+	{CountryCode: "CC", Name: "Cocos (Keeling) Islands", RegionCode: "AS"},
+	{CountryCode: "CK", Name: "Cook Islands", RegionCode: "OC"},
+	{CountryCode: "CW", Name: "Curaçao", RegionCode: "NA"},
+	{CountryCode: "CX", Name: "Christmas Island", RegionCode: "AS"},
+	{CountryCode: "FK", Name: "Falkland Islands", RegionCode: "SA"},
+	{CountryCode: "FO", Name: "Faroe Islands", RegionCode: "EU"},
+	{CountryCode: "GF", Name: "French Guiana", RegionCode: "SA"},
+	{CountryCode: "GG", Name: "Guernsey", RegionCode: "EU"},
+	{CountryCode: "GI", Name: "Gibraltar", RegionCode: "EU"},
+	{CountryCode: "GL", Name: "Greenland", RegionCode: "NA"},
+	{CountryCode: "GP", Name: "Guadeloupe", RegionCode: "NA"},
+	{CountryCode: "GS", Name: "South Georgia and the South Sandwich Islands", RegionCode: "AN"},
+	{CountryCode: "GU", Name: "Guam", RegionCode: "OC"},
+	{CountryCode: "HK", Name: "Hong Kong", RegionCode: "AS"},
+	{CountryCode: "HM", Name: "Heard Island and McDonald Islands", RegionCode: "AN"},
+	{CountryCode: "IM", Name: "Isle of Man", RegionCode: "EU"},
+	{CountryCode: "IO", Name: "British Indian Ocean Territory", RegionCode: "AS"},
+	{CountryCode: "JE", Name: "Jersey", RegionCode: "EU"},
+	{CountryCode: "KY", Name: "Cayman Islands", RegionCode: "NA"},
+	{CountryCode: "MF", Name: "Saint Martin", RegionCode: "NA"},
+	{CountryCode: "MO", Name: "Macau", RegionCode: "AS"},
+	{CountryCode: "MP", Name: "Northern Mariana Islands", RegionCode: "OC"},
+	{CountryCode: "MQ", Name: "Martinique", RegionCode: "NA"},
+	{CountryCode: "MS", Name: "Montserrat", RegionCode: "NA"},
+	{CountryCode: "NC", Name: "New Caledonia", RegionCode: "OC"},
+	{CountryCode: "NF", Name: "Norfolk Island", RegionCode: "OC"},
+	{CountryCode: "NU", Name: "Niue", RegionCode: "OC"},
+	{CountryCode: "PF", Name: "French Polynesia", RegionCode: "OC"},
+	{CountryCode: "PM", Name: "Saint Pierre and Miquelon", RegionCode: "NA"},
+	{CountryCode: "PN", Name: "Pitcairn Islands", RegionCode: "OC"},
+	{CountryCode: "PR", Name: "Puerto Rico", RegionCode: "NA"},
+	{CountryCode: "RE", Name: "Réunion", RegionCode: "AF"},
+	{CountryCode: "SH", Name: "Saint Helena, Ascension and Tristan da Cunha", RegionCode: "AF"},
+	{CountryCode: "SJ", Name: "Svalbard and Jan Mayen", RegionCode: "EU"},
+	{CountryCode: "SX", Name: "Sint Maarten", RegionCode: "NA"},
+	{CountryCode: "TC", Name: "Turks and Caicos Islands", RegionCode: "NA"},
+	{CountryCode: "TF", Name: "French Southern and Antarctic Lands", RegionCode: "AN"},
+	{CountryCode: "TK", Name: "Tokelau", RegionCode: "OC"},
+	{CountryCode: "UM", Name: "United States Minor Outlying Islands", RegionCode: "OC"},
+	{CountryCode: "VG", Name: "British Virgin Islands", RegionCode: "NA"},
+	{CountryCode: "VI", Name: "United States Virgin Islands", RegionCode: "NA"},
+	{CountryCode: "WF", Name: "Wallis and Futuna", RegionCode: "OC"},
+	{CountryCode: "YT", Name: "Mayotte", RegionCode: "AF"},
+}
+
+// disputedStates are disputed or partially recognised states.
+var disputedStates = []models.Country{
+	{CountryCode: "EH", Name: "Western Sahara", RegionCode: "AF"},
+	{CountryCode: "PS", Name: "Palestine", RegionCode: "AS"},
+	{CountryCode: "XK", Name: "Kosovo", RegionCode: "EU"},
+}
+
+// neighbors maps alpha-2 codes to the codes of the entries sharing a land border with them.
+var neighbors = map[string][]string{
+	"AF": {"CN", "IR", "PK", "TJ", "TM", "UZ"},
+	"AL": {"GR", "ME", "MK", "XK"},
+	"DZ": {"EH", "LY", "MA", "ML", "MR", "NE", "TN"},
+	"AD": {"ES", "FR"},
+	"AO": {"CD", "CG", "NA", "ZM"},
+	"AR": {"BO", "BR", "CL", "PY", "UY"},
+	"AM": {"AZ", "GE", "IR", "TR"},
+	"AT": {"CH", "CZ", "DE", "HU", "IT", "LI", "SI", "SK"},
+	"AZ": {"AM", "GE", "IR", "RU", "TR"},
+	"BD": {"IN", "MM"},
+	"BY": {"LT", "LV", "PL", "RU", "UA"},
+	"BE": {"DE", "FR", "LU", "NL"},
+	"BZ": {"GT", "MX"},
+	"BJ": {"BF", "NE", "NG", "TG"},
+	"BT": {"CN", "IN"},
+	"BO": {"AR", "BR", "CL", "PE", "PY"},
+	"BA": {"HR", "ME", "RS"},
+	"BW": {"NA", "ZA", "ZM", "ZW"},
+	"BR": {"AR", "BO", "CO", "GF", "GY", "PE", "PY", "SR", "UY", "VE"},
+	"BN": {"MY"},
+	"BG": {"GR", "MK", "RO", "RS", "TR"},
+	"BF": {"BJ", "CI", "GH", "ML", "NE", "TG"},
+	"BI": {"CD", "RW", "TZ"},
+	"KH": {"LA", "TH", "VN"},
+	"CM": {"CF", "CG", "GA", "GQ", "NG", "TD"},
+	"CA": {"US"},
+	"CF": {"CD", "CG", "CM", "SD", "SS", "TD"},
+	"TD": {"CF", "CM", "LY", "NE", "NG", "SD"},
+	"CL": {"AR", "BO", "PE"},
+	"CN": {"AF", "BT", "HK", "IN", "KG", "KP", "KZ", "LA", "MM", "MN", "MO", "NP", "PK", "RU", "TJ", "VN"},
+	"CO": {"BR", "EC", "PA", "PE", "VE"},
+	"CG": {"AO", "CD", "CF", "CM", "GA"},
+	"CD": {"AO", "BI", "CF", "CG", "RW", "SS", "TZ", "UG", "ZM"},
+	"CR": {"NI", "PA"},
+	"HR": {"BA", "HU", "ME", "RS", "SI"},
+	"CZ": {"AT", "DE", "PL", "SK"},
+	"DK": {"DE"},
+	"DJ": {"ER", "ET", "SO"},
+	"DO": {"HT"},
+	"EC": {"CO", "PE"},
+	"EG": {"IL", "LY", "PS", "SD"},
+	"SV": {"GT", "HN"},
+	"GQ": {"CM", "GA"},
+	"ER": {"DJ", "ET", "SD"},
+	"EE": {"LV", "RU"},
+	"SZ": {"MZ", "ZA"},
+	"ET": {"DJ", "ER", "KE", "SD", "SO", "SS"},
+	"FI": {"NO", "RU", "SE"},
+	"FR": {"AD", "BE", "CH", "DE", "ES", "IT", "LU", "MC"},
+	"GA": {"CG", "CM", "GQ"},
+	"GM": {"SN"},
+	"GE": {"AM", "AZ", "RU", "TR"},
+	"DE": {"AT", "BE", "CH", "CZ", "DK", "FR", "LU", "NL", "PL"},
+	"GH": {"BF", "CI", "TG"},
+	"GR": {"AL", "BG", "MK", "TR"},
+	"GT": {"BZ", "HN", "MX", "SV"},
+	"GN": {"CI", "GW", "LR", "ML", "SL", "SN"},
+	"GW": {"GN", "SN"},
+	"GY": {"BR", "SR", "VE"},
+	"HT": {"DO"},
+	"HN": {"GT", "NI", "SV"},
+	"HU": {"AT", "HR", "RO", "RS", "SI", "SK", "UA"},
+	"IN": {"BD", "BT", "CN", "MM", "NP", "PK"},
+	"ID": {"MY", "PG", "TL"},
+	"IR": {"AF", "AM", "AZ", "IQ", "PK", "TM", "TR"},
+	"IQ": {"IR", "JO", "KW", "SA", "SY", "TR"},
+	"IE": {"GB"},
+	"IL": {"EG", "JO", "LB", "PS", "SY"},
+	"IT": {"AT", "CH", "FR", "SI", "SM", "VA"},
+	"CI": {"BF", "GH", "GN", "LR", "ML"},
+	"JO": {"IL", "IQ", "PS", "SA", "SY"},
+	"KZ": {"CN", "KG", "RU", "TM", "UZ"},
+	"KE": {"ET", "SO", "SS", "TZ", "UG"},
+	"KP": {"CN", "KR", "RU"},
+	"KR": {"KP"},
+	"KW": {"IQ", "SA"},
+	"KG": {"CN", "KZ", "TJ", "UZ"},
+	"LA": {"CN", "KH", "MM", "TH", "VN"},
+	"LV": {"BY", "EE", "LT", "RU"},
+	"LB": {"IL", "SY"},
+	"LS": {"ZA"},
+	"LR": {"CI", "GN", "SL"},
+	"LY": {"DZ", "EG", "NE", "SD", "TD", "TN"},
+	"LI": {"AT", "CH"},
+	"LT": {"BY", "LV", "PL", "RU"},
+	"LU": {"BE", "DE", "FR"},
+	"MW": {"MZ", "TZ", "ZM"},
+	"MY": {"BN", "ID", "TH"},
+	"ML": {"BF", "CI", "DZ", "GN", "MR", "NE", "SN"},
+	"MR": {"DZ", "EH", "ML", "SN"},
+	"MX": {"BZ", "GT", "US"},
+	"MD": {"RO", "UA"},
+	"MC": {"FR"},
+	"MN": {"CN", "RU"},
+	"ME": {"AL", "BA", "HR", "RS", "XK"},
+	"MA": {"DZ", "EH", "ES"},
+	"MZ": {"MW", "SZ", "TZ", "ZA", "ZM", "ZW"},
+	"MM": {"BD", "CN", "IN", "LA", "TH"},
+	"NA": {"AO", "BW", "ZA", "ZM"},
+	"NP": {"CN", "IN"},
+	"NL": {"BE", "DE"},
+	"NI": {"CR", "HN"},
+	"NE": {"BF", "BJ", "DZ", "LY", "ML", "NG", "TD"},
+	"NG": {"BJ", "CM", "NE", "TD"},
+	"MK": {"AL", "BG", "GR", "RS", "XK"},
+	"NO": {"FI", "RU", "SE"},
+	"OM": {"AE", "SA", "YE"},
+	"PK": {"AF", "CN", "IN", "IR"},
+	"PA": {"CO", "CR"},
+	"PG": {"ID"},
+	"PY": {"AR", "BO", "BR"},
+	"PE": {"BO", "BR", "CL", "CO", "EC"},
+	"PL": {"BY", "CZ", "DE", "LT", "RU", "SK", "UA"},
+	"PT": {"ES"},
+	"QA": {"SA"},
+	"RO": {"BG", "HU", "MD", "RS", "UA"},
+	"RU": {"AZ", "BY", "CN", "EE", "FI", "GE", "KP", "KZ", "LT", "LV", "MN", "NO", "PL", "UA"},
+	"RW": {"BI", "CD", "TZ", "UG"},
+	"SM": {"IT"},
+	"SA": {"AE", "IQ", "JO", "KW", "OM", "QA", "YE"},
+	"SN": {"GM", "GN", "GW", "ML", "MR"},
+	"RS": {"BA", "BG", "HR", "HU", "ME", "MK", "RO", "XK"},
+	"SL": {"GN", "LR"},
+	"SK": {"AT", "CZ", "HU", "PL", "UA"},
+	"SI": {"AT", "HR", "HU", "IT"},
+	"SO": {"DJ", "ET", "KE"},
+	"ZA": {"BW", "LS", "MZ", "NA", "SZ", "ZW"},
+	"SS": {"CD", "CF", "ET", "KE", "SD", "UG"},
+	"ES": {"AD", "FR", "GI", "MA", "PT"},
+	"SD": {"CF", "EG", "ER", "ET", "LY", "SS", "TD"},
+	"SR": {"BR", "GF", "GY"},
+	"SE": {"FI", "NO"},
+	"CH": {"AT", "DE", "FR", "IT", "LI"},
+	"SY": {"IL", "IQ", "JO", "LB", "TR"},
+	"TJ": {"AF", "CN", "KG", "UZ"},
+	"TZ": {"BI", "CD", "KE", "MW", "MZ", "RW", "UG", "ZM"},
+	"TH": {"KH", "LA", "MM", "MY"},
+	"TL": {"ID"},
+	"TG": {"BF", "BJ", "GH"},
+	"TN": {"DZ", "LY"},
+	"TR": {"AM", "AZ", "BG", "GE", "GR", "IQ", "IR", "SY"},
+	"TM": {"AF", "IR", "KZ", "UZ"},
+	"UG": {"CD", "KE", "RW", "SS", "TZ"},
+	"UA": {"BY", "HU", "MD", "PL", "RO", "RU", "SK"},
+	"AE": {"OM", "SA"},
+	"GB": {"IE"},
+	"US": {"CA", "MX"},
+	"UY": {"AR", "BR"},
+	"UZ": {"AF", "KG", "KZ", "TJ", "TM"},
+	"VA": {"IT"},
+	"VE": {"BR", "CO", "GY"},
+	"VN": {"CN", "KH", "LA"},
+	"YE": {"OM", "SA"},
+	"ZM": {"AO", "BW", "CD", "MW", "MZ", "NA", "TZ", "ZW"},
+	"ZW": {"BW", "MZ", "ZA", "ZM"},
+	"GF": {"BR", "SR"},
+	"GI": {"ES"},
+	"HK": {"CN"},
+	"MF": {"SX"},
+	"MO": {"CN"},
+	"SX": {"MF"},
+	"EH": {"DZ", "MA", "MR"},
+	"PS": {"EG", "IL", "JO"},
+	"XK": {"AL", "ME", "MK", "RS"},
+}
@@ -0,0 +1,190 @@
+//go:build ignore
+
+// gen_countries.go generates the bundled country lists and land borders (countries_gen.go) and
+// the ISO 3166-1 code table of package models (../models/country_codes_gen.go) from
+// countries.csv, the single canonical source of country codes. Run with go generate.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+const header = "// Code generated by gen_countries.go from countries.csv; DO NOT EDIT.\n\n"
+
+// row is one line of countries.csv.
+type row struct {
+	alpha2, alpha3, countryType, region, name string
+
+	// neighbors are the alpha-2 codes of the entries sharing a land border, space-separated in
+	// the CSV. Borders must be listed on both sides.
+	neighbors []string
+}
+
+func main() {
+	rows, err := readRows("countries.csv")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write("countries_gen.go", countriesSource(rows)); err != nil {
+		log.Fatal(err)
+	}
+	if err := write("../models/country_codes_gen.go", codesSource(rows)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func readRows(path string) ([]row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(records) == 0 ||
+		strings.Join(records[0], ",") != "alpha2,alpha3,type,region,name,neighbors" {
+		return nil, fmt.Errorf("%s: unexpected header", path)
+	}
+
+	seen := make(map[string]bool)
+	var rows []row
+	for i, rec := range records[1:] {
+		r := row{
+			alpha2:      rec[0],
+			alpha3:      rec[1],
+			countryType: rec[2],
+			region:      rec[3],
+			name:        rec[4],
+			neighbors:   strings.Fields(rec[5]),
+		}
+		if len(r.alpha2) != 2 || len(r.alpha3) != 3 || r.name == "" {
+			return nil, fmt.Errorf("%s line %d: invalid codes or name", path, i+2)
+		}
+		switch r.countryType {
+		case "sovereign", "territory", "disputed":
+		default:
+			return nil, fmt.Errorf("%s line %d: unknown type %q", path, i+2, r.countryType)
+		}
+		if seen[r.alpha2] || seen[r.alpha3] {
+			return nil, fmt.Errorf("%s line %d: duplicate code", path, i+2)
+		}
+		seen[r.alpha2], seen[r.alpha3] = true, true
+		rows = append(rows, r)
+	}
+
+	borders := make(map[string]bool)
+	for _, r := range rows {
+		for _, n := range r.neighbors {
+			borders[r.alpha2+n] = true
+		}
+	}
+	for _, r := range rows {
+		for _, n := range r.neighbors {
+			if !borders[n+r.alpha2] {
+				return nil, fmt.Errorf("%s: border %s-%s is not listed for %s", path, r.alpha2, n, n)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func countriesSource(rows []row) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString("package data\n\n")
+	buf.WriteString("import \"github.com/matti777/my-countries/backend/internal/models\"\n\n")
+
+	lists := []struct {
+		doc, name, countryType string
+	}{
+		{
+			"// List is a Go slice of every sovereign country on earth.\n" +
+				"// It matches the Country model (CountryCode, Name, RegionCode).\n" +
+				"// RegionCode uses 2-letter ISO 3166-1 continent codes: " +
+				"AF, AN, AS, EU, NA, OC, SA.\n",
+			"List", "sovereign",
+		},
+		{
+			"// dependentTerritories are inhabited or administered areas under another " +
+				"country's sovereignty.\n",
+			"dependentTerritories", "territory",
+		},
+		{
+			"// disputedStates are disputed or partially recognised states.\n",
+			"disputedStates", "disputed",
+		},
+	}
+	for _, l := range lists {
+		buf.WriteString(l.doc)
+		fmt.Fprintf(&buf, "var %s = []models.Country{\n", l.name)
+		for _, r := range rows {
+			if r.countryType == l.countryType {
+				fmt.Fprintf(&buf, "{CountryCode: %q, Name: %q, RegionCode: %q},\n",
+					r.alpha2, r.name, r.region)
+			}
+		}
+		buf.WriteString("}\n\n")
+	}
+
+	buf.WriteString("// neighbors maps alpha-2 codes to the codes of the entries sharing a land border " +
+		"with them.\n")
+	buf.WriteString("var neighbors = map[string][]string{\n")
+	for _, r := range rows {
+		if len(r.neighbors) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "%q: {", r.alpha2)
+		for i, n := range r.neighbors {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "%q", n)
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func codesSource(rows []row) []byte {
+	sorted := append([]row(nil), rows...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].alpha2 < sorted[j].alpha2 })
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString("package models\n\n")
+	buf.WriteString("// countryCodeTable lists the ISO 3166-1 codes of every bundled country and " +
+		"territory, by\n// alpha-2 code.\n")
+	buf.WriteString("var countryCodeTable = []countryCodeFormats{\n")
+	for i, r := range sorted {
+		fmt.Fprintf(&buf, "{%q, %q},", r.alpha2, r.alpha3)
+		if i%6 == 5 {
+			buf.WriteByte('\n')
+		} else {
+			buf.WriteByte(' ')
+		}
+	}
+	buf.WriteString("\n}\n")
+	return buf.Bytes()
+}
+
+func write(path string, src []byte) error {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, formatted, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
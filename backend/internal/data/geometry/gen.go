@@ -0,0 +1,264 @@
+//go:build ignore
+
+// gen.go builds the embedded boundary datasets from the Natural Earth 1:110m Admin 0 GeoJSON
+// vendored by the frontend, one file per detail level. Run with go generate.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+const source = "../../../../frontend/public/assets/globe/ne_110m_admin_0_countries.geojson"
+
+// levels maps output files to their Douglas-Peucker tolerance in degrees (0 keeps all points).
+var levels = []struct {
+	file      string
+	tolerance float64
+}{
+	{"countries_110m.geojson", 0},
+	{"countries_110m_medium.geojson", 0.2},
+	{"countries_110m_low.geojson", 0.75},
+}
+
+// adm0Codes covers features whose ISO_A2 is "-99" but that map to a listed country.
+// Others with "-99" (N. Cyprus, Somaliland) are skipped.
+var adm0Codes = map[string]string{"FRA": "FR", "NOR": "NO"}
+
+type point [2]float64
+
+type ring []point
+
+type polygon []ring
+
+type sourceFeature struct {
+	Properties struct {
+		ISOA2  string `json:"ISO_A2"`
+		ADM0A3 string `json:"ADM0_A3"`
+	} `json:"properties"`
+	Geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+type country struct {
+	code     string
+	polygons []polygon
+}
+
+func main() {
+	raw, err := os.ReadFile(source)
+	if err != nil {
+		log.Fatalf("failed to read source: %v", err)
+	}
+	var collection struct {
+		Features []sourceFeature `json:"features"`
+	}
+	if err := json.Unmarshal(raw, &collection); err != nil {
+		log.Fatalf("failed to parse source: %v", err)
+	}
+
+	var countries []country
+	for _, f := range collection.Features {
+		code := f.Properties.ISOA2
+		if code == "-99" {
+			code = adm0Codes[f.Properties.ADM0A3]
+		}
+		if code == "" {
+			continue
+		}
+		polygons, err := parsePolygons(f.Geometry.Type, f.Geometry.Coordinates)
+		if err != nil {
+			log.Fatalf("%s: %v", code, err)
+		}
+		countries = append(countries, country{code: code, polygons: polygons})
+	}
+	sort.Slice(countries, func(i, j int) bool { return countries[i].code < countries[j].code })
+
+	for _, level := range levels {
+		var buf bytes.Buffer
+		buf.WriteString(`{"type":"FeatureCollection","features":[` + "\n")
+		for i, c := range countries {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			writeFeature(&buf, c.code, simplifyPolygons(c.polygons, level.tolerance))
+		}
+		buf.WriteString("\n]}\n")
+		if err := os.WriteFile(level.file, buf.Bytes(), 0o644); err != nil {
+			log.Fatalf("failed to write %s: %v", level.file, err)
+		}
+		fmt.Printf("%s: %d features, %d bytes\n", level.file, len(countries), buf.Len())
+	}
+}
+
+func parsePolygons(geometryType string, coordinates json.RawMessage) ([]polygon, error) {
+	switch geometryType {
+	case "Polygon":
+		var p polygon
+		if err := json.Unmarshal(coordinates, &p); err != nil {
+			return nil, err
+		}
+		return []polygon{p}, nil
+	case "MultiPolygon":
+		var ps []polygon
+		if err := json.Unmarshal(coordinates, &ps); err != nil {
+			return nil, err
+		}
+		return ps, nil
+	}
+	return nil, fmt.Errorf("unsupported geometry type %q", geometryType)
+}
+
+// simplifyPolygons simplifies every ring and drops polygons whose exterior ring collapses and
+// holes that collapse. The largest polygon is always kept, unsimplified if necessary, so every
+// country keeps a geometry.
+func simplifyPolygons(polygons []polygon, tolerance float64) []polygon {
+	var out []polygon
+	for _, p := range polygons {
+		exterior := simplifyRing(p[0], tolerance)
+		if len(exterior) < 4 {
+			continue
+		}
+		sp := polygon{exterior}
+		for _, hole := range p[1:] {
+			if h := simplifyRing(hole, tolerance); len(h) >= 4 {
+				sp = append(sp, h)
+			}
+		}
+		out = append(out, sp)
+	}
+	if len(out) == 0 {
+		largest := polygons[0]
+		for _, p := range polygons[1:] {
+			if area(p[0]) > area(largest[0]) {
+				largest = p
+			}
+		}
+		out = []polygon{{simplifyRing(largest[0], 0)}}
+	}
+	return out
+}
+
+// simplifyRing rounds to 4 decimals, removes consecutive duplicates and applies
+// Douglas-Peucker with the given tolerance, keeping the ring closed.
+func simplifyRing(r ring, tolerance float64) ring {
+	var rounded ring
+	for _, pt := range r {
+		pt = point{round4(pt[0]), round4(pt[1])}
+		if len(rounded) > 0 && rounded[len(rounded)-1] == pt {
+			continue
+		}
+		rounded = append(rounded, pt)
+	}
+	if tolerance == 0 || len(rounded) < 4 {
+		return rounded
+	}
+	keep := make([]bool, len(rounded))
+	keep[0], keep[len(rounded)-1] = true, true
+	// The ring starts and ends at the same point, so split it at the point farthest from it.
+	far, farDist := 0, 0.0
+	for i, pt := range rounded {
+		if d := math.Hypot(pt[0]-rounded[0][0], pt[1]-rounded[0][1]); d > farDist {
+			far, farDist = i, d
+		}
+	}
+	keep[far] = true
+	douglasPeucker(rounded, 0, far, tolerance, keep)
+	douglasPeucker(rounded, far, len(rounded)-1, tolerance, keep)
+
+	var out ring
+	for i, pt := range rounded {
+		if keep[i] {
+			out = append(out, pt)
+		}
+	}
+	return out
+}
+
+func douglasPeucker(r ring, first, last int, tolerance float64, keep []bool) {
+	if last-first < 2 {
+		return
+	}
+	index, maxDist := 0, 0.0
+	for i := first + 1; i < last; i++ {
+		if d := segmentDistance(r[i], r[first], r[last]); d > maxDist {
+			index, maxDist = i, d
+		}
+	}
+	if maxDist <= tolerance {
+		return
+	}
+	keep[index] = true
+	douglasPeucker(r, first, index, tolerance, keep)
+	douglasPeucker(r, index, last, tolerance, keep)
+}
+
+func segmentDistance(p, a, b point) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
+
+func area(r ring) float64 {
+	sum := 0.0
+	for i := 0; i+1 < len(r); i++ {
+		sum += r[i][0]*r[i+1][1] - r[i+1][0]*r[i][1]
+	}
+	return math.Abs(sum) / 2
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
+
+func writeFeature(buf *bytes.Buffer, code string, polygons []polygon) {
+	fmt.Fprintf(buf, `{"type":"Feature","properties":{"countryCode":%q},"geometry":`, code)
+	if len(polygons) == 1 {
+		buf.WriteString(`{"type":"Polygon","coordinates":`)
+		writePolygon(buf, polygons[0])
+	} else {
+		buf.WriteString(`{"type":"MultiPolygon","coordinates":[`)
+		for i, p := range polygons {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writePolygon(buf, p)
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteString("}}")
+}
+
+func writePolygon(buf *bytes.Buffer, p polygon) {
+	buf.WriteByte('[')
+	for i, r := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('[')
+		for j, pt := range r {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('[')
+			buf.WriteString(strconv.FormatFloat(pt[0], 'f', -1, 64))
+			buf.WriteByte(',')
+			buf.WriteString(strconv.FormatFloat(pt[1], 'f', -1, 64))
+			buf.WriteByte(']')
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteByte(']')
+}
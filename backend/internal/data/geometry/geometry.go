@@ -0,0 +1,153 @@
+// Package geometry serves simplified country boundary polygons from embedded GeoJSON datasets
+// (Natural Earth 1:110m Admin 0, public domain; coordinates rounded to 4 decimals), one per
+// detail Level. Countries and territories too small for that scale have no geometry.
+package geometry
+
+//go:generate go run gen.go
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Level selects how far the boundaries are simplified.
+type Level string
+
+// Detail levels; see gen.go for their Douglas-Peucker tolerances.
+const (
+	// Full is the source dataset with all points.
+	Full Level = "full"
+	// Medium keeps roughly 60% of the points (tolerance 0.2°).
+	Medium Level = "medium"
+	// Low keeps roughly 25% of the points (tolerance 0.75°), for small map views and mobile.
+	Low Level = "low"
+)
+
+// ParseLevel parses a detail query value; empty means Full.
+func ParseLevel(s string) (Level, error) {
+	switch l := Level(strings.ToLower(strings.TrimSpace(s))); l {
+	case "":
+		return Full, nil
+	case Full, Medium, Low:
+		return l, nil
+	}
+	return "", fmt.Errorf("detail must be one of %s, %s, %s", Full, Medium, Low)
+}
+
+var (
+	//go:embed countries_110m.geojson
+	countriesFull []byte
+	//go:embed countries_110m_medium.geojson
+	countriesMedium []byte
+	//go:embed countries_110m_low.geojson
+	countriesLow []byte
+)
+
+// datasets holds the lazily parsed features of each Level.
+var datasets = map[Level]*dataset{
+	Full:   {raw: countriesFull},
+	Medium: {raw: countriesMedium},
+	Low:    {raw: countriesLow},
+}
+
+type dataset struct {
+	raw      []byte
+	once     sync.Once
+	features map[string]*Feature
+	err      error
+}
+
+// Feature is the encoded GeoJSON Feature of one country, ready to be served.
+type Feature struct {
+	// JSON is the Feature document; properties hold countryCode.
+	JSON []byte
+	// Gzip is JSON compressed with gzip, for clients sending Accept-Encoding: gzip.
+	Gzip []byte
+	// Rings are the outer and hole rings of all polygons as [longitude, latitude] points, for
+	// drawing the country (holes wind opposite to outer rings).
+	Rings [][][2]float64
+}
+
+// Lookup returns the boundary Feature of an alpha-2 code (case-insensitive) at level, or false
+// when the dataset has none. Each level's dataset is parsed on first use.
+func Lookup(code string, level Level) (*Feature, bool, error) {
+	features, err := All(level)
+	if err != nil {
+		return nil, false, err
+	}
+	f, ok := features[strings.ToUpper(strings.TrimSpace(code))]
+	return f, ok, nil
+}
+
+// All returns the boundary Features of level by alpha-2 code. The map is shared and must not
+// be modified.
+func All(level Level) (map[string]*Feature, error) {
+	d, ok := datasets[level]
+	if !ok {
+		return nil, fmt.Errorf("unknown geometry level %q", level)
+	}
+	d.once.Do(func() {
+		d.features, d.err = load(d.raw)
+	})
+	return d.features, d.err
+}
+
+func load(data []byte) (map[string]*Feature, error) {
+	var collection struct {
+		Features []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("failed to parse geometry dataset: %w", err)
+	}
+
+	out := make(map[string]*Feature, len(collection.Features))
+	for _, raw := range collection.Features {
+		var f struct {
+			Properties struct {
+				CountryCode string `json:"countryCode"`
+			} `json:"properties"`
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		}
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse geometry feature: %w", err)
+		}
+		var polygons [][][][2]float64
+		switch f.Geometry.Type {
+		case "Polygon":
+			var polygon [][][2]float64
+			err := json.Unmarshal(f.Geometry.Coordinates, &polygon)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse geometry coordinates: %w", err)
+			}
+			polygons = append(polygons, polygon)
+		case "MultiPolygon":
+			err := json.Unmarshal(f.Geometry.Coordinates, &polygons)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse geometry coordinates: %w", err)
+			}
+		}
+		var rings [][][2]float64
+		for _, polygon := range polygons {
+			rings = append(rings, polygon...)
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(raw); err != nil {
+			return nil, fmt.Errorf("failed to compress geometry: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress geometry: %w", err)
+		}
+		out[f.Properties.CountryCode] = &Feature{JSON: raw, Gzip: buf.Bytes(), Rings: rings}
+	}
+
+	return out, nil
+}
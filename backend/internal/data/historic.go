@@ -0,0 +1,75 @@
+package data
+
+import (
+	"strings"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// HistoricCountries lists former countries accepted on visits from their time (GET
+// /countries?include=historic). Their codes are not in countries.csv since ISO 3166-1 no
+// longer assigns them; none collides with a current code. Maintained by hand.
+var HistoricCountries = []models.HistoricCountry{
+	{
+		CountryCode: "CS", Alpha3: "CSK", Name: "Czechoslovakia", RegionCode: "EU",
+		From: date(1918, 10, 28), Until: date(1993, 1, 1),
+		SuccessorCodes: []string{"CZ", "SK"},
+	},
+	{
+		CountryCode: "DD", Alpha3: "DDR", Name: "East Germany", RegionCode: "EU",
+		From: date(1949, 10, 7), Until: date(1990, 10, 3),
+		SuccessorCodes: []string{"DE"},
+	},
+	{
+		CountryCode: "VD", Alpha3: "VDR", Name: "North Vietnam", RegionCode: "AS",
+		From: date(1945, 9, 2), Until: date(1976, 7, 2),
+		SuccessorCodes: []string{"VN"},
+	},
+	{
+		CountryCode: "YD", Alpha3: "YMD", Name: "South Yemen", RegionCode: "AS",
+		From: date(1967, 11, 30), Until: date(1990, 5, 22),
+		SuccessorCodes: []string{"YE"},
+	},
+	{
+		CountryCode: "SU", Alpha3: "SUN", Name: "Soviet Union", RegionCode: "EU",
+		From: date(1922, 12, 30), Until: date(1991, 12, 26),
+		SuccessorCodes: []string{
+			"AM", "AZ", "BY", "EE", "GE", "KG", "KZ", "LT", "LV", "MD", "RU", "TJ", "TM", "UA", "UZ",
+		},
+	},
+	{
+		// Kingdom, Socialist Federal Republic and Federal Republic of Yugoslavia; the last
+		// became Serbia and Montenegro in 2003.
+		CountryCode: "YU", Alpha3: "YUG", Name: "Yugoslavia", RegionCode: "EU",
+		From: date(1918, 12, 1), Until: date(2003, 2, 4),
+		SuccessorCodes: []string{"BA", "HR", "ME", "MK", "RS", "SI", "XK"},
+	},
+}
+
+// historicByCode maps alpha-2 and alpha-3 codes to their HistoricCountries entry.
+var historicByCode = func() map[string]models.HistoricCountry {
+	m := make(map[string]models.HistoricCountry, 2*len(HistoricCountries))
+	for _, h := range HistoricCountries {
+		m[h.CountryCode] = h
+		m[h.Alpha3] = h
+	}
+	return m
+}()
+
+// HistoricCountryByCode returns the historic country with the given alpha-2 or alpha-3 code
+// (case-insensitive).
+func HistoricCountryByCode(code string) (models.HistoricCountry, bool) {
+	h, ok := historicByCode[strings.ToUpper(strings.TrimSpace(code))]
+	return h, ok
+}
+
+// SuccessorCodes returns the successor country codes of a historic country code, or nil for
+// any other code.
+func SuccessorCodes(code string) []string {
+	return historicByCode[code].SuccessorCodes
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -1,19 +1,254 @@
 package data
 
-import "strings"
+//go:generate go run gen_countries.go
 
-// listedCodes is the set of CountryCode values from List for fast lookup.
-var listedCodes map[string]struct{}
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/textnorm"
+)
+
+// catalog is an immutable view of the bundled countries with the current overrides applied.
+// It is replaced as a whole by SetOverrides, so readers never see a partial update.
+type catalog struct {
+	// list and territories are the sovereign and other non-deprecated countries, in order.
+	list        []models.Country
+	territories []models.Country
+
+	// listed is the set of CountryCode values in list.
+	listed map[string]struct{}
+
+	// byCode maps CountryCode values, including deprecated ones, to their entries.
+	byCode map[string]models.Country
+
+	// byName maps folded (textnorm.Fold) country names and Aliases to their entries.
+	byName map[string]models.Country
+
+	// version identifies the catalog contents; see Version.
+	version string
+}
+
+// current is the catalog used by the lookup functions.
+var current atomic.Pointer[catalog]
+
+// embeddedCodes is the set of CountryCode values in List and Territories.
+var embeddedCodes map[string]struct{}
 
 func init() {
-	listedCodes = make(map[string]struct{}, len(List))
-	for _, c := range List {
-		listedCodes[c.CountryCode] = struct{}{}
+	for i := range List {
+		List[i].Type = models.CountryTypeSovereign
+		setDerived(&List[i])
 	}
+	for _, c := range dependentTerritories {
+		c.Type = models.CountryTypeTerritory
+		Territories = append(Territories, c)
+	}
+	for _, c := range disputedStates {
+		c.Type = models.CountryTypeDisputed
+		Territories = append(Territories, c)
+	}
+	for i := range Territories {
+		setDerived(&Territories[i])
+	}
+	embeddedCodes = make(map[string]struct{}, len(List)+len(Territories))
+	for _, group := range [][]models.Country{List, Territories} {
+		for _, c := range group {
+			embeddedCodes[c.CountryCode] = struct{}{}
+		}
+	}
+	current.Store(newCatalog(nil))
+}
+
+// SetOverrides replaces the current catalog with List and Territories merged with overrides
+// (see models.CountryOverride) and returns the codes of overrides that were skipped because
+// they add a country without a name or region.
+func SetOverrides(overrides []models.CountryOverride) []string {
+	var skipped []string
+	valid := make([]models.CountryOverride, 0, len(overrides))
+	for _, o := range overrides {
+		if _, embedded := embeddedCodes[o.CountryCode]; !embedded &&
+			(o.Name == "" || o.RegionCode == "") {
+			skipped = append(skipped, o.CountryCode)
+			continue
+		}
+		valid = append(valid, o)
+	}
+	current.Store(newCatalog(valid))
+	return skipped
+}
+
+// IsEmbeddedCountry reports whether code is an alpha-2 code in List or Territories, i.e. a
+// country override for it renames or deprecates rather than adds a country.
+func IsEmbeddedCountry(code string) bool {
+	_, ok := embeddedCodes[code]
+	return ok
+}
+
+// Version identifies the contents of the country lists: a short hash that changes whenever an
+// override (or a deploy) changes a code, name, region, type or deprecation.
+func Version() string {
+	return current.Load().version
+}
+
+// newCatalog builds a catalog from List and Territories with overrides applied. Renamed
+// countries keep their embedded name as an alias; added countries and those moved to another
+// type are inserted by name.
+func newCatalog(overrides []models.CountryOverride) *catalog {
+	byOverride := make(map[string]models.CountryOverride, len(overrides))
+	for _, o := range overrides {
+		byOverride[o.CountryCode] = o
+	}
+
+	all := make([]models.Country, 0, len(List)+len(Territories)+len(overrides))
+	all = append(append(all, List...), Territories...)
+	formerNames := make(map[string]string)
+	moved := make(map[string]bool)
+	for i := range all {
+		o, ok := byOverride[all[i].CountryCode]
+		if !ok {
+			continue
+		}
+		if o.Name != "" && o.Name != all[i].Name {
+			formerNames[all[i].CountryCode] = all[i].Name
+		}
+		moved[all[i].CountryCode] = o.Type != "" && o.Type != all[i].Type
+		applyOverride(&all[i], o)
+		delete(byOverride, all[i].CountryCode)
+	}
+
+	cat := &catalog{
+		listed: make(map[string]struct{}, len(List)),
+		byCode: make(map[string]models.Country, len(all)),
+		byName: make(map[string]models.Country, len(all)),
+	}
+	for _, c := range all {
+		cat.add(c, moved[c.CountryCode])
+	}
+	added := make([]string, 0, len(byOverride))
+	for code := range byOverride {
+		added = append(added, code)
+	}
+	sort.Strings(added)
+	for _, code := range added {
+		c := models.Country{CountryCode: code, Type: models.CountryTypeSovereign}
+		applyOverride(&c, byOverride[code])
+		setDerived(&c)
+		cat.add(c, true)
+	}
+
+	// Sovereign names win over territory names, then names over aliases
+	for _, c := range cat.list {
+		cat.addName(c.Name, c)
+	}
+	for _, c := range cat.territories {
+		cat.addName(c.Name, c)
+	}
+	for code, name := range formerNames {
+		cat.addName(name, cat.byCode[code])
+	}
+	for code, names := range Aliases {
+		if c, ok := cat.byCode[code]; ok {
+			for _, name := range names {
+				cat.addName(name, c)
+			}
+		}
+	}
+
+	h := sha256.New()
+	for _, c := range append(append([]models.Country(nil), cat.list...), cat.territories...) {
+		fmt.Fprintf(h, "%s|%s|%s|%s\n", c.CountryCode, c.Name, c.RegionCode, c.Type)
+	}
+	cat.version = hex.EncodeToString(h.Sum(nil))[:12]
+	return cat
+}
+
+// add indexes c by code and, unless deprecated, adds it to list or territories: appended, or
+// with byName before the first entry whose name sorts after it.
+func (cat *catalog) add(c models.Country, byName bool) {
+	cat.byCode[c.CountryCode] = c
+	if c.Deprecated {
+		return
+	}
+	countries := &cat.territories
+	if c.Type == models.CountryTypeSovereign {
+		countries = &cat.list
+		cat.listed[c.CountryCode] = struct{}{}
+	}
+	at := len(*countries)
+	if byName {
+		name := textnorm.Fold(c.Name)
+		for i, other := range *countries {
+			if textnorm.Fold(other.Name) > name {
+				at = i
+				break
+			}
+		}
+	}
+	*countries = slices.Insert(*countries, at, c)
+}
+
+func (cat *catalog) addName(name string, c models.Country) {
+	if _, taken := cat.byName[textnorm.Fold(name)]; !taken {
+		cat.byName[textnorm.Fold(name)] = c
+	}
+}
+
+func applyOverride(c *models.Country, o models.CountryOverride) {
+	if o.Name != "" {
+		c.Name = o.Name
+	}
+	if o.RegionCode != "" {
+		c.RegionCode = o.RegionCode
+	}
+	if o.Type != "" {
+		c.Type = o.Type
+	}
+	c.Deprecated = o.Deprecated
+}
+
+func setDerived(c *models.Country) {
+	c.Alpha3 = models.Alpha3(c.CountryCode)
+	c.FlagEmoji = models.FlagEmoji(c.CountryCode)
+	c.FlagImagePath = models.FlagImagePath(c.CountryCode)
+}
+
+// Countries returns the sovereign countries: List with the current overrides applied
+// (GET /countries). The slice is shared and must not be modified.
+func Countries() []models.Country {
+	return current.Load().list
+}
+
+// ListWithTerritories returns Countries followed by the territories and disputed states, with
+// the current overrides applied (GET /countries?include=territories).
+func ListWithTerritories() []models.Country {
+	cat := current.Load()
+	out := make([]models.Country, 0, len(cat.list)+len(cat.territories))
+	out = append(out, cat.list...)
+	return append(out, cat.territories...)
+}
+
+// IsTerritory reports whether code (case-insensitive) is one of the territories and disputed
+// states in ListWithTerritories.
+func IsTerritory(code string) bool {
+	c, ok := current.Load().byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return ok && !c.Deprecated && c.Type != models.CountryTypeSovereign
+}
+
+// IsVisitableCountry reports whether code may be used for a visit: a listed sovereign country,
+// or one of Territories when includeTerritories (the user's Settings.IncludeTerritories) is set.
+func IsVisitableCountry(code string, includeTerritories bool) bool {
+	return IsListedCountry(code) || (includeTerritories && IsTerritory(code))
 }
 
 // IsListedCountry reports whether code is exactly one of the bundled sovereign
-// country codes (2 uppercase ASCII letters). Use for API validation against data.List.
+// country codes (2 uppercase ASCII letters). Use for API validation against Countries.
 func IsListedCountry(code string) bool {
 	n := strings.TrimSpace(code)
 	if len(n) != 2 {
@@ -23,6 +258,39 @@ func IsListedCountry(code string) bool {
 	if n[0] < 'A' || n[0] > 'Z' || n[1] < 'A' || n[1] > 'Z' {
 		return false
 	}
-	_, ok := listedCodes[n]
+	_, ok := current.Load().listed[n]
+	return ok
+}
+
+// CountryByName returns the bundled country whose Name or one of its Aliases matches name.
+// Matching ignores case, diacritics and punctuation ("Turkiye", "Côte d'Ivoire" and
+// "Elfenbeinküste" all resolve).
+func CountryByName(name string) (models.Country, bool) {
+	c, ok := current.Load().byName[textnorm.Fold(name)]
+	return c, ok
+}
+
+// CountryNames returns the Name and Aliases of the country with the given code,
+// or nil when the code is not listed.
+func CountryNames(code string) []string {
+	c, ok := CountryByCode(code)
+	if !ok {
+		return nil
+	}
+	return append([]string{c.Name}, Aliases[c.CountryCode]...)
+}
+
+// CountryByCode returns the bundled country or territory for an ISO 3166-1 alpha-2 or alpha-3
+// code (case-insensitive). Handlers and importers use it to validate and normalize country codes.
+// Deprecated entries (see models.CountryOverride) are returned so existing visits still resolve.
+func CountryByCode(code string) (models.Country, bool) {
+	c, ok := current.Load().byCode[models.NormalizeCountryCode(code)]
+	return c, ok
+}
+
+// IsValidCode reports whether code is the alpha-2 or alpha-3 code of a bundled country or
+// territory (case-insensitive). See CountryByCode.
+func IsValidCode(code string) bool {
+	_, ok := CountryByCode(code)
 	return ok
 }
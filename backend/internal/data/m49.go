@@ -0,0 +1,179 @@
+package data
+
+import (
+	"sort"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/textnorm"
+)
+
+// m49Area is the UN M49 classification of a country or area.
+type m49Area struct {
+	code      string
+	subRegion string
+}
+
+// m49SubRegion is a UN M49 sub-region and the region it belongs to.
+type m49SubRegion struct {
+	name   string
+	region string
+}
+
+// m49SubRegions maps M49 sub-region codes to their names and regions.
+var m49SubRegions = map[string]m49SubRegion{
+	"011": {"Western Africa", "Africa"},
+	"014": {"Eastern Africa", "Africa"},
+	"015": {"Northern Africa", "Africa"},
+	"017": {"Middle Africa", "Africa"},
+	"018": {"Southern Africa", "Africa"},
+	"005": {"South America", "Americas"},
+	"013": {"Central America", "Americas"},
+	"021": {"Northern America", "Americas"},
+	"029": {"Caribbean", "Americas"},
+	"030": {"Eastern Asia", "Asia"},
+	"034": {"Southern Asia", "Asia"},
+	"035": {"South-eastern Asia", "Asia"},
+	"143": {"Central Asia", "Asia"},
+	"145": {"Western Asia", "Asia"},
+	"039": {"Southern Europe", "Europe"},
+	"151": {"Eastern Europe", "Europe"},
+	"154": {"Northern Europe", "Europe"},
+	"155": {"Western Europe", "Europe"},
+	"053": {"Australia and New Zealand", "Oceania"},
+	"054": {"Melanesia", "Oceania"},
+	"057": {"Micronesia", "Oceania"},
+	"061": {"Polynesia", "Oceania"},
+}
+
+// UNList returns the countries and areas of the UN M49 standard (GET /countries?list=un),
+// sorted by name, with M49Code, M49Region and M49SubRegion set.
+func UNList() []models.Country {
+	out := make([]models.Country, 0, len(m49Areas))
+	for _, c := range ListWithTerritories() {
+		area, ok := m49Areas[c.CountryCode]
+		if !ok {
+			continue
+		}
+		c.M49Code = area.code
+		if sub, ok := m49SubRegions[area.subRegion]; ok {
+			c.M49SubRegion = sub.name
+			c.M49Region = sub.region
+		}
+		out = append(out, c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return textnorm.Fold(out[i].Name) < textnorm.Fold(out[j].Name)
+	})
+	return out
+}
+
+// IsUNArea reports whether code (case-insensitive) is a country or area in UNList.
+func IsUNArea(code string) bool {
+	c, ok := CountryByCode(code)
+	if !ok {
+		return false
+	}
+	_, ok = m49Areas[c.CountryCode]
+	return ok
+}
+
+// m49Areas maps alpha-2 codes to their UN M49 numeric code and sub-region code. Every bundled
+// country and territory is included except those without an M49 entry (Taiwan, Kosovo).
+// Antarctica has no sub-region.
+var m49Areas = map[string]m49Area{
+	// Northern Africa
+	"DZ": {"012", "015"}, "EG": {"818", "015"}, "EH": {"732", "015"}, "LY": {"434", "015"},
+	"MA": {"504", "015"}, "SD": {"729", "015"}, "TN": {"788", "015"},
+	// Eastern Africa
+	"BI": {"108", "014"}, "DJ": {"262", "014"}, "ER": {"232", "014"}, "ET": {"231", "014"},
+	"IO": {"086", "014"}, "KE": {"404", "014"}, "KM": {"174", "014"}, "MG": {"450", "014"},
+	"MU": {"480", "014"}, "MW": {"454", "014"}, "MZ": {"508", "014"}, "RE": {"638", "014"},
+	"RW": {"646", "014"}, "SC": {"690", "014"}, "SO": {"706", "014"}, "SS": {"728", "014"},
+	"TF": {"260", "014"}, "TZ": {"834", "014"}, "UG": {"800", "014"}, "YT": {"175", "014"},
+	"ZM": {"894", "014"}, "ZW": {"716", "014"},
+	// Middle Africa
+	"AO": {"024", "017"}, "CD": {"180", "017"}, "CF": {"140", "017"}, "CG": {"178", "017"},
+	"CM": {"120", "017"}, "GA": {"266", "017"}, "GQ": {"226", "017"}, "ST": {"678", "017"},
+	"TD": {"148", "017"},
+	// Southern Africa
+	"BW": {"072", "018"}, "LS": {"426", "018"}, "NA": {"516", "018"}, "SZ": {"748", "018"},
+	"ZA": {"710", "018"},
+	// Western Africa
+	"BF": {"854", "011"}, "BJ": {"204", "011"}, "CI": {"384", "011"}, "CV": {"132", "011"},
+	"GH": {"288", "011"}, "GM": {"270", "011"}, "GN": {"324", "011"}, "GW": {"624", "011"},
+	"LR": {"430", "011"}, "ML": {"466", "011"}, "MR": {"478", "011"}, "NE": {"562", "011"},
+	"NG": {"566", "011"}, "SH": {"654", "011"}, "SL": {"694", "011"}, "SN": {"686", "011"},
+	"TG": {"768", "011"},
+	// Caribbean
+	"AG": {"028", "029"}, "AI": {"660", "029"}, "AW": {"533", "029"}, "BB": {"052", "029"},
+	"BL": {"652", "029"}, "BQ": {"535", "029"}, "BS": {"044", "029"}, "CU": {"192", "029"},
+	"CW": {"531", "029"}, "DM": {"212", "029"}, "DO": {"214", "029"}, "GD": {"308", "029"},
+	"GP": {"312", "029"}, "HT": {"332", "029"}, "JM": {"388", "029"}, "KN": {"659", "029"},
+	"KY": {"136", "029"}, "LC": {"662", "029"}, "MF": {"663", "029"}, "MQ": {"474", "029"},
+	"MS": {"500", "029"}, "PR": {"630", "029"}, "SX": {"534", "029"}, "TC": {"796", "029"},
+	"TT": {"780", "029"}, "VC": {"670", "029"}, "VG": {"092", "029"}, "VI": {"850", "029"},
+	// Central America
+	"BZ": {"084", "013"}, "CR": {"188", "013"}, "GT": {"320", "013"}, "HN": {"340", "013"},
+	"MX": {"484", "013"}, "NI": {"558", "013"}, "PA": {"591", "013"}, "SV": {"222", "013"},
+	// South America
+	"AR": {"032", "005"}, "BO": {"068", "005"}, "BR": {"076", "005"}, "BV": {"074", "005"},
+	"CL": {"152", "005"}, "CO": {"170", "005"}, "EC": {"218", "005"}, "FK": {"238", "005"},
+	"GF": {"254", "005"}, "GS": {"239", "005"}, "GY": {"328", "005"}, "PE": {"604", "005"},
+	"PY": {"600", "005"}, "SR": {"740", "005"}, "UY": {"858", "005"}, "VE": {"862", "005"},
+	// Northern America
+	"BM": {"060", "021"}, "CA": {"124", "021"}, "GL": {"304", "021"}, "PM": {"666", "021"},
+	"US": {"840", "021"},
+	// Central Asia
+	"KG": {"417", "143"}, "KZ": {"398", "143"}, "TJ": {"762", "143"}, "TM": {"795", "143"},
+	"UZ": {"860", "143"},
+	// Eastern Asia
+	"CN": {"156", "030"}, "HK": {"344", "030"}, "JP": {"392", "030"}, "KP": {"408", "030"},
+	"KR": {"410", "030"}, "MN": {"496", "030"}, "MO": {"446", "030"},
+	// South-eastern Asia
+	"BN": {"096", "035"}, "ID": {"360", "035"}, "KH": {"116", "035"}, "LA": {"418", "035"},
+	"MM": {"104", "035"}, "MY": {"458", "035"}, "PH": {"608", "035"}, "SG": {"702", "035"},
+	"TH": {"764", "035"}, "TL": {"626", "035"}, "VN": {"704", "035"},
+	// Southern Asia
+	"AF": {"004", "034"}, "BD": {"050", "034"}, "BT": {"064", "034"}, "IN": {"356", "034"},
+	"IR": {"364", "034"}, "LK": {"144", "034"}, "MV": {"462", "034"}, "NP": {"524", "034"},
+	"PK": {"586", "034"},
+	// Western Asia
+	"AE": {"784", "145"}, "AM": {"051", "145"}, "AZ": {"031", "145"}, "BH": {"048", "145"},
+	"CY": {"196", "145"}, "GE": {"268", "145"}, "IL": {"376", "145"}, "IQ": {"368", "145"},
+	"JO": {"400", "145"}, "KW": {"414", "145"}, "LB": {"422", "145"}, "OM": {"512", "145"},
+	"PS": {"275", "145"}, "QA": {"634", "145"}, "SA": {"682", "145"}, "SY": {"760", "145"},
+	"TR": {"792", "145"}, "YE": {"887", "145"},
+	// Eastern Europe
+	"BG": {"100", "151"}, "BY": {"112", "151"}, "CZ": {"203", "151"}, "HU": {"348", "151"},
+	"MD": {"498", "151"}, "PL": {"616", "151"}, "RO": {"642", "151"}, "RU": {"643", "151"},
+	"SK": {"703", "151"}, "UA": {"804", "151"},
+	// Northern Europe
+	"AX": {"248", "154"}, "DK": {"208", "154"}, "EE": {"233", "154"}, "FI": {"246", "154"},
+	"FO": {"234", "154"}, "GB": {"826", "154"}, "GG": {"831", "154"}, "IE": {"372", "154"},
+	"IM": {"833", "154"}, "IS": {"352", "154"}, "JE": {"832", "154"}, "LT": {"440", "154"},
+	"LV": {"428", "154"}, "NO": {"578", "154"}, "SE": {"752", "154"}, "SJ": {"744", "154"},
+	// Southern Europe
+	"AD": {"020", "039"}, "AL": {"008", "039"}, "BA": {"070", "039"}, "ES": {"724", "039"},
+	"GI": {"292", "039"}, "GR": {"300", "039"}, "HR": {"191", "039"}, "IT": {"380", "039"},
+	"ME": {"499", "039"}, "MK": {"807", "039"}, "MT": {"470", "039"}, "PT": {"620", "039"},
+	"RS": {"688", "039"}, "SI": {"705", "039"}, "SM": {"674", "039"}, "VA": {"336", "039"},
+	// Western Europe
+	"AT": {"040", "155"}, "BE": {"056", "155"}, "CH": {"756", "155"}, "DE": {"276", "155"},
+	"FR": {"250", "155"}, "LI": {"438", "155"}, "LU": {"442", "155"}, "MC": {"492", "155"},
+	"NL": {"528", "155"},
+	// Australia and New Zealand
+	"AU": {"036", "053"}, "CC": {"166", "053"}, "CX": {"162", "053"}, "HM": {"334", "053"},
+	"NF": {"574", "053"}, "NZ": {"554", "053"},
+	// Melanesia
+	"FJ": {"242", "054"}, "NC": {"540", "054"}, "PG": {"598", "054"}, "SB": {"090", "054"},
+	"VU": {"548", "054"},
+	// Micronesia
+	"FM": {"583", "057"}, "GU": {"316", "057"}, "KI": {"296", "057"}, "MH": {"584", "057"},
+	"MP": {"580", "057"}, "NR": {"520", "057"}, "PW": {"585", "057"}, "UM": {"581", "057"},
+	// Polynesia
+	"AS": {"016", "061"}, "CK": {"184", "061"}, "NU": {"570", "061"}, "PF": {"258", "061"},
+	"PN": {"612", "061"}, "TK": {"772", "061"}, "TO": {"776", "061"}, "TV": {"798", "061"},
+	"WF": {"876", "061"}, "WS": {"882", "061"},
+	// Antarctica
+	"AQ": {"010", ""},
+}
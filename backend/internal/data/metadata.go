@@ -0,0 +1,231 @@
+package data
+
+import (
+	"strings"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// countryMetadata is the reference data for one entry of List.
+type countryMetadata struct {
+	capital    string
+	population int64
+	currencies []string
+	languages  []string
+}
+
+// CountryMetadata returns the capital, population, currencies and languages of a listed
+// sovereign country (case-insensitive code), or false when none is bundled (territories).
+func CountryMetadata(code string) (models.CountryMetadata, bool) {
+	m, ok := metadata[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return models.CountryMetadata{}, false
+	}
+	return models.CountryMetadata{
+		Capital:    m.capital,
+		Population: m.population,
+		Currencies: append([]string(nil), m.currencies...),
+		Languages:  append([]string(nil), m.languages...),
+	}, true
+}
+
+// metadata maps the codes in List to their reference data. Capitals are the official capital
+// in English; populations are recent estimates. Currencies are ISO 4217 codes of legal tender;
+// languages are ISO 639 codes (two-letter where one exists) of official or national languages.
+var metadata = map[string]countryMetadata{
+	"AF": {"Kabul", 41_454_761, []string{"AFN"}, []string{"ps", "uz", "tk"}},
+	"AL": {"Tirana", 2_745_972, []string{"ALL"}, []string{"sq"}},
+	"DZ": {"Algiers", 45_606_480, []string{"DZD"}, []string{"ar", "ber"}},
+	"AD": {"Andorra la Vella", 80_088, []string{"EUR"}, []string{"ca"}},
+	"AO": {"Luanda", 36_684_202, []string{"AOA"}, []string{"pt"}},
+	"AG": {"St. John's", 93_772, []string{"XCD"}, []string{"en"}},
+	"AR": {"Buenos Aires", 46_654_581, []string{"ARS"}, []string{"es"}},
+	"AM": {"Yerevan", 2_777_970, []string{"AMD"}, []string{"hy"}},
+	"AU": {"Canberra", 26_638_544, []string{"AUD"}, []string{"en"}},
+	"AT": {"Vienna", 9_132_383, []string{"EUR"}, []string{"de"}},
+	"AZ": {"Baku", 10_412_651, []string{"AZN"}, []string{"az"}},
+	"BS": {"Nassau", 412_623, []string{"BSD"}, []string{"en"}},
+	"BH": {"Manama", 1_485_509, []string{"BHD"}, []string{"ar"}},
+	"BD": {"Dhaka", 172_954_319, []string{"BDT"}, []string{"bn"}},
+	"BB": {"Bridgetown", 281_995, []string{"BBD"}, []string{"en"}},
+	"BY": {"Minsk", 9_498_238, []string{"BYN"}, []string{"be", "ru"}},
+	"BE": {"Brussels", 11_822_592, []string{"EUR"}, []string{"nl", "fr", "de"}},
+	"BZ": {"Belmopan", 410_825, []string{"BZD"}, []string{"en"}},
+	"BJ": {"Porto-Novo", 13_712_828, []string{"XOF"}, []string{"fr"}},
+	"BT": {"Thimphu", 787_424, []string{"BTN", "INR"}, []string{"dz"}},
+	"BO": {"Sucre", 12_388_571, []string{"BOB"}, []string{"es", "ay", "qu"}},
+	"BA": {"Sarajevo", 3_210_847, []string{"BAM"}, []string{"bs", "hr", "sr"}},
+	"BW": {"Gaborone", 2_675_352, []string{"BWP"}, []string{"en", "tn"}},
+	"BR": {"Brasília", 216_422_446, []string{"BRL"}, []string{"pt"}},
+	"BN": {"Bandar Seri Begawan", 452_524, []string{"BND"}, []string{"ms"}},
+	"BG": {"Sofia", 6_687_717, []string{"BGN"}, []string{"bg"}},
+	"BF": {"Ouagadougou", 23_251_485, []string{"XOF"}, []string{"fr"}},
+	"BI": {"Gitega", 13_238_559, []string{"BIF"}, []string{"rn", "fr", "en"}},
+	"CV": {"Praia", 598_682, []string{"CVE"}, []string{"pt"}},
+	"KH": {"Phnom Penh", 16_944_826, []string{"KHR"}, []string{"km"}},
+	"CM": {"Yaoundé", 28_647_293, []string{"XAF"}, []string{"fr", "en"}},
+	"CA": {"Ottawa", 40_097_761, []string{"CAD"}, []string{"en", "fr"}},
+	"CF": {"Bangui", 5_742_315, []string{"XAF"}, []string{"fr", "sg"}},
+	"TD": {"N'Djamena", 18_278_568, []string{"XAF"}, []string{"fr", "ar"}},
+	"CL": {"Santiago", 19_629_590, []string{"CLP"}, []string{"es"}},
+	"CN": {"Beijing", 1_410_710_000, []string{"CNY"}, []string{"zh"}},
+	"CO": {"Bogotá", 52_085_168, []string{"COP"}, []string{"es"}},
+	"KM": {"Moroni", 852_075, []string{"KMF"}, []string{"ar", "fr"}},
+	"CG": {"Brazzaville", 6_106_869, []string{"XAF"}, []string{"fr"}},
+	"CD": {"Kinshasa", 102_262_808, []string{"CDF"}, []string{"fr"}},
+	"CR": {"San José", 5_212_173, []string{"CRC"}, []string{"es"}},
+	"HR": {"Zagreb", 3_859_686, []string{"EUR"}, []string{"hr"}},
+	"CU": {"Havana", 11_194_449, []string{"CUP"}, []string{"es"}},
+	"CY": {"Nicosia", 1_260_138, []string{"EUR"}, []string{"el", "tr"}},
+	"CZ": {"Prague", 10_900_555, []string{"CZK"}, []string{"cs"}},
+	"DK": {"Copenhagen", 5_946_984, []string{"DKK"}, []string{"da"}},
+	"DJ": {"Djibouti", 1_136_455, []string{"DJF"}, []string{"fr", "ar"}},
+	"DM": {"Roseau", 73_040, []string{"XCD"}, []string{"en"}},
+	"DO": {"Santo Domingo", 11_332_972, []string{"DOP"}, []string{"es"}},
+	"EC": {"Quito", 18_190_484, []string{"USD"}, []string{"es"}},
+	"EG": {"Cairo", 112_716_598, []string{"EGP"}, []string{"ar"}},
+	"SV": {"San Salvador", 6_364_943, []string{"USD"}, []string{"es"}},
+	"GQ": {"Malabo", 1_714_671, []string{"XAF"}, []string{"es", "fr", "pt"}},
+	"ER": {"Asmara", 3_748_901, []string{"ERN"}, []string{"ti", "ar", "en"}},
+	"EE": {"Tallinn", 1_366_491, []string{"EUR"}, []string{"et"}},
+	"SZ": {"Mbabane", 1_210_822, []string{"SZL", "ZAR"}, []string{"en", "ss"}},
+	"ET": {"Addis Ababa", 126_527_060, []string{"ETB"}, []string{"am"}},
+	"FJ": {"Suva", 936_375, []string{"FJD"}, []string{"en", "fj", "hi"}},
+	"FI": {"Helsinki", 5_584_264, []string{"EUR"}, []string{"fi", "sv"}},
+	"FR": {"Paris", 68_170_228, []string{"EUR"}, []string{"fr"}},
+	"GA": {"Libreville", 2_436_566, []string{"XAF"}, []string{"fr"}},
+	"GM": {"Banjul", 2_773_168, []string{"GMD"}, []string{"en"}},
+	"GE": {"Tbilisi", 3_760_365, []string{"GEL"}, []string{"ka"}},
+	"DE": {"Berlin", 84_482_267, []string{"EUR"}, []string{"de"}},
+	"GH": {"Accra", 34_121_985, []string{"GHS"}, []string{"en"}},
+	"GR": {"Athens", 10_361_295, []string{"EUR"}, []string{"el"}},
+	"GD": {"St. George's", 126_183, []string{"XCD"}, []string{"en"}},
+	"GT": {"Guatemala City", 18_092_026, []string{"GTQ"}, []string{"es"}},
+	"GN": {"Conakry", 14_190_612, []string{"GNF"}, []string{"fr"}},
+	"GW": {"Bissau", 2_150_842, []string{"XOF"}, []string{"pt"}},
+	"GY": {"Georgetown", 813_834, []string{"GYD"}, []string{"en"}},
+	"HT": {"Port-au-Prince", 11_724_763, []string{"HTG"}, []string{"fr", "ht"}},
+	"HN": {"Tegucigalpa", 10_593_798, []string{"HNL"}, []string{"es"}},
+	"HU": {"Budapest", 9_589_872, []string{"HUF"}, []string{"hu"}},
+	"IS": {"Reykjavík", 393_600, []string{"ISK"}, []string{"is"}},
+	"IN": {"New Delhi", 1_428_627_663, []string{"INR"}, []string{"hi", "en"}},
+	"ID": {"Jakarta", 277_534_122, []string{"IDR"}, []string{"id"}},
+	"IR": {"Tehran", 89_172_767, []string{"IRR"}, []string{"fa"}},
+	"IQ": {"Baghdad", 45_504_560, []string{"IQD"}, []string{"ar", "ku"}},
+	"IE": {"Dublin", 5_262_382, []string{"EUR"}, []string{"ga", "en"}},
+	"IL": {"Jerusalem", 9_756_700, []string{"ILS"}, []string{"he"}},
+	"IT": {"Rome", 58_993_475, []string{"EUR"}, []string{"it"}},
+	"CI": {"Yamoussoukro", 28_873_034, []string{"XOF"}, []string{"fr"}},
+	"JM": {"Kingston", 2_825_544, []string{"JMD"}, []string{"en"}},
+	"JP": {"Tokyo", 124_516_650, []string{"JPY"}, []string{"ja"}},
+	"JO": {"Amman", 11_337_052, []string{"JOD"}, []string{"ar"}},
+	"KZ": {"Astana", 19_899_120, []string{"KZT"}, []string{"kk", "ru"}},
+	"KE": {"Nairobi", 55_100_586, []string{"KES"}, []string{"sw", "en"}},
+	"KI": {"South Tarawa", 133_515, []string{"AUD"}, []string{"en"}},
+	"KP": {"Pyongyang", 26_160_821, []string{"KPW"}, []string{"ko"}},
+	"KR": {"Seoul", 51_712_619, []string{"KRW"}, []string{"ko"}},
+	"KW": {"Kuwait City", 4_310_108, []string{"KWD"}, []string{"ar"}},
+	"KG": {"Bishkek", 7_100_000, []string{"KGS"}, []string{"ky", "ru"}},
+	"LA": {"Vientiane", 7_633_779, []string{"LAK"}, []string{"lo"}},
+	"LV": {"Riga", 1_883_162, []string{"EUR"}, []string{"lv"}},
+	"LB": {"Beirut", 5_353_930, []string{"LBP"}, []string{"ar"}},
+	"LS": {"Maseru", 2_330_318, []string{"LSL", "ZAR"}, []string{"st", "en"}},
+	"LR": {"Monrovia", 5_418_377, []string{"LRD"}, []string{"en"}},
+	"LY": {"Tripoli", 6_888_388, []string{"LYD"}, []string{"ar"}},
+	"LI": {"Vaduz", 39_584, []string{"CHF"}, []string{"de"}},
+	"LT": {"Vilnius", 2_871_897, []string{"EUR"}, []string{"lt"}},
+	"LU": {"Luxembourg", 660_809, []string{"EUR"}, []string{"lb", "fr", "de"}},
+	"MG": {"Antananarivo", 30_325_732, []string{"MGA"}, []string{"mg", "fr"}},
+	"MW": {"Lilongwe", 20_931_751, []string{"MWK"}, []string{"en", "ny"}},
+	"MY": {"Kuala Lumpur", 34_308_525, []string{"MYR"}, []string{"ms"}},
+	"MV": {"Malé", 521_021, []string{"MVR"}, []string{"dv"}},
+	"ML": {"Bamako", 23_293_698, []string{"XOF"}, []string{"bm"}},
+	"MT": {"Valletta", 553_214, []string{"EUR"}, []string{"mt", "en"}},
+	"MH": {"Majuro", 41_996, []string{"USD"}, []string{"mh", "en"}},
+	"MR": {"Nouakchott", 4_862_989, []string{"MRU"}, []string{"ar"}},
+	"MU": {"Port Louis", 1_261_041, []string{"MUR"}, []string{"en", "fr"}},
+	"MX": {"Mexico City", 128_455_567, []string{"MXN"}, []string{"es"}},
+	"FM": {"Palikir", 115_224, []string{"USD"}, []string{"en"}},
+	"MD": {"Chișinău", 2_486_891, []string{"MDL"}, []string{"ro"}},
+	"MC": {"Monaco", 38_956, []string{"EUR"}, []string{"fr"}},
+	"MN": {"Ulaanbaatar", 3_447_157, []string{"MNT"}, []string{"mn"}},
+	"ME": {"Podgorica", 616_695, []string{"EUR"}, []string{"cnr"}},
+	"MA": {"Rabat", 37_840_044, []string{"MAD"}, []string{"ar", "ber"}},
+	"MZ": {"Maputo", 33_897_354, []string{"MZN"}, []string{"pt"}},
+	"MM": {"Naypyidaw", 54_577_997, []string{"MMK"}, []string{"my"}},
+	"NA": {"Windhoek", 2_604_172, []string{"NAD", "ZAR"}, []string{"en"}},
+	"NR": {"Yaren", 12_780, []string{"AUD"}, []string{"na", "en"}},
+	"NP": {"Kathmandu", 30_896_590, []string{"NPR"}, []string{"ne"}},
+	"NL": {"Amsterdam", 17_879_488, []string{"EUR"}, []string{"nl"}},
+	"NZ": {"Wellington", 5_223_100, []string{"NZD"}, []string{"en", "mi"}},
+	"NI": {"Managua", 7_046_310, []string{"NIO"}, []string{"es"}},
+	"NE": {"Niamey", 27_202_843, []string{"XOF"}, []string{"fr"}},
+	"NG": {"Abuja", 223_804_632, []string{"NGN"}, []string{"en"}},
+	"MK": {"Skopje", 1_826_247, []string{"MKD"}, []string{"mk", "sq"}},
+	"NO": {"Oslo", 5_519_594, []string{"NOK"}, []string{"no"}},
+	"OM": {"Muscat", 4_644_384, []string{"OMR"}, []string{"ar"}},
+	"PK": {"Islamabad", 240_485_658, []string{"PKR"}, []string{"ur", "en"}},
+	"PW": {"Ngerulmud", 18_058, []string{"USD"}, []string{"en", "pau"}},
+	"PA": {"Panama City", 4_468_087, []string{"PAB", "USD"}, []string{"es"}},
+	"PG": {"Port Moresby", 10_329_931, []string{"PGK"}, []string{"en", "tpi", "ho"}},
+	"PY": {"Asunción", 6_861_524, []string{"PYG"}, []string{"es", "gn"}},
+	"PE": {"Lima", 34_352_719, []string{"PEN"}, []string{"es", "qu", "ay"}},
+	"PH": {"Manila", 117_337_368, []string{"PHP"}, []string{"fil", "en"}},
+	"PL": {"Warsaw", 36_753_736, []string{"PLN"}, []string{"pl"}},
+	"PT": {"Lisbon", 10_467_366, []string{"EUR"}, []string{"pt"}},
+	"QA": {"Doha", 2_716_391, []string{"QAR"}, []string{"ar"}},
+	"RO": {"Bucharest", 19_051_562, []string{"RON"}, []string{"ro"}},
+	"RU": {"Moscow", 144_444_359, []string{"RUB"}, []string{"ru"}},
+	"RW": {"Kigali", 14_094_683, []string{"RWF"}, []string{"rw", "en", "fr", "sw"}},
+	"KN": {"Basseterre", 47_755, []string{"XCD"}, []string{"en"}},
+	"LC": {"Castries", 180_251, []string{"XCD"}, []string{"en"}},
+	"VC": {"Kingstown", 103_698, []string{"XCD"}, []string{"en"}},
+	"WS": {"Apia", 225_681, []string{"WST"}, []string{"sm", "en"}},
+	"SM": {"San Marino", 33_642, []string{"EUR"}, []string{"it"}},
+	"ST": {"São Tomé", 231_856, []string{"STN"}, []string{"pt"}},
+	"SA": {"Riyadh", 36_947_025, []string{"SAR"}, []string{"ar"}},
+	"SN": {"Dakar", 17_763_163, []string{"XOF"}, []string{"fr"}},
+	"RS": {"Belgrade", 6_623_183, []string{"RSD"}, []string{"sr"}},
+	"SC": {"Victoria", 119_773, []string{"SCR"}, []string{"fr", "en", "crs"}},
+	"SL": {"Freetown", 8_791_092, []string{"SLE"}, []string{"en"}},
+	"SG": {"Singapore", 5_917_648, []string{"SGD"}, []string{"en", "ms", "zh", "ta"}},
+	"SK": {"Bratislava", 5_428_792, []string{"EUR"}, []string{"sk"}},
+	"SI": {"Ljubljana", 2_119_675, []string{"EUR"}, []string{"sl"}},
+	"SB": {"Honiara", 740_424, []string{"SBD"}, []string{"en"}},
+	"SO": {"Mogadishu", 18_143_378, []string{"SOS"}, []string{"so", "ar"}},
+	"ZA": {"Pretoria", 62_027_503, []string{"ZAR"}, []string{"zu", "xh", "af", "en"}},
+	"SS": {"Juba", 11_088_796, []string{"SSP"}, []string{"en"}},
+	"ES": {"Madrid", 48_085_361, []string{"EUR"}, []string{"es"}},
+	"LK": {"Sri Jayawardenepura Kotte", 21_893_579, []string{"LKR"}, []string{"si", "ta"}},
+	"SD": {"Khartoum", 48_109_006, []string{"SDG"}, []string{"ar", "en"}},
+	"SR": {"Paramaribo", 623_236, []string{"SRD"}, []string{"nl"}},
+	"SE": {"Stockholm", 10_551_707, []string{"SEK"}, []string{"sv"}},
+	"CH": {"Bern", 8_849_852, []string{"CHF"}, []string{"de", "fr", "it", "rm"}},
+	"SY": {"Damascus", 23_227_014, []string{"SYP"}, []string{"ar"}},
+	"TW": {"Taipei", 23_420_442, []string{"TWD"}, []string{"zh"}},
+	"TJ": {"Dushanbe", 10_143_543, []string{"TJS"}, []string{"tg"}},
+	"TZ": {"Dodoma", 67_438_106, []string{"TZS"}, []string{"sw", "en"}},
+	"TH": {"Bangkok", 71_801_279, []string{"THB"}, []string{"th"}},
+	"TL": {"Dili", 1_360_596, []string{"USD"}, []string{"tet", "pt"}},
+	"TG": {"Lomé", 9_053_799, []string{"XOF"}, []string{"fr"}},
+	"TO": {"Nukuʻalofa", 107_773, []string{"TOP"}, []string{"to", "en"}},
+	"TT": {"Port of Spain", 1_534_937, []string{"TTD"}, []string{"en"}},
+	"TN": {"Tunis", 12_458_223, []string{"TND"}, []string{"ar"}},
+	"TR": {"Ankara", 85_326_000, []string{"TRY"}, []string{"tr"}},
+	"TM": {"Ashgabat", 6_516_100, []string{"TMT"}, []string{"tk"}},
+	"TV": {"Funafuti", 11_396, []string{"AUD", "TVD"}, []string{"tvl", "en"}},
+	"UG": {"Kampala", 48_582_334, []string{"UGX"}, []string{"en", "sw"}},
+	"UA": {"Kyiv", 37_000_000, []string{"UAH"}, []string{"uk"}},
+	"AE": {"Abu Dhabi", 9_516_871, []string{"AED"}, []string{"ar"}},
+	"GB": {"London", 68_350_000, []string{"GBP"}, []string{"en"}},
+	"US": {"Washington, D.C.", 334_914_895, []string{"USD"}, []string{"en"}},
+	"UY": {"Montevideo", 3_423_108, []string{"UYU"}, []string{"es"}},
+	"UZ": {"Tashkent", 36_412_350, []string{"UZS"}, []string{"uz"}},
+	"VU": {"Port Vila", 334_506, []string{"VUV"}, []string{"bi", "en", "fr"}},
+	"VA": {"Vatican City", 764, []string{"EUR"}, []string{"it", "la"}},
+	"VE": {"Caracas", 28_838_499, []string{"VES"}, []string{"es"}},
+	"VN": {"Hanoi", 100_300_000, []string{"VND"}, []string{"vi"}},
+	"YE": {"Sanaa", 34_449_825, []string{"YER"}, []string{"ar"}},
+	"ZM": {"Lusaka", 20_569_737, []string{"ZMW"}, []string{"en"}},
+	"ZW": {"Harare", 16_665_409, []string{"ZWL", "USD"}, []string{"en", "sn", "nd"}},
+}
@@ -0,0 +1,17 @@
+package data
+
+import "github.com/matti777/my-countries/backend/internal/models"
+
+// Neighbors returns the alpha-2 codes of the countries and territories sharing a land border
+// with code (alpha-2 or alpha-3, case-insensitive), in code order. Island states and unknown
+// codes have none. Neighbors deprecated by a country override are left out.
+func Neighbors(code string) []string {
+	cat := current.Load()
+	var out []string
+	for _, n := range neighbors[models.NormalizeCountryCode(code)] {
+		if c, ok := cat.byCode[n]; ok && !c.Deprecated {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,23 @@
+package data
+
+// Regions lists the continent codes used in Country.RegionCode, in display order.
+var Regions = []string{"EU", "AS", "AF", "NA", "SA", "OC", "AN"}
+
+// regionNames maps continent codes to English display names.
+var regionNames = map[string]string{
+	"AF": "Africa",
+	"AN": "Antarctica",
+	"AS": "Asia",
+	"EU": "Europe",
+	"NA": "North America",
+	"OC": "Oceania",
+	"SA": "South America",
+}
+
+// RegionName returns the display name for a continent code, or the code itself when unknown.
+func RegionName(code string) string {
+	if name, ok := regionNames[code]; ok {
+		return name
+	}
+	return code
+}
@@ -0,0 +1,164 @@
+package subdivisions
+
+import "github.com/matti777/my-countries/backend/internal/models"
+
+// list holds the bundled ISO 3166-2 subdivisions, grouped by country and ordered by code.
+// Only countries whose travellers commonly track visits at state level are included.
+var list = []models.Subdivision{
+	// Australia
+	{Code: "AU-ACT", CountryCode: "AU", Name: "Australian Capital Territory", Type: "territory"},
+	{Code: "AU-NSW", CountryCode: "AU", Name: "New South Wales", Type: "state"},
+	{Code: "AU-NT", CountryCode: "AU", Name: "Northern Territory", Type: "territory"},
+	{Code: "AU-QLD", CountryCode: "AU", Name: "Queensland", Type: "state"},
+	{Code: "AU-SA", CountryCode: "AU", Name: "South Australia", Type: "state"},
+	{Code: "AU-TAS", CountryCode: "AU", Name: "Tasmania", Type: "state"},
+	{Code: "AU-VIC", CountryCode: "AU", Name: "Victoria", Type: "state"},
+	{Code: "AU-WA", CountryCode: "AU", Name: "Western Australia", Type: "state"},
+
+	// Canada
+	{Code: "CA-AB", CountryCode: "CA", Name: "Alberta", Type: "province"},
+	{Code: "CA-BC", CountryCode: "CA", Name: "British Columbia", Type: "province"},
+	{Code: "CA-MB", CountryCode: "CA", Name: "Manitoba", Type: "province"},
+	{Code: "CA-NB", CountryCode: "CA", Name: "New Brunswick", Type: "province"},
+	{Code: "CA-NL", CountryCode: "CA", Name: "Newfoundland and Labrador", Type: "province"},
+	{Code: "CA-NS", CountryCode: "CA", Name: "Nova Scotia", Type: "province"},
+	{Code: "CA-NT", CountryCode: "CA", Name: "Northwest Territories", Type: "territory"},
+	{Code: "CA-NU", CountryCode: "CA", Name: "Nunavut", Type: "territory"},
+	{Code: "CA-ON", CountryCode: "CA", Name: "Ontario", Type: "province"},
+	{Code: "CA-PE", CountryCode: "CA", Name: "Prince Edward Island", Type: "province"},
+	{Code: "CA-QC", CountryCode: "CA", Name: "Quebec", Type: "province"},
+	{Code: "CA-SK", CountryCode: "CA", Name: "Saskatchewan", Type: "province"},
+	{Code: "CA-YT", CountryCode: "CA", Name: "Yukon", Type: "territory"},
+
+	// Germany
+	{Code: "DE-BB", CountryCode: "DE", Name: "Brandenburg", Type: "state"},
+	{Code: "DE-BE", CountryCode: "DE", Name: "Berlin", Type: "state"},
+	{Code: "DE-BW", CountryCode: "DE", Name: "Baden-Württemberg", Type: "state"},
+	{Code: "DE-BY", CountryCode: "DE", Name: "Bavaria", Type: "state"},
+	{Code: "DE-HB", CountryCode: "DE", Name: "Bremen", Type: "state"},
+	{Code: "DE-HE", CountryCode: "DE", Name: "Hesse", Type: "state"},
+	{Code: "DE-HH", CountryCode: "DE", Name: "Hamburg", Type: "state"},
+	{Code: "DE-MV", CountryCode: "DE", Name: "Mecklenburg-Western Pomerania", Type: "state"},
+	{Code: "DE-NI", CountryCode: "DE", Name: "Lower Saxony", Type: "state"},
+	{Code: "DE-NW", CountryCode: "DE", Name: "North Rhine-Westphalia", Type: "state"},
+	{Code: "DE-RP", CountryCode: "DE", Name: "Rhineland-Palatinate", Type: "state"},
+	{Code: "DE-SH", CountryCode: "DE", Name: "Schleswig-Holstein", Type: "state"},
+	{Code: "DE-SL", CountryCode: "DE", Name: "Saarland", Type: "state"},
+	{Code: "DE-SN", CountryCode: "DE", Name: "Saxony", Type: "state"},
+	{Code: "DE-ST", CountryCode: "DE", Name: "Saxony-Anhalt", Type: "state"},
+	{Code: "DE-TH", CountryCode: "DE", Name: "Thuringia", Type: "state"},
+
+	// Finland
+	{Code: "FI-01", CountryCode: "FI", Name: "Åland", Type: "region"},
+	{Code: "FI-02", CountryCode: "FI", Name: "South Karelia", Type: "region"},
+	{Code: "FI-03", CountryCode: "FI", Name: "South Ostrobothnia", Type: "region"},
+	{Code: "FI-04", CountryCode: "FI", Name: "South Savo", Type: "region"},
+	{Code: "FI-05", CountryCode: "FI", Name: "Kainuu", Type: "region"},
+	{Code: "FI-06", CountryCode: "FI", Name: "Tavastia Proper", Type: "region"},
+	{Code: "FI-07", CountryCode: "FI", Name: "Central Ostrobothnia", Type: "region"},
+	{Code: "FI-08", CountryCode: "FI", Name: "Central Finland", Type: "region"},
+	{Code: "FI-09", CountryCode: "FI", Name: "Kymenlaakso", Type: "region"},
+	{Code: "FI-10", CountryCode: "FI", Name: "Lapland", Type: "region"},
+	{Code: "FI-11", CountryCode: "FI", Name: "Pirkanmaa", Type: "region"},
+	{Code: "FI-12", CountryCode: "FI", Name: "Ostrobothnia", Type: "region"},
+	{Code: "FI-13", CountryCode: "FI", Name: "North Karelia", Type: "region"},
+	{Code: "FI-14", CountryCode: "FI", Name: "North Ostrobothnia", Type: "region"},
+	{Code: "FI-15", CountryCode: "FI", Name: "North Savo", Type: "region"},
+	{Code: "FI-16", CountryCode: "FI", Name: "Päijät-Häme", Type: "region"},
+	{Code: "FI-17", CountryCode: "FI", Name: "Satakunta", Type: "region"},
+	{Code: "FI-18", CountryCode: "FI", Name: "Uusimaa", Type: "region"},
+	{Code: "FI-19", CountryCode: "FI", Name: "Southwest Finland", Type: "region"},
+
+	// United Kingdom (constituent countries)
+	{Code: "GB-ENG", CountryCode: "GB", Name: "England", Type: "country"},
+	{Code: "GB-NIR", CountryCode: "GB", Name: "Northern Ireland", Type: "province"},
+	{Code: "GB-SCT", CountryCode: "GB", Name: "Scotland", Type: "country"},
+	{Code: "GB-WLS", CountryCode: "GB", Name: "Wales", Type: "country"},
+
+	// Mexico
+	{Code: "MX-AGU", CountryCode: "MX", Name: "Aguascalientes", Type: "state"},
+	{Code: "MX-BCN", CountryCode: "MX", Name: "Baja California", Type: "state"},
+	{Code: "MX-BCS", CountryCode: "MX", Name: "Baja California Sur", Type: "state"},
+	{Code: "MX-CAM", CountryCode: "MX", Name: "Campeche", Type: "state"},
+	{Code: "MX-CHH", CountryCode: "MX", Name: "Chihuahua", Type: "state"},
+	{Code: "MX-CHP", CountryCode: "MX", Name: "Chiapas", Type: "state"},
+	{Code: "MX-CMX", CountryCode: "MX", Name: "Mexico City", Type: "federal entity"},
+	{Code: "MX-COA", CountryCode: "MX", Name: "Coahuila", Type: "state"},
+	{Code: "MX-COL", CountryCode: "MX", Name: "Colima", Type: "state"},
+	{Code: "MX-DUR", CountryCode: "MX", Name: "Durango", Type: "state"},
+	{Code: "MX-GRO", CountryCode: "MX", Name: "Guerrero", Type: "state"},
+	{Code: "MX-GUA", CountryCode: "MX", Name: "Guanajuato", Type: "state"},
+	{Code: "MX-HID", CountryCode: "MX", Name: "Hidalgo", Type: "state"},
+	{Code: "MX-JAL", CountryCode: "MX", Name: "Jalisco", Type: "state"},
+	{Code: "MX-MEX", CountryCode: "MX", Name: "State of Mexico", Type: "state"},
+	{Code: "MX-MIC", CountryCode: "MX", Name: "Michoacán", Type: "state"},
+	{Code: "MX-MOR", CountryCode: "MX", Name: "Morelos", Type: "state"},
+	{Code: "MX-NAY", CountryCode: "MX", Name: "Nayarit", Type: "state"},
+	{Code: "MX-NLE", CountryCode: "MX", Name: "Nuevo León", Type: "state"},
+	{Code: "MX-OAX", CountryCode: "MX", Name: "Oaxaca", Type: "state"},
+	{Code: "MX-PUE", CountryCode: "MX", Name: "Puebla", Type: "state"},
+	{Code: "MX-QUE", CountryCode: "MX", Name: "Querétaro", Type: "state"},
+	{Code: "MX-ROO", CountryCode: "MX", Name: "Quintana Roo", Type: "state"},
+	{Code: "MX-SIN", CountryCode: "MX", Name: "Sinaloa", Type: "state"},
+	{Code: "MX-SLP", CountryCode: "MX", Name: "San Luis Potosí", Type: "state"},
+	{Code: "MX-SON", CountryCode: "MX", Name: "Sonora", Type: "state"},
+	{Code: "MX-TAB", CountryCode: "MX", Name: "Tabasco", Type: "state"},
+	{Code: "MX-TAM", CountryCode: "MX", Name: "Tamaulipas", Type: "state"},
+	{Code: "MX-TLA", CountryCode: "MX", Name: "Tlaxcala", Type: "state"},
+	{Code: "MX-VER", CountryCode: "MX", Name: "Veracruz", Type: "state"},
+	{Code: "MX-YUC", CountryCode: "MX", Name: "Yucatán", Type: "state"},
+	{Code: "MX-ZAC", CountryCode: "MX", Name: "Zacatecas", Type: "state"},
+
+	// United States
+	{Code: "US-AK", CountryCode: "US", Name: "Alaska", Type: "state"},
+	{Code: "US-AL", CountryCode: "US", Name: "Alabama", Type: "state"},
+	{Code: "US-AR", CountryCode: "US", Name: "Arkansas", Type: "state"},
+	{Code: "US-AZ", CountryCode: "US", Name: "Arizona", Type: "state"},
+	{Code: "US-CA", CountryCode: "US", Name: "California", Type: "state"},
+	{Code: "US-CO", CountryCode: "US", Name: "Colorado", Type: "state"},
+	{Code: "US-CT", CountryCode: "US", Name: "Connecticut", Type: "state"},
+	{Code: "US-DC", CountryCode: "US", Name: "District of Columbia", Type: "district"},
+	{Code: "US-DE", CountryCode: "US", Name: "Delaware", Type: "state"},
+	{Code: "US-FL", CountryCode: "US", Name: "Florida", Type: "state"},
+	{Code: "US-GA", CountryCode: "US", Name: "Georgia", Type: "state"},
+	{Code: "US-HI", CountryCode: "US", Name: "Hawaii", Type: "state"},
+	{Code: "US-IA", CountryCode: "US", Name: "Iowa", Type: "state"},
+	{Code: "US-ID", CountryCode: "US", Name: "Idaho", Type: "state"},
+	{Code: "US-IL", CountryCode: "US", Name: "Illinois", Type: "state"},
+	{Code: "US-IN", CountryCode: "US", Name: "Indiana", Type: "state"},
+	{Code: "US-KS", CountryCode: "US", Name: "Kansas", Type: "state"},
+	{Code: "US-KY", CountryCode: "US", Name: "Kentucky", Type: "state"},
+	{Code: "US-LA", CountryCode: "US", Name: "Louisiana", Type: "state"},
+	{Code: "US-MA", CountryCode: "US", Name: "Massachusetts", Type: "state"},
+	{Code: "US-MD", CountryCode: "US", Name: "Maryland", Type: "state"},
+	{Code: "US-ME", CountryCode: "US", Name: "Maine", Type: "state"},
+	{Code: "US-MI", CountryCode: "US", Name: "Michigan", Type: "state"},
+	{Code: "US-MN", CountryCode: "US", Name: "Minnesota", Type: "state"},
+	{Code: "US-MO", CountryCode: "US", Name: "Missouri", Type: "state"},
+	{Code: "US-MS", CountryCode: "US", Name: "Mississippi", Type: "state"},
+	{Code: "US-MT", CountryCode: "US", Name: "Montana", Type: "state"},
+	{Code: "US-NC", CountryCode: "US", Name: "North Carolina", Type: "state"},
+	{Code: "US-ND", CountryCode: "US", Name: "North Dakota", Type: "state"},
+	{Code: "US-NE", CountryCode: "US", Name: "Nebraska", Type: "state"},
+	{Code: "US-NH", CountryCode: "US", Name: "New Hampshire", Type: "state"},
+	{Code: "US-NJ", CountryCode: "US", Name: "New Jersey", Type: "state"},
+	{Code: "US-NM", CountryCode: "US", Name: "New Mexico", Type: "state"},
+	{Code: "US-NV", CountryCode: "US", Name: "Nevada", Type: "state"},
+	{Code: "US-NY", CountryCode: "US", Name: "New York", Type: "state"},
+	{Code: "US-OH", CountryCode: "US", Name: "Ohio", Type: "state"},
+	{Code: "US-OK", CountryCode: "US", Name: "Oklahoma", Type: "state"},
+	{Code: "US-OR", CountryCode: "US", Name: "Oregon", Type: "state"},
+	{Code: "US-PA", CountryCode: "US", Name: "Pennsylvania", Type: "state"},
+	{Code: "US-RI", CountryCode: "US", Name: "Rhode Island", Type: "state"},
+	{Code: "US-SC", CountryCode: "US", Name: "South Carolina", Type: "state"},
+	{Code: "US-SD", CountryCode: "US", Name: "South Dakota", Type: "state"},
+	{Code: "US-TN", CountryCode: "US", Name: "Tennessee", Type: "state"},
+	{Code: "US-TX", CountryCode: "US", Name: "Texas", Type: "state"},
+	{Code: "US-UT", CountryCode: "US", Name: "Utah", Type: "state"},
+	{Code: "US-VA", CountryCode: "US", Name: "Virginia", Type: "state"},
+	{Code: "US-VT", CountryCode: "US", Name: "Vermont", Type: "state"},
+	{Code: "US-WA", CountryCode: "US", Name: "Washington", Type: "state"},
+	{Code: "US-WI", CountryCode: "US", Name: "Wisconsin", Type: "state"},
+	{Code: "US-WV", CountryCode: "US", Name: "West Virginia", Type: "state"},
+	{Code: "US-WY", CountryCode: "US", Name: "Wyoming", Type: "state"},
+}
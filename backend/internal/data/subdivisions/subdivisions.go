@@ -0,0 +1,49 @@
+// Package subdivisions holds bundled ISO 3166-2 subdivision reference data (states, provinces,
+// regions) for the countries listed in list.go.
+package subdivisions
+
+import (
+	"strings"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// byCode maps ISO 3166-2 codes to their entries.
+var byCode map[string]models.Subdivision
+
+// byCountry maps alpha-2 country codes to their subdivisions, in list order.
+var byCountry map[string][]models.Subdivision
+
+func init() {
+	byCode = make(map[string]models.Subdivision, len(list))
+	byCountry = make(map[string][]models.Subdivision)
+	for _, s := range list {
+		byCode[s.Code] = s
+		byCountry[s.CountryCode] = append(byCountry[s.CountryCode], s)
+	}
+}
+
+// ByCountry returns the subdivisions of an alpha-2 country code (case-insensitive); empty when
+// the country has none bundled.
+func ByCountry(countryCode string) []models.Subdivision {
+	subs := byCountry[strings.ToUpper(strings.TrimSpace(countryCode))]
+	out := make([]models.Subdivision, len(subs))
+	copy(out, subs)
+	return out
+}
+
+// Lookup returns the subdivision with the given ISO 3166-2 code (case-insensitive).
+func Lookup(code string) (models.Subdivision, bool) {
+	s, ok := byCode[strings.ToUpper(strings.TrimSpace(code))]
+	return s, ok
+}
+
+// Validate returns the canonical (uppercase) form of code if it is a bundled subdivision of
+// countryCode, or false.
+func Validate(countryCode, code string) (string, bool) {
+	s, ok := Lookup(code)
+	if !ok || s.CountryCode != countryCode {
+		return "", false
+	}
+	return s.Code, true
+}
@@ -0,0 +1,474 @@
+package data
+
+import (
+	"strings"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// TCCDestinations is the Travelers' Century Club destination list (GET /countries?list=tcc),
+// grouped by TCC region. TCC counts islands, regions and exclaves separately from their
+// countries; each entry links to the bundled country or territory it belongs to. TCC revises
+// its list occasionally, so this snapshot is maintained by hand. Built in init.
+var TCCDestinations []models.Destination
+
+// tccDestinationsByCode maps Destination.Code to its entry.
+var tccDestinationsByCode map[string]models.Destination
+
+// tccRegion is a TCC region and its destinations (Region is set in init).
+type tccRegion struct {
+	name         string
+	destinations []models.Destination
+}
+
+func init() {
+	tccDestinationsByCode = make(map[string]models.Destination)
+	for _, r := range tccRegions {
+		for _, d := range r.destinations {
+			d.Region = r.name
+			TCCDestinations = append(TCCDestinations, d)
+			tccDestinationsByCode[d.Code] = d
+		}
+	}
+}
+
+// TCCDestinationByCode returns the TCC destination with the given code (case-insensitive).
+func TCCDestinationByCode(code string) (models.Destination, bool) {
+	d, ok := tccDestinationsByCode[strings.ToLower(strings.TrimSpace(code))]
+	return d, ok
+}
+
+// ValidateTCCDestination returns the canonical code if code is a TCC destination belonging to
+// countryCode, or false.
+func ValidateTCCDestination(countryCode, code string) (string, bool) {
+	d, ok := TCCDestinationByCode(code)
+	if !ok || d.CountryCode != countryCode {
+		return "", false
+	}
+	return d.Code, true
+}
+
+// tccRegions holds the destinations in TCC region order, alphabetical within each region.
+var tccRegions = []tccRegion{
+	{"Pacific Ocean", []models.Destination{
+		{Code: "american-samoa", Name: "American Samoa", CountryCode: "AS"},
+		{Code: "australia", Name: "Australia", CountryCode: "AU"},
+		{Code: "austral-islands", Name: "Austral Islands", CountryCode: "PF"},
+		{Code: "bougainville", Name: "Bougainville", CountryCode: "PG"},
+		{Code: "cook-islands", Name: "Cook Islands", CountryCode: "CK"},
+		{Code: "easter-island", Name: "Easter Island", CountryCode: "CL"},
+		{Code: "fiji", Name: "Fiji", CountryCode: "FJ"},
+		{Code: "galapagos-islands", Name: "Galápagos Islands", CountryCode: "EC"},
+		{Code: "guam", Name: "Guam", CountryCode: "GU"},
+		{Code: "hawaiian-islands", Name: "Hawaiian Islands", CountryCode: "US"},
+		{Code: "howland-and-baker-islands", Name: "Howland and Baker Islands", CountryCode: "UM"},
+		{Code: "johnston-atoll", Name: "Johnston Atoll", CountryCode: "UM"},
+		{Code: "juan-fernandez-islands", Name: "Juan Fernández Islands", CountryCode: "CL"},
+		{Code: "kiribati-gilbert-islands", Name: "Kiribati (Gilbert Islands)", CountryCode: "KI"},
+		{Code: "line-islands", Name: "Line Islands", CountryCode: "KI"},
+		{Code: "lord-howe-island", Name: "Lord Howe Island", CountryCode: "AU"},
+		{Code: "marquesas-islands", Name: "Marquesas Islands", CountryCode: "PF"},
+		{Code: "marshall-islands", Name: "Marshall Islands", CountryCode: "MH"},
+		{Code: "micronesia", Name: "Micronesia", CountryCode: "FM"},
+		{Code: "midway-island", Name: "Midway Island", CountryCode: "UM"},
+		{Code: "nauru", Name: "Nauru", CountryCode: "NR"},
+		{Code: "new-caledonia", Name: "New Caledonia", CountryCode: "NC"},
+		{Code: "new-zealand", Name: "New Zealand", CountryCode: "NZ"},
+		{Code: "niue", Name: "Niue", CountryCode: "NU"},
+		{Code: "norfolk-island", Name: "Norfolk Island", CountryCode: "NF"},
+		{Code: "northern-mariana-islands", Name: "Northern Mariana Islands", CountryCode: "MP"},
+		{Code: "ogasawara-islands", Name: "Ogasawara Islands", CountryCode: "JP"},
+		{Code: "palau", Name: "Palau", CountryCode: "PW"},
+		{Code: "papua-new-guinea", Name: "Papua New Guinea", CountryCode: "PG"},
+		{Code: "phoenix-islands", Name: "Phoenix Islands", CountryCode: "KI"},
+		{Code: "pitcairn-islands", Name: "Pitcairn Islands", CountryCode: "PN"},
+		{Code: "rotuma", Name: "Rotuma", CountryCode: "FJ"},
+		{Code: "ryukyu-islands", Name: "Ryukyu Islands", CountryCode: "JP"},
+		{Code: "samoa", Name: "Samoa", CountryCode: "WS"},
+		{Code: "solomon-islands", Name: "Solomon Islands", CountryCode: "SB"},
+		{Code: "tahiti-society-islands", Name: "Tahiti (Society Islands)", CountryCode: "PF"},
+		{Code: "tasmania", Name: "Tasmania", CountryCode: "AU"},
+		{Code: "tokelau", Name: "Tokelau", CountryCode: "TK"},
+		{Code: "tonga", Name: "Tonga", CountryCode: "TO"},
+		{Code: "tuamotu-archipelago", Name: "Tuamotu Archipelago", CountryCode: "PF"},
+		{Code: "tuvalu", Name: "Tuvalu", CountryCode: "TV"},
+		{Code: "vanuatu", Name: "Vanuatu", CountryCode: "VU"},
+		{Code: "wake-island", Name: "Wake Island", CountryCode: "UM"},
+		{Code: "wallis-and-futuna", Name: "Wallis and Futuna", CountryCode: "WF"},
+	}},
+	{"North America", []models.Destination{
+		{Code: "alaska", Name: "Alaska", CountryCode: "US"},
+		{Code: "canada", Name: "Canada", CountryCode: "CA"},
+		{Code: "greenland", Name: "Greenland", CountryCode: "GL"},
+		{Code: "mexico", Name: "Mexico", CountryCode: "MX"},
+		{Code: "saint-pierre-and-miquelon", Name: "Saint Pierre and Miquelon", CountryCode: "PM"},
+		{Code: "united-states", Name: "United States", CountryCode: "US"},
+	}},
+	{"Central America", []models.Destination{
+		{Code: "belize", Name: "Belize", CountryCode: "BZ"},
+		{Code: "costa-rica", Name: "Costa Rica", CountryCode: "CR"},
+		{Code: "el-salvador", Name: "El Salvador", CountryCode: "SV"},
+		{Code: "guatemala", Name: "Guatemala", CountryCode: "GT"},
+		{Code: "honduras", Name: "Honduras", CountryCode: "HN"},
+		{Code: "nicaragua", Name: "Nicaragua", CountryCode: "NI"},
+		{Code: "panama", Name: "Panama", CountryCode: "PA"},
+	}},
+	{"South America", []models.Destination{
+		{Code: "argentina", Name: "Argentina", CountryCode: "AR"},
+		{Code: "bolivia", Name: "Bolivia", CountryCode: "BO"},
+		{Code: "brazil", Name: "Brazil", CountryCode: "BR"},
+		{Code: "chile", Name: "Chile", CountryCode: "CL"},
+		{Code: "colombia", Name: "Colombia", CountryCode: "CO"},
+		{Code: "ecuador", Name: "Ecuador", CountryCode: "EC"},
+		{Code: "french-guiana", Name: "French Guiana", CountryCode: "GF"},
+		{Code: "guyana", Name: "Guyana", CountryCode: "GY"},
+		{Code: "nueva-esparta", Name: "Nueva Esparta", CountryCode: "VE"},
+		{Code: "paraguay", Name: "Paraguay", CountryCode: "PY"},
+		{Code: "peru", Name: "Peru", CountryCode: "PE"},
+		{Code: "suriname", Name: "Suriname", CountryCode: "SR"},
+		{Code: "uruguay", Name: "Uruguay", CountryCode: "UY"},
+		{Code: "venezuela", Name: "Venezuela", CountryCode: "VE"},
+	}},
+	{"Caribbean", []models.Destination{
+		{Code: "anguilla", Name: "Anguilla", CountryCode: "AI"},
+		{Code: "antigua-and-barbuda", Name: "Antigua and Barbuda", CountryCode: "AG"},
+		{Code: "aruba", Name: "Aruba", CountryCode: "AW"},
+		{Code: "bahamas", Name: "Bahamas", CountryCode: "BS"},
+		{Code: "barbados", Name: "Barbados", CountryCode: "BB"},
+		{Code: "bonaire", Name: "Bonaire", CountryCode: "BQ"},
+		{Code: "british-virgin-islands", Name: "British Virgin Islands", CountryCode: "VG"},
+		{Code: "cayman-islands", Name: "Cayman Islands", CountryCode: "KY"},
+		{Code: "cuba", Name: "Cuba", CountryCode: "CU"},
+		{Code: "curacao", Name: "Curaçao", CountryCode: "CW"},
+		{Code: "dominica", Name: "Dominica", CountryCode: "DM"},
+		{Code: "dominican-republic", Name: "Dominican Republic", CountryCode: "DO"},
+		{Code: "grenada", Name: "Grenada", CountryCode: "GD"},
+		{Code: "guadeloupe", Name: "Guadeloupe", CountryCode: "GP"},
+		{Code: "haiti", Name: "Haiti", CountryCode: "HT"},
+		{Code: "jamaica", Name: "Jamaica", CountryCode: "JM"},
+		{Code: "martinique", Name: "Martinique", CountryCode: "MQ"},
+		{Code: "montserrat", Name: "Montserrat", CountryCode: "MS"},
+		{Code: "nevis", Name: "Nevis", CountryCode: "KN"},
+		{Code: "puerto-rico", Name: "Puerto Rico", CountryCode: "PR"},
+		{Code: "saba", Name: "Saba", CountryCode: "BQ"},
+		{Code: "saint-barthelemy", Name: "Saint Barthélemy", CountryCode: "BL"},
+		{Code: "saint-eustatius", Name: "Saint Eustatius", CountryCode: "BQ"},
+		{Code: "saint-kitts", Name: "Saint Kitts", CountryCode: "KN"},
+		{Code: "saint-lucia", Name: "Saint Lucia", CountryCode: "LC"},
+		{Code: "saint-martin", Name: "Saint Martin", CountryCode: "MF"},
+		{
+			Code:        "saint-vincent-and-the-grenadines",
+			Name:        "Saint Vincent and the Grenadines",
+			CountryCode: "VC",
+		},
+		{
+			Code:        "san-andres-and-providencia",
+			Name:        "San Andrés and Providencia",
+			CountryCode: "CO",
+		},
+		{Code: "sint-maarten", Name: "Sint Maarten", CountryCode: "SX"},
+		{Code: "trinidad-and-tobago", Name: "Trinidad and Tobago", CountryCode: "TT"},
+		{Code: "turks-and-caicos-islands", Name: "Turks and Caicos Islands", CountryCode: "TC"},
+		{
+			Code:        "united-states-virgin-islands",
+			Name:        "United States Virgin Islands",
+			CountryCode: "VI",
+		},
+	}},
+	{"Atlantic Ocean", []models.Destination{
+		{Code: "ascension", Name: "Ascension", CountryCode: "SH"},
+		{Code: "azores", Name: "Azores", CountryCode: "PT"},
+		{Code: "bermuda", Name: "Bermuda", CountryCode: "BM"},
+		{Code: "canary-islands", Name: "Canary Islands", CountryCode: "ES"},
+		{Code: "cape-verde", Name: "Cape Verde", CountryCode: "CV"},
+		{Code: "falkland-islands", Name: "Falkland Islands", CountryCode: "FK"},
+		{Code: "faroe-islands", Name: "Faroe Islands", CountryCode: "FO"},
+		{Code: "fernando-de-noronha", Name: "Fernando de Noronha", CountryCode: "BR"},
+		{Code: "madeira", Name: "Madeira", CountryCode: "PT"},
+		{Code: "saint-helena", Name: "Saint Helena", CountryCode: "SH"},
+		{Code: "south-georgia", Name: "South Georgia", CountryCode: "GS"},
+		{Code: "tristan-da-cunha", Name: "Tristan da Cunha", CountryCode: "SH"},
+	}},
+	{"Europe and Mediterranean", []models.Destination{
+		{Code: "aland-islands", Name: "Åland Islands", CountryCode: "AX"},
+		{Code: "albania", Name: "Albania", CountryCode: "AL"},
+		{Code: "andorra", Name: "Andorra", CountryCode: "AD"},
+		{Code: "austria", Name: "Austria", CountryCode: "AT"},
+		{Code: "balearic-islands", Name: "Balearic Islands", CountryCode: "ES"},
+		{Code: "belarus", Name: "Belarus", CountryCode: "BY"},
+		{Code: "belgium", Name: "Belgium", CountryCode: "BE"},
+		{Code: "bosnia-and-herzegovina", Name: "Bosnia and Herzegovina", CountryCode: "BA"},
+		{Code: "bulgaria", Name: "Bulgaria", CountryCode: "BG"},
+		{Code: "corsica", Name: "Corsica", CountryCode: "FR"},
+		{Code: "crete", Name: "Crete", CountryCode: "GR"},
+		{Code: "croatia", Name: "Croatia", CountryCode: "HR"},
+		{Code: "cyprus", Name: "Cyprus", CountryCode: "CY"},
+		{Code: "czech-republic", Name: "Czech Republic", CountryCode: "CZ"},
+		{Code: "denmark", Name: "Denmark", CountryCode: "DK"},
+		{Code: "dodecanese-islands", Name: "Dodecanese Islands", CountryCode: "GR"},
+		{Code: "england", Name: "England", CountryCode: "GB"},
+		{Code: "estonia", Name: "Estonia", CountryCode: "EE"},
+		{Code: "finland", Name: "Finland", CountryCode: "FI"},
+		{Code: "france", Name: "France", CountryCode: "FR"},
+		{Code: "germany", Name: "Germany", CountryCode: "DE"},
+		{Code: "gibraltar", Name: "Gibraltar", CountryCode: "GI"},
+		{Code: "greece", Name: "Greece", CountryCode: "GR"},
+		{Code: "greek-aegean-islands", Name: "Greek Aegean Islands", CountryCode: "GR"},
+		{Code: "guernsey", Name: "Guernsey", CountryCode: "GG"},
+		{Code: "hungary", Name: "Hungary", CountryCode: "HU"},
+		{Code: "iceland", Name: "Iceland", CountryCode: "IS"},
+		{Code: "ionian-islands", Name: "Ionian Islands", CountryCode: "GR"},
+		{Code: "ireland", Name: "Ireland", CountryCode: "IE"},
+		{Code: "isle-of-man", Name: "Isle of Man", CountryCode: "IM"},
+		{Code: "italy", Name: "Italy", CountryCode: "IT"},
+		{Code: "jersey", Name: "Jersey", CountryCode: "JE"},
+		{Code: "kaliningrad", Name: "Kaliningrad", CountryCode: "RU"},
+		{Code: "kosovo", Name: "Kosovo", CountryCode: "XK"},
+		{Code: "latvia", Name: "Latvia", CountryCode: "LV"},
+		{Code: "liechtenstein", Name: "Liechtenstein", CountryCode: "LI"},
+		{Code: "lithuania", Name: "Lithuania", CountryCode: "LT"},
+		{Code: "luxembourg", Name: "Luxembourg", CountryCode: "LU"},
+		{Code: "malta", Name: "Malta", CountryCode: "MT"},
+		{Code: "moldova", Name: "Moldova", CountryCode: "MD"},
+		{Code: "monaco", Name: "Monaco", CountryCode: "MC"},
+		{Code: "montenegro", Name: "Montenegro", CountryCode: "ME"},
+		{Code: "netherlands", Name: "Netherlands", CountryCode: "NL"},
+		{Code: "north-macedonia", Name: "North Macedonia", CountryCode: "MK"},
+		{Code: "northern-cyprus", Name: "Northern Cyprus", CountryCode: "CY"},
+		{Code: "northern-ireland", Name: "Northern Ireland", CountryCode: "GB"},
+		{Code: "norway", Name: "Norway", CountryCode: "NO"},
+		{Code: "poland", Name: "Poland", CountryCode: "PL"},
+		{Code: "portugal", Name: "Portugal", CountryCode: "PT"},
+		{Code: "romania", Name: "Romania", CountryCode: "RO"},
+		{Code: "russia-europe", Name: "Russia (Europe)", CountryCode: "RU"},
+		{Code: "san-marino", Name: "San Marino", CountryCode: "SM"},
+		{Code: "sardinia", Name: "Sardinia", CountryCode: "IT"},
+		{Code: "scotland", Name: "Scotland", CountryCode: "GB"},
+		{Code: "serbia", Name: "Serbia", CountryCode: "RS"},
+		{Code: "shetland-islands", Name: "Shetland Islands", CountryCode: "GB"},
+		{Code: "sicily", Name: "Sicily", CountryCode: "IT"},
+		{Code: "slovakia", Name: "Slovakia", CountryCode: "SK"},
+		{Code: "slovenia", Name: "Slovenia", CountryCode: "SI"},
+		{Code: "spain", Name: "Spain", CountryCode: "ES"},
+		{Code: "svalbard", Name: "Svalbard", CountryCode: "SJ"},
+		{Code: "sweden", Name: "Sweden", CountryCode: "SE"},
+		{Code: "switzerland", Name: "Switzerland", CountryCode: "CH"},
+		{Code: "transnistria", Name: "Transnistria", CountryCode: "MD"},
+		{Code: "turkey-europe", Name: "Turkey (Europe)", CountryCode: "TR"},
+		{Code: "ukraine", Name: "Ukraine", CountryCode: "UA"},
+		{Code: "vatican-city", Name: "Vatican City", CountryCode: "VA"},
+		{Code: "wales", Name: "Wales", CountryCode: "GB"},
+	}},
+	{"Antarctica", []models.Destination{
+		{Code: "argentine-antarctica", Name: "Argentine Antarctica", CountryCode: "AQ"},
+		{
+			Code:        "australian-antarctic-territory",
+			Name:        "Australian Antarctic Territory",
+			CountryCode: "AQ",
+		},
+		{
+			Code:        "british-antarctic-territory",
+			Name:        "British Antarctic Territory",
+			CountryCode: "AQ",
+		},
+		{
+			Code:        "chilean-antarctic-territory",
+			Name:        "Chilean Antarctic Territory",
+			CountryCode: "AQ",
+		},
+		{
+			Code:        "french-antarctica-adelie-land",
+			Name:        "French Antarctica (Adélie Land)",
+			CountryCode: "AQ",
+		},
+		{
+			Code:        "new-zealand-antarctica-ross-dependency",
+			Name:        "New Zealand Antarctica (Ross Dependency)",
+			CountryCode: "AQ",
+		},
+		{
+			Code:        "norwegian-antarctica-queen-maud-land",
+			Name:        "Norwegian Antarctica (Queen Maud Land)",
+			CountryCode: "AQ",
+		},
+		{
+			Code:        "norwegian-dependencies-bouvet-and-peter-i-islands",
+			Name:        "Norwegian Dependencies (Bouvet and Peter I Islands)",
+			CountryCode: "BV",
+		},
+	}},
+	{"Africa", []models.Destination{
+		{Code: "algeria", Name: "Algeria", CountryCode: "DZ"},
+		{Code: "angola", Name: "Angola", CountryCode: "AO"},
+		{Code: "benin", Name: "Benin", CountryCode: "BJ"},
+		{Code: "botswana", Name: "Botswana", CountryCode: "BW"},
+		{Code: "burkina-faso", Name: "Burkina Faso", CountryCode: "BF"},
+		{Code: "burundi", Name: "Burundi", CountryCode: "BI"},
+		{Code: "cabinda", Name: "Cabinda", CountryCode: "AO"},
+		{Code: "cameroon", Name: "Cameroon", CountryCode: "CM"},
+		{Code: "central-african-republic", Name: "Central African Republic", CountryCode: "CF"},
+		{Code: "chad", Name: "Chad", CountryCode: "TD"},
+		{Code: "congo", Name: "Congo", CountryCode: "CG"},
+		{
+			Code:        "congo-democratic-republic",
+			Name:        "Congo (Democratic Republic)",
+			CountryCode: "CD",
+		},
+		{Code: "djibouti", Name: "Djibouti", CountryCode: "DJ"},
+		{Code: "egypt", Name: "Egypt", CountryCode: "EG"},
+		{Code: "equatorial-guinea", Name: "Equatorial Guinea", CountryCode: "GQ"},
+		{Code: "eritrea", Name: "Eritrea", CountryCode: "ER"},
+		{Code: "eswatini", Name: "Eswatini", CountryCode: "SZ"},
+		{Code: "ethiopia", Name: "Ethiopia", CountryCode: "ET"},
+		{Code: "gabon", Name: "Gabon", CountryCode: "GA"},
+		{Code: "gambia", Name: "Gambia", CountryCode: "GM"},
+		{Code: "ghana", Name: "Ghana", CountryCode: "GH"},
+		{Code: "guinea", Name: "Guinea", CountryCode: "GN"},
+		{Code: "guinea-bissau", Name: "Guinea-Bissau", CountryCode: "GW"},
+		{Code: "ivory-coast", Name: "Ivory Coast", CountryCode: "CI"},
+		{Code: "kenya", Name: "Kenya", CountryCode: "KE"},
+		{Code: "lesotho", Name: "Lesotho", CountryCode: "LS"},
+		{Code: "liberia", Name: "Liberia", CountryCode: "LR"},
+		{Code: "libya", Name: "Libya", CountryCode: "LY"},
+		{Code: "malawi", Name: "Malawi", CountryCode: "MW"},
+		{Code: "mali", Name: "Mali", CountryCode: "ML"},
+		{Code: "mauritania", Name: "Mauritania", CountryCode: "MR"},
+		{Code: "morocco", Name: "Morocco", CountryCode: "MA"},
+		{Code: "mozambique", Name: "Mozambique", CountryCode: "MZ"},
+		{Code: "namibia", Name: "Namibia", CountryCode: "NA"},
+		{Code: "niger", Name: "Niger", CountryCode: "NE"},
+		{Code: "nigeria", Name: "Nigeria", CountryCode: "NG"},
+		{Code: "rwanda", Name: "Rwanda", CountryCode: "RW"},
+		{Code: "sao-tome-and-principe", Name: "Sao Tome and Principe", CountryCode: "ST"},
+		{Code: "senegal", Name: "Senegal", CountryCode: "SN"},
+		{Code: "sierra-leone", Name: "Sierra Leone", CountryCode: "SL"},
+		{Code: "somalia", Name: "Somalia", CountryCode: "SO"},
+		{Code: "somaliland", Name: "Somaliland", CountryCode: "SO"},
+		{Code: "south-africa", Name: "South Africa", CountryCode: "ZA"},
+		{Code: "south-sudan", Name: "South Sudan", CountryCode: "SS"},
+		{
+			Code:        "spanish-north-africa-ceuta-and-melilla",
+			Name:        "Spanish North Africa (Ceuta and Melilla)",
+			CountryCode: "ES",
+		},
+		{Code: "sudan", Name: "Sudan", CountryCode: "SD"},
+		{Code: "tanzania", Name: "Tanzania", CountryCode: "TZ"},
+		{Code: "togo", Name: "Togo", CountryCode: "TG"},
+		{Code: "tunisia", Name: "Tunisia", CountryCode: "TN"},
+		{Code: "uganda", Name: "Uganda", CountryCode: "UG"},
+		{Code: "western-sahara", Name: "Western Sahara", CountryCode: "EH"},
+		{Code: "zambia", Name: "Zambia", CountryCode: "ZM"},
+		{Code: "zimbabwe", Name: "Zimbabwe", CountryCode: "ZW"},
+	}},
+	{"Middle East", []models.Destination{
+		{Code: "abu-dhabi", Name: "Abu Dhabi", CountryCode: "AE"},
+		{Code: "ajman", Name: "Ajman", CountryCode: "AE"},
+		{Code: "bahrain", Name: "Bahrain", CountryCode: "BH"},
+		{Code: "dubai", Name: "Dubai", CountryCode: "AE"},
+		{Code: "fujairah", Name: "Fujairah", CountryCode: "AE"},
+		{Code: "iran", Name: "Iran", CountryCode: "IR"},
+		{Code: "iraq", Name: "Iraq", CountryCode: "IQ"},
+		{Code: "israel", Name: "Israel", CountryCode: "IL"},
+		{Code: "jordan", Name: "Jordan", CountryCode: "JO"},
+		{Code: "kuwait", Name: "Kuwait", CountryCode: "KW"},
+		{Code: "lebanon", Name: "Lebanon", CountryCode: "LB"},
+		{Code: "oman", Name: "Oman", CountryCode: "OM"},
+		{Code: "palestine", Name: "Palestine", CountryCode: "PS"},
+		{Code: "qatar", Name: "Qatar", CountryCode: "QA"},
+		{Code: "ras-al-khaimah", Name: "Ras al-Khaimah", CountryCode: "AE"},
+		{Code: "saudi-arabia", Name: "Saudi Arabia", CountryCode: "SA"},
+		{Code: "sharjah", Name: "Sharjah", CountryCode: "AE"},
+		{Code: "syria", Name: "Syria", CountryCode: "SY"},
+		{Code: "turkey-asia", Name: "Turkey (Asia)", CountryCode: "TR"},
+		{Code: "umm-al-quwain", Name: "Umm al-Quwain", CountryCode: "AE"},
+		{Code: "yemen", Name: "Yemen", CountryCode: "YE"},
+	}},
+	{"Indian Ocean", []models.Destination{
+		{Code: "agalega-and-saint-brandon", Name: "Agaléga and Saint Brandon", CountryCode: "MU"},
+		{
+			Code:        "amsterdam-and-saint-paul-islands",
+			Name:        "Amsterdam and Saint Paul Islands",
+			CountryCode: "TF",
+		},
+		{
+			Code:        "andaman-and-nicobar-islands",
+			Name:        "Andaman and Nicobar Islands",
+			CountryCode: "IN",
+		},
+		{Code: "chagos-archipelago", Name: "Chagos Archipelago", CountryCode: "IO"},
+		{Code: "christmas-island", Name: "Christmas Island", CountryCode: "CX"},
+		{Code: "cocos-keeling-islands", Name: "Cocos (Keeling) Islands", CountryCode: "CC"},
+		{Code: "comoros", Name: "Comoros", CountryCode: "KM"},
+		{Code: "crozet-islands", Name: "Crozet Islands", CountryCode: "TF"},
+		{
+			Code:        "heard-and-mcdonald-islands",
+			Name:        "Heard and McDonald Islands",
+			CountryCode: "HM",
+		},
+		{Code: "kerguelen-islands", Name: "Kerguelen Islands", CountryCode: "TF"},
+		{Code: "lakshadweep", Name: "Lakshadweep", CountryCode: "IN"},
+		{Code: "madagascar", Name: "Madagascar", CountryCode: "MG"},
+		{Code: "maldives", Name: "Maldives", CountryCode: "MV"},
+		{Code: "mauritius", Name: "Mauritius", CountryCode: "MU"},
+		{Code: "mayotte", Name: "Mayotte", CountryCode: "YT"},
+		{Code: "prince-edward-islands", Name: "Prince Edward Islands", CountryCode: "ZA"},
+		{Code: "reunion", Name: "Réunion", CountryCode: "RE"},
+		{Code: "rodrigues", Name: "Rodrigues", CountryCode: "MU"},
+		{Code: "seychelles", Name: "Seychelles", CountryCode: "SC"},
+		{Code: "socotra", Name: "Socotra", CountryCode: "YE"},
+		{Code: "zanzibar", Name: "Zanzibar", CountryCode: "TZ"},
+	}},
+	{"Asia", []models.Destination{
+		{Code: "abkhazia", Name: "Abkhazia", CountryCode: "GE"},
+		{Code: "afghanistan", Name: "Afghanistan", CountryCode: "AF"},
+		{Code: "armenia", Name: "Armenia", CountryCode: "AM"},
+		{Code: "azerbaijan", Name: "Azerbaijan", CountryCode: "AZ"},
+		{Code: "bali", Name: "Bali", CountryCode: "ID"},
+		{Code: "bangladesh", Name: "Bangladesh", CountryCode: "BD"},
+		{Code: "bhutan", Name: "Bhutan", CountryCode: "BT"},
+		{Code: "brunei", Name: "Brunei", CountryCode: "BN"},
+		{Code: "cambodia", Name: "Cambodia", CountryCode: "KH"},
+		{Code: "china", Name: "China", CountryCode: "CN"},
+		{Code: "georgia", Name: "Georgia", CountryCode: "GE"},
+		{Code: "hainan", Name: "Hainan", CountryCode: "CN"},
+		{Code: "hong-kong", Name: "Hong Kong", CountryCode: "HK"},
+		{Code: "india", Name: "India", CountryCode: "IN"},
+		{Code: "indonesia-java", Name: "Indonesia (Java)", CountryCode: "ID"},
+		{Code: "jammu-and-kashmir", Name: "Jammu and Kashmir", CountryCode: "IN"},
+		{Code: "japan", Name: "Japan", CountryCode: "JP"},
+		{Code: "kalimantan", Name: "Kalimantan", CountryCode: "ID"},
+		{Code: "kazakhstan", Name: "Kazakhstan", CountryCode: "KZ"},
+		{Code: "kyrgyzstan", Name: "Kyrgyzstan", CountryCode: "KG"},
+		{Code: "laos", Name: "Laos", CountryCode: "LA"},
+		{Code: "lesser-sunda-islands", Name: "Lesser Sunda Islands", CountryCode: "ID"},
+		{Code: "macau", Name: "Macau", CountryCode: "MO"},
+		{Code: "malaysia", Name: "Malaysia", CountryCode: "MY"},
+		{Code: "maluku-islands", Name: "Maluku Islands", CountryCode: "ID"},
+		{Code: "mongolia", Name: "Mongolia", CountryCode: "MN"},
+		{Code: "myanmar", Name: "Myanmar", CountryCode: "MM"},
+		{Code: "nakhchivan", Name: "Nakhchivan", CountryCode: "AZ"},
+		{Code: "nepal", Name: "Nepal", CountryCode: "NP"},
+		{Code: "north-korea", Name: "North Korea", CountryCode: "KP"},
+		{Code: "pakistan", Name: "Pakistan", CountryCode: "PK"},
+		{Code: "papua-indonesia", Name: "Papua (Indonesia)", CountryCode: "ID"},
+		{Code: "philippines", Name: "Philippines", CountryCode: "PH"},
+		{Code: "russia-asia", Name: "Russia (Asia)", CountryCode: "RU"},
+		{Code: "sabah", Name: "Sabah", CountryCode: "MY"},
+		{Code: "sarawak", Name: "Sarawak", CountryCode: "MY"},
+		{Code: "sikkim", Name: "Sikkim", CountryCode: "IN"},
+		{Code: "singapore", Name: "Singapore", CountryCode: "SG"},
+		{Code: "south-korea", Name: "South Korea", CountryCode: "KR"},
+		{Code: "south-ossetia", Name: "South Ossetia", CountryCode: "GE"},
+		{Code: "sri-lanka", Name: "Sri Lanka", CountryCode: "LK"},
+		{Code: "sulawesi", Name: "Sulawesi", CountryCode: "ID"},
+		{Code: "sumatra", Name: "Sumatra", CountryCode: "ID"},
+		{Code: "taiwan", Name: "Taiwan", CountryCode: "TW"},
+		{Code: "tajikistan", Name: "Tajikistan", CountryCode: "TJ"},
+		{Code: "thailand", Name: "Thailand", CountryCode: "TH"},
+		{Code: "tibet", Name: "Tibet", CountryCode: "CN"},
+		{Code: "timor-leste", Name: "Timor-Leste", CountryCode: "TL"},
+		{Code: "turkmenistan", Name: "Turkmenistan", CountryCode: "TM"},
+		{Code: "uzbekistan", Name: "Uzbekistan", CountryCode: "UZ"},
+		{Code: "vietnam", Name: "Vietnam", CountryCode: "VN"},
+	}},
+}
@@ -0,0 +1,11 @@
+package data
+
+import (
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// Territories lists dependent territories (Type territory) and disputed states (Type disputed)
+// that have an ISO 3166-1 alpha-2 code (or, for Kosovo, the widely used user-assigned XK) but
+// are not in List. Returned by GET /countries?include=territories and accepted as visits by
+// users who enabled Settings.IncludeTerritories. Built in init.
+var Territories []models.Country
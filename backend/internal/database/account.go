@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ScheduleAccountDeletion sets DeletionScheduledAt of users/{userID} to at. Returns
+// ErrUserNotFound if the user document is missing.
+func (c *Client) ScheduleAccountDeletion(ctx context.Context, userID string, at time.Time) error {
+	return c.setDeletionScheduledAt(ctx, userID, at.UTC())
+}
+
+// CancelAccountDeletion clears DeletionScheduledAt of users/{userID}. Returns ErrUserNotFound
+// if the user document is missing.
+func (c *Client) CancelAccountDeletion(ctx context.Context, userID string) error {
+	return c.setDeletionScheduledAt(ctx, userID, firestore.Delete)
+}
+
+func (c *Client) setDeletionScheduledAt(ctx context.Context, userID string, value any) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	_, err := c.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
+		{Path: "DeletionScheduledAt", Value: value},
+	})
+	if status.Code(err) == codes.NotFound {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update account deletion: %w", err)
+	}
+	return nil
+}
+
+// GetUsersDueForDeletion returns the IDs of up to limit users whose DeletionScheduledAt is at or
+// before now.
+func (c *Client) GetUsersDueForDeletion(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]string, error) {
+	docs, err := c.Collection("users").Where("DeletionScheduledAt", "<=", now).
+		Select().Limit(limit).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users due for deletion: %w", err)
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.Ref.ID
+	}
+	return ids, nil
+}
+
+// PurgeUser permanently deletes the account of userID if its deletion is due at now (a
+// cancelled or rescheduled deletion is left alone): the User document with its country visits
+// (including visit history), friends, followers, blocked users, friend invites, insights,
+// share links, share stats, API keys, audit log and handle, user's entries in the followers of
+// their friends, visit overlaps (both copies), friend requests from and to the user and
+// organization memberships. Friend entries of other users and organizations the user created
+// are kept, as for any account that no longer exists. Proof files are deleted by the caller.
+// Purging is idempotent, so an interrupted purge is completed by the next call.
+func (c *Client) PurgeUser(ctx context.Context, userID string, now time.Time) error {
+	u, err := c.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil || u.DeletionScheduledAt == nil || u.DeletionScheduledAt.After(now) {
+		return nil
+	}
+	userRef := c.Collection("users").Doc(userID)
+	var refs []*firestore.DocumentRef
+
+	// Visits are listed with missing documents too, since history outlives deleted visits
+	visitRefs, err := userRef.Collection("country_visits").DocumentRefs(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to list country visits: %w", err)
+	}
+	for _, ref := range visitRefs {
+		historyRefs, err := ref.Collection("history").DocumentRefs(ctx).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list visit history: %w", err)
+		}
+		refs = append(refs, historyRefs...)
+		refs = append(refs, ref)
+	}
+	for _, name := range []string{"friends", "followers", "blocked", "friend_invites", "insights",
+		"share_links", "share_stats", "api_keys", "audit"} {
+		subRefs, err := userRef.Collection(name).DocumentRefs(ctx).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", name, err)
+		}
+		refs = append(refs, subRefs...)
+	}
+	if u.Handle != "" {
+		refs = append(refs, c.handleRef(u.Handle))
+	}
+	overlaps, err := c.overlapsRef(userID).Documents(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to list overlaps: %w", err)
+	}
+	for _, overlap := range overlaps {
+		pair, _, err := c.overlapPairRefs(overlap)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, pair...)
+	}
+
+	friends, err := c.GetFriendsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	shareTokens := make([]string, len(friends))
+	for i, f := range friends {
+		shareTokens[i] = f.ShareToken
+	}
+	friendUsers, err := c.GetUsersByShareTokens(ctx, shareTokens)
+	if err != nil {
+		return err
+	}
+	for _, f := range friendUsers {
+		refs = append(refs, c.followerRef(f.ID, userID))
+	}
+
+	requests := c.Collection("friend_requests")
+	for _, q := range []firestore.Query{
+		requests.Where("FromUserID", "==", userID),
+		requests.Where("ToUserID", "==", userID),
+		c.CollectionGroup("members").Where("UserID", "==", userID),
+	} {
+		docs, err := q.Documents(ctx).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list user relations: %w", err)
+		}
+		for _, doc := range docs {
+			refs = append(refs, doc.Ref)
+		}
+	}
+
+	// The User document goes last: until it is deleted, the purge can be retried
+	if err := c.deleteRefs(ctx, refs); err != nil {
+		return err
+	}
+	if _, err := userRef.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
+// deleteRefs deletes refs with a BulkWriter. Missing documents are not an error.
+func (c *Client) deleteRefs(ctx context.Context, refs []*firestore.DocumentRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	bw := c.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(refs))
+	for _, ref := range refs {
+		job, err := bw.Delete(ref)
+		if err != nil {
+			bw.End()
+			return fmt.Errorf("failed to queue delete: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return fmt.Errorf("failed to delete document: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrTooManyAPIKeys = errors.New("too many API keys")
+)
+
+func (c *Client) apiKeysRef(userID string) *firestore.CollectionRef {
+	return c.Collection("users").Doc(userID).Collection("api_keys")
+}
+
+// CreateAPIKey stores the API key of key.UserID under a new ID. Returns ErrTooManyAPIKeys when
+// the user has models.MaxAPIKeys keys.
+func (c *Client) CreateAPIKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	if key == nil || key.UserID == "" || key.KeyHash == "" {
+		return nil, fmt.Errorf("userID and key hash are required")
+	}
+	existing, err := c.GetAPIKeys(ctx, key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxAPIKeys {
+		return nil, ErrTooManyAPIKeys
+	}
+	out := *key
+	out.CreatedAt = time.Now().UTC()
+	out.LastUsedAt = nil
+	ref := c.apiKeysRef(out.UserID).NewDoc()
+	if _, err := ref.Create(ctx, &out); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	out.ID = ref.ID
+	return &out, nil
+}
+
+// GetAPIKeys returns the API keys of userID, newest first.
+func (c *Client) GetAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	docs, err := c.apiKeysRef(userID).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	keys := make([]models.APIKey, 0, len(docs))
+	for _, doc := range docs {
+		var k models.APIKey
+		if err := doc.DataTo(&k); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal API key: %w", err)
+		}
+		k.ID = doc.Ref.ID
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// GetAPIKeyByHash returns the API key of any user whose KeyHash is keyHash, or nil (not error)
+// when there is none.
+func (c *Client) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	if keyHash == "" {
+		return nil, nil
+	}
+	docs, err := c.CollectionGroup("api_keys").Where("KeyHash", "==", keyHash).Limit(1).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	var key models.APIKey
+	if err := docs[0].DataTo(&key); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal API key: %w", err)
+	}
+	key.ID = docs[0].Ref.ID
+	return &key, nil
+}
+
+// TouchAPIKey sets LastUsedAt of the API key keyID of userID to now. A key revoked meanwhile is
+// not an error.
+func (c *Client) TouchAPIKey(ctx context.Context, userID, keyID string, now time.Time) error {
+	if userID == "" || keyID == "" {
+		return fmt.Errorf("userID and keyID are required")
+	}
+	_, err := c.apiKeysRef(userID).Doc(keyID).Update(ctx, []firestore.Update{
+		{Path: "LastUsedAt", Value: now},
+	})
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+	return nil
+}
+
+// DeleteAPIKey revokes the API key keyID of userID. Returns ErrAPIKeyNotFound if it does not
+// exist.
+func (c *Client) DeleteAPIKey(ctx context.Context, userID, keyID string) error {
+	if userID == "" || keyID == "" {
+		return fmt.Errorf("userID and keyID are required")
+	}
+	_, err := c.apiKeysRef(userID).Doc(keyID).Delete(ctx, firestore.Exists)
+	if status.Code(err) == codes.NotFound {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+	return nil
+}
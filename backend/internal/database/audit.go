@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func (c *Client) auditRef(userID string) *firestore.CollectionRef {
+	return c.Collection("users").Doc(userID).Collection("audit")
+}
+
+// RecordAuditEvent adds event to the audit log of userID, expiring models.AuditRetention after
+// event.Time.
+func (c *Client) RecordAuditEvent(
+	ctx context.Context,
+	userID string,
+	event *models.AuditEvent,
+) error {
+	if userID == "" || event == nil || event.Type == "" {
+		return fmt.Errorf("userID and event type are required")
+	}
+	out := *event
+	out.ExpiresAt = out.Time.Add(models.AuditRetention)
+	if _, _, err := c.auditRef(userID).Add(ctx, &out); err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// GetAuditEvents returns up to limit of the most recent audit events of userID, newest first.
+func (c *Client) GetAuditEvents(
+	ctx context.Context,
+	userID string,
+	limit int,
+) ([]models.AuditEvent, error) {
+	if userID == "" || limit <= 0 {
+		return nil, fmt.Errorf("userID and a positive limit are required")
+	}
+	docs, err := c.auditRef(userID).OrderBy("Time", firestore.Desc).Limit(limit).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	events := make([]models.AuditEvent, 0, len(docs))
+	for _, doc := range docs {
+		var e models.AuditEvent
+		if err := doc.DataTo(&e); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit event: %w", err)
+		}
+		e.ID = doc.Ref.ID
+		events = append(events, e)
+	}
+	return events, nil
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetBackfillJob loads the checkpoint document backfill_jobs/{name}. Returns (nil, nil) if the
+// job has never been started.
+func (c *Client) GetBackfillJob(ctx context.Context, name string) (*models.BackfillJob, error) {
+	snap, err := c.Collection("backfill_jobs").Doc(name).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get backfill job: %w", err)
+	}
+	var job models.BackfillJob
+	if err := snap.DataTo(&job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backfill job: %w", err)
+	}
+	job.Name = snap.Ref.ID
+	return &job, nil
+}
+
+// SaveBackfillJob writes the checkpoint document backfill_jobs/{job.Name}, replacing it.
+func (c *Client) SaveBackfillJob(ctx context.Context, job *models.BackfillJob) error {
+	if job == nil || job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	if _, err := c.Collection("backfill_jobs").Doc(job.Name).Set(ctx, job); err != nil {
+		return fmt.Errorf("failed to save backfill job: %w", err)
+	}
+	return nil
+}
+
+// ListUserIDs returns up to limit user document IDs in ID order, starting after afterID
+// (from the beginning when empty). Only document references are read.
+func (c *Client) ListUserIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	q := c.Collection("users").Select().OrderBy(firestore.DocumentID, firestore.Asc).Limit(limit)
+	if afterID != "" {
+		q = q.StartAfter(afterID)
+	}
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+
+	var ids []string
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate users: %w", err)
+		}
+		ids = append(ids, doc.Ref.ID)
+	}
+	return ids, nil
+}
+
+// RebuildUserVisitStats recomputes the user's VisitCount and DistinctCountries from
+// country_visits. The visits are read in the same transaction as the update, so a concurrent
+// visit write makes the transaction retry instead of leaving stale counts. A user document
+// that no longer exists is skipped.
+func (c *Client) RebuildUserVisitStats(ctx context.Context, userID string) error {
+	userRef := c.Collection("users").Doc(userID)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(userRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if !snap.Exists() {
+			return nil
+		}
+
+		docs, err := tx.Documents(userRef.Collection("country_visits")).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to get country visits: %w", err)
+		}
+		countries := make(map[string]struct{}, len(docs))
+		for _, doc := range docs {
+			var visit models.CountryVisit
+			if err := doc.DataTo(&visit); err != nil {
+				return fmt.Errorf("failed to unmarshal country visit: %w", err)
+			}
+			countries[visit.CountryCode] = struct{}{}
+		}
+
+		return tx.Update(userRef, []firestore.Update{
+			{Path: "VisitCount", Value: int64(len(docs))},
+			{Path: "DistinctCountries", Value: int64(len(countries))},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rebuild visit stats: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var ErrBlockedUserNotFound = errors.New("blocked user not found")
+
+// BlockUser adds blocked to users/{userID}/blocked in one transaction that also ends any
+// relation between the two users: the friend documents on both sides (ownerShareToken is the
+// owner's ShareToken, as stored on the other user's side), their followers entries and pending
+// friend requests in either direction are deleted. Blocking an already blocked user refreshes
+// the stored copy.
+func (c *Client) BlockUser(
+	ctx context.Context,
+	userID, ownerShareToken string,
+	blocked *models.BlockedUser,
+) (*models.BlockedUser, error) {
+	if userID == "" || blocked == nil || blocked.UserID == "" {
+		return nil, fmt.Errorf("userID and blocked user ID are required")
+	}
+	users := c.Collection("users")
+	requests := c.Collection("friend_requests")
+	out := *blocked
+	out.CreatedAt = time.Now().UTC()
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// Reads first: Firestore transactions allow no reads after writes
+		var refs []*firestore.DocumentRef
+		for _, q := range []firestore.Query{
+			users.Doc(userID).Collection("friends").Where("ShareToken", "==", blocked.ShareToken),
+			users.Doc(blocked.UserID).Collection("friends").Where("ShareToken", "==", ownerShareToken),
+			requests.Where("FromUserID", "==", userID).Where("ToUserID", "==", blocked.UserID),
+			requests.Where("FromUserID", "==", blocked.UserID).Where("ToUserID", "==", userID),
+		} {
+			docs, err := tx.Documents(q).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to list relations: %w", err)
+			}
+			for _, doc := range docs {
+				refs = append(refs, doc.Ref)
+			}
+		}
+		refs = append(refs,
+			c.followerRef(userID, blocked.UserID), c.followerRef(blocked.UserID, userID))
+		for _, ref := range refs {
+			if err := tx.Delete(ref); err != nil {
+				return err
+			}
+		}
+		return tx.Set(users.Doc(userID).Collection("blocked").Doc(blocked.UserID), &out)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to block user: %w", err)
+	}
+	return &out, nil
+}
+
+// GetBlockedUsers returns the users blocked by userID, most recently blocked first.
+func (c *Client) GetBlockedUsers(ctx context.Context, userID string) ([]models.BlockedUser, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	docs, err := c.Collection("users").Doc(userID).Collection("blocked").Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocked users: %w", err)
+	}
+	blocked := make([]models.BlockedUser, 0, len(docs))
+	for _, doc := range docs {
+		var b models.BlockedUser
+		if err := doc.DataTo(&b); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal blocked user: %w", err)
+		}
+		b.UserID = doc.Ref.ID
+		blocked = append(blocked, b)
+	}
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].CreatedAt.After(blocked[j].CreatedAt)
+	})
+	return blocked, nil
+}
+
+// IsBlocked reports whether userID has blocked otherUserID.
+func (c *Client) IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error) {
+	if userID == "" || otherUserID == "" {
+		return false, fmt.Errorf("userID and otherUserID are required")
+	}
+	_, err := c.Collection("users").Doc(userID).Collection("blocked").Doc(otherUserID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check block: %w", err)
+	}
+	return true, nil
+}
+
+// UnblockUser removes the block of the user blocked with shareToken from users/{userID}/blocked.
+// Returns ErrBlockedUserNotFound when there is no such block.
+func (c *Client) UnblockUser(ctx context.Context, userID, shareToken string) error {
+	if userID == "" || shareToken == "" {
+		return fmt.Errorf("userID and shareToken are required")
+	}
+	docs, err := c.Collection("users").Doc(userID).Collection("blocked").
+		Where("ShareToken", "==", shareToken).Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to find blocked user: %w", err)
+	}
+	if len(docs) == 0 {
+		return ErrBlockedUserNotFound
+	}
+	if _, err := docs[0].Ref.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	return nil
+}
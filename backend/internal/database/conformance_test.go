@@ -0,0 +1,25 @@
+package database_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/database/dbtest"
+)
+
+// TestConformance runs the conformance suite on the Firestore emulator at
+// FIRESTORE_EMULATOR_HOST (make test-integration starts one) and is skipped without it.
+func TestConformance(t *testing.T) {
+	host := os.Getenv("FIRESTORE_EMULATOR_HOST")
+	if host == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set; run make test-integration")
+	}
+	c, err := database.NewClient(context.Background(), "demo-test", host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	dbtest.Run(t, func(t *testing.T) dbtest.DB { return c })
+}
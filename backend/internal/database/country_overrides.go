@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// ErrCountryOverrideNotFound is returned by DeleteCountryOverride for a code without an override.
+var ErrCountryOverrideNotFound = errors.New("country override not found")
+
+// GetCountryOverrides loads every document of countries_overrides, ordered by country code.
+func (c *Client) GetCountryOverrides(ctx context.Context) ([]models.CountryOverride, error) {
+	iter := c.Collection("countries_overrides").Documents(ctx)
+	defer iter.Stop()
+
+	var overrides []models.CountryOverride
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate country overrides: %w", err)
+		}
+		var o models.CountryOverride
+		if err := doc.DataTo(&o); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal country override: %w", err)
+		}
+		o.CountryCode = doc.Ref.ID
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// SaveCountryOverride writes countries_overrides/{o.CountryCode}, replacing any earlier override.
+func (c *Client) SaveCountryOverride(ctx context.Context, o *models.CountryOverride) error {
+	if o == nil || o.CountryCode == "" {
+		return fmt.Errorf("country code is required")
+	}
+	if _, err := c.Collection("countries_overrides").Doc(o.CountryCode).Set(ctx, o); err != nil {
+		return fmt.Errorf("failed to save country override: %w", err)
+	}
+	return nil
+}
+
+// DeleteCountryOverride deletes countries_overrides/{code}, restoring the bundled entry (or
+// removing an added country). Returns ErrCountryOverrideNotFound if there is no override.
+func (c *Client) DeleteCountryOverride(ctx context.Context, code string) error {
+	if code == "" {
+		return fmt.Errorf("country code is required")
+	}
+	_, err := c.Collection("countries_overrides").Doc(code).Delete(ctx, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrCountryOverrideNotFound
+		}
+		return fmt.Errorf("failed to delete country override: %w", err)
+	}
+	return nil
+}
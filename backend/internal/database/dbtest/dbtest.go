@@ -0,0 +1,149 @@
+// Package dbtest is the conformance suite of the database backends: the Firestore client in
+// internal/database and the memory and sqldb implementations. The tests of each backend call
+// Run, so all of them are held to the same semantics, including under concurrent calls.
+package dbtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// DB is the part of the server's Database interface the suite exercises.
+type DB interface {
+	EnsureUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, userID string) (*models.User, error)
+	GetUserByShareToken(ctx context.Context, shareToken string) (*models.User, error)
+	SetUserLimits(ctx context.Context, userID string, limits *models.UserLimits) error
+
+	CreateCountryVisit(
+		ctx context.Context,
+		visit *models.CountryVisit,
+		maxVisits int,
+	) (*models.CountryVisit, error)
+	GetCountryVisit(ctx context.Context, visitID, userID string) (*models.CountryVisit, error)
+	GetCountryVisitsByUser(ctx context.Context, userID string) ([]models.CountryVisit, error)
+	GetPublicCountryVisitsByUser(ctx context.Context, userID string) ([]models.CountryVisit, error)
+	GetCountryVisitsPage(
+		ctx context.Context,
+		userID, cursor string,
+		limit int,
+	) ([]models.CountryVisit, string, error)
+	ReplaceCountryVisit(
+		ctx context.Context,
+		visit *models.CountryVisit,
+		unmodifiedSince time.Time,
+	) error
+	DeleteCountryVisit(ctx context.Context, visitID string, userID string) error
+	GetFeedVisits(
+		ctx context.Context,
+		userIDs []string,
+		after *models.FeedCursor,
+		limit int,
+	) ([]models.CountryVisit, error)
+
+	GetFriendsByUser(ctx context.Context, userID string) ([]models.Friend, error)
+	GetFriendsPage(
+		ctx context.Context,
+		userID, cursor string,
+		limit int,
+	) ([]models.Friend, string, error)
+	GetFriendByShareToken(ctx context.Context, userID, shareToken string) (*models.Friend, error)
+	UpdateFriendNickname(
+		ctx context.Context,
+		userID, shareToken, nickname string,
+	) (*models.Friend, error)
+	DeleteFriendByShareToken(ctx context.Context, userID, shareToken string) error
+	GetFollowers(ctx context.Context, userID string) ([]models.Follower, error)
+	CreateFriendRequest(ctx context.Context, req *models.FriendRequest) (*models.FriendRequest, error)
+	AcceptFriendRequest(
+		ctx context.Context,
+		requestID, userID string,
+		maxFriends int,
+	) (models.Friend, error)
+}
+
+// Run runs the suite on the databases returned by open, which is called once per test. The
+// databases may be shared (e.g. one emulator): tests only touch users they create.
+func Run(t *testing.T, open func(t *testing.T) DB) {
+	t.Run("EnsureUser", func(t *testing.T) { testEnsureUser(t, open(t)) })
+	t.Run("EnsureUserConcurrent", func(t *testing.T) { testEnsureUserConcurrent(t, open(t)) })
+	t.Run("Visits", func(t *testing.T) { testVisits(t, open(t)) })
+	t.Run("VisitsPage", func(t *testing.T) { testVisitsPage(t, open(t)) })
+	t.Run("VisitLimit", func(t *testing.T) { testVisitLimit(t, open(t)) })
+	t.Run("FeedVisits", func(t *testing.T) { testFeedVisits(t, open(t)) })
+	t.Run("Friends", func(t *testing.T) { testFriends(t, open(t)) })
+	t.Run("FriendsPage", func(t *testing.T) { testFriendsPage(t, open(t)) })
+	t.Run("FriendLimit", func(t *testing.T) { testFriendLimit(t, open(t)) })
+	t.Run("AcceptFriendRequestConcurrent", func(t *testing.T) {
+		testAcceptFriendRequestConcurrent(t, open(t))
+	})
+}
+
+// newUserID returns a user ID not used by earlier tests.
+func newUserID() string {
+	return "test-" + uuid.New().String()
+}
+
+// newUser creates a user and returns it as stored.
+func newUser(t *testing.T, db DB) *models.User {
+	t.Helper()
+	ctx := context.Background()
+	id := newUserID()
+	if err := db.EnsureUser(ctx, &models.User{ID: id, Name: "User " + id[5:13]}); err != nil {
+		t.Fatalf("EnsureUser: %v", err)
+	}
+	u, err := db.GetUserByID(ctx, id)
+	if err != nil || u == nil {
+		t.Fatalf("GetUserByID(%s) = %+v, %v", id, u, err)
+	}
+	return u
+}
+
+// friendRequest returns a friend request from one user to another.
+func friendRequest(from, to *models.User) *models.FriendRequest {
+	return &models.FriendRequest{
+		FromUserID:     from.ID,
+		FromShareToken: from.ShareToken,
+		FromName:       from.Name,
+		ToUserID:       to.ID,
+		ToShareToken:   to.ShareToken,
+		ToName:         to.Name,
+	}
+}
+
+// befriend makes a and b friends: a sends a request, which b accepts.
+func befriend(t *testing.T, db DB, a, b *models.User) {
+	t.Helper()
+	ctx := context.Background()
+	req, err := db.CreateFriendRequest(ctx, friendRequest(a, b))
+	if err != nil {
+		t.Fatalf("CreateFriendRequest: %v", err)
+	}
+	if _, err := db.AcceptFriendRequest(ctx, req.ID, b.ID, 0); err != nil {
+		t.Fatalf("AcceptFriendRequest: %v", err)
+	}
+}
+
+// parallel calls f(i) for i in [0, n) concurrently and returns the errors by i.
+func parallel(n int, f func(i int) error) []error {
+	errs := make([]error, n)
+	start := make(chan struct{})
+	done := make(chan struct{})
+	for i := range n {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			<-start
+			errs[i] = f(i)
+		}()
+	}
+	close(start)
+	for range n {
+		<-done
+	}
+	return errs
+}
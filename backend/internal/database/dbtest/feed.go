@@ -0,0 +1,87 @@
+package dbtest
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func testFeedVisits(t *testing.T, db DB) {
+	ctx := context.Background()
+
+	// More users than the Firestore client reads at a time, one visit each a day apart, and a
+	// second user with a private visit and two visits at the same time
+	var userIDs []string
+	var want []models.CountryVisit
+	for i := range 12 {
+		u := newUser(t, db)
+		userIDs = append(userIDs, u.ID)
+		v, err := db.CreateCountryVisit(ctx, &models.CountryVisit{
+			UserID: u.ID, CountryCode: "FI", VisitedTime: visitedTime.AddDate(0, 0, i),
+		}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, *v)
+	}
+	u := newUser(t, db)
+	userIDs = append(userIDs, u.ID)
+	for _, code := range []string{"SE", "NO"} {
+		want = append(want, *newVisit(t, db, u.ID, code))
+	}
+	if _, err := db.CreateCountryVisit(ctx, &models.CountryVisit{
+		UserID: u.ID, CountryCode: "DK", VisitedTime: visitedTime.Add(time.Hour),
+		IsPrivate: true,
+	}, 0); err != nil {
+		t.Fatal(err)
+	}
+	// A visit of a user not asked for
+	newVisit(t, db, newUser(t, db).ID, "IS")
+
+	sort.Slice(want, func(i, j int) bool {
+		if !want[i].VisitedTime.Equal(want[j].VisitedTime) {
+			return want[i].VisitedTime.After(want[j].VisitedTime)
+		}
+		return want[i].ID > want[j].ID
+	})
+
+	var got []models.CountryVisit
+	var after *models.FeedCursor
+	for page := 0; ; page++ {
+		if page > len(want) {
+			t.Fatalf("no last page after %d pages", page)
+		}
+		visits, err := db.GetFeedVisits(ctx, userIDs, after, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(visits) > 5 {
+			t.Fatalf("page %d has %d visits, want at most 5", page, len(visits))
+		}
+		got = append(got, visits...)
+		if len(visits) < 5 {
+			break
+		}
+		cursor := models.FeedCursorOf(visits[len(visits)-1])
+		after = &cursor
+	}
+	if !slices.Equal(visitIDs(got), visitIDs(want)) {
+		t.Errorf("feed = %v, want %v", visitIDs(got), visitIDs(want))
+	}
+	for _, v := range got {
+		if v.UserID == "" {
+			t.Errorf("visit %s has no UserID", v.ID)
+		}
+	}
+
+	if visits, err := db.GetFeedVisits(ctx, nil, nil, 5); err != nil || len(visits) != 0 {
+		t.Errorf("feed of no users = %v, %v; want none", visits, err)
+	}
+	if _, err := db.GetFeedVisits(ctx, userIDs, nil, 0); err == nil {
+		t.Error("limit 0 accepted")
+	}
+}
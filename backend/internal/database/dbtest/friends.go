@@ -0,0 +1,219 @@
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func testFriends(t *testing.T, db DB) {
+	ctx := context.Background()
+	a, b := newUser(t, db), newUser(t, db)
+
+	req, err := db.CreateFriendRequest(ctx, friendRequest(a, b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateFriendRequest(ctx, friendRequest(b, a)); !errors.Is(err,
+		database.ErrFriendRequestExists) {
+		t.Errorf("CreateFriendRequest in reverse = %v, want ErrFriendRequestExists", err)
+	}
+	if _, err := db.AcceptFriendRequest(ctx, req.ID, a.ID, 0); !errors.Is(err,
+		database.ErrFriendRequestNotFound) {
+		t.Errorf("AcceptFriendRequest by the sender = %v, want ErrFriendRequestNotFound", err)
+	}
+	friend, err := db.AcceptFriendRequest(ctx, req.ID, b.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if friend.ID == "" || friend.ShareToken != a.ShareToken || friend.Name != a.Name {
+		t.Errorf("accepted friend = %+v, want %s with an ID", friend, a.ShareToken)
+	}
+
+	got, err := db.GetFriendByShareToken(ctx, a.ID, b.ShareToken)
+	if err != nil || got == nil || got.Name != b.Name || got.ID == "" {
+		t.Fatalf("GetFriendByShareToken = %+v, %v; want %s", got, err, b.Name)
+	}
+	if none, err := db.GetFriendByShareToken(ctx, a.ID, "no-such-token"); err != nil ||
+		none != nil {
+		t.Errorf("GetFriendByShareToken(unknown) = %+v, %v; want nil, nil", none, err)
+	}
+
+	named, err := db.UpdateFriendNickname(ctx, a.ID, b.ShareToken, "Bee")
+	if err != nil || named.Nickname != "Bee" || named.ID != got.ID {
+		t.Errorf("UpdateFriendNickname = %+v, %v; want nickname Bee", named, err)
+	}
+	if got, err = db.GetFriendByShareToken(ctx, a.ID, b.ShareToken); err != nil ||
+		got.Nickname != "Bee" {
+		t.Errorf("after UpdateFriendNickname = %+v, %v; want nickname Bee", got, err)
+	}
+	if _, err := db.UpdateFriendNickname(ctx, a.ID, "no-such-token", "x"); !errors.Is(err,
+		database.ErrFriendNotFound) {
+		t.Errorf("UpdateFriendNickname(unknown) = %v, want ErrFriendNotFound", err)
+	}
+
+	if err := db.DeleteFriendByShareToken(ctx, a.ID, b.ShareToken); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeleteFriendByShareToken(ctx, a.ID, b.ShareToken); !errors.Is(err,
+		database.ErrFriendNotFound) {
+		t.Errorf("DeleteFriendByShareToken again = %v, want ErrFriendNotFound", err)
+	}
+	friends, err := db.GetFriendsByUser(ctx, a.ID)
+	if err != nil || len(friends) != 0 {
+		t.Errorf("friends of %s after delete = %+v, %v; want none", a.ID, friends, err)
+	}
+	followers, err := db.GetFollowers(ctx, b.ID)
+	if err != nil || len(followers) != 0 {
+		t.Errorf("followers of %s after delete = %+v, %v; want none", b.ID, followers, err)
+	}
+
+	// Removing a friend is one-sided
+	friends, err = db.GetFriendsByUser(ctx, b.ID)
+	if err != nil || len(friends) != 1 || friends[0].ShareToken != a.ShareToken {
+		t.Errorf("friends of %s = %+v, %v; want only %s", b.ID, friends, err, a.ShareToken)
+	}
+}
+
+func testFriendsPage(t *testing.T, db DB) {
+	ctx := context.Background()
+	u := newUser(t, db)
+	var want []string
+	for range 5 {
+		befriend(t, db, newUser(t, db), u)
+	}
+	friends, err := db.GetFriendsByUser(ctx, u.ID)
+	if err != nil || len(friends) != 5 {
+		t.Fatalf("GetFriendsByUser = %d friends, %v; want 5", len(friends), err)
+	}
+	for _, f := range friends {
+		want = append(want, f.ID)
+	}
+
+	var got []string
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > len(want) {
+			t.Fatalf("no last page after %d pages", page)
+		}
+		friends, next, err := db.GetFriendsPage(ctx, u.ID, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(friends) > 2 || (next != "" && len(friends) != 2) {
+			t.Fatalf("page %d has %d friends and cursor %q", page, len(friends), next)
+		}
+		for _, f := range friends {
+			got = append(got, f.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !slices.IsSorted(got) {
+		t.Errorf("pages = %v, want ID order", got)
+	}
+	if !sameIDs(got, want) {
+		t.Errorf("pages = %v, want each of %v once", got, want)
+	}
+}
+
+func testFriendLimit(t *testing.T, db DB) {
+	ctx := context.Background()
+	a, b, c := newUser(t, db), newUser(t, db), newUser(t, db)
+	befriend(t, db, b, a)
+
+	req, err := db.CreateFriendRequest(ctx, friendRequest(c, a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.AcceptFriendRequest(ctx, req.ID, a.ID, 1)
+	requireLimitError(t, err, database.ErrFriendLimitReached, 1)
+	if _, err := db.AcceptFriendRequest(ctx, req.ID, a.ID, 2); err != nil {
+		t.Fatalf("AcceptFriendRequest under the limit: %v", err)
+	}
+}
+
+// testAcceptFriendRequestConcurrent has two users add each other at the same time, in both
+// directions, and accept every request that was created many times over at once: each must end
+// up with exactly one Friend entry for and one follower entry of the other.
+func testAcceptFriendRequestConcurrent(t *testing.T, db DB) {
+	const n = 10
+	ctx := context.Background()
+	a, b := newUser(t, db), newUser(t, db)
+
+	// Requests in either direction exclude each other; backends whose check is not atomic
+	// across directions may keep one of each
+	requests := []*models.FriendRequest{friendRequest(a, b), friendRequest(b, a)}
+	created := make([]string, 2*n)
+	errs := parallel(2*n, func(i int) error {
+		req, err := db.CreateFriendRequest(ctx, requests[i%2])
+		if err != nil {
+			return err
+		}
+		created[i] = req.ID
+		return nil
+	})
+	var ids []string
+	for i, err := range errs {
+		switch {
+		case errors.Is(err, database.ErrFriendRequestExists):
+		case err != nil:
+			t.Fatalf("CreateFriendRequest: %v", err)
+		default:
+			ids = append(ids, created[i])
+		}
+	}
+	if len(ids) == 0 || len(ids) > 2 {
+		t.Fatalf("%d friend requests created, want 1 or 2", len(ids))
+	}
+
+	type accept struct{ requestID, userID string }
+	var accepts []accept
+	for _, id := range ids {
+		for _, u := range []string{a.ID, b.ID} {
+			accepts = append(accepts, accept{id, u})
+		}
+	}
+	accepted := 0
+	errs = parallel(n*len(accepts), func(i int) error {
+		acc := accepts[i%len(accepts)]
+		_, err := db.AcceptFriendRequest(ctx, acc.requestID, acc.userID, 0)
+		return err
+	})
+	for _, err := range errs {
+		switch {
+		case errors.Is(err, database.ErrFriendRequestNotFound):
+		case err != nil:
+			t.Fatalf("AcceptFriendRequest: %v", err)
+		default:
+			accepted++
+		}
+	}
+	if accepted != len(ids) {
+		t.Errorf("%d accepts succeeded, want one per request (%d)", accepted, len(ids))
+	}
+
+	for _, pair := range [][2]*models.User{{a, b}, {b, a}} {
+		user, other := pair[0], pair[1]
+		friends, err := db.GetFriendsByUser(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(friends) != 1 || friends[0].ShareToken != other.ShareToken {
+			t.Errorf("friends of %s = %+v, want only %s", user.ID, friends, other.ShareToken)
+		}
+		followers, err := db.GetFollowers(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(followers) != 1 || followers[0].UserID != other.ID {
+			t.Errorf("followers of %s = %+v, want only %s", user.ID, followers, other.ID)
+		}
+	}
+}
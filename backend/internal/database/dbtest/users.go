@@ -0,0 +1,101 @@
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func testEnsureUser(t *testing.T, db DB) {
+	ctx := context.Background()
+	id := newUserID()
+	if u, err := db.GetUserByID(ctx, id); err != nil || u != nil {
+		t.Fatalf("GetUserByID before EnsureUser = %+v, %v; want nil, nil", u, err)
+	}
+
+	err := db.EnsureUser(ctx, &models.User{ID: id, Name: "First", ImageURL: "https://a/1.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := db.GetUserByID(ctx, id)
+	if err != nil || u == nil {
+		t.Fatalf("GetUserByID = %+v, %v", u, err)
+	}
+	if u.ID != id || u.Name != "First" || u.ImageURL != "https://a/1.png" {
+		t.Errorf("created %+v, want ID, Name and ImageURL as given", u)
+	}
+	if u.ShareToken == "" || u.Handle != "" {
+		t.Errorf("ShareToken = %q, Handle = %q; want a token and no handle", u.ShareToken,
+			u.Handle)
+	}
+	byToken, err := db.GetUserByShareToken(ctx, u.ShareToken)
+	if err != nil || byToken == nil || byToken.ID != id {
+		t.Errorf("GetUserByShareToken = %+v, %v; want %s", byToken, err, id)
+	}
+	if other, err := db.GetUserByShareToken(ctx, "no-such-token"); err != nil || other != nil {
+		t.Errorf("GetUserByShareToken(unknown) = %+v, %v; want nil, nil", other, err)
+	}
+
+	// Later logins update the profile but keep the token, and an empty name keeps the name
+	if err := db.EnsureUser(ctx, &models.User{ID: id, ImageURL: "https://a/2.png"}); err != nil {
+		t.Fatal(err)
+	}
+	again, err := db.GetUserByID(ctx, id)
+	if err != nil || again == nil {
+		t.Fatalf("GetUserByID = %+v, %v", again, err)
+	}
+	if again.ShareToken != u.ShareToken || again.Name != "First" ||
+		again.ImageURL != "https://a/2.png" {
+		t.Errorf("after another login = %+v; want token %s, name First and the new image",
+			again, u.ShareToken)
+	}
+}
+
+// testEnsureUserConcurrent logs the same new user in from many goroutines at once, each reading
+// the user back as a login does: all of them must see the one ShareToken that is stored.
+func testEnsureUserConcurrent(t *testing.T, db DB) {
+	const n = 20
+	ctx := context.Background()
+	id := newUserID()
+
+	tokens := make([]string, n)
+	errs := parallel(n, func(i int) error {
+		err := db.EnsureUser(ctx, &models.User{ID: id, Name: fmt.Sprintf("Name %d", i)})
+		if err != nil {
+			return err
+		}
+		u, err := db.GetUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return fmt.Errorf("user %s not found after EnsureUser", id)
+		}
+		tokens[i] = u.ShareToken
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u, err := db.GetUserByID(ctx, id)
+	if err != nil || u == nil || u.ShareToken == "" {
+		t.Fatalf("GetUserByID = %+v, %v", u, err)
+	}
+	for i, token := range tokens {
+		if token != u.ShareToken {
+			t.Errorf("caller %d saw ShareToken %q, stored is %q", i, token, u.ShareToken)
+		}
+	}
+	byToken, err := db.GetUserByShareToken(ctx, u.ShareToken)
+	if err != nil || byToken == nil || byToken.ID != id {
+		t.Errorf("GetUserByShareToken = %+v, %v; want %s", byToken, err, id)
+	}
+	if u.Handle != "" {
+		t.Errorf("Handle = %q, want none: logins do not claim handles", u.Handle)
+	}
+}
@@ -0,0 +1,219 @@
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// visitedTime is the visit time of test visits; the backends store it to the second.
+var visitedTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// newVisit creates a visit of userID to countryCode with no visit limit.
+func newVisit(t *testing.T, db DB, userID, countryCode string) *models.CountryVisit {
+	t.Helper()
+	v, err := db.CreateCountryVisit(context.Background(), &models.CountryVisit{
+		UserID: userID, CountryCode: countryCode, VisitedTime: visitedTime,
+	}, 0)
+	if err != nil {
+		t.Fatalf("CreateCountryVisit: %v", err)
+	}
+	return v
+}
+
+func testVisits(t *testing.T, db DB) {
+	ctx := context.Background()
+	u, other := newUser(t, db), newUser(t, db)
+
+	fi, err := db.CreateCountryVisit(ctx, &models.CountryVisit{
+		UserID: u.ID, CountryCode: "FI", VisitedTime: visitedTime, Notes: "Sauna",
+		Tags: []string{"summer"},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.ID == "" || fi.UpdatedAt == nil {
+		t.Fatalf("created %+v, want an ID and UpdatedAt", fi)
+	}
+	se, err := db.CreateCountryVisit(ctx, &models.CountryVisit{
+		UserID: u.ID, CountryCode: "SE", VisitedTime: visitedTime, IsPrivate: true,
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := db.GetCountryVisit(ctx, fi.ID, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != fi.ID || got.UserID != u.ID || got.CountryCode != "FI" ||
+		!got.VisitedTime.Equal(visitedTime) || got.Notes != "Sauna" ||
+		!slices.Equal(got.Tags, []string{"summer"}) {
+		t.Errorf("GetCountryVisit = %+v, want the created visit", got)
+	}
+	if got.UpdatedAt == nil || !got.UpdatedAt.Equal(*fi.UpdatedAt) {
+		t.Errorf("UpdatedAt = %v, want %v as returned on create", got.UpdatedAt, fi.UpdatedAt)
+	}
+
+	all, err := db.GetCountryVisitsByUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := visitIDs(all); !sameIDs(ids, []string{fi.ID, se.ID}) {
+		t.Errorf("GetCountryVisitsByUser = %v, want %s and %s", ids, fi.ID, se.ID)
+	}
+	public, err := db.GetPublicCountryVisitsByUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := visitIDs(public); !sameIDs(ids, []string{fi.ID}) {
+		t.Errorf("GetPublicCountryVisitsByUser = %v, want only %s", ids, fi.ID)
+	}
+
+	// Updates
+	stale := fi.UpdatedAt.Add(-time.Second)
+	got.Notes = "Lakes"
+	if err := db.ReplaceCountryVisit(ctx, got, stale); !errors.Is(err, database.ErrVisitModified) {
+		t.Errorf("ReplaceCountryVisit(stale) = %v, want ErrVisitModified", err)
+	}
+	if err := db.ReplaceCountryVisit(ctx, got, *fi.UpdatedAt); err != nil {
+		t.Fatalf("ReplaceCountryVisit: %v", err)
+	}
+	if got.UpdatedAt == nil || !got.UpdatedAt.After(*fi.UpdatedAt) {
+		t.Errorf("UpdatedAt after replace = %v, want after %v", got.UpdatedAt, fi.UpdatedAt)
+	}
+	replaced, err := db.GetCountryVisit(ctx, fi.ID, u.ID)
+	if err != nil || replaced.Notes != "Lakes" || !replaced.UpdatedAt.Equal(*got.UpdatedAt) {
+		t.Errorf("after replace = %+v, %v; want notes Lakes", replaced, err)
+	}
+
+	// Visits are only found by their owner
+	if _, err := db.GetCountryVisit(ctx, fi.ID, other.ID); !errors.Is(err,
+		database.ErrVisitNotFound) {
+		t.Errorf("GetCountryVisit by another user = %v, want ErrVisitNotFound", err)
+	}
+	if err := db.DeleteCountryVisit(ctx, fi.ID, other.ID); !errors.Is(err,
+		database.ErrVisitNotFound) {
+		t.Errorf("DeleteCountryVisit by another user = %v, want ErrVisitNotFound", err)
+	}
+
+	if err := db.DeleteCountryVisit(ctx, se.ID, u.ID); err != nil {
+		t.Fatalf("DeleteCountryVisit: %v", err)
+	}
+	if _, err := db.GetCountryVisit(ctx, se.ID, u.ID); !errors.Is(err,
+		database.ErrVisitNotFound) {
+		t.Errorf("GetCountryVisit after delete = %v, want ErrVisitNotFound", err)
+	}
+	if err := db.DeleteCountryVisit(ctx, se.ID, u.ID); !errors.Is(err,
+		database.ErrVisitNotFound) {
+		t.Errorf("DeleteCountryVisit again = %v, want ErrVisitNotFound", err)
+	}
+	if _, err := db.GetCountryVisit(ctx, "no-such-visit", newUserID()); !errors.Is(err,
+		database.ErrVisitNotFound) {
+		t.Errorf("GetCountryVisit of an unknown user = %v, want ErrVisitNotFound", err)
+	}
+	all, err = db.GetCountryVisitsByUser(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := visitIDs(all); !sameIDs(ids, []string{fi.ID}) {
+		t.Errorf("GetCountryVisitsByUser after delete = %v, want only %s", ids, fi.ID)
+	}
+}
+
+func testVisitsPage(t *testing.T, db DB) {
+	ctx := context.Background()
+	u := newUser(t, db)
+	var want []string
+	for _, code := range []string{"FI", "SE", "NO", "DK", "IS"} {
+		want = append(want, newVisit(t, db, u.ID, code).ID)
+	}
+
+	var got []string
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > len(want) {
+			t.Fatalf("no last page after %d pages", page)
+		}
+		visits, next, err := db.GetCountryVisitsPage(ctx, u.ID, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(visits) > 2 || (next != "" && len(visits) != 2) {
+			t.Fatalf("page %d has %d visits and cursor %q", page, len(visits), next)
+		}
+		got = append(got, visitIDs(visits)...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if !slices.IsSorted(got) {
+		t.Errorf("pages = %v, want ID order", got)
+	}
+	if !sameIDs(got, want) {
+		t.Errorf("pages = %v, want each of %v once", got, want)
+	}
+
+	visits, next, err := db.GetCountryVisitsPage(ctx, newUserID(), "", 2)
+	if err != nil || len(visits) != 0 || next != "" {
+		t.Errorf("page of an unknown user = %v, %q, %v; want none", visits, next, err)
+	}
+}
+
+func testVisitLimit(t *testing.T, db DB) {
+	ctx := context.Background()
+	u := newUser(t, db)
+	create := func(maxVisits int) error {
+		_, err := db.CreateCountryVisit(ctx, &models.CountryVisit{
+			UserID: u.ID, CountryCode: "FI", VisitedTime: visitedTime,
+		}, maxVisits)
+		return err
+	}
+	for range 2 {
+		if err := create(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	requireLimitError(t, create(2), database.ErrVisitLimitReached, 2)
+
+	// An admin override takes precedence over the default limit
+	if err := db.SetUserLimits(ctx, u.ID, &models.UserLimits{MaxVisits: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := create(2); err != nil {
+		t.Fatalf("create with an override of 3: %v", err)
+	}
+	requireLimitError(t, create(2), database.ErrVisitLimitReached, 3)
+	if err := db.SetUserLimits(ctx, newUserID(), &models.UserLimits{MaxVisits: 3}); !errors.Is(
+		err, database.ErrUserNotFound) {
+		t.Errorf("SetUserLimits of an unknown user = %v, want ErrUserNotFound", err)
+	}
+}
+
+// requireLimitError fails the test unless err is a database.LimitError of target and limit.
+func requireLimitError(t *testing.T, err, target error, limit int) {
+	t.Helper()
+	var limitErr *database.LimitError
+	if !errors.Is(err, target) || !errors.As(err, &limitErr) || limitErr.Limit != limit {
+		t.Fatalf("err = %v, want a LimitError of %v with limit %d", err, target, limit)
+	}
+}
+
+func visitIDs(visits []models.CountryVisit) []string {
+	ids := make([]string, len(visits))
+	for i, v := range visits {
+		ids[i] = v.ID
+	}
+	return ids
+}
+
+// sameIDs reports whether got holds each of want exactly once, in any order.
+func sameIDs(got, want []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(want)))
+}
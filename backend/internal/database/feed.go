@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cloud.google.com/go/firestore"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// feedReads is how many users' visits GetFeedVisits reads at a time.
+const feedReads = 10
+
+// GetFeedVisits returns up to limit non-private visits of userIDs in feed order (VisitedTime
+// descending, then visit ID descending), starting after the cursor when it is non-nil.
+// Each user's visits are read newest first in pages of limit, so only the single-field index on
+// VisitTime is needed; private visits are skipped while reading (see
+// GetPublicCountryVisitsByUser). Users are read concurrently, at most feedReads at a time;
+// callers bound their number (models.MaxFeedFriends).
+func (c *Client) GetFeedVisits(
+	ctx context.Context,
+	userIDs []string,
+	after *models.FeedCursor,
+	limit int,
+) ([]models.CountryVisit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	byUser := make([][]models.CountryVisit, len(userIDs))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(feedReads)
+	for i, userID := range userIDs {
+		g.Go(func() error {
+			userVisits, err := c.getFeedVisitsOfUser(gctx, userID, after, limit)
+			byUser[i] = userVisits
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	var visits []models.CountryVisit
+	for _, userVisits := range byUser {
+		visits = append(visits, userVisits...)
+	}
+	sort.Slice(visits, func(i, j int) bool {
+		if !visits[i].VisitedTime.Equal(visits[j].VisitedTime) {
+			return visits[i].VisitedTime.After(visits[j].VisitedTime)
+		}
+		return visits[i].ID > visits[j].ID
+	})
+	if len(visits) > limit {
+		visits = visits[:limit]
+	}
+	return visits, nil
+}
+
+// getFeedVisitsOfUser returns up to limit of the user's non-private visits after the cursor, in
+// feed order.
+func (c *Client) getFeedVisitsOfUser(
+	ctx context.Context,
+	userID string,
+	after *models.FeedCursor,
+	limit int,
+) ([]models.CountryVisit, error) {
+	// Ties on VisitTime are ordered by document ID descending, as in the feed
+	query := c.Collection("users").Doc(userID).Collection("country_visits").
+		OrderBy("VisitTime", firestore.Desc)
+	if after != nil {
+		// Visits at the cursor's exact time may still follow it; Precedes sorts them out
+		query = query.Where("VisitTime", "<=", after.VisitedTime)
+	}
+	query = query.Limit(limit)
+
+	var visits []models.CountryVisit
+	var last *firestore.DocumentSnapshot
+	for {
+		q := query
+		if last != nil {
+			q = q.StartAfter(last)
+		}
+		docs, err := q.Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list feed visits: %w", err)
+		}
+		for _, doc := range docs {
+			var visit models.CountryVisit
+			if err := doc.DataTo(&visit); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal country visit: %w", err)
+			}
+			visit.ID = doc.Ref.ID
+			if visit.IsPrivate || (after != nil && !after.Precedes(visit)) {
+				continue
+			}
+			if visit.Tags == nil {
+				visit.Tags = []string{}
+			}
+			visit.Verified = len(visit.Proofs) > 0
+			visit.UserID = userID
+			visits = append(visits, visit)
+			if len(visits) == limit {
+				return visits, nil
+			}
+		}
+		if len(docs) < limit {
+			return visits, nil
+		}
+		last = docs[len(docs)-1]
+	}
+}
@@ -6,6 +6,10 @@ import (
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 // Client wraps Firestore client
@@ -13,9 +17,23 @@ type Client struct {
 	*firestore.Client
 }
 
-// NewClient creates a new Firestore client
-func NewClient(ctx context.Context, projectID string) (*Client, error) {
-	client, err := firestore.NewClient(ctx, projectID)
+// NewClient creates a new Firestore client. opts are passed to firestore.NewClient. A non-empty
+// emulatorHost (host:port) connects to the Firestore emulator instead, over plaintext gRPC and
+// without Google credentials.
+func NewClient(
+	ctx context.Context,
+	projectID, emulatorHost string,
+	opts ...option.ClientOption,
+) (*Client, error) {
+	if emulatorHost != "" {
+		opts = append([]option.ClientOption{
+			option.WithEndpoint(emulatorHost),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+			option.WithGRPCDialOption(grpc.WithPerRPCCredentials(emulatorCredentials{})),
+		}, opts...)
+	}
+	client, err := firestore.NewClient(ctx, projectID, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create firestore client: %w", err)
 	}
@@ -33,6 +51,31 @@ func NewClientWithCredentials(ctx context.Context, projectID string, credentials
 	return &Client{Client: client}, nil
 }
 
+// emulatorCredentials authenticates as the emulator's admin ("Bearer owner"), which bypasses
+// security rules.
+type emulatorCredentials struct{}
+
+func (emulatorCredentials) GetRequestMetadata(
+	ctx context.Context,
+	uri ...string,
+) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer owner"}, nil
+}
+
+func (emulatorCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// Ping checks that Firestore answers by reading a document that does not exist (one document
+// read).
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Collection("health").Doc("ping").Get(ctx)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("failed to ping firestore: %w", err)
+	}
+	return nil
+}
+
 // Close closes the Firestore client
 func (c *Client) Close() error {
 	return c.Client.Close()
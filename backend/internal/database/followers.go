@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// followerRef is the reverse index entry users/{userID}/followers/{followerID}, present while
+// followerID has userID as a friend.
+func (c *Client) followerRef(userID, followerID string) *firestore.DocumentRef {
+	return c.Collection("users").Doc(userID).Collection("followers").Doc(followerID)
+}
+
+// GetFollowers returns the users who have userID as a friend, most recent first. ShareToken,
+// Name and ImageURL are read from the followers' User documents; followers whose account no
+// longer exists or who set Settings.HideFromFollowers are left out. IsFriend is not set.
+func (c *Client) GetFollowers(ctx context.Context, userID string) ([]models.Follower, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	docs, err := c.Collection("users").Doc(userID).Collection("followers").Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list followers: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	userRefs := make([]*firestore.DocumentRef, len(docs))
+	for i, doc := range docs {
+		userRefs[i] = c.Collection("users").Doc(doc.Ref.ID)
+	}
+	userSnaps, err := c.GetAll(ctx, userRefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follower users: %w", err)
+	}
+	followers := make([]models.Follower, 0, len(docs))
+	for i, doc := range docs {
+		if !userSnaps[i].Exists() {
+			continue
+		}
+		var u models.User
+		if err := userSnaps[i].DataTo(&u); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+		}
+		if u.Settings != nil && u.Settings.HideFromFollowers {
+			continue
+		}
+		var f models.Follower
+		if err := doc.DataTo(&f); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal follower: %w", err)
+		}
+		f.UserID = doc.Ref.ID
+		f.ShareToken = u.ShareToken
+		f.Name = u.Name
+		f.ImageURL = u.ImageURL
+		followers = append(followers, f)
+	}
+	sort.Slice(followers, func(i, j int) bool {
+		return followers[i].CreatedAt.After(followers[j].CreatedAt)
+	})
+	return followers, nil
+}
+
+// RebuildFollowers adds userID to the followers index of every user in userID's friends, for
+// friendships created before the index existed. Existing entries are kept, so it is idempotent.
+func (c *Client) RebuildFollowers(ctx context.Context, userID string) error {
+	friends, err := c.GetFriendsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(friends) == 0 {
+		return nil
+	}
+	shareTokens := make([]string, len(friends))
+	for i, f := range friends {
+		shareTokens[i] = f.ShareToken
+	}
+	users, err := c.GetUsersByShareTokens(ctx, shareTokens)
+	if err != nil {
+		return err
+	}
+	bw := c.BulkWriter(ctx)
+	now := time.Now().UTC()
+	var jobs []*firestore.BulkWriterJob
+	for _, u := range users {
+		job, err := bw.Create(c.followerRef(u.ID, userID), models.Follower{CreatedAt: now})
+		if err != nil {
+			bw.End()
+			return fmt.Errorf("failed to queue follower: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil && status.Code(err) != codes.AlreadyExists {
+			return fmt.Errorf("failed to create follower: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var (
+	ErrFriendInviteNotFound = errors.New("friend invite not found")
+	ErrFriendInviteExists   = errors.New("an invite to this email is already pending")
+	ErrTooManyFriendInvites = errors.New("too many pending invites")
+)
+
+func (c *Client) friendInvitesRef(userID string) *firestore.CollectionRef {
+	return c.Collection("users").Doc(userID).Collection("friend_invites")
+}
+
+// CreateFriendInvite stores an invite of userID to invite.Email, expiring after
+// models.FriendInviteTTL. Returns ErrFriendInviteExists when an unexpired invite to the email
+// exists and ErrTooManyFriendInvites when the user has models.MaxPendingFriendInvites.
+func (c *Client) CreateFriendInvite(
+	ctx context.Context,
+	userID string,
+	invite *models.FriendInvite,
+) (*models.FriendInvite, error) {
+	if userID == "" || invite == nil || invite.Email == "" {
+		return nil, fmt.Errorf("userID and email are required")
+	}
+	coll := c.friendInvitesRef(userID)
+	ref := coll.NewDoc()
+	out := *invite
+	out.ID = ref.ID
+	out.CreatedAt = time.Now().UTC()
+	out.ExpiresAt = out.CreatedAt.Add(models.FriendInviteTTL)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.Documents(coll.Where("ExpiresAt", ">", out.CreatedAt)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list friend invites: %w", err)
+		}
+		if len(docs) >= models.MaxPendingFriendInvites {
+			return ErrTooManyFriendInvites
+		}
+		for _, doc := range docs {
+			if email, _ := doc.DataAt("Email"); email == out.Email {
+				return ErrFriendInviteExists
+			}
+		}
+		return tx.Create(ref, &out)
+	})
+	if errors.Is(err, ErrFriendInviteExists) || errors.Is(err, ErrTooManyFriendInvites) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create friend invite: %w", err)
+	}
+	return &out, nil
+}
+
+// GetFriendInvites returns the unexpired invites of userID, oldest first.
+func (c *Client) GetFriendInvites(
+	ctx context.Context,
+	userID string,
+) ([]models.FriendInvite, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	return c.queryFriendInvites(ctx, c.friendInvitesRef(userID).
+		Where("ExpiresAt", ">", time.Now().UTC()))
+}
+
+// FindFriendInvite returns the unexpired invite of userID to email (normalized), or nil.
+func (c *Client) FindFriendInvite(
+	ctx context.Context,
+	userID, email string,
+) (*models.FriendInvite, error) {
+	if userID == "" || email == "" {
+		return nil, fmt.Errorf("userID and email are required")
+	}
+	invites, err := c.queryFriendInvites(ctx, c.friendInvitesRef(userID).
+		Where("Email", "==", email))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, inv := range invites {
+		if now.Before(inv.ExpiresAt) {
+			return &inv, nil
+		}
+	}
+	return nil, nil
+}
+
+// DeleteFriendInvite deletes the invite inviteID of userID. Returns ErrFriendInviteNotFound if
+// it does not exist.
+func (c *Client) DeleteFriendInvite(ctx context.Context, userID, inviteID string) error {
+	if userID == "" || inviteID == "" {
+		return fmt.Errorf("userID and inviteID are required")
+	}
+	_, err := c.friendInvitesRef(userID).Doc(inviteID).Delete(ctx, firestore.Exists)
+	if status.Code(err) == codes.NotFound {
+		return ErrFriendInviteNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete friend invite: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) queryFriendInvites(
+	ctx context.Context,
+	q firestore.Query,
+) ([]models.FriendInvite, error) {
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friend invites: %w", err)
+	}
+	invites := make([]models.FriendInvite, 0, len(docs))
+	for _, doc := range docs {
+		var inv models.FriendInvite
+		if err := doc.DataTo(&inv); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal friend invite: %w", err)
+		}
+		inv.ID = doc.Ref.ID
+		invites = append(invites, inv)
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.Before(invites[j].CreatedAt)
+	})
+	return invites, nil
+}
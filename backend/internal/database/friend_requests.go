@@ -0,0 +1,290 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var (
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	ErrFriendRequestExists   = errors.New("friend request already exists")
+	ErrFriendLimitReached    = errors.New("friend limit reached")
+)
+
+// CreateFriendRequest stores a friend request from req.FromUserID to req.ToUserID. Returns
+// ErrFriendRequestExists when a request between the two users exists in either direction.
+func (c *Client) CreateFriendRequest(
+	ctx context.Context,
+	req *models.FriendRequest,
+) (*models.FriendRequest, error) {
+	if req == nil || req.FromUserID == "" || req.ToUserID == "" {
+		return nil, fmt.Errorf("fromUserID and toUserID are required")
+	}
+	coll := c.Collection("friend_requests")
+	ref := coll.NewDoc()
+	out := *req
+	out.ID = ref.ID
+	out.CreatedAt = time.Now().UTC()
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for _, q := range []firestore.Query{
+			coll.Where("FromUserID", "==", req.FromUserID).Where("ToUserID", "==", req.ToUserID),
+			coll.Where("FromUserID", "==", req.ToUserID).Where("ToUserID", "==", req.FromUserID),
+		} {
+			docs, err := tx.Documents(q.Limit(1)).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to check existing friend requests: %w", err)
+			}
+			if len(docs) > 0 {
+				return ErrFriendRequestExists
+			}
+		}
+		return tx.Create(ref, &out)
+	})
+	if errors.Is(err, ErrFriendRequestExists) {
+		return nil, ErrFriendRequestExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create friend request: %w", err)
+	}
+	return &out, nil
+}
+
+// GetFriendRequests returns the friend requests sent to and by userID, oldest first.
+func (c *Client) GetFriendRequests(
+	ctx context.Context,
+	userID string,
+) (incoming, outgoing []models.FriendRequest, err error) {
+	if userID == "" {
+		return nil, nil, fmt.Errorf("userID is required")
+	}
+	coll := c.Collection("friend_requests")
+	incoming, err = c.queryFriendRequests(ctx, coll.Where("ToUserID", "==", userID))
+	if err != nil {
+		return nil, nil, err
+	}
+	outgoing, err = c.queryFriendRequests(ctx, coll.Where("FromUserID", "==", userID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return incoming, outgoing, nil
+}
+
+func (c *Client) queryFriendRequests(
+	ctx context.Context,
+	q firestore.Query,
+) ([]models.FriendRequest, error) {
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friend requests: %w", err)
+	}
+	requests := make([]models.FriendRequest, 0, len(docs))
+	for _, doc := range docs {
+		r, err := friendRequestFromSnapshot(doc)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *r)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+func friendRequestFromSnapshot(snap *firestore.DocumentSnapshot) (*models.FriendRequest, error) {
+	var r models.FriendRequest
+	if err := snap.DataTo(&r); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal friend request: %w", err)
+	}
+	r.ID = snap.Ref.ID
+	return &r, nil
+}
+
+// GetFriendRequest returns the friend request with requestID if userID sent or received it;
+// otherwise ErrFriendRequestNotFound.
+func (c *Client) GetFriendRequest(
+	ctx context.Context,
+	requestID, userID string,
+) (*models.FriendRequest, error) {
+	if requestID == "" || userID == "" {
+		return nil, fmt.Errorf("requestID and userID are required")
+	}
+	snap, err := c.Collection("friend_requests").Doc(requestID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrFriendRequestNotFound
+		}
+		return nil, fmt.Errorf("failed to get friend request: %w", err)
+	}
+	r, err := friendRequestFromSnapshot(snap)
+	if err != nil {
+		return nil, err
+	}
+	if r.FromUserID != userID && r.ToUserID != userID {
+		return nil, ErrFriendRequestNotFound
+	}
+	return r, nil
+}
+
+// AcceptFriendRequest accepts a friend request received by userID in one transaction: each user
+// gets the other as a Friend (unless already present) and as a follower, and the request is
+// deleted. Returns the requester as the recipient's Friend, ErrFriendRequestNotFound unless
+// userID received it, or a LimitError wrapping ErrFriendLimitReached when a user gaining a
+// friend has maxFriends, or as many as their models.UserLimits override allows.
+func (c *Client) AcceptFriendRequest(
+	ctx context.Context,
+	requestID, userID string,
+	maxFriends int,
+) (models.Friend, error) {
+	if requestID == "" || userID == "" {
+		return models.Friend{}, fmt.Errorf("requestID and userID are required")
+	}
+	ref := c.Collection("friend_requests").Doc(requestID)
+	var friend models.Friend
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrFriendRequestNotFound
+			}
+			return fmt.Errorf("failed to get friend request: %w", err)
+		}
+		r, err := friendRequestFromSnapshot(snap)
+		if err != nil {
+			return err
+		}
+		if r.ToUserID != userID {
+			return ErrFriendRequestNotFound
+		}
+
+		// Reads first: Firestore transactions allow no reads after writes
+		toFriends := c.Collection("users").Doc(r.ToUserID).Collection("friends")
+		fromFriends := c.Collection("users").Doc(r.FromUserID).Collection("friends")
+		toExisting, err := tx.Documents(toFriends.Where("ShareToken", "==", r.FromShareToken).
+			Limit(1)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to check existing friend: %w", err)
+		}
+		fromExisting, err := tx.Documents(fromFriends.Where("ShareToken", "==", r.ToShareToken).
+			Limit(1)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to check existing friend: %w", err)
+		}
+		if len(toExisting) == 0 {
+			if err := checkFriendLimit(ctx, tx, toFriends, maxFriends); err != nil {
+				return err
+			}
+		}
+		if len(fromExisting) == 0 {
+			if err := checkFriendLimit(ctx, tx, fromFriends, maxFriends); err != nil {
+				return err
+			}
+		}
+
+		friend = models.Friend{
+			ShareToken: r.FromShareToken,
+			Name:       r.FromName,
+			ImageURL:   r.FromImageURL,
+		}
+		now := time.Now().UTC()
+		if len(toExisting) == 0 {
+			newRef := toFriends.NewDoc()
+			friend.ID = newRef.ID
+			if err := tx.Create(newRef, friendDoc(friend)); err != nil {
+				return err
+			}
+			follower := models.Follower{CreatedAt: now}
+			if err := tx.Set(c.followerRef(r.FromUserID, r.ToUserID), follower); err != nil {
+				return err
+			}
+		} else {
+			friend.ID = toExisting[0].Ref.ID
+		}
+		if len(fromExisting) == 0 {
+			back := models.Friend{ShareToken: r.ToShareToken, Name: r.ToName, ImageURL: r.ToImageURL}
+			if err := tx.Create(fromFriends.NewDoc(), friendDoc(back)); err != nil {
+				return err
+			}
+			follower := models.Follower{CreatedAt: now}
+			if err := tx.Set(c.followerRef(r.ToUserID, r.FromUserID), follower); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(ref)
+	})
+	if errors.Is(err, ErrFriendRequestNotFound) || errors.Is(err, ErrFriendLimitReached) {
+		return models.Friend{}, err
+	}
+	if err != nil {
+		return models.Friend{}, fmt.Errorf("failed to accept friend request: %w", err)
+	}
+	return friend, nil
+}
+
+// checkFriendLimit returns a LimitError when the friends collection of a user has MaxFriends
+// (maxFriends or the user's override; 0 for no limit) documents. It counts with an aggregation
+// query, so a full collection is not read.
+func checkFriendLimit(
+	ctx context.Context,
+	tx *firestore.Transaction,
+	friends *firestore.CollectionRef,
+	maxFriends int,
+) error {
+	user, err := tx.Get(friends.Parent)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	limits, err := userLimits(user, models.UserLimits{MaxFriends: maxFriends})
+	if err != nil {
+		return err
+	}
+	if limits.MaxFriends <= 0 {
+		return nil
+	}
+	res, err := friends.NewAggregationQuery().WithCount("count").Transaction(tx).Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count friends: %w", err)
+	}
+	count, ok := res["count"].(*firestorepb.Value)
+	if !ok {
+		return fmt.Errorf("unexpected friend count result %v", res["count"])
+	}
+	if count.GetIntegerValue() >= int64(limits.MaxFriends) {
+		return NewLimitError(ErrFriendLimitReached, friends.Parent.ID, limits.MaxFriends)
+	}
+	return nil
+}
+
+// friendDoc is the stored form of f; ImageURL is written only when set.
+func friendDoc(f models.Friend) map[string]interface{} {
+	doc := map[string]interface{}{
+		"ShareToken": f.ShareToken,
+		"Name":       f.Name,
+	}
+	if f.ImageURL != "" {
+		doc["ImageURL"] = f.ImageURL
+	}
+	return doc
+}
+
+// DeleteFriendRequest deletes a friend request sent or received by userID (declining or
+// withdrawing it). Returns ErrFriendRequestNotFound otherwise.
+func (c *Client) DeleteFriendRequest(ctx context.Context, requestID, userID string) error {
+	if _, err := c.GetFriendRequest(ctx, requestID, userID); err != nil {
+		return err
+	}
+	if _, err := c.Collection("friend_requests").Doc(requestID).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete friend request: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotGuest is returned by MergeGuestUser when the account to merge is not a guest account.
+var ErrNotGuest = errors.New("not a guest account")
+
+// MergeGuestUser merges the guest account guestUserID into userID with MergeUsers, returning the
+// number of visits moved. Returns ErrUserNotFound when either user document is missing and
+// ErrNotGuest unless guestUserID is a guest account.
+func (c *Client) MergeGuestUser(
+	ctx context.Context,
+	guestUserID, userID string,
+	now time.Time,
+) (int, error) {
+	if guestUserID == "" || userID == "" {
+		return 0, fmt.Errorf("guestUserID and userID are required")
+	}
+	guest, err := c.GetUserByID(ctx, guestUserID)
+	if err != nil {
+		return 0, err
+	}
+	if guest == nil {
+		return 0, ErrUserNotFound
+	}
+	if !guest.IsAnonymous {
+		return 0, ErrNotGuest
+	}
+	res, err := c.MergeUsers(ctx, guestUserID, userID, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.Visits, nil
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var (
+	ErrHandleNotFound = errors.New("handle not found")
+	ErrHandleTaken    = errors.New("handle is already taken")
+)
+
+func (c *Client) handleRef(handle string) *firestore.DocumentRef {
+	return c.Collection("handles").Doc(handle)
+}
+
+// SetHandle gives userID the (normalized, valid) handle in one transaction, releasing the
+// user's previous handle. Returns ErrHandleTaken when another user has it and ErrUserNotFound
+// without a User document. Setting the user's current handle again changes nothing.
+func (c *Client) SetHandle(ctx context.Context, userID, handle string) error {
+	if userID == "" || handle == "" {
+		return fmt.Errorf("userID and handle are required")
+	}
+	userRef := c.Collection("users").Doc(userID)
+	ref := c.handleRef(handle)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		userSnap, err := tx.Get(userRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		snap, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return fmt.Errorf("failed to get handle: %w", err)
+		}
+		if snap.Exists() {
+			if owner, _ := snap.DataAt("UserID"); owner != userID {
+				return ErrHandleTaken
+			}
+		}
+		raw, _ := userSnap.DataAt("Handle")
+		old, _ := raw.(string)
+		if old == handle && snap.Exists() {
+			return nil
+		}
+		if old != "" && old != handle {
+			if err := tx.Delete(c.handleRef(old)); err != nil {
+				return err
+			}
+		}
+		err = tx.Set(ref, &models.Handle{UserID: userID, CreatedAt: time.Now().UTC()})
+		if err != nil {
+			return err
+		}
+		return tx.Update(userRef, []firestore.Update{{Path: "Handle", Value: handle}})
+	})
+	if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrHandleTaken) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set handle: %w", err)
+	}
+	return nil
+}
+
+// DeleteHandle releases the handle of userID, which anyone may claim afterwards. Returns
+// ErrHandleNotFound when the user has none and ErrUserNotFound without a User document.
+func (c *Client) DeleteHandle(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	userRef := c.Collection("users").Doc(userID)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		userSnap, err := tx.Get(userRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		raw, _ := userSnap.DataAt("Handle")
+		handle, _ := raw.(string)
+		if handle == "" {
+			return ErrHandleNotFound
+		}
+		if err := tx.Delete(c.handleRef(handle)); err != nil {
+			return err
+		}
+		return tx.Update(userRef, []firestore.Update{{Path: "Handle", Value: firestore.Delete}})
+	})
+	if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrHandleNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete handle: %w", err)
+	}
+	return nil
+}
+
+// GetUserByHandle returns the owner of the normalized handle, or nil (not error) when nobody
+// has it.
+func (c *Client) GetUserByHandle(ctx context.Context, handle string) (*models.User, error) {
+	if handle == "" {
+		return nil, fmt.Errorf("handle is required")
+	}
+	snap, err := c.handleRef(handle).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handle: %w", err)
+	}
+	var h models.Handle
+	if err := snap.DataTo(&h); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal handle: %w", err)
+	}
+	u, err := c.GetUserByID(ctx, h.UserID)
+	// Both documents change in one transaction; a mismatch means the account was purged
+	if err != nil || u == nil || u.Handle != handle {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,22 @@
+//go:build ignore
+
+// gen.go writes the declared indexes to firestore.indexes.json in the backend directory. Run
+// with go generate.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/matti777/my-countries/backend/internal/database/indexes"
+)
+
+func main() {
+	out, err := indexes.JSON()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("../../../firestore.indexes.json", out, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
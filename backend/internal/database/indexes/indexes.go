@@ -0,0 +1,274 @@
+// Package indexes declares the Firestore indexes the database queries need beyond the automatic
+// single-field indexes of collection scope: composite indexes and collection group single-field
+// indexes. A query without its index fails only when it runs, so Verify checks the declared
+// indexes at startup and logs the missing ones, and JSON renders them as
+// firestore.indexes.json (go generate) for `firebase deploy --only firestore:indexes`.
+//
+// A new query filtering or ordering on several fields, or on a collection group, adds its
+// index here.
+package indexes
+
+//go:generate go run gen.go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	admin "cloud.google.com/go/firestore/apiv1/admin"
+	"cloud.google.com/go/firestore/apiv1/admin/adminpb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+	"github.com/matti777/my-countries/backend/internal/ttl"
+)
+
+// Order is the order of a field in an index.
+type Order string
+
+const (
+	Ascending  Order = "ASCENDING"
+	Descending Order = "DESCENDING"
+)
+
+// Field is a field of a composite index.
+type Field struct {
+	Path  string
+	Order Order
+}
+
+// Composite is a composite index of collection scope on the collections with ID
+// CollectionGroup.
+type Composite struct {
+	CollectionGroup string
+	Fields          []Field
+}
+
+// GroupField is an ascending single-field index of collection group scope, which Firestore
+// does not create automatically.
+type GroupField struct {
+	CollectionGroup string
+	Field           string
+
+	// TTL marks the expiry field of a ttl.Policy, so deploying the index keeps its TTL policy.
+	TTL bool
+}
+
+// Composites lists the composite indexes.
+var Composites = []Composite{
+	// Duplicate and block checks on friend requests between two users
+	{CollectionGroup: "friend_requests", Fields: []Field{
+		{Path: "FromUserID", Order: Ascending},
+		{Path: "ToUserID", Order: Ascending},
+	}},
+	// Duplicate check when recording a visit overlap
+	{CollectionGroup: "overlaps", Fields: []Field{
+		{Path: "FriendUserID", Order: Ascending},
+		{Path: "FriendVisitID", Order: Ascending},
+	}},
+}
+
+// GroupFields lists the collection group single-field indexes: lookups by organization member,
+// share link token and API key hash, and the TTL purge of every ttl.Policies entry.
+var GroupFields = append([]GroupField{
+	{CollectionGroup: "members", Field: "UserID"},
+	{CollectionGroup: "share_links", Field: "Token"},
+	{CollectionGroup: "api_keys", Field: "KeyHash"},
+}, ttlFields()...)
+
+func ttlFields() []GroupField {
+	fields := make([]GroupField, len(ttl.Policies))
+	for i, p := range ttl.Policies {
+		fields[i] = GroupField{CollectionGroup: p.CollectionGroup, Field: p.Field, TTL: true}
+	}
+	return fields
+}
+
+func (c Composite) String() string {
+	fields := make([]string, len(c.Fields))
+	for i, f := range c.Fields {
+		fields[i] = f.Path + " " + string(f.Order)
+	}
+	return fmt.Sprintf("%s(%s)", c.CollectionGroup, strings.Join(fields, ", "))
+}
+
+func (f GroupField) String() string {
+	return fmt.Sprintf("collection group %s.%s", f.CollectionGroup, f.Field)
+}
+
+// Verify checks that the Composites and GroupFields indexes of the default database of
+// projectID exist and are ready, and logs a warning for each one that is not. It returns the
+// number of such indexes; an error only when the indexes could not be listed.
+func Verify(ctx context.Context, projectID string) (int, error) {
+	ctx, span := tracing.New(ctx, "indexes.Verify")
+	defer span.End()
+
+	client, err := admin.NewFirestoreAdminClient(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create firestore admin client: %w", err)
+	}
+	defer client.Close()
+	log := logging.FromContext(ctx)
+	parent := fmt.Sprintf("projects/%s/databases/(default)/collectionGroups/", projectID)
+
+	missing := 0
+	for _, c := range Composites {
+		state, err := compositeState(ctx, client, parent+c.CollectionGroup, c)
+		if err != nil {
+			return missing, err
+		}
+		if state != adminpb.Index_READY {
+			log.Warn("Firestore composite index not ready; its queries fail until it is",
+				"index", c.String(), "state", stateName(state))
+			missing++
+		}
+	}
+	for _, f := range GroupFields {
+		state, err := groupFieldState(ctx, client, parent+f.CollectionGroup+"/fields/"+f.Field)
+		if err != nil {
+			return missing, err
+		}
+		if state != adminpb.Index_READY {
+			log.Warn("Firestore collection group index not ready; its queries fail until it is",
+				"index", f.String(), "state", stateName(state))
+			missing++
+		}
+	}
+	return missing, nil
+}
+
+func stateName(state adminpb.Index_State) string {
+	if state == adminpb.Index_STATE_UNSPECIFIED {
+		return "missing"
+	}
+	return state.String()
+}
+
+// compositeState returns the state of the index of collection group name matching c, or
+// STATE_UNSPECIFIED when there is none.
+func compositeState(
+	ctx context.Context,
+	client *admin.FirestoreAdminClient,
+	name string,
+	c Composite,
+) (adminpb.Index_State, error) {
+	it := client.ListIndexes(ctx, &adminpb.ListIndexesRequest{Parent: name})
+	for {
+		idx, err := it.Next()
+		if err == iterator.Done {
+			return adminpb.Index_STATE_UNSPECIFIED, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to list indexes of %s: %w", c.CollectionGroup, err)
+		}
+		if idx.GetQueryScope() == adminpb.Index_COLLECTION && matches(idx.GetFields(), c.Fields) {
+			return idx.GetState(), nil
+		}
+	}
+}
+
+// matches reports whether the index fields are fields, ignoring the document name Firestore
+// appends to composite indexes.
+func matches(got []*adminpb.Index_IndexField, fields []Field) bool {
+	if n := len(got); n > 0 && got[n-1].GetFieldPath() == "__name__" {
+		got = got[:n-1]
+	}
+	if len(got) != len(fields) {
+		return false
+	}
+	for i, f := range fields {
+		if got[i].GetFieldPath() != f.Path || got[i].GetOrder().String() != string(f.Order) {
+			return false
+		}
+	}
+	return true
+}
+
+// groupFieldState returns the state of the ascending collection group index of the field
+// name, or STATE_UNSPECIFIED when there is none.
+func groupFieldState(
+	ctx context.Context,
+	client *admin.FirestoreAdminClient,
+	name string,
+) (adminpb.Index_State, error) {
+	field, err := client.GetField(ctx, &adminpb.GetFieldRequest{Name: name})
+	if status.Code(err) == codes.NotFound {
+		return adminpb.Index_STATE_UNSPECIFIED, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get field %s: %w", name, err)
+	}
+	for _, idx := range field.GetIndexConfig().GetIndexes() {
+		fields := idx.GetFields()
+		if idx.GetQueryScope() == adminpb.Index_COLLECTION_GROUP && len(fields) == 1 &&
+			fields[0].GetOrder() == adminpb.Index_IndexField_ASCENDING {
+			return idx.GetState(), nil
+		}
+	}
+	return adminpb.Index_STATE_UNSPECIFIED, nil
+}
+
+// indexesFile is the format of firestore.indexes.json.
+type indexesFile struct {
+	Indexes        []fileIndex         `json:"indexes"`
+	FieldOverrides []fileFieldOverride `json:"fieldOverrides"`
+}
+
+type fileIndex struct {
+	CollectionGroup string      `json:"collectionGroup"`
+	QueryScope      string      `json:"queryScope"`
+	Fields          []fileField `json:"fields"`
+}
+
+type fileField struct {
+	FieldPath string `json:"fieldPath"`
+	Order     string `json:"order"`
+}
+
+type fileFieldOverride struct {
+	CollectionGroup string              `json:"collectionGroup"`
+	FieldPath       string              `json:"fieldPath"`
+	TTL             bool                `json:"ttl"`
+	Indexes         []fileOverrideIndex `json:"indexes"`
+}
+
+type fileOverrideIndex struct {
+	Order       string `json:"order,omitempty"`
+	ArrayConfig string `json:"arrayConfig,omitempty"`
+	QueryScope  string `json:"queryScope"`
+}
+
+// JSON returns Composites and GroupFields in the firestore.indexes.json format. A field
+// override replaces the field's automatic indexes, so those are listed too.
+func JSON() ([]byte, error) {
+	file := indexesFile{Indexes: []fileIndex{}, FieldOverrides: []fileFieldOverride{}}
+	for _, c := range Composites {
+		idx := fileIndex{CollectionGroup: c.CollectionGroup, QueryScope: "COLLECTION"}
+		for _, f := range c.Fields {
+			idx.Fields = append(idx.Fields, fileField{FieldPath: f.Path, Order: string(f.Order)})
+		}
+		file.Indexes = append(file.Indexes, idx)
+	}
+	for _, f := range GroupFields {
+		file.FieldOverrides = append(file.FieldOverrides, fileFieldOverride{
+			CollectionGroup: f.CollectionGroup,
+			FieldPath:       f.Field,
+			TTL:             f.TTL,
+			Indexes: []fileOverrideIndex{
+				{Order: string(Ascending), QueryScope: "COLLECTION"},
+				{Order: string(Descending), QueryScope: "COLLECTION"},
+				{ArrayConfig: "CONTAINS", QueryScope: "COLLECTION"},
+				{Order: string(Ascending), QueryScope: "COLLECTION_GROUP"},
+			},
+		})
+	}
+	out, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal indexes: %w", err)
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func (c *Client) insightsRef(userID string) *firestore.DocumentRef {
+	return c.Collection("users").Doc(userID).Collection("insights").Doc("latest")
+}
+
+// GetInsights loads users/{userID}/insights/latest. Returns (nil, nil) if the insights have
+// not been generated yet.
+func (c *Client) GetInsights(ctx context.Context, userID string) (*models.Insights, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	snap, err := c.insightsRef(userID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get insights: %w", err)
+	}
+	var ins models.Insights
+	if err := snap.DataTo(&ins); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal insights: %w", err)
+	}
+	return &ins, nil
+}
+
+// SaveInsights writes users/{userID}/insights/latest, replacing it.
+func (c *Client) SaveInsights(ctx context.Context, userID string, ins *models.Insights) error {
+	if userID == "" || ins == nil {
+		return fmt.Errorf("userID and insights are required")
+	}
+	if _, err := c.insightsRef(userID).Set(ctx, ins); err != nil {
+		return fmt.Errorf("failed to save insights: %w", err)
+	}
+	return nil
+}
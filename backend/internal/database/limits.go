@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var ErrVisitLimitReached = errors.New("visit limit reached")
+
+// LimitError is returned when a write would take a user past one of their models.UserLimits.
+// It wraps ErrVisitLimitReached or ErrFriendLimitReached, so errors.Is matches those, and
+// carries the limit in effect for the user, which may be an admin override.
+type LimitError struct {
+	Err    error
+	UserID string
+	Limit  int
+}
+
+// NewLimitError returns a LimitError for err of userID.
+func NewLimitError(err error, userID string, limit int) *LimitError {
+	return &LimitError{Err: err, UserID: userID, Limit: limit}
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v for user %s (limit %d)", e.Err, e.UserID, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// SetUserLimits stores limits as the admin override of the limits of userID; nil removes it.
+// Returns ErrUserNotFound if the user does not exist.
+func (c *Client) SetUserLimits(ctx context.Context, userID string, limits *models.UserLimits) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	var value any = firestore.Delete
+	if limits != nil {
+		value = limits
+	}
+	_, err := c.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
+		{Path: "Limits", Value: value},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to set user limits: %w", err)
+	}
+	return nil
+}
+
+// userLimits returns the limits in effect for the user document snap: its Limits override
+// over defaults. A missing user has the defaults.
+func userLimits(
+	snap *firestore.DocumentSnapshot,
+	defaults models.UserLimits,
+) (models.UserLimits, error) {
+	if snap == nil || !snap.Exists() {
+		return defaults, nil
+	}
+	var u models.User
+	if err := snap.DataTo(&u); err != nil {
+		return defaults, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	return u.Limits.Or(defaults), nil
+}
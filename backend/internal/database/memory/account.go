@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+)
+
+// ScheduleAccountDeletion sets DeletionScheduledAt of userID to at. Returns
+// database.ErrUserNotFound if the user does not exist.
+func (db *DB) ScheduleAccountDeletion(ctx context.Context, userID string, at time.Time) error {
+	at = at.UTC()
+	return db.setDeletionScheduledAt(userID, &at)
+}
+
+// CancelAccountDeletion clears DeletionScheduledAt of userID. Returns database.ErrUserNotFound
+// if the user does not exist.
+func (db *DB) CancelAccountDeletion(ctx context.Context, userID string) error {
+	return db.setDeletionScheduledAt(userID, nil)
+}
+
+func (db *DB) setDeletionScheduledAt(userID string, at *time.Time) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.userDoc(userID)
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	u.DeletionScheduledAt = at
+	return nil
+}
+
+// GetUsersDueForDeletion returns the IDs of up to limit users whose DeletionScheduledAt is at or
+// before now.
+func (db *DB) GetUsersDueForDeletion(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	ids := []string{}
+	for _, id := range sortedKeys(db.users) {
+		if len(ids) == limit {
+			break
+		}
+		u := db.users[id].doc
+		if u != nil && u.DeletionScheduledAt != nil && !u.DeletionScheduledAt.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// PurgeUser deletes the account of userID if its deletion is due at now, with the same scope
+// as the Firestore client: all of the user's data, the user's followers entries at their
+// friends, visit overlaps (both copies), friend requests from and to the user and organization
+// memberships. Friend entries of other users and organizations the user created are kept.
+func (db *DB) PurgeUser(ctx context.Context, userID string, now time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.purgeUser(userID, now)
+	return nil
+}
+
+func (db *DB) purgeUser(userID string, now time.Time) {
+	u, ok := db.users[userID]
+	if !ok || u.doc == nil || u.doc.DeletionScheduledAt == nil ||
+		u.doc.DeletionScheduledAt.After(now) {
+		return
+	}
+	if u.doc.Handle != "" {
+		delete(db.handles, u.doc.Handle)
+	}
+	for id, o := range u.overlaps {
+		if friend, ok := db.users[o.FriendUserID]; ok {
+			delete(friend.overlaps, id)
+		}
+	}
+	for _, f := range u.friends {
+		if friendID, friend := db.userByShareToken(f.ShareToken); friend != nil {
+			delete(db.users[friendID].followers, userID)
+		}
+	}
+	for id, r := range db.friendRequests {
+		if r.FromUserID == userID || r.ToUserID == userID {
+			delete(db.friendRequests, id)
+		}
+	}
+	for _, o := range db.orgs {
+		delete(o.members, userID)
+	}
+	delete(db.users, userID)
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// CreateAPIKey stores the API key of key.UserID under a new ID. Returns
+// database.ErrTooManyAPIKeys when the user has models.MaxAPIKeys keys.
+func (db *DB) CreateAPIKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	if key == nil || key.UserID == "" || key.KeyHash == "" {
+		return nil, fmt.Errorf("userID and key hash are required")
+	}
+	out := *key
+	out.ID = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.LastUsedAt = nil
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.user(out.UserID)
+	if len(u.apiKeys) >= models.MaxAPIKeys {
+		return nil, database.ErrTooManyAPIKeys
+	}
+	u.apiKeys[out.ID] = out
+	return &out, nil
+}
+
+// GetAPIKeys returns the API keys of userID, newest first.
+func (db *DB) GetAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	keys := []models.APIKey{}
+	if u, ok := db.users[userID]; ok {
+		for _, id := range sortedKeys(u.apiKeys) {
+			k := u.apiKeys[id]
+			k.LastUsedAt = clonePtr(k.LastUsedAt)
+			keys = append(keys, k)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// GetAPIKeyByHash returns the API key of any user whose KeyHash is keyHash, or nil (not error)
+// when there is none.
+func (db *DB) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	if keyHash == "" {
+		return nil, nil
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, u := range db.users {
+		for _, k := range u.apiKeys {
+			if k.KeyHash == keyHash {
+				k.LastUsedAt = clonePtr(k.LastUsedAt)
+				return &k, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// TouchAPIKey sets LastUsedAt of the API key keyID of userID to now. A key revoked meanwhile is
+// not an error.
+func (db *DB) TouchAPIKey(ctx context.Context, userID, keyID string, now time.Time) error {
+	if userID == "" || keyID == "" {
+		return fmt.Errorf("userID and keyID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if u, ok := db.users[userID]; ok {
+		if k, ok := u.apiKeys[keyID]; ok {
+			k.LastUsedAt = &now
+			u.apiKeys[keyID] = k
+		}
+	}
+	return nil
+}
+
+// DeleteAPIKey revokes the API key keyID of userID. Returns database.ErrAPIKeyNotFound if it
+// does not exist.
+func (db *DB) DeleteAPIKey(ctx context.Context, userID, keyID string) error {
+	if userID == "" || keyID == "" {
+		return fmt.Errorf("userID and keyID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return database.ErrAPIKeyNotFound
+	}
+	if _, ok := u.apiKeys[keyID]; !ok {
+		return database.ErrAPIKeyNotFound
+	}
+	delete(u.apiKeys, keyID)
+	return nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// RecordAuditEvent adds event to the audit log of userID, expiring models.AuditRetention after
+// event.Time.
+func (db *DB) RecordAuditEvent(
+	ctx context.Context,
+	userID string,
+	event *models.AuditEvent,
+) error {
+	if userID == "" || event == nil || event.Type == "" {
+		return fmt.Errorf("userID and event type are required")
+	}
+	out := *event
+	out.ID = newID()
+	out.ExpiresAt = out.Time.Add(models.AuditRetention)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.user(userID)
+	u.audit = append(u.audit, out)
+	return nil
+}
+
+// GetAuditEvents returns up to limit of the most recent audit events of userID, newest first.
+func (db *DB) GetAuditEvents(
+	ctx context.Context,
+	userID string,
+	limit int,
+) ([]models.AuditEvent, error) {
+	if userID == "" || limit <= 0 {
+		return nil, fmt.Errorf("userID and a positive limit are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var events []models.AuditEvent
+	if u, ok := db.users[userID]; ok {
+		events = append(events, u.audit...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.After(events[j].Time)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	if events == nil {
+		events = []models.AuditEvent{}
+	}
+	return events, nil
+}
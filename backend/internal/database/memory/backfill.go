@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetBackfillJob returns the checkpoint of the job name, or (nil, nil) if it has never been
+// started.
+func (db *DB) GetBackfillJob(ctx context.Context, name string) (*models.BackfillJob, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	job, ok := db.backfillJobs[name]
+	if !ok {
+		return nil, nil
+	}
+	job.CompletedAt = clonePtr(job.CompletedAt)
+	return &job, nil
+}
+
+// SaveBackfillJob replaces the checkpoint of job.Name.
+func (db *DB) SaveBackfillJob(ctx context.Context, job *models.BackfillJob) error {
+	if job == nil || job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	stored := *job
+	stored.CompletedAt = clonePtr(job.CompletedAt)
+	db.backfillJobs[job.Name] = stored
+	return nil
+}
+
+// ListUserIDs returns up to limit user IDs in ID order, starting after afterID.
+func (db *DB) ListUserIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var ids []string
+	for _, id := range sortedKeys(db.users) {
+		if len(ids) == limit {
+			break
+		}
+		if id > afterID && db.users[id].doc != nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// RebuildUserVisitStats recomputes VisitCount and DistinctCountries of userID. A user that
+// does not exist is skipped.
+func (db *DB) RebuildUserVisitStats(ctx context.Context, userID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.rebuildUserVisitStats(userID)
+	return nil
+}
+
+func (db *DB) rebuildUserVisitStats(userID string) {
+	u, ok := db.users[userID]
+	if !ok || u.doc == nil {
+		return
+	}
+	countries := make(map[string]struct{}, len(u.visits))
+	for _, v := range u.visits {
+		countries[v.CountryCode] = struct{}{}
+	}
+	u.doc.VisitCount = int64(len(u.visits))
+	u.doc.DistinctCountries = int64(len(countries))
+}
+
+// MigrateUserSchema does nothing: the in-memory database keeps models, not versioned documents.
+func (db *DB) MigrateUserSchema(ctx context.Context, userID string) error {
+	return nil
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// BlockUser adds blocked to the blocked users of userID and ends any relation between the two
+// users: the friend entries on both sides (ownerShareToken is the owner's ShareToken), their
+// followers entries and pending friend requests in either direction.
+func (db *DB) BlockUser(
+	ctx context.Context,
+	userID, ownerShareToken string,
+	blocked *models.BlockedUser,
+) (*models.BlockedUser, error) {
+	if userID == "" || blocked == nil || blocked.UserID == "" {
+		return nil, fmt.Errorf("userID and blocked user ID are required")
+	}
+	out := *blocked
+	out.CreatedAt = time.Now().UTC()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	owner, other := db.user(userID), db.user(blocked.UserID)
+	for id, f := range owner.friends {
+		if f.ShareToken == blocked.ShareToken {
+			delete(owner.friends, id)
+		}
+	}
+	for id, f := range other.friends {
+		if f.ShareToken == ownerShareToken {
+			delete(other.friends, id)
+		}
+	}
+	for id, r := range db.friendRequests {
+		if (r.FromUserID == userID && r.ToUserID == blocked.UserID) ||
+			(r.FromUserID == blocked.UserID && r.ToUserID == userID) {
+			delete(db.friendRequests, id)
+		}
+	}
+	delete(owner.followers, blocked.UserID)
+	delete(other.followers, userID)
+	owner.blocked[blocked.UserID] = out
+	return &out, nil
+}
+
+// GetBlockedUsers returns the users blocked by userID, most recently blocked first.
+func (db *DB) GetBlockedUsers(ctx context.Context, userID string) ([]models.BlockedUser, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	blocked := []models.BlockedUser{}
+	if u, ok := db.users[userID]; ok {
+		for _, id := range sortedKeys(u.blocked) {
+			b := u.blocked[id]
+			b.UserID = id
+			blocked = append(blocked, b)
+		}
+	}
+	sort.SliceStable(blocked, func(i, j int) bool {
+		return blocked[i].CreatedAt.After(blocked[j].CreatedAt)
+	})
+	return blocked, nil
+}
+
+// IsBlocked reports whether userID has blocked otherUserID.
+func (db *DB) IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error) {
+	if userID == "" || otherUserID == "" {
+		return false, fmt.Errorf("userID and otherUserID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.isBlocked(userID, otherUserID), nil
+}
+
+func (db *DB) isBlocked(userID, otherUserID string) bool {
+	u, ok := db.users[userID]
+	if !ok {
+		return false
+	}
+	_, blocked := u.blocked[otherUserID]
+	return blocked
+}
+
+// UnblockUser removes the block of the user blocked with shareToken. Returns
+// database.ErrBlockedUserNotFound when there is no such block.
+func (db *DB) UnblockUser(ctx context.Context, userID, shareToken string) error {
+	if userID == "" || shareToken == "" {
+		return fmt.Errorf("userID and shareToken are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if u, ok := db.users[userID]; ok {
+		for id, b := range u.blocked {
+			if b.ShareToken == shareToken {
+				delete(u.blocked, id)
+				return nil
+			}
+		}
+	}
+	return database.ErrBlockedUserNotFound
+}
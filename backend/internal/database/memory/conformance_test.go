@@ -0,0 +1,12 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/database/dbtest"
+	"github.com/matti777/my-countries/backend/internal/database/memory"
+)
+
+func TestConformance(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T) dbtest.DB { return memory.New() })
+}
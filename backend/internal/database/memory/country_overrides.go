@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetCountryOverrides returns every country override, ordered by country code.
+func (db *DB) GetCountryOverrides(ctx context.Context) ([]models.CountryOverride, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var overrides []models.CountryOverride
+	for _, code := range sortedKeys(db.overrides) {
+		overrides = append(overrides, db.overrides[code])
+	}
+	return overrides, nil
+}
+
+// SaveCountryOverride stores o, replacing any earlier override of o.CountryCode.
+func (db *DB) SaveCountryOverride(ctx context.Context, o *models.CountryOverride) error {
+	if o == nil || o.CountryCode == "" {
+		return fmt.Errorf("country code is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.overrides[o.CountryCode] = *o
+	return nil
+}
+
+// DeleteCountryOverride deletes the override of code. Returns
+// database.ErrCountryOverrideNotFound if there is none.
+func (db *DB) DeleteCountryOverride(ctx context.Context, code string) error {
+	if code == "" {
+		return fmt.Errorf("country code is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.overrides[code]; !ok {
+		return database.ErrCountryOverrideNotFound
+	}
+	delete(db.overrides, code)
+	return nil
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetFeedVisits returns up to limit non-private visits of userIDs in feed order (VisitedTime
+// descending, then visit ID descending), starting after the cursor when it is non-nil.
+func (db *DB) GetFeedVisits(
+	ctx context.Context,
+	userIDs []string,
+	after *models.FeedCursor,
+	limit int,
+) ([]models.CountryVisit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var visits []models.CountryVisit
+	for _, userID := range userIDs {
+		for _, v := range db.countryVisits(userID, false) {
+			if after == nil || after.Precedes(v) {
+				visits = append(visits, v)
+			}
+		}
+	}
+	sort.Slice(visits, func(i, j int) bool {
+		if !visits[i].VisitedTime.Equal(visits[j].VisitedTime) {
+			return visits[i].VisitedTime.After(visits[j].VisitedTime)
+		}
+		return visits[i].ID > visits[j].ID
+	})
+	if len(visits) > limit {
+		visits = visits[:limit]
+	}
+	return visits, nil
+}
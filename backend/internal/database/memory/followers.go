@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetFollowers returns the users who have userID as a friend, most recent first, leaving out
+// followers whose account no longer exists or who set Settings.HideFromFollowers. IsFriend is
+// not set.
+func (db *DB) GetFollowers(ctx context.Context, userID string) ([]models.Follower, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok || len(u.followers) == 0 {
+		return nil, nil
+	}
+	followers := make([]models.Follower, 0, len(u.followers))
+	for _, id := range sortedKeys(u.followers) {
+		doc := db.userDoc(id)
+		if doc == nil || (doc.Settings != nil && doc.Settings.HideFromFollowers) {
+			continue
+		}
+		f := u.followers[id]
+		f.UserID = id
+		f.ShareToken = doc.ShareToken
+		f.Name = doc.Name
+		f.ImageURL = doc.ImageURL
+		followers = append(followers, f)
+	}
+	sort.SliceStable(followers, func(i, j int) bool {
+		return followers[i].CreatedAt.After(followers[j].CreatedAt)
+	})
+	return followers, nil
+}
+
+// RebuildFollowers adds userID to the followers of every user in userID's friends. Existing
+// entries are kept.
+func (db *DB) RebuildFollowers(ctx context.Context, userID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	now := time.Now().UTC()
+	for _, f := range db.friendsOf(userID) {
+		friendID, friend := db.userByShareToken(f.ShareToken)
+		if friend == nil {
+			continue
+		}
+		followers := db.users[friendID].followers
+		if _, ok := followers[userID]; !ok {
+			followers[userID] = models.Follower{CreatedAt: now}
+		}
+	}
+	return nil
+}
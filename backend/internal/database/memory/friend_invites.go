@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// CreateFriendInvite stores an invite of userID to invite.Email, expiring after
+// models.FriendInviteTTL. Returns database.ErrFriendInviteExists when an unexpired invite to
+// the email exists and database.ErrTooManyFriendInvites when the user has
+// models.MaxPendingFriendInvites.
+func (db *DB) CreateFriendInvite(
+	ctx context.Context,
+	userID string,
+	invite *models.FriendInvite,
+) (*models.FriendInvite, error) {
+	if userID == "" || invite == nil || invite.Email == "" {
+		return nil, fmt.Errorf("userID and email are required")
+	}
+	out := *invite
+	out.ID = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.ExpiresAt = out.CreatedAt.Add(models.FriendInviteTTL)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	pending := db.friendInvites(userID, out.CreatedAt)
+	if len(pending) >= models.MaxPendingFriendInvites {
+		return nil, database.ErrTooManyFriendInvites
+	}
+	for _, inv := range pending {
+		if inv.Email == out.Email {
+			return nil, database.ErrFriendInviteExists
+		}
+	}
+	db.user(userID).invites[out.ID] = out
+	return &out, nil
+}
+
+// GetFriendInvites returns the unexpired invites of userID, oldest first.
+func (db *DB) GetFriendInvites(
+	ctx context.Context,
+	userID string,
+) ([]models.FriendInvite, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.friendInvites(userID, time.Now()), nil
+}
+
+// FindFriendInvite returns the unexpired invite of userID to email (normalized), or nil.
+func (db *DB) FindFriendInvite(
+	ctx context.Context,
+	userID, email string,
+) (*models.FriendInvite, error) {
+	if userID == "" || email == "" {
+		return nil, fmt.Errorf("userID and email are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, inv := range db.friendInvites(userID, time.Now()) {
+		if inv.Email == email {
+			return &inv, nil
+		}
+	}
+	return nil, nil
+}
+
+// DeleteFriendInvite deletes the invite inviteID of userID. Returns
+// database.ErrFriendInviteNotFound if it does not exist.
+func (db *DB) DeleteFriendInvite(ctx context.Context, userID, inviteID string) error {
+	if userID == "" || inviteID == "" {
+		return fmt.Errorf("userID and inviteID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return database.ErrFriendInviteNotFound
+	}
+	if _, ok := u.invites[inviteID]; !ok {
+		return database.ErrFriendInviteNotFound
+	}
+	delete(u.invites, inviteID)
+	return nil
+}
+
+// friendInvites returns the invites of userID unexpired at now, oldest first.
+func (db *DB) friendInvites(userID string, now time.Time) []models.FriendInvite {
+	invites := []models.FriendInvite{}
+	if u, ok := db.users[userID]; ok {
+		for _, id := range sortedKeys(u.invites) {
+			if inv := u.invites[id]; now.Before(inv.ExpiresAt) {
+				invites = append(invites, inv)
+			}
+		}
+	}
+	sort.SliceStable(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.Before(invites[j].CreatedAt)
+	})
+	return invites
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// CreateFriendRequest stores a friend request from req.FromUserID to req.ToUserID. Returns
+// database.ErrFriendRequestExists when a request between the two users exists in either
+// direction.
+func (db *DB) CreateFriendRequest(
+	ctx context.Context,
+	req *models.FriendRequest,
+) (*models.FriendRequest, error) {
+	if req == nil || req.FromUserID == "" || req.ToUserID == "" {
+		return nil, fmt.Errorf("fromUserID and toUserID are required")
+	}
+	out := *req
+	out.ID = newID()
+	out.CreatedAt = time.Now().UTC()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, r := range db.friendRequests {
+		if (r.FromUserID == req.FromUserID && r.ToUserID == req.ToUserID) ||
+			(r.FromUserID == req.ToUserID && r.ToUserID == req.FromUserID) {
+			return nil, database.ErrFriendRequestExists
+		}
+	}
+	db.friendRequests[out.ID] = out
+	return &out, nil
+}
+
+// GetFriendRequests returns the friend requests sent to and by userID, oldest first.
+func (db *DB) GetFriendRequests(
+	ctx context.Context,
+	userID string,
+) (incoming, outgoing []models.FriendRequest, err error) {
+	if userID == "" {
+		return nil, nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	incoming, outgoing = []models.FriendRequest{}, []models.FriendRequest{}
+	for _, id := range sortedKeys(db.friendRequests) {
+		switch r := db.friendRequests[id]; userID {
+		case r.ToUserID:
+			incoming = append(incoming, r)
+		case r.FromUserID:
+			outgoing = append(outgoing, r)
+		}
+	}
+	for _, requests := range [][]models.FriendRequest{incoming, outgoing} {
+		sort.SliceStable(requests, func(i, j int) bool {
+			return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+		})
+	}
+	return incoming, outgoing, nil
+}
+
+// GetFriendRequest returns the friend request with requestID if userID sent or received it;
+// otherwise database.ErrFriendRequestNotFound.
+func (db *DB) GetFriendRequest(
+	ctx context.Context,
+	requestID, userID string,
+) (*models.FriendRequest, error) {
+	if requestID == "" || userID == "" {
+		return nil, fmt.Errorf("requestID and userID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	r, ok := db.friendRequests[requestID]
+	if !ok || (r.FromUserID != userID && r.ToUserID != userID) {
+		return nil, database.ErrFriendRequestNotFound
+	}
+	return &r, nil
+}
+
+// AcceptFriendRequest accepts a friend request received by userID: each user gets the other as
+// a Friend (unless already present) and as a follower, and the request is deleted. Returns the
+// requester as the recipient's Friend, database.ErrFriendRequestNotFound unless userID received
+// it, or a database.LimitError when a user gaining a friend has maxFriends or their override's
+// MaxFriends.
+func (db *DB) AcceptFriendRequest(
+	ctx context.Context,
+	requestID, userID string,
+	maxFriends int,
+) (models.Friend, error) {
+	if requestID == "" || userID == "" {
+		return models.Friend{}, fmt.Errorf("requestID and userID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	r, ok := db.friendRequests[requestID]
+	if !ok || r.ToUserID != userID {
+		return models.Friend{}, database.ErrFriendRequestNotFound
+	}
+	toExisting := db.friendByShareToken(r.ToUserID, r.FromShareToken)
+	fromExisting := db.friendByShareToken(r.FromUserID, r.ToShareToken)
+	for _, check := range []struct {
+		userID   string
+		existing *models.Friend
+	}{{r.ToUserID, toExisting}, {r.FromUserID, fromExisting}} {
+		if check.existing != nil {
+			continue
+		}
+		limits := models.UserLimits{MaxFriends: maxFriends}
+		if u := db.userDoc(check.userID); u != nil {
+			limits = u.Limits.Or(limits)
+		}
+		if limits.MaxFriends > 0 && len(db.friendsOf(check.userID)) >= limits.MaxFriends {
+			return models.Friend{}, database.NewLimitError(database.ErrFriendLimitReached,
+				check.userID, limits.MaxFriends)
+		}
+	}
+
+	friend := models.Friend{
+		ShareToken: r.FromShareToken,
+		Name:       r.FromName,
+		ImageURL:   r.FromImageURL,
+	}
+	now := time.Now().UTC()
+	if toExisting == nil {
+		friend.ID = newID()
+		db.user(r.ToUserID).friends[friend.ID] = friend
+		db.user(r.FromUserID).followers[r.ToUserID] = models.Follower{CreatedAt: now}
+	} else {
+		friend.ID = toExisting.ID
+	}
+	if fromExisting == nil {
+		back := models.Friend{
+			ID:         newID(),
+			ShareToken: r.ToShareToken,
+			Name:       r.ToName,
+			ImageURL:   r.ToImageURL,
+		}
+		db.user(r.FromUserID).friends[back.ID] = back
+		db.user(r.ToUserID).followers[r.FromUserID] = models.Follower{CreatedAt: now}
+	}
+	delete(db.friendRequests, requestID)
+	return friend, nil
+}
+
+// DeleteFriendRequest deletes a friend request sent or received by userID. Returns
+// database.ErrFriendRequestNotFound otherwise.
+func (db *DB) DeleteFriendRequest(ctx context.Context, requestID, userID string) error {
+	if _, err := db.GetFriendRequest(ctx, requestID, userID); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	delete(db.friendRequests, requestID)
+	return nil
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+)
+
+// MergeGuestUser merges the guest account guestUserID into userID with MergeUsers, returning the
+// number of visits moved. Returns database.ErrUserNotFound when either user is missing and
+// database.ErrNotGuest unless guestUserID is a guest account.
+func (db *DB) MergeGuestUser(
+	ctx context.Context,
+	guestUserID, userID string,
+	now time.Time,
+) (int, error) {
+	if guestUserID == "" || userID == "" {
+		return 0, fmt.Errorf("guestUserID and userID are required")
+	}
+	guest, err := db.GetUserByID(ctx, guestUserID)
+	if err != nil {
+		return 0, err
+	}
+	if guest == nil {
+		return 0, database.ErrUserNotFound
+	}
+	if !guest.IsAnonymous {
+		return 0, database.ErrNotGuest
+	}
+	res, err := db.MergeUsers(ctx, guestUserID, userID, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.Visits, nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// SetHandle gives userID the (normalized, valid) handle, releasing the user's previous handle.
+// Returns database.ErrHandleTaken when another user has it and database.ErrUserNotFound
+// without a User document.
+func (db *DB) SetHandle(ctx context.Context, userID, handle string) error {
+	if userID == "" || handle == "" {
+		return fmt.Errorf("userID and handle are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.userDoc(userID)
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	h, taken := db.handles[handle]
+	if taken && h.UserID != userID {
+		return database.ErrHandleTaken
+	}
+	if u.Handle == handle && taken {
+		return nil
+	}
+	if u.Handle != "" && u.Handle != handle {
+		delete(db.handles, u.Handle)
+	}
+	db.handles[handle] = models.Handle{UserID: userID, CreatedAt: time.Now().UTC()}
+	u.Handle = handle
+	return nil
+}
+
+// DeleteHandle releases the handle of userID. Returns database.ErrHandleNotFound when the user
+// has none and database.ErrUserNotFound without a User document.
+func (db *DB) DeleteHandle(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.userDoc(userID)
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	if u.Handle == "" {
+		return database.ErrHandleNotFound
+	}
+	delete(db.handles, u.Handle)
+	u.Handle = ""
+	return nil
+}
+
+// GetUserByHandle returns the owner of the normalized handle, or nil (not error) when nobody
+// has it.
+func (db *DB) GetUserByHandle(ctx context.Context, handle string) (*models.User, error) {
+	if handle == "" {
+		return nil, fmt.Errorf("handle is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	h, ok := db.handles[handle]
+	if !ok {
+		return nil, nil
+	}
+	u := db.userDoc(h.UserID)
+	if u == nil || u.Handle != handle {
+		return nil, nil
+	}
+	return readUser(h.UserID, u), nil
+}
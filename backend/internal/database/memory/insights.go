@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetInsights returns the insights of userID, or (nil, nil) if they have not been generated yet.
+func (db *DB) GetInsights(ctx context.Context, userID string) (*models.Insights, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok || u.insights == nil {
+		return nil, nil
+	}
+	return cloneInsights(u.insights), nil
+}
+
+// SaveInsights replaces the insights of userID.
+func (db *DB) SaveInsights(ctx context.Context, userID string, ins *models.Insights) error {
+	if userID == "" || ins == nil {
+		return fmt.Errorf("userID and insights are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.user(userID).insights = cloneInsights(ins)
+	return nil
+}
+
+func cloneInsights(ins *models.Insights) *models.Insights {
+	out := *ins
+	out.FrequencyChangePercent = clonePtr(ins.FrequencyChangePercent)
+	out.NewRegionCodes = slices.Clone(ins.NewRegionCodes)
+	out.LongestGap = clonePtr(ins.LongestGap)
+	return &out
+}
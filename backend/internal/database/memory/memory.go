@@ -0,0 +1,228 @@
+// Package memory is an in-memory implementation of the database used by the server and the
+// background workers, with the semantics of the Firestore client in internal/database. It needs
+// no GCP project, so unit tests and the --demo mode of cmd/backend run on it. Data lives only
+// as long as the process.
+//
+// Every method holds a single mutex, which makes each call atomic like a Firestore
+// transaction. Values are copied in and out, so callers never share memory with the store.
+// Errors are the sentinels of internal/database.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// DB stores every collection in maps. The zero value is not usable; use New.
+type DB struct {
+	mu sync.Mutex
+
+	users          map[string]*userData
+	handles        map[string]models.Handle
+	friendRequests map[string]models.FriendRequest
+	orgs           map[string]*orgData
+	orgInvitations map[string]models.OrganizationInvitation
+	orgShareLinks  map[string]models.OrgShareLink
+	backfillJobs   map[string]models.BackfillJob
+	overrides      map[string]models.CountryOverride
+}
+
+// userData is the User document of one user with its subcollections. As in Firestore, the
+// subcollections may exist while doc is nil.
+type userData struct {
+	doc *models.User
+
+	visits     map[string]models.CountryVisit
+	history    map[string][]models.VisitHistoryEvent // by visit ID, oldest first
+	friends    map[string]models.Friend              // by document ID
+	followers  map[string]models.Follower            // by follower user ID
+	blocked    map[string]models.BlockedUser         // by blocked user ID
+	invites    map[string]models.FriendInvite
+	insights   *models.Insights
+	shareLinks map[string]models.ShareLink // by token
+	shareStats map[string]models.ShareStats
+	apiKeys    map[string]models.APIKey
+	audit      []models.AuditEvent
+	overlaps   map[string]models.VisitOverlap
+}
+
+// orgData is an organization with its members (by user ID) and goals.
+type orgData struct {
+	org     models.Organization
+	members map[string]models.OrganizationMember
+	goals   map[string]models.OrganizationGoal
+}
+
+// New returns an empty DB.
+func New() *DB {
+	return &DB{
+		users:          make(map[string]*userData),
+		handles:        make(map[string]models.Handle),
+		friendRequests: make(map[string]models.FriendRequest),
+		orgs:           make(map[string]*orgData),
+		orgInvitations: make(map[string]models.OrganizationInvitation),
+		orgShareLinks:  make(map[string]models.OrgShareLink),
+		backfillJobs:   make(map[string]models.BackfillJob),
+		overrides:      make(map[string]models.CountryOverride),
+	}
+}
+
+// newID returns a new random document ID.
+func newID() string {
+	return uuid.New().String()
+}
+
+// Ping always succeeds: the database is in memory.
+func (db *DB) Ping(ctx context.Context) error {
+	return nil
+}
+
+// user returns the data of userID, creating it for a write.
+func (db *DB) user(userID string) *userData {
+	u, ok := db.users[userID]
+	if !ok {
+		u = &userData{
+			visits:     make(map[string]models.CountryVisit),
+			history:    make(map[string][]models.VisitHistoryEvent),
+			friends:    make(map[string]models.Friend),
+			followers:  make(map[string]models.Follower),
+			blocked:    make(map[string]models.BlockedUser),
+			invites:    make(map[string]models.FriendInvite),
+			shareLinks: make(map[string]models.ShareLink),
+			shareStats: make(map[string]models.ShareStats),
+			apiKeys:    make(map[string]models.APIKey),
+			overlaps:   make(map[string]models.VisitOverlap),
+		}
+		db.users[userID] = u
+	}
+	return u
+}
+
+// userDoc returns the User document of userID, or nil when it does not exist.
+func (db *DB) userDoc(userID string) *models.User {
+	if u, ok := db.users[userID]; ok {
+		return u.doc
+	}
+	return nil
+}
+
+// userByShareToken returns the ID and User document of the user with shareToken, or nil.
+func (db *DB) userByShareToken(shareToken string) (string, *models.User) {
+	for id, u := range db.users {
+		if u.doc != nil && u.doc.ShareToken == shareToken {
+			return id, u.doc
+		}
+	}
+	return "", nil
+}
+
+// bumpVisitsRevision increments VisitsRevision of each user once; users without a User
+// document are skipped.
+func (db *DB) bumpVisitsRevision(userIDs ...string) {
+	seen := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		if doc := db.userDoc(userID); doc != nil {
+			doc.VisitsRevision++
+		}
+	}
+}
+
+// readUser returns a copy of the stored u as GetUserByID returns it.
+func readUser(userID string, u *models.User) *models.User {
+	out := *u
+	out.ID = userID
+	out.UserID = userID
+	if u.Settings == nil {
+		s := models.DefaultUserSettings()
+		out.Settings = &s
+	} else {
+		out.Settings = cloneSettings(*u.Settings)
+	}
+	out.TokensRevokedAt = clonePtr(u.TokensRevokedAt)
+	out.DeletionScheduledAt = clonePtr(u.DeletionScheduledAt)
+	return &out
+}
+
+func cloneSettings(s models.UserSettings) *models.UserSettings {
+	s.VisitDefaults = clonePtr(s.VisitDefaults)
+	return &s
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// storedVisit is the stored form of visit, with the fields countryVisitDoc of the Firestore
+// client writes.
+func storedVisit(visit *models.CountryVisit) models.CountryVisit {
+	out := models.CountryVisit{
+		CountryCode:     visit.CountryCode,
+		VisitedTime:     visit.VisitedTime,
+		Tags:            visit.Tags,
+		Notes:           visit.Notes,
+		IsPrivate:       visit.IsPrivate,
+		VisitType:       visit.VisitType,
+		SubdivisionCode: visit.SubdivisionCode,
+		DestinationCode: visit.DestinationCode,
+		Location:        visit.Location,
+		Companions:      visit.Companions,
+		Proofs:          visit.Proofs,
+		UpdatedAt:       visit.UpdatedAt,
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if visit.MediaURL != nil && *visit.MediaURL != "" {
+		out.MediaURL = visit.MediaURL
+	}
+	if len(out.Companions) == 0 {
+		out.Companions = nil
+	}
+	if len(out.Proofs) == 0 {
+		out.Proofs = nil
+	}
+	return cloneVisit(out)
+}
+
+func cloneVisit(v models.CountryVisit) models.CountryVisit {
+	v.MediaURL = clonePtr(v.MediaURL)
+	v.Tags = slices.Clone(v.Tags)
+	v.Location = clonePtr(v.Location)
+	v.Companions = slices.Clone(v.Companions)
+	v.Proofs = slices.Clone(v.Proofs)
+	v.UpdatedAt = clonePtr(v.UpdatedAt)
+	return v
+}
+
+// readVisit returns a copy of the stored visit as the Firestore client reads it.
+func readVisit(v models.CountryVisit, visitID, userID string) models.CountryVisit {
+	out := cloneVisit(v)
+	out.ID = visitID
+	out.UserID = userID
+	out.Verified = len(out.Proofs) > 0
+	return out
+}
+
+// sortedKeys returns the keys of m in ascending order, the order Firestore lists documents in.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// MergeUsers merges the account fromUserID into toUserID and then purges fromUserID, like the
+// Firestore client: visits are moved under their IDs, friends toUserID does not have are added,
+// followers of fromUserID get toUserID as their friend instead (or lose the entry when they have
+// both, are toUserID or are blocked by it), blocked users, share links and API keys are copied
+// and the handle is taken over when toUserID has none. Returns database.ErrUserNotFound when
+// either user is missing.
+func (db *DB) MergeUsers(
+	ctx context.Context,
+	fromUserID, toUserID string,
+	now time.Time,
+) (*models.AccountMergeResult, error) {
+	if fromUserID == "" || toUserID == "" || fromUserID == toUserID {
+		return nil, fmt.Errorf("two different user IDs are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	from, to := db.users[fromUserID], db.users[toUserID]
+	if from == nil || from.doc == nil || to == nil || to.doc == nil {
+		return nil, database.ErrUserNotFound
+	}
+
+	res := &models.AccountMergeResult{}
+	for id, v := range from.visits {
+		to.visits[id] = v
+		db.addHistoryEvent(to, id, models.VisitEventCreated, toUserID, nil, &v)
+		delete(from.visits, id)
+		res.Visits++
+	}
+	db.bumpVisitsRevision(fromUserID, toUserID)
+	db.rebuildUserVisitStats(toUserID)
+
+	res.Friends = db.mergeFriends(from, to, toUserID, now)
+	res.Followers = db.mergeFollowers(from, to, toUserID, now)
+	for id, b := range from.blocked {
+		if id != toUserID {
+			to.blocked[id] = b
+			res.BlockedUsers++
+		}
+	}
+	for token, l := range from.shareLinks {
+		l.UserID = toUserID
+		to.shareLinks[token] = l
+		res.ShareLinks++
+	}
+	for id, k := range from.apiKeys {
+		k.UserID = toUserID
+		k.LastUsedAt = clonePtr(k.LastUsedAt)
+		to.apiKeys[id] = k
+		res.APIKeys++
+	}
+	if handle := from.doc.Handle; to.doc.Handle == "" && handle != "" {
+		if h, ok := db.handles[handle]; ok && h.UserID == fromUserID {
+			h.UserID = toUserID
+			db.handles[handle] = h
+			to.doc.Handle = handle
+			from.doc.Handle = ""
+		}
+		res.Handle = handle
+	}
+
+	at := now.UTC()
+	from.doc.DeletionScheduledAt = &at
+	db.purgeUser(fromUserID, now)
+	return res, nil
+}
+
+// mergeFriends adds the friends of from that to does not have (by ShareToken, leaving out to
+// itself) to to's friends, with to's entries in their followers, and returns how many were
+// added.
+func (db *DB) mergeFriends(from, to *userData, toUserID string, now time.Time) int {
+	has := map[string]bool{to.doc.ShareToken: true}
+	for _, f := range to.friends {
+		has[f.ShareToken] = true
+	}
+	added := 0
+	for _, id := range sortedKeys(from.friends) {
+		f := from.friends[id]
+		if has[f.ShareToken] {
+			continue
+		}
+		has[f.ShareToken] = true
+		f.ID = newID()
+		f.SyncedAt = time.Time{}
+		to.friends[f.ID] = f
+		if friendID, u := db.userByShareToken(f.ShareToken); u != nil && friendID != toUserID {
+			db.users[friendID].followers[toUserID] = models.Follower{CreatedAt: now}
+		}
+		added++
+	}
+	return added
+}
+
+// mergeFollowers points the friend entries of users who have from as a friend to to and
+// returns how many were pointed to to. Entries are deleted instead when the follower has to as
+// a friend already, is to itself or is blocked by to.
+func (db *DB) mergeFollowers(from, to *userData, toUserID string, now time.Time) int {
+	moved := 0
+	for _, followerID := range sortedKeys(from.followers) {
+		follower, ok := db.users[followerID]
+		if !ok {
+			continue
+		}
+		var fromEntries []string
+		hasTo := false
+		for _, id := range sortedKeys(follower.friends) {
+			switch follower.friends[id].ShareToken {
+			case from.doc.ShareToken:
+				fromEntries = append(fromEntries, id)
+			case to.doc.ShareToken:
+				hasTo = true
+			}
+		}
+		_, blocked := to.blocked[followerID]
+		keep := len(fromEntries) > 0 && !hasTo && followerID != toUserID && !blocked
+		for i, id := range fromEntries {
+			if keep && i == 0 {
+				continue
+			}
+			delete(follower.friends, id)
+		}
+		if !keep {
+			continue
+		}
+		f := follower.friends[fromEntries[0]]
+		f.ShareToken = to.doc.ShareToken
+		f.Name = to.doc.Name
+		f.ImageURL = to.doc.ImageURL
+		follower.friends[f.ID] = f
+		to.followers[followerID] = models.Follower{CreatedAt: now}
+		moved++
+	}
+	return moved
+}
@@ -0,0 +1,497 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// CreateOrganization stores org under a new ID with owner as its first member and a fresh
+// ShareToken.
+func (db *DB) CreateOrganization(
+	ctx context.Context,
+	org *models.Organization,
+	owner models.OrganizationMember,
+) (*models.Organization, error) {
+	if org == nil || org.Name == "" || owner.UserID == "" {
+		return nil, fmt.Errorf("organization name and owner are required")
+	}
+	out := *org
+	out.ID = newID()
+	out.ShareToken = newID()
+	out.CreatedBy = owner.UserID
+	out.MemberCount = 1
+	owner.Role = models.OrgRoleOwner
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.orgs[out.ID] = &orgData{
+		org:     out,
+		members: map[string]models.OrganizationMember{owner.UserID: owner},
+		goals:   make(map[string]models.OrganizationGoal),
+	}
+	return &out, nil
+}
+
+// GetOrganization returns the organization orgID, or database.ErrOrganizationNotFound.
+func (db *DB) GetOrganization(ctx context.Context, orgID string) (*models.Organization, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	o, ok := db.orgs[orgID]
+	if !ok {
+		return nil, database.ErrOrganizationNotFound
+	}
+	out := o.org
+	return &out, nil
+}
+
+// GetOrganizationByShareToken returns the organization with shareToken, or (nil, nil).
+func (db *DB) GetOrganizationByShareToken(
+	ctx context.Context,
+	shareToken string,
+) (*models.Organization, error) {
+	if shareToken == "" {
+		return nil, fmt.Errorf("shareToken is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, o := range db.orgs {
+		if o.org.ShareToken == shareToken {
+			out := o.org
+			return &out, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateOrganizationProfile sets Name and Description of orgID. Returns
+// database.ErrOrganizationNotFound if it does not exist.
+func (db *DB) UpdateOrganizationProfile(
+	ctx context.Context,
+	orgID, name, description string,
+) error {
+	if orgID == "" || name == "" {
+		return fmt.Errorf("orgID and name are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	o, ok := db.orgs[orgID]
+	if !ok {
+		return database.ErrOrganizationNotFound
+	}
+	o.org.Name = name
+	o.org.Description = description
+	return nil
+}
+
+// DeleteOrganization deletes the organization with its members, invitations, goals and share
+// links. Returns database.ErrOrganizationNotFound if it does not exist.
+func (db *DB) DeleteOrganization(ctx context.Context, orgID string) error {
+	if orgID == "" {
+		return fmt.Errorf("orgID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.orgs[orgID]; !ok {
+		return database.ErrOrganizationNotFound
+	}
+	for code, inv := range db.orgInvitations {
+		if inv.OrganizationID == orgID {
+			delete(db.orgInvitations, code)
+		}
+	}
+	for token, l := range db.orgShareLinks {
+		if l.OrganizationID == orgID {
+			delete(db.orgShareLinks, token)
+		}
+	}
+	delete(db.orgs, orgID)
+	return nil
+}
+
+// GetOrganizationMembers lists the members of an organization, ordered by JoinedAt.
+func (db *DB) GetOrganizationMembers(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationMember, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	o, ok := db.orgs[orgID]
+	if !ok {
+		return nil, nil
+	}
+	var members []models.OrganizationMember
+	for _, id := range sortedKeys(o.members) {
+		members = append(members, o.members[id])
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+	return members, nil
+}
+
+// GetOrganizationMember returns the membership of userID, or database.ErrOrgMemberNotFound when
+// the user is not a member (or the organization does not exist).
+func (db *DB) GetOrganizationMember(
+	ctx context.Context,
+	orgID, userID string,
+) (*models.OrganizationMember, error) {
+	if orgID == "" || userID == "" {
+		return nil, fmt.Errorf("orgID and userID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	o, ok := db.orgs[orgID]
+	if !ok {
+		return nil, database.ErrOrgMemberNotFound
+	}
+	m, ok := o.members[userID]
+	if !ok {
+		return nil, database.ErrOrgMemberNotFound
+	}
+	return &m, nil
+}
+
+// GetOrganizationsByUser returns the organizations userID is a member of, with the user's role.
+func (db *DB) GetOrganizationsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.OrganizationMembership, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var memberships []models.OrganizationMembership
+	for _, id := range sortedKeys(db.orgs) {
+		o := db.orgs[id]
+		if m, ok := o.members[userID]; ok {
+			memberships = append(memberships, models.OrganizationMembership{
+				Organization: o.org,
+				Role:         m.Role,
+			})
+		}
+	}
+	return memberships, nil
+}
+
+// UpdateOrganizationMemberRole sets the Role of a membership. Returns
+// database.ErrOrgMemberNotFound if the user is not a member.
+func (db *DB) UpdateOrganizationMemberRole(
+	ctx context.Context,
+	orgID, userID, role string,
+) error {
+	if orgID == "" || userID == "" || role == "" {
+		return fmt.Errorf("orgID, userID and role are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	o, ok := db.orgs[orgID]
+	if !ok {
+		return database.ErrOrgMemberNotFound
+	}
+	m, ok := o.members[userID]
+	if !ok {
+		return database.ErrOrgMemberNotFound
+	}
+	m.Role = role
+	o.members[userID] = m
+	return nil
+}
+
+// RemoveOrganizationMember deletes a membership and decrements MemberCount. Returns
+// database.ErrOrgMemberNotFound if the user is not a member.
+func (db *DB) RemoveOrganizationMember(ctx context.Context, orgID, userID string) error {
+	if orgID == "" || userID == "" {
+		return fmt.Errorf("orgID and userID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	o, ok := db.orgs[orgID]
+	if !ok {
+		return database.ErrOrgMemberNotFound
+	}
+	if _, ok := o.members[userID]; !ok {
+		return database.ErrOrgMemberNotFound
+	}
+	delete(o.members, userID)
+	o.org.MemberCount--
+	return nil
+}
+
+// CreateOrganizationInvitation stores a new invitation under a random code, expiring after
+// models.OrgInvitationTTL. Returns database.ErrTooManyOrgInvitations when the organization
+// already has models.MaxOrgPendingInvitations invitations.
+func (db *DB) CreateOrganizationInvitation(
+	ctx context.Context,
+	inv *models.OrganizationInvitation,
+) (*models.OrganizationInvitation, error) {
+	if inv == nil || inv.OrganizationID == "" || inv.Role == "" {
+		return nil, fmt.Errorf("organizationID and role are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.orgInvitationsOf(inv.OrganizationID)) >= models.MaxOrgPendingInvitations {
+		return nil, database.ErrTooManyOrgInvitations
+	}
+	out := *inv
+	out.Code = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.ExpiresAt = out.CreatedAt.Add(models.OrgInvitationTTL)
+	db.orgInvitations[out.Code] = out
+	return &out, nil
+}
+
+// GetOrganizationInvitations lists the invitations of an organization, expired ones included.
+func (db *DB) GetOrganizationInvitations(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationInvitation, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.orgInvitationsOf(orgID), nil
+}
+
+func (db *DB) orgInvitationsOf(orgID string) []models.OrganizationInvitation {
+	invitations := []models.OrganizationInvitation{}
+	for _, code := range sortedKeys(db.orgInvitations) {
+		if inv := db.orgInvitations[code]; inv.OrganizationID == orgID {
+			invitations = append(invitations, inv)
+		}
+	}
+	return invitations
+}
+
+// GetOrganizationInvitation returns the invitation code. Returns
+// database.ErrOrgInvitationNotFound if it is missing or expired.
+func (db *DB) GetOrganizationInvitation(
+	ctx context.Context,
+	code string,
+) (*models.OrganizationInvitation, error) {
+	if code == "" {
+		return nil, fmt.Errorf("code is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.orgInvitation(code)
+}
+
+// orgInvitation returns the invitation code, treating expired ones as missing.
+func (db *DB) orgInvitation(code string) (*models.OrganizationInvitation, error) {
+	inv, ok := db.orgInvitations[code]
+	if !ok || !time.Now().Before(inv.ExpiresAt) {
+		return nil, database.ErrOrgInvitationNotFound
+	}
+	return &inv, nil
+}
+
+// AcceptOrganizationInvitation adds member with the invitation's role, increments MemberCount
+// and deletes the single-use invitation. Returns the joined organization, or
+// database.ErrOrgInvitationNotFound (missing or expired), database.ErrOrganizationNotFound,
+// database.ErrOrgMemberAlreadyExists or database.ErrOrganizationFull.
+func (db *DB) AcceptOrganizationInvitation(
+	ctx context.Context,
+	code string,
+	member models.OrganizationMember,
+) (*models.Organization, error) {
+	if code == "" || member.UserID == "" {
+		return nil, fmt.Errorf("code and member user ID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	inv, err := db.orgInvitation(code)
+	if err != nil {
+		return nil, err
+	}
+	o, ok := db.orgs[inv.OrganizationID]
+	if !ok {
+		return nil, database.ErrOrganizationNotFound
+	}
+	if _, ok := o.members[member.UserID]; ok {
+		return nil, database.ErrOrgMemberAlreadyExists
+	}
+	if o.org.MemberCount >= models.MaxOrgMembers {
+		return nil, database.ErrOrganizationFull
+	}
+	member.Role = inv.Role
+	o.members[member.UserID] = member
+	o.org.MemberCount++
+	delete(db.orgInvitations, code)
+	out := o.org
+	return &out, nil
+}
+
+// DeleteOrganizationInvitation revokes an invitation of orgID. Returns
+// database.ErrOrgInvitationNotFound if there is no such invitation for that organization.
+func (db *DB) DeleteOrganizationInvitation(ctx context.Context, orgID, code string) error {
+	if orgID == "" || code == "" {
+		return fmt.Errorf("orgID and code are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	inv, ok := db.orgInvitations[code]
+	if !ok || inv.OrganizationID != orgID {
+		return database.ErrOrgInvitationNotFound
+	}
+	delete(db.orgInvitations, code)
+	return nil
+}
+
+// GetOrganizationGoals lists the goals of an organization, ordered by CreatedAt.
+func (db *DB) GetOrganizationGoals(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationGoal, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	goals := []models.OrganizationGoal{}
+	if o, ok := db.orgs[orgID]; ok {
+		for _, id := range sortedKeys(o.goals) {
+			g := o.goals[id]
+			g.CountryCodes = slices.Clone(g.CountryCodes)
+			goals = append(goals, g)
+		}
+	}
+	sort.SliceStable(goals, func(i, j int) bool {
+		return goals[i].CreatedAt.Before(goals[j].CreatedAt)
+	})
+	return goals, nil
+}
+
+// CreateOrganizationGoal adds a goal to orgID. Returns database.ErrOrganizationNotFound for an
+// unknown organization and database.ErrTooManyOrgGoals when it already has models.MaxOrgGoals
+// goals.
+func (db *DB) CreateOrganizationGoal(
+	ctx context.Context,
+	orgID string,
+	goal *models.OrganizationGoal,
+) (*models.OrganizationGoal, error) {
+	if orgID == "" || goal == nil {
+		return nil, fmt.Errorf("orgID and goal are required")
+	}
+	out := *goal
+	out.ID = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.CountryCodes = slices.Clone(goal.CountryCodes)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	o, ok := db.orgs[orgID]
+	if !ok {
+		return nil, database.ErrOrganizationNotFound
+	}
+	if len(o.goals) >= models.MaxOrgGoals {
+		return nil, database.ErrTooManyOrgGoals
+	}
+	o.goals[out.ID] = out
+	return &out, nil
+}
+
+// DeleteOrganizationGoal deletes a goal of orgID. Returns database.ErrOrgGoalNotFound if it does
+// not exist.
+func (db *DB) DeleteOrganizationGoal(ctx context.Context, orgID, goalID string) error {
+	if orgID == "" || goalID == "" {
+		return fmt.Errorf("orgID and goalID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	o, ok := db.orgs[orgID]
+	if !ok {
+		return database.ErrOrgGoalNotFound
+	}
+	if _, ok := o.goals[goalID]; !ok {
+		return database.ErrOrgGoalNotFound
+	}
+	delete(o.goals, goalID)
+	return nil
+}
+
+// CreateOrgShareLink mints a share link with a fresh token. Returns
+// database.ErrTooManyOrgShareLinks when the organization already has models.MaxOrgShareLinks
+// links.
+func (db *DB) CreateOrgShareLink(
+	ctx context.Context,
+	link *models.OrgShareLink,
+) (*models.OrgShareLink, error) {
+	if link == nil || link.OrganizationID == "" || link.Scope == "" {
+		return nil, fmt.Errorf("organizationID and scope are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.orgShareLinksOf(link.OrganizationID)) >= models.MaxOrgShareLinks {
+		return nil, database.ErrTooManyOrgShareLinks
+	}
+	out := *link
+	out.Token = newID()
+	out.CreatedAt = time.Now().UTC()
+	db.orgShareLinks[out.Token] = out
+	return &out, nil
+}
+
+// GetOrgShareLinks lists the share links of an organization.
+func (db *DB) GetOrgShareLinks(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrgShareLink, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.orgShareLinksOf(orgID), nil
+}
+
+func (db *DB) orgShareLinksOf(orgID string) []models.OrgShareLink {
+	links := []models.OrgShareLink{}
+	for _, token := range sortedKeys(db.orgShareLinks) {
+		if l := db.orgShareLinks[token]; l.OrganizationID == orgID {
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+// GetOrgShareLink returns the share link with token, or nil (not error) when there is none.
+func (db *DB) GetOrgShareLink(ctx context.Context, token string) (*models.OrgShareLink, error) {
+	if token == "" {
+		return nil, nil
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	l, ok := db.orgShareLinks[token]
+	if !ok {
+		return nil, nil
+	}
+	return &l, nil
+}
+
+// DeleteOrgShareLink revokes a share link of orgID. Returns database.ErrOrgShareLinkNotFound if
+// there is no such link for that organization.
+func (db *DB) DeleteOrgShareLink(ctx context.Context, orgID, token string) error {
+	if orgID == "" || token == "" {
+		return fmt.Errorf("orgID and token are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	l, ok := db.orgShareLinks[token]
+	if !ok || l.OrganizationID != orgID {
+		return database.ErrOrgShareLinkNotFound
+	}
+	delete(db.orgShareLinks, token)
+	return nil
+}
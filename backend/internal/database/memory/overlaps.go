@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// CreateVisitOverlap links the visit overlap.VisitID of userID (ShareToken shareToken) to the
+// visit overlap.FriendVisitID of overlap.FriendUserID. Both users get a copy under one new ID
+// and their VisitsRevision is incremented. Returns database.ErrVisitNotFound when either visit
+// is gone and database.ErrOverlapExists when userID already linked a visit to the friend's
+// visit.
+func (db *DB) CreateVisitOverlap(
+	ctx context.Context,
+	userID, shareToken string,
+	overlap *models.VisitOverlap,
+) (*models.VisitOverlap, error) {
+	if userID == "" || overlap == nil || overlap.FriendUserID == "" {
+		return nil, fmt.Errorf("userID and overlap friend user ID are required")
+	}
+	out := *overlap
+	out.CreatedAt = time.Now().UTC()
+	out.ID = newID()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if !db.hasVisit(userID, out.VisitID) || !db.hasVisit(out.FriendUserID, out.FriendVisitID) {
+		return nil, database.ErrVisitNotFound
+	}
+	u, friend := db.users[userID], db.users[out.FriendUserID]
+	for _, o := range u.overlaps {
+		if o.FriendUserID == out.FriendUserID && o.FriendVisitID == out.FriendVisitID {
+			return nil, database.ErrOverlapExists
+		}
+	}
+	u.overlaps[out.ID] = out
+	friend.overlaps[out.ID] = models.VisitOverlap{
+		ID:               out.ID,
+		VisitID:          out.FriendVisitID,
+		FriendUserID:     userID,
+		FriendShareToken: shareToken,
+		FriendVisitID:    out.VisitID,
+		CreatedAt:        out.CreatedAt,
+	}
+	db.bumpVisitsRevision(userID, out.FriendUserID)
+	return &out, nil
+}
+
+func (db *DB) hasVisit(userID, visitID string) bool {
+	u, ok := db.users[userID]
+	if !ok {
+		return false
+	}
+	_, ok = u.visits[visitID]
+	return ok
+}
+
+// GetVisitOverlaps returns the overlaps of all visits of userID, oldest first.
+func (db *DB) GetVisitOverlaps(
+	ctx context.Context,
+	userID string,
+) ([]models.VisitOverlap, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	overlaps := []models.VisitOverlap{}
+	if u, ok := db.users[userID]; ok {
+		for _, id := range sortedKeys(u.overlaps) {
+			overlaps = append(overlaps, u.overlaps[id])
+		}
+	}
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		return overlaps[i].CreatedAt.Before(overlaps[j].CreatedAt)
+	})
+	return overlaps, nil
+}
+
+// DeleteVisitOverlap deletes the overlap overlapID of userID together with the friend's copy.
+// Returns database.ErrOverlapNotFound when userID has no such overlap.
+func (db *DB) DeleteVisitOverlap(ctx context.Context, userID, overlapID string) error {
+	if userID == "" || overlapID == "" {
+		return fmt.Errorf("userID and overlapID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return database.ErrOverlapNotFound
+	}
+	o, ok := u.overlaps[overlapID]
+	if !ok {
+		return database.ErrOverlapNotFound
+	}
+	delete(u.overlaps, overlapID)
+	if friend, ok := db.users[o.FriendUserID]; ok {
+		delete(friend.overlaps, overlapID)
+	}
+	db.bumpVisitsRevision(userID, o.FriendUserID)
+	return nil
+}
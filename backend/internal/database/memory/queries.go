@@ -0,0 +1,617 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetCountryVisitsByUser returns all country visits of userID in ID order.
+func (db *DB) GetCountryVisitsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.CountryVisit, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.countryVisits(userID, true), nil
+}
+
+// GetPublicCountryVisitsByUser returns the country visits of userID except private ones.
+func (db *DB) GetPublicCountryVisitsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.CountryVisit, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.countryVisits(userID, false), nil
+}
+
+func (db *DB) countryVisits(userID string, includePrivate bool) []models.CountryVisit {
+	u, ok := db.users[userID]
+	if !ok {
+		return nil
+	}
+	var visits []models.CountryVisit
+	for _, id := range sortedKeys(u.visits) {
+		if v := u.visits[id]; includePrivate || !v.IsPrivate {
+			visits = append(visits, readVisit(v, id, userID))
+		}
+	}
+	return visits
+}
+
+// GetVisitedCountryCodes returns those of countryCodes that userID has at least one visit of,
+// in the order given.
+func (db *DB) GetVisitedCountryCodes(
+	ctx context.Context,
+	userID string,
+	countryCodes []string,
+) ([]string, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	visited := make(map[string]bool)
+	if u, ok := db.users[userID]; ok {
+		for _, v := range u.visits {
+			visited[v.CountryCode] = true
+		}
+	}
+	out := []string{}
+	for _, code := range countryCodes {
+		if visited[code] {
+			out = append(out, code)
+			visited[code] = false
+		}
+	}
+	return out, nil
+}
+
+// GetCountryVisitsPage returns up to limit visits of userID in ID order after the visit with ID
+// cursor; nextCursor is set when the page is full.
+func (db *DB) GetCountryVisitsPage(
+	ctx context.Context,
+	userID, cursor string,
+	limit int,
+) ([]models.CountryVisit, string, error) {
+	if userID == "" || limit <= 0 {
+		return nil, "", fmt.Errorf("userID and a positive limit are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	visits := make([]models.CountryVisit, 0, limit)
+	u, ok := db.users[userID]
+	if !ok {
+		return visits, "", nil
+	}
+	for _, id := range sortedKeys(u.visits) {
+		if id <= cursor {
+			continue
+		}
+		visits = append(visits, readVisit(u.visits[id], id, userID))
+		if len(visits) == limit {
+			return visits, id, nil
+		}
+	}
+	return visits, "", nil
+}
+
+// GetCountryVisit returns the visit visitID of userID, or database.ErrVisitNotFound.
+func (db *DB) GetCountryVisit(
+	ctx context.Context,
+	visitID, userID string,
+) (*models.CountryVisit, error) {
+	if visitID == "" || userID == "" {
+		return nil, fmt.Errorf("visitID and userID are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return nil, database.ErrVisitNotFound
+	}
+	v, ok := u.visits[visitID]
+	if !ok {
+		return nil, database.ErrVisitNotFound
+	}
+	out := readVisit(v, visitID, userID)
+	return &out, nil
+}
+
+// CreateCountryVisit stores a new visit of visit.UserID, records a created history event,
+// increments the user's VisitsRevision and adjusts its visit stats. Fails with a
+// database.LimitError when the user has maxVisits visits or their override's MaxVisits.
+func (db *DB) CreateCountryVisit(
+	ctx context.Context,
+	visit *models.CountryVisit,
+	maxVisits int,
+) (*models.CountryVisit, error) {
+	if visit == nil {
+		return nil, fmt.Errorf("visit is required")
+	}
+	if visit.UserID == "" || visit.CountryCode == "" {
+		return nil, fmt.Errorf("user_id and country_code are required")
+	}
+	doc := storedVisit(visit)
+	actorID := actorIDFromContext(ctx, visit.UserID)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.user(visit.UserID)
+	limits := models.UserLimits{MaxVisits: maxVisits}
+	if u.doc != nil {
+		limits = u.doc.Limits.Or(limits)
+	}
+	if limits.MaxVisits > 0 && len(u.visits) >= limits.MaxVisits {
+		return nil, database.NewLimitError(database.ErrVisitLimitReached, visit.UserID,
+			limits.MaxVisits)
+	}
+	id := newID()
+	updatedAt := models.NextVisitUpdatedAt(nil, time.Now())
+	doc.UpdatedAt = &updatedAt
+	u.visits[id] = doc
+	db.addHistoryEvent(u, id, models.VisitEventCreated, actorID, nil, &doc)
+	db.bumpVisitsRevision(visit.UserID)
+	updateVisitStats(u, "", doc.CountryCode)
+	out := *visit
+	out.Tags = doc.Tags
+	out.ID = id
+	out.UpdatedAt = &updatedAt
+	return &out, nil
+}
+
+// ReplaceCountryVisit writes the full visit and sets visit.UpdatedAt, records an updated (or, for
+// a new visit, created) history event, increments the user's VisitsRevision and adjusts its
+// visit stats. Unless unmodifiedSince is zero, returns database.ErrVisitModified when the stored
+// visit was written after it.
+func (db *DB) ReplaceCountryVisit(
+	ctx context.Context,
+	visit *models.CountryVisit,
+	unmodifiedSince time.Time,
+) error {
+	if visit == nil || visit.ID == "" || visit.UserID == "" {
+		return fmt.Errorf("visit with id and userID is required")
+	}
+	doc := storedVisit(visit)
+	actorID := actorIDFromContext(ctx, visit.UserID)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.user(visit.UserID)
+	removedCode := ""
+	before, exists := u.visits[visit.ID]
+	if exists && !unmodifiedSince.IsZero() && before.ModifiedSince(unmodifiedSince) {
+		return database.ErrVisitModified
+	}
+	updatedAt := models.NextVisitUpdatedAt(before.UpdatedAt, time.Now())
+	doc.UpdatedAt = &updatedAt
+	if exists {
+		db.addHistoryEvent(u, visit.ID, models.VisitEventUpdated, actorID, &before, &doc)
+		removedCode = before.CountryCode
+	} else {
+		db.addHistoryEvent(u, visit.ID, models.VisitEventCreated, actorID, nil, &doc)
+	}
+	u.visits[visit.ID] = doc
+	db.bumpVisitsRevision(visit.UserID)
+	updateVisitStats(u, removedCode, doc.CountryCode)
+	visit.UpdatedAt = &updatedAt
+	return nil
+}
+
+// DeleteCountryVisit deletes the visit visitID of userID with its overlaps (both copies),
+// records a deleted history event, adjusts the user's visit stats and increments the
+// VisitsRevision of the user and of the friends whose overlaps were deleted. Returns
+// database.ErrVisitNotFound if it does not exist.
+func (db *DB) DeleteCountryVisit(ctx context.Context, visitID string, userID string) error {
+	if visitID == "" || userID == "" {
+		return fmt.Errorf("visitID and userID are required")
+	}
+	actorID := actorIDFromContext(ctx, userID)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return database.ErrVisitNotFound
+	}
+	before, ok := u.visits[visitID]
+	if !ok {
+		return database.ErrVisitNotFound
+	}
+	revisionUserIDs := []string{userID}
+	for id, o := range u.overlaps {
+		if o.VisitID != visitID {
+			continue
+		}
+		delete(u.overlaps, id)
+		if friend, ok := db.users[o.FriendUserID]; ok {
+			delete(friend.overlaps, id)
+		}
+		revisionUserIDs = append(revisionUserIDs, o.FriendUserID)
+	}
+	delete(u.visits, visitID)
+	db.addHistoryEvent(u, visitID, models.VisitEventDeleted, actorID, &before, nil)
+	db.bumpVisitsRevision(revisionUserIDs...)
+	updateVisitStats(u, before.CountryCode, "")
+	return nil
+}
+
+// updateVisitStats adjusts VisitCount and DistinctCountries of u after a write that removed a
+// visit of removedCode and/or added one of addedCode ("" for none).
+func updateVisitStats(u *userData, removedCode, addedCode string) {
+	if u.doc == nil || removedCode == addedCode {
+		return
+	}
+	remaining := make(map[string]int, 2)
+	for _, v := range u.visits {
+		if v.CountryCode == removedCode || v.CountryCode == addedCode {
+			remaining[v.CountryCode]++
+		}
+	}
+	if removedCode != "" {
+		u.doc.VisitCount--
+		if remaining[removedCode] == 0 {
+			u.doc.DistinctCountries--
+		}
+	}
+	if addedCode != "" {
+		u.doc.VisitCount++
+		if remaining[addedCode] == 1 {
+			u.doc.DistinctCountries++
+		}
+	}
+}
+
+// addHistoryEvent appends a history event of the visit visitID; before and after are stored
+// visits (nil when the visit did not exist before or no longer exists after the change).
+func (db *DB) addHistoryEvent(
+	u *userData,
+	visitID, eventType, actorID string,
+	before, after *models.CountryVisit,
+) {
+	event := models.VisitHistoryEvent{
+		ID:      newID(),
+		Type:    eventType,
+		ActorID: actorID,
+		Time:    time.Now().UTC(),
+	}
+	if before != nil {
+		v := cloneVisit(*before)
+		event.Before = &v
+	}
+	if after != nil {
+		v := cloneVisit(*after)
+		event.After = &v
+	}
+	u.history[visitID] = append(u.history[visitID], event)
+}
+
+// actorIDFromContext returns the ID of the authenticated user making the change, or fallback.
+func actorIDFromContext(ctx context.Context, fallback string) string {
+	if user, ok := ctxkeys.CurrentUser(ctx); ok && user.ID != "" {
+		return user.ID
+	}
+	return fallback
+}
+
+// GetCountryVisitHistory returns the change history of a visit, oldest first. History outlives
+// the visit; database.ErrVisitNotFound is returned only when there is neither history nor a
+// visit.
+func (db *DB) GetCountryVisitHistory(
+	ctx context.Context,
+	visitID, userID string,
+) ([]models.VisitHistoryEvent, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return nil, database.ErrVisitNotFound
+	}
+	stored := u.history[visitID]
+	if len(stored) == 0 {
+		if _, ok := u.visits[visitID]; !ok {
+			return nil, database.ErrVisitNotFound
+		}
+		return []models.VisitHistoryEvent{}, nil
+	}
+	events := make([]models.VisitHistoryEvent, len(stored))
+	for i, e := range stored {
+		events[i] = e
+		for _, v := range []**models.CountryVisit{&events[i].Before, &events[i].After} {
+			if *v != nil {
+				visit := cloneVisit(**v)
+				visit.ID = visitID
+				visit.UserID = userID
+				*v = &visit
+			}
+		}
+	}
+	return events, nil
+}
+
+// EnsureUser creates the User document of user.ID with a new ShareToken and default settings,
+// or updates ImageURL, Name (when set) and IsAnonymous of an existing one.
+func (db *DB) EnsureUser(ctx context.Context, user *models.User) error {
+	if user == nil || user.ID == "" {
+		return fmt.Errorf("user ID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.user(user.ID)
+	if u.doc == nil {
+		settings := models.DefaultUserSettings()
+		u.doc = &models.User{
+			ShareToken:  newID(),
+			Name:        user.Name,
+			Email:       user.Email,
+			ImageURL:    user.ImageURL,
+			IsAnonymous: user.IsAnonymous,
+			Settings:    &settings,
+		}
+		return nil
+	}
+	u.doc.ImageURL = user.ImageURL
+	if user.Name != "" {
+		u.doc.Name = user.Name
+	}
+	if !user.IsAnonymous {
+		u.doc.IsAnonymous = false
+	}
+	return nil
+}
+
+// GetUserByShareToken returns the user with shareToken, or (nil, nil) when there is none.
+func (db *DB) GetUserByShareToken(ctx context.Context, shareToken string) (*models.User, error) {
+	if shareToken == "" {
+		return nil, fmt.Errorf("shareToken is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	id, u := db.userByShareToken(shareToken)
+	if u == nil {
+		return nil, nil
+	}
+	return readUser(id, u), nil
+}
+
+// GetUsersByShareTokens returns the users with the given ShareTokens by ShareToken. Tokens
+// without a user are absent.
+func (db *DB) GetUsersByShareTokens(
+	ctx context.Context,
+	shareTokens []string,
+) (map[string]*models.User, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.usersByShareTokens(shareTokens), nil
+}
+
+func (db *DB) usersByShareTokens(shareTokens []string) map[string]*models.User {
+	users := make(map[string]*models.User, len(shareTokens))
+	for _, token := range shareTokens {
+		if id, u := db.userByShareToken(token); u != nil {
+			users[token] = readUser(id, u)
+		}
+	}
+	return users
+}
+
+// GetUserByID returns the user with userID, or (nil, nil) when there is none.
+func (db *DB) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.userDoc(userID)
+	if u == nil {
+		return nil, nil
+	}
+	return readUser(userID, u), nil
+}
+
+// GetFriendsByUser returns all friends of userID in ID order, or nil when there are none.
+func (db *DB) GetFriendsByUser(ctx context.Context, userID string) ([]models.Friend, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.friendsOf(userID), nil
+}
+
+func (db *DB) friendsOf(userID string) []models.Friend {
+	u, ok := db.users[userID]
+	if !ok {
+		return nil
+	}
+	var friends []models.Friend
+	for _, id := range sortedKeys(u.friends) {
+		friends = append(friends, u.friends[id])
+	}
+	return friends
+}
+
+// GetFriendsPage returns up to limit friends of userID in ID order after the friend with ID
+// cursor; nextCursor is set when the page is full.
+func (db *DB) GetFriendsPage(
+	ctx context.Context,
+	userID, cursor string,
+	limit int,
+) ([]models.Friend, string, error) {
+	if userID == "" || limit <= 0 {
+		return nil, "", fmt.Errorf("userID and a positive limit are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	friends := make([]models.Friend, 0, limit)
+	for _, f := range db.friendsOf(userID) {
+		if f.ID <= cursor {
+			continue
+		}
+		friends = append(friends, f)
+		if len(friends) == limit {
+			return friends, f.ID, nil
+		}
+	}
+	return friends, "", nil
+}
+
+// GetFriendByShareToken returns the friend of userID with shareToken, or (nil, nil).
+func (db *DB) GetFriendByShareToken(
+	ctx context.Context,
+	userID, shareToken string,
+) (*models.Friend, error) {
+	if userID == "" || shareToken == "" {
+		return nil, fmt.Errorf("userID and shareToken are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.friendByShareToken(userID, shareToken), nil
+}
+
+func (db *DB) friendByShareToken(userID, shareToken string) *models.Friend {
+	for _, f := range db.friendsOf(userID) {
+		if f.ShareToken == shareToken {
+			return &f
+		}
+	}
+	return nil
+}
+
+// DeleteFriendByShareToken deletes the friend of userID with shareToken and userID from the
+// friend's followers. Returns database.ErrFriendNotFound when there is no such friend.
+func (db *DB) DeleteFriendByShareToken(ctx context.Context, userID, shareToken string) error {
+	if userID == "" || shareToken == "" {
+		return fmt.Errorf("userID and shareToken are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	f := db.friendByShareToken(userID, shareToken)
+	if f == nil {
+		return database.ErrFriendNotFound
+	}
+	delete(db.users[userID].friends, f.ID)
+	if friendID, friend := db.userByShareToken(shareToken); friend != nil {
+		delete(db.users[friendID].followers, userID)
+	}
+	return nil
+}
+
+// UpdateFriendProfiles writes Name, ImageURL and SyncedAt of the given friends (by ID) of
+// userID. Friends deleted meanwhile are skipped.
+func (db *DB) UpdateFriendProfiles(
+	ctx context.Context,
+	userID string,
+	friends []models.Friend,
+) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return nil
+	}
+	for _, f := range friends {
+		stored, ok := u.friends[f.ID]
+		if !ok {
+			continue
+		}
+		stored.Name = f.Name
+		stored.ImageURL = f.ImageURL
+		stored.SyncedAt = f.SyncedAt
+		u.friends[f.ID] = stored
+	}
+	return nil
+}
+
+// UpdateFriendNickname sets Nickname of the friend of userID with shareToken (empty clears it)
+// and returns the friend. Returns database.ErrFriendNotFound when there is no such friend.
+func (db *DB) UpdateFriendNickname(
+	ctx context.Context,
+	userID, shareToken, nickname string,
+) (*models.Friend, error) {
+	if userID == "" || shareToken == "" {
+		return nil, fmt.Errorf("userID and shareToken are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	f := db.friendByShareToken(userID, shareToken)
+	if f == nil {
+		return nil, database.ErrFriendNotFound
+	}
+	f.Nickname = nickname
+	db.users[userID].friends[f.ID] = *f
+	return f, nil
+}
+
+// SetSharingDisabled sets SharingDisabled of userID. Returns database.ErrUserNotFound if the
+// user does not exist.
+func (db *DB) SetSharingDisabled(ctx context.Context, userID string, disabled bool) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.userDoc(userID)
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	u.SharingDisabled = disabled
+	return nil
+}
+
+// SetTokensRevokedAt sets TokensRevokedAt of userID. Returns database.ErrUserNotFound if the
+// user does not exist.
+func (db *DB) SetTokensRevokedAt(ctx context.Context, userID string, at time.Time) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.userDoc(userID)
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	u.TokensRevokedAt = &at
+	return nil
+}
+
+// SetUserLimits stores limits as the admin override of the limits of userID; nil removes it.
+// Returns database.ErrUserNotFound if the user does not exist.
+func (db *DB) SetUserLimits(ctx context.Context, userID string, limits *models.UserLimits) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.userDoc(userID)
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	u.Limits = limits
+	return nil
+}
+
+// UpdateUserSettings replaces Settings of userID. Returns database.ErrUserNotFound if the user
+// does not exist.
+func (db *DB) UpdateUserSettings(
+	ctx context.Context,
+	userID string,
+	settings models.UserSettings,
+) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.userDoc(userID)
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	u.Settings = cloneSettings(settings)
+	return nil
+}
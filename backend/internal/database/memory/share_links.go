@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// CreateShareLink stores a share link of link.UserID with a fresh token. Returns
+// database.ErrTooManyShareLinks when the user has models.MaxShareLinks unexpired links.
+func (db *DB) CreateShareLink(
+	ctx context.Context,
+	link *models.ShareLink,
+) (*models.ShareLink, error) {
+	if link == nil || link.UserID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	out := *link
+	out.Token = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.Views = 0
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.shareLinks(out.UserID, out.CreatedAt)) >= models.MaxShareLinks {
+		return nil, database.ErrTooManyShareLinks
+	}
+	db.user(out.UserID).shareLinks[out.Token] = out
+	return &out, nil
+}
+
+// GetShareLinks returns the unexpired share links of userID, newest first. Links whose views
+// are used up are included.
+func (db *DB) GetShareLinks(ctx context.Context, userID string) ([]models.ShareLink, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.shareLinks(userID, time.Now()), nil
+}
+
+func (db *DB) shareLinks(userID string, now time.Time) []models.ShareLink {
+	links := []models.ShareLink{}
+	if u, ok := db.users[userID]; ok {
+		for _, token := range sortedKeys(u.shareLinks) {
+			if l := u.shareLinks[token]; l.ExpiresAt.After(now) {
+				links = append(links, l)
+			}
+		}
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].CreatedAt.After(links[j].CreatedAt)
+	})
+	return links
+}
+
+// GetShareLinkByToken returns the share link with token of any user, or nil (not error) when
+// there is none. Expired links are returned too.
+func (db *DB) GetShareLinkByToken(ctx context.Context, token string) (*models.ShareLink, error) {
+	if token == "" {
+		return nil, nil
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, u := range db.users {
+		if l, ok := u.shareLinks[token]; ok {
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+// CountShareLinkView counts a view of the share link token of userID. Returns
+// database.ErrShareLinkNotFound when the link is gone or no longer active at now.
+func (db *DB) CountShareLinkView(
+	ctx context.Context,
+	userID, token string,
+	now time.Time,
+) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return database.ErrShareLinkNotFound
+	}
+	l, ok := u.shareLinks[token]
+	if !ok || !l.Active(now) {
+		return database.ErrShareLinkNotFound
+	}
+	l.Views++
+	u.shareLinks[token] = l
+	return nil
+}
+
+// DeleteShareLink revokes the share link token of userID. Returns
+// database.ErrShareLinkNotFound if it does not exist.
+func (db *DB) DeleteShareLink(ctx context.Context, userID, token string) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok {
+		return database.ErrShareLinkNotFound
+	}
+	if _, ok := u.shareLinks[token]; !ok {
+		return database.ErrShareLinkNotFound
+	}
+	delete(u.shareLinks, token)
+	return nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// RecordShareView counts a view of token, a share token of userID, at now: the total, the
+// day's bucket and the last view time.
+func (db *DB) RecordShareView(ctx context.Context, userID, token string, now time.Time) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u := db.user(userID)
+	stats := u.shareStats[token]
+	stats.Views++
+	stats.LastViewedAt = &now
+	stats.Days = maps.Clone(stats.Days)
+	if stats.Days == nil {
+		stats.Days = make(map[string]int64)
+	}
+	stats.Days[models.ShareStatsDate(now)]++
+	u.shareStats[token] = stats
+	return nil
+}
+
+// GetShareStats returns the view statistics of tokens, share tokens of userID, in the order of
+// tokens. Tokens never viewed have zero Views. Daily is not set.
+func (db *DB) GetShareStats(
+	ctx context.Context,
+	userID string,
+	tokens []string,
+) ([]models.ShareStats, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	out := make([]models.ShareStats, len(tokens))
+	for i, token := range tokens {
+		if u, ok := db.users[userID]; ok {
+			stats := u.shareStats[token]
+			stats.LastViewedAt = clonePtr(stats.LastViewedAt)
+			stats.Days = maps.Clone(stats.Days)
+			out[i] = stats
+		}
+		out[i].Token = token
+	}
+	return out, nil
+}
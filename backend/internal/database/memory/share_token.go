@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+)
+
+// RotateShareToken gives userID a new random ShareToken. The users who have userID as a friend
+// either get their Friend entry and visit companions moved to the new token, or, with
+// removeFromFriends, lose the Friend entry, the companion and the follower entry. Pending friend
+// requests from and to the user carry the new token either way. Returns the new token and the
+// number of friends lists the user was removed from.
+func (db *DB) RotateShareToken(
+	ctx context.Context,
+	userID string,
+	removeFromFriends bool,
+) (string, int, error) {
+	if userID == "" {
+		return "", 0, fmt.Errorf("userID is required")
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	u, ok := db.users[userID]
+	if !ok || u.doc == nil {
+		return "", 0, database.ErrUserNotFound
+	}
+	old, shareToken := u.doc.ShareToken, newID()
+	u.doc.ShareToken = shareToken
+	removed := 0
+	for _, followerID := range sortedKeys(u.followers) {
+		follower, ok := db.users[followerID]
+		if !ok {
+			continue
+		}
+		hadFriend := false
+		for id, f := range follower.friends {
+			if f.ShareToken != old {
+				continue
+			}
+			hadFriend = true
+			if removeFromFriends {
+				delete(follower.friends, id)
+			} else {
+				f.ShareToken = shareToken
+				follower.friends[id] = f
+			}
+		}
+		changedVisits := false
+		for id, v := range follower.visits {
+			if !slices.Contains(v.Companions, old) {
+				continue
+			}
+			companions := make([]string, 0, len(v.Companions))
+			for _, token := range v.Companions {
+				switch {
+				case token != old:
+					companions = append(companions, token)
+				case !removeFromFriends:
+					companions = append(companions, shareToken)
+				}
+			}
+			v.Companions = companions
+			follower.visits[id] = v
+			changedVisits = true
+		}
+		if changedVisits {
+			db.bumpVisitsRevision(followerID)
+		}
+		if removeFromFriends {
+			delete(u.followers, followerID)
+			if hadFriend {
+				removed++
+			}
+		}
+	}
+	if old != "" {
+		for id, r := range db.friendRequests {
+			if r.FromUserID == userID {
+				r.FromShareToken = shareToken
+			}
+			if r.ToUserID == userID {
+				r.ToShareToken = shareToken
+			}
+			db.friendRequests[id] = r
+		}
+	}
+	return shareToken, removed, nil
+}
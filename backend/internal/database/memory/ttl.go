@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DeleteExpiredDocuments deletes up to limit documents of collectionGroup whose field is at or
+// before now and returns how many it deleted. Only the ExpiresAt fields of the collections in
+// ttl.Policies are supported.
+func (db *DB) DeleteExpiredDocuments(
+	ctx context.Context,
+	collectionGroup, field string,
+	now time.Time,
+	limit int,
+) (int, error) {
+	if field != "ExpiresAt" {
+		return 0, fmt.Errorf("unsupported expiry field %s.%s", collectionGroup, field)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	deleted := 0
+	expired := func(expiresAt time.Time) bool {
+		if deleted == limit || expiresAt.After(now) {
+			return false
+		}
+		deleted++
+		return true
+	}
+	switch collectionGroup {
+	case "organization_invitations":
+		for code, inv := range db.orgInvitations {
+			if expired(inv.ExpiresAt) {
+				delete(db.orgInvitations, code)
+			}
+		}
+	case "friend_invites":
+		for _, u := range db.users {
+			for id, inv := range u.invites {
+				if expired(inv.ExpiresAt) {
+					delete(u.invites, id)
+				}
+			}
+		}
+	case "share_links":
+		for _, u := range db.users {
+			for token, l := range u.shareLinks {
+				if expired(l.ExpiresAt) {
+					delete(u.shareLinks, token)
+				}
+			}
+		}
+	case "audit":
+		for _, u := range db.users {
+			kept := u.audit[:0]
+			for _, e := range u.audit {
+				if !expired(e.ExpiresAt) {
+					kept = append(kept, e)
+				}
+			}
+			u.audit = kept
+		}
+	default:
+		return 0, fmt.Errorf("unsupported collection group %s", collectionGroup)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetVisitSummariesForUsers returns the counts over the non-private visits of each of userIDs,
+// by user ID; users without visits get a zero summary.
+func (db *DB) GetVisitSummariesForUsers(
+	ctx context.Context,
+	userIDs []string,
+) (map[string]models.FriendVisitSummary, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	summaries := make(map[string]models.FriendVisitSummary, len(userIDs))
+	for _, userID := range userIDs {
+		if userID == "" {
+			return nil, fmt.Errorf("userIDs must not be empty")
+		}
+		var summary models.FriendVisitSummary
+		if u, ok := db.users[userID]; ok {
+			countries := make(map[string]struct{}, len(u.visits))
+			for _, v := range u.visits {
+				if v.IsPrivate {
+					continue
+				}
+				summary.VisitCount++
+				countries[v.CountryCode] = struct{}{}
+				if len(v.Proofs) > 0 {
+					summary.VerifiedVisitCount++
+				}
+			}
+			summary.CountryCount = len(countries)
+		}
+		summaries[userID] = summary
+	}
+	return summaries, nil
+}
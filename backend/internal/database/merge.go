@@ -0,0 +1,366 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// visitMoveBatchSize is how many visits moveVisits moves per transaction: each visit takes three
+// writes (create, history event, delete) of the 500 a transaction allows.
+const visitMoveBatchSize = 150
+
+// friendMergeBatchSize is how many friends mergeFriends adds per transaction, with two writes
+// (friend and followers entry) each.
+const friendMergeBatchSize = 200
+
+// MergeUsers merges the account fromUserID into toUserID and then purges fromUserID:
+//   - country visits are moved (see moveVisits);
+//   - friends toUserID does not have yet are added, with their followers entries;
+//   - users who have fromUserID as a friend get toUserID instead (or lose the entry when they
+//     have both or toUserID has blocked them), so toUserID's ShareToken is the one kept;
+//   - blocked users, share links and API keys are copied under the same IDs;
+//   - the handle of fromUserID is taken over when toUserID has none.
+//
+// Everything else of fromUserID (pending friend requests and invites, organization
+// memberships, visit overlaps) is deleted with the account. Each step is idempotent, so an
+// interrupted merge is completed by the next call. Returns ErrUserNotFound when either user
+// document is missing.
+func (c *Client) MergeUsers(
+	ctx context.Context,
+	fromUserID, toUserID string,
+	now time.Time,
+) (*models.AccountMergeResult, error) {
+	if fromUserID == "" || toUserID == "" || fromUserID == toUserID {
+		return nil, fmt.Errorf("two different user IDs are required")
+	}
+	from, err := c.GetUserByID(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := c.GetUserByID(ctx, toUserID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, ErrUserNotFound
+	}
+
+	res := &models.AccountMergeResult{}
+	docs, err := c.Collection("users").Doc(from.ID).Collection("country_visits").Select().
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visits to merge: %w", err)
+	}
+	for start := 0; start < len(docs); start += visitMoveBatchSize {
+		refs := make([]*firestore.DocumentRef, 0, visitMoveBatchSize)
+		for _, doc := range docs[start:min(start+visitMoveBatchSize, len(docs))] {
+			refs = append(refs, doc.Ref)
+		}
+		n, err := c.moveVisits(ctx, refs, from.ID, to.ID)
+		if err != nil {
+			return nil, err
+		}
+		res.Visits += n
+	}
+	if err := c.RebuildUserVisitStats(ctx, to.ID); err != nil {
+		return nil, err
+	}
+
+	if res.Friends, err = c.mergeFriends(ctx, from, to, now); err != nil {
+		return nil, err
+	}
+	if res.Followers, err = c.mergeFollowers(ctx, from, to, now); err != nil {
+		return nil, err
+	}
+	res.BlockedUsers, err = c.copyUserDocs(ctx, "blocked", from.ID, to.ID,
+		func(id string, data map[string]interface{}) bool { return id != to.ID })
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"share_links", "api_keys"} {
+		n, err := c.copyUserDocs(ctx, name, from.ID, to.ID,
+			func(_ string, data map[string]interface{}) bool {
+				data["UserID"] = to.ID
+				return true
+			})
+		if err != nil {
+			return nil, err
+		}
+		if name == "share_links" {
+			res.ShareLinks = n
+		} else {
+			res.APIKeys = n
+		}
+	}
+	if to.Handle == "" && from.Handle != "" {
+		if err := c.moveHandle(ctx, from.Handle, from.ID, to.ID); err != nil {
+			return nil, err
+		}
+		res.Handle = from.Handle
+	}
+
+	if err := c.ScheduleAccountDeletion(ctx, from.ID, now); err != nil {
+		return nil, err
+	}
+	if err := c.PurgeUser(ctx, from.ID, now); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// moveVisits moves the visits at refs (under fromUserID) to toUserID in one transaction and
+// returns how many still existed. Visits keep their IDs and get a created history event under
+// toUserID; the VisitsRevision of both users is bumped, so a visit is never lost or duplicated.
+func (c *Client) moveVisits(
+	ctx context.Context,
+	refs []*firestore.DocumentRef,
+	fromUserID, toUserID string,
+) (int, error) {
+	target := c.Collection("users").Doc(toUserID).Collection("country_visits")
+	moved := 0
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		moved = 0
+		bump, err := c.prepareVisitsRevisionBumps(tx, fromUserID, toUserID)
+		if err != nil {
+			return err
+		}
+		snaps, err := tx.GetAll(refs)
+		if err != nil {
+			return fmt.Errorf("failed to get visits to move: %w", err)
+		}
+		for _, snap := range snaps {
+			if !snap.Exists() {
+				continue
+			}
+			ref := target.Doc(snap.Ref.ID)
+			event := visitHistoryEvent(models.VisitEventCreated, toUserID, nil, snap.Data())
+			if err := tx.Create(ref, snap.Data()); err != nil {
+				return err
+			}
+			if err := tx.Create(ref.Collection("history").NewDoc(), event); err != nil {
+				return err
+			}
+			if err := tx.Delete(snap.Ref); err != nil {
+				return err
+			}
+			moved++
+		}
+		return bump()
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to move visits: %w", err)
+	}
+	return moved, nil
+}
+
+// mergeFriends adds the friends of from that to does not have (by ShareToken, leaving out to
+// itself) to to's friends, with to's entries in their followers, and returns how many were
+// added. Nicknames are kept. The friend limit does not apply, so no friend is lost.
+func (c *Client) mergeFriends(
+	ctx context.Context,
+	from, to *models.User,
+	now time.Time,
+) (int, error) {
+	friends, err := c.GetFriendsByUser(ctx, from.ID)
+	if err != nil || len(friends) == 0 {
+		return 0, err
+	}
+	shareTokens := make([]string, len(friends))
+	for i, f := range friends {
+		shareTokens[i] = f.ShareToken
+	}
+	friendUsers, err := c.GetUsersByShareTokens(ctx, shareTokens)
+	if err != nil {
+		return 0, err
+	}
+	toFriends := c.Collection("users").Doc(to.ID).Collection("friends")
+	added := 0
+	for start := 0; start < len(friends); start += friendMergeBatchSize {
+		batch := friends[start:min(start+friendMergeBatchSize, len(friends))]
+		n := 0
+		err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			n = 0
+			existing, err := tx.Documents(toFriends).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to list friends: %w", err)
+			}
+			has := map[string]bool{to.ShareToken: true}
+			for _, doc := range existing {
+				token, _ := doc.DataAt("ShareToken")
+				if s, ok := token.(string); ok {
+					has[s] = true
+				}
+			}
+			for _, f := range batch {
+				if has[f.ShareToken] {
+					continue
+				}
+				has[f.ShareToken] = true
+				doc := friendDoc(f)
+				if f.Nickname != "" {
+					doc["Nickname"] = f.Nickname
+				}
+				if err := tx.Create(toFriends.NewDoc(), doc); err != nil {
+					return err
+				}
+				if u := friendUsers[f.ShareToken]; u != nil && u.ID != to.ID {
+					follower := models.Follower{CreatedAt: now}
+					if err := tx.Set(c.followerRef(u.ID, to.ID), follower); err != nil {
+						return err
+					}
+				}
+				n++
+			}
+			return nil
+		})
+		if err != nil {
+			return added, fmt.Errorf("failed to merge friends: %w", err)
+		}
+		added += n
+	}
+	return added, nil
+}
+
+// mergeFollowers points the friend entries of users who have from as a friend to to, one
+// transaction per follower, and returns how many were pointed to to. Entries are deleted
+// instead when the follower has to as a friend already, is to itself or is blocked by to.
+func (c *Client) mergeFollowers(
+	ctx context.Context,
+	from, to *models.User,
+	now time.Time,
+) (int, error) {
+	followers, err := c.Collection("users").Doc(from.ID).Collection("followers").
+		DocumentRefs(ctx).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list followers: %w", err)
+	}
+	moved := 0
+	for _, follower := range followers {
+		followerID := follower.ID
+		blockedRef := c.Collection("users").Doc(to.ID).Collection("blocked").Doc(followerID)
+		friends := c.Collection("users").Doc(followerID).Collection("friends")
+		repointed := false
+		err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			repointed = false
+			fromEntries, err := tx.Documents(friends.Where("ShareToken", "==", from.ShareToken)).
+				GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to find friend entry: %w", err)
+			}
+			toEntries, err := tx.Documents(friends.Where("ShareToken", "==", to.ShareToken).
+				Limit(1)).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to find friend entry: %w", err)
+			}
+			blocked, err := tx.Get(blockedRef)
+			if err != nil && status.Code(err) != codes.NotFound {
+				return fmt.Errorf("failed to get blocked user: %w", err)
+			}
+			keep := len(fromEntries) > 0 && len(toEntries) == 0 && followerID != to.ID &&
+				!blocked.Exists()
+			for i, entry := range fromEntries {
+				if keep && i == 0 {
+					continue
+				}
+				if err := tx.Delete(entry.Ref); err != nil {
+					return err
+				}
+			}
+			if !keep {
+				return nil
+			}
+			err = tx.Update(fromEntries[0].Ref, []firestore.Update{
+				{Path: "ShareToken", Value: to.ShareToken},
+				{Path: "Name", Value: to.Name},
+				{Path: "ImageURL", Value: to.ImageURL},
+			})
+			if err != nil {
+				return err
+			}
+			repointed = true
+			return tx.Set(c.followerRef(to.ID, followerID), models.Follower{CreatedAt: now})
+		})
+		if err != nil {
+			return moved, fmt.Errorf("failed to merge follower: %w", err)
+		}
+		if repointed {
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// copyUserDocs copies the documents of the subcollection name of fromUserID to toUserID under
+// the same IDs, overwriting existing ones, and returns how many were copied. keep may change a
+// document's data and returns false to skip it.
+func (c *Client) copyUserDocs(
+	ctx context.Context,
+	name, fromUserID, toUserID string,
+	keep func(id string, data map[string]interface{}) bool,
+) (int, error) {
+	docs, err := c.Collection("users").Doc(fromUserID).Collection(name).Documents(ctx).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list %s: %w", name, err)
+	}
+	target := c.Collection("users").Doc(toUserID).Collection(name)
+	bw := c.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+	for _, doc := range docs {
+		data := doc.Data()
+		if !keep(doc.Ref.ID, data) {
+			continue
+		}
+		job, err := bw.Set(target.Doc(doc.Ref.ID), data)
+		if err != nil {
+			bw.End()
+			return 0, fmt.Errorf("failed to queue copy: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return 0, fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+	}
+	return len(jobs), nil
+}
+
+// moveHandle hands handle from fromUserID over to toUserID in one transaction; fromUserID no
+// longer has it, so purging that account keeps the handle.
+func (c *Client) moveHandle(ctx context.Context, handle, fromUserID, toUserID string) error {
+	users := c.Collection("users")
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(c.handleRef(handle))
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil
+			}
+			return fmt.Errorf("failed to get handle: %w", err)
+		}
+		if owner, _ := snap.DataAt("UserID"); owner != fromUserID {
+			return nil
+		}
+		err = tx.Update(snap.Ref, []firestore.Update{{Path: "UserID", Value: toUserID}})
+		if err != nil {
+			return err
+		}
+		err = tx.Update(users.Doc(toUserID), []firestore.Update{{Path: "Handle", Value: handle}})
+		if err != nil {
+			return err
+		}
+		return tx.Update(users.Doc(fromUserID),
+			[]firestore.Update{{Path: "Handle", Value: firestore.Delete}})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move handle: %w", err)
+	}
+	return nil
+}
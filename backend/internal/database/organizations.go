@@ -0,0 +1,694 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var (
+	ErrOrganizationNotFound   = errors.New("organization not found")
+	ErrOrgMemberNotFound      = errors.New("organization member not found")
+	ErrOrgMemberAlreadyExists = errors.New("already a member of the organization")
+	ErrOrganizationFull       = errors.New("organization has the maximum number of members")
+	ErrOrgInvitationNotFound  = errors.New("organization invitation not found")
+	ErrTooManyOrgInvitations  = errors.New("organization has too many pending invitations")
+	ErrOrgGoalNotFound        = errors.New("organization goal not found")
+	ErrTooManyOrgGoals        = errors.New("organization has the maximum number of goals")
+	ErrOrgShareLinkNotFound   = errors.New("organization share link not found")
+	ErrTooManyOrgShareLinks   = errors.New("organization has the maximum number of share links")
+)
+
+func (c *Client) organizationRef(orgID string) *firestore.DocumentRef {
+	return c.Collection("organizations").Doc(orgID)
+}
+
+// CreateOrganization creates organizations/{new ID} with owner as its first member (MemberCount
+// 1) and a fresh ShareToken, in one transaction. Returns the stored organization.
+func (c *Client) CreateOrganization(
+	ctx context.Context,
+	org *models.Organization,
+	owner models.OrganizationMember,
+) (*models.Organization, error) {
+	if org == nil || org.Name == "" || owner.UserID == "" {
+		return nil, fmt.Errorf("organization name and owner are required")
+	}
+	ref := c.Collection("organizations").NewDoc()
+	out := *org
+	out.ID = ref.ID
+	out.ShareToken = uuid.New().String()
+	out.CreatedBy = owner.UserID
+	out.MemberCount = 1
+	owner.Role = models.OrgRoleOwner
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Create(ref, &out); err != nil {
+			return err
+		}
+		return tx.Create(ref.Collection("members").Doc(owner.UserID), &owner)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	return &out, nil
+}
+
+// GetOrganization loads organizations/{orgID}. Returns ErrOrganizationNotFound if missing.
+func (c *Client) GetOrganization(ctx context.Context, orgID string) (*models.Organization, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	snap, err := c.organizationRef(orgID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return organizationFromSnapshot(snap)
+}
+
+// GetOrganizationByShareToken looks up the organization by ShareToken. Returns (nil, nil) if
+// not found.
+func (c *Client) GetOrganizationByShareToken(
+	ctx context.Context,
+	shareToken string,
+) (*models.Organization, error) {
+	if shareToken == "" {
+		return nil, fmt.Errorf("shareToken is required")
+	}
+	iter := c.Collection("organizations").Where("ShareToken", "==", shareToken).Limit(1).
+		Documents(ctx)
+	defer iter.Stop()
+	snap, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization by share token: %w", err)
+	}
+	return organizationFromSnapshot(snap)
+}
+
+func organizationFromSnapshot(snap *firestore.DocumentSnapshot) (*models.Organization, error) {
+	var org models.Organization
+	if err := snap.DataTo(&org); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal organization: %w", err)
+	}
+	org.ID = snap.Ref.ID
+	return &org, nil
+}
+
+// UpdateOrganizationProfile sets Name and Description of organizations/{orgID}. Returns
+// ErrOrganizationNotFound if missing.
+func (c *Client) UpdateOrganizationProfile(
+	ctx context.Context,
+	orgID, name, description string,
+) error {
+	if orgID == "" || name == "" {
+		return fmt.Errorf("orgID and name are required")
+	}
+	_, err := c.organizationRef(orgID).Update(ctx, []firestore.Update{
+		{Path: "Name", Value: name},
+		{Path: "Description", Value: description},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrOrganizationNotFound
+		}
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+	return nil
+}
+
+// DeleteOrganization deletes the organization with its members, invitations, goals and share
+// links in one transaction (bounded by the Max* limits of each). Returns
+// ErrOrganizationNotFound if missing.
+func (c *Client) DeleteOrganization(ctx context.Context, orgID string) error {
+	if orgID == "" {
+		return fmt.Errorf("orgID is required")
+	}
+	ref := c.organizationRef(orgID)
+	invitations := c.Collection("organization_invitations").Where("OrganizationID", "==", orgID)
+	shareLinks := c.Collection("org_share_links").Where("OrganizationID", "==", orgID)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrOrganizationNotFound
+			}
+			return fmt.Errorf("failed to get organization: %w", err)
+		}
+		members, err := tx.Documents(ref.Collection("members")).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list members: %w", err)
+		}
+		pending, err := tx.Documents(invitations).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list invitations: %w", err)
+		}
+		goals, err := tx.Documents(ref.Collection("goals")).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list goals: %w", err)
+		}
+		links, err := tx.Documents(shareLinks).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list share links: %w", err)
+		}
+		docs := append(append(append(members, pending...), goals...), links...)
+		for _, doc := range docs {
+			if err := tx.Delete(doc.Ref); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(snap.Ref)
+	})
+	if errors.Is(err, ErrOrganizationNotFound) {
+		return ErrOrganizationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	return nil
+}
+
+// GetOrganizationMembers lists the members of an organization, ordered by JoinedAt.
+func (c *Client) GetOrganizationMembers(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationMember, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	iter := c.organizationRef(orgID).Collection("members").OrderBy("JoinedAt", firestore.Asc).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var members []models.OrganizationMember
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate organization members: %w", err)
+		}
+		var m models.OrganizationMember
+		if err := doc.DataTo(&m); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal organization member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// GetOrganizationMember loads the membership of userID. Returns ErrOrgMemberNotFound when the
+// user is not a member (or the organization does not exist).
+func (c *Client) GetOrganizationMember(
+	ctx context.Context,
+	orgID, userID string,
+) (*models.OrganizationMember, error) {
+	if orgID == "" || userID == "" {
+		return nil, fmt.Errorf("orgID and userID are required")
+	}
+	snap, err := c.organizationRef(orgID).Collection("members").Doc(userID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrOrgMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	var m models.OrganizationMember
+	if err := snap.DataTo(&m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal organization member: %w", err)
+	}
+	return &m, nil
+}
+
+// GetOrganizationsByUser returns the organizations userID is a member of, with the user's role,
+// via a collection group query over members.
+func (c *Client) GetOrganizationsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.OrganizationMembership, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	memberDocs, err := c.CollectionGroup("members").Where("UserID", "==", userID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+	if len(memberDocs) == 0 {
+		return nil, nil
+	}
+	refs := make([]*firestore.DocumentRef, len(memberDocs))
+	for i, doc := range memberDocs {
+		refs[i] = doc.Ref.Parent.Parent
+	}
+	orgDocs, err := c.GetAll(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
+	}
+
+	memberships := make([]models.OrganizationMembership, 0, len(orgDocs))
+	for i, snap := range orgDocs {
+		if !snap.Exists() {
+			continue
+		}
+		org, err := organizationFromSnapshot(snap)
+		if err != nil {
+			return nil, err
+		}
+		var m models.OrganizationMember
+		if err := memberDocs[i].DataTo(&m); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal organization member: %w", err)
+		}
+		memberships = append(memberships, models.OrganizationMembership{
+			Organization: *org,
+			Role:         m.Role,
+		})
+	}
+	return memberships, nil
+}
+
+// UpdateOrganizationMemberRole sets the Role of a membership. Returns ErrOrgMemberNotFound if
+// the user is not a member.
+func (c *Client) UpdateOrganizationMemberRole(
+	ctx context.Context,
+	orgID, userID, role string,
+) error {
+	if orgID == "" || userID == "" || role == "" {
+		return fmt.Errorf("orgID, userID and role are required")
+	}
+	_, err := c.organizationRef(orgID).Collection("members").Doc(userID).Update(ctx,
+		[]firestore.Update{{Path: "Role", Value: role}})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrOrgMemberNotFound
+		}
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+	return nil
+}
+
+// RemoveOrganizationMember deletes a membership and decrements MemberCount in one transaction.
+// Returns ErrOrgMemberNotFound if the user is not a member.
+func (c *Client) RemoveOrganizationMember(ctx context.Context, orgID, userID string) error {
+	if orgID == "" || userID == "" {
+		return fmt.Errorf("orgID and userID are required")
+	}
+	orgRef := c.organizationRef(orgID)
+	memberRef := orgRef.Collection("members").Doc(userID)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(memberRef); err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrOrgMemberNotFound
+			}
+			return fmt.Errorf("failed to get organization member: %w", err)
+		}
+		if err := tx.Delete(memberRef); err != nil {
+			return err
+		}
+		return tx.Update(orgRef, []firestore.Update{
+			{Path: "MemberCount", Value: firestore.Increment(-1)},
+		})
+	})
+	if errors.Is(err, ErrOrgMemberNotFound) {
+		return ErrOrgMemberNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+	return nil
+}
+
+// CreateOrganizationInvitation stores a new invitation under a random code, expiring after
+// models.OrgInvitationTTL. Returns ErrTooManyOrgInvitations when the organization already has
+// models.MaxOrgPendingInvitations invitations.
+func (c *Client) CreateOrganizationInvitation(
+	ctx context.Context,
+	inv *models.OrganizationInvitation,
+) (*models.OrganizationInvitation, error) {
+	if inv == nil || inv.OrganizationID == "" || inv.Role == "" {
+		return nil, fmt.Errorf("organizationID and role are required")
+	}
+	pending, err := c.GetOrganizationInvitations(ctx, inv.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) >= models.MaxOrgPendingInvitations {
+		return nil, ErrTooManyOrgInvitations
+	}
+
+	out := *inv
+	out.Code = uuid.New().String()
+	out.CreatedAt = time.Now().UTC()
+	out.ExpiresAt = out.CreatedAt.Add(models.OrgInvitationTTL)
+	_, err = c.Collection("organization_invitations").Doc(out.Code).Create(ctx, &out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization invitation: %w", err)
+	}
+	return &out, nil
+}
+
+// GetOrganizationInvitations lists the invitations of an organization, expired ones included.
+func (c *Client) GetOrganizationInvitations(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationInvitation, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	docs, err := c.Collection("organization_invitations").Where("OrganizationID", "==", orgID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization invitations: %w", err)
+	}
+	invitations := make([]models.OrganizationInvitation, 0, len(docs))
+	for _, doc := range docs {
+		var inv models.OrganizationInvitation
+		if err := doc.DataTo(&inv); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal organization invitation: %w", err)
+		}
+		inv.Code = doc.Ref.ID
+		invitations = append(invitations, inv)
+	}
+	return invitations, nil
+}
+
+// GetOrganizationInvitation loads organization_invitations/{code}. Returns
+// ErrOrgInvitationNotFound if missing or expired.
+func (c *Client) GetOrganizationInvitation(
+	ctx context.Context,
+	code string,
+) (*models.OrganizationInvitation, error) {
+	if code == "" {
+		return nil, fmt.Errorf("code is required")
+	}
+	snap, err := c.Collection("organization_invitations").Doc(code).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrOrgInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization invitation: %w", err)
+	}
+	return invitationFromSnapshot(snap)
+}
+
+// invitationFromSnapshot decodes an invitation, treating expired ones as missing.
+func invitationFromSnapshot(
+	snap *firestore.DocumentSnapshot,
+) (*models.OrganizationInvitation, error) {
+	var inv models.OrganizationInvitation
+	if err := snap.DataTo(&inv); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal organization invitation: %w", err)
+	}
+	inv.Code = snap.Ref.ID
+	if !time.Now().Before(inv.ExpiresAt) {
+		return nil, ErrOrgInvitationNotFound
+	}
+	return &inv, nil
+}
+
+// AcceptOrganizationInvitation adds member with the invitation's role, increments MemberCount
+// and deletes the single-use invitation in one transaction. Returns the joined organization,
+// or ErrOrgInvitationNotFound (missing or expired), ErrOrganizationNotFound,
+// ErrOrgMemberAlreadyExists or ErrOrganizationFull.
+func (c *Client) AcceptOrganizationInvitation(
+	ctx context.Context,
+	code string,
+	member models.OrganizationMember,
+) (*models.Organization, error) {
+	if code == "" || member.UserID == "" {
+		return nil, fmt.Errorf("code and member user ID are required")
+	}
+	invRef := c.Collection("organization_invitations").Doc(code)
+	var joined *models.Organization
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		invSnap, err := tx.Get(invRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrOrgInvitationNotFound
+			}
+			return fmt.Errorf("failed to get organization invitation: %w", err)
+		}
+		inv, err := invitationFromSnapshot(invSnap)
+		if err != nil {
+			return err
+		}
+		orgRef := c.organizationRef(inv.OrganizationID)
+		orgSnap, err := tx.Get(orgRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrOrganizationNotFound
+			}
+			return fmt.Errorf("failed to get organization: %w", err)
+		}
+		org, err := organizationFromSnapshot(orgSnap)
+		if err != nil {
+			return err
+		}
+		memberRef := orgRef.Collection("members").Doc(member.UserID)
+		memberSnap, err := tx.Get(memberRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return fmt.Errorf("failed to get organization member: %w", err)
+		}
+		if memberSnap != nil && memberSnap.Exists() {
+			return ErrOrgMemberAlreadyExists
+		}
+		if org.MemberCount >= models.MaxOrgMembers {
+			return ErrOrganizationFull
+		}
+
+		member.Role = inv.Role
+		if err := tx.Create(memberRef, &member); err != nil {
+			return err
+		}
+		if err := tx.Update(orgRef, []firestore.Update{
+			{Path: "MemberCount", Value: firestore.Increment(1)},
+		}); err != nil {
+			return err
+		}
+		if err := tx.Delete(invRef); err != nil {
+			return err
+		}
+		org.MemberCount++
+		joined = org
+		return nil
+	})
+	for _, sentinel := range []error{
+		ErrOrgInvitationNotFound, ErrOrganizationNotFound, ErrOrgMemberAlreadyExists,
+		ErrOrganizationFull,
+	} {
+		if errors.Is(err, sentinel) {
+			return nil, sentinel
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept organization invitation: %w", err)
+	}
+	return joined, nil
+}
+
+// DeleteOrganizationInvitation revokes an invitation of orgID. Returns ErrOrgInvitationNotFound
+// if there is no such invitation for that organization.
+func (c *Client) DeleteOrganizationInvitation(ctx context.Context, orgID, code string) error {
+	if orgID == "" || code == "" {
+		return fmt.Errorf("orgID and code are required")
+	}
+	ref := c.Collection("organization_invitations").Doc(code)
+	snap, err := ref.Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrOrgInvitationNotFound
+		}
+		return fmt.Errorf("failed to get organization invitation: %w", err)
+	}
+	if id, _ := snap.DataAt("OrganizationID"); id != orgID {
+		return ErrOrgInvitationNotFound
+	}
+	if _, err := ref.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete organization invitation: %w", err)
+	}
+	return nil
+}
+
+// GetOrganizationGoals lists the goals of an organization, ordered by CreatedAt.
+func (c *Client) GetOrganizationGoals(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationGoal, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	docs, err := c.organizationRef(orgID).Collection("goals").OrderBy("CreatedAt", firestore.Asc).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization goals: %w", err)
+	}
+	goals := make([]models.OrganizationGoal, 0, len(docs))
+	for _, doc := range docs {
+		var g models.OrganizationGoal
+		if err := doc.DataTo(&g); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal organization goal: %w", err)
+		}
+		g.ID = doc.Ref.ID
+		goals = append(goals, g)
+	}
+	return goals, nil
+}
+
+// CreateOrganizationGoal adds a goal to orgID. Returns ErrOrganizationNotFound for an unknown
+// organization and ErrTooManyOrgGoals when it already has models.MaxOrgGoals goals.
+func (c *Client) CreateOrganizationGoal(
+	ctx context.Context,
+	orgID string,
+	goal *models.OrganizationGoal,
+) (*models.OrganizationGoal, error) {
+	if orgID == "" || goal == nil {
+		return nil, fmt.Errorf("orgID and goal are required")
+	}
+	ref := c.organizationRef(orgID)
+	goalRef := ref.Collection("goals").NewDoc()
+	out := *goal
+	out.ID = goalRef.ID
+	out.CreatedAt = time.Now().UTC()
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(ref); err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrOrganizationNotFound
+			}
+			return fmt.Errorf("failed to get organization: %w", err)
+		}
+		existing, err := tx.Documents(ref.Collection("goals")).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list goals: %w", err)
+		}
+		if len(existing) >= models.MaxOrgGoals {
+			return ErrTooManyOrgGoals
+		}
+		return tx.Create(goalRef, &out)
+	})
+	if errors.Is(err, ErrOrganizationNotFound) || errors.Is(err, ErrTooManyOrgGoals) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization goal: %w", err)
+	}
+	return &out, nil
+}
+
+// DeleteOrganizationGoal deletes a goal of orgID. Returns ErrOrgGoalNotFound if it does not
+// exist.
+func (c *Client) DeleteOrganizationGoal(ctx context.Context, orgID, goalID string) error {
+	if orgID == "" || goalID == "" {
+		return fmt.Errorf("orgID and goalID are required")
+	}
+	ref := c.organizationRef(orgID).Collection("goals").Doc(goalID)
+	if _, err := ref.Delete(ctx, firestore.Exists); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrOrgGoalNotFound
+		}
+		return fmt.Errorf("failed to delete organization goal: %w", err)
+	}
+	return nil
+}
+
+// CreateOrgShareLink mints a share link with a fresh token. Returns ErrTooManyOrgShareLinks when
+// the organization already has models.MaxOrgShareLinks links.
+func (c *Client) CreateOrgShareLink(
+	ctx context.Context,
+	link *models.OrgShareLink,
+) (*models.OrgShareLink, error) {
+	if link == nil || link.OrganizationID == "" || link.Scope == "" {
+		return nil, fmt.Errorf("organizationID and scope are required")
+	}
+	existing, err := c.GetOrgShareLinks(ctx, link.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxOrgShareLinks {
+		return nil, ErrTooManyOrgShareLinks
+	}
+
+	out := *link
+	out.Token = uuid.New().String()
+	out.CreatedAt = time.Now().UTC()
+	if _, err := c.Collection("org_share_links").Doc(out.Token).Create(ctx, &out); err != nil {
+		return nil, fmt.Errorf("failed to create organization share link: %w", err)
+	}
+	return &out, nil
+}
+
+// GetOrgShareLinks lists the share links of an organization.
+func (c *Client) GetOrgShareLinks(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrgShareLink, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	docs, err := c.Collection("org_share_links").Where("OrganizationID", "==", orgID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization share links: %w", err)
+	}
+	links := make([]models.OrgShareLink, 0, len(docs))
+	for _, doc := range docs {
+		var l models.OrgShareLink
+		if err := doc.DataTo(&l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal organization share link: %w", err)
+		}
+		l.Token = doc.Ref.ID
+		links = append(links, l)
+	}
+	return links, nil
+}
+
+// GetOrgShareLink returns the share link with token, or nil (not error) when there is none.
+func (c *Client) GetOrgShareLink(ctx context.Context, token string) (*models.OrgShareLink, error) {
+	if token == "" {
+		return nil, nil
+	}
+	snap, err := c.Collection("org_share_links").Doc(token).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get organization share link: %w", err)
+	}
+	var l models.OrgShareLink
+	if err := snap.DataTo(&l); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal organization share link: %w", err)
+	}
+	l.Token = snap.Ref.ID
+	return &l, nil
+}
+
+// DeleteOrgShareLink revokes a share link of orgID. Returns ErrOrgShareLinkNotFound if there is
+// no such link for that organization.
+func (c *Client) DeleteOrgShareLink(ctx context.Context, orgID, token string) error {
+	if orgID == "" || token == "" {
+		return fmt.Errorf("orgID and token are required")
+	}
+	link, err := c.GetOrgShareLink(ctx, token)
+	if err != nil {
+		return err
+	}
+	if link == nil || link.OrganizationID != orgID {
+		return ErrOrgShareLinkNotFound
+	}
+	if _, err := c.Collection("org_share_links").Doc(token).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete organization share link: %w", err)
+	}
+	return nil
+}
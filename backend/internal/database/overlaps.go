@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var (
+	ErrOverlapNotFound = errors.New("overlap not found")
+	ErrOverlapExists   = errors.New("overlap already exists")
+)
+
+func (c *Client) overlapsRef(userID string) *firestore.CollectionRef {
+	return c.Collection("users").Doc(userID).Collection("overlaps")
+}
+
+// mirrorOverlap returns the copy of o (held by userID, whose ShareToken is shareToken) from the
+// friend's point of view.
+func mirrorOverlap(o models.VisitOverlap, userID, shareToken string) models.VisitOverlap {
+	return models.VisitOverlap{
+		ID:               o.ID,
+		VisitID:          o.FriendVisitID,
+		FriendUserID:     userID,
+		FriendShareToken: shareToken,
+		FriendVisitID:    o.VisitID,
+		CreatedAt:        o.CreatedAt,
+	}
+}
+
+// CreateVisitOverlap links the visit overlap.VisitID of userID (ShareToken shareToken) to the
+// visit overlap.FriendVisitID of overlap.FriendUserID. Both users get a copy under one new ID in
+// a single transaction that also increments both users' VisitsRevision, as overlaps are part
+// of GET /visits. Returns ErrVisitNotFound when either visit is gone and ErrOverlapExists
+// when userID already linked a visit to the friend's visit.
+func (c *Client) CreateVisitOverlap(
+	ctx context.Context,
+	userID, shareToken string,
+	overlap *models.VisitOverlap,
+) (*models.VisitOverlap, error) {
+	if userID == "" || overlap == nil || overlap.FriendUserID == "" {
+		return nil, fmt.Errorf("userID and overlap friend user ID are required")
+	}
+	out := *overlap
+	out.CreatedAt = time.Now().UTC()
+	ref := c.overlapsRef(userID).NewDoc()
+	out.ID = ref.ID
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for _, visitRef := range []*firestore.DocumentRef{
+			c.Collection("users").Doc(userID).Collection("country_visits").Doc(out.VisitID),
+			c.Collection("users").Doc(out.FriendUserID).Collection("country_visits").
+				Doc(out.FriendVisitID),
+		} {
+			if _, err := tx.Get(visitRef); err != nil {
+				if status.Code(err) == codes.NotFound {
+					return ErrVisitNotFound
+				}
+				return fmt.Errorf("failed to get country visit: %w", err)
+			}
+		}
+		existing, err := tx.Documents(c.overlapsRef(userID).
+			Where("FriendUserID", "==", out.FriendUserID).
+			Where("FriendVisitID", "==", out.FriendVisitID).Limit(1)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to check existing overlap: %w", err)
+		}
+		if len(existing) > 0 {
+			return ErrOverlapExists
+		}
+		bumps, err := c.prepareVisitsRevisionBumps(tx, userID, out.FriendUserID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(ref, &out); err != nil {
+			return err
+		}
+		mirror := mirrorOverlap(out, userID, shareToken)
+		if err := tx.Create(c.overlapsRef(out.FriendUserID).Doc(out.ID), &mirror); err != nil {
+			return err
+		}
+		return bumps()
+	})
+	if errors.Is(err, ErrVisitNotFound) || errors.Is(err, ErrOverlapExists) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create overlap: %w", err)
+	}
+	return &out, nil
+}
+
+// GetVisitOverlaps returns the overlaps of all visits of userID, oldest first.
+func (c *Client) GetVisitOverlaps(
+	ctx context.Context,
+	userID string,
+) ([]models.VisitOverlap, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	docs, err := c.overlapsRef(userID).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overlaps: %w", err)
+	}
+	overlaps := make([]models.VisitOverlap, 0, len(docs))
+	for _, doc := range docs {
+		var o models.VisitOverlap
+		if err := doc.DataTo(&o); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal overlap: %w", err)
+		}
+		o.ID = doc.Ref.ID
+		overlaps = append(overlaps, o)
+	}
+	sort.Slice(overlaps, func(i, j int) bool {
+		return overlaps[i].CreatedAt.Before(overlaps[j].CreatedAt)
+	})
+	return overlaps, nil
+}
+
+// DeleteVisitOverlap deletes the overlap overlapID of userID together with the friend's copy,
+// so either user can remove it. Returns ErrOverlapNotFound when userID has no such overlap.
+func (c *Client) DeleteVisitOverlap(ctx context.Context, userID, overlapID string) error {
+	if userID == "" || overlapID == "" {
+		return fmt.Errorf("userID and overlapID are required")
+	}
+	ref := c.overlapsRef(userID).Doc(overlapID)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrOverlapNotFound
+			}
+			return fmt.Errorf("failed to get overlap: %w", err)
+		}
+		refs, friendUserID, err := c.overlapPairRefs(snap)
+		if err != nil {
+			return err
+		}
+		bumps, err := c.prepareVisitsRevisionBumps(tx, userID, friendUserID)
+		if err != nil {
+			return err
+		}
+		for _, r := range refs {
+			if err := tx.Delete(r); err != nil {
+				return err
+			}
+		}
+		return bumps()
+	})
+	if errors.Is(err, ErrOverlapNotFound) {
+		return ErrOverlapNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete overlap: %w", err)
+	}
+	return nil
+}
+
+// overlapPairRefs returns the references of the stored overlap snap and of the friend's copy,
+// and the friend's user ID.
+func (c *Client) overlapPairRefs(
+	snap *firestore.DocumentSnapshot,
+) ([]*firestore.DocumentRef, string, error) {
+	var o models.VisitOverlap
+	if err := snap.DataTo(&o); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal overlap: %w", err)
+	}
+	refs := []*firestore.DocumentRef{snap.Ref, c.overlapsRef(o.FriendUserID).Doc(snap.Ref.ID)}
+	return refs, o.FriendUserID, nil
+}
+
+// prepareVisitsRevisionBumps is prepareVisitsRevisionBump for several users; each user is bumped
+// once even when listed repeatedly.
+func (c *Client) prepareVisitsRevisionBumps(
+	tx *firestore.Transaction,
+	userIDs ...string,
+) (func() error, error) {
+	bumps := make([]func() error, 0, len(userIDs))
+	seen := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		bump, err := c.prepareVisitsRevisionBump(tx, userID)
+		if err != nil {
+			return nil, err
+		}
+		bumps = append(bumps, bump)
+	}
+	return func() error {
+		for _, bump := range bumps {
+			if err := bump(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
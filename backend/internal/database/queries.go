@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/google/uuid"
@@ -11,18 +12,39 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/migrations"
 	"github.com/matti777/my-countries/backend/internal/models"
 )
 
 var (
-	ErrVisitNotFound       = errors.New("visit not found")
-	ErrFriendAlreadyExists = errors.New("friend already exists")
-	ErrFriendNotFound      = errors.New("friend not found")
-	ErrUserNotFound        = errors.New("user not found")
+	ErrVisitNotFound  = errors.New("visit not found")
+	ErrFriendNotFound = errors.New("friend not found")
+	ErrUserNotFound   = errors.New("user not found")
+
+	// ErrVisitModified is returned by ReplaceCountryVisit when the visit was written after the
+	// given unmodifiedSince time.
+	ErrVisitModified = errors.New("visit modified")
 )
 
 // GetCountryVisitsByUser retrieves all country visits for a user. userID is the auth User ID (Firestore document ID).
 func (c *Client) GetCountryVisitsByUser(ctx context.Context, userID string) ([]models.CountryVisit, error) {
+	return c.getCountryVisits(ctx, userID, true)
+}
+
+// GetPublicCountryVisitsByUser retrieves the user's country visits excluding private ones.
+// Use for share views and any endpoint that exposes visits to someone other than the owner.
+// Private visits are skipped here rather than with a query filter because documents written
+// before IsPrivate existed lack the field and would not match IsPrivate == false.
+func (c *Client) GetPublicCountryVisitsByUser(ctx context.Context, userID string) ([]models.CountryVisit, error) {
+	return c.getCountryVisits(ctx, userID, false)
+}
+
+func (c *Client) getCountryVisits(
+	ctx context.Context,
+	userID string,
+	includePrivate bool,
+) ([]models.CountryVisit, error) {
 	iter := c.Collection("users").Doc(userID).Collection("country_visits").Documents(ctx)
 	defer iter.Stop()
 
@@ -36,21 +58,90 @@ func (c *Client) GetCountryVisitsByUser(ctx context.Context, userID string) ([]m
 			return nil, fmt.Errorf("failed to iterate country visits: %w", err)
 		}
 
-		var visit models.CountryVisit
-		if err := doc.DataTo(&visit); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal country visit: %w", err)
+		visit, err := c.countryVisitFromDoc(ctx, userID, doc)
+		if err != nil {
+			return nil, err
 		}
-		if visit.Tags == nil {
-			visit.Tags = []string{}
+		if visit.IsPrivate && !includePrivate {
+			continue
 		}
-		visit.ID = doc.Ref.ID
-		visit.UserID = userID
 		visits = append(visits, visit)
 	}
 
 	return visits, nil
 }
 
+// GetVisitedCountryCodes returns those of countryCodes that the user has at least one visit
+// (private ones included) of, in the order given. Only matching visits are read, in batches of
+// maxInQueryValues codes.
+func (c *Client) GetVisitedCountryCodes(
+	ctx context.Context,
+	userID string,
+	countryCodes []string,
+) ([]string, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	coll := c.Collection("users").Doc(userID).Collection("country_visits")
+	visited := make(map[string]bool, len(countryCodes))
+	for start := 0; start < len(countryCodes); start += maxInQueryValues {
+		chunk := countryCodes[start:min(start+maxInQueryValues, len(countryCodes))]
+		docs, err := coll.Where("CountryCode", "in", chunk).Select("CountryCode").
+			Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get visited countries: %w", err)
+		}
+		for _, doc := range docs {
+			if code, ok := doc.Data()["CountryCode"].(string); ok {
+				visited[code] = true
+			}
+		}
+	}
+	out := []string{}
+	for _, code := range countryCodes {
+		if visited[code] {
+			out = append(out, code)
+			visited[code] = false
+		}
+	}
+	return out, nil
+}
+
+// GetCountryVisitsPage returns up to limit of the user's country visits (including private
+// ones) in document ID order, starting after the visit with ID cursor (from the start when
+// empty). nextCursor is the ID of the last visit returned when the page is full, and empty on
+// the last page.
+func (c *Client) GetCountryVisitsPage(
+	ctx context.Context,
+	userID, cursor string,
+	limit int,
+) (visits []models.CountryVisit, nextCursor string, err error) {
+	if userID == "" || limit <= 0 {
+		return nil, "", fmt.Errorf("userID and a positive limit are required")
+	}
+	q := c.Collection("users").Doc(userID).Collection("country_visits").
+		OrderBy(firestore.DocumentID, firestore.Asc).Limit(limit)
+	if cursor != "" {
+		q = q.StartAfter(cursor)
+	}
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list country visits: %w", err)
+	}
+	visits = make([]models.CountryVisit, 0, len(docs))
+	for _, doc := range docs {
+		visit, err := c.countryVisitFromDoc(ctx, userID, doc)
+		if err != nil {
+			return nil, "", err
+		}
+		visits = append(visits, visit)
+	}
+	if len(docs) == limit {
+		nextCursor = docs[len(docs)-1].Ref.ID
+	}
+	return visits, nextCursor, nil
+}
+
 // GetCountryVisit loads a single country visit by ID under users/{userID}/country_visits.
 // Returns (nil, ErrVisitNotFound) if the document does not exist.
 func (c *Client) GetCountryVisit(ctx context.Context, visitID, userID string) (*models.CountryVisit, error) {
@@ -68,31 +159,90 @@ func (c *Client) GetCountryVisit(ctx context.Context, visitID, userID string) (*
 	if !snap.Exists() {
 		return nil, ErrVisitNotFound
 	}
-	var visit models.CountryVisit
-	if err := snap.DataTo(&visit); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal country visit: %w", err)
-	}
-	if visit.Tags == nil {
-		visit.Tags = []string{}
+	visit, err := c.countryVisitFromDoc(ctx, userID, snap)
+	if err != nil {
+		return nil, err
 	}
-	visit.ID = snap.Ref.ID
-	visit.UserID = userID
 	return &visit, nil
 }
 
-// ReplaceCountryVisit writes the full country visit document at users/{userID}/country_visits/{visit.ID}.
-func (c *Client) ReplaceCountryVisit(ctx context.Context, visit *models.CountryVisit) error {
+// ReplaceCountryVisit writes the full country visit document at users/{userID}/country_visits/{visit.ID}
+// and sets visit.UpdatedAt. Increments the user's VisitsRevision, adjusts its visit stats and
+// records an updated history event (with the previous document as Before) in the same
+// transaction. Unless unmodifiedSince is zero, returns ErrVisitModified when the stored visit
+// was written after it.
+func (c *Client) ReplaceCountryVisit(
+	ctx context.Context,
+	visit *models.CountryVisit,
+	unmodifiedSince time.Time,
+) error {
 	if visit == nil || visit.ID == "" || visit.UserID == "" {
 		return fmt.Errorf("visit with id and userID is required")
 	}
+	ref := c.Collection("users").Doc(visit.UserID).Collection("country_visits").Doc(visit.ID)
+	actorID := actorIDFromContext(ctx, visit.UserID)
+	var updatedAt time.Time
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return fmt.Errorf("failed to get country visit: %w", err)
+		}
+		// Only the fields used here are read: the document may be at an older schema version
+		var before models.CountryVisit
+		exists := snap != nil && snap.Exists()
+		if exists {
+			before.CountryCode, _ = snap.Data()["CountryCode"].(string)
+			if t, ok := snap.Data()["UpdatedAt"].(time.Time); ok {
+				before.UpdatedAt = &t
+			}
+			if !unmodifiedSince.IsZero() && before.ModifiedSince(unmodifiedSince) {
+				return ErrVisitModified
+			}
+		}
+		updatedAt = models.NextVisitUpdatedAt(before.UpdatedAt, time.Now())
+		stored := *visit
+		stored.UpdatedAt = &updatedAt
+		doc := countryVisitDoc(&stored)
+		event := visitHistoryEvent(models.VisitEventUpdated, actorID, nil, doc)
+		if exists {
+			event["Before"] = snap.Data()
+		} else {
+			event["Type"] = models.VisitEventCreated
+		}
+		bump, err := c.prepareVisitWriteBump(tx, visit.UserID, before.CountryCode,
+			visit.CountryCode, 0)
+		if err != nil {
+			return err
+		}
+		if err := tx.Set(ref, doc); err != nil {
+			return err
+		}
+		if err := tx.Create(ref.Collection("history").NewDoc(), event); err != nil {
+			return err
+		}
+		return bump()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update country visit: %w", err)
+	}
+	visit.UpdatedAt = &updatedAt
+	return nil
+}
+
+// countryVisitDoc builds the Firestore document for a country visit at the latest schema
+// version. Optional fields are only written when set.
+func countryVisitDoc(visit *models.CountryVisit) map[string]interface{} {
 	tags := visit.Tags
 	if tags == nil {
 		tags = []string{}
 	}
 	doc := map[string]interface{}{
-		"CountryCode": visit.CountryCode,
-		"VisitTime":   visit.VisitedTime,
-		"Tags":        tags,
+		"CountryCode":    visit.CountryCode,
+		"VisitTime":      visit.VisitedTime,
+		"VisitedTime":    visit.VisitedTime,
+		"Tags":           tags,
+		"IsPrivate":      visit.IsPrivate,
+		migrations.Field: int64(migrations.Latest(migrations.CountryVisits)),
 	}
 	if visit.MediaURL != nil && *visit.MediaURL != "" {
 		doc["MediaURL"] = *visit.MediaURL
@@ -100,59 +250,288 @@ func (c *Client) ReplaceCountryVisit(ctx context.Context, visit *models.CountryV
 	if visit.Notes != "" {
 		doc["Notes"] = visit.Notes
 	}
-	ref := c.Collection("users").Doc(visit.UserID).Collection("country_visits").Doc(visit.ID)
-	_, err := ref.Set(ctx, doc)
+	if visit.VisitType != "" {
+		doc["VisitType"] = visit.VisitType
+	}
+	if visit.SubdivisionCode != "" {
+		doc["SubdivisionCode"] = visit.SubdivisionCode
+	}
+	if visit.DestinationCode != "" {
+		doc["DestinationCode"] = visit.DestinationCode
+	}
+	if len(visit.Companions) > 0 {
+		doc["Companions"] = visit.Companions
+	}
+	if visit.Location != nil {
+		doc["Location"] = visit.Location
+	}
+	if len(visit.Proofs) > 0 {
+		doc["Proofs"] = visit.Proofs
+	}
+	if visit.UpdatedAt != nil {
+		doc["UpdatedAt"] = *visit.UpdatedAt
+	}
+
+	return doc
+}
+
+// visitHistoryEvent builds a history document; before and after are visit documents (nil when
+// the visit did not exist before or no longer exists after the change).
+func visitHistoryEvent(
+	eventType, actorID string,
+	before, after map[string]interface{},
+) map[string]interface{} {
+	event := map[string]interface{}{
+		"Type":    eventType,
+		"ActorID": actorID,
+		"Time":    firestore.ServerTimestamp,
+	}
+	if before != nil {
+		event["Before"] = before
+	}
+	if after != nil {
+		event["After"] = after
+	}
+
+	return event
+}
+
+// actorIDFromContext returns the ID of the authenticated user making the change, or fallback
+// when the request context has none (e.g. background jobs).
+func actorIDFromContext(ctx context.Context, fallback string) string {
+	if user, ok := ctxkeys.CurrentUser(ctx); ok && user.ID != "" {
+		return user.ID
+	}
+
+	return fallback
+}
+
+// GetCountryVisitHistory returns the change history of a visit, oldest first, from
+// users/{userID}/country_visits/{visitID}/history. History outlives the visit itself, so deleted
+// visits still have one. Visits written before history was recorded return an empty list;
+// ErrVisitNotFound is returned only when there is neither history nor a visit.
+func (c *Client) GetCountryVisitHistory(
+	ctx context.Context,
+	visitID, userID string,
+) ([]models.VisitHistoryEvent, error) {
+	ref := c.Collection("users").Doc(userID).Collection("country_visits").Doc(visitID)
+	iter := ref.Collection("history").OrderBy("Time", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	var events []models.VisitHistoryEvent
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate visit history: %w", err)
+		}
+		var event models.VisitHistoryEvent
+		if err := doc.DataTo(&event); err != nil {
+			return nil, fmt.Errorf("failed to decode visit history event: %w", err)
+		}
+		event.ID = doc.Ref.ID
+		for _, v := range []*models.CountryVisit{event.Before, event.After} {
+			if v != nil {
+				v.ID = visitID
+				v.UserID = userID
+			}
+		}
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		snap, err := ref.Get(ctx)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil, ErrVisitNotFound
+			}
+			return nil, fmt.Errorf("failed to get country visit: %w", err)
+		}
+		if !snap.Exists() {
+			return nil, ErrVisitNotFound
+		}
+		return []models.VisitHistoryEvent{}, nil
+	}
+
+	return events, nil
+}
+
+// prepareVisitsRevisionBump reads the user document inside tx (Firestore requires reads before
+// writes) and returns a function that increments VisitsRevision. The returned function is a
+// no-op when the user document does not exist yet.
+func (c *Client) prepareVisitsRevisionBump(tx *firestore.Transaction, userID string) (func() error, error) {
+	userRef := c.Collection("users").Doc(userID)
+	snap, err := tx.Get(userRef)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if snap == nil || !snap.Exists() {
+		return func() error { return nil }, nil
+	}
+	return func() error {
+		return tx.Update(userRef, []firestore.Update{
+			{Path: "VisitsRevision", Value: firestore.Increment(1)},
+		})
+	}, nil
+}
+
+// prepareVisitWriteBump is prepareVisitsRevisionBump for a write that removes a visit of
+// removedCode and/or adds one of addedCode ("" for none, the same code for an edit that keeps
+// the country). The returned function also adjusts VisitCount and DistinctCountries; whether
+// the country count changes is read inside tx from the user's other visits of those countries.
+// A write adding a visit to a user with MaxVisits (maxVisits or the user's override; 0 for no
+// limit) visits fails with a LimitError.
+func (c *Client) prepareVisitWriteBump(
+	tx *firestore.Transaction,
+	userID, removedCode, addedCode string,
+	maxVisits int,
+) (func() error, error) {
+	userRef := c.Collection("users").Doc(userID)
+	snap, err := tx.Get(userRef)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if snap == nil || !snap.Exists() {
+		return func() error { return nil }, nil
+	}
+	visits := userRef.Collection("country_visits")
+	var visitDelta, countryDelta int64
+	if removedCode != addedCode {
+		if removedCode != "" {
+			// The visit being removed is one of them
+			n, err := countVisitsOf(tx, visits, removedCode, 2)
+			if err != nil {
+				return nil, err
+			}
+			visitDelta--
+			if n <= 1 {
+				countryDelta--
+			}
+		}
+		if addedCode != "" {
+			n, err := countVisitsOf(tx, visits, addedCode, 1)
+			if err != nil {
+				return nil, err
+			}
+			visitDelta++
+			if n == 0 {
+				countryDelta++
+			}
+		}
+	}
+	if visitDelta > 0 {
+		limits, err := userLimits(snap, models.UserLimits{MaxVisits: maxVisits})
+		if err != nil {
+			return nil, err
+		}
+		count, _ := snap.Data()["VisitCount"].(int64)
+		if limits.MaxVisits > 0 && count >= int64(limits.MaxVisits) {
+			return nil, NewLimitError(ErrVisitLimitReached, userID, limits.MaxVisits)
+		}
+	}
+	return func() error {
+		updates := []firestore.Update{{Path: "VisitsRevision", Value: firestore.Increment(1)}}
+		if visitDelta != 0 {
+			updates = append(updates, firestore.Update{
+				Path:  "VisitCount",
+				Value: firestore.Increment(visitDelta),
+			})
+		}
+		if countryDelta != 0 {
+			updates = append(updates, firestore.Update{
+				Path:  "DistinctCountries",
+				Value: firestore.Increment(countryDelta),
+			})
+		}
+		return tx.Update(userRef, updates)
+	}, nil
+}
+
+// countVisitsOf returns how many of the visits in the country_visits collection visits have
+// countryCode, counting up to limit.
+func countVisitsOf(
+	tx *firestore.Transaction,
+	visits *firestore.CollectionRef,
+	countryCode string,
+	limit int,
+) (int, error) {
+	docs, err := tx.Documents(visits.Where("CountryCode", "==", countryCode).Select().Limit(limit)).
+		GetAll()
 	if err != nil {
-		return fmt.Errorf("failed to update country visit: %w", err)
+		return 0, fmt.Errorf("failed to count country visits: %w", err)
 	}
-	return nil
+	return len(docs), nil
 }
 
-// EnsureUser gets or creates the user document with document ID = user.ID (auth token UserID). On create, stores ShareToken, Name, Email, ImageURL, and default Settings. When user already exists, updates ImageURL from the token so avatar changes are reflected.
+// EnsureUser gets or creates the user document with document ID = user.ID (auth token UserID). On create, stores ShareToken, Name, Email, ImageURL, and default Settings.
+// When user already exists, updates ImageURL and (when present) Name from the token so avatar
+// and account name changes are reflected; friends pick them up through GET /friends. A guest
+// account signing in with a provider stops being anonymous.
+// The read and the write run in one transaction, so concurrent first logins of the same user
+// create the document once and cannot replace each other's ShareToken.
 // Only POST /login should call this; auth middleware does not.
 func (c *Client) EnsureUser(ctx context.Context, user *models.User) error {
 	if user == nil || user.ID == "" {
 		return fmt.Errorf("user ID is required")
 	}
 	ref := c.Collection("users").Doc(user.ID)
-	_, err := ref.Get(ctx)
-	if err != nil {
-		if status.Code(err) == codes.NotFound {
-			shareToken := uuid.New().String()
-			defaults := models.DefaultUserSettings()
-			doc := map[string]interface{}{
-				"ShareToken": shareToken,
-				"Name":       user.Name,
-				"Email":      user.Email,
-				"Settings": map[string]interface{}{
-					"Sharing": map[string]interface{}{
-						"ShareMediaURL": defaults.Sharing.ShareMediaURL,
-						"ShareNotes":    defaults.Sharing.ShareNotes,
-						"ShareTags":     defaults.Sharing.ShareTags,
-					},
-				},
-			}
-			if user.ImageURL != "" {
-				doc["ImageURL"] = user.ImageURL
-			}
-			_, err = ref.Set(ctx, doc)
-			if err != nil {
-				return fmt.Errorf("failed to create user: %w", err)
+	created := false
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		created = false
+		_, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) != codes.NotFound {
+				return fmt.Errorf("failed to check user: %w", err)
 			}
-			return nil
+			created = true
+			return tx.Create(ref, newUserDoc(user))
 		}
-		return fmt.Errorf("failed to check user: %w", err)
-	}
-	// Doc exists: always update ImageURL from token so avatar changes are reflected
-	_, err = ref.Update(ctx, []firestore.Update{
-		{Path: "ImageURL", Value: user.ImageURL},
+		// Doc exists: always update ImageURL from token so avatar changes are reflected
+		updates := []firestore.Update{{Path: "ImageURL", Value: user.ImageURL}}
+		if user.Name != "" {
+			updates = append(updates, firestore.Update{Path: "Name", Value: user.Name})
+		}
+		if !user.IsAnonymous {
+			// Linking a guest's Firebase account to a provider keeps its UID
+			updates = append(updates, firestore.Update{Path: "IsAnonymous", Value: firestore.Delete})
+		}
+		return tx.Update(ref, updates)
 	})
 	if err != nil {
-		return fmt.Errorf("failed to update user ImageURL: %w", err)
+		if created {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return fmt.Errorf("failed to update user profile: %w", err)
 	}
 	return nil
 }
 
+// newUserDoc returns the fields of a new users/{id} document for user, with a new ShareToken
+// and default Settings.
+func newUserDoc(user *models.User) map[string]interface{} {
+	defaults := models.DefaultUserSettings()
+	doc := map[string]interface{}{
+		"ShareToken": uuid.New().String(),
+		"Name":       user.Name,
+		"Email":      user.Email,
+		"Settings": map[string]interface{}{
+			"Sharing": map[string]interface{}{
+				"ShareMediaURL": defaults.Sharing.ShareMediaURL,
+				"ShareNotes":    defaults.Sharing.ShareNotes,
+				"ShareTags":     defaults.Sharing.ShareTags,
+			},
+		},
+	}
+	if user.ImageURL != "" {
+		doc["ImageURL"] = user.ImageURL
+	}
+	if user.IsAnonymous {
+		doc["IsAnonymous"] = true
+	}
+	return doc
+}
+
 // applyUserSettingsDefaults sets DefaultUserSettings when Settings was absent in Firestore.
 // If Settings exists but Sharing.ShareTags is missing, defaults ShareTags to true.
 func applyUserSettingsDefaults(u *models.User, data map[string]interface{}) {
@@ -210,6 +589,36 @@ func (c *Client) GetUserByShareToken(ctx context.Context, shareToken string) (*m
 	return &u, nil
 }
 
+// maxInQueryValues is the most values Firestore accepts in one "in" filter.
+const maxInQueryValues = 30
+
+// GetUsersByShareTokens looks up the users with the given ShareTokens in batches of
+// maxInQueryValues and returns them by ShareToken. Tokens without a user are absent.
+func (c *Client) GetUsersByShareTokens(
+	ctx context.Context,
+	shareTokens []string,
+) (map[string]*models.User, error) {
+	users := make(map[string]*models.User, len(shareTokens))
+	for start := 0; start < len(shareTokens); start += maxInQueryValues {
+		chunk := shareTokens[start:min(start+maxInQueryValues, len(shareTokens))]
+		docs, err := c.Collection("users").Where("ShareToken", "in", chunk).Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users by share tokens: %w", err)
+		}
+		for _, doc := range docs {
+			var u models.User
+			if err := doc.DataTo(&u); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+			}
+			u.ID = doc.Ref.ID
+			u.UserID = u.ID
+			applyUserSettingsDefaults(&u, doc.Data())
+			users[u.ShareToken] = &u
+		}
+	}
+	return users, nil
+}
+
 // GetUserByID looks up the User document by ID (auth token UserID). Returns (nil, nil) if not found.
 func (c *Client) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
 	if userID == "" {
@@ -237,65 +646,126 @@ func (c *Client) GetUserByID(ctx context.Context, userID string) (*models.User,
 }
 
 // CreateCountryVisit adds a new country visit document under users/{userID}/country_visits.
-// Document contains CountryCode, VisitTime, optional MediaURL and Tags (user is implied by path).
-func (c *Client) CreateCountryVisit(ctx context.Context, visit *models.CountryVisit) (*models.CountryVisit, error) {
+// Document contains CountryCode, VisitTime and its copy VisitedTime, optional MediaURL and Tags
+// (user is implied by path).
+// Increments the user's VisitsRevision and VisitCount (and DistinctCountries for a new country)
+// and records a created history event in the same transaction. Fails with a LimitError when the
+// user has maxVisits visits, or as many as their models.UserLimits override allows.
+func (c *Client) CreateCountryVisit(
+	ctx context.Context,
+	visit *models.CountryVisit,
+	maxVisits int,
+) (*models.CountryVisit, error) {
 	if visit == nil {
 		return nil, fmt.Errorf("visit is required")
 	}
 	if visit.UserID == "" || visit.CountryCode == "" {
 		return nil, fmt.Errorf("user_id and country_code are required")
 	}
-	tags := visit.Tags
-	if tags == nil {
-		tags = []string{}
-	}
 	ref := c.Collection("users").Doc(visit.UserID).Collection("country_visits").NewDoc()
-	doc := map[string]interface{}{
-		"CountryCode": visit.CountryCode,
-		"VisitTime":   visit.VisitedTime,
-		"Tags":        tags,
-	}
-	if visit.MediaURL != nil && *visit.MediaURL != "" {
-		doc["MediaURL"] = *visit.MediaURL
-	}
-	if visit.Notes != "" {
-		doc["Notes"] = visit.Notes
-	}
-	_, err := ref.Set(ctx, doc)
+	updatedAt := models.NextVisitUpdatedAt(nil, time.Now())
+	stored := *visit
+	stored.UpdatedAt = &updatedAt
+	doc := countryVisitDoc(&stored)
+	actorID := actorIDFromContext(ctx, visit.UserID)
+	event := visitHistoryEvent(models.VisitEventCreated, actorID, nil, doc)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		bump, err := c.prepareVisitWriteBump(tx, visit.UserID, "", visit.CountryCode, maxVisits)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(ref, doc); err != nil {
+			return err
+		}
+		if err := tx.Create(ref.Collection("history").NewDoc(), event); err != nil {
+			return err
+		}
+		return bump()
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create country visit: %w", err)
 	}
-	out := *visit
-	out.Tags = tags
+	out := stored
+	out.Tags = doc["Tags"].([]string)
 	out.ID = ref.ID
 	return &out, nil
 }
 
 // DeleteCountryVisit deletes a country visit by ID from users/{userID}/country_visits.
-// Returns ErrVisitNotFound if the document does not exist.
+// Returns ErrVisitNotFound if the document does not exist. Increments the user's VisitsRevision,
+// adjusts its visit stats, records a deleted history event and deletes the visit's overlaps
+// (both copies) in the same transaction.
 func (c *Client) DeleteCountryVisit(ctx context.Context, visitID string, userID string) error {
 	if visitID == "" || userID == "" {
 		return fmt.Errorf("visitID and userID are required")
 	}
 	ref := c.Collection("users").Doc(userID).Collection("country_visits").Doc(visitID)
-	snap, err := ref.Get(ctx)
-	if err != nil {
-		if status.Code(err) == codes.NotFound {
+	actorID := actorIDFromContext(ctx, userID)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrVisitNotFound
+			}
+			return fmt.Errorf("failed to get country visit: %w", err)
+		}
+		if !snap.Exists() {
 			return ErrVisitNotFound
 		}
-		return fmt.Errorf("failed to get country visit: %w", err)
-	}
-	if !snap.Exists() {
+		overlaps, err := tx.Documents(c.overlapsRef(userID).Where("VisitID", "==", visitID)).
+			GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list overlaps: %w", err)
+		}
+		var overlapRefs []*firestore.DocumentRef
+		var friendUserIDs []string
+		for _, overlap := range overlaps {
+			refs, friendUserID, err := c.overlapPairRefs(overlap)
+			if err != nil {
+				return err
+			}
+			overlapRefs = append(overlapRefs, refs...)
+			friendUserIDs = append(friendUserIDs, friendUserID)
+		}
+		countryCode, _ := snap.Data()["CountryCode"].(string)
+		bump, err := c.prepareVisitWriteBump(tx, userID, countryCode, "", 0)
+		if err != nil {
+			return err
+		}
+		bumpFriends, err := c.prepareVisitsRevisionBumps(tx, friendUserIDs...)
+		if err != nil {
+			return err
+		}
+		for _, r := range overlapRefs {
+			if err := tx.Delete(r); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(ref); err != nil {
+			return err
+		}
+		// History stays readable after the visit document is gone (subcollections are not
+		// deleted with their parent document)
+		event := visitHistoryEvent(models.VisitEventDeleted, actorID, snap.Data(), nil)
+		if err := tx.Create(ref.Collection("history").NewDoc(), event); err != nil {
+			return err
+		}
+		if err := bump(); err != nil {
+			return err
+		}
+		return bumpFriends()
+	})
+	if errors.Is(err, ErrVisitNotFound) {
 		return ErrVisitNotFound
 	}
-	_, err = ref.Delete(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to delete country visit: %w", err)
 	}
 	return nil
 }
 
-// GetFriendsByUser retrieves all friends for a user from users/{userID}/friends.
+// GetFriendsByUser retrieves all friends for a user from users/{userID}/friends; their number is
+// bounded by the friend limit of AcceptFriendRequest. Use GetFriendsPage for listings.
 // Returns a nil slice (not error) when the user has no friends.
 func (c *Client) GetFriendsByUser(ctx context.Context, userID string) ([]models.Friend, error) {
 	if userID == "" {
@@ -323,63 +793,204 @@ func (c *Client) GetFriendsByUser(ctx context.Context, userID string) ([]models.
 	return friends, nil
 }
 
-// AddFriend adds a friend by ShareToken, Name, and ImageURL under users/{userID}/friends.
-// Returns ErrFriendAlreadyExists if a friend with that ShareToken already exists.
-func (c *Client) AddFriend(ctx context.Context, userID string, shareToken, name, imageURL string) (models.Friend, error) {
-	if userID == "" || shareToken == "" || name == "" {
-		return models.Friend{}, fmt.Errorf("userID, shareToken and name are required")
+// GetFriendsPage returns up to limit friends of userID in document ID order, starting after the
+// friend with document ID cursor (from the start when empty). nextCursor is the ID of the last
+// friend returned when the page is full, and empty on the last page.
+func (c *Client) GetFriendsPage(
+	ctx context.Context,
+	userID, cursor string,
+	limit int,
+) (friends []models.Friend, nextCursor string, err error) {
+	if userID == "" || limit <= 0 {
+		return nil, "", fmt.Errorf("userID and a positive limit are required")
 	}
-	coll := c.Collection("users").Doc(userID).Collection("friends")
-	iter := coll.Where("ShareToken", "==", shareToken).Limit(1).Documents(ctx)
-	docSnap, err := iter.Next()
-	iter.Stop()
-	if err != nil && err != iterator.Done {
-		return models.Friend{}, fmt.Errorf("failed to check existing friend: %w", err)
+	q := c.Collection("users").Doc(userID).Collection("friends").
+		OrderBy(firestore.DocumentID, firestore.Asc).Limit(limit)
+	if cursor != "" {
+		q = q.StartAfter(cursor)
+	}
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list friends: %w", err)
+	}
+	friends = make([]models.Friend, 0, len(docs))
+	for _, doc := range docs {
+		var f models.Friend
+		if err := doc.DataTo(&f); err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal friend: %w", err)
+		}
+		f.ID = doc.Ref.ID
+		friends = append(friends, f)
 	}
-	if err == nil && docSnap.Exists() {
-		return models.Friend{}, ErrFriendAlreadyExists
+	if len(docs) == limit {
+		nextCursor = docs[len(docs)-1].Ref.ID
 	}
+	return friends, nextCursor, nil
+}
 
-	ref := coll.NewDoc()
-	doc := map[string]interface{}{
-		"ShareToken": shareToken,
-		"Name":       name,
+// GetFriendByShareToken returns the friend with shareToken from users/{userID}/friends, or
+// nil (not error) when the user has no such friend.
+func (c *Client) GetFriendByShareToken(
+	ctx context.Context,
+	userID, shareToken string,
+) (*models.Friend, error) {
+	if userID == "" || shareToken == "" {
+		return nil, fmt.Errorf("userID and shareToken are required")
 	}
-	if imageURL != "" {
-		doc["ImageURL"] = imageURL
+	iter := c.Collection("users").Doc(userID).Collection("friends").
+		Where("ShareToken", "==", shareToken).Limit(1).Documents(ctx)
+	defer iter.Stop()
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
 	}
-	_, err = ref.Set(ctx, doc)
 	if err != nil {
-		return models.Friend{}, fmt.Errorf("failed to create friend: %w", err)
+		return nil, fmt.Errorf("failed to find friend: %w", err)
+	}
+	var f models.Friend
+	if err := doc.DataTo(&f); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal friend: %w", err)
 	}
-	return models.Friend{ID: ref.ID, ShareToken: shareToken, Name: name, ImageURL: imageURL}, nil
+	f.ID = doc.Ref.ID
+	return &f, nil
 }
 
-// DeleteFriendByShareToken deletes a friend by ShareToken from users/{userID}/friends.
+// DeleteFriendByShareToken deletes a friend by ShareToken from users/{userID}/friends and, in
+// the same transaction, userID from the friend's followers.
 // Returns ErrFriendNotFound when no document with that ShareToken exists.
 func (c *Client) DeleteFriendByShareToken(ctx context.Context, userID, shareToken string) error {
 	if userID == "" || shareToken == "" {
 		return fmt.Errorf("userID and shareToken are required")
 	}
 	coll := c.Collection("users").Doc(userID).Collection("friends")
-	iter := coll.Where("ShareToken", "==", shareToken).Limit(1).Documents(ctx)
-	docSnap, err := iter.Next()
-	if err == iterator.Done {
-		iter.Stop()
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		friendDocs, err := tx.Documents(coll.Where("ShareToken", "==", shareToken).Limit(1)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to find friend: %w", err)
+		}
+		if len(friendDocs) == 0 {
+			return ErrFriendNotFound
+		}
+		// The friend's account may be gone; then there is no followers entry to delete
+		userDocs, err := tx.Documents(c.Collection("users").
+			Where("ShareToken", "==", shareToken).Limit(1)).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to get user by share token: %w", err)
+		}
+		if err := tx.Delete(friendDocs[0].Ref); err != nil {
+			return err
+		}
+		if len(userDocs) > 0 {
+			return tx.Delete(c.followerRef(userDocs[0].Ref.ID, userID))
+		}
+		return nil
+	})
+	if errors.Is(err, ErrFriendNotFound) {
 		return ErrFriendNotFound
 	}
 	if err != nil {
-		iter.Stop()
-		return fmt.Errorf("failed to find friend: %w", err)
+		return fmt.Errorf("failed to delete friend: %w", err)
 	}
-	if !docSnap.Exists() {
-		iter.Stop()
-		return ErrFriendNotFound
+	return nil
+}
+
+// UpdateFriendProfiles writes Name, ImageURL and SyncedAt of the given friends (by ID) in
+// users/{userID}/friends in one batch. Friends deleted meanwhile are skipped.
+func (c *Client) UpdateFriendProfiles(
+	ctx context.Context,
+	userID string,
+	friends []models.Friend,
+) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
 	}
-	iter.Stop()
-	_, err = docSnap.Ref.Delete(ctx)
+	if len(friends) == 0 {
+		return nil
+	}
+	coll := c.Collection("users").Doc(userID).Collection("friends")
+	bw := c.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(friends))
+	for _, f := range friends {
+		job, err := bw.Update(coll.Doc(f.ID), []firestore.Update{
+			{Path: "Name", Value: f.Name},
+			{Path: "ImageURL", Value: f.ImageURL},
+			{Path: "SyncedAt", Value: f.SyncedAt},
+		})
+		if err != nil {
+			bw.End()
+			return fmt.Errorf("failed to queue friend update: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil && status.Code(err) != codes.NotFound {
+			return fmt.Errorf("failed to update friend: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpdateFriendNickname sets Nickname of the friend with shareToken in users/{userID}/friends
+// (an empty nickname clears it) and returns the updated friend. Returns ErrFriendNotFound when
+// no such friend exists.
+func (c *Client) UpdateFriendNickname(
+	ctx context.Context,
+	userID, shareToken, nickname string,
+) (*models.Friend, error) {
+	friend, err := c.GetFriendByShareToken(ctx, userID, shareToken)
 	if err != nil {
-		return fmt.Errorf("failed to delete friend: %w", err)
+		return nil, err
+	}
+	if friend == nil {
+		return nil, ErrFriendNotFound
+	}
+	var value any = nickname
+	if nickname == "" {
+		value = firestore.Delete
+	}
+	ref := c.Collection("users").Doc(userID).Collection("friends").Doc(friend.ID)
+	_, err = ref.Update(ctx, []firestore.Update{{Path: "Nickname", Value: value}})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrFriendNotFound
+		}
+		return nil, fmt.Errorf("failed to update friend nickname: %w", err)
+	}
+	friend.Nickname = nickname
+	return friend, nil
+}
+
+// SetSharingDisabled sets SharingDisabled on users/{userID}. Returns ErrUserNotFound if missing.
+func (c *Client) SetSharingDisabled(ctx context.Context, userID string, disabled bool) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	_, err := c.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
+		{Path: "SharingDisabled", Value: disabled},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to update sharing: %w", err)
+	}
+	return nil
+}
+
+// SetTokensRevokedAt sets TokensRevokedAt on users/{userID}. Returns ErrUserNotFound if missing.
+func (c *Client) SetTokensRevokedAt(ctx context.Context, userID string, at time.Time) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	_, err := c.Collection("users").Doc(userID).Update(ctx, []firestore.Update{
+		{Path: "TokensRevokedAt", Value: at},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to revoke tokens: %w", err)
 	}
 	return nil
 }
@@ -417,6 +1028,19 @@ func (c *Client) UpdateUserSettings(
 	if settings.Description != "" {
 		settingsDoc["Description"] = settings.Description
 	}
+	if d := settings.VisitDefaults; d != nil {
+		defaultsDoc := map[string]interface{}{"IsPrivate": d.IsPrivate}
+		if d.Dedupe != "" {
+			defaultsDoc["Dedupe"] = d.Dedupe
+		}
+		if d.VisitType != "" {
+			defaultsDoc["VisitType"] = d.VisitType
+		}
+		settingsDoc["VisitDefaults"] = defaultsDoc
+	}
+	if settings.IncludeTerritories {
+		settingsDoc["IncludeTerritories"] = true
+	}
 	_, err = ref.Update(ctx, []firestore.Update{
 		{Path: "Settings", Value: settingsDoc},
 	})
@@ -428,5 +1052,3 @@ func (c *Client) UpdateUserSettings(
 	}
 	return nil
 }
-
-
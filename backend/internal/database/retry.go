@@ -0,0 +1,538 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const (
+	// retryBaseDelay is the most a read waits before its first retry; the bound doubles for each
+	// further retry and the wait is uniformly random below it.
+	retryBaseDelay = 100 * time.Millisecond
+
+	// retryMaxDelay caps the bound of retryBaseDelay.
+	retryMaxDelay = 2 * time.Second
+)
+
+// RetryingClient decorates a Client with a deadline per attempt and retries of its idempotent
+// reads (Get*, Find*, IsBlocked and ListUserIDs) failing with a transient error (Unavailable or
+// DeadlineExceeded), so a Firestore hiccup doesn't fail a request or a background job. Writes
+// are the embedded Client's, unchanged: a write that timed out may still have been applied.
+// Each retry adds a db.retry event to the span in the call's context, and a retried call sets
+// the span attribute db.<method>.retries to its retry count.
+type RetryingClient struct {
+	*Client
+	timeout time.Duration
+	retries int
+}
+
+// NewRetryingClient returns c with reads retried up to retries times, each attempt with a
+// deadline of timeout (0: only the caller's).
+func NewRetryingClient(c *Client, timeout time.Duration, retries int) *RetryingClient {
+	return &RetryingClient{Client: c, timeout: timeout, retries: retries}
+}
+
+func (c *RetryingClient) withTimeout(
+	ctx context.Context,
+) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// retryRead calls read with a deadline of c.timeout per attempt until it succeeds, fails with
+// a non-transient error, runs out of retries or ctx is done.
+func retryRead[T any](
+	ctx context.Context,
+	c *RetryingClient,
+	method string,
+	read func(ctx context.Context) (T, error),
+) (T, error) {
+	span := oteltrace.SpanFromContext(ctx)
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := c.withTimeout(ctx)
+		v, err := read(attemptCtx)
+		cancel()
+		if err == nil || attempt >= c.retries || !isTransient(err) || ctx.Err() != nil {
+			if attempt > 0 {
+				span.SetAttributes(attribute.Int("db."+method+".retries", attempt))
+			}
+			return v, err
+		}
+
+		span.AddEvent("db.retry", oteltrace.WithAttributes(
+			attribute.String("db.method", method),
+			attribute.Int("db.attempt", attempt+1),
+			attribute.String("error", err.Error()),
+		))
+		logging.FromContext(ctx).Warn("Retrying database read",
+			logging.DBMethod, method, logging.Attempt, attempt+1, logging.Error, err)
+		t := time.NewTimer(rand.N(min(retryBaseDelay<<attempt, retryMaxDelay)))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return v, err
+		}
+	}
+}
+
+// isTransient reports whether err is worth retrying: the database was briefly unavailable or
+// the attempt ran out of time.
+func isTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+func (c *RetryingClient) GetCountryVisitsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.CountryVisit, error) {
+	return retryRead(ctx, c, "GetCountryVisitsByUser",
+		func(ctx context.Context) ([]models.CountryVisit, error) {
+			return c.Client.GetCountryVisitsByUser(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetPublicCountryVisitsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.CountryVisit, error) {
+	return retryRead(ctx, c, "GetPublicCountryVisitsByUser",
+		func(ctx context.Context) ([]models.CountryVisit, error) {
+			return c.Client.GetPublicCountryVisitsByUser(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetCountryVisitsPage(
+	ctx context.Context,
+	userID, cursor string,
+	limit int,
+) ([]models.CountryVisit, string, error) {
+	var next string
+	visits, err := retryRead(ctx, c, "GetCountryVisitsPage",
+		func(ctx context.Context) (visits []models.CountryVisit, err error) {
+			visits, next, err = c.Client.GetCountryVisitsPage(ctx, userID, cursor, limit)
+			return visits, err
+		})
+	return visits, next, err
+}
+
+func (c *RetryingClient) GetFeedVisits(
+	ctx context.Context,
+	userIDs []string,
+	after *models.FeedCursor,
+	limit int,
+) ([]models.CountryVisit, error) {
+	return retryRead(ctx, c, "GetFeedVisits",
+		func(ctx context.Context) ([]models.CountryVisit, error) {
+			return c.Client.GetFeedVisits(ctx, userIDs, after, limit)
+		})
+}
+
+func (c *RetryingClient) GetVisitedCountryCodes(
+	ctx context.Context,
+	userID string,
+	countryCodes []string,
+) ([]string, error) {
+	return retryRead(ctx, c, "GetVisitedCountryCodes",
+		func(ctx context.Context) ([]string, error) {
+			return c.Client.GetVisitedCountryCodes(ctx, userID, countryCodes)
+		})
+}
+
+func (c *RetryingClient) GetVisitSummariesForUsers(
+	ctx context.Context,
+	userIDs []string,
+) (map[string]models.FriendVisitSummary, error) {
+	return retryRead(ctx, c, "GetVisitSummariesForUsers",
+		func(ctx context.Context) (map[string]models.FriendVisitSummary, error) {
+			return c.Client.GetVisitSummariesForUsers(ctx, userIDs)
+		})
+}
+
+func (c *RetryingClient) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	return retryRead(ctx, c, "GetUserByID",
+		func(ctx context.Context) (*models.User, error) {
+			return c.Client.GetUserByID(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetUserByShareToken(
+	ctx context.Context,
+	shareToken string,
+) (*models.User, error) {
+	return retryRead(ctx, c, "GetUserByShareToken",
+		func(ctx context.Context) (*models.User, error) {
+			return c.Client.GetUserByShareToken(ctx, shareToken)
+		})
+}
+
+func (c *RetryingClient) GetUsersByShareTokens(
+	ctx context.Context,
+	shareTokens []string,
+) (map[string]*models.User, error) {
+	return retryRead(ctx, c, "GetUsersByShareTokens",
+		func(ctx context.Context) (map[string]*models.User, error) {
+			return c.Client.GetUsersByShareTokens(ctx, shareTokens)
+		})
+}
+
+func (c *RetryingClient) GetUserByHandle(ctx context.Context, handle string) (*models.User, error) {
+	return retryRead(ctx, c, "GetUserByHandle",
+		func(ctx context.Context) (*models.User, error) {
+			return c.Client.GetUserByHandle(ctx, handle)
+		})
+}
+
+func (c *RetryingClient) GetShareStats(
+	ctx context.Context,
+	userID string,
+	tokens []string,
+) ([]models.ShareStats, error) {
+	return retryRead(ctx, c, "GetShareStats",
+		func(ctx context.Context) ([]models.ShareStats, error) {
+			return c.Client.GetShareStats(ctx, userID, tokens)
+		})
+}
+
+func (c *RetryingClient) GetShareLinks(
+	ctx context.Context,
+	userID string,
+) ([]models.ShareLink, error) {
+	return retryRead(ctx, c, "GetShareLinks",
+		func(ctx context.Context) ([]models.ShareLink, error) {
+			return c.Client.GetShareLinks(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetShareLinkByToken(
+	ctx context.Context,
+	token string,
+) (*models.ShareLink, error) {
+	return retryRead(ctx, c, "GetShareLinkByToken",
+		func(ctx context.Context) (*models.ShareLink, error) {
+			return c.Client.GetShareLinkByToken(ctx, token)
+		})
+}
+
+func (c *RetryingClient) GetAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	return retryRead(ctx, c, "GetAPIKeys",
+		func(ctx context.Context) ([]models.APIKey, error) {
+			return c.Client.GetAPIKeys(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetAPIKeyByHash(
+	ctx context.Context,
+	keyHash string,
+) (*models.APIKey, error) {
+	return retryRead(ctx, c, "GetAPIKeyByHash",
+		func(ctx context.Context) (*models.APIKey, error) {
+			return c.Client.GetAPIKeyByHash(ctx, keyHash)
+		})
+}
+
+func (c *RetryingClient) GetAuditEvents(
+	ctx context.Context,
+	userID string,
+	limit int,
+) ([]models.AuditEvent, error) {
+	return retryRead(ctx, c, "GetAuditEvents",
+		func(ctx context.Context) ([]models.AuditEvent, error) {
+			return c.Client.GetAuditEvents(ctx, userID, limit)
+		})
+}
+
+func (c *RetryingClient) GetCountryVisit(
+	ctx context.Context,
+	visitID, userID string,
+) (*models.CountryVisit, error) {
+	return retryRead(ctx, c, "GetCountryVisit",
+		func(ctx context.Context) (*models.CountryVisit, error) {
+			return c.Client.GetCountryVisit(ctx, visitID, userID)
+		})
+}
+
+func (c *RetryingClient) GetCountryVisitHistory(
+	ctx context.Context,
+	visitID, userID string,
+) ([]models.VisitHistoryEvent, error) {
+	return retryRead(ctx, c, "GetCountryVisitHistory",
+		func(ctx context.Context) ([]models.VisitHistoryEvent, error) {
+			return c.Client.GetCountryVisitHistory(ctx, visitID, userID)
+		})
+}
+
+func (c *RetryingClient) GetVisitOverlaps(
+	ctx context.Context,
+	userID string,
+) ([]models.VisitOverlap, error) {
+	return retryRead(ctx, c, "GetVisitOverlaps",
+		func(ctx context.Context) ([]models.VisitOverlap, error) {
+			return c.Client.GetVisitOverlaps(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetInsights(ctx context.Context, userID string) (*models.Insights, error) {
+	return retryRead(ctx, c, "GetInsights",
+		func(ctx context.Context) (*models.Insights, error) {
+			return c.Client.GetInsights(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetFriendsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.Friend, error) {
+	return retryRead(ctx, c, "GetFriendsByUser",
+		func(ctx context.Context) ([]models.Friend, error) {
+			return c.Client.GetFriendsByUser(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetFriendsPage(
+	ctx context.Context,
+	userID, cursor string,
+	limit int,
+) ([]models.Friend, string, error) {
+	var next string
+	friends, err := retryRead(ctx, c, "GetFriendsPage",
+		func(ctx context.Context) (friends []models.Friend, err error) {
+			friends, next, err = c.Client.GetFriendsPage(ctx, userID, cursor, limit)
+			return friends, err
+		})
+	return friends, next, err
+}
+
+func (c *RetryingClient) GetFriendByShareToken(
+	ctx context.Context,
+	userID, shareToken string,
+) (*models.Friend, error) {
+	return retryRead(ctx, c, "GetFriendByShareToken",
+		func(ctx context.Context) (*models.Friend, error) {
+			return c.Client.GetFriendByShareToken(ctx, userID, shareToken)
+		})
+}
+
+func (c *RetryingClient) GetFollowers(
+	ctx context.Context,
+	userID string,
+) ([]models.Follower, error) {
+	return retryRead(ctx, c, "GetFollowers",
+		func(ctx context.Context) ([]models.Follower, error) {
+			return c.Client.GetFollowers(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetFriendInvites(
+	ctx context.Context,
+	userID string,
+) ([]models.FriendInvite, error) {
+	return retryRead(ctx, c, "GetFriendInvites",
+		func(ctx context.Context) ([]models.FriendInvite, error) {
+			return c.Client.GetFriendInvites(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) FindFriendInvite(
+	ctx context.Context,
+	userID, email string,
+) (*models.FriendInvite, error) {
+	return retryRead(ctx, c, "FindFriendInvite",
+		func(ctx context.Context) (*models.FriendInvite, error) {
+			return c.Client.FindFriendInvite(ctx, userID, email)
+		})
+}
+
+func (c *RetryingClient) GetFriendRequests(
+	ctx context.Context,
+	userID string,
+) (incoming, outgoing []models.FriendRequest, err error) {
+	incoming, err = retryRead(ctx, c, "GetFriendRequests",
+		func(ctx context.Context) (in []models.FriendRequest, err error) {
+			in, outgoing, err = c.Client.GetFriendRequests(ctx, userID)
+			return in, err
+		})
+	return incoming, outgoing, err
+}
+
+func (c *RetryingClient) GetFriendRequest(
+	ctx context.Context,
+	requestID, userID string,
+) (*models.FriendRequest, error) {
+	return retryRead(ctx, c, "GetFriendRequest",
+		func(ctx context.Context) (*models.FriendRequest, error) {
+			return c.Client.GetFriendRequest(ctx, requestID, userID)
+		})
+}
+
+func (c *RetryingClient) GetBlockedUsers(
+	ctx context.Context,
+	userID string,
+) ([]models.BlockedUser, error) {
+	return retryRead(ctx, c, "GetBlockedUsers",
+		func(ctx context.Context) ([]models.BlockedUser, error) {
+			return c.Client.GetBlockedUsers(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error) {
+	return retryRead(ctx, c, "IsBlocked",
+		func(ctx context.Context) (bool, error) {
+			return c.Client.IsBlocked(ctx, userID, otherUserID)
+		})
+}
+
+func (c *RetryingClient) GetOrganization(
+	ctx context.Context,
+	orgID string,
+) (*models.Organization, error) {
+	return retryRead(ctx, c, "GetOrganization",
+		func(ctx context.Context) (*models.Organization, error) {
+			return c.Client.GetOrganization(ctx, orgID)
+		})
+}
+
+func (c *RetryingClient) GetOrganizationByShareToken(
+	ctx context.Context,
+	shareToken string,
+) (*models.Organization, error) {
+	return retryRead(ctx, c, "GetOrganizationByShareToken",
+		func(ctx context.Context) (*models.Organization, error) {
+			return c.Client.GetOrganizationByShareToken(ctx, shareToken)
+		})
+}
+
+func (c *RetryingClient) GetOrganizationMembers(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationMember, error) {
+	return retryRead(ctx, c, "GetOrganizationMembers",
+		func(ctx context.Context) ([]models.OrganizationMember, error) {
+			return c.Client.GetOrganizationMembers(ctx, orgID)
+		})
+}
+
+func (c *RetryingClient) GetOrganizationMember(
+	ctx context.Context,
+	orgID, userID string,
+) (*models.OrganizationMember, error) {
+	return retryRead(ctx, c, "GetOrganizationMember",
+		func(ctx context.Context) (*models.OrganizationMember, error) {
+			return c.Client.GetOrganizationMember(ctx, orgID, userID)
+		})
+}
+
+func (c *RetryingClient) GetOrganizationsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.OrganizationMembership, error) {
+	return retryRead(ctx, c, "GetOrganizationsByUser",
+		func(ctx context.Context) ([]models.OrganizationMembership, error) {
+			return c.Client.GetOrganizationsByUser(ctx, userID)
+		})
+}
+
+func (c *RetryingClient) GetOrganizationInvitations(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationInvitation, error) {
+	return retryRead(ctx, c, "GetOrganizationInvitations",
+		func(ctx context.Context) ([]models.OrganizationInvitation, error) {
+			return c.Client.GetOrganizationInvitations(ctx, orgID)
+		})
+}
+
+func (c *RetryingClient) GetOrganizationInvitation(
+	ctx context.Context,
+	code string,
+) (*models.OrganizationInvitation, error) {
+	return retryRead(ctx, c, "GetOrganizationInvitation",
+		func(ctx context.Context) (*models.OrganizationInvitation, error) {
+			return c.Client.GetOrganizationInvitation(ctx, code)
+		})
+}
+
+func (c *RetryingClient) GetOrganizationGoals(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationGoal, error) {
+	return retryRead(ctx, c, "GetOrganizationGoals",
+		func(ctx context.Context) ([]models.OrganizationGoal, error) {
+			return c.Client.GetOrganizationGoals(ctx, orgID)
+		})
+}
+
+func (c *RetryingClient) GetOrgShareLinks(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrgShareLink, error) {
+	return retryRead(ctx, c, "GetOrgShareLinks",
+		func(ctx context.Context) ([]models.OrgShareLink, error) {
+			return c.Client.GetOrgShareLinks(ctx, orgID)
+		})
+}
+
+func (c *RetryingClient) GetOrgShareLink(
+	ctx context.Context,
+	token string,
+) (*models.OrgShareLink, error) {
+	return retryRead(ctx, c, "GetOrgShareLink",
+		func(ctx context.Context) (*models.OrgShareLink, error) {
+			return c.Client.GetOrgShareLink(ctx, token)
+		})
+}
+
+func (c *RetryingClient) GetUsersDueForDeletion(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]string, error) {
+	return retryRead(ctx, c, "GetUsersDueForDeletion",
+		func(ctx context.Context) ([]string, error) {
+			return c.Client.GetUsersDueForDeletion(ctx, now, limit)
+		})
+}
+
+func (c *RetryingClient) GetBackfillJob(
+	ctx context.Context,
+	name string,
+) (*models.BackfillJob, error) {
+	return retryRead(ctx, c, "GetBackfillJob",
+		func(ctx context.Context) (*models.BackfillJob, error) {
+			return c.Client.GetBackfillJob(ctx, name)
+		})
+}
+
+func (c *RetryingClient) ListUserIDs(
+	ctx context.Context,
+	afterID string,
+	limit int,
+) ([]string, error) {
+	return retryRead(ctx, c, "ListUserIDs",
+		func(ctx context.Context) ([]string, error) {
+			return c.Client.ListUserIDs(ctx, afterID, limit)
+		})
+}
+
+func (c *RetryingClient) GetCountryOverrides(ctx context.Context) ([]models.CountryOverride, error) {
+	return retryRead(ctx, c, "GetCountryOverrides",
+		func(ctx context.Context) ([]models.CountryOverride, error) {
+			return c.Client.GetCountryOverrides(ctx)
+		})
+}
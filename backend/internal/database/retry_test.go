@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryRead(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	notFound := status.Error(codes.NotFound, "not found")
+	tests := []struct {
+		name      string
+		errs      []error // returned by successive attempts; nil after the last
+		retries   int
+		wantCalls int
+		wantErr   error
+	}{
+		{"success", nil, 3, 1, nil},
+		{"transient then success", []error{unavailable, unavailable}, 3, 3, nil},
+		{"retries exhausted", []error{unavailable, unavailable, unavailable}, 2, 3, unavailable},
+		{"not transient", []error{notFound}, 3, 1, notFound},
+		{"retries disabled", []error{unavailable}, 0, 1, unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewRetryingClient(nil, 0, tt.retries)
+			calls := 0
+			got, err := retryRead(context.Background(), c, "Test",
+				func(ctx context.Context) (int, error) {
+					calls++
+					if calls <= len(tt.errs) {
+						return 0, tt.errs[calls-1]
+					}
+					return 42, nil
+				})
+			if calls != tt.wantCalls {
+				t.Errorf("read called %d times, want %d", calls, tt.wantCalls)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != 42 {
+				t.Errorf("got %d, want 42", got)
+			}
+		})
+	}
+}
+
+// TestRetryReadDeadline checks that each attempt gets its own deadline and that an attempt
+// running out of time is retried.
+func TestRetryReadDeadline(t *testing.T) {
+	c := NewRetryingClient(nil, 20*time.Millisecond, 1)
+	calls := 0
+	_, err := retryRead(context.Background(), c, "Test", func(ctx context.Context) (int, error) {
+		calls++
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("attempt has no deadline")
+		}
+		if calls == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 1, nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("err = %v after %d calls, want success on the second", err, calls)
+	}
+}
+
+func TestRetryReadStopsWhenCanceled(t *testing.T) {
+	c := NewRetryingClient(nil, 0, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := retryRead(ctx, c, "Test", func(ctx context.Context) (int, error) {
+		calls++
+		cancel()
+		return 0, status.Error(codes.Unavailable, "unavailable")
+	})
+	if calls != 1 || status.Code(err) != codes.Unavailable {
+		t.Errorf("err = %v after %d calls, want the first error without retries", err, calls)
+	}
+}
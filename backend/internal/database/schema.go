@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/migrations"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// upgradeDoc brings doc, a document of collection, to the latest schema version (see
+// internal/migrations). An outdated document is migrated, written back unless it changed after
+// doc was read, and returned as read again; a current one is returned as is.
+func (c *Client) upgradeDoc(
+	ctx context.Context,
+	collection string,
+	doc *firestore.DocumentSnapshot,
+) (*firestore.DocumentSnapshot, error) {
+	data := doc.Data()
+	changed, err := migrations.Upgrade(collection, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate %s: %w", doc.Ref.Path, err)
+	}
+	if !changed {
+		return doc, nil
+	}
+	updates := schemaUpdates(doc.Data(), data)
+	_, err = doc.Ref.Update(ctx, updates, firestore.LastUpdateTime(doc.UpdateTime))
+	// On a concurrent change the document is read again as is; the next read upgrades it
+	if err != nil && status.Code(err) != codes.FailedPrecondition {
+		return nil, fmt.Errorf("failed to write migrated document: %w", err)
+	}
+	fresh, err := doc.Ref.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migrated document: %w", err)
+	}
+	return fresh, nil
+}
+
+// schemaUpdates returns the updates turning the fields before into after.
+func schemaUpdates(before, after map[string]any) []firestore.Update {
+	var updates []firestore.Update
+	for k, v := range after {
+		if old, ok := before[k]; !ok || !reflect.DeepEqual(old, v) {
+			updates = append(updates, firestore.Update{FieldPath: firestore.FieldPath{k}, Value: v})
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			updates = append(updates, firestore.Update{
+				FieldPath: firestore.FieldPath{k},
+				Value:     firestore.Delete,
+			})
+		}
+	}
+	return updates
+}
+
+// countryVisitFromDoc decodes doc, a country visit of userID, upgrading it first.
+func (c *Client) countryVisitFromDoc(
+	ctx context.Context,
+	userID string,
+	doc *firestore.DocumentSnapshot,
+) (models.CountryVisit, error) {
+	var visit models.CountryVisit
+	doc, err := c.upgradeDoc(ctx, migrations.CountryVisits, doc)
+	if err != nil {
+		return visit, err
+	}
+	if err := doc.DataTo(&visit); err != nil {
+		return visit, fmt.Errorf("failed to unmarshal country visit: %w", err)
+	}
+	if visit.Tags == nil {
+		visit.Tags = []string{}
+	}
+	visit.Verified = len(visit.Proofs) > 0
+	visit.ID = doc.Ref.ID
+	visit.UserID = userID
+	return visit, nil
+}
+
+// MigrateUserSchema upgrades the user's country visits to the latest schema version, for the
+// schema-migrations backfill job. Current documents are only read, so it is idempotent.
+func (c *Client) MigrateUserSchema(ctx context.Context, userID string) error {
+	docs, err := c.Collection("users").Doc(userID).Collection("country_visits").
+		Documents(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to get country visits: %w", err)
+	}
+	for _, doc := range docs {
+		if migrations.Version(doc.Data()) >= migrations.Latest(migrations.CountryVisits) {
+			continue
+		}
+		if _, err := c.upgradeDoc(ctx, migrations.CountryVisits, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
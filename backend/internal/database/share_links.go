@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var (
+	ErrShareLinkNotFound = errors.New("share link not found")
+	ErrTooManyShareLinks = errors.New("too many share links")
+)
+
+func (c *Client) shareLinksRef(userID string) *firestore.CollectionRef {
+	return c.Collection("users").Doc(userID).Collection("share_links")
+}
+
+// CreateShareLink stores a share link of link.UserID with a fresh token. Returns
+// ErrTooManyShareLinks when the user has models.MaxShareLinks unexpired links.
+func (c *Client) CreateShareLink(
+	ctx context.Context,
+	link *models.ShareLink,
+) (*models.ShareLink, error) {
+	if link == nil || link.UserID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	existing, err := c.GetShareLinks(ctx, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxShareLinks {
+		return nil, ErrTooManyShareLinks
+	}
+	out := *link
+	out.Token = uuid.New().String()
+	out.CreatedAt = time.Now().UTC()
+	out.Views = 0
+	if _, err := c.shareLinksRef(out.UserID).Doc(out.Token).Create(ctx, &out); err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return &out, nil
+}
+
+// GetShareLinks returns the unexpired share links of userID, newest first. Links whose views
+// are used up are included.
+func (c *Client) GetShareLinks(ctx context.Context, userID string) ([]models.ShareLink, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	docs, err := c.shareLinksRef(userID).Where("ExpiresAt", ">", time.Now().UTC()).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	links := make([]models.ShareLink, 0, len(docs))
+	for _, doc := range docs {
+		var l models.ShareLink
+		if err := doc.DataTo(&l); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal share link: %w", err)
+		}
+		links = append(links, l)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].CreatedAt.After(links[j].CreatedAt)
+	})
+	return links, nil
+}
+
+// GetShareLinkByToken returns the share link with token of any user, or nil (not error) when
+// there is none. Expired links are returned too; see models.ShareLink.Active.
+func (c *Client) GetShareLinkByToken(ctx context.Context, token string) (*models.ShareLink, error) {
+	if token == "" {
+		return nil, nil
+	}
+	// Tokens are unique across users; the collection group query finds the owner
+	docs, err := c.CollectionGroup("share_links").Where("Token", "==", token).Limit(1).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find share link: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	var link models.ShareLink
+	if err := docs[0].DataTo(&link); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal share link: %w", err)
+	}
+	return &link, nil
+}
+
+// CountShareLinkView counts a view of the share link token of userID in a transaction. Returns
+// ErrShareLinkNotFound when the link is gone or no longer active at now, e.g. because
+// concurrent views used up its MaxViews.
+func (c *Client) CountShareLinkView(
+	ctx context.Context,
+	userID, token string,
+	now time.Time,
+) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	ref := c.shareLinksRef(userID).Doc(token)
+	err := c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrShareLinkNotFound
+			}
+			return err
+		}
+		var link models.ShareLink
+		if err := snap.DataTo(&link); err != nil {
+			return fmt.Errorf("failed to unmarshal share link: %w", err)
+		}
+		if !link.Active(now) {
+			return ErrShareLinkNotFound
+		}
+		return tx.Update(ref, []firestore.Update{{Path: "Views", Value: firestore.Increment(1)}})
+	})
+	if errors.Is(err, ErrShareLinkNotFound) {
+		return ErrShareLinkNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to count share link view: %w", err)
+	}
+	return nil
+}
+
+// DeleteShareLink revokes the share link token of userID. Returns ErrShareLinkNotFound if it
+// does not exist.
+func (c *Client) DeleteShareLink(ctx context.Context, userID, token string) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	_, err := c.shareLinksRef(userID).Doc(token).Delete(ctx, firestore.Exists)
+	if status.Code(err) == codes.NotFound {
+		return ErrShareLinkNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete share link: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// RecordShareView counts a view of token, a share token of userID, at now in
+// users/{userID}/share_stats/{token}: the total, the day's bucket and the last view time.
+func (c *Client) RecordShareView(ctx context.Context, userID, token string, now time.Time) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	ref := c.Collection("users").Doc(userID).Collection("share_stats").Doc(token)
+	_, err := ref.Set(ctx, map[string]interface{}{
+		"Views":        firestore.Increment(1),
+		"LastViewedAt": now,
+		"Days":         map[string]interface{}{models.ShareStatsDate(now): firestore.Increment(1)},
+	}, firestore.MergeAll)
+	if err != nil {
+		return fmt.Errorf("failed to record share view: %w", err)
+	}
+	return nil
+}
+
+// GetShareStats returns the view statistics of tokens, share tokens of userID, in the order of
+// tokens. Tokens never viewed have zero Views. Daily is not set.
+func (c *Client) GetShareStats(
+	ctx context.Context,
+	userID string,
+	tokens []string,
+) ([]models.ShareStats, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	stats := c.Collection("users").Doc(userID).Collection("share_stats")
+	refs := make([]*firestore.DocumentRef, len(tokens))
+	for i, token := range tokens {
+		refs[i] = stats.Doc(token)
+	}
+	snaps, err := c.GetAll(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share stats: %w", err)
+	}
+	out := make([]models.ShareStats, len(tokens))
+	for i, snap := range snaps {
+		if snap.Exists() {
+			if err := snap.DataTo(&out[i]); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal share stats: %w", err)
+			}
+		}
+		out[i].Token = tokens[i]
+	}
+	return out, nil
+}
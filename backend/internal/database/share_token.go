@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// RotateShareToken gives userID a new random ShareToken in one transaction, so the old share
+// link stops working. The users who have userID as a friend (its followers) either get their
+// Friend entry and visit companions moved to the new token, or, with removeFromFriends, lose
+// the Friend entry, the companion and the follower entry. Pending friend requests from and to
+// the user carry the new token either way. Returns the new token and the number of friends
+// lists the user was removed from.
+func (c *Client) RotateShareToken(
+	ctx context.Context,
+	userID string,
+	removeFromFriends bool,
+) (shareToken string, removed int, err error) {
+	if userID == "" {
+		return "", 0, fmt.Errorf("userID is required")
+	}
+	userRef := c.Collection("users").Doc(userID)
+	requests := c.Collection("friend_requests")
+	err = c.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		shareToken = uuid.New().String()
+		removed = 0
+
+		// Reads first: Firestore transactions allow no reads after writes
+		snap, err := tx.Get(userRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		oldToken, _ := snap.DataAt("ShareToken")
+		old, _ := oldToken.(string)
+		followers, err := tx.Documents(userRef.Collection("followers")).GetAll()
+		if err != nil {
+			return fmt.Errorf("failed to list followers: %w", err)
+		}
+		type followerDocs struct {
+			follower *firestore.DocumentSnapshot
+			friends  []*firestore.DocumentSnapshot
+			visits   []*firestore.DocumentSnapshot
+			bump     func() error
+		}
+		var affected []followerDocs
+		for _, follower := range followers {
+			followerRef := c.Collection("users").Doc(follower.Ref.ID)
+			friends, err := tx.Documents(followerRef.Collection("friends").
+				Where("ShareToken", "==", old)).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to find friend entry: %w", err)
+			}
+			visits, err := tx.Documents(followerRef.Collection("country_visits").
+				Where("Companions", "array-contains", old)).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to find companion visits: %w", err)
+			}
+			bump := func() error { return nil }
+			if len(visits) > 0 {
+				if bump, err = c.prepareVisitsRevisionBump(tx, follower.Ref.ID); err != nil {
+					return err
+				}
+			}
+			affected = append(affected, followerDocs{follower, friends, visits, bump})
+		}
+		var sent, received []*firestore.DocumentSnapshot
+		if old != "" {
+			if sent, err = tx.Documents(requests.Where("FromUserID", "==", userID)).
+				GetAll(); err != nil {
+				return fmt.Errorf("failed to list friend requests: %w", err)
+			}
+			if received, err = tx.Documents(requests.Where("ToUserID", "==", userID)).
+				GetAll(); err != nil {
+				return fmt.Errorf("failed to list friend requests: %w", err)
+			}
+		}
+
+		if err := tx.Update(userRef, []firestore.Update{
+			{Path: "ShareToken", Value: shareToken},
+		}); err != nil {
+			return err
+		}
+		for _, a := range affected {
+			for _, f := range a.friends {
+				if removeFromFriends {
+					err = tx.Delete(f.Ref)
+				} else {
+					err = tx.Update(f.Ref, []firestore.Update{
+						{Path: "ShareToken", Value: shareToken},
+					})
+				}
+				if err != nil {
+					return err
+				}
+			}
+			for _, v := range a.visits {
+				var visit models.CountryVisit
+				if err := v.DataTo(&visit); err != nil {
+					return fmt.Errorf("failed to unmarshal country visit: %w", err)
+				}
+				companions := make([]string, 0, len(visit.Companions))
+				for _, token := range visit.Companions {
+					switch {
+					case token != old:
+						companions = append(companions, token)
+					case !removeFromFriends:
+						companions = append(companions, shareToken)
+					}
+				}
+				if err := tx.Update(v.Ref, []firestore.Update{
+					{Path: "Companions", Value: companions},
+				}); err != nil {
+					return err
+				}
+			}
+			if len(a.visits) > 0 {
+				if err := a.bump(); err != nil {
+					return err
+				}
+			}
+			if removeFromFriends {
+				if err := tx.Delete(a.follower.Ref); err != nil {
+					return err
+				}
+				if len(a.friends) > 0 {
+					removed++
+				}
+			}
+		}
+		for _, r := range sent {
+			err := tx.Update(r.Ref, []firestore.Update{{Path: "FromShareToken", Value: shareToken}})
+			if err != nil {
+				return err
+			}
+		}
+		for _, r := range received {
+			err := tx.Update(r.Ref, []firestore.Update{{Path: "ToShareToken", Value: shareToken}})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrUserNotFound) {
+		return "", 0, ErrUserNotFound
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to rotate share token: %w", err)
+	}
+	return shareToken, removed, nil
+}
@@ -0,0 +1,95 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScheduleAccountDeletion sets DeletionScheduledAt of userID to at. Returns
+// database.ErrUserNotFound if the user does not exist.
+func (db *DB) ScheduleAccountDeletion(ctx context.Context, userID string, at time.Time) error {
+	return db.updateUser(ctx, userID, `deletion_scheduled_at = ?`, at.UTC())
+}
+
+// CancelAccountDeletion clears DeletionScheduledAt of userID. Returns database.ErrUserNotFound
+// if the user does not exist.
+func (db *DB) CancelAccountDeletion(ctx context.Context, userID string) error {
+	return db.updateUser(ctx, userID, `deletion_scheduled_at = NULL`)
+}
+
+// GetUsersDueForDeletion returns the IDs of up to limit users whose DeletionScheduledAt is at or
+// before now.
+func (db *DB) GetUsersDueForDeletion(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]string, error) {
+	rows, err := db.conn().query(ctx, `SELECT id FROM users WHERE deletion_scheduled_at <= ?
+		ORDER BY id LIMIT ?`, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users due for deletion: %w", err)
+	}
+	ids, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users due for deletion: %w", err)
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids, nil
+}
+
+// purgeStatements delete the data of a user in PurgeUser; every ? is the user ID. The follower
+// entries at the user's friends must go before the user's friends.
+var purgeStatements = []string{
+	`DELETE FROM followers WHERE follower_id = ? AND user_id IN (SELECT u.id FROM users u
+		JOIN friends f ON f.share_token = u.share_token WHERE f.user_id = ?)`,
+	`DELETE FROM visit_overlaps WHERE id IN (SELECT id FROM visit_overlaps WHERE user_id = ?)`,
+	`DELETE FROM country_visits WHERE user_id = ?`,
+	`DELETE FROM visit_history WHERE user_id = ?`,
+	`DELETE FROM friends WHERE user_id = ?`,
+	`DELETE FROM followers WHERE user_id = ?`,
+	`DELETE FROM blocked_users WHERE user_id = ?`,
+	`DELETE FROM friend_invites WHERE user_id = ?`,
+	`DELETE FROM insights WHERE user_id = ?`,
+	`DELETE FROM share_links WHERE user_id = ?`,
+	`DELETE FROM share_stats WHERE user_id = ?`,
+	`DELETE FROM api_keys WHERE user_id = ?`,
+	`DELETE FROM audit_events WHERE user_id = ?`,
+	`DELETE FROM handles WHERE user_id = ?`,
+	`DELETE FROM friend_requests WHERE from_user_id = ? OR to_user_id = ?`,
+	`DELETE FROM organization_members WHERE user_id = ?`,
+	`DELETE FROM users WHERE id = ?`,
+}
+
+// PurgeUser deletes the account of userID if its deletion is due at now, with the same scope
+// as the Firestore client: all of the user's data, the user's followers entries at their
+// friends, visit overlaps (both copies), friend requests from and to the user and organization
+// memberships. Friend entries of other users and organizations the user created are kept.
+func (db *DB) PurgeUser(ctx context.Context, userID string, now time.Time) error {
+	return db.tx(ctx, func(c conn) error {
+		return c.purgeUser(ctx, userID, now)
+	})
+}
+
+func (c conn) purgeUser(ctx context.Context, userID string, now time.Time) error {
+	u, err := c.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil || u.DeletionScheduledAt == nil || u.DeletionScheduledAt.After(now) {
+		return nil
+	}
+	for _, stmt := range purgeStatements {
+		args := make([]any, strings.Count(stmt, "?"))
+		for i := range args {
+			args[i] = userID
+		}
+		if _, err := c.exec(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("failed to purge user: %w", err)
+		}
+	}
+	return nil
+}
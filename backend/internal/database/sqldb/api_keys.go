@@ -0,0 +1,125 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, created_at, last_used_at`
+
+func scanAPIKey(s scanner) (models.APIKey, error) {
+	var k models.APIKey
+	var lastUsedAt sql.NullTime
+	err := s.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.CreatedAt, &lastUsedAt)
+	k.CreatedAt = k.CreatedAt.UTC()
+	k.LastUsedAt = timePtr(lastUsedAt)
+	return k, err
+}
+
+// CreateAPIKey stores the API key of key.UserID under a new ID. Returns
+// database.ErrTooManyAPIKeys when the user has models.MaxAPIKeys keys.
+func (db *DB) CreateAPIKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error) {
+	if key == nil || key.UserID == "" || key.KeyHash == "" {
+		return nil, fmt.Errorf("userID and key hash are required")
+	}
+	out := *key
+	out.ID = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.LastUsedAt = nil
+	err := db.tx(ctx, func(c conn) error {
+		var n int
+		err := c.queryRow(ctx, `SELECT COUNT(*) FROM api_keys WHERE user_id = ?`, out.UserID).
+			Scan(&n)
+		if err != nil {
+			return fmt.Errorf("failed to count API keys: %w", err)
+		}
+		if n >= models.MaxAPIKeys {
+			return database.ErrTooManyAPIKeys
+		}
+		_, err = c.exec(ctx, `INSERT INTO api_keys (`+apiKeyColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, NULL)`,
+			out.ID, out.UserID, out.Name, out.Prefix, out.KeyHash, out.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAPIKeys returns the API keys of userID, newest first.
+func (db *DB) GetAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys
+		WHERE user_id = ? ORDER BY created_at DESC, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	keys, err := scanAll(rows, scanAPIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash returns the API key of any user whose KeyHash is keyHash, or nil (not error)
+// when there is none.
+func (db *DB) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	if keyHash == "" {
+		return nil, nil
+	}
+	k, err := scanAPIKey(db.conn().queryRow(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &k, nil
+}
+
+// TouchAPIKey sets LastUsedAt of the API key keyID of userID to now. A key revoked meanwhile is
+// not an error.
+func (db *DB) TouchAPIKey(ctx context.Context, userID, keyID string, now time.Time) error {
+	if userID == "" || keyID == "" {
+		return fmt.Errorf("userID and keyID are required")
+	}
+	_, err := db.conn().exec(ctx, `UPDATE api_keys SET last_used_at = ?
+		WHERE user_id = ? AND id = ?`, now.UTC(), userID, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to touch API key: %w", err)
+	}
+	return nil
+}
+
+// DeleteAPIKey revokes the API key keyID of userID. Returns database.ErrAPIKeyNotFound if it
+// does not exist.
+func (db *DB) DeleteAPIKey(ctx context.Context, userID, keyID string) error {
+	if userID == "" || keyID == "" {
+		return fmt.Errorf("userID and keyID are required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM api_keys WHERE user_id = ? AND id = ?`,
+		userID, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+	if n == 0 {
+		return database.ErrAPIKeyNotFound
+	}
+	return nil
+}
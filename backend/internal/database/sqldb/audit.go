@@ -0,0 +1,60 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// RecordAuditEvent adds event to the audit log of userID, expiring models.AuditRetention after
+// event.Time.
+func (db *DB) RecordAuditEvent(
+	ctx context.Context,
+	userID string,
+	event *models.AuditEvent,
+) error {
+	if userID == "" || event == nil || event.Type == "" {
+		return fmt.Errorf("userID and event type are required")
+	}
+	at := event.Time.UTC()
+	_, err := db.conn().exec(ctx, `INSERT INTO audit_events
+		(user_id, id, type, time, expires_at, ip, user_agent, detail)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, newID(), event.Type, at, at.Add(models.AuditRetention), event.IP,
+		event.UserAgent, event.Detail)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// GetAuditEvents returns up to limit of the most recent audit events of userID, newest first.
+func (db *DB) GetAuditEvents(
+	ctx context.Context,
+	userID string,
+	limit int,
+) ([]models.AuditEvent, error) {
+	if userID == "" || limit <= 0 {
+		return nil, fmt.Errorf("userID and a positive limit are required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT id, type, time, expires_at, ip, user_agent, detail
+		FROM audit_events WHERE user_id = ? ORDER BY time DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	events, err := scanAll(rows, func(s scanner) (models.AuditEvent, error) {
+		var e models.AuditEvent
+		err := s.Scan(&e.ID, &e.Type, &e.Time, &e.ExpiresAt, &e.IP, &e.UserAgent, &e.Detail)
+		e.Time = e.Time.UTC()
+		e.ExpiresAt = e.ExpiresAt.UTC()
+		return e, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	if events == nil {
+		events = []models.AuditEvent{}
+	}
+	return events, nil
+}
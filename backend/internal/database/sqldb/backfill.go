@@ -0,0 +1,89 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetBackfillJob returns the checkpoint of the job name, or (nil, nil) if it has never been
+// started.
+func (db *DB) GetBackfillJob(ctx context.Context, name string) (*models.BackfillJob, error) {
+	job := models.BackfillJob{Name: name}
+	var completedAt sql.NullTime
+	err := db.conn().queryRow(ctx, `SELECT status, cursor, processed, failed, last_error,
+		started_at, updated_at, completed_at FROM backfill_jobs WHERE name = ?`, name).
+		Scan(&job.Status, &job.Cursor, &job.Processed, &job.Failed, &job.LastError,
+			&job.StartedAt, &job.UpdatedAt, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill job: %w", err)
+	}
+	job.StartedAt = job.StartedAt.UTC()
+	job.UpdatedAt = job.UpdatedAt.UTC()
+	job.CompletedAt = timePtr(completedAt)
+	return &job, nil
+}
+
+// SaveBackfillJob replaces the checkpoint of job.Name.
+func (db *DB) SaveBackfillJob(ctx context.Context, job *models.BackfillJob) error {
+	if job == nil || job.Name == "" {
+		return fmt.Errorf("job name is required")
+	}
+	_, err := db.conn().exec(ctx, `INSERT INTO backfill_jobs (name, status, cursor, processed,
+		failed, last_error, started_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET status = excluded.status, cursor = excluded.cursor,
+			processed = excluded.processed, failed = excluded.failed,
+			last_error = excluded.last_error, started_at = excluded.started_at,
+			updated_at = excluded.updated_at, completed_at = excluded.completed_at`,
+		job.Name, job.Status, job.Cursor, job.Processed, job.Failed, job.LastError,
+		job.StartedAt.UTC(), job.UpdatedAt.UTC(), utcPtr(job.CompletedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save backfill job: %w", err)
+	}
+	return nil
+}
+
+// ListUserIDs returns up to limit user IDs in ID order, starting after afterID.
+func (db *DB) ListUserIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	rows, err := db.conn().query(ctx, `SELECT id FROM users WHERE id > ? ORDER BY id LIMIT ?`,
+		afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	ids, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return ids, nil
+}
+
+// RebuildUserVisitStats recomputes VisitCount and DistinctCountries of userID. A user that
+// does not exist is skipped.
+func (db *DB) RebuildUserVisitStats(ctx context.Context, userID string) error {
+	return db.conn().rebuildUserVisitStats(ctx, userID)
+}
+
+func (c conn) rebuildUserVisitStats(ctx context.Context, userID string) error {
+	_, err := c.exec(ctx, `UPDATE users SET
+		visit_count = (SELECT COUNT(*) FROM country_visits WHERE user_id = ?),
+		distinct_countries = (SELECT COUNT(DISTINCT country_code) FROM country_visits
+			WHERE user_id = ?)
+		WHERE id = ?`, userID, userID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild visit stats: %w", err)
+	}
+	return nil
+}
+
+// MigrateUserSchema does nothing: the SQL schema is upgraded as a whole by the migrations
+// applied at startup (see migrate.go), so rows have no per-row schema version.
+func (db *DB) MigrateUserSchema(ctx context.Context, userID string) error {
+	return nil
+}
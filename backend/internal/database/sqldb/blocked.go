@@ -0,0 +1,130 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// BlockUser adds blocked to the blocked users of userID and ends any relation between the two
+// users: the friend entries on both sides (ownerShareToken is the owner's ShareToken), their
+// followers entries and pending friend requests in either direction.
+func (db *DB) BlockUser(
+	ctx context.Context,
+	userID, ownerShareToken string,
+	blocked *models.BlockedUser,
+) (*models.BlockedUser, error) {
+	if userID == "" || blocked == nil || blocked.UserID == "" {
+		return nil, fmt.Errorf("userID and blocked user ID are required")
+	}
+	out := *blocked
+	out.CreatedAt = time.Now().UTC()
+	err := db.tx(ctx, func(c conn) error {
+		for _, stmt := range []struct {
+			query string
+			args  []any
+		}{
+			{`DELETE FROM friends WHERE user_id = ? AND share_token = ?`,
+				[]any{userID, blocked.ShareToken}},
+			{`DELETE FROM friends WHERE user_id = ? AND share_token = ?`,
+				[]any{blocked.UserID, ownerShareToken}},
+			{`DELETE FROM friend_requests WHERE (from_user_id = ? AND to_user_id = ?)
+				OR (from_user_id = ? AND to_user_id = ?)`,
+				[]any{userID, blocked.UserID, blocked.UserID, userID}},
+			{`DELETE FROM followers WHERE (user_id = ? AND follower_id = ?)
+				OR (user_id = ? AND follower_id = ?)`,
+				[]any{userID, blocked.UserID, blocked.UserID, userID}},
+		} {
+			if _, err := c.exec(ctx, stmt.query, stmt.args...); err != nil {
+				return fmt.Errorf("failed to end relation with blocked user: %w", err)
+			}
+		}
+		return c.putBlocked(ctx, userID, out)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// putBlocked stores b as a blocked user of userID, replacing an existing entry.
+func (c conn) putBlocked(ctx context.Context, userID string, b models.BlockedUser) error {
+	_, err := c.exec(ctx, `INSERT INTO blocked_users
+		(user_id, blocked_user_id, share_token, name, image_url, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, blocked_user_id) DO UPDATE SET
+			share_token = excluded.share_token, name = excluded.name,
+			image_url = excluded.image_url, created_at = excluded.created_at`,
+		userID, b.UserID, b.ShareToken, b.Name, b.ImageURL, b.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+	return nil
+}
+
+const blockedColumns = `blocked_user_id, share_token, name, image_url, created_at`
+
+func scanBlocked(s scanner) (models.BlockedUser, error) {
+	var b models.BlockedUser
+	err := s.Scan(&b.UserID, &b.ShareToken, &b.Name, &b.ImageURL, &b.CreatedAt)
+	b.CreatedAt = b.CreatedAt.UTC()
+	return b, err
+}
+
+// GetBlockedUsers returns the users blocked by userID, most recently blocked first.
+func (db *DB) GetBlockedUsers(ctx context.Context, userID string) ([]models.BlockedUser, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT `+blockedColumns+` FROM blocked_users
+		WHERE user_id = ? ORDER BY created_at DESC, blocked_user_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocked users: %w", err)
+	}
+	blocked, err := scanAll(rows, scanBlocked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocked users: %w", err)
+	}
+	if blocked == nil {
+		blocked = []models.BlockedUser{}
+	}
+	return blocked, nil
+}
+
+// IsBlocked reports whether userID has blocked otherUserID.
+func (db *DB) IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error) {
+	if userID == "" || otherUserID == "" {
+		return false, fmt.Errorf("userID and otherUserID are required")
+	}
+	return db.conn().isBlocked(ctx, userID, otherUserID)
+}
+
+func (c conn) isBlocked(ctx context.Context, userID, otherUserID string) (bool, error) {
+	var n int
+	err := c.queryRow(ctx, `SELECT COUNT(*) FROM blocked_users
+		WHERE user_id = ? AND blocked_user_id = ?`, userID, otherUserID).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to check blocked user: %w", err)
+	}
+	return n > 0, nil
+}
+
+// UnblockUser removes the block of the user blocked with shareToken. Returns
+// database.ErrBlockedUserNotFound when there is no such block.
+func (db *DB) UnblockUser(ctx context.Context, userID, shareToken string) error {
+	if userID == "" || shareToken == "" {
+		return fmt.Errorf("userID and shareToken are required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM blocked_users WHERE user_id = ? AND share_token = ?`,
+		userID, shareToken)
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	if n == 0 {
+		return database.ErrBlockedUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package sqldb_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/database/dbtest"
+	"github.com/matti777/my-countries/backend/internal/database/sqldb"
+)
+
+// TestSQLiteConformance runs the conformance suite on a new SQLite file per test.
+func TestSQLiteConformance(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T) dbtest.DB {
+		db, err := sqldb.OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	})
+}
+
+// TestPostgresConformance runs the conformance suite on the PostgreSQL database at
+// TEST_DATABASE_URL (make test-integration starts one) and is skipped without it.
+func TestPostgresConformance(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set; run make test-integration")
+	}
+	db, err := sqldb.OpenPostgres(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	dbtest.Run(t, func(t *testing.T) dbtest.DB { return db })
+}
@@ -0,0 +1,63 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetCountryOverrides returns every country override, ordered by country code.
+func (db *DB) GetCountryOverrides(ctx context.Context) ([]models.CountryOverride, error) {
+	rows, err := db.conn().query(ctx, `SELECT country_code, name, region_code, type, deprecated,
+		updated_at FROM country_overrides ORDER BY country_code`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list country overrides: %w", err)
+	}
+	overrides, err := scanAll(rows, func(s scanner) (models.CountryOverride, error) {
+		var o models.CountryOverride
+		err := s.Scan(&o.CountryCode, &o.Name, &o.RegionCode, &o.Type, &o.Deprecated,
+			&o.UpdatedAt)
+		o.UpdatedAt = o.UpdatedAt.UTC()
+		return o, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list country overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// SaveCountryOverride stores o, replacing any earlier override of o.CountryCode.
+func (db *DB) SaveCountryOverride(ctx context.Context, o *models.CountryOverride) error {
+	if o == nil || o.CountryCode == "" {
+		return fmt.Errorf("country code is required")
+	}
+	_, err := db.conn().exec(ctx, `INSERT INTO country_overrides
+		(country_code, name, region_code, type, deprecated, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (country_code) DO UPDATE SET name = excluded.name,
+			region_code = excluded.region_code, type = excluded.type,
+			deprecated = excluded.deprecated, updated_at = excluded.updated_at`,
+		o.CountryCode, o.Name, o.RegionCode, o.Type, o.Deprecated, o.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save country override: %w", err)
+	}
+	return nil
+}
+
+// DeleteCountryOverride deletes the override of code. Returns
+// database.ErrCountryOverrideNotFound if there is none.
+func (db *DB) DeleteCountryOverride(ctx context.Context, code string) error {
+	if code == "" {
+		return fmt.Errorf("country code is required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM country_overrides WHERE country_code = ?`, code)
+	if err != nil {
+		return fmt.Errorf("failed to delete country override: %w", err)
+	}
+	if n == 0 {
+		return database.ErrCountryOverrideNotFound
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetFeedVisits returns up to limit non-private visits of userIDs in feed order (VisitedTime
+// descending, then visit ID descending), starting after the cursor when it is non-nil.
+func (db *DB) GetFeedVisits(
+	ctx context.Context,
+	userIDs []string,
+	after *models.FeedCursor,
+	limit int,
+) ([]models.CountryVisit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	query := `SELECT ` + visitColumns + ` FROM country_visits
+		WHERE user_id IN (` + placeholders(len(userIDs)) + `) AND is_private = ?`
+	args := append(anys(userIDs), false)
+	if after != nil {
+		at := after.VisitedTime.UTC()
+		query += ` AND (visited_time < ? OR (visited_time = ? AND id < ?))`
+		args = append(args, at, at, after.VisitID)
+	}
+	rows, err := db.conn().query(ctx, query+` ORDER BY visited_time DESC, id DESC LIMIT ?`,
+		append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feed visits: %w", err)
+	}
+	visits, err := scanAll(rows, scanVisit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feed visits: %w", err)
+	}
+	return visits, nil
+}
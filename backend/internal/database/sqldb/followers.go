@@ -0,0 +1,106 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetFollowers returns the users who have userID as a friend, most recent first, leaving out
+// followers whose account no longer exists or who set Settings.HideFromFollowers. IsFriend is
+// not set.
+func (db *DB) GetFollowers(ctx context.Context, userID string) ([]models.Follower, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT f.created_at, `+userColumns+`
+		FROM followers f JOIN users ON users.id = f.follower_id
+		WHERE f.user_id = ? ORDER BY f.created_at DESC, users.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list followers: %w", err)
+	}
+	list, err := scanAll(rows, func(s scanner) (models.Follower, error) {
+		var f models.Follower
+		var createdAt time.Time
+		u, err := scanUser(prefixScanner{s, &createdAt})
+		if err != nil {
+			return f, err
+		}
+		if u.Settings.HideFromFollowers {
+			return f, nil
+		}
+		f.UserID = u.ID
+		f.ShareToken = u.ShareToken
+		f.Name = u.Name
+		f.ImageURL = u.ImageURL
+		f.CreatedAt = createdAt.UTC()
+		return f, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list followers: %w", err)
+	}
+	var followers []models.Follower
+	for _, f := range list {
+		if f.UserID != "" {
+			followers = append(followers, f)
+		}
+	}
+	return followers, nil
+}
+
+// prefixScanner scans the first column of a row into dest and the remaining ones into the
+// destinations passed to Scan.
+type prefixScanner struct {
+	s    scanner
+	dest any
+}
+
+func (p prefixScanner) Scan(dest ...any) error {
+	return p.s.Scan(append([]any{p.dest}, dest...)...)
+}
+
+// RebuildFollowers adds userID to the followers of every user in userID's friends. Existing
+// entries are kept.
+func (db *DB) RebuildFollowers(ctx context.Context, userID string) error {
+	return db.tx(ctx, func(c conn) error {
+		rows, err := c.query(ctx, `SELECT DISTINCT u.id FROM friends f
+			JOIN users u ON u.share_token = f.share_token WHERE f.user_id = ?`, userID)
+		if err != nil {
+			return fmt.Errorf("failed to list friend users: %w", err)
+		}
+		friendIDs, err := scanStrings(rows)
+		if err != nil {
+			return fmt.Errorf("failed to list friend users: %w", err)
+		}
+		now := time.Now().UTC()
+		for _, friendID := range friendIDs {
+			if err := c.addFollower(ctx, friendID, userID, now, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// addFollower adds followerID to the followers of userID, replacing an existing entry only
+// with replace.
+func (c conn) addFollower(
+	ctx context.Context,
+	userID, followerID string,
+	now time.Time,
+	replace bool,
+) error {
+	onConflict := `DO NOTHING`
+	if replace {
+		onConflict = `DO UPDATE SET created_at = excluded.created_at`
+	}
+	_, err := c.exec(ctx, `INSERT INTO followers (user_id, follower_id, created_at)
+		VALUES (?, ?, ?) ON CONFLICT (user_id, follower_id) `+onConflict,
+		userID, followerID, now.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to add follower: %w", err)
+	}
+	return nil
+}
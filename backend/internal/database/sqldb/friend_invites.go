@@ -0,0 +1,132 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func scanFriendInvite(s scanner) (models.FriendInvite, error) {
+	var inv models.FriendInvite
+	err := s.Scan(&inv.ID, &inv.Email, &inv.CreatedAt, &inv.ExpiresAt)
+	inv.CreatedAt = inv.CreatedAt.UTC()
+	inv.ExpiresAt = inv.ExpiresAt.UTC()
+	return inv, err
+}
+
+// CreateFriendInvite stores an invite of userID to invite.Email, expiring after
+// models.FriendInviteTTL. Returns database.ErrFriendInviteExists when an unexpired invite to
+// the email exists and database.ErrTooManyFriendInvites when the user has
+// models.MaxPendingFriendInvites.
+func (db *DB) CreateFriendInvite(
+	ctx context.Context,
+	userID string,
+	invite *models.FriendInvite,
+) (*models.FriendInvite, error) {
+	if userID == "" || invite == nil || invite.Email == "" {
+		return nil, fmt.Errorf("userID and email are required")
+	}
+	out := *invite
+	out.ID = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.ExpiresAt = out.CreatedAt.Add(models.FriendInviteTTL)
+	err := db.tx(ctx, func(c conn) error {
+		pending, err := c.friendInvites(ctx, userID, out.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if len(pending) >= models.MaxPendingFriendInvites {
+			return database.ErrTooManyFriendInvites
+		}
+		for _, inv := range pending {
+			if inv.Email == out.Email {
+				return database.ErrFriendInviteExists
+			}
+		}
+		_, err = c.exec(ctx, `INSERT INTO friend_invites
+			(user_id, id, email, created_at, expires_at) VALUES (?, ?, ?, ?, ?)`,
+			userID, out.ID, out.Email, out.CreatedAt, out.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to create friend invite: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetFriendInvites returns the unexpired invites of userID, oldest first.
+func (db *DB) GetFriendInvites(
+	ctx context.Context,
+	userID string,
+) ([]models.FriendInvite, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	return db.conn().friendInvites(ctx, userID, time.Now())
+}
+
+// friendInvites returns the invites of userID unexpired at now, oldest first.
+func (c conn) friendInvites(
+	ctx context.Context,
+	userID string,
+	now time.Time,
+) ([]models.FriendInvite, error) {
+	rows, err := c.query(ctx, `SELECT id, email, created_at, expires_at FROM friend_invites
+		WHERE user_id = ? AND expires_at > ? ORDER BY created_at, id`, userID, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friend invites: %w", err)
+	}
+	invites, err := scanAll(rows, scanFriendInvite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friend invites: %w", err)
+	}
+	if invites == nil {
+		invites = []models.FriendInvite{}
+	}
+	return invites, nil
+}
+
+// FindFriendInvite returns the unexpired invite of userID to email (normalized), or nil.
+func (db *DB) FindFriendInvite(
+	ctx context.Context,
+	userID, email string,
+) (*models.FriendInvite, error) {
+	if userID == "" || email == "" {
+		return nil, fmt.Errorf("userID and email are required")
+	}
+	inv, err := scanFriendInvite(db.conn().queryRow(ctx, `SELECT id, email, created_at,
+		expires_at FROM friend_invites WHERE user_id = ? AND email = ? AND expires_at > ?
+		ORDER BY created_at LIMIT 1`, userID, email, time.Now().UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find friend invite: %w", err)
+	}
+	return &inv, nil
+}
+
+// DeleteFriendInvite deletes the invite inviteID of userID. Returns
+// database.ErrFriendInviteNotFound if it does not exist.
+func (db *DB) DeleteFriendInvite(ctx context.Context, userID, inviteID string) error {
+	if userID == "" || inviteID == "" {
+		return fmt.Errorf("userID and inviteID are required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM friend_invites WHERE user_id = ? AND id = ?`,
+		userID, inviteID)
+	if err != nil {
+		return fmt.Errorf("failed to delete friend invite: %w", err)
+	}
+	if n == 0 {
+		return database.ErrFriendInviteNotFound
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const friendRequestColumns = `id, from_user_id, from_share_token, from_name, from_image_url,
+	to_user_id, to_share_token, to_name, to_image_url, created_at`
+
+func scanFriendRequest(s scanner) (models.FriendRequest, error) {
+	var r models.FriendRequest
+	err := s.Scan(&r.ID, &r.FromUserID, &r.FromShareToken, &r.FromName, &r.FromImageURL,
+		&r.ToUserID, &r.ToShareToken, &r.ToName, &r.ToImageURL, &r.CreatedAt)
+	r.CreatedAt = r.CreatedAt.UTC()
+	return r, err
+}
+
+// CreateFriendRequest stores a friend request from req.FromUserID to req.ToUserID. Returns
+// database.ErrFriendRequestExists when a request between the two users exists in either
+// direction.
+func (db *DB) CreateFriendRequest(
+	ctx context.Context,
+	req *models.FriendRequest,
+) (*models.FriendRequest, error) {
+	if req == nil || req.FromUserID == "" || req.ToUserID == "" {
+		return nil, fmt.Errorf("fromUserID and toUserID are required")
+	}
+	out := *req
+	out.ID = newID()
+	out.CreatedAt = time.Now().UTC()
+	err := db.tx(ctx, func(c conn) error {
+		var n int
+		err := c.queryRow(ctx, `SELECT COUNT(*) FROM friend_requests
+			WHERE (from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?)`,
+			out.FromUserID, out.ToUserID, out.ToUserID, out.FromUserID).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("failed to check friend requests: %w", err)
+		}
+		if n > 0 {
+			return database.ErrFriendRequestExists
+		}
+		_, err = c.exec(ctx, `INSERT INTO friend_requests (`+friendRequestColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			out.ID, out.FromUserID, out.FromShareToken, out.FromName, out.FromImageURL,
+			out.ToUserID, out.ToShareToken, out.ToName, out.ToImageURL, out.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create friend request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetFriendRequests returns the friend requests sent to and by userID, oldest first.
+func (db *DB) GetFriendRequests(
+	ctx context.Context,
+	userID string,
+) (incoming, outgoing []models.FriendRequest, err error) {
+	if userID == "" {
+		return nil, nil, fmt.Errorf("userID is required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT `+friendRequestColumns+` FROM friend_requests
+		WHERE to_user_id = ? OR from_user_id = ? ORDER BY created_at, id`, userID, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list friend requests: %w", err)
+	}
+	requests, err := scanAll(rows, scanFriendRequest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list friend requests: %w", err)
+	}
+	incoming, outgoing = []models.FriendRequest{}, []models.FriendRequest{}
+	for _, r := range requests {
+		if r.ToUserID == userID {
+			incoming = append(incoming, r)
+		} else {
+			outgoing = append(outgoing, r)
+		}
+	}
+	return incoming, outgoing, nil
+}
+
+// GetFriendRequest returns the friend request with requestID if userID sent or received it;
+// otherwise database.ErrFriendRequestNotFound.
+func (db *DB) GetFriendRequest(
+	ctx context.Context,
+	requestID, userID string,
+) (*models.FriendRequest, error) {
+	if requestID == "" || userID == "" {
+		return nil, fmt.Errorf("requestID and userID are required")
+	}
+	r, err := db.conn().friendRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if r.FromUserID != userID && r.ToUserID != userID {
+		return nil, database.ErrFriendRequestNotFound
+	}
+	return r, nil
+}
+
+func (c conn) friendRequest(ctx context.Context, requestID string) (*models.FriendRequest, error) {
+	r, err := scanFriendRequest(c.queryRow(ctx, `SELECT `+friendRequestColumns+`
+		FROM friend_requests WHERE id = ?`, requestID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, database.ErrFriendRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get friend request: %w", err)
+	}
+	return &r, nil
+}
+
+// AcceptFriendRequest accepts a friend request received by userID: each user gets the other as
+// a Friend (unless already present) and as a follower, and the request is deleted. Returns the
+// requester as the recipient's Friend, database.ErrFriendRequestNotFound unless userID received
+// it, or a database.LimitError when a user gaining a friend has maxFriends or their override's
+// MaxFriends.
+func (db *DB) AcceptFriendRequest(
+	ctx context.Context,
+	requestID, userID string,
+	maxFriends int,
+) (models.Friend, error) {
+	if requestID == "" || userID == "" {
+		return models.Friend{}, fmt.Errorf("requestID and userID are required")
+	}
+	var friend models.Friend
+	err := db.tx(ctx, func(c conn) error {
+		r, err := c.friendRequest(ctx, requestID)
+		if err != nil {
+			return err
+		}
+		if r.ToUserID != userID {
+			return database.ErrFriendRequestNotFound
+		}
+		toExisting, err := c.friendByShareToken(ctx, r.ToUserID, r.FromShareToken)
+		if err != nil {
+			return err
+		}
+		fromExisting, err := c.friendByShareToken(ctx, r.FromUserID, r.ToShareToken)
+		if err != nil {
+			return err
+		}
+		for _, check := range []struct {
+			userID   string
+			existing *models.Friend
+		}{{r.ToUserID, toExisting}, {r.FromUserID, fromExisting}} {
+			if check.existing != nil {
+				continue
+			}
+			u, err := c.user(ctx, check.userID)
+			if err != nil {
+				return err
+			}
+			limits := models.UserLimits{MaxFriends: maxFriends}
+			if u != nil {
+				limits = u.Limits.Or(limits)
+			}
+			if limits.MaxFriends <= 0 {
+				continue
+			}
+			n, err := c.friendCount(ctx, check.userID)
+			if err != nil {
+				return err
+			}
+			if n >= limits.MaxFriends {
+				return database.NewLimitError(database.ErrFriendLimitReached, check.userID,
+					limits.MaxFriends)
+			}
+		}
+
+		friend = models.Friend{
+			ShareToken: r.FromShareToken,
+			Name:       r.FromName,
+			ImageURL:   r.FromImageURL,
+		}
+		now := time.Now().UTC()
+		if toExisting == nil {
+			friend.ID = newID()
+			if err := c.addFriend(ctx, r.ToUserID, friend); err != nil {
+				return err
+			}
+			if err := c.addFollower(ctx, r.FromUserID, r.ToUserID, now, true); err != nil {
+				return err
+			}
+		} else {
+			friend.ID = toExisting.ID
+		}
+		if fromExisting == nil {
+			back := models.Friend{
+				ID:         newID(),
+				ShareToken: r.ToShareToken,
+				Name:       r.ToName,
+				ImageURL:   r.ToImageURL,
+			}
+			if err := c.addFriend(ctx, r.FromUserID, back); err != nil {
+				return err
+			}
+			if err := c.addFollower(ctx, r.ToUserID, r.FromUserID, now, true); err != nil {
+				return err
+			}
+		}
+		_, err = c.exec(ctx, `DELETE FROM friend_requests WHERE id = ?`, requestID)
+		if err != nil {
+			return fmt.Errorf("failed to delete friend request: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Friend{}, err
+	}
+	return friend, nil
+}
+
+func (c conn) friendCount(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := c.queryRow(ctx, `SELECT COUNT(*) FROM friends WHERE user_id = ?`, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count friends: %w", err)
+	}
+	return n, nil
+}
+
+// addFriend stores f (with its ID) as a friend of userID.
+func (c conn) addFriend(ctx context.Context, userID string, f models.Friend) error {
+	_, err := c.exec(ctx, `INSERT INTO friends
+		(user_id, id, share_token, name, image_url, synced_at, nickname)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		userID, f.ID, f.ShareToken, f.Name, f.ImageURL, nullTime(f.SyncedAt), f.Nickname)
+	if err != nil {
+		return fmt.Errorf("failed to add friend: %w", err)
+	}
+	return nil
+}
+
+// DeleteFriendRequest deletes a friend request sent or received by userID. Returns
+// database.ErrFriendRequestNotFound otherwise.
+func (db *DB) DeleteFriendRequest(ctx context.Context, requestID, userID string) error {
+	if requestID == "" || userID == "" {
+		return fmt.Errorf("requestID and userID are required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM friend_requests
+		WHERE id = ? AND (from_user_id = ? OR to_user_id = ?)`, requestID, userID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete friend request: %w", err)
+	}
+	if n == 0 {
+		return database.ErrFriendRequestNotFound
+	}
+	return nil
+}
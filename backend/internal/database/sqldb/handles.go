@@ -0,0 +1,120 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// SetHandle gives userID the (normalized, valid) handle, releasing the user's previous handle.
+// Returns database.ErrHandleTaken when another user has it and database.ErrUserNotFound
+// without a User row.
+func (db *DB) SetHandle(ctx context.Context, userID, handle string) error {
+	if userID == "" || handle == "" {
+		return fmt.Errorf("userID and handle are required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		u, err := c.user(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return database.ErrUserNotFound
+		}
+		owner, err := c.handleOwner(ctx, handle)
+		if err != nil {
+			return err
+		}
+		if owner != "" && owner != userID {
+			return database.ErrHandleTaken
+		}
+		if u.Handle == handle && owner != "" {
+			return nil
+		}
+		return c.moveHandle(ctx, userID, u.Handle, handle)
+	})
+}
+
+// handleOwner returns the user ID holding handle, or "" when it is free.
+func (c conn) handleOwner(ctx context.Context, handle string) (string, error) {
+	var userID string
+	err := c.queryRow(ctx, `SELECT user_id FROM handles WHERE handle = ?`, handle).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get handle: %w", err)
+	}
+	return userID, nil
+}
+
+// moveHandle releases oldHandle (if any) and gives userID the free handle.
+func (c conn) moveHandle(ctx context.Context, userID, oldHandle, handle string) error {
+	if oldHandle != "" && oldHandle != handle {
+		if _, err := c.exec(ctx, `DELETE FROM handles WHERE handle = ?`, oldHandle); err != nil {
+			return fmt.Errorf("failed to release handle: %w", err)
+		}
+	}
+	_, err := c.exec(ctx, `INSERT INTO handles (handle, user_id, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (handle) DO UPDATE SET user_id = excluded.user_id,
+			created_at = excluded.created_at`,
+		handle, userID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to set handle: %w", err)
+	}
+	_, err = c.exec(ctx, `UPDATE users SET handle = ? WHERE id = ?`, handle, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set handle: %w", err)
+	}
+	return nil
+}
+
+// DeleteHandle releases the handle of userID. Returns database.ErrHandleNotFound when the user
+// has none and database.ErrUserNotFound without a User row.
+func (db *DB) DeleteHandle(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		u, err := c.user(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return database.ErrUserNotFound
+		}
+		if u.Handle == "" {
+			return database.ErrHandleNotFound
+		}
+		if _, err := c.exec(ctx, `DELETE FROM handles WHERE handle = ?`, u.Handle); err != nil {
+			return fmt.Errorf("failed to release handle: %w", err)
+		}
+		if _, err := c.exec(ctx, `UPDATE users SET handle = '' WHERE id = ?`, userID); err != nil {
+			return fmt.Errorf("failed to release handle: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetUserByHandle returns the owner of the normalized handle, or nil (not error) when nobody
+// has it.
+func (db *DB) GetUserByHandle(ctx context.Context, handle string) (*models.User, error) {
+	if handle == "" {
+		return nil, fmt.Errorf("handle is required")
+	}
+	u, err := scanUser(db.conn().queryRow(ctx, `SELECT `+userColumns+` FROM users
+		WHERE handle = ? AND id = (SELECT user_id FROM handles WHERE handle = ?)`,
+		handle, handle))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by handle: %w", err)
+	}
+	return u, nil
+}
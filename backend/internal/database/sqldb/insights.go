@@ -0,0 +1,49 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetInsights returns the insights of userID, or (nil, nil) if they have not been generated yet.
+func (db *DB) GetInsights(ctx context.Context, userID string) (*models.Insights, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	var data sql.NullString
+	err := db.conn().queryRow(ctx, `SELECT data FROM insights WHERE user_id = ?`, userID).
+		Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get insights: %w", err)
+	}
+	var ins models.Insights
+	if err := fromJSON(data, &ins); err != nil {
+		return nil, err
+	}
+	ins.GeneratedAt = ins.GeneratedAt.UTC()
+	return &ins, nil
+}
+
+// SaveInsights replaces the insights of userID.
+func (db *DB) SaveInsights(ctx context.Context, userID string, ins *models.Insights) error {
+	if userID == "" || ins == nil {
+		return fmt.Errorf("userID and insights are required")
+	}
+	data, err := toJSON(ins)
+	if err != nil {
+		return err
+	}
+	_, err = db.conn().exec(ctx, `INSERT INTO insights (user_id, data) VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET data = excluded.data`, userID, data)
+	if err != nil {
+		return fmt.Errorf("failed to save insights: %w", err)
+	}
+	return nil
+}
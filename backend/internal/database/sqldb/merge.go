@@ -0,0 +1,252 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// MergeUsers merges the account fromUserID into toUserID and then purges fromUserID, like the
+// Firestore client: visits are moved under their IDs, friends toUserID does not have are added,
+// followers of fromUserID get toUserID as their friend instead (or lose the entry when they have
+// both, are toUserID or are blocked by it), blocked users, share links and API keys are moved
+// and the handle is taken over when toUserID has none. Returns database.ErrUserNotFound when
+// either user is missing.
+func (db *DB) MergeUsers(
+	ctx context.Context,
+	fromUserID, toUserID string,
+	now time.Time,
+) (*models.AccountMergeResult, error) {
+	if fromUserID == "" || toUserID == "" || fromUserID == toUserID {
+		return nil, fmt.Errorf("two different user IDs are required")
+	}
+	var res *models.AccountMergeResult
+	err := db.tx(ctx, func(c conn) error {
+		res = &models.AccountMergeResult{}
+		from, err := c.user(ctx, fromUserID)
+		if err != nil {
+			return err
+		}
+		to, err := c.user(ctx, toUserID)
+		if err != nil {
+			return err
+		}
+		if from == nil || to == nil {
+			return database.ErrUserNotFound
+		}
+
+		if res.Visits, err = c.mergeVisits(ctx, fromUserID, toUserID); err != nil {
+			return err
+		}
+		if res.Friends, err = c.mergeFriends(ctx, fromUserID, to, now); err != nil {
+			return err
+		}
+		if res.Followers, err = c.mergeFollowers(ctx, from, to, now); err != nil {
+			return err
+		}
+		if res.BlockedUsers, err = c.mergeBlocked(ctx, fromUserID, toUserID); err != nil {
+			return err
+		}
+		for _, moved := range []struct {
+			table string
+			count *int
+		}{{"share_links", &res.ShareLinks}, {"api_keys", &res.APIKeys}} {
+			n, err := c.exec(ctx, `UPDATE `+moved.table+` SET user_id = ? WHERE user_id = ?`,
+				toUserID, fromUserID)
+			if err != nil {
+				return fmt.Errorf("failed to move %s: %w", moved.table, err)
+			}
+			*moved.count = int(n)
+		}
+		if handle := from.Handle; to.Handle == "" && handle != "" {
+			owner, err := c.handleOwner(ctx, handle)
+			if err != nil {
+				return err
+			}
+			if owner == fromUserID {
+				if _, err := c.exec(ctx, `UPDATE users SET handle = '' WHERE id = ?`,
+					fromUserID); err != nil {
+					return fmt.Errorf("failed to move handle: %w", err)
+				}
+				if err := c.moveHandle(ctx, toUserID, "", handle); err != nil {
+					return err
+				}
+			}
+			res.Handle = handle
+		}
+
+		if _, err := c.exec(ctx, `UPDATE users SET deletion_scheduled_at = ? WHERE id = ?`,
+			now.UTC(), fromUserID); err != nil {
+			return fmt.Errorf("failed to schedule account deletion: %w", err)
+		}
+		return c.purgeUser(ctx, fromUserID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// mergeVisits moves the visits of fromUserID to toUserID, recording them as created in the
+// history of toUserID, and returns how many were moved.
+func (c conn) mergeVisits(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	visits, err := c.countryVisits(ctx, fromUserID, true)
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range visits {
+		v.UserID = toUserID
+		if err := c.putVisit(ctx, v); err != nil {
+			return 0, err
+		}
+		err := c.addHistoryEvent(ctx, toUserID, v.ID, models.VisitEventCreated, toUserID, nil, &v)
+		if err != nil {
+			return 0, err
+		}
+	}
+	_, err = c.exec(ctx, `DELETE FROM country_visits WHERE user_id = ?`, fromUserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move visits: %w", err)
+	}
+	if err := c.bumpVisitsRevision(ctx, fromUserID, toUserID); err != nil {
+		return 0, err
+	}
+	if err := c.rebuildUserVisitStats(ctx, toUserID); err != nil {
+		return 0, err
+	}
+	return len(visits), nil
+}
+
+// mergeFriends adds the friends of fromUserID that to does not have (by ShareToken, leaving out
+// to itself) to to's friends, with to's entries in their followers, and returns how many were
+// added.
+func (c conn) mergeFriends(
+	ctx context.Context,
+	fromUserID string,
+	to *models.User,
+	now time.Time,
+) (int, error) {
+	toFriends, err := c.friends(ctx, to.ID)
+	if err != nil {
+		return 0, err
+	}
+	has := map[string]bool{to.ShareToken: true}
+	for _, f := range toFriends {
+		has[f.ShareToken] = true
+	}
+	fromFriends, err := c.friends(ctx, fromUserID)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, f := range fromFriends {
+		if has[f.ShareToken] {
+			continue
+		}
+		has[f.ShareToken] = true
+		f.ID = newID()
+		f.SyncedAt = time.Time{}
+		if err := c.addFriend(ctx, to.ID, f); err != nil {
+			return 0, err
+		}
+		friend, err := c.userByShareToken(ctx, f.ShareToken)
+		if err != nil {
+			return 0, err
+		}
+		if friend != nil && friend.ID != to.ID {
+			if err := c.addFollower(ctx, friend.ID, to.ID, now, true); err != nil {
+				return 0, err
+			}
+		}
+		added++
+	}
+	return added, nil
+}
+
+// mergeFollowers points the friend entries of users who have from as a friend to to and
+// returns how many were pointed to to. Entries are deleted instead when the follower has to as
+// a friend already, is to itself or is blocked by to.
+func (c conn) mergeFollowers(
+	ctx context.Context,
+	from, to *models.User,
+	now time.Time,
+) (int, error) {
+	rows, err := c.query(ctx, `SELECT f.follower_id FROM followers f
+		JOIN users u ON u.id = f.follower_id WHERE f.user_id = ? ORDER BY f.follower_id`, from.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list followers: %w", err)
+	}
+	followerIDs, err := scanStrings(rows)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list followers: %w", err)
+	}
+	moved := 0
+	for _, followerID := range followerIDs {
+		friends, err := c.friends(ctx, followerID)
+		if err != nil {
+			return 0, err
+		}
+		var fromEntries []string
+		hasTo := false
+		for _, f := range friends {
+			switch f.ShareToken {
+			case from.ShareToken:
+				fromEntries = append(fromEntries, f.ID)
+			case to.ShareToken:
+				hasTo = true
+			}
+		}
+		blocked, err := c.isBlocked(ctx, to.ID, followerID)
+		if err != nil {
+			return 0, err
+		}
+		keep := len(fromEntries) > 0 && !hasTo && followerID != to.ID && !blocked
+		for i, id := range fromEntries {
+			if keep && i == 0 {
+				continue
+			}
+			_, err := c.exec(ctx, `DELETE FROM friends WHERE user_id = ? AND id = ?`,
+				followerID, id)
+			if err != nil {
+				return 0, fmt.Errorf("failed to delete friend: %w", err)
+			}
+		}
+		if !keep {
+			continue
+		}
+		_, err = c.exec(ctx, `UPDATE friends SET share_token = ?, name = ?, image_url = ?
+			WHERE user_id = ? AND id = ?`,
+			to.ShareToken, to.Name, to.ImageURL, followerID, fromEntries[0])
+		if err != nil {
+			return 0, fmt.Errorf("failed to update friend: %w", err)
+		}
+		if err := c.addFollower(ctx, to.ID, followerID, now, true); err != nil {
+			return 0, err
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// mergeBlocked copies the blocked users of fromUserID except toUserID to toUserID and returns
+// how many were copied.
+func (c conn) mergeBlocked(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	rows, err := c.query(ctx, `SELECT `+blockedColumns+` FROM blocked_users
+		WHERE user_id = ? AND blocked_user_id <> ?`, fromUserID, toUserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list blocked users: %w", err)
+	}
+	blocked, err := scanAll(rows, scanBlocked)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list blocked users: %w", err)
+	}
+	for _, b := range blocked {
+		if err := c.putBlocked(ctx, toUserID, b); err != nil {
+			return 0, err
+		}
+	}
+	return len(blocked), nil
+}
@@ -0,0 +1,96 @@
+package sqldb
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrations holds the schema of each dialect as numbered files (0001_initial.sql, ...),
+// applied in order. Applied files must not change; add a new file instead.
+//
+//go:embed migrations
+var migrations embed.FS
+
+// migrate applies the migrations of the dialect that have not been applied yet, each in its
+// own transaction, and records them in schema_migrations.
+func (db *DB) migrate(ctx context.Context) error {
+	c := db.conn()
+	if _, err := c.exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	rows, err := c.query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to list schema migrations: %w", err)
+	}
+	applied := make(map[int]bool)
+	versions, err := scanAll(rows, func(s scanner) (int, error) {
+		var v int
+		err := s.Scan(&v)
+		return v, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list schema migrations: %w", err)
+	}
+	for _, v := range versions {
+		applied[v] = true
+	}
+
+	dir := path.Join("migrations", db.dialect.name)
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read migrations: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, e := range entries {
+		version, err := strconv.Atoi(strings.SplitN(e.Name(), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("invalid migration file name %s", e.Name())
+		}
+		if applied[version] {
+			continue
+		}
+		script, err := migrations.ReadFile(path.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", e.Name(), err)
+		}
+		err = db.runTx(ctx, func(c conn) error {
+			for _, stmt := range strings.Split(stripComments(string(script)), ";") {
+				if strings.TrimSpace(stmt) == "" {
+					continue
+				}
+				if _, err := c.q.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+			_, err := c.exec(ctx,
+				`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+				version, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// stripComments removes -- comments from a migration script, which may contain semicolons.
+func stripComments(stmt string) string {
+	lines := strings.Split(stmt, "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, "--"); j >= 0 {
+			lines[i] = line[:j]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,102 @@
+package sqldb
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// TestMigrationFiles checks that both dialects have the same numbered migrations.
+func TestMigrationFiles(t *testing.T) {
+	var names [][]string
+	for _, d := range []dialect{sqliteDialect, postgresDialect} {
+		entries, err := fs.ReadDir(migrations, path.Join("migrations", d.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var dialectNames []string
+		for _, e := range entries {
+			dialectNames = append(dialectNames, e.Name())
+		}
+		if len(dialectNames) == 0 {
+			t.Fatalf("no %s migrations", d.name)
+		}
+		names = append(names, dialectNames)
+	}
+	if !slices.Equal(names[0], names[1]) {
+		t.Errorf("sqlite migrations %v differ from postgres %v", names[0], names[1])
+	}
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.db")
+	testMigrate(t, func() (*DB, error) { return OpenSQLite(context.Background(), file) })
+}
+
+func TestMigratePostgres(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set; run make test-integration")
+	}
+	testMigrate(t, func() (*DB, error) { return OpenPostgres(context.Background(), url) })
+}
+
+// testMigrate opens a database twice: the first open applies every migration of the dialect,
+// and reopening applies none and keeps the data.
+func testMigrate(t *testing.T, open func() (*DB, error)) {
+	ctx := context.Background()
+	db, err := open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := appliedVersions(t, db)
+	entries, err := fs.ReadDir(migrations, path.Join("migrations", db.dialect.name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != len(entries) {
+		t.Fatalf("applied versions %v, want one per migration file (%d)", want, len(entries))
+	}
+	userID := "migrate-" + t.Name()
+	if err := db.EnsureUser(ctx, &models.User{ID: userID, Name: "Kept"}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = open()
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer db.Close()
+	if got := appliedVersions(t, db); !slices.Equal(got, want) {
+		t.Errorf("applied versions after reopening = %v, want %v", got, want)
+	}
+	u, err := db.GetUserByID(ctx, userID)
+	if err != nil || u == nil || u.Name != "Kept" {
+		t.Errorf("GetUserByID after reopening = %+v, %v; want the user", u, err)
+	}
+}
+
+func appliedVersions(t *testing.T, db *DB) []int {
+	t.Helper()
+	rows, err := db.conn().query(context.Background(),
+		`SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions, err := scanAll(rows, func(s scanner) (int, error) {
+		var v int
+		err := s.Scan(&v)
+		return v, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return versions
+}
@@ -0,0 +1,4 @@
+-- Admin override of the per-user document limits (models.UserLimits as JSON); NULL applies the
+-- configured limits.
+
+ALTER TABLE users ADD COLUMN limits TEXT;
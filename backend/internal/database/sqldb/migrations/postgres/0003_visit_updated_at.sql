@@ -0,0 +1,4 @@
+-- Time of the last write of a visit (models.CountryVisit.UpdatedAt), for PATCH /visits/:id
+-- preconditions; NULL for visits not written since.
+
+ALTER TABLE country_visits ADD COLUMN updated_at TIMESTAMPTZ;
@@ -0,0 +1,260 @@
+-- Same tables as the postgres schema; SQLite keeps TIMESTAMP values as text and BOOLEAN values
+-- as 0/1, and history order comes from the rowid alias seq.
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    share_token TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    settings TEXT,
+    is_anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    visits_revision BIGINT NOT NULL DEFAULT 0,
+    visit_count BIGINT NOT NULL DEFAULT 0,
+    distinct_countries BIGINT NOT NULL DEFAULT 0,
+    handle TEXT NOT NULL DEFAULT '',
+    sharing_disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    tokens_revoked_at TIMESTAMP,
+    deletion_scheduled_at TIMESTAMP
+);
+
+CREATE INDEX users_deletion_scheduled_at ON users (deletion_scheduled_at);
+
+CREATE TABLE country_visits (
+    user_id TEXT NOT NULL,
+    id TEXT NOT NULL,
+    country_code TEXT NOT NULL,
+    visited_time TIMESTAMP NOT NULL,
+    media_url TEXT,
+    notes TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '[]',
+    is_private BOOLEAN NOT NULL DEFAULT FALSE,
+    subdivision_code TEXT NOT NULL DEFAULT '',
+    destination_code TEXT NOT NULL DEFAULT '',
+    visit_type TEXT NOT NULL DEFAULT '',
+    location TEXT,
+    companions TEXT,
+    proofs TEXT,
+    PRIMARY KEY (user_id, id)
+);
+
+CREATE INDEX country_visits_feed ON country_visits (user_id, visited_time DESC, id DESC);
+
+CREATE TABLE visit_history (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL,
+    visit_id TEXT NOT NULL,
+    id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    time TIMESTAMP NOT NULL,
+    before_visit TEXT,
+    after_visit TEXT,
+    UNIQUE (user_id, visit_id, id)
+);
+
+CREATE TABLE visit_overlaps (
+    user_id TEXT NOT NULL,
+    id TEXT NOT NULL,
+    visit_id TEXT NOT NULL,
+    friend_user_id TEXT NOT NULL,
+    friend_share_token TEXT NOT NULL,
+    friend_visit_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, id)
+);
+
+CREATE TABLE friends (
+    user_id TEXT NOT NULL,
+    id TEXT NOT NULL,
+    share_token TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    synced_at TIMESTAMP,
+    nickname TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (user_id, id)
+);
+
+CREATE INDEX friends_share_token ON friends (share_token);
+
+CREATE TABLE followers (
+    user_id TEXT NOT NULL,
+    follower_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, follower_id)
+);
+
+CREATE INDEX followers_follower_id ON followers (follower_id);
+
+CREATE TABLE blocked_users (
+    user_id TEXT NOT NULL,
+    blocked_user_id TEXT NOT NULL,
+    share_token TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, blocked_user_id)
+);
+
+CREATE TABLE friend_invites (
+    user_id TEXT NOT NULL,
+    id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, id)
+);
+
+CREATE INDEX friend_invites_expires_at ON friend_invites (expires_at);
+
+CREATE TABLE friend_requests (
+    id TEXT PRIMARY KEY,
+    from_user_id TEXT NOT NULL,
+    from_share_token TEXT NOT NULL,
+    from_name TEXT NOT NULL DEFAULT '',
+    from_image_url TEXT NOT NULL DEFAULT '',
+    to_user_id TEXT NOT NULL,
+    to_share_token TEXT NOT NULL,
+    to_name TEXT NOT NULL DEFAULT '',
+    to_image_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX friend_requests_from_user_id ON friend_requests (from_user_id);
+CREATE INDEX friend_requests_to_user_id ON friend_requests (to_user_id);
+
+CREATE TABLE handles (
+    handle TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE insights (
+    user_id TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE share_links (
+    token TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    scope TEXT NOT NULL,
+    max_views INTEGER NOT NULL DEFAULT 0,
+    views INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX share_links_user_id ON share_links (user_id);
+CREATE INDEX share_links_expires_at ON share_links (expires_at);
+
+CREATE TABLE share_stats (
+    user_id TEXT NOT NULL,
+    token TEXT NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP,
+    days TEXT NOT NULL DEFAULT '{}',
+    PRIMARY KEY (user_id, token)
+);
+
+CREATE TABLE api_keys (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    prefix TEXT NOT NULL DEFAULT '',
+    key_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX api_keys_user_id ON api_keys (user_id);
+
+CREATE TABLE audit_events (
+    user_id TEXT NOT NULL,
+    id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    time TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (user_id, id)
+);
+
+CREATE INDEX audit_events_expires_at ON audit_events (expires_at);
+
+CREATE TABLE organizations (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    share_token TEXT NOT NULL UNIQUE,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    member_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE organization_members (
+    organization_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    joined_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX organization_members_user_id ON organization_members (user_id);
+
+CREATE TABLE organization_invitations (
+    code TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX organization_invitations_organization_id ON organization_invitations (organization_id);
+CREATE INDEX organization_invitations_expires_at ON organization_invitations (expires_at);
+
+CREATE TABLE organization_goals (
+    organization_id TEXT NOT NULL,
+    id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    country_codes TEXT,
+    target_count INTEGER NOT NULL DEFAULT 0,
+    year INTEGER NOT NULL DEFAULT 0,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (organization_id, id)
+);
+
+CREATE TABLE org_share_links (
+    token TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX org_share_links_organization_id ON org_share_links (organization_id);
+
+CREATE TABLE backfill_jobs (
+    name TEXT PRIMARY KEY,
+    status TEXT NOT NULL,
+    cursor TEXT NOT NULL DEFAULT '',
+    processed BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP
+);
+
+CREATE TABLE country_overrides (
+    country_code TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    region_code TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL DEFAULT '',
+    deprecated BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,653 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const orgColumns = `id, name, description, share_token, created_by, created_at, member_count`
+
+func scanOrg(s scanner) (models.Organization, error) {
+	var o models.Organization
+	err := s.Scan(&o.ID, &o.Name, &o.Description, &o.ShareToken, &o.CreatedBy, &o.CreatedAt,
+		&o.MemberCount)
+	o.CreatedAt = o.CreatedAt.UTC()
+	return o, err
+}
+
+// CreateOrganization stores org under a new ID with owner as its first member and a fresh
+// ShareToken.
+func (db *DB) CreateOrganization(
+	ctx context.Context,
+	org *models.Organization,
+	owner models.OrganizationMember,
+) (*models.Organization, error) {
+	if org == nil || org.Name == "" || owner.UserID == "" {
+		return nil, fmt.Errorf("organization name and owner are required")
+	}
+	out := *org
+	out.ID = newID()
+	out.ShareToken = newID()
+	out.CreatedBy = owner.UserID
+	out.MemberCount = 1
+	owner.Role = models.OrgRoleOwner
+	err := db.tx(ctx, func(c conn) error {
+		_, err := c.exec(ctx, `INSERT INTO organizations (`+orgColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`, out.ID, out.Name, out.Description, out.ShareToken,
+			out.CreatedBy, out.CreatedAt.UTC(), out.MemberCount)
+		if err != nil {
+			return fmt.Errorf("failed to create organization: %w", err)
+		}
+		return c.addOrgMember(ctx, out.ID, owner)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrganization returns the organization orgID, or database.ErrOrganizationNotFound.
+func (db *DB) GetOrganization(ctx context.Context, orgID string) (*models.Organization, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	return db.conn().org(ctx, orgID)
+}
+
+func (c conn) org(ctx context.Context, orgID string) (*models.Organization, error) {
+	o, err := scanOrg(c.queryRow(ctx,
+		`SELECT `+orgColumns+` FROM organizations WHERE id = ?`, orgID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, database.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return &o, nil
+}
+
+// GetOrganizationByShareToken returns the organization with shareToken, or (nil, nil).
+func (db *DB) GetOrganizationByShareToken(
+	ctx context.Context,
+	shareToken string,
+) (*models.Organization, error) {
+	if shareToken == "" {
+		return nil, fmt.Errorf("shareToken is required")
+	}
+	o, err := scanOrg(db.conn().queryRow(ctx,
+		`SELECT `+orgColumns+` FROM organizations WHERE share_token = ?`, shareToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return &o, nil
+}
+
+// UpdateOrganizationProfile sets Name and Description of orgID. Returns
+// database.ErrOrganizationNotFound if it does not exist.
+func (db *DB) UpdateOrganizationProfile(
+	ctx context.Context,
+	orgID, name, description string,
+) error {
+	if orgID == "" || name == "" {
+		return fmt.Errorf("orgID and name are required")
+	}
+	n, err := db.conn().exec(ctx, `UPDATE organizations SET name = ?, description = ?
+		WHERE id = ?`, name, description, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+	if n == 0 {
+		return database.ErrOrganizationNotFound
+	}
+	return nil
+}
+
+// DeleteOrganization deletes the organization with its members, invitations, goals and share
+// links. Returns database.ErrOrganizationNotFound if it does not exist.
+func (db *DB) DeleteOrganization(ctx context.Context, orgID string) error {
+	if orgID == "" {
+		return fmt.Errorf("orgID is required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		for _, table := range []string{"organization_members", "organization_invitations",
+			"organization_goals", "org_share_links"} {
+			_, err := c.exec(ctx, `DELETE FROM `+table+` WHERE organization_id = ?`, orgID)
+			if err != nil {
+				return fmt.Errorf("failed to delete %s: %w", table, err)
+			}
+		}
+		n, err := c.exec(ctx, `DELETE FROM organizations WHERE id = ?`, orgID)
+		if err != nil {
+			return fmt.Errorf("failed to delete organization: %w", err)
+		}
+		if n == 0 {
+			return database.ErrOrganizationNotFound
+		}
+		return nil
+	})
+}
+
+const orgMemberColumns = `user_id, role, name, image_url, joined_at`
+
+func scanOrgMember(s scanner) (models.OrganizationMember, error) {
+	var m models.OrganizationMember
+	err := s.Scan(&m.UserID, &m.Role, &m.Name, &m.ImageURL, &m.JoinedAt)
+	m.JoinedAt = m.JoinedAt.UTC()
+	return m, err
+}
+
+func (c conn) addOrgMember(ctx context.Context, orgID string, m models.OrganizationMember) error {
+	_, err := c.exec(ctx, `INSERT INTO organization_members
+		(organization_id, `+orgMemberColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		orgID, m.UserID, m.Role, m.Name, m.ImageURL, m.JoinedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+	return nil
+}
+
+// GetOrganizationMembers lists the members of an organization, ordered by JoinedAt.
+func (db *DB) GetOrganizationMembers(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationMember, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT `+orgMemberColumns+` FROM organization_members
+		WHERE organization_id = ? ORDER BY joined_at, user_id`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	members, err := scanAll(rows, scanOrgMember)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	return members, nil
+}
+
+// GetOrganizationMember returns the membership of userID, or database.ErrOrgMemberNotFound when
+// the user is not a member (or the organization does not exist).
+func (db *DB) GetOrganizationMember(
+	ctx context.Context,
+	orgID, userID string,
+) (*models.OrganizationMember, error) {
+	if orgID == "" || userID == "" {
+		return nil, fmt.Errorf("orgID and userID are required")
+	}
+	m, err := scanOrgMember(db.conn().queryRow(ctx, `SELECT `+orgMemberColumns+`
+		FROM organization_members WHERE organization_id = ? AND user_id = ?`, orgID, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, database.ErrOrgMemberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	return &m, nil
+}
+
+// GetOrganizationsByUser returns the organizations userID is a member of, with the user's role.
+func (db *DB) GetOrganizationsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.OrganizationMembership, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT m.role, o.id, o.name, o.description,
+		o.share_token, o.created_by, o.created_at, o.member_count
+		FROM organization_members m JOIN organizations o ON o.id = m.organization_id
+		WHERE m.user_id = ? ORDER BY o.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	memberships, err := scanAll(rows, func(s scanner) (models.OrganizationMembership, error) {
+		var m models.OrganizationMembership
+		o, err := scanOrg(prefixScanner{s, &m.Role})
+		m.Organization = o
+		return m, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return memberships, nil
+}
+
+// UpdateOrganizationMemberRole sets the Role of a membership. Returns
+// database.ErrOrgMemberNotFound if the user is not a member.
+func (db *DB) UpdateOrganizationMemberRole(
+	ctx context.Context,
+	orgID, userID, role string,
+) error {
+	if orgID == "" || userID == "" || role == "" {
+		return fmt.Errorf("orgID, userID and role are required")
+	}
+	n, err := db.conn().exec(ctx, `UPDATE organization_members SET role = ?
+		WHERE organization_id = ? AND user_id = ?`, role, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+	if n == 0 {
+		return database.ErrOrgMemberNotFound
+	}
+	return nil
+}
+
+// RemoveOrganizationMember deletes a membership and decrements MemberCount. Returns
+// database.ErrOrgMemberNotFound if the user is not a member.
+func (db *DB) RemoveOrganizationMember(ctx context.Context, orgID, userID string) error {
+	if orgID == "" || userID == "" {
+		return fmt.Errorf("orgID and userID are required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		n, err := c.exec(ctx, `DELETE FROM organization_members
+			WHERE organization_id = ? AND user_id = ?`, orgID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove organization member: %w", err)
+		}
+		if n == 0 {
+			return database.ErrOrgMemberNotFound
+		}
+		return c.addOrgMemberCount(ctx, orgID, -1)
+	})
+}
+
+func (c conn) addOrgMemberCount(ctx context.Context, orgID string, delta int) error {
+	_, err := c.exec(ctx, `UPDATE organizations SET member_count = member_count + ?
+		WHERE id = ?`, delta, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to update member count: %w", err)
+	}
+	return nil
+}
+
+const orgInvitationColumns = `code, organization_id, role, created_by, created_at, expires_at`
+
+func scanOrgInvitation(s scanner) (models.OrganizationInvitation, error) {
+	var inv models.OrganizationInvitation
+	err := s.Scan(&inv.Code, &inv.OrganizationID, &inv.Role, &inv.CreatedBy, &inv.CreatedAt,
+		&inv.ExpiresAt)
+	inv.CreatedAt = inv.CreatedAt.UTC()
+	inv.ExpiresAt = inv.ExpiresAt.UTC()
+	return inv, err
+}
+
+// CreateOrganizationInvitation stores a new invitation under a random code, expiring after
+// models.OrgInvitationTTL. Returns database.ErrTooManyOrgInvitations when the organization
+// already has models.MaxOrgPendingInvitations invitations.
+func (db *DB) CreateOrganizationInvitation(
+	ctx context.Context,
+	inv *models.OrganizationInvitation,
+) (*models.OrganizationInvitation, error) {
+	if inv == nil || inv.OrganizationID == "" || inv.Role == "" {
+		return nil, fmt.Errorf("organizationID and role are required")
+	}
+	out := *inv
+	out.Code = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.ExpiresAt = out.CreatedAt.Add(models.OrgInvitationTTL)
+	err := db.tx(ctx, func(c conn) error {
+		invitations, err := c.orgInvitations(ctx, out.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if len(invitations) >= models.MaxOrgPendingInvitations {
+			return database.ErrTooManyOrgInvitations
+		}
+		_, err = c.exec(ctx, `INSERT INTO organization_invitations (`+orgInvitationColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?)`, out.Code, out.OrganizationID, out.Role, out.CreatedBy,
+			out.CreatedAt, out.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to create organization invitation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrganizationInvitations lists the invitations of an organization, expired ones included.
+func (db *DB) GetOrganizationInvitations(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationInvitation, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	return db.conn().orgInvitations(ctx, orgID)
+}
+
+func (c conn) orgInvitations(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationInvitation, error) {
+	rows, err := c.query(ctx, `SELECT `+orgInvitationColumns+` FROM organization_invitations
+		WHERE organization_id = ? ORDER BY code`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization invitations: %w", err)
+	}
+	invitations, err := scanAll(rows, scanOrgInvitation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization invitations: %w", err)
+	}
+	if invitations == nil {
+		invitations = []models.OrganizationInvitation{}
+	}
+	return invitations, nil
+}
+
+// GetOrganizationInvitation returns the invitation code. Returns
+// database.ErrOrgInvitationNotFound if it is missing or expired.
+func (db *DB) GetOrganizationInvitation(
+	ctx context.Context,
+	code string,
+) (*models.OrganizationInvitation, error) {
+	if code == "" {
+		return nil, fmt.Errorf("code is required")
+	}
+	return db.conn().orgInvitation(ctx, code)
+}
+
+// orgInvitation returns the invitation code, treating expired ones as missing.
+func (c conn) orgInvitation(
+	ctx context.Context,
+	code string,
+) (*models.OrganizationInvitation, error) {
+	inv, err := scanOrgInvitation(c.queryRow(ctx, `SELECT `+orgInvitationColumns+`
+		FROM organization_invitations WHERE code = ?`, code))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !time.Now().Before(inv.ExpiresAt)) {
+		return nil, database.ErrOrgInvitationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization invitation: %w", err)
+	}
+	return &inv, nil
+}
+
+// AcceptOrganizationInvitation adds member with the invitation's role, increments MemberCount
+// and deletes the single-use invitation. Returns the joined organization, or
+// database.ErrOrgInvitationNotFound (missing or expired), database.ErrOrganizationNotFound,
+// database.ErrOrgMemberAlreadyExists or database.ErrOrganizationFull.
+func (db *DB) AcceptOrganizationInvitation(
+	ctx context.Context,
+	code string,
+	member models.OrganizationMember,
+) (*models.Organization, error) {
+	if code == "" || member.UserID == "" {
+		return nil, fmt.Errorf("code and member user ID are required")
+	}
+	var org *models.Organization
+	err := db.tx(ctx, func(c conn) error {
+		inv, err := c.orgInvitation(ctx, code)
+		if err != nil {
+			return err
+		}
+		if org, err = c.org(ctx, inv.OrganizationID); err != nil {
+			return err
+		}
+		var n int
+		err = c.queryRow(ctx, `SELECT COUNT(*) FROM organization_members
+			WHERE organization_id = ? AND user_id = ?`, org.ID, member.UserID).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("failed to check organization member: %w", err)
+		}
+		if n > 0 {
+			return database.ErrOrgMemberAlreadyExists
+		}
+		if org.MemberCount >= models.MaxOrgMembers {
+			return database.ErrOrganizationFull
+		}
+		member.Role = inv.Role
+		if err := c.addOrgMember(ctx, org.ID, member); err != nil {
+			return err
+		}
+		if err := c.addOrgMemberCount(ctx, org.ID, 1); err != nil {
+			return err
+		}
+		org.MemberCount++
+		_, err = c.exec(ctx, `DELETE FROM organization_invitations WHERE code = ?`, code)
+		if err != nil {
+			return fmt.Errorf("failed to delete organization invitation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// DeleteOrganizationInvitation revokes an invitation of orgID. Returns
+// database.ErrOrgInvitationNotFound if there is no such invitation for that organization.
+func (db *DB) DeleteOrganizationInvitation(ctx context.Context, orgID, code string) error {
+	if orgID == "" || code == "" {
+		return fmt.Errorf("orgID and code are required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM organization_invitations
+		WHERE code = ? AND organization_id = ?`, code, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization invitation: %w", err)
+	}
+	if n == 0 {
+		return database.ErrOrgInvitationNotFound
+	}
+	return nil
+}
+
+const orgGoalColumns = `id, title, country_codes, target_count, year, created_by, created_at`
+
+// GetOrganizationGoals lists the goals of an organization, ordered by CreatedAt.
+func (db *DB) GetOrganizationGoals(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrganizationGoal, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	return db.conn().orgGoals(ctx, orgID)
+}
+
+func (c conn) orgGoals(ctx context.Context, orgID string) ([]models.OrganizationGoal, error) {
+	rows, err := c.query(ctx, `SELECT `+orgGoalColumns+` FROM organization_goals
+		WHERE organization_id = ? ORDER BY created_at, id`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization goals: %w", err)
+	}
+	goals, err := scanAll(rows, func(s scanner) (models.OrganizationGoal, error) {
+		var g models.OrganizationGoal
+		var countryCodes sql.NullString
+		err := s.Scan(&g.ID, &g.Title, &countryCodes, &g.TargetCount, &g.Year, &g.CreatedBy,
+			&g.CreatedAt)
+		if err != nil {
+			return g, err
+		}
+		g.CreatedAt = g.CreatedAt.UTC()
+		return g, fromJSON(countryCodes, &g.CountryCodes)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization goals: %w", err)
+	}
+	if goals == nil {
+		goals = []models.OrganizationGoal{}
+	}
+	return goals, nil
+}
+
+// CreateOrganizationGoal adds a goal to orgID. Returns database.ErrOrganizationNotFound for an
+// unknown organization and database.ErrTooManyOrgGoals when it already has models.MaxOrgGoals
+// goals.
+func (db *DB) CreateOrganizationGoal(
+	ctx context.Context,
+	orgID string,
+	goal *models.OrganizationGoal,
+) (*models.OrganizationGoal, error) {
+	if orgID == "" || goal == nil {
+		return nil, fmt.Errorf("orgID and goal are required")
+	}
+	out := *goal
+	out.ID = newID()
+	out.CreatedAt = time.Now().UTC()
+	var countryCodes sql.NullString
+	if len(out.CountryCodes) > 0 {
+		s, err := toJSON(out.CountryCodes)
+		if err != nil {
+			return nil, err
+		}
+		countryCodes = sql.NullString{String: s, Valid: true}
+	}
+	err := db.tx(ctx, func(c conn) error {
+		if _, err := c.org(ctx, orgID); err != nil {
+			return err
+		}
+		goals, err := c.orgGoals(ctx, orgID)
+		if err != nil {
+			return err
+		}
+		if len(goals) >= models.MaxOrgGoals {
+			return database.ErrTooManyOrgGoals
+		}
+		_, err = c.exec(ctx, `INSERT INTO organization_goals (organization_id, `+orgGoalColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, orgID, out.ID, out.Title, countryCodes,
+			out.TargetCount, out.Year, out.CreatedBy, out.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create organization goal: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteOrganizationGoal deletes a goal of orgID. Returns database.ErrOrgGoalNotFound if it does
+// not exist.
+func (db *DB) DeleteOrganizationGoal(ctx context.Context, orgID, goalID string) error {
+	if orgID == "" || goalID == "" {
+		return fmt.Errorf("orgID and goalID are required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM organization_goals
+		WHERE organization_id = ? AND id = ?`, orgID, goalID)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization goal: %w", err)
+	}
+	if n == 0 {
+		return database.ErrOrgGoalNotFound
+	}
+	return nil
+}
+
+const orgShareLinkColumns = `token, organization_id, scope, created_by, created_at`
+
+func scanOrgShareLink(s scanner) (models.OrgShareLink, error) {
+	var l models.OrgShareLink
+	err := s.Scan(&l.Token, &l.OrganizationID, &l.Scope, &l.CreatedBy, &l.CreatedAt)
+	l.CreatedAt = l.CreatedAt.UTC()
+	return l, err
+}
+
+// CreateOrgShareLink mints a share link with a fresh token. Returns
+// database.ErrTooManyOrgShareLinks when the organization already has models.MaxOrgShareLinks
+// links.
+func (db *DB) CreateOrgShareLink(
+	ctx context.Context,
+	link *models.OrgShareLink,
+) (*models.OrgShareLink, error) {
+	if link == nil || link.OrganizationID == "" || link.Scope == "" {
+		return nil, fmt.Errorf("organizationID and scope are required")
+	}
+	out := *link
+	out.Token = newID()
+	out.CreatedAt = time.Now().UTC()
+	err := db.tx(ctx, func(c conn) error {
+		links, err := c.orgShareLinks(ctx, out.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if len(links) >= models.MaxOrgShareLinks {
+			return database.ErrTooManyOrgShareLinks
+		}
+		_, err = c.exec(ctx, `INSERT INTO org_share_links (`+orgShareLinkColumns+`)
+			VALUES (?, ?, ?, ?, ?)`, out.Token, out.OrganizationID, out.Scope, out.CreatedBy,
+			out.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create organization share link: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrgShareLinks lists the share links of an organization.
+func (db *DB) GetOrgShareLinks(
+	ctx context.Context,
+	orgID string,
+) ([]models.OrgShareLink, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("orgID is required")
+	}
+	return db.conn().orgShareLinks(ctx, orgID)
+}
+
+func (c conn) orgShareLinks(ctx context.Context, orgID string) ([]models.OrgShareLink, error) {
+	rows, err := c.query(ctx, `SELECT `+orgShareLinkColumns+` FROM org_share_links
+		WHERE organization_id = ? ORDER BY token`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization share links: %w", err)
+	}
+	links, err := scanAll(rows, scanOrgShareLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization share links: %w", err)
+	}
+	if links == nil {
+		links = []models.OrgShareLink{}
+	}
+	return links, nil
+}
+
+// GetOrgShareLink returns the share link with token, or nil (not error) when there is none.
+func (db *DB) GetOrgShareLink(ctx context.Context, token string) (*models.OrgShareLink, error) {
+	if token == "" {
+		return nil, nil
+	}
+	l, err := scanOrgShareLink(db.conn().queryRow(ctx,
+		`SELECT `+orgShareLinkColumns+` FROM org_share_links WHERE token = ?`, token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization share link: %w", err)
+	}
+	return &l, nil
+}
+
+// DeleteOrgShareLink revokes a share link of orgID. Returns database.ErrOrgShareLinkNotFound if
+// there is no such link for that organization.
+func (db *DB) DeleteOrgShareLink(ctx context.Context, orgID, token string) error {
+	if orgID == "" || token == "" {
+		return fmt.Errorf("orgID and token are required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM org_share_links
+		WHERE token = ? AND organization_id = ?`, token, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization share link: %w", err)
+	}
+	if n == 0 {
+		return database.ErrOrgShareLinkNotFound
+	}
+	return nil
+}
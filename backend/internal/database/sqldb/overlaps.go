@@ -0,0 +1,134 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const overlapColumns = `id, visit_id, friend_user_id, friend_share_token, friend_visit_id,
+	created_at`
+
+func scanOverlap(s scanner) (models.VisitOverlap, error) {
+	var o models.VisitOverlap
+	err := s.Scan(&o.ID, &o.VisitID, &o.FriendUserID, &o.FriendShareToken, &o.FriendVisitID,
+		&o.CreatedAt)
+	o.CreatedAt = o.CreatedAt.UTC()
+	return o, err
+}
+
+func (c conn) putOverlap(ctx context.Context, userID string, o models.VisitOverlap) error {
+	_, err := c.exec(ctx, `INSERT INTO visit_overlaps (user_id, `+overlapColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`, userID, o.ID, o.VisitID, o.FriendUserID,
+		o.FriendShareToken, o.FriendVisitID, o.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create visit overlap: %w", err)
+	}
+	return nil
+}
+
+// CreateVisitOverlap links the visit overlap.VisitID of userID (ShareToken shareToken) to the
+// visit overlap.FriendVisitID of overlap.FriendUserID. Both users get a copy under one new ID
+// and their VisitsRevision is incremented. Returns database.ErrVisitNotFound when either visit
+// is gone and database.ErrOverlapExists when userID already linked a visit to the friend's
+// visit.
+func (db *DB) CreateVisitOverlap(
+	ctx context.Context,
+	userID, shareToken string,
+	overlap *models.VisitOverlap,
+) (*models.VisitOverlap, error) {
+	if userID == "" || overlap == nil || overlap.FriendUserID == "" {
+		return nil, fmt.Errorf("userID and overlap friend user ID are required")
+	}
+	out := *overlap
+	out.CreatedAt = time.Now().UTC()
+	out.ID = newID()
+	err := db.tx(ctx, func(c conn) error {
+		if _, err := c.countryVisit(ctx, out.VisitID, userID); err != nil {
+			return err
+		}
+		if _, err := c.countryVisit(ctx, out.FriendVisitID, out.FriendUserID); err != nil {
+			return err
+		}
+		var n int
+		err := c.queryRow(ctx, `SELECT COUNT(*) FROM visit_overlaps
+			WHERE user_id = ? AND friend_user_id = ? AND friend_visit_id = ?`,
+			userID, out.FriendUserID, out.FriendVisitID).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("failed to check visit overlaps: %w", err)
+		}
+		if n > 0 {
+			return database.ErrOverlapExists
+		}
+		if err := c.putOverlap(ctx, userID, out); err != nil {
+			return err
+		}
+		err = c.putOverlap(ctx, out.FriendUserID, models.VisitOverlap{
+			ID:               out.ID,
+			VisitID:          out.FriendVisitID,
+			FriendUserID:     userID,
+			FriendShareToken: shareToken,
+			FriendVisitID:    out.VisitID,
+			CreatedAt:        out.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		return c.bumpVisitsRevision(ctx, userID, out.FriendUserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetVisitOverlaps returns the overlaps of all visits of userID, oldest first.
+func (db *DB) GetVisitOverlaps(
+	ctx context.Context,
+	userID string,
+) ([]models.VisitOverlap, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT `+overlapColumns+` FROM visit_overlaps
+		WHERE user_id = ? ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visit overlaps: %w", err)
+	}
+	overlaps, err := scanAll(rows, scanOverlap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visit overlaps: %w", err)
+	}
+	if overlaps == nil {
+		overlaps = []models.VisitOverlap{}
+	}
+	return overlaps, nil
+}
+
+// DeleteVisitOverlap deletes the overlap overlapID of userID together with the friend's copy.
+// Returns database.ErrOverlapNotFound when userID has no such overlap.
+func (db *DB) DeleteVisitOverlap(ctx context.Context, userID, overlapID string) error {
+	if userID == "" || overlapID == "" {
+		return fmt.Errorf("userID and overlapID are required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		var friendUserID string
+		err := c.queryRow(ctx, `SELECT friend_user_id FROM visit_overlaps
+			WHERE user_id = ? AND id = ?`, userID, overlapID).Scan(&friendUserID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.ErrOverlapNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get visit overlap: %w", err)
+		}
+		if _, err := c.exec(ctx, `DELETE FROM visit_overlaps WHERE id = ?`, overlapID); err != nil {
+			return fmt.Errorf("failed to delete visit overlap: %w", err)
+		}
+		return c.bumpVisitsRevision(ctx, userID, friendUserID)
+	})
+}
@@ -0,0 +1,41 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx driver
+)
+
+var postgresDialect = dialect{
+	name:           "postgres",
+	numberedParams: true,
+	txOptions:      &sql.TxOptions{Isolation: sql.LevelSerializable},
+	retryable: func(err error) bool {
+		var pgErr *pgconn.PgError
+		// serialization_failure, deadlock_detected
+		return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+	},
+}
+
+// OpenPostgres connects to the PostgreSQL database at url (a postgres:// URL or key=value
+// connection string) and brings its schema up to date.
+func OpenPostgres(ctx context.Context, url string) (*DB, error) {
+	sqlDB, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres database: %w", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to connect to postgres database: %w", err)
+	}
+	db := &DB{db: sqlDB, dialect: postgresDialect}
+	if err := db.migrate(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
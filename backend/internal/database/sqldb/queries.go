@@ -0,0 +1,898 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const userColumns = `id, share_token, name, email, image_url, settings, is_anonymous,
+	visits_revision, visit_count, distinct_countries, handle, sharing_disabled,
+	tokens_revoked_at, deletion_scheduled_at, limits`
+
+// scanUser scans userColumns, applying DefaultUserSettings when Settings is unset.
+func scanUser(s scanner) (*models.User, error) {
+	var u models.User
+	var settings, limits sql.NullString
+	var revokedAt, deletionAt sql.NullTime
+	err := s.Scan(&u.ID, &u.ShareToken, &u.Name, &u.Email, &u.ImageURL, &settings,
+		&u.IsAnonymous, &u.VisitsRevision, &u.VisitCount, &u.DistinctCountries, &u.Handle,
+		&u.SharingDisabled, &revokedAt, &deletionAt, &limits)
+	if err != nil {
+		return nil, err
+	}
+	if limits.Valid {
+		u.Limits = &models.UserLimits{}
+		if err := fromJSON(limits, u.Limits); err != nil {
+			return nil, err
+		}
+	}
+	u.UserID = u.ID
+	u.TokensRevokedAt = timePtr(revokedAt)
+	u.DeletionScheduledAt = timePtr(deletionAt)
+	if settings.Valid {
+		u.Settings = &models.UserSettings{}
+		if err := fromJSON(settings, u.Settings); err != nil {
+			return nil, err
+		}
+	} else {
+		defaults := models.DefaultUserSettings()
+		u.Settings = &defaults
+	}
+	return &u, nil
+}
+
+// user returns the user userID, or (nil, nil) when there is none.
+func (c conn) user(ctx context.Context, userID string) (*models.User, error) {
+	u, err := scanUser(c.queryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return u, nil
+}
+
+// userByShareToken returns the user with shareToken, or (nil, nil) when there is none.
+func (c conn) userByShareToken(ctx context.Context, shareToken string) (*models.User, error) {
+	u, err := scanUser(c.queryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE share_token = ?`, shareToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by share token: %w", err)
+	}
+	return u, nil
+}
+
+// bumpVisitsRevision increments VisitsRevision of each user once; missing users are skipped.
+func (c conn) bumpVisitsRevision(ctx context.Context, userIDs ...string) error {
+	seen := make(map[string]bool, len(userIDs))
+	var ids []string
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := c.exec(ctx, `UPDATE users SET visits_revision = visits_revision + 1
+		WHERE id IN (`+placeholders(len(ids))+`)`, anys(ids)...)
+	if err != nil {
+		return fmt.Errorf("failed to increment visits revision: %w", err)
+	}
+	return nil
+}
+
+const visitColumns = `id, user_id, country_code, visited_time, media_url, notes, tags,
+	is_private, subdivision_code, destination_code, visit_type, location, companions, proofs,
+	updated_at`
+
+func scanVisit(s scanner) (models.CountryVisit, error) {
+	var v models.CountryVisit
+	var tags, location, companions, proofs sql.NullString
+	var updatedAt sql.NullTime
+	err := s.Scan(&v.ID, &v.UserID, &v.CountryCode, &v.VisitedTime, &v.MediaURL, &v.Notes,
+		&tags, &v.IsPrivate, &v.SubdivisionCode, &v.DestinationCode, &v.VisitType, &location,
+		&companions, &proofs, &updatedAt)
+	if err != nil {
+		return v, err
+	}
+	v.VisitedTime = v.VisitedTime.UTC()
+	v.UpdatedAt = timePtr(updatedAt)
+	v.Tags = []string{}
+	for _, field := range []struct {
+		raw sql.NullString
+		v   any
+	}{{tags, &v.Tags}, {location, &v.Location}, {companions, &v.Companions}, {proofs, &v.Proofs}} {
+		if err := fromJSON(field.raw, field.v); err != nil {
+			return v, err
+		}
+	}
+	v.Verified = len(v.Proofs) > 0
+	return v, nil
+}
+
+// storedVisit returns the fields of visit the Firestore client stores (see countryVisitDoc),
+// normalized the same way.
+func storedVisit(visit *models.CountryVisit) models.CountryVisit {
+	out := models.CountryVisit{
+		ID:              visit.ID,
+		UserID:          visit.UserID,
+		CountryCode:     visit.CountryCode,
+		VisitedTime:     visit.VisitedTime.UTC(),
+		Tags:            visit.Tags,
+		Notes:           visit.Notes,
+		IsPrivate:       visit.IsPrivate,
+		VisitType:       visit.VisitType,
+		SubdivisionCode: visit.SubdivisionCode,
+		DestinationCode: visit.DestinationCode,
+		Location:        visit.Location,
+		Companions:      visit.Companions,
+		Proofs:          visit.Proofs,
+		UpdatedAt:       visit.UpdatedAt,
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if visit.MediaURL != nil && *visit.MediaURL != "" {
+		out.MediaURL = visit.MediaURL
+	}
+	if len(out.Companions) == 0 {
+		out.Companions = nil
+	}
+	if len(out.Proofs) == 0 {
+		out.Proofs = nil
+	}
+	return out
+}
+
+// visitValues returns the values of visitColumns for a stored visit.
+func visitValues(v models.CountryVisit) ([]any, error) {
+	tags, err := toJSON(v.Tags)
+	if err != nil {
+		return nil, err
+	}
+	var location, companions, proofs sql.NullString
+	for _, field := range []struct {
+		v   any
+		set bool
+		out *sql.NullString
+	}{
+		{v.Location, v.Location != nil, &location},
+		{v.Companions, v.Companions != nil, &companions},
+		{v.Proofs, v.Proofs != nil, &proofs},
+	} {
+		if field.set {
+			if field.out.String, err = toJSON(field.v); err != nil {
+				return nil, err
+			}
+			field.out.Valid = true
+		}
+	}
+	return []any{v.ID, v.UserID, v.CountryCode, v.VisitedTime, v.MediaURL, v.Notes, tags,
+		v.IsPrivate, v.SubdivisionCode, v.DestinationCode, v.VisitType, location, companions,
+		proofs, utcPtr(v.UpdatedAt)}, nil
+}
+
+// putVisit inserts or replaces a stored visit.
+func (c conn) putVisit(ctx context.Context, v models.CountryVisit) error {
+	values, err := visitValues(v)
+	if err != nil {
+		return err
+	}
+	_, err = c.exec(ctx, `INSERT INTO country_visits (`+visitColumns+`)
+		VALUES (`+placeholders(len(values))+`)
+		ON CONFLICT (user_id, id) DO UPDATE SET country_code = excluded.country_code,
+			visited_time = excluded.visited_time, media_url = excluded.media_url,
+			notes = excluded.notes, tags = excluded.tags, is_private = excluded.is_private,
+			subdivision_code = excluded.subdivision_code,
+			destination_code = excluded.destination_code, visit_type = excluded.visit_type,
+			location = excluded.location, companions = excluded.companions,
+			proofs = excluded.proofs, updated_at = excluded.updated_at`, values...)
+	if err != nil {
+		return fmt.Errorf("failed to write country visit: %w", err)
+	}
+	return nil
+}
+
+// GetCountryVisitsByUser returns all country visits of userID in ID order.
+func (db *DB) GetCountryVisitsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.CountryVisit, error) {
+	return db.conn().countryVisits(ctx, userID, true)
+}
+
+// GetPublicCountryVisitsByUser returns the country visits of userID except private ones.
+func (db *DB) GetPublicCountryVisitsByUser(
+	ctx context.Context,
+	userID string,
+) ([]models.CountryVisit, error) {
+	return db.conn().countryVisits(ctx, userID, false)
+}
+
+func (c conn) countryVisits(
+	ctx context.Context,
+	userID string,
+	includePrivate bool,
+) ([]models.CountryVisit, error) {
+	query := `SELECT ` + visitColumns + ` FROM country_visits WHERE user_id = ?`
+	args := []any{userID}
+	if !includePrivate {
+		query += ` AND is_private = ?`
+		args = append(args, false)
+	}
+	rows, err := c.query(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list country visits: %w", err)
+	}
+	visits, err := scanAll(rows, scanVisit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list country visits: %w", err)
+	}
+	return visits, nil
+}
+
+// GetVisitedCountryCodes returns those of countryCodes that userID has at least one visit of,
+// in the order given.
+func (db *DB) GetVisitedCountryCodes(
+	ctx context.Context,
+	userID string,
+	countryCodes []string,
+) ([]string, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	out := []string{}
+	if len(countryCodes) == 0 {
+		return out, nil
+	}
+	rows, err := db.conn().query(ctx, `SELECT DISTINCT country_code FROM country_visits
+		WHERE user_id = ? AND country_code IN (`+placeholders(len(countryCodes))+`)`,
+		append([]any{userID}, anys(countryCodes)...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visited countries: %w", err)
+	}
+	codes, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visited countries: %w", err)
+	}
+	visited := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		visited[code] = true
+	}
+	for _, code := range countryCodes {
+		if visited[code] {
+			out = append(out, code)
+			visited[code] = false
+		}
+	}
+	return out, nil
+}
+
+// GetCountryVisitsPage returns up to limit visits of userID in ID order after the visit with ID
+// cursor; nextCursor is set when the page is full.
+func (db *DB) GetCountryVisitsPage(
+	ctx context.Context,
+	userID, cursor string,
+	limit int,
+) ([]models.CountryVisit, string, error) {
+	if userID == "" || limit <= 0 {
+		return nil, "", fmt.Errorf("userID and a positive limit are required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT `+visitColumns+` FROM country_visits
+		WHERE user_id = ? AND id > ? ORDER BY id LIMIT ?`, userID, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list country visits: %w", err)
+	}
+	visits, err := scanAll(rows, scanVisit)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list country visits: %w", err)
+	}
+	if visits == nil {
+		visits = []models.CountryVisit{}
+	}
+	nextCursor := ""
+	if len(visits) == limit {
+		nextCursor = visits[len(visits)-1].ID
+	}
+	return visits, nextCursor, nil
+}
+
+// GetCountryVisit returns the visit visitID of userID, or database.ErrVisitNotFound.
+func (db *DB) GetCountryVisit(
+	ctx context.Context,
+	visitID, userID string,
+) (*models.CountryVisit, error) {
+	if visitID == "" || userID == "" {
+		return nil, fmt.Errorf("visitID and userID are required")
+	}
+	return db.conn().countryVisit(ctx, visitID, userID)
+}
+
+func (c conn) countryVisit(
+	ctx context.Context,
+	visitID, userID string,
+) (*models.CountryVisit, error) {
+	v, err := scanVisit(c.queryRow(ctx, `SELECT `+visitColumns+` FROM country_visits
+		WHERE user_id = ? AND id = ?`, userID, visitID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, database.ErrVisitNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get country visit: %w", err)
+	}
+	return &v, nil
+}
+
+// CreateCountryVisit stores a new visit of visit.UserID, records a created history event,
+// increments the user's VisitsRevision and adjusts its visit stats. Fails with a
+// database.LimitError when the user has maxVisits visits or their override's MaxVisits.
+func (db *DB) CreateCountryVisit(
+	ctx context.Context,
+	visit *models.CountryVisit,
+	maxVisits int,
+) (*models.CountryVisit, error) {
+	if visit == nil {
+		return nil, fmt.Errorf("visit is required")
+	}
+	if visit.UserID == "" || visit.CountryCode == "" {
+		return nil, fmt.Errorf("user_id and country_code are required")
+	}
+	doc := storedVisit(visit)
+	doc.ID = newID()
+	updatedAt := models.NextVisitUpdatedAt(nil, time.Now())
+	doc.UpdatedAt = &updatedAt
+	actorID := actorIDFromContext(ctx, visit.UserID)
+	err := db.tx(ctx, func(c conn) error {
+		u, err := c.user(ctx, doc.UserID)
+		if err != nil {
+			return err
+		}
+		if u != nil {
+			limits := u.Limits.Or(models.UserLimits{MaxVisits: maxVisits})
+			if limits.MaxVisits > 0 && u.VisitCount >= int64(limits.MaxVisits) {
+				return database.NewLimitError(database.ErrVisitLimitReached, doc.UserID,
+					limits.MaxVisits)
+			}
+		}
+		if err := c.putVisit(ctx, doc); err != nil {
+			return err
+		}
+		err = c.addHistoryEvent(ctx, doc.UserID, doc.ID, models.VisitEventCreated, actorID,
+			nil, &doc)
+		if err != nil {
+			return err
+		}
+		if err := c.updateVisitStats(ctx, doc.UserID, "", doc.CountryCode); err != nil {
+			return err
+		}
+		return c.bumpVisitsRevision(ctx, doc.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := *visit
+	out.Tags = doc.Tags
+	out.ID = doc.ID
+	out.UpdatedAt = doc.UpdatedAt
+	return &out, nil
+}
+
+// ReplaceCountryVisit writes the full visit and sets visit.UpdatedAt, records an updated (or, for
+// a new visit, created) history event and increments the user's VisitsRevision. Unless
+// unmodifiedSince is zero, returns database.ErrVisitModified when the stored visit was written
+// after it.
+func (db *DB) ReplaceCountryVisit(
+	ctx context.Context,
+	visit *models.CountryVisit,
+	unmodifiedSince time.Time,
+) error {
+	if visit == nil || visit.ID == "" || visit.UserID == "" {
+		return fmt.Errorf("visit with id and userID is required")
+	}
+	doc := storedVisit(visit)
+	actorID := actorIDFromContext(ctx, visit.UserID)
+	err := db.tx(ctx, func(c conn) error {
+		before, err := c.countryVisit(ctx, doc.ID, doc.UserID)
+		if err != nil && !errors.Is(err, database.ErrVisitNotFound) {
+			return err
+		}
+		eventType := models.VisitEventUpdated
+		removedCode := ""
+		var prev *time.Time
+		if before == nil {
+			eventType = models.VisitEventCreated
+		} else {
+			if !unmodifiedSince.IsZero() && before.ModifiedSince(unmodifiedSince) {
+				return database.ErrVisitModified
+			}
+			removedCode = before.CountryCode
+			prev = before.UpdatedAt
+		}
+		updatedAt := models.NextVisitUpdatedAt(prev, time.Now())
+		doc.UpdatedAt = &updatedAt
+		if err := c.putVisit(ctx, doc); err != nil {
+			return err
+		}
+		err = c.addHistoryEvent(ctx, doc.UserID, doc.ID, eventType, actorID, before, &doc)
+		if err != nil {
+			return err
+		}
+		if err := c.updateVisitStats(ctx, doc.UserID, removedCode, doc.CountryCode); err != nil {
+			return err
+		}
+		return c.bumpVisitsRevision(ctx, doc.UserID)
+	})
+	if err != nil {
+		return err
+	}
+	visit.UpdatedAt = doc.UpdatedAt
+	return nil
+}
+
+// DeleteCountryVisit deletes the visit visitID of userID with its overlaps (both copies),
+// records a deleted history event, adjusts the user's visit stats and increments the
+// VisitsRevision of the user and of the friends whose overlaps were deleted. Returns
+// database.ErrVisitNotFound if it does not exist.
+func (db *DB) DeleteCountryVisit(ctx context.Context, visitID string, userID string) error {
+	if visitID == "" || userID == "" {
+		return fmt.Errorf("visitID and userID are required")
+	}
+	actorID := actorIDFromContext(ctx, userID)
+	return db.tx(ctx, func(c conn) error {
+		before, err := c.countryVisit(ctx, visitID, userID)
+		if err != nil {
+			return err
+		}
+		rows, err := c.query(ctx, `SELECT `+overlapColumns+` FROM visit_overlaps
+			WHERE user_id = ? AND visit_id = ?`, userID, visitID)
+		if err != nil {
+			return fmt.Errorf("failed to list visit overlaps: %w", err)
+		}
+		overlaps, err := scanAll(rows, scanOverlap)
+		if err != nil {
+			return fmt.Errorf("failed to list visit overlaps: %w", err)
+		}
+		revisionUserIDs := []string{userID}
+		for _, o := range overlaps {
+			// Both copies of an overlap share its ID
+			if _, err := c.exec(ctx, `DELETE FROM visit_overlaps WHERE id = ?`, o.ID); err != nil {
+				return fmt.Errorf("failed to delete visit overlap: %w", err)
+			}
+			revisionUserIDs = append(revisionUserIDs, o.FriendUserID)
+		}
+		_, err = c.exec(ctx, `DELETE FROM country_visits WHERE user_id = ? AND id = ?`,
+			userID, visitID)
+		if err != nil {
+			return fmt.Errorf("failed to delete country visit: %w", err)
+		}
+		err = c.addHistoryEvent(ctx, userID, visitID, models.VisitEventDeleted, actorID,
+			before, nil)
+		if err != nil {
+			return err
+		}
+		if err := c.updateVisitStats(ctx, userID, before.CountryCode, ""); err != nil {
+			return err
+		}
+		return c.bumpVisitsRevision(ctx, revisionUserIDs...)
+	})
+}
+
+// updateVisitStats adjusts visit_count and distinct_countries of userID after a write that
+// removed a visit of removedCode and/or added one of addedCode ("" for none).
+func (c conn) updateVisitStats(ctx context.Context, userID, removedCode, addedCode string) error {
+	if removedCode == addedCode {
+		return nil
+	}
+	var visitDelta, countryDelta int
+	for _, code := range []string{removedCode, addedCode} {
+		if code == "" {
+			continue
+		}
+		var n int
+		err := c.queryRow(ctx, `SELECT COUNT(*) FROM country_visits
+			WHERE user_id = ? AND country_code = ?`, userID, code).Scan(&n)
+		if err != nil {
+			return fmt.Errorf("failed to count country visits: %w", err)
+		}
+		if code == removedCode {
+			visitDelta--
+			if n == 0 {
+				countryDelta--
+			}
+		} else {
+			visitDelta++
+			if n == 1 {
+				countryDelta++
+			}
+		}
+	}
+	_, err := c.exec(ctx, `UPDATE users SET visit_count = visit_count + ?,
+		distinct_countries = distinct_countries + ? WHERE id = ?`, visitDelta, countryDelta, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update visit stats: %w", err)
+	}
+	return nil
+}
+
+// addHistoryEvent records a history event of the visit visitID of userID; before and after are
+// nil when the visit did not exist before or no longer exists after the change.
+func (c conn) addHistoryEvent(
+	ctx context.Context,
+	userID, visitID, eventType, actorID string,
+	before, after *models.CountryVisit,
+) error {
+	var values [2]sql.NullString
+	for i, v := range []*models.CountryVisit{before, after} {
+		if v == nil {
+			continue
+		}
+		stored := storedVisit(v)
+		stored.ID, stored.UserID = "", ""
+		s, err := toJSON(stored)
+		if err != nil {
+			return err
+		}
+		values[i] = sql.NullString{String: s, Valid: true}
+	}
+	_, err := c.exec(ctx, `INSERT INTO visit_history
+		(user_id, visit_id, id, type, actor_id, time, before_visit, after_visit)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, visitID, newID(), eventType, actorID, time.Now().UTC(), values[0], values[1])
+	if err != nil {
+		return fmt.Errorf("failed to record visit history: %w", err)
+	}
+	return nil
+}
+
+// GetCountryVisitHistory returns the change history of a visit, oldest first. History outlives
+// the visit; database.ErrVisitNotFound is returned only when there is neither history nor a
+// visit.
+func (db *DB) GetCountryVisitHistory(
+	ctx context.Context,
+	visitID, userID string,
+) ([]models.VisitHistoryEvent, error) {
+	c := db.conn()
+	rows, err := c.query(ctx, `SELECT id, type, actor_id, time, before_visit, after_visit
+		FROM visit_history WHERE user_id = ? AND visit_id = ? ORDER BY time, seq`,
+		userID, visitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visit history: %w", err)
+	}
+	events, err := scanAll(rows, func(s scanner) (models.VisitHistoryEvent, error) {
+		var e models.VisitHistoryEvent
+		var before, after sql.NullString
+		if err := s.Scan(&e.ID, &e.Type, &e.ActorID, &e.Time, &before, &after); err != nil {
+			return e, err
+		}
+		e.Time = e.Time.UTC()
+		for _, field := range []struct {
+			raw sql.NullString
+			v   **models.CountryVisit
+		}{{before, &e.Before}, {after, &e.After}} {
+			if !field.raw.Valid {
+				continue
+			}
+			visit := models.CountryVisit{}
+			if err := fromJSON(field.raw, &visit); err != nil {
+				return e, err
+			}
+			visit.ID = visitID
+			visit.UserID = userID
+			*field.v = &visit
+		}
+		return e, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list visit history: %w", err)
+	}
+	if len(events) == 0 {
+		if _, err := c.countryVisit(ctx, visitID, userID); err != nil {
+			return nil, err
+		}
+		return []models.VisitHistoryEvent{}, nil
+	}
+	return events, nil
+}
+
+// EnsureUser creates the User document of user.ID with a new ShareToken and default settings,
+// or updates ImageURL, Name (when set) and IsAnonymous of an existing one.
+func (db *DB) EnsureUser(ctx context.Context, user *models.User) error {
+	if user == nil || user.ID == "" {
+		return fmt.Errorf("user ID is required")
+	}
+	settings, err := toJSON(models.DefaultUserSettings())
+	if err != nil {
+		return err
+	}
+	_, err = db.conn().exec(ctx, `INSERT INTO users
+		(id, share_token, name, email, image_url, settings, is_anonymous)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET image_url = excluded.image_url,
+			name = CASE WHEN excluded.name <> '' THEN excluded.name ELSE users.name END,
+			is_anonymous = users.is_anonymous AND excluded.is_anonymous`,
+		user.ID, newID(), user.Name, user.Email, user.ImageURL, settings, user.IsAnonymous)
+	if err != nil {
+		return fmt.Errorf("failed to ensure user: %w", err)
+	}
+	return nil
+}
+
+// GetUserByShareToken returns the user with shareToken, or (nil, nil) when there is none.
+func (db *DB) GetUserByShareToken(ctx context.Context, shareToken string) (*models.User, error) {
+	if shareToken == "" {
+		return nil, fmt.Errorf("shareToken is required")
+	}
+	return db.conn().userByShareToken(ctx, shareToken)
+}
+
+// GetUsersByShareTokens returns the users with the given ShareTokens by ShareToken. Tokens
+// without a user are absent.
+func (db *DB) GetUsersByShareTokens(
+	ctx context.Context,
+	shareTokens []string,
+) (map[string]*models.User, error) {
+	users := make(map[string]*models.User, len(shareTokens))
+	if len(shareTokens) == 0 {
+		return users, nil
+	}
+	rows, err := db.conn().query(ctx, `SELECT `+userColumns+` FROM users
+		WHERE share_token IN (`+placeholders(len(shareTokens))+`)`, anys(shareTokens)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by share tokens: %w", err)
+	}
+	list, err := scanAll(rows, scanUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by share tokens: %w", err)
+	}
+	for _, u := range list {
+		users[u.ShareToken] = u
+	}
+	return users, nil
+}
+
+// GetUserByID returns the user with userID, or (nil, nil) when there is none.
+func (db *DB) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	return db.conn().user(ctx, userID)
+}
+
+const friendColumns = `id, share_token, name, image_url, synced_at, nickname`
+
+func scanFriend(s scanner) (models.Friend, error) {
+	var f models.Friend
+	var syncedAt sql.NullTime
+	err := s.Scan(&f.ID, &f.ShareToken, &f.Name, &f.ImageURL, &syncedAt, &f.Nickname)
+	if err != nil {
+		return f, err
+	}
+	if syncedAt.Valid {
+		f.SyncedAt = syncedAt.Time.UTC()
+	}
+	return f, nil
+}
+
+// GetFriendsByUser returns all friends of userID in ID order, or nil when there are none.
+func (db *DB) GetFriendsByUser(ctx context.Context, userID string) ([]models.Friend, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	return db.conn().friends(ctx, userID)
+}
+
+func (c conn) friends(ctx context.Context, userID string) ([]models.Friend, error) {
+	rows, err := c.query(ctx, `SELECT `+friendColumns+` FROM friends WHERE user_id = ?
+		ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friends: %w", err)
+	}
+	friends, err := scanAll(rows, scanFriend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friends: %w", err)
+	}
+	return friends, nil
+}
+
+// GetFriendsPage returns up to limit friends of userID in ID order after the friend with ID
+// cursor; nextCursor is set when the page is full.
+func (db *DB) GetFriendsPage(
+	ctx context.Context,
+	userID, cursor string,
+	limit int,
+) ([]models.Friend, string, error) {
+	if userID == "" || limit <= 0 {
+		return nil, "", fmt.Errorf("userID and a positive limit are required")
+	}
+	rows, err := db.conn().query(ctx, `SELECT `+friendColumns+` FROM friends
+		WHERE user_id = ? AND id > ? ORDER BY id LIMIT ?`, userID, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list friends: %w", err)
+	}
+	friends, err := scanAll(rows, scanFriend)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list friends: %w", err)
+	}
+	if friends == nil {
+		friends = []models.Friend{}
+	}
+	nextCursor := ""
+	if len(friends) == limit {
+		nextCursor = friends[len(friends)-1].ID
+	}
+	return friends, nextCursor, nil
+}
+
+// GetFriendByShareToken returns the friend of userID with shareToken, or (nil, nil).
+func (db *DB) GetFriendByShareToken(
+	ctx context.Context,
+	userID, shareToken string,
+) (*models.Friend, error) {
+	if userID == "" || shareToken == "" {
+		return nil, fmt.Errorf("userID and shareToken are required")
+	}
+	return db.conn().friendByShareToken(ctx, userID, shareToken)
+}
+
+func (c conn) friendByShareToken(
+	ctx context.Context,
+	userID, shareToken string,
+) (*models.Friend, error) {
+	f, err := scanFriend(c.queryRow(ctx, `SELECT `+friendColumns+` FROM friends
+		WHERE user_id = ? AND share_token = ? ORDER BY id LIMIT 1`, userID, shareToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get friend: %w", err)
+	}
+	return &f, nil
+}
+
+// DeleteFriendByShareToken deletes the friend of userID with shareToken and userID from the
+// friend's followers. Returns database.ErrFriendNotFound when there is no such friend.
+func (db *DB) DeleteFriendByShareToken(ctx context.Context, userID, shareToken string) error {
+	if userID == "" || shareToken == "" {
+		return fmt.Errorf("userID and shareToken are required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		f, err := c.friendByShareToken(ctx, userID, shareToken)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return database.ErrFriendNotFound
+		}
+		if _, err := c.exec(ctx, `DELETE FROM friends WHERE user_id = ? AND id = ?`,
+			userID, f.ID); err != nil {
+			return fmt.Errorf("failed to delete friend: %w", err)
+		}
+		_, err = c.exec(ctx, `DELETE FROM followers WHERE follower_id = ?
+			AND user_id IN (SELECT id FROM users WHERE share_token = ?)`, userID, shareToken)
+		if err != nil {
+			return fmt.Errorf("failed to delete follower: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateFriendProfiles writes Name, ImageURL and SyncedAt of the given friends (by ID) of
+// userID. Friends deleted meanwhile are skipped.
+func (db *DB) UpdateFriendProfiles(
+	ctx context.Context,
+	userID string,
+	friends []models.Friend,
+) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		for _, f := range friends {
+			_, err := c.exec(ctx, `UPDATE friends SET name = ?, image_url = ?, synced_at = ?
+				WHERE user_id = ? AND id = ?`,
+				f.Name, f.ImageURL, nullTime(f.SyncedAt), userID, f.ID)
+			if err != nil {
+				return fmt.Errorf("failed to update friend profile: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateFriendNickname sets Nickname of the friend of userID with shareToken (empty clears it)
+// and returns the friend. Returns database.ErrFriendNotFound when there is no such friend.
+func (db *DB) UpdateFriendNickname(
+	ctx context.Context,
+	userID, shareToken, nickname string,
+) (*models.Friend, error) {
+	if userID == "" || shareToken == "" {
+		return nil, fmt.Errorf("userID and shareToken are required")
+	}
+	var out *models.Friend
+	err := db.tx(ctx, func(c conn) error {
+		f, err := c.friendByShareToken(ctx, userID, shareToken)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return database.ErrFriendNotFound
+		}
+		if _, err := c.exec(ctx, `UPDATE friends SET nickname = ? WHERE user_id = ? AND id = ?`,
+			nickname, userID, f.ID); err != nil {
+			return fmt.Errorf("failed to update friend nickname: %w", err)
+		}
+		f.Nickname = nickname
+		out = f
+		return nil
+	})
+	return out, err
+}
+
+// updateUser runs an UPDATE of the users row userID. Returns database.ErrUserNotFound if the
+// user does not exist.
+func (db *DB) updateUser(ctx context.Context, userID, set string, args ...any) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	n, err := db.conn().exec(ctx, `UPDATE users SET `+set+` WHERE id = ?`,
+		append(args, userID)...)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	if n == 0 {
+		return database.ErrUserNotFound
+	}
+	return nil
+}
+
+// SetSharingDisabled sets SharingDisabled of userID. Returns database.ErrUserNotFound if the
+// user does not exist.
+func (db *DB) SetSharingDisabled(ctx context.Context, userID string, disabled bool) error {
+	return db.updateUser(ctx, userID, `sharing_disabled = ?`, disabled)
+}
+
+// SetTokensRevokedAt sets TokensRevokedAt of userID. Returns database.ErrUserNotFound if the
+// user does not exist.
+func (db *DB) SetTokensRevokedAt(ctx context.Context, userID string, at time.Time) error {
+	return db.updateUser(ctx, userID, `tokens_revoked_at = ?`, at.UTC())
+}
+
+// SetUserLimits stores limits as the admin override of the limits of userID; nil removes it.
+// Returns database.ErrUserNotFound if the user does not exist.
+func (db *DB) SetUserLimits(ctx context.Context, userID string, limits *models.UserLimits) error {
+	var value sql.NullString
+	if limits != nil {
+		s, err := toJSON(limits)
+		if err != nil {
+			return err
+		}
+		value = sql.NullString{String: s, Valid: true}
+	}
+	return db.updateUser(ctx, userID, `limits = ?`, value)
+}
+
+// UpdateUserSettings replaces Settings of userID. Returns database.ErrUserNotFound if the user
+// does not exist.
+func (db *DB) UpdateUserSettings(
+	ctx context.Context,
+	userID string,
+	settings models.UserSettings,
+) error {
+	value, err := toJSON(settings)
+	if err != nil {
+		return err
+	}
+	return db.updateUser(ctx, userID, `settings = ?`, value)
+}
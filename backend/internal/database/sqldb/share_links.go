@@ -0,0 +1,152 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const shareLinkColumns = `token, user_id, created_at, expires_at, scope, max_views, views`
+
+func scanShareLink(s scanner) (models.ShareLink, error) {
+	var l models.ShareLink
+	err := s.Scan(&l.Token, &l.UserID, &l.CreatedAt, &l.ExpiresAt, &l.Scope, &l.MaxViews,
+		&l.Views)
+	l.CreatedAt = l.CreatedAt.UTC()
+	l.ExpiresAt = l.ExpiresAt.UTC()
+	return l, err
+}
+
+// CreateShareLink stores a share link of link.UserID with a fresh token. Returns
+// database.ErrTooManyShareLinks when the user has models.MaxShareLinks unexpired links.
+func (db *DB) CreateShareLink(
+	ctx context.Context,
+	link *models.ShareLink,
+) (*models.ShareLink, error) {
+	if link == nil || link.UserID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	out := *link
+	out.Token = newID()
+	out.CreatedAt = time.Now().UTC()
+	out.Views = 0
+	err := db.tx(ctx, func(c conn) error {
+		links, err := c.shareLinks(ctx, out.UserID, out.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if len(links) >= models.MaxShareLinks {
+			return database.ErrTooManyShareLinks
+		}
+		_, err = c.exec(ctx, `INSERT INTO share_links (`+shareLinkColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			out.Token, out.UserID, out.CreatedAt, out.ExpiresAt.UTC(), out.Scope, out.MaxViews,
+			out.Views)
+		if err != nil {
+			return fmt.Errorf("failed to create share link: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetShareLinks returns the unexpired share links of userID, newest first. Links whose views
+// are used up are included.
+func (db *DB) GetShareLinks(ctx context.Context, userID string) ([]models.ShareLink, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	return db.conn().shareLinks(ctx, userID, time.Now())
+}
+
+func (c conn) shareLinks(
+	ctx context.Context,
+	userID string,
+	now time.Time,
+) ([]models.ShareLink, error) {
+	rows, err := c.query(ctx, `SELECT `+shareLinkColumns+` FROM share_links
+		WHERE user_id = ? AND expires_at > ? ORDER BY created_at DESC, token`, userID, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	links, err := scanAll(rows, scanShareLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	if links == nil {
+		links = []models.ShareLink{}
+	}
+	return links, nil
+}
+
+// GetShareLinkByToken returns the share link with token of any user, or nil (not error) when
+// there is none. Expired links are returned too.
+func (db *DB) GetShareLinkByToken(ctx context.Context, token string) (*models.ShareLink, error) {
+	if token == "" {
+		return nil, nil
+	}
+	l, err := scanShareLink(db.conn().queryRow(ctx,
+		`SELECT `+shareLinkColumns+` FROM share_links WHERE token = ?`, token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	return &l, nil
+}
+
+// CountShareLinkView counts a view of the share link token of userID. Returns
+// database.ErrShareLinkNotFound when the link is gone or no longer active at now.
+func (db *DB) CountShareLinkView(
+	ctx context.Context,
+	userID, token string,
+	now time.Time,
+) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		l, err := scanShareLink(c.queryRow(ctx, `SELECT `+shareLinkColumns+` FROM share_links
+			WHERE user_id = ? AND token = ?`, userID, token))
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.ErrShareLinkNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get share link: %w", err)
+		}
+		if !l.Active(now) {
+			return database.ErrShareLinkNotFound
+		}
+		if _, err := c.exec(ctx, `UPDATE share_links SET views = views + 1 WHERE token = ?`,
+			token); err != nil {
+			return fmt.Errorf("failed to count share link view: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteShareLink revokes the share link token of userID. Returns
+// database.ErrShareLinkNotFound if it does not exist.
+func (db *DB) DeleteShareLink(ctx context.Context, userID, token string) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM share_links WHERE user_id = ? AND token = ?`,
+		userID, token)
+	if err != nil {
+		return fmt.Errorf("failed to delete share link: %w", err)
+	}
+	if n == 0 {
+		return database.ErrShareLinkNotFound
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// RecordShareView counts a view of token, a share token of userID, at now: the total, the
+// day's bucket and the last view time.
+func (db *DB) RecordShareView(ctx context.Context, userID, token string, now time.Time) error {
+	if userID == "" || token == "" {
+		return fmt.Errorf("userID and token are required")
+	}
+	return db.tx(ctx, func(c conn) error {
+		stats, err := c.shareStats(ctx, userID, token)
+		if err != nil {
+			return err
+		}
+		if stats.Days == nil {
+			stats.Days = make(map[string]int64)
+		}
+		stats.Days[models.ShareStatsDate(now)]++
+		days, err := toJSON(stats.Days)
+		if err != nil {
+			return err
+		}
+		_, err = c.exec(ctx, `INSERT INTO share_stats (user_id, token, views, last_viewed_at, days)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, token) DO UPDATE SET views = excluded.views,
+				last_viewed_at = excluded.last_viewed_at, days = excluded.days`,
+			userID, token, stats.Views+1, now.UTC(), days)
+		if err != nil {
+			return fmt.Errorf("failed to record share view: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetShareStats returns the view statistics of tokens, share tokens of userID, in the order of
+// tokens. Tokens never viewed have zero Views. Daily is not set.
+func (db *DB) GetShareStats(
+	ctx context.Context,
+	userID string,
+	tokens []string,
+) ([]models.ShareStats, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	c := db.conn()
+	out := make([]models.ShareStats, len(tokens))
+	for i, token := range tokens {
+		stats, err := c.shareStats(ctx, userID, token)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = stats
+	}
+	return out, nil
+}
+
+// shareStats returns the statistics of token of userID; zero ones when it was never viewed.
+func (c conn) shareStats(ctx context.Context, userID, token string) (models.ShareStats, error) {
+	stats := models.ShareStats{Token: token}
+	var lastViewedAt sql.NullTime
+	var days sql.NullString
+	err := c.queryRow(ctx, `SELECT views, last_viewed_at, days FROM share_stats
+		WHERE user_id = ? AND token = ?`, userID, token).Scan(&stats.Views, &lastViewedAt, &days)
+	if errors.Is(err, sql.ErrNoRows) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to get share stats: %w", err)
+	}
+	stats.LastViewedAt = timePtr(lastViewedAt)
+	if err := fromJSON(days, &stats.Days); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
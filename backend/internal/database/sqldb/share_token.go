@@ -0,0 +1,128 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+)
+
+// RotateShareToken gives userID a new random ShareToken. The users who have userID as a friend
+// either get their Friend entry and visit companions moved to the new token, or, with
+// removeFromFriends, lose the Friend entry, the companion and the follower entry. Pending friend
+// requests from and to the user carry the new token either way. Returns the new token and the
+// number of friends lists the user was removed from.
+func (db *DB) RotateShareToken(
+	ctx context.Context,
+	userID string,
+	removeFromFriends bool,
+) (string, int, error) {
+	if userID == "" {
+		return "", 0, fmt.Errorf("userID is required")
+	}
+	shareToken, removed := newID(), 0
+	err := db.tx(ctx, func(c conn) error {
+		removed = 0
+		u, err := c.user(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return database.ErrUserNotFound
+		}
+		old := u.ShareToken
+		if _, err := c.exec(ctx, `UPDATE users SET share_token = ? WHERE id = ?`,
+			shareToken, userID); err != nil {
+			return fmt.Errorf("failed to rotate share token: %w", err)
+		}
+		rows, err := c.query(ctx, `SELECT f.follower_id FROM followers f
+			JOIN users u ON u.id = f.follower_id WHERE f.user_id = ? ORDER BY f.follower_id`,
+			userID)
+		if err != nil {
+			return fmt.Errorf("failed to list followers: %w", err)
+		}
+		followerIDs, err := scanStrings(rows)
+		if err != nil {
+			return fmt.Errorf("failed to list followers: %w", err)
+		}
+		for _, followerID := range followerIDs {
+			var n int64
+			if removeFromFriends {
+				n, err = c.exec(ctx, `DELETE FROM friends WHERE user_id = ? AND share_token = ?`,
+					followerID, old)
+			} else {
+				n, err = c.exec(ctx, `UPDATE friends SET share_token = ?
+					WHERE user_id = ? AND share_token = ?`, shareToken, followerID, old)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update friend entries: %w", err)
+			}
+			if err := c.replaceCompanion(ctx, followerID, old, shareToken,
+				removeFromFriends); err != nil {
+				return err
+			}
+			if removeFromFriends {
+				if _, err := c.exec(ctx, `DELETE FROM followers
+					WHERE user_id = ? AND follower_id = ?`, userID, followerID); err != nil {
+					return fmt.Errorf("failed to delete follower: %w", err)
+				}
+				if n > 0 {
+					removed++
+				}
+			}
+		}
+		if old == "" {
+			return nil
+		}
+		for _, side := range []string{"from", "to"} {
+			_, err := c.exec(ctx, `UPDATE friend_requests SET `+side+`_share_token = ?
+				WHERE `+side+`_user_id = ?`, shareToken, userID)
+			if err != nil {
+				return fmt.Errorf("failed to update friend requests: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return shareToken, removed, nil
+}
+
+// replaceCompanion replaces the companion old in the visits of userID with shareToken, or
+// removes it with remove, and bumps VisitsRevision when a visit changed.
+func (c conn) replaceCompanion(
+	ctx context.Context,
+	userID, old, shareToken string,
+	remove bool,
+) error {
+	visits, err := c.countryVisits(ctx, userID, true)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, v := range visits {
+		if !slices.Contains(v.Companions, old) {
+			continue
+		}
+		companions := make([]string, 0, len(v.Companions))
+		for _, token := range v.Companions {
+			switch {
+			case token != old:
+				companions = append(companions, token)
+			case !remove:
+				companions = append(companions, shareToken)
+			}
+		}
+		v.Companions = companions
+		if err := c.putVisit(ctx, v); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return c.bumpVisitsRevision(ctx, userID)
+}
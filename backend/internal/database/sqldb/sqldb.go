@@ -0,0 +1,229 @@
+// Package sqldb implements the database used by the server and the background workers on a SQL
+// database, for self-hosting without GCP. It keeps the semantics of the Firestore client in
+// internal/database, including its sentinel errors: tables mirror the Firestore collections,
+// nested values (tags, locations, settings, ...) are JSON text, and there are no foreign keys,
+// since subcollections may outlive their User document there too.
+//
+// Queries are written with ? placeholders and rebound for the dialect. Multi-statement writes
+// run in serializable transactions that are retried on serialization failures (on SQLite, when
+// the database stays locked by another writer).
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+)
+
+// maxTxAttempts bounds how often a transaction is retried after a serialization failure.
+const maxTxAttempts = 5
+
+// dialect holds what differs between the supported SQL databases.
+type dialect struct {
+	name string
+	// numberedParams rewrites ? placeholders to $1, $2, ...
+	numberedParams bool
+	txOptions      *sql.TxOptions
+	// retryable reports whether a failed transaction may succeed when run again.
+	retryable func(err error) bool
+}
+
+// DB is a SQL database. Use OpenPostgres or OpenSQLite.
+type DB struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// Close closes the database.
+func (db *DB) Close() error {
+	return db.db.Close()
+}
+
+// Ping checks that the database answers.
+func (db *DB) Ping(ctx context.Context) error {
+	if err := db.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping %s: %w", db.dialect.name, err)
+	}
+	return nil
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// conn runs the queries of one method outside or inside a transaction.
+type conn struct {
+	q       queryer
+	dialect dialect
+}
+
+func (db *DB) conn() conn {
+	return conn{q: db.db, dialect: db.dialect}
+}
+
+func (c conn) rebind(query string) string {
+	if !c.dialect.numberedParams {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+func (c conn) exec(ctx context.Context, query string, args ...any) (int64, error) {
+	res, err := c.q.ExecContext(ctx, c.rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (c conn) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.q.QueryContext(ctx, c.rebind(query), args...)
+}
+
+func (c conn) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.q.QueryRowContext(ctx, c.rebind(query), args...)
+}
+
+// tx runs fn in a transaction, retrying it on serialization failures. fn must not leave state
+// behind when it fails.
+func (db *DB) tx(ctx context.Context, fn func(c conn) error) error {
+	for attempt := 1; ; attempt++ {
+		err := db.runTx(ctx, fn)
+		if err == nil || attempt == maxTxAttempts || !db.dialect.retryable(err) {
+			return err
+		}
+	}
+}
+
+func (db *DB) runTx(ctx context.Context, fn func(c conn) error) error {
+	tx, err := db.db.BeginTx(ctx, db.dialect.txOptions)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := fn(conn{q: tx, dialect: db.dialect}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// scanAll scans every row of rows with scan.
+func scanAll[T any](rows *sql.Rows, scan func(s scanner) (T, error)) ([]T, error) {
+	defer rows.Close()
+	var out []T
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// scanStrings returns the single string column of rows.
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	return scanAll(rows, func(s scanner) (string, error) {
+		var v string
+		err := s.Scan(&v)
+		return v, err
+	})
+}
+
+// placeholders returns n comma-separated ? placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// anys returns values as arguments for an IN list.
+func anys[T any](values []T) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// newID returns a new random row ID.
+func newID() string {
+	return uuid.New().String()
+}
+
+// toJSON encodes v for a JSON text column.
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode json: %w", err)
+	}
+	return string(b), nil
+}
+
+// fromJSON decodes a JSON text column into v; an empty or null value leaves v unchanged.
+func fromJSON(s sql.NullString, v any) error {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(s.String), v); err != nil {
+		return fmt.Errorf("failed to decode json: %w", err)
+	}
+	return nil
+}
+
+// nullTime stores the zero time as NULL, like an omitted Firestore field.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
+
+// timePtr returns the time of a nullable column, or nil.
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time.UTC()
+	return &v
+}
+
+// utcPtr returns t in UTC for a nullable column.
+func utcPtr(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// actorIDFromContext returns the ID of the authenticated user making the change, or fallback.
+func actorIDFromContext(ctx context.Context, fallback string) string {
+	if user, ok := ctxkeys.CurrentUser(ctx); ok && user.ID != "" {
+		return user.ID
+	}
+	return fallback
+}
@@ -0,0 +1,47 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var sqliteDialect = dialect{
+	name: "sqlite",
+	// Transactions take the write lock when they begin (_txlock=immediate), which makes them
+	// serializable without an isolation level.
+	retryable: func(err error) bool {
+		var sqliteErr *sqlite.Error
+		return errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY
+	},
+}
+
+// OpenSQLite opens (creating if needed) the SQLite database file at path and brings its schema
+// up to date. The file is used in WAL mode, so its -wal and -shm files belong with it.
+func OpenSQLite(ctx context.Context, path string) (*DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+	params.Set("_txlock", "immediate")
+	params.Set("_time_format", "sqlite")
+	sqlDB, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+	db := &DB{db: sqlDB, dialect: sqliteDialect}
+	if err := db.migrate(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,165 @@
+package sqldb_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/database/sqldb"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// openSQLite opens the SQLite database file in dir, closing it when the test ends.
+func openSQLite(t *testing.T, dir string) *sqldb.DB {
+	t.Helper()
+	db, err := sqldb.OpenSQLite(context.Background(), filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// TestSQLiteTimestamps stores times in other zones than UTC: SQLite keeps them as text, so
+// reads and comparisons must not depend on the zone they were written in.
+func TestSQLiteTimestamps(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t, t.TempDir())
+	helsinki := time.FixedZone("EEST", 3*60*60)
+	now := time.Now().Truncate(time.Second)
+
+	if err := db.EnsureUser(ctx, &models.User{ID: "u1", Name: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	visitedTime := time.Date(2020, 1, 2, 1, 0, 0, 0, helsinki)
+	v, err := db.CreateCountryVisit(ctx, &models.CountryVisit{
+		UserID: "u1", CountryCode: "FI", VisitedTime: visitedTime,
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.GetCountryVisit(ctx, v.ID, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.VisitedTime.Equal(visitedTime) {
+		t.Errorf("VisitedTime = %v, want %v", got.VisitedTime, visitedTime)
+	}
+
+	// Deletion is due by instant, whatever the zones of the stored time and of now
+	for _, tt := range []struct {
+		userID string
+		at     time.Time
+	}{
+		{"due", now.Add(-time.Hour).In(helsinki)},
+		{"due-utc", now.Add(-time.Minute).UTC()},
+		{"later", now.Add(time.Hour).In(helsinki)},
+		{"later-utc", now.Add(time.Minute).UTC()},
+	} {
+		if err := db.EnsureUser(ctx, &models.User{ID: tt.userID, Name: tt.userID}); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.ScheduleAccountDeletion(ctx, tt.userID, tt.at); err != nil {
+			t.Fatal(err)
+		}
+		u, err := db.GetUserByID(ctx, tt.userID)
+		if err != nil || u.DeletionScheduledAt == nil || !u.DeletionScheduledAt.Equal(tt.at) {
+			t.Errorf("DeletionScheduledAt of %s = %v, %v; want %v", tt.userID, u, err, tt.at)
+		}
+	}
+	due, err := db.GetUsersDueForDeletion(ctx, now.In(helsinki), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 2 || !slices.Contains(due, "due") || !slices.Contains(due, "due-utc") {
+		t.Errorf("GetUsersDueForDeletion = %v, want due and due-utc", due)
+	}
+}
+
+// TestSQLiteUpserts writes rows that SQLite inserts or updates with ON CONFLICT.
+func TestSQLiteUpserts(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t, t.TempDir())
+	if err := db.EnsureUser(ctx, &models.User{ID: "u1", Name: "A"}); err != nil {
+		t.Fatal(err)
+	}
+
+	visit := &models.CountryVisit{
+		ID: "v1", UserID: "u1", CountryCode: "FI", VisitedTime: time.Unix(1577836800, 0),
+	}
+	if err := db.ReplaceCountryVisit(ctx, visit, time.Time{}); err != nil {
+		t.Fatalf("ReplaceCountryVisit of a new visit: %v", err)
+	}
+	visit.CountryCode = "SE"
+	if err := db.ReplaceCountryVisit(ctx, visit, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	visits, err := db.GetCountryVisitsByUser(ctx, "u1")
+	if err != nil || len(visits) != 1 || visits[0].CountryCode != "SE" {
+		t.Errorf("visits = %+v, %v; want v1 in SE only", visits, err)
+	}
+
+	for _, count := range []int{1, 2} {
+		err := db.SaveInsights(ctx, "u1", &models.Insights{
+			GeneratedAt: time.Now().UTC(), RecentVisitCount: count,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	ins, err := db.GetInsights(ctx, "u1")
+	if err != nil || ins == nil || ins.RecentVisitCount != 2 {
+		t.Errorf("GetInsights = %+v, %v; want the second save", ins, err)
+	}
+}
+
+// TestSQLiteLocking writes to one file from two connection pools at once, as two processes
+// would: transactions wait for the write lock, so the visit limit holds across both.
+func TestSQLiteLocking(t *testing.T) {
+	const n, limit = 30, 20
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbs := []*sqldb.DB{openSQLite(t, dir), openSQLite(t, dir)}
+	if err := dbs[0].EnsureUser(ctx, &models.User{ID: "u1", Name: "A"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	created, limited := 0, 0
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := dbs[i%2].CreateCountryVisit(ctx, &models.CountryVisit{
+				UserID: "u1", CountryCode: "FI", VisitedTime: time.Unix(1577836800, 0),
+			}, limit)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, database.ErrVisitLimitReached):
+				limited++
+			case err != nil:
+				t.Errorf("CreateCountryVisit: %v", err)
+			default:
+				created++
+			}
+		}()
+	}
+	wg.Wait()
+	if created != limit || limited != n-limit {
+		t.Errorf("%d created and %d over the limit, want %d and %d", created, limited, limit,
+			n-limit)
+	}
+	for i, db := range dbs {
+		visits, err := db.GetCountryVisitsByUser(ctx, "u1")
+		if err != nil || len(visits) != limit {
+			t.Errorf("connection %d reads %d visits, %v; want %d", i, len(visits), err, limit)
+		}
+	}
+}
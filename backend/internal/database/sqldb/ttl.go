@@ -0,0 +1,39 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// expiringTables maps the collection groups of ttl.Policies to their tables and primary keys.
+var expiringTables = map[string]struct{ table, key string }{
+	"organization_invitations": {"organization_invitations", "code"},
+	"friend_invites":           {"friend_invites", "user_id, id"},
+	"share_links":              {"share_links", "token"},
+	"audit":                    {"audit_events", "user_id, id"},
+}
+
+// DeleteExpiredDocuments deletes up to limit rows of collectionGroup whose field is at or
+// before now and returns how many it deleted. Only the ExpiresAt fields of the collections in
+// ttl.Policies are supported.
+func (db *DB) DeleteExpiredDocuments(
+	ctx context.Context,
+	collectionGroup, field string,
+	now time.Time,
+	limit int,
+) (int, error) {
+	t, ok := expiringTables[collectionGroup]
+	if !ok {
+		return 0, fmt.Errorf("unsupported collection group %s", collectionGroup)
+	}
+	if field != "ExpiresAt" {
+		return 0, fmt.Errorf("unsupported expiry field %s.%s", collectionGroup, field)
+	}
+	n, err := db.conn().exec(ctx, `DELETE FROM `+t.table+` WHERE (`+t.key+`) IN
+		(SELECT `+t.key+` FROM `+t.table+` WHERE expires_at <= ? LIMIT ?)`, now.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired %s: %w", collectionGroup, err)
+	}
+	return int(n), nil
+}
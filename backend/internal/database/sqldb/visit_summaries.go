@@ -0,0 +1,51 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// GetVisitSummariesForUsers returns the counts over the non-private visits of each of userIDs,
+// by user ID, in one query; users without visits get a zero summary.
+func (db *DB) GetVisitSummariesForUsers(
+	ctx context.Context,
+	userIDs []string,
+) (map[string]models.FriendVisitSummary, error) {
+	summaries := make(map[string]models.FriendVisitSummary, len(userIDs))
+	if len(userIDs) == 0 {
+		return summaries, nil
+	}
+	for _, userID := range userIDs {
+		if userID == "" {
+			return nil, fmt.Errorf("userIDs must not be empty")
+		}
+		summaries[userID] = models.FriendVisitSummary{}
+	}
+	// proofs is NULL for visits without proofs
+	rows, err := db.conn().query(ctx, `SELECT user_id, COUNT(*), COUNT(DISTINCT country_code),
+		COUNT(proofs) FROM country_visits
+		WHERE user_id IN (`+placeholders(len(userIDs))+`) AND NOT is_private
+		GROUP BY user_id`, anys(userIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visit summaries: %w", err)
+	}
+	type row struct {
+		userID  string
+		summary models.FriendVisitSummary
+	}
+	list, err := scanAll(rows, func(s scanner) (row, error) {
+		var r row
+		err := s.Scan(&r.userID, &r.summary.VisitCount, &r.summary.CountryCount,
+			&r.summary.VerifiedVisitCount)
+		return r, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visit summaries: %w", err)
+	}
+	for _, r := range list {
+		summaries[r.userID] = r.summary
+	}
+	return summaries, nil
+}
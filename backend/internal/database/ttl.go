@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// DeleteExpiredDocuments deletes up to limit documents of collectionGroup whose timestamp field
+// is at or before now and returns how many it deleted. The query needs a collection group
+// scoped single-field index on field.
+func (c *Client) DeleteExpiredDocuments(
+	ctx context.Context,
+	collectionGroup, field string,
+	now time.Time,
+	limit int,
+) (int, error) {
+	docs, err := c.CollectionGroup(collectionGroup).Where(field, "<=", now).Select().Limit(limit).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired documents: %w", err)
+	}
+	refs := make([]*firestore.DocumentRef, len(docs))
+	for i, doc := range docs {
+		refs[i] = doc.Ref
+	}
+	if err := c.deleteRefs(ctx, refs); err != nil {
+		return 0, err
+	}
+	return len(refs), nil
+}
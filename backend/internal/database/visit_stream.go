@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// WatchCountryVisits listens to the country visits of userID until ctx is done or fn returns an
+// error, calling fn with the changes of every snapshot after the first. ready is called once
+// the listener has the current visits, so callers can read them knowing no later change is
+// missed. Returns ctx.Err() when ctx is done.
+func (c *Client) WatchCountryVisits(
+	ctx context.Context,
+	userID string,
+	ready func() error,
+	fn func(changes []models.VisitChange) error,
+) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+	iter := c.Collection("users").Doc(userID).Collection("country_visits").Snapshots(ctx)
+	defer iter.Stop()
+
+	for first := true; ; first = false {
+		snap, err := iter.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to listen to country visits: %w", err)
+		}
+		if first {
+			if err := ready(); err != nil {
+				return err
+			}
+			continue
+		}
+		changes := make([]models.VisitChange, 0, len(snap.Changes))
+		for _, ch := range snap.Changes {
+			change, err := c.visitChange(ctx, userID, ch)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, change)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if err := fn(changes); err != nil {
+			return err
+		}
+	}
+}
+
+// visitChange converts a snapshot listener change of a country visit of userID.
+func (c *Client) visitChange(
+	ctx context.Context,
+	userID string,
+	ch firestore.DocumentChange,
+) (models.VisitChange, error) {
+	change := models.VisitChange{ID: ch.Doc.Ref.ID}
+	switch ch.Kind {
+	case firestore.DocumentRemoved:
+		change.Type = models.VisitChangeRemoved
+		return change, nil
+	case firestore.DocumentAdded:
+		change.Type = models.VisitChangeAdded
+	default:
+		change.Type = models.VisitChangeModified
+	}
+	visit, err := c.countryVisitFromDoc(ctx, userID, ch.Doc)
+	if err != nil {
+		return change, err
+	}
+	change.Visit = &visit
+	return change, nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// visitSummaryReads is how many users' visits GetVisitSummariesForUsers reads at a time.
+const visitSummaryReads = 10
+
+// GetVisitSummariesForUsers returns the counts over the non-private visits of each of userIDs,
+// by user ID. Users without visits (or without a document) get a zero summary. Firestore has
+// no query across the visits of several users, so they are read concurrently, at most
+// visitSummaryReads users at a time and only the fields the counts need.
+func (c *Client) GetVisitSummariesForUsers(
+	ctx context.Context,
+	userIDs []string,
+) (map[string]models.FriendVisitSummary, error) {
+	for _, userID := range userIDs {
+		if userID == "" {
+			return nil, fmt.Errorf("userIDs must not be empty")
+		}
+	}
+	var mu sync.Mutex
+	summaries := make(map[string]models.FriendVisitSummary, len(userIDs))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(visitSummaryReads)
+	for _, userID := range userIDs {
+		g.Go(func() error {
+			docs, err := c.Collection("users").Doc(userID).Collection("country_visits").
+				Select("CountryCode", "IsPrivate", "Proofs").Documents(ctx).GetAll()
+			if err != nil {
+				return fmt.Errorf("failed to get country visits of %s: %w", userID, err)
+			}
+			var summary models.FriendVisitSummary
+			countries := make(map[string]struct{}, len(docs))
+			for _, doc := range docs {
+				var visit models.CountryVisit
+				if err := doc.DataTo(&visit); err != nil {
+					return fmt.Errorf("failed to unmarshal country visit: %w", err)
+				}
+				if visit.IsPrivate {
+					continue
+				}
+				summary.VisitCount++
+				countries[visit.CountryCode] = struct{}{}
+				if len(visit.Proofs) > 0 {
+					summary.VerifiedVisitCount++
+				}
+			}
+			summary.CountryCount = len(countries)
+			mu.Lock()
+			summaries[userID] = summary
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
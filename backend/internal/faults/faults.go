@@ -0,0 +1,124 @@
+// Package faults injects failures for resilience testing outside production: random latency
+// on HTTP requests and errors on a share of Firestore RPCs. config.Load refuses to enable it
+// unless APP_ENV is debug or staging.
+package faults
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config selects the faults to inject. The zero value injects nothing.
+type Config struct {
+	// Latency is the maximum delay added to a delayed request; each delay is uniformly random
+	// in [0, Latency).
+	Latency time.Duration
+
+	// LatencyRate is the fraction (0-1) of requests delayed.
+	LatencyRate float64
+
+	// FirestoreErrorRate is the fraction (0-1) of Firestore RPCs failing with Unavailable.
+	FirestoreErrorRate float64
+}
+
+// Enabled reports whether any fault is configured.
+func (c Config) Enabled() bool {
+	return (c.Latency > 0 && c.LatencyRate > 0) || c.FirestoreErrorRate > 0
+}
+
+// Injector injects the faults of a Config and counts them.
+type Injector struct {
+	cfg Config
+
+	delayed        atomic.Int64
+	firestoreFails atomic.Int64
+}
+
+// New returns an Injector for cfg.
+func New(cfg Config) *Injector {
+	return &Injector{cfg: cfg}
+}
+
+// Config returns the injected faults.
+func (i *Injector) Config() Config {
+	return i.cfg
+}
+
+// Counts returns how many requests were delayed and Firestore RPCs failed so far.
+func (i *Injector) Counts() (delayed, firestoreFails int64) {
+	return i.delayed.Load(), i.firestoreFails.Load()
+}
+
+// Delay sleeps a random time up to Config.Latency for Config.LatencyRate of the calls. It
+// returns early when ctx is done.
+func (i *Injector) Delay(ctx context.Context) {
+	if i.cfg.Latency <= 0 || !hit(i.cfg.LatencyRate) {
+		return
+	}
+	i.delayed.Add(1)
+	t := time.NewTimer(rand.N(i.cfg.Latency))
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// ClientOptions returns the options making a Firestore client fail Config.FirestoreErrorRate of
+// its RPCs, or nil when no Firestore faults are configured.
+func (i *Injector) ClientOptions() []option.ClientOption {
+	if i.cfg.FirestoreErrorRate <= 0 {
+		return nil
+	}
+	return []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(i.unaryInterceptor)),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(i.streamInterceptor)),
+	}
+}
+
+func (i *Injector) unaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if err := i.firestoreError(method); err != nil {
+		return err
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (i *Injector) streamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	if err := i.firestoreError(method); err != nil {
+		return nil, err
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+func (i *Injector) firestoreError(method string) error {
+	if !hit(i.cfg.FirestoreErrorRate) {
+		return nil
+	}
+	i.firestoreFails.Add(1)
+	return status.Errorf(codes.Unavailable, "injected fault: %s", method)
+}
+
+func hit(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
@@ -0,0 +1,87 @@
+package features
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+)
+
+// Flag names an experimental behavior that trusted testers can enable per request.
+type Flag string
+
+const (
+	// Feed enables the friend activity feed endpoint while it is in preview.
+	Feed Flag = "feed"
+	// Goals enables goal endpoints while they are in preview.
+	Goals Flag = "goals"
+)
+
+// Catalog lists the flags accepted in the X-Feature-Preview header, with a short description.
+// Remove a flag from the catalog when its feature graduates to general availability.
+var Catalog = map[Flag]string{
+	Feed:  "Friend activity feed",
+	Goals: "Travel goals",
+}
+
+// Set is the set of flags enabled for a request.
+type Set map[Flag]struct{}
+
+// Names returns the enabled flag names in sorted order.
+func (s Set) Names() []string {
+	out := make([]string, 0, len(s))
+	for f := range s {
+		out = append(out, string(f))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Parse parses a comma-separated X-Feature-Preview header value. Returns the known flags and
+// the names that are not in Catalog.
+func Parse(header string) (Set, []string) {
+	set := Set{}
+	var unknown []string
+	for _, name := range strings.Split(header, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := Catalog[Flag(name)]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		set[Flag(name)] = struct{}{}
+	}
+	return set, unknown
+}
+
+// CatalogNames returns all catalog flag names in sorted order.
+func CatalogNames() []string {
+	out := make([]string, 0, len(Catalog))
+	for f := range Catalog {
+		out = append(out, string(f))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// WithContext returns a context carrying the enabled preview flags.
+func WithContext(ctx context.Context, set Set) context.Context {
+	return context.WithValue(ctx, ctxkeys.FeaturePreviewKey, set)
+}
+
+// FromContext returns the preview flags enabled for the request (empty when none).
+func FromContext(ctx context.Context) Set {
+	if set, ok := ctx.Value(ctxkeys.FeaturePreviewKey).(Set); ok {
+		return set
+	}
+	return Set{}
+}
+
+// Enabled reports whether flag is enabled for the request in ctx.
+func Enabled(ctx context.Context, flag Flag) bool {
+	_, ok := FromContext(ctx)[flag]
+	return ok
+}
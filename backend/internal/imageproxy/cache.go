@@ -0,0 +1,146 @@
+package imageproxy
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"google.golang.org/api/googleapi"
+	storage "google.golang.org/api/storage/v1"
+)
+
+// MemoryCache is an in-process LRU cache bounded by the total size of cached images.
+type MemoryCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	order    *list.List // front = most recently used; values are *memoryEntry
+	entries  map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key string
+	img *Image
+}
+
+// NewMemoryCache returns a MemoryCache holding at most maxBytes of image data.
+func NewMemoryCache(maxBytes int) *MemoryCache {
+	return &MemoryCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(_ context.Context, key string) (*Image, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	m.order.MoveToFront(el)
+	return el.Value.(*memoryEntry).img, true, nil
+}
+
+// Put implements Cache. Images larger than the whole cache are not stored.
+func (m *MemoryCache) Put(_ context.Context, key string, img *Image) error {
+	if len(img.Data) > m.maxBytes {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		m.size -= len(el.Value.(*memoryEntry).img.Data)
+		m.order.Remove(el)
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, img: img})
+	m.size += len(img.Data)
+	for m.size > m.maxBytes {
+		oldest := m.order.Back()
+		e := oldest.Value.(*memoryEntry)
+		m.order.Remove(oldest)
+		delete(m.entries, e.key)
+		m.size -= len(e.img.Data)
+	}
+	return nil
+}
+
+// GCSCache stores resized images as objects in a Cloud Storage bucket, shared by all
+// instances. Objects are named "<prefix><key>".
+type GCSCache struct {
+	service *storage.Service
+	bucket  string
+	prefix  string
+}
+
+// NewGCSCache returns a GCSCache for bucket using application default credentials.
+func NewGCSCache(ctx context.Context, bucket, prefix string) (*GCSCache, error) {
+	service, err := storage.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage service: %w", err)
+	}
+	return &GCSCache{service: service, bucket: bucket, prefix: prefix}, nil
+}
+
+// Get implements Cache.
+func (g *GCSCache) Get(ctx context.Context, key string) (*Image, bool, error) {
+	resp, err := g.service.Objects.Get(g.bucket, g.prefix+key).Context(ctx).Download()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to read cached image: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBytes))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cached image: %w", err)
+	}
+	return &Image{Data: data, ContentType: resp.Header.Get("Content-Type")}, true, nil
+}
+
+// Put implements Cache.
+func (g *GCSCache) Put(ctx context.Context, key string, img *Image) error {
+	obj := &storage.Object{Name: g.prefix + key, ContentType: img.ContentType}
+	_, err := g.service.Objects.Insert(g.bucket, obj).
+		Media(bytes.NewReader(img.Data)).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return fmt.Errorf("failed to write cached image: %w", err)
+	}
+	return nil
+}
+
+// TieredCache checks a fast local cache before a shared one and fills the local cache on hits.
+type TieredCache struct {
+	Local  Cache
+	Shared Cache
+}
+
+// Get implements Cache.
+func (t TieredCache) Get(ctx context.Context, key string) (*Image, bool, error) {
+	if img, ok, err := t.Local.Get(ctx, key); err == nil && ok {
+		return img, true, nil
+	}
+	img, ok, err := t.Shared.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	_ = t.Local.Put(ctx, key, img)
+	return img, true, nil
+}
+
+// Put implements Cache.
+func (t TieredCache) Put(ctx context.Context, key string, img *Image) error {
+	_ = t.Local.Put(ctx, key, img)
+	return t.Shared.Put(ctx, key, img)
+}
@@ -0,0 +1,278 @@
+package imageproxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoder
+)
+
+// Widths are the output widths served; a requested width is rounded up to the next one so
+// the cache holds a bounded number of variants per source image.
+var Widths = []int{32, 64, 128, 256, 512, 1024}
+
+// DefaultAllowedHosts are the source hosts accepted when no allowlist is configured:
+// Google profile images (avatars from the auth token).
+var DefaultAllowedHosts = []string{"googleusercontent.com", "ggpht.com"}
+
+const (
+	// maxSourceBytes bounds the downloaded source image size.
+	maxSourceBytes = 8 << 20
+	// maxSourcePixels bounds decoded image dimensions (decompression bombs).
+	maxSourcePixels = 40_000_000
+	// maxRedirects is the number of redirects followed; each target is re-checked.
+	maxRedirects = 3
+	fetchTimeout = 10 * time.Second
+	jpegQuality  = 85
+)
+
+var (
+	// ErrInvalidURL is returned for URLs that are malformed, not https or not on the allowlist.
+	ErrInvalidURL = errors.New("url is not allowed")
+	// ErrUpstream is returned when the source cannot be fetched or is not a supported image.
+	ErrUpstream = errors.New("failed to fetch image")
+)
+
+// Image is a resized image ready to be served.
+type Image struct {
+	Data        []byte
+	ContentType string
+}
+
+// Cache stores resized images by key. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the cached image and true, or false when the key is not cached.
+	Get(ctx context.Context, key string) (*Image, bool, error)
+	Put(ctx context.Context, key string, img *Image) error
+}
+
+// Proxy fetches images from allowlisted hosts, resizes them and caches the result.
+type Proxy struct {
+	allowedHosts []string
+	cache        Cache
+	client       *http.Client
+
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+}
+
+// CacheStats counts cache lookups of Fetch since startup.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// New returns a Proxy accepting source URLs on allowedHosts (a host matches an entry when equal
+// to it or a subdomain of it). Outbound connections to non-public IP addresses are refused at
+// dial time, so DNS tricks cannot reach internal services.
+func New(allowedHosts []string, cache Cache) *Proxy {
+	if len(allowedHosts) == 0 {
+		allowedHosts = DefaultAllowedHosts
+	}
+	hosts := make([]string, 0, len(allowedHosts))
+	for _, h := range allowedHosts {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	p := &Proxy{allowedHosts: hosts, cache: cache}
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: publicAddressOnly}
+	p.client = &http.Client{
+		Timeout: fetchTimeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("too many redirects")
+			}
+			return p.checkURL(req.URL)
+		},
+	}
+	return p
+}
+
+// Fetch returns rawURL resized to the served width for width (never upscaled).
+func (p *Proxy) Fetch(ctx context.Context, rawURL string, width int) (*Image, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	if err := p.checkURL(u); err != nil {
+		return nil, err
+	}
+	width = SnapWidth(width)
+	key := cacheKey(u.String(), width)
+	if p.cache != nil {
+		if img, ok, err := p.cache.Get(ctx, key); err == nil && ok {
+			p.cacheHits.Add(1)
+			return img, nil
+		}
+		p.cacheMisses.Add(1)
+	}
+	src, err := p.download(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	img, err := resize(src, width)
+	if err != nil {
+		return nil, err
+	}
+	if p.cache != nil {
+		// Cache failures only cost a refetch next time
+		_ = p.cache.Put(ctx, key, img)
+	}
+	return img, nil
+}
+
+// CacheStats returns the cache hit and miss counts of this instance.
+func (p *Proxy) CacheStats() CacheStats {
+	return CacheStats{Hits: p.cacheHits.Load(), Misses: p.cacheMisses.Load()}
+}
+
+// SnapWidth rounds width up to the nearest entry in Widths (the largest when above all).
+func SnapWidth(width int) int {
+	for _, w := range Widths {
+		if width <= w {
+			return w
+		}
+	}
+	return Widths[len(Widths)-1]
+}
+
+// checkURL enforces https, the default port, no credentials and the host allowlist.
+func (p *Proxy) checkURL(u *url.URL) error {
+	if u.Scheme != "https" || u.User != nil || u.Host == "" {
+		return ErrInvalidURL
+	}
+	if port := u.Port(); port != "" && port != "443" {
+		return ErrInvalidURL
+	}
+	host := strings.ToLower(u.Hostname())
+	if net.ParseIP(host) != nil {
+		return ErrInvalidURL
+	}
+	for _, allowed := range p.allowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return ErrInvalidURL
+}
+
+func (p *Proxy) download(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("Accept", "image/*")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidURL) {
+			return nil, ErrInvalidURL
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrUpstream, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "image/") {
+		return nil, fmt.Errorf("%w: content type %s", ErrUpstream, ct)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	if len(body) > maxSourceBytes {
+		return nil, fmt.Errorf("%w: image larger than %d bytes", ErrUpstream, maxSourceBytes)
+	}
+	return body, nil
+}
+
+// resize decodes src and scales it to width, keeping the aspect ratio. PNG and GIF sources
+// are encoded as PNG to keep transparency; everything else as JPEG.
+func resize(src []byte, width int) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported image", ErrUpstream)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxSourcePixels {
+		return nil, fmt.Errorf("%w: image dimensions %dx%d", ErrUpstream, cfg.Width, cfg.Height)
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported image", ErrUpstream)
+	}
+
+	out := decoded
+	if b := decoded.Bounds(); b.Dx() > width {
+		height := b.Dy() * width / b.Dx()
+		if height < 1 {
+			height = 1
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), decoded, b, draw.Over, nil)
+		out = dst
+	}
+
+	var buf bytes.Buffer
+	if format == "png" || format == "gif" {
+		if err := png.Encode(&buf, out); err != nil {
+			return nil, fmt.Errorf("failed to encode png: %w", err)
+		}
+		return &Image{Data: buf.Bytes(), ContentType: "image/png"}, nil
+	}
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+	}
+	return &Image{Data: buf.Bytes(), ContentType: "image/jpeg"}, nil
+}
+
+func cacheKey(rawURL string, width int) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return hex.EncodeToString(sum[:]) + "-w" + strconv.Itoa(width)
+}
+
+// publicAddressOnly is a net.Dialer Control func refusing loopback, private, link-local,
+// shared (CGNAT), multicast and unspecified addresses.
+func publicAddressOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address %q", address)
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: address %s is not public", ErrInvalidURL, ip)
+	}
+	return nil
+}
+
+// sharedAddressSpace is 100.64.0.0/10 (RFC 6598), not covered by net.IP.IsPrivate.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/textnorm"
+)
+
+// beenImporter parses the been app export: one visited country per line, given either as an
+// ISO alpha-2 or alpha-3 code or a country name (any language in data.Aliases). Lines starting with #
+// are ignored.
+// The export carries no dates, so records have a zero VisitedTime, and a country listed twice
+// is one record.
+type beenImporter struct{}
+
+func (beenImporter) Parse(r io.Reader) ([]Record, error) {
+	var records []Record
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// CSV exports put the country, possibly quoted, in the first column
+		if i := strings.IndexByte(line, ','); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		line = strings.TrimSpace(strings.Trim(line, `"`))
+		if line == "" {
+			continue
+		}
+		rec := Record{CountryCode: line}
+		if !data.IsValidCode(line) {
+			rec = Record{CountryCode: resolveCountryName(line), Name: line}
+		}
+		key := textnorm.Fold(rec.Name)
+		if c, ok := data.CountryByCode(rec.CountryCode); ok {
+			key = c.CountryCode
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid been export: %w", err)
+	}
+	return records, nil
+}
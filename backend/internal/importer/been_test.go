@@ -0,0 +1,57 @@
+package importer
+
+import "testing"
+
+func TestBeenParse(t *testing.T) {
+	runParseTests(t, beenImporter{}, []parseTest{
+		{
+			name:  "codes",
+			input: "FI\nse\nDEU\n",
+			want: []Record{
+				{CountryCode: "FI"}, {CountryCode: "se"}, {CountryCode: "DEU"},
+			},
+		},
+		{
+			name:  "names and aliases",
+			input: "Finland\nUnited States of America\nCzech Republic\nTürkiye\n",
+			want: []Record{
+				{CountryCode: "FI", Name: "Finland"},
+				{CountryCode: "US", Name: "United States of America"},
+				{CountryCode: "CZ", Name: "Czech Republic"},
+				{CountryCode: "TR", Name: "Türkiye"},
+			},
+		},
+		{
+			name:  "comments, blank lines and whitespace",
+			input: "# visited countries\n\n  FI  \r\n\t\n",
+			want:  []Record{{CountryCode: "FI"}},
+		},
+		{
+			name:  "csv rows",
+			input: "\"France\",2019\nES,2020\n,2021\n\"\",x\n",
+			want: []Record{
+				{CountryCode: "FR", Name: "France"}, {CountryCode: "ES"},
+			},
+		},
+		{
+			name:  "unknown names",
+			input: "Atlantis\nFI\nNarnia\n",
+			want: []Record{
+				{Name: "Atlantis"}, {CountryCode: "FI"}, {Name: "Narnia"},
+			},
+		},
+		{
+			name:  "duplicates",
+			input: "FI\nfi\nFIN\nFinland\nSuomi\nAtlantis\natlantis\nAtlantis\n",
+			want: []Record{
+				{CountryCode: "FI"}, {Name: "Atlantis"},
+			},
+		},
+		{name: "empty", input: ""},
+		{
+			name:    "line too long",
+			input:   string(make([]byte, 70000)),
+			wantErr: "invalid been export",
+		},
+	})
+}
@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/data"
+)
+
+// Record is a single visit parsed from another travel app's export.
+// VisitedTime is zero when the source format carries no date (e.g. been).
+type Record struct {
+	CountryCode string
+	VisitedTime time.Time
+
+	// Name is the country name the source gave instead of a code, as written there. With an
+	// empty CountryCode the name matched no country; callers report it back to the user.
+	Name string
+}
+
+// Importer parses an export file from another travel app into visit records.
+// CountryCode values are returned as found in the source (may be lowercase or a country name
+// already resolved to a code); callers validate them against the bundled country list.
+type Importer interface {
+	Parse(r io.Reader) ([]Record, error)
+}
+
+// registry maps the `source` query parameter value to its Importer.
+var registry = map[string]Importer{
+	"nomadlist":  nomadListImporter{},
+	"been":       beenImporter{},
+	"polarsteps": polarstepsImporter{},
+}
+
+// Get returns the Importer registered for source (case-insensitive).
+func Get(source string) (Importer, bool) {
+	imp, ok := registry[strings.ToLower(strings.TrimSpace(source))]
+	return imp, ok
+}
+
+// Sources returns the registered source names in sorted order.
+func Sources() []string {
+	out := make([]string, 0, len(registry))
+	for name := range registry {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// parseDate parses the date formats seen in exports: RFC3339 or plain YYYY-MM-DD (UTC).
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t, nil
+}
+
+// resolveCountryName maps a country name or alias (see data.CountryByName) to its code; returns
+// "" when unknown.
+func resolveCountryName(name string) string {
+	if c, ok := data.CountryByName(name); ok {
+		return c.CountryCode
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseTest is a case of the table tests of each format.
+type parseTest struct {
+	name    string
+	input   string
+	want    []Record
+	wantErr string // substring of the error; empty when parsing succeeds
+}
+
+// runParseTests parses each test's input with imp and compares the records or error.
+func runParseTests(t *testing.T, imp Importer, tests []parseTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := imp.Parse(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %+v\nwant      %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "", want: time.Time{}},
+		{input: "2019-01-02", want: date(2019, 1, 2)},
+		{input: " 2019-01-02 ", want: date(2019, 1, 2)},
+		{input: "2019-01-02T10:00:00+02:00", want: time.Date(2019, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{input: "2019-01-02T08:00:00Z", want: time.Date(2019, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{input: "02.01.2019", wantErr: true},
+		{input: "2019-13-01", wantErr: true},
+		{input: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDate(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDate(%q) = %v, want an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolveCountryName(t *testing.T) {
+	tests := map[string]string{
+		"Finland":                  "FI",
+		"finland":                  "FI",
+		"  Finland ":               "FI",
+		"United States of America": "US",
+		"USA":                      "US",
+		"Czech Republic":           "CZ",
+		"Czechia":                  "CZ",
+		"Côte d'Ivoire":            "CI",
+		"Cote dIvoire":             "CI",
+		"Korea, Republic of":       "KR",
+		"The Netherlands":          "NL",
+		"Trinidad & Tobago":        "TT",
+		"Slovak Republic":          "SK",
+		"Macao":                    "MO",
+		"US Virgin Islands":        "VI",
+		"Atlantis":                 "",
+		"":                         "",
+	}
+	for name, want := range tests {
+		if got := resolveCountryName(name); got != want {
+			t.Errorf("resolveCountryName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	for _, source := range []string{"been", " Been ", "NOMADLIST", "polarsteps"} {
+		if _, ok := Get(source); !ok {
+			t.Errorf("Get(%q) found no importer", source)
+		}
+	}
+	if _, ok := Get("tripit"); ok {
+		t.Error("Get(tripit) found an importer")
+	}
+	want := []string{"been", "nomadlist", "polarsteps"}
+	if got := Sources(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Sources() = %v, want %v", got, want)
+	}
+}
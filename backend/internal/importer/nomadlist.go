@@ -0,0 +1,48 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// nomadListImporter parses the Nomad List JSON export:
+// { "trips": [ { "country_code": "th", "country": "Thailand", "date_start": "2019-01-02" }, ... ] }
+// Trips repeated with the same country and start date (the export lists a trip once per city)
+// are one record.
+type nomadListImporter struct{}
+
+func (nomadListImporter) Parse(r io.Reader) ([]Record, error) {
+	var export struct {
+		Trips []struct {
+			CountryCode string `json:"country_code"`
+			Country     string `json:"country"`
+			DateStart   string `json:"date_start"`
+		} `json:"trips"`
+	}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid nomadlist export: %w", err)
+	}
+	records := make([]Record, 0, len(export.Trips))
+	seen := make(map[Record]bool)
+	for i, trip := range export.Trips {
+		t, err := parseDate(trip.DateStart)
+		if err != nil {
+			return nil, fmt.Errorf("trip %d: %w", i, err)
+		}
+		rec := Record{CountryCode: strings.TrimSpace(trip.CountryCode), VisitedTime: t}
+		if rec.CountryCode == "" {
+			rec.Name = strings.TrimSpace(trip.Country)
+			rec.CountryCode = resolveCountryName(rec.Name)
+		}
+		key := rec
+		key.CountryCode = strings.ToUpper(key.CountryCode)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		records = append(records, rec)
+	}
+	return records, nil
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNomadListParse(t *testing.T) {
+	runParseTests(t, nomadListImporter{}, []parseTest{
+		{
+			name: "codes and dates",
+			input: `{"trips": [
+				{"country_code": "th", "country": "Thailand", "date_start": "2019-01-02"},
+				{"country_code": "VN", "date_start": "2019-02-10T12:00:00+07:00"}
+			]}`,
+			want: []Record{
+				{CountryCode: "th", VisitedTime: date(2019, 1, 2)},
+				{CountryCode: "VN", VisitedTime: time.Date(2019, 2, 10, 5, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "names without codes",
+			input: `{"trips": [
+				{"country": "United States of America", "date_start": "2020-03-01"},
+				{"country": " Czech Republic ", "date_start": "2020-04-01"}
+			]}`,
+			want: []Record{
+				{CountryCode: "US", Name: "United States of America", VisitedTime: date(2020, 3, 1)},
+				{CountryCode: "CZ", Name: "Czech Republic", VisitedTime: date(2020, 4, 1)},
+			},
+		},
+		{
+			name: "unknown names",
+			input: `{"trips": [
+				{"country": "Atlantis", "date_start": "2020-03-01"},
+				{"date_start": "2020-03-02"}
+			]}`,
+			want: []Record{
+				{Name: "Atlantis", VisitedTime: date(2020, 3, 1)},
+				{VisitedTime: date(2020, 3, 2)},
+			},
+		},
+		{
+			name:  "missing date",
+			input: `{"trips": [{"country_code": "FI"}]}`,
+			want:  []Record{{CountryCode: "FI"}},
+		},
+		{
+			name: "duplicates",
+			input: `{"trips": [
+				{"country_code": "th", "date_start": "2019-01-02"},
+				{"country_code": "TH", "date_start": "2019-01-02"},
+				{"country_code": "TH", "date_start": "2019-03-01"}
+			]}`,
+			want: []Record{
+				{CountryCode: "th", VisitedTime: date(2019, 1, 2)},
+				{CountryCode: "TH", VisitedTime: date(2019, 3, 1)},
+			},
+		},
+		{name: "no trips", input: `{}`},
+		{
+			name:    "invalid date",
+			input:   `{"trips": [{"country_code": "FI", "date_start": "02.01.2019"}]}`,
+			wantErr: `trip 0: invalid date "02.01.2019"`,
+		},
+		{
+			name:    "malformed json",
+			input:   `{"trips": [{"country_code": "FI",}]}`,
+			wantErr: "invalid nomadlist export",
+		},
+		{
+			name:    "wrong field type",
+			input:   `{"trips": [{"country_code": 246}]}`,
+			wantErr: "invalid nomadlist export",
+		},
+		{name: "not json", input: "FI\nSE\n", wantErr: "invalid nomadlist export"},
+	})
+}
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// polarstepsImporter parses a Polarsteps trip.json export:
+// { "all_steps": [ { "start_time": 1546300800.0, "location": { "country_code": "NL" } }, ... ] }
+// Consecutive steps in the same country are collapsed into one visit dated by the first step.
+// Steps without a country (e.g. at sea) are skipped.
+type polarstepsImporter struct{}
+
+func (polarstepsImporter) Parse(r io.Reader) ([]Record, error) {
+	var export struct {
+		AllSteps []struct {
+			StartTime float64 `json:"start_time"`
+			Location  struct {
+				CountryCode string `json:"country_code"`
+				Country     string `json:"country"`
+			} `json:"location"`
+		} `json:"all_steps"`
+	}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid polarsteps export: %w", err)
+	}
+	var records []Record
+	prev := ""
+	for _, step := range export.AllSteps {
+		rec := Record{CountryCode: strings.TrimSpace(step.Location.CountryCode)}
+		if rec.CountryCode == "" {
+			rec.Name = strings.TrimSpace(step.Location.Country)
+			rec.CountryCode = resolveCountryName(rec.Name)
+		}
+		key := rec.CountryCode
+		if key == "" {
+			key = rec.Name
+		}
+		if key == "" || strings.EqualFold(key, prev) {
+			continue
+		}
+		prev = key
+		if step.StartTime > 0 {
+			rec.VisitedTime = time.Unix(int64(math.Floor(step.StartTime)), 0).UTC()
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolarstepsParse(t *testing.T) {
+	jan1 := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	runParseTests(t, polarstepsImporter{}, []parseTest{
+		{
+			name: "codes and times",
+			input: `{"all_steps": [
+				{"start_time": 1546300800.0, "location": {"country_code": "NL"}},
+				{"start_time": 1546387200.75, "location": {"country_code": "BE"}}
+			]}`,
+			want: []Record{
+				{CountryCode: "NL", VisitedTime: jan1},
+				{CountryCode: "BE", VisitedTime: jan1.Add(24 * time.Hour)},
+			},
+		},
+		{
+			name: "consecutive steps collapse",
+			input: `{"all_steps": [
+				{"start_time": 1546300800, "location": {"country_code": "NL"}},
+				{"start_time": 1546387200, "location": {"country_code": "nl"}},
+				{"start_time": 1546473600, "location": {"country_code": "BE"}},
+				{"start_time": 1546560000, "location": {"country_code": "NL"}}
+			]}`,
+			want: []Record{
+				{CountryCode: "NL", VisitedTime: jan1},
+				{CountryCode: "BE", VisitedTime: jan1.Add(48 * time.Hour)},
+				{CountryCode: "NL", VisitedTime: jan1.Add(72 * time.Hour)},
+			},
+		},
+		{
+			name: "names without codes",
+			input: `{"all_steps": [
+				{"start_time": 1546300800, "location": {"country": "The Netherlands"}},
+				{"start_time": 1546387200, "location": {"country": "Czech Republic"}}
+			]}`,
+			want: []Record{
+				{CountryCode: "NL", Name: "The Netherlands", VisitedTime: jan1},
+				{CountryCode: "CZ", Name: "Czech Republic", VisitedTime: jan1.Add(24 * time.Hour)},
+			},
+		},
+		{
+			name: "unknown names and steps without a country",
+			input: `{"all_steps": [
+				{"start_time": 1546300800, "location": {"country": "Atlantis"}},
+				{"start_time": 1546387200, "location": {"country": "Atlantis"}},
+				{"start_time": 1546473600, "location": {}},
+				{"start_time": 1546560000, "location": {"country_code": "NL"}}
+			]}`,
+			want: []Record{
+				{Name: "Atlantis", VisitedTime: jan1},
+				{CountryCode: "NL", VisitedTime: jan1.Add(72 * time.Hour)},
+			},
+		},
+		{
+			name: "missing or negative start time",
+			input: `{"all_steps": [
+				{"location": {"country_code": "NL"}},
+				{"start_time": -5, "location": {"country_code": "BE"}}
+			]}`,
+			want: []Record{{CountryCode: "NL"}, {CountryCode: "BE"}},
+		},
+		{name: "no steps", input: `{"all_steps": []}`},
+		{
+			name:    "malformed json",
+			input:   `{"all_steps": [`,
+			wantErr: "invalid polarsteps export",
+		},
+		{
+			name:    "start time as string",
+			input:   `{"all_steps": [{"start_time": "2019-01-01", "location": {"country_code": "NL"}}]}`,
+			wantErr: "invalid polarsteps export",
+		},
+	})
+}
@@ -0,0 +1,170 @@
+// Package insights computes per-user travel trends (visit frequency change, newly visited
+// continents, the longest gap between visits) so clients can show them without processing all
+// visits. The insights backfill job rebuilds every user's Insights document; a Scheduler on
+// every instance starts it once a week.
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/backfill"
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// checkInterval is how often a Scheduler checks whether the job is due.
+const checkInterval = time.Hour
+
+// Compute returns the insights of visits as of now.
+func Compute(visits []models.CountryVisit, now time.Time) models.Insights {
+	sorted := make([]models.CountryVisit, len(visits))
+	copy(sorted, visits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].VisitedTime.Before(sorted[j].VisitedTime)
+	})
+
+	recentStart := now.Add(-models.InsightsPeriod)
+	previousStart := recentStart.Add(-models.InsightsPeriod)
+	ins := models.Insights{GeneratedAt: now, NewRegionCodes: []string{}}
+	seenRegions := make(map[string]bool)
+	for i, v := range sorted {
+		t := v.VisitedTime
+		switch {
+		case !t.Before(recentStart) && !t.After(now):
+			ins.RecentVisitCount++
+		case !t.Before(previousStart) && t.Before(recentStart):
+			ins.PreviousVisitCount++
+		}
+		if region := regionCode(v.CountryCode); region != "" && !seenRegions[region] {
+			seenRegions[region] = true
+			if !t.Before(recentStart) && !t.After(now) {
+				ins.NewRegionCodes = append(ins.NewRegionCodes, region)
+			}
+		}
+		if i == 0 {
+			continue
+		}
+		from := sorted[i-1].VisitedTime
+		if gap := t.Sub(from); ins.LongestGap == nil ||
+			gap > ins.LongestGap.To.Sub(ins.LongestGap.From) {
+			ins.LongestGap = &models.TripGap{From: from, To: t, Days: int(gap.Hours() / 24)}
+		}
+	}
+	if ins.PreviousVisitCount > 0 {
+		change := float64(ins.RecentVisitCount-ins.PreviousVisitCount) /
+			float64(ins.PreviousVisitCount) * 100
+		ins.FrequencyChangePercent = &change
+	}
+	return ins
+}
+
+// regionCode returns the continent of a current or historic country code, or "" when unknown.
+func regionCode(countryCode string) string {
+	if c, ok := data.CountryByCode(countryCode); ok {
+		return c.RegionCode
+	}
+	if h, ok := data.HistoricCountryByCode(countryCode); ok {
+		return h.RegionCode
+	}
+	return ""
+}
+
+// Store reads visits and saves insights. Implemented by database.Client.
+type Store interface {
+	GetCountryVisitsByUser(ctx context.Context, userID string) ([]models.CountryVisit, error)
+	SaveInsights(ctx context.Context, userID string, ins *models.Insights) error
+}
+
+// Generator rebuilds the Insights document of a user.
+type Generator struct {
+	store Store
+}
+
+// NewGenerator returns a Generator reading and writing through store.
+func NewGenerator(store Store) *Generator {
+	return &Generator{store: store}
+}
+
+// Rebuild computes and saves the insights of userID. It is a backfill.RebuildFunc.
+func (g *Generator) Rebuild(ctx context.Context, userID string) error {
+	visits, err := g.store.GetCountryVisitsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	ins := Compute(visits, time.Now().UTC())
+	return g.store.SaveInsights(ctx, userID, &ins)
+}
+
+// Jobs starts backfill jobs. Implemented by backfill.Runner.
+type Jobs interface {
+	Status(ctx context.Context, name string) (*models.BackfillJob, error)
+	Start(ctx context.Context, name string, restart bool) (*models.BackfillJob, error)
+}
+
+// Scheduler starts the insights backfill job when its last run started more than an interval
+// ago, and resumes it when interrupted. A job paused by an admin is left paused.
+type Scheduler struct {
+	jobs     Jobs
+	interval time.Duration
+}
+
+// NewScheduler returns a Scheduler that runs backfill.JobInsights every interval.
+func NewScheduler(jobs Jobs, interval time.Duration) *Scheduler {
+	return &Scheduler{jobs: jobs, interval: interval}
+}
+
+// Check starts or resumes the job when due. Instances racing to start it are stopped by
+// backfill.ErrAlreadyRunning, which is not an error here.
+func (s *Scheduler) Check(ctx context.Context) error {
+	ctx, span := tracing.New(ctx, "insights.Check")
+	defer span.End()
+
+	job, err := s.jobs.Status(ctx, backfill.JobInsights)
+	if err != nil {
+		return fmt.Errorf("failed to get insights job: %w", err)
+	}
+	var restart bool
+	switch job.Status {
+	case models.BackfillPaused:
+		return nil
+	case models.BackfillPending:
+		restart = true
+	case models.BackfillCompleted:
+		if time.Since(job.StartedAt) < s.interval {
+			return nil
+		}
+		restart = true
+	}
+	// A running job is resumed only when its checkpoint is stale, see backfill.Runner.Start
+	_, err = s.jobs.Start(ctx, backfill.JobInsights, restart)
+	if errors.Is(err, backfill.ErrAlreadyRunning) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start insights job: %w", err)
+	}
+	logging.FromContext(ctx).Info("Started insights job", "restart", restart)
+	return nil
+}
+
+// Run calls Check at once and then hourly until ctx is cancelled. Failures are logged.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.Check(ctx); err != nil {
+			logging.FromContext(ctx).Error("Insights schedule check failed", logging.Error, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
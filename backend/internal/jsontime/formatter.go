@@ -0,0 +1,45 @@
+package jsontime
+
+// Formatter is implemented by the response models that have timestamps. They marshal them as
+// encoding/json writes time.Time until a format is set with WithTimeFormat, which also sets it
+// on the models they contain.
+type Formatter interface {
+	// WithTimeFormat returns a copy of the value whose timestamps marshal in f.
+	WithTimeFormat(f Format) any
+}
+
+// Apply returns v with its timestamps set to marshal in f if v is a Formatter, else v itself.
+// Response writers call it on every body before json.Marshal.
+func Apply(v any, f Format) any {
+	if m, ok := v.(Formatter); ok {
+		return m.WithTimeFormat(f)
+	}
+	return v
+}
+
+// Set returns a copy of v whose timestamps marshal in f.
+func Set[T Formatter](v T, f Format) T {
+	return v.WithTimeFormat(f).(T)
+}
+
+// SetPtr is Set for an optional value; it returns nil for nil and never modifies *v.
+func SetPtr[T Formatter](v *T, f Format) *T {
+	if v == nil {
+		return nil
+	}
+	out := Set(*v, f)
+	return &out
+}
+
+// SetAll returns a copy of items with the timestamps of each set to marshal in f; nil stays
+// nil, so that omitempty still applies.
+func SetAll[T Formatter](items []T, f Format) []T {
+	if items == nil {
+		return nil
+	}
+	out := make([]T, len(items))
+	for i, item := range items {
+		out[i] = Set(item, f)
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+// Package jsontime implements the API's JSON time encoding policy: timestamps are written either
+// as RFC 3339 strings or as Unix seconds, chosen per request, and accepted in both forms.
+package jsontime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a JSON time encoding.
+type Format string
+
+const (
+	// RFC3339 writes times as RFC 3339 strings (e.g. "2024-05-01T00:00:00Z"), as encoding/json
+	// writes time.Time.
+	RFC3339 Format = "rfc3339"
+	// Unix writes times as integer Unix seconds.
+	Unix Format = "unix"
+)
+
+// ErrUnknownFormat is returned by ParseFormat for values other than rfc3339 and unix.
+var ErrUnknownFormat = errors.New("time format must be rfc3339 or unix")
+
+// ParseFormat parses a format name (case-insensitive).
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case RFC3339, Unix:
+		return f, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// Time is a time.Time that marshals in its Format and unmarshals from either a JSON number
+// (Unix seconds) or an RFC 3339 string. In any format other than Unix it marshals as time.Time
+// does; in Unix, the zero Time marshals as null.
+type Time struct {
+	time.Time
+	Format Format
+}
+
+// New returns t as a Time marshaling in format f.
+func New(t time.Time, f Format) Time {
+	return Time{Time: t, Format: f}
+}
+
+// NewPtr is New for an optional time; it returns nil for nil.
+func NewPtr(t *time.Time, f Format) *Time {
+	if t == nil {
+		return nil
+	}
+	out := New(*t, f)
+	return &out
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.Format != Unix {
+		return t.Time.MarshalJSON()
+	}
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return strconv.AppendInt(nil, t.Unix(), 10), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The resulting Time is in UTC.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		parsed, err := Parse(s)
+		if err != nil {
+			return err
+		}
+		t.Time = parsed
+		return nil
+	}
+	secs, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("time must be Unix seconds or an RFC 3339 string: %w", err)
+	}
+	t.Time = time.Unix(secs, 0).UTC()
+	return nil
+}
+
+// Parse parses s as Unix seconds or an RFC 3339 timestamp (query parameters, import options).
+func Parse(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time must be Unix seconds or an RFC 3339 string: %w", err)
+	}
+	return parsed.UTC(), nil
+}
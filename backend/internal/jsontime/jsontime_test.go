@@ -0,0 +1,76 @@
+package jsontime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimeMarshalJSON(t *testing.T) {
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value Time
+		want  string
+	}{
+		{New(at, RFC3339), `"2024-05-01T00:00:00Z"`},
+		{New(at, ""), `"2024-05-01T00:00:00Z"`},
+		{New(at, Unix), `1714521600`},
+		{New(time.Time{}, RFC3339), `"0001-01-01T00:00:00Z"`},
+		{New(time.Time{}, Unix), `null`},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: Marshal = %s, want %s", tt.value.Format, got, tt.want)
+		}
+	}
+}
+
+func TestTimeUnmarshalJSON(t *testing.T) {
+	want := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, in := range []string{`1714521600`, `"2024-05-01T00:00:00Z"`, `"2024-05-01T03:00:00+03:00"`} {
+		var got Time
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if !got.Equal(want) || got.Location() != time.UTC {
+			t.Errorf("%s: got %v, want %v", in, got.Time, want)
+		}
+	}
+	var got Time
+	if err := json.Unmarshal([]byte(`"yesterday"`), &got); err == nil {
+		t.Error("expected an error for a malformed time")
+	}
+}
+
+// stamped records the format it was given.
+type stamped struct {
+	Format Format
+}
+
+func (s stamped) WithTimeFormat(f Format) any {
+	s.Format = f
+	return s
+}
+
+func TestSetAll(t *testing.T) {
+	if got := SetAll[stamped](nil, Unix); got != nil {
+		t.Errorf("SetAll(nil) = %v, want nil", got)
+	}
+	items := []stamped{{}, {}}
+	got := SetAll(items, Unix)
+	for i := range got {
+		if got[i].Format != Unix || items[i].Format != "" {
+			t.Errorf("item %d: got %q, input %q", i, got[i].Format, items[i].Format)
+		}
+	}
+	if v, ok := Apply(stamped{}, Unix).(stamped); !ok || v.Format != Unix {
+		t.Errorf("Apply = %v", v)
+	}
+	if v := Apply("plain", Unix); v != "plain" {
+		t.Errorf("Apply(non-Formatter) = %v", v)
+	}
+}
@@ -10,4 +10,13 @@ const (
 	Port        = "port"
 	Count       = "count"
 	CountryCode = "country_code"
+
+	FeaturePreview = "feature_preview"
+	DryRun         = "dry_run"
+	BackfillJob    = "backfill_job"
+	OrganizationID = "organization_id"
+	WriteJob       = "write_job"
+	DBMethod       = "db_method"
+	Attempt        = "attempt"
+	RequestID      = "request_id"
 )
@@ -0,0 +1,113 @@
+// Package mailer sends transactional email (friend invitations) through an SMTP relay. The app
+// has no email provider of its own; without SMTP_HOST no Mailer is created and features that
+// send email are unavailable.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config configures the SMTP relay.
+type Config struct {
+	// Host is the SMTP relay host name. Empty disables email.
+	Host string
+
+	// Port is the submission port; the connection is upgraded with STARTTLS when offered.
+	Port int
+
+	// Username and Password authenticate with PLAIN auth when Username is set.
+	Username string
+	Password string
+
+	// From is the sender address, e.g. "My Countries <noreply@example.com>".
+	From string
+}
+
+// Enabled reports whether email is configured.
+func (c Config) Enabled() bool {
+	return c.Host != ""
+}
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPMailer sends email through the relay of its Config.
+type SMTPMailer struct {
+	cfg  Config
+	from *mail.Address
+}
+
+// NewSMTPMailer returns an SMTPMailer for cfg, which must be enabled and have a valid From.
+func NewSMTPMailer(cfg Config) (*SMTPMailer, error) {
+	if !cfg.Enabled() {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", cfg.From, err)
+	}
+	return &SMTPMailer{cfg: cfg, from: from}, nil
+}
+
+// Send delivers msg. The SMTP exchange cannot be cancelled; ctx only bounds the wait before it.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	err = smtp.SendMail(addr, auth, m.from.Address, []string{to.Address}, m.compose(to, msg))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// compose renders msg as an RFC 5322 message with CRLF line endings.
+func (m *SMTPMailer) compose(to *mail.Address, msg Message) []byte {
+	var b strings.Builder
+	header := func(name, value string) {
+		b.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", m.from.String())
+	header("To", to.String())
+	header("Subject", mimeHeader(msg.Subject))
+	header("Date", time.Now().UTC().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// mimeHeader encodes s for a header value, dropping line breaks that would start a new header.
+func mimeHeader(s string) string {
+	s = strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	return mime.QEncoding.Encode("UTF-8", s)
+}
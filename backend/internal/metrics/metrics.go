@@ -0,0 +1,158 @@
+// Package metrics collects instance metrics and serves them in the Prometheus text format:
+// HTTP requests per route and status, Firestore RPC latencies and any counters or gauges read
+// on scrape (e.g. JWKS fetches, open visit streams). main serves Handler on METRICS_PORT, a
+// listener separate from the API so metrics are only reachable from inside the deployment
+// (e.g. a Prometheus sidecar). Written against the text format directly rather than a client
+// library, which the few metric types here do not need.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// contentType is the Content-Type of the Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// listenMethod is the Firestore RPC of snapshot listeners, which stay open as long as they
+// listen and so have no meaningful latency.
+const listenMethod = "Listen"
+
+// Metrics are the metrics of an instance.
+type Metrics struct {
+	registry registry
+
+	requests          *vec
+	requestDuration   *vec
+	firestoreDuration *vec
+}
+
+// New returns Metrics with the HTTP and Firestore metric families registered.
+func New() *Metrics {
+	m := &Metrics{
+		requests: newVec("http_requests_total",
+			"HTTP requests by route template, method and status code.", nil,
+			"route", "method", "status"),
+		requestDuration: newVec("http_request_duration_seconds",
+			"HTTP request latency by route template and method.", defaultBuckets,
+			"route", "method"),
+		firestoreDuration: newVec("firestore_rpc_duration_seconds",
+			"Firestore RPC latency by method and gRPC status code.", defaultBuckets,
+			"method", "code"),
+	}
+	m.registry.register(m.requests)
+	m.registry.register(m.requestDuration)
+	m.registry.register(m.firestoreDuration)
+	return m
+}
+
+// ObserveRequest records an HTTP request of route (the route template, e.g. /visits/:id) that
+// took d and responded status.
+func (m *Metrics) ObserveRequest(route, method string, status int, d time.Duration) {
+	m.requests.add(1, route, method, strconv.Itoa(status))
+	m.requestDuration.observe(d.Seconds(), route, method)
+}
+
+// CounterFunc registers a counter without labels whose value is read from fn on every scrape.
+func (m *Metrics) CounterFunc(name, help string, fn func() float64) {
+	m.registry.register(funcMetric{name: name, help: help, kind: "counter", fn: fn})
+}
+
+// GaugeFunc registers a gauge without labels whose value is read from fn on every scrape.
+func (m *Metrics) GaugeFunc(name, help string, fn func() float64) {
+	m.registry.register(funcMetric{name: name, help: help, kind: "gauge", fn: fn})
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		buf := bufio.NewWriter(w)
+		if err := m.registry.write(buf); err != nil {
+			return
+		}
+		buf.Flush()
+	})
+}
+
+// ClientOptions returns the options making a Firestore client record the latency of its RPCs.
+// A streaming RPC (e.g. RunQuery) is timed until its last response.
+func (m *Metrics) ClientOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(m.unaryInterceptor)),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(m.streamInterceptor)),
+	}
+}
+
+func (m *Metrics) unaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	m.observeRPC(method, err, time.Since(start))
+	return err
+}
+
+func (m *Metrics) streamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	start := time.Now()
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if path.Base(method) == listenMethod {
+		return stream, err
+	}
+	if err != nil {
+		m.observeRPC(method, err, time.Since(start))
+		return nil, err
+	}
+	return &timedStream{ClientStream: stream, done: func(err error) {
+		m.observeRPC(method, err, time.Since(start))
+	}}, nil
+}
+
+// observeRPC records a Firestore RPC, labeled with the last element of its full method name.
+func (m *Metrics) observeRPC(method string, err error, d time.Duration) {
+	m.firestoreDuration.observe(d.Seconds(), path.Base(method), status.Code(err).String())
+}
+
+// timedStream calls done once, when the stream ends: io.EOF (success) or an error from RecvMsg.
+type timedStream struct {
+	grpc.ClientStream
+	once sync.Once
+	done func(err error)
+}
+
+func (s *timedStream) RecvMsg(msg any) error {
+	err := s.ClientStream.RecvMsg(msg)
+	if err != nil {
+		s.once.Do(func() {
+			if errors.Is(err, io.EOF) {
+				s.done(nil)
+				return
+			}
+			s.done(err)
+		})
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultBuckets are the upper bounds (seconds) of latency histograms, as in the Prometheus
+// client libraries.
+var defaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metric is a metric family that writes itself in the Prometheus text format.
+type metric interface {
+	write(w io.Writer) error
+}
+
+// registry holds metric families in registration order.
+type registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func (r *registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// write writes all families in the Prometheus text exposition format (version 0.0.4).
+func (r *registry) write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// series is one labeled series of a family.
+type series struct {
+	labels []string
+
+	// value is the counter value; for histograms the sum of the observations.
+	value float64
+
+	// counts are the histogram's cumulative bucket counts, the last one for +Inf.
+	counts []uint64
+}
+
+// vec is a counter or histogram family with labels.
+type vec struct {
+	name, help string
+	labelNames []string
+	buckets    []float64 // histograms only
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func newVec(name, help string, buckets []float64, labelNames ...string) *vec {
+	return &vec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     make(map[string]*series),
+	}
+}
+
+// get returns the series of labels, creating it. v.mu must be held.
+func (v *vec) get(labels []string) *series {
+	key := strings.Join(labels, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labels: slices.Clone(labels)}
+		if v.buckets != nil {
+			s.counts = make([]uint64, len(v.buckets)+1)
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+// add adds delta to the counter series of labels.
+func (v *vec) add(delta float64, labels ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.get(labels).value += delta
+}
+
+// observe records x in the histogram series of labels.
+func (v *vec) observe(x float64, labels ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s := v.get(labels)
+	s.value += x
+	for i, upper := range v.buckets {
+		if x <= upper {
+			s.counts[i]++
+		}
+	}
+	s.counts[len(v.buckets)]++
+}
+
+func (v *vec) write(w io.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	kind := "counter"
+	if v.buckets != nil {
+		kind = "histogram"
+	}
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name,
+		kind); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		s := v.series[k]
+		labels := formatLabels(v.labelNames, s.labels)
+		if v.buckets == nil {
+			if _, err := fmt.Fprintf(w, "%s%s %s\n", v.name, labels,
+				formatFloat(s.value)); err != nil {
+				return err
+			}
+			continue
+		}
+		for i, upper := range v.buckets {
+			le := formatLabels(append(slices.Clone(v.labelNames), "le"),
+				append(slices.Clone(s.labels), formatFloat(upper)))
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, le, s.counts[i]); err != nil {
+				return err
+			}
+		}
+		count := s.counts[len(v.buckets)]
+		inf := formatLabels(append(slices.Clone(v.labelNames), "le"),
+			append(slices.Clone(s.labels), "+Inf"))
+		_, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			v.name, inf, count, v.name, labels, formatFloat(s.value), v.name, labels, count)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// funcMetric is an unlabeled counter or gauge read from fn when scraped.
+type funcMetric struct {
+	name, help, kind string
+	fn               func() float64
+}
+
+func (f funcMetric) write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", f.name, f.help, f.name,
+		f.kind, f.name, formatFloat(f.fn()))
+	return err
+}
+
+// formatLabels returns {name="value",...}, or "" without labels.
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,110 @@
+// Package migrations upgrades Firestore documents written with an older schema. A migrated
+// collection stores the schema version of each document in its SchemaVersion field (missing
+// means version 0). A Migration moves the documents of one collection from Version-1 to Version
+// by editing their raw fields; new documents are written at Latest. The database upgrades
+// documents lazily when reading them, and the schema-migrations backfill job upgrades the rest.
+//
+// Migrations are never edited or removed once deployed, since documents may still be at any
+// earlier version. Renaming a field that queries filter or order on (such as VisitTime, the
+// stored name of CountryVisit.VisitedTime) takes two releases: one migration copying the value
+// to the new field while queries keep the old one, then, once the backfill job has completed,
+// the switch of the queries and indexes.
+package migrations
+
+import "fmt"
+
+// Field is the document field holding the schema version.
+const Field = "SchemaVersion"
+
+// CountryVisits is the collection ID of country visits (users/{userID}/country_visits).
+const CountryVisits = "country_visits"
+
+// Migration upgrades documents of Collection to Version.
+type Migration struct {
+	Collection  string
+	Version     int
+	Description string
+
+	// Up edits the fields of a document at Version-1 in place. It must also accept a document
+	// already (partially) at Version, as concurrent readers may upgrade the same document.
+	Up func(data map[string]any) error
+}
+
+// Registry lists the migrations of every collection, in increasing Version per collection
+// without gaps.
+var Registry = []Migration{
+	{
+		Collection: CountryVisits,
+		Version:    1,
+		// Visits were written without IsPrivate when not private, and some without Tags
+		Description: "store IsPrivate and Tags on every visit",
+		Up: func(data map[string]any) error {
+			if _, ok := data["IsPrivate"]; !ok {
+				data["IsPrivate"] = false
+			}
+			if data["Tags"] == nil {
+				data["Tags"] = []any{}
+			}
+			return nil
+		},
+	},
+	{
+		Collection: CountryVisits,
+		Version:    2,
+		// First step of renaming VisitTime to VisitedTime, the name of the model field: queries,
+		// indexes and decoding keep VisitTime until the schema-migrations job has completed
+		Description: "copy VisitTime to VisitedTime",
+		Up: func(data map[string]any) error {
+			if _, ok := data["VisitedTime"]; ok {
+				return nil
+			}
+			t, ok := data["VisitTime"]
+			if !ok {
+				return fmt.Errorf("visit has no VisitTime")
+			}
+			data["VisitedTime"] = t
+			return nil
+		},
+	},
+}
+
+// Latest returns the schema version new documents of collection are written at: the highest
+// Version registered for it, or 0.
+func Latest(collection string) int {
+	latest := 0
+	for _, m := range Registry {
+		if m.Collection == collection {
+			latest = max(latest, m.Version)
+		}
+	}
+	return latest
+}
+
+// Version returns the schema version of the document fields data.
+func Version(data map[string]any) int {
+	v, _ := data[Field].(int64)
+	return int(v)
+}
+
+// Upgrade applies the migrations of collection newer than the version of data to data and sets
+// its Field. It reports whether data was changed, i.e. was not at Latest.
+func Upgrade(collection string, data map[string]any) (bool, error) {
+	version := Version(data)
+	changed := false
+	for _, m := range Registry {
+		if m.Collection != collection || m.Version <= version {
+			continue
+		}
+		if m.Version != version+1 {
+			return changed, fmt.Errorf("missing %s migration to version %d", collection, version+1)
+		}
+		if err := m.Up(data); err != nil {
+			return changed, fmt.Errorf("%s migration to version %d failed: %w",
+				collection, m.Version, err)
+		}
+		version = m.Version
+		data[Field] = int64(version)
+		changed = true
+	}
+	return changed, nil
+}
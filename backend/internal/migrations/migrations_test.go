@@ -0,0 +1,209 @@
+package migrations
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var visitTime = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// fixture is a document before and after one migration.
+type fixture struct {
+	name    string
+	before  map[string]any
+	after   map[string]any
+	wantErr bool
+}
+
+// fixtures lists the test documents of each migration by collection and version. Every
+// migration in Registry needs at least one.
+var fixtures = map[string]map[int][]fixture{
+	CountryVisits: {
+		1: {
+			{
+				name:   "missing IsPrivate and Tags",
+				before: map[string]any{"CountryCode": "FI", "VisitTime": visitTime},
+				after: map[string]any{
+					"CountryCode": "FI", "VisitTime": visitTime, "IsPrivate": false,
+					"Tags": []any{},
+				},
+			},
+			{
+				name: "null Tags",
+				before: map[string]any{
+					"CountryCode": "FI", "VisitTime": visitTime, "IsPrivate": true, "Tags": nil,
+				},
+				after: map[string]any{
+					"CountryCode": "FI", "VisitTime": visitTime, "IsPrivate": true,
+					"Tags": []any{},
+				},
+			},
+			{
+				name: "already set",
+				before: map[string]any{
+					"CountryCode": "FI", "VisitTime": visitTime, "IsPrivate": true,
+					"Tags": []any{"sauna"},
+				},
+				after: map[string]any{
+					"CountryCode": "FI", "VisitTime": visitTime, "IsPrivate": true,
+					"Tags": []any{"sauna"},
+				},
+			},
+		},
+		2: {
+			{
+				name:   "VisitTime only",
+				before: map[string]any{"CountryCode": "FI", "VisitTime": visitTime},
+				after: map[string]any{
+					"CountryCode": "FI", "VisitTime": visitTime, "VisitedTime": visitTime,
+				},
+			},
+			{
+				name: "already copied",
+				before: map[string]any{
+					"CountryCode": "FI", "VisitTime": visitTime, "VisitedTime": visitTime,
+				},
+				after: map[string]any{
+					"CountryCode": "FI", "VisitTime": visitTime, "VisitedTime": visitTime,
+				},
+			},
+			{
+				name:    "no VisitTime",
+				before:  map[string]any{"CountryCode": "FI"},
+				wantErr: true,
+			},
+		},
+	},
+}
+
+// clone copies the fields of a fixture document, so migrations cannot edit the fixture.
+func clone(data map[string]any) map[string]any {
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		if s, ok := v.([]any); ok {
+			v = append([]any{}, s...)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+func TestRegistry(t *testing.T) {
+	versions := map[string]int{}
+	for _, m := range Registry {
+		if m.Version != versions[m.Collection]+1 {
+			t.Errorf("%s migration %d follows version %d", m.Collection, m.Version,
+				versions[m.Collection])
+		}
+		versions[m.Collection] = m.Version
+		if m.Description == "" || m.Up == nil {
+			t.Errorf("%s migration %d has no description or Up", m.Collection, m.Version)
+		}
+		if len(fixtures[m.Collection][m.Version]) == 0 {
+			t.Errorf("%s migration %d has no fixtures", m.Collection, m.Version)
+		}
+	}
+	for collection, latest := range versions {
+		if got := Latest(collection); got != latest {
+			t.Errorf("Latest(%s) = %d, want %d", collection, got, latest)
+		}
+	}
+	if got := Latest("unknown"); got != 0 {
+		t.Errorf("Latest(unknown) = %d, want 0", got)
+	}
+}
+
+// TestMigrations runs each migration on its fixtures, and again on its own result, which must
+// not change: concurrent readers may migrate the same document.
+func TestMigrations(t *testing.T) {
+	for _, m := range Registry {
+		for _, f := range fixtures[m.Collection][m.Version] {
+			t.Run(fmt.Sprintf("%s/%d/%s", m.Collection, m.Version, f.name), func(t *testing.T) {
+				data := clone(f.before)
+				err := m.Up(data)
+				if f.wantErr {
+					if err == nil {
+						t.Fatalf("Up succeeded with %v, want an error", data)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(data, f.after) {
+					t.Fatalf("Up = %v, want %v", data, f.after)
+				}
+				if err := m.Up(data); err != nil {
+					t.Fatalf("Up on a migrated document: %v", err)
+				}
+				if !reflect.DeepEqual(data, f.after) {
+					t.Errorf("Up is not idempotent: %v, want %v", data, f.after)
+				}
+			})
+		}
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	latest := Latest(CountryVisits)
+	data := map[string]any{"CountryCode": "FI", "VisitTime": visitTime}
+	changed, err := Upgrade(CountryVisits, data)
+	if err != nil || !changed {
+		t.Fatalf("Upgrade = %v, %v, want changed", changed, err)
+	}
+	want := map[string]any{
+		"CountryCode": "FI", "VisitTime": visitTime, "VisitedTime": visitTime,
+		"IsPrivate": false, "Tags": []any{}, Field: int64(latest),
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("Upgrade from 0 = %v, want %v", data, want)
+	}
+	if Version(data) != latest {
+		t.Errorf("Version = %d, want %d", Version(data), latest)
+	}
+
+	// At the latest version nothing changes
+	again := clone(data)
+	changed, err = Upgrade(CountryVisits, again)
+	if err != nil || changed || !reflect.DeepEqual(again, want) {
+		t.Errorf("second Upgrade = %v, %v, %v; want unchanged", changed, err, again)
+	}
+
+	// From an intermediate version only the newer migrations run
+	partial := map[string]any{"CountryCode": "FI", "VisitTime": visitTime, Field: int64(1)}
+	if _, err := Upgrade(CountryVisits, partial); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := partial["IsPrivate"]; ok {
+		t.Errorf("Upgrade from 1 ran migration 1: %v", partial)
+	}
+	if partial["VisitedTime"] != visitTime || Version(partial) != latest {
+		t.Errorf("Upgrade from 1 = %v", partial)
+	}
+
+	// A failing migration leaves the version at the last one applied
+	broken := map[string]any{"CountryCode": "FI"}
+	changed, err = Upgrade(CountryVisits, broken)
+	if err == nil || !changed || Version(broken) != 1 {
+		t.Errorf("Upgrade without VisitTime = %v, %v, version %d; want an error at version 1",
+			changed, err, Version(broken))
+	}
+}
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		data map[string]any
+		want int
+	}{
+		{map[string]any{}, 0},
+		{map[string]any{Field: int64(2)}, 2},
+		{map[string]any{Field: "2"}, 0},
+	}
+	for _, tt := range tests {
+		if got := Version(tt.data); got != tt.want {
+			t.Errorf("Version(%v) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+}
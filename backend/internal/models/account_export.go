@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// AccountExportUser is the "user" section of the GET /me/export archive: the User document
+// without internal fields.
+type AccountExportUser struct {
+	// ExportedAt is when the export was started.
+	ExportedAt time.Time `json:"exportedAt"`
+
+	UserID              string     `json:"userId"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	ImageURL            string     `json:"imageUrl,omitempty"`
+	ShareToken          string     `json:"shareToken"`
+	Handle              string     `json:"handle,omitempty"`
+	IsAnonymous         bool       `json:"isAnonymous,omitempty"`
+	SharingDisabled     bool       `json:"sharingDisabled,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (u AccountExportUser) MarshalJSON() ([]byte, error) {
+	type fields AccountExportUser
+	return json.Marshal(struct {
+		fields
+		ExportedAt          jsontime.Time  `json:"exportedAt"`
+		DeletionScheduledAt *jsontime.Time `json:"deletionScheduledAt,omitempty"`
+	}{
+		fields(u),
+		jsontime.New(u.ExportedAt, u.timeFormat),
+		jsontime.NewPtr(u.DeletionScheduledAt, u.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (u AccountExportUser) WithTimeFormat(f jsontime.Format) any {
+	u.timeFormat = f
+	return u
+}
@@ -0,0 +1,24 @@
+package models
+
+// AccountMergeResult is the response for POST /me/merge: how much of the merged account was
+// moved to the kept one.
+type AccountMergeResult struct {
+	// Visits is the number of country visits moved.
+	Visits int `json:"visits"`
+
+	// Friends is the number of friends added; friends the kept account already had are not
+	// counted.
+	Friends int `json:"friends"`
+
+	// Followers is the number of users whose friend entry now points to the kept account.
+	Followers int `json:"followers"`
+
+	// BlockedUsers, ShareLinks and APIKeys are the numbers of those documents moved.
+	BlockedUsers int `json:"blockedUsers"`
+	ShareLinks   int `json:"shareLinks"`
+	APIKeys      int `json:"apiKeys"`
+
+	// Handle is the vanity handle taken over from the merged account; empty when the kept
+	// account had one already or the merged account had none.
+	Handle string `json:"handle,omitempty"`
+}
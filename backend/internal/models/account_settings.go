@@ -0,0 +1,9 @@
+package models
+
+// AccountSettings is the body and response for GET/PATCH /me/settings: account-wide switches
+// stored on the User document, apart from the profile Settings replaced by PUT /settings.
+type AccountSettings struct {
+	// SharingDisabled makes the user's share views return 404 for the ShareToken and every
+	// share link, without changing them; see User.SharingDisabled.
+	SharingDisabled bool `json:"sharingDisabled"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// AccountDeletionGracePeriod is how long after DELETE /account the account is purged; the user
+// can cancel the deletion until then.
+const AccountDeletionGracePeriod = 14 * 24 * time.Hour
+
+// Account statuses of AccountStatus.
+const (
+	AccountStatusActive          = "active"
+	AccountStatusPendingDeletion = "pending_deletion"
+)
+
+// AccountStatus is the response for GET and DELETE /account and POST /account/cancel-deletion.
+type AccountStatus struct {
+	Status string `json:"status"`
+
+	// DeletionAt is when the account will be purged; set only with AccountStatusPendingDeletion.
+	DeletionAt *time.Time `json:"deletionAt,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (s AccountStatus) MarshalJSON() ([]byte, error) {
+	type fields AccountStatus
+	return json.Marshal(struct {
+		fields
+		DeletionAt *jsontime.Time `json:"deletionAt,omitempty"`
+	}{
+		fields(s),
+		jsontime.NewPtr(s.DeletionAt, s.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (s AccountStatus) WithTimeFormat(f jsontime.Format) any {
+	s.timeFormat = f
+	return s
+}
+
+// AccountStatusOf returns the account status of u.
+func AccountStatusOf(u *User) AccountStatus {
+	if u.PendingDeletion() {
+		return AccountStatus{Status: AccountStatusPendingDeletion, DeletionAt: u.DeletionScheduledAt}
+	}
+	return AccountStatus{Status: AccountStatusActive}
+}
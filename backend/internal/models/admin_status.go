@@ -0,0 +1,146 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// AdminStatusResponse is the response for GET /admin/status: live operational signals of the
+// instance that served the request, for on-call triage.
+type AdminStatusResponse struct {
+	Build    BuildStatus            `json:"build"`
+	Requests RequestStatus          `json:"requests"`
+	Caches   map[string]CacheStatus `json:"caches"`
+	Queues   map[string]int         `json:"queues"`
+	Auth     AuthStatus             `json:"auth"`
+
+	// ReadOnly is true in read-only maintenance mode (READ_ONLY).
+	ReadOnly bool `json:"readOnly"`
+
+	// RateLimited counts the requests refused with 429 since startup per configured quota.
+	RateLimited map[string]int64 `json:"rateLimited"`
+
+	// Faults is set only when fault injection is enabled (staging).
+	Faults *FaultStatus `json:"faults,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r AdminStatusResponse) MarshalJSON() ([]byte, error) {
+	type fields AdminStatusResponse
+	r.Caches = emptyMapIfNil(r.Caches)
+	r.Queues = emptyMapIfNil(r.Queues)
+	r.RateLimited = emptyMapIfNil(r.RateLimited)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r AdminStatusResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Build = jsontime.Set(r.Build, f)
+	r.Auth = jsontime.Set(r.Auth, f)
+	return r
+}
+
+// BuildStatus identifies the running binary.
+type BuildStatus struct {
+	Version       string    `json:"version"`
+	Revision      string    `json:"revision,omitempty"`
+	GoVersion     string    `json:"goVersion"`
+	StartedAt     time.Time `json:"startedAt"`
+	UptimeSeconds int64     `json:"uptimeSeconds"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (s BuildStatus) MarshalJSON() ([]byte, error) {
+	type fields BuildStatus
+	return json.Marshal(struct {
+		fields
+		StartedAt jsontime.Time `json:"startedAt"`
+	}{
+		fields(s),
+		jsontime.New(s.StartedAt, s.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (s BuildStatus) WithTimeFormat(f jsontime.Format) any {
+	s.timeFormat = f
+	return s
+}
+
+// RequestStatus counts responses over the last WindowSeconds.
+type RequestStatus struct {
+	WindowSeconds int `json:"windowSeconds"`
+	Total         int `json:"total"`
+	ClientErrors  int `json:"clientErrors"`
+	ServerErrors  int `json:"serverErrors"`
+
+	// ErrorRate is ServerErrors / Total, 0 without requests.
+	ErrorRate float64 `json:"errorRate"`
+}
+
+// CacheStatus counts lookups of an in-process cache since startup.
+type CacheStatus struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+
+	// HitRate is Hits / (Hits + Misses), 0 without lookups.
+	HitRate float64 `json:"hitRate"`
+
+	// Entries is the number of cached items, when known.
+	Entries *int `json:"entries,omitempty"`
+}
+
+// AuthStatus describes the ID token verification keys.
+type AuthStatus struct {
+	// JWKSFetchedAt is when the signing keys were last fetched; omitted before the first fetch.
+	JWKSFetchedAt *time.Time `json:"jwksFetchedAt,omitempty"`
+
+	// JWKSAgeSeconds is the age of the keys; omitted before the first fetch.
+	JWKSAgeSeconds *int64 `json:"jwksAgeSeconds,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (s AuthStatus) MarshalJSON() ([]byte, error) {
+	type fields AuthStatus
+	return json.Marshal(struct {
+		fields
+		JWKSFetchedAt *jsontime.Time `json:"jwksFetchedAt,omitempty"`
+	}{
+		fields(s),
+		jsontime.NewPtr(s.JWKSFetchedAt, s.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (s AuthStatus) WithTimeFormat(f jsontime.Format) any {
+	s.timeFormat = f
+	return s
+}
+
+// FaultStatus describes the injected faults and counts them since startup.
+type FaultStatus struct {
+	LatencyMaxMs            int64   `json:"latencyMaxMs"`
+	LatencyPercent          float64 `json:"latencyPercent"`
+	FirestoreErrorPercent   float64 `json:"firestoreErrorPercent"`
+	DelayedRequests         int64   `json:"delayedRequests"`
+	FailedFirestoreRequests int64   `json:"failedFirestoreRequests"`
+}
+
+// NewCacheStatus returns a CacheStatus with HitRate computed.
+func NewCacheStatus(hits, misses int64) CacheStatus {
+	st := CacheStatus{Hits: hits, Misses: misses}
+	if hits+misses > 0 {
+		st.HitRate = float64(hits) / float64(hits+misses)
+	}
+	return st
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+const (
+	// MaxAPIKeys is the maximum number of API keys of a user.
+	MaxAPIKeys = 10
+
+	// MaxAPIKeyNameLength is the maximum length of APIKey.Name in characters.
+	MaxAPIKeyNameLength = 50
+)
+
+// APIKey is a personal access token for scripts and integrations, as defined in
+// data-models.md. Stored in users/{userID}/api_keys/{ID}; requests authenticate with the key in
+// the X-Api-Key header. Only the key's hash is stored.
+type APIKey struct {
+	// ID is the Firestore document ID.
+	ID string `firestore:"-" json:"id"`
+
+	// UserID is the auth user ID of the owner. Not sent in API.
+	UserID string `firestore:"UserID" json:"-"`
+
+	// Name describes what the key is used for.
+	Name string `firestore:"Name" json:"name"`
+
+	// Prefix is the start of the key, shown to tell keys apart.
+	Prefix string `firestore:"Prefix" json:"prefix"`
+
+	// KeyHash is the hex SHA-256 hash of the key. Not sent in API.
+	KeyHash string `firestore:"KeyHash" json:"-"`
+
+	// CreatedAt is when the key was created.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// LastUsedAt is when the key last authenticated a request, updated at most hourly; nil
+	// when never used.
+	LastUsedAt *time.Time `firestore:"LastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// apiKeyFields is APIKey without its methods, for marshaling.
+type apiKeyFields APIKey
+
+// apiKeyJSON is APIKey as written in JSON, with the timestamps in its time format.
+type apiKeyJSON struct {
+	apiKeyFields
+	CreatedAt  jsontime.Time  `json:"createdAt"`
+	LastUsedAt *jsontime.Time `json:"lastUsedAt,omitempty"`
+}
+
+func (k APIKey) toJSON() apiKeyJSON {
+	return apiKeyJSON{
+		apiKeyFields: apiKeyFields(k),
+		CreatedAt:    jsontime.New(k.CreatedAt, k.timeFormat),
+		LastUsedAt:   jsontime.NewPtr(k.LastUsedAt, k.timeFormat),
+	}
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (k APIKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.toJSON())
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (k APIKey) WithTimeFormat(f jsontime.Format) any {
+	k.timeFormat = f
+	return k
+}
+
+// APIKeysResponse is the response for GET /me/api-keys.
+type APIKeysResponse struct {
+	APIKeys []APIKey `json:"apiKeys"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r APIKeysResponse) MarshalJSON() ([]byte, error) {
+	type fields APIKeysResponse
+	r.APIKeys = emptyIfNil(r.APIKeys)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r APIKeysResponse) WithTimeFormat(f jsontime.Format) any {
+	r.APIKeys = jsontime.SetAll(r.APIKeys, f)
+	return r
+}
+
+// CreatedAPIKeyResponse is the response for POST /me/api-keys; the only time Key is returned.
+type CreatedAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// MarshalJSON implements json.Marshaler. It is needed because the promoted
+// APIKey.MarshalJSON would leave out Key.
+func (r CreatedAPIKeyResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		apiKeyJSON
+		Key string `json:"key"`
+	}{r.APIKey.toJSON(), r.Key})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r CreatedAPIKeyResponse) WithTimeFormat(f jsontime.Format) any {
+	r.APIKey = jsontime.Set(r.APIKey, f)
+	return r
+}
+
+// ValidateAPIKeyName returns an error unless name is non-empty and at most MaxAPIKeyNameLength
+// characters.
+func ValidateAPIKeyName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len([]rune(name)) > MaxAPIKeyNameLength {
+		return fmt.Errorf("name must be at most %d characters", MaxAPIKeyNameLength)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Audit event types (AuditEvent.Type).
+const (
+	AuditLogin                    = "login"
+	AuditTokenRejected            = "token_rejected"
+	AuditShareTokenRotated        = "share_token_rotated"
+	AuditAccountDeletionScheduled = "account_deletion_scheduled"
+	AuditAccountDeletionCancelled = "account_deletion_cancelled"
+	AuditSessionsRevoked          = "sessions_revoked"
+	AuditAPIKeyCreated            = "api_key_created"
+	AuditAPIKeyRevoked            = "api_key_revoked"
+	AuditAccountsMerged           = "accounts_merged"
+)
+
+const (
+	// AuditRetention is how long audit events are kept before TTL deletes them.
+	AuditRetention = 365 * 24 * time.Hour
+
+	// DefaultAuditLimit is the number of events GET /me/audit returns without a limit.
+	DefaultAuditLimit = 50
+
+	// MaxAuditLimit is the largest limit of GET /me/audit.
+	MaxAuditLimit = 200
+)
+
+// AuditEvent is a security-relevant event on a user's account, as defined in data-models.md.
+// Stored in users/{userID}/audit; deleted by TTL after AuditRetention and with the account.
+type AuditEvent struct {
+	// ID is the Firestore document ID.
+	ID string `firestore:"-" json:"id"`
+
+	// Type is one of the Audit* event types.
+	Type string `firestore:"Type" json:"type"`
+
+	// Time is when the event happened.
+	Time time.Time `firestore:"Time" json:"time"`
+
+	// ExpiresAt is when TTL deletes the event. Not sent in API.
+	ExpiresAt time.Time `firestore:"ExpiresAt" json:"-"`
+
+	// IP and UserAgent identify the client that made the request.
+	IP        string `firestore:"IP,omitempty" json:"ip,omitempty"`
+	UserAgent string `firestore:"UserAgent,omitempty" json:"userAgent,omitempty"`
+
+	// Detail is optional event-specific context, e.g. the ID of a created API key or why a
+	// token was rejected.
+	Detail string `firestore:"Detail,omitempty" json:"detail,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (e AuditEvent) MarshalJSON() ([]byte, error) {
+	type fields AuditEvent
+	return json.Marshal(struct {
+		fields
+		Time jsontime.Time `json:"time"`
+	}{
+		fields(e),
+		jsontime.New(e.Time, e.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (e AuditEvent) WithTimeFormat(f jsontime.Format) any {
+	e.timeFormat = f
+	return e
+}
+
+// AuditEventsResponse is the response for GET /me/audit and GET /admin/users/:userId/audit.
+type AuditEventsResponse struct {
+	Events []AuditEvent `json:"events"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r AuditEventsResponse) MarshalJSON() ([]byte, error) {
+	type fields AuditEventsResponse
+	r.Events = emptyIfNil(r.Events)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r AuditEventsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Events = jsontime.SetAll(r.Events, f)
+	return r
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Backfill job statuses for BackfillJob.Status.
+const (
+	BackfillPending   = "pending"
+	BackfillRunning   = "running"
+	BackfillPaused    = "paused"
+	BackfillCompleted = "completed"
+)
+
+// BackfillJob is the checkpoint of a backfill job that walks all users and rebuilds derived
+// data, stored in backfill_jobs/{Name} (see data-models.md). Returned by the admin backfill
+// endpoints.
+type BackfillJob struct {
+	// Name is the job name and Firestore document ID.
+	Name string `firestore:"-" json:"name"`
+
+	// Status is one of BackfillPending, BackfillRunning, BackfillPaused or BackfillCompleted.
+	Status string `firestore:"Status" json:"status"`
+
+	// Cursor is the ID of the last user whose batch was checkpointed; the job resumes after it.
+	Cursor string `firestore:"Cursor" json:"cursor"`
+
+	// Processed is the number of users rebuilt since the job was (re)started.
+	Processed int64 `firestore:"Processed" json:"processed"`
+
+	// Failed is the number of users whose rebuild failed; they are skipped, not retried.
+	Failed int64 `firestore:"Failed" json:"failed"`
+
+	// LastError is the most recent rebuild error, if any.
+	LastError string `firestore:"LastError,omitempty" json:"lastError,omitempty"`
+
+	// StartedAt is when the job was (re)started from the beginning.
+	StartedAt time.Time `firestore:"StartedAt" json:"startedAt"`
+
+	// UpdatedAt is the time of the last checkpoint. A running job whose UpdatedAt is older
+	// than a few minutes was interrupted (e.g. the instance stopped) and may be resumed.
+	UpdatedAt time.Time `firestore:"UpdatedAt" json:"updatedAt"`
+
+	// CompletedAt is set when the job has walked all users.
+	CompletedAt *time.Time `firestore:"CompletedAt,omitempty" json:"completedAt,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (j BackfillJob) MarshalJSON() ([]byte, error) {
+	type fields BackfillJob
+	return json.Marshal(struct {
+		fields
+		StartedAt   jsontime.Time  `json:"startedAt"`
+		UpdatedAt   jsontime.Time  `json:"updatedAt"`
+		CompletedAt *jsontime.Time `json:"completedAt,omitempty"`
+	}{
+		fields(j),
+		jsontime.New(j.StartedAt, j.timeFormat),
+		jsontime.New(j.UpdatedAt, j.timeFormat),
+		jsontime.NewPtr(j.CompletedAt, j.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (j BackfillJob) WithTimeFormat(f jsontime.Format) any {
+	j.timeFormat = f
+	return j
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Backup status values.
+const (
+	BackupRunning   = "running"
+	BackupCompleted = "completed"
+	BackupFailed    = "failed"
+
+	// BackupUnknown is the status of a backup whose export operation Firestore no longer
+	// reports and that did not write its export metadata.
+	BackupUnknown = "unknown"
+)
+
+// Backup triggers.
+const (
+	BackupTriggerSchedule = "schedule"
+	BackupTriggerAdmin    = "admin"
+)
+
+// Backup is a Firestore export of the users collection tree to the backup bucket
+// (GET /admin/backup). Its record is stored next to the export as backup.json.
+type Backup struct {
+	// ID is the UTC start time (e.g. 20261016T030000Z), also the export's folder under backups/.
+	ID      string `json:"id"`
+	URI     string `json:"uri"`
+	Trigger string `json:"trigger"`
+
+	// Operation is the name of the Firestore export operation; empty until it has started.
+	Operation string `json:"operation,omitempty"`
+
+	// Status is not stored; it is read from the export operation.
+	Status      string     `json:"status"`
+	StartedAt   time.Time  `json:"startedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// Documents is the number of documents exported so far.
+	Documents int64  `json:"documents"`
+	Error     string `json:"error,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (b Backup) MarshalJSON() ([]byte, error) {
+	type fields Backup
+	return json.Marshal(struct {
+		fields
+		StartedAt   jsontime.Time  `json:"startedAt"`
+		CompletedAt *jsontime.Time `json:"completedAt,omitempty"`
+	}{
+		fields(b),
+		jsontime.New(b.StartedAt, b.timeFormat),
+		jsontime.NewPtr(b.CompletedAt, b.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (b Backup) WithTimeFormat(f jsontime.Format) any {
+	b.timeFormat = f
+	return b
+}
+
+// BackupsResponse is the response for GET /admin/backup, newest first.
+type BackupsResponse struct {
+	Backups []Backup `json:"backups"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r BackupsResponse) MarshalJSON() ([]byte, error) {
+	type fields BackupsResponse
+	r.Backups = emptyIfNil(r.Backups)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r BackupsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Backups = jsontime.SetAll(r.Backups, f)
+	return r
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// BlockedUser is a user the owner has blocked, as defined in data-models.md. Stored in
+// users/{userID}/blocked/{UserID}, keyed by the blocked user's ID so the block outlives share
+// token changes.
+type BlockedUser struct {
+	// UserID is the blocked user's auth user ID and the Firestore document ID. Not sent in API.
+	UserID string `firestore:"-" json:"-"`
+
+	// ShareToken is the blocked user's ShareToken when they were blocked.
+	ShareToken string `firestore:"ShareToken" json:"shareToken"`
+
+	// Name and ImageURL are copied from the blocked user so the block list can be shown.
+	Name     string `firestore:"Name" json:"name"`
+	ImageURL string `firestore:"ImageURL,omitempty" json:"imageUrl,omitempty"`
+
+	// CreatedAt is when the user was blocked.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (b BlockedUser) MarshalJSON() ([]byte, error) {
+	type fields BlockedUser
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+	}{
+		fields(b),
+		jsontime.New(b.CreatedAt, b.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (b BlockedUser) WithTimeFormat(f jsontime.Format) any {
+	b.timeFormat = f
+	return b
+}
+
+// BlockedUsersResponse is the response for GET /blocked.
+type BlockedUsersResponse struct {
+	Blocked []BlockedUser `json:"blocked"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r BlockedUsersResponse) MarshalJSON() ([]byte, error) {
+	type fields BlockedUsersResponse
+	r.Blocked = emptyIfNil(r.Blocked)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r BlockedUsersResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Blocked = jsontime.SetAll(r.Blocked, f)
+	return r
+}
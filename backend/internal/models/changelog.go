@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// ChangelogEntry describes one API change, as served by GET /api/changelog.
+type ChangelogEntry struct {
+	// Version is the API version that introduced the change (semantic versioning).
+	Version string `json:"version"`
+
+	// Date is the release date (YYYY-MM-DD).
+	Date string `json:"date"`
+
+	// ChangeType is one of added, changed, deprecated, removed, fixed.
+	ChangeType string `json:"changeType"`
+
+	// Endpoints lists the affected routes as "METHOD /path"; empty for cross-cutting changes.
+	Endpoints []string `json:"endpoints"`
+
+	// Description is a short human-readable summary.
+	Description string `json:"description"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (c ChangelogEntry) MarshalJSON() ([]byte, error) {
+	type fields ChangelogEntry
+	c.Endpoints = emptyIfNil(c.Endpoints)
+	return json.Marshal(fields(c))
+}
+
+// ChangelogResponse is the response for GET /api/changelog.
+type ChangelogResponse struct {
+	APIVersion string           `json:"apiVersion"`
+	AppVersion string           `json:"appVersion"`
+	Changes    []ChangelogEntry `json:"changes"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r ChangelogResponse) MarshalJSON() ([]byte, error) {
+	type fields ChangelogResponse
+	r.Changes = emptyIfNil(r.Changes)
+	return json.Marshal(fields(r))
+}
@@ -1,80 +1,95 @@
 package models
 
+import (
+	"strings"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
 // Country represents reference data for a country, as defined in data-models.md.
 // Firestore ID is stored in ID but must not be sent over the REST interface.
 type Country struct {
 	// CountryCode is a 2-letter ISO 3166-1 alpha-2 code. Mandatory.
 	CountryCode string `firestore:"country_code" json:"countryCode"`
 
+	// Alpha3 is the ISO 3166-1 alpha-3 code (e.g. "FIN"). Derived from CountryCode.
+	Alpha3 string `firestore:"-" json:"alpha3,omitempty"`
+
 	// Name is the full name of the country.
 	Name string `firestore:"name" json:"name"`
 
 	// RegionCode is a 2-letter ISO 3166-1 continent code.
 	RegionCode string `firestore:"region_code" json:"regionCode"`
 
+	// Type is CountryTypeSovereign for entries in the default list, otherwise
+	// CountryTypeTerritory or CountryTypeDisputed.
+	Type string `firestore:"type" json:"type"`
+
+	// Deprecated is set by a CountryOverride. Deprecated countries are not in the lists but
+	// still resolve by code for existing visits.
+	Deprecated bool `firestore:"-" json:"deprecated,omitempty"`
+
+	// FlagEmoji is the flag as a pair of Unicode regional indicator symbols. Derived from CountryCode.
+	FlagEmoji string `firestore:"-" json:"flagEmoji"`
+
+	// FlagImagePath is the path of the flag thumbnail (JPEG) in the embedded static FS.
+	FlagImagePath string `firestore:"-" json:"flagImagePath"`
+
+	// M49Code is the UN M49 numeric code (e.g. "246"). Only set in GET /countries?list=un.
+	M49Code string `firestore:"-" json:"m49Code,omitempty"`
+
+	// M49Region is the UN M49 region name (e.g. "Europe"). Only set in GET /countries?list=un.
+	M49Region string `firestore:"-" json:"m49Region,omitempty"`
+
+	// M49SubRegion is the UN M49 sub-region name (e.g. "Northern Europe"). Only set in
+	// GET /countries?list=un.
+	M49SubRegion string `firestore:"-" json:"m49SubRegion,omitempty"`
+
 	// ID is the Firestore document ID. Not sent over REST.
 	ID string `firestore:"-" json:"-"`
 }
 
-// CountryResponse is the response wrapper for GET /countries.
+// Country types (Country.Type).
+const (
+	CountryTypeSovereign = "sovereign"
+	CountryTypeTerritory = "territory"
+	CountryTypeDisputed  = "disputed"
+)
+
+// CountryResponse is the response wrapper for GET /countries. List is the selected scheme;
+// Destinations is set instead of Countries for CountryListTCC. Version identifies the country
+// data (see data.Version) and changes when a CountryOverride is applied. Historic is only set
+// for ?include=historic.
 type CountryResponse struct {
-	Countries []Country `json:"countries"`
+	List         string            `json:"list"`
+	Version      string            `json:"version"`
+	Countries    []Country         `json:"countries,omitempty"`
+	Destinations []Destination     `json:"destinations,omitempty"`
+	Historic     []HistoricCountry `json:"historic,omitempty"`
 }
 
-// ValidateCountryCode checks if a country code is a valid ISO 3166-1 alpha-2 code.
-func ValidateCountryCode(code string) bool {
-	if len(code) != 2 {
-		return false
-	}
-
-	// ISO 3166-1 alpha-2 codes are exactly 2 uppercase letters
-	for _, r := range code {
-		if r < 'A' || r > 'Z' {
-			return false
-		}
-	}
+// WithTimeFormat implements jsontime.Formatter.
+func (r CountryResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Historic = jsontime.SetAll(r.Historic, f)
+	return r
+}
 
-	// Check against a comprehensive list of valid ISO 3166-1 alpha-2 codes
-	validCodes := map[string]bool{
-		"AD": true, "AE": true, "AF": true, "AG": true, "AI": true, "AL": true, "AM": true,
-		"AO": true, "AQ": true, "AR": true, "AS": true, "AT": true, "AU": true, "AW": true,
-		"AX": true, "AZ": true, "BA": true, "BB": true, "BD": true, "BE": true, "BF": true,
-		"BG": true, "BH": true, "BI": true, "BJ": true, "BL": true, "BM": true, "BN": true,
-		"BO": true, "BQ": true, "BR": true, "BS": true, "BT": true, "BV": true, "BW": true,
-		"BY": true, "BZ": true, "CA": true, "CC": true, "CD": true, "CF": true, "CG": true,
-		"CH": true, "CI": true, "CK": true, "CL": true, "CM": true, "CN": true, "CO": true,
-		"CR": true, "CU": true, "CV": true, "CW": true, "CX": true, "CY": true, "CZ": true,
-		"DE": true, "DJ": true, "DK": true, "DM": true, "DO": true, "DZ": true, "EC": true,
-		"EE": true, "EG": true, "EH": true, "ER": true, "ES": true, "ET": true, "FI": true,
-		"FJ": true, "FK": true, "FM": true, "FO": true, "FR": true, "GA": true, "GB": true,
-		"GD": true, "GE": true, "GF": true, "GG": true, "GH": true, "GI": true, "GL": true,
-		"GM": true, "GN": true, "GP": true, "GQ": true, "GR": true, "GS": true, "GT": true,
-		"GU": true, "GW": true, "GY": true, "HK": true, "HM": true, "HN": true, "HR": true,
-		"HT": true, "HU": true, "ID": true, "IE": true, "IL": true, "IM": true, "IN": true,
-		"IO": true, "IQ": true, "IR": true, "IS": true, "IT": true, "JE": true, "JM": true,
-		"JO": true, "JP": true, "KE": true, "KG": true, "KH": true, "KI": true, "KM": true,
-		"KN": true, "KP": true, "KR": true, "KW": true, "KY": true, "KZ": true, "LA": true,
-		"LB": true, "LC": true, "LI": true, "LK": true, "LR": true, "LS": true, "LT": true,
-		"LU": true, "LV": true, "LY": true, "MA": true, "MC": true, "MD": true, "ME": true,
-		"MF": true, "MG": true, "MH": true, "MK": true, "ML": true, "MM": true, "MN": true,
-		"MO": true, "MP": true, "MQ": true, "MR": true, "MS": true, "MT": true, "MU": true,
-		"MV": true, "MW": true, "MX": true, "MY": true, "MZ": true, "NA": true, "NC": true,
-		"NE": true, "NF": true, "NG": true, "NI": true, "NL": true, "NO": true, "NP": true,
-		"NR": true, "NU": true, "NZ": true, "OM": true, "PA": true, "PE": true, "PF": true,
-		"PG": true, "PH": true, "PK": true, "PL": true, "PM": true, "PN": true, "PR": true,
-		"PS": true, "PT": true, "PW": true, "PY": true, "QA": true, "RE": true, "RO": true,
-		"RS": true, "RU": true, "RW": true, "SA": true, "SB": true, "SC": true, "SD": true,
-		"SE": true, "SG": true, "SH": true, "SI": true, "SJ": true, "SK": true, "SL": true,
-		"SM": true, "SN": true, "SO": true, "SR": true, "SS": true, "ST": true, "SV": true,
-		"SX": true, "SY": true, "SZ": true, "TC": true, "TD": true, "TF": true, "TG": true,
-		"TH": true, "TJ": true, "TK": true, "TL": true, "TM": true, "TN": true, "TO": true,
-		"TR": true, "TT": true, "TV": true, "TW": true, "TZ": true, "UA": true, "UG": true,
-		"UM": true, "US": true, "UY": true, "UZ": true, "VA": true, "VC": true, "VE": true,
-		"VG": true, "VI": true, "VN": true, "VU": true, "WF": true, "WS": true, "YE": true,
-		"YT": true, "ZA": true, "ZM": true, "ZW": true,
+// FlagEmoji returns the flag emoji for an alpha-2 code, or "" if code is not two letters A-Z.
+func FlagEmoji(code string) string {
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return ""
 	}
+	const regionalIndicatorA = 0x1F1E6
+	return string([]rune{
+		rune(regionalIndicatorA + int(code[0]-'A')),
+		rune(regionalIndicatorA + int(code[1]-'A')),
+	})
+}
 
-	return validCodes[code]
+// FlagImagePath returns the static asset path of the flag thumbnail for an alpha-2 code.
+// Images are downloaded by the frontend's download-flags script into /assets/images.
+func FlagImagePath(code string) string {
+	return "/assets/images/" + strings.ToLower(code) + ".jpg"
 }
 
 // ValidateRegionCode checks if a region code is a valid ISO 3166-1 continent code.
@@ -0,0 +1,59 @@
+package models
+
+import "strings"
+
+// countryCodeFormats is one row of countryCodeTable (country_codes_gen.go, generated from
+// data/countries.csv): the ISO 3166-1 codes of a country or area.
+type countryCodeFormats struct {
+	alpha2 string
+	alpha3 string
+}
+
+// byAlpha2 and byAlpha3 index countryCodeTable.
+var (
+	byAlpha2 map[string]countryCodeFormats
+	byAlpha3 map[string]countryCodeFormats
+)
+
+func init() {
+	byAlpha2 = make(map[string]countryCodeFormats, len(countryCodeTable))
+	byAlpha3 = make(map[string]countryCodeFormats, len(countryCodeTable))
+	for _, f := range countryCodeTable {
+		byAlpha2[f.alpha2] = f
+		byAlpha3[f.alpha3] = f
+	}
+}
+
+// ValidateCountryCode checks if a country code is a valid ISO 3166-1 alpha-2 code.
+func ValidateCountryCode(code string) bool {
+	f, ok := byAlpha2[code]
+	return ok && !isUserAssigned(f.alpha2)
+}
+
+// ValidateAlpha3Code checks if a country code is a valid ISO 3166-1 alpha-3 code.
+func ValidateAlpha3Code(code string) bool {
+	f, ok := byAlpha3[code]
+	return ok && !isUserAssigned(f.alpha2)
+}
+
+// Alpha3 returns the alpha-3 code for an alpha-2 code, or "" when code is unknown.
+func Alpha3(alpha2 string) string {
+	return byAlpha2[alpha2].alpha3
+}
+
+// NormalizeCountryCode trims and uppercases code and converts a known alpha-3 code to its
+// alpha-2 form, the format visits are stored in. Other input is returned trimmed and uppercased
+// for the caller to validate.
+func NormalizeCountryCode(code string) string {
+	n := strings.ToUpper(strings.TrimSpace(code))
+	if f, ok := byAlpha3[n]; ok {
+		return f.alpha2
+	}
+	return n
+}
+
+// isUserAssigned reports whether alpha2 is in the ISO 3166-1 user-assigned range XA-XZ (e.g.
+// Kosovo's widely used XK), which converts between formats but is not an official code.
+func isUserAssigned(alpha2 string) bool {
+	return alpha2 >= "XA" && alpha2 <= "XZ"
+}
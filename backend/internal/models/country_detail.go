@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// CountryMetadata is bundled reference data shown on a country's info card.
+type CountryMetadata struct {
+	// Capital is the capital city in English.
+	Capital string `json:"capital,omitempty"`
+
+	// Population is a recent estimate of the number of inhabitants.
+	Population int64 `json:"population,omitempty"`
+
+	// Currencies are ISO 4217 codes of the legal tender, most used first.
+	Currencies []string `json:"currencies,omitempty"`
+
+	// Languages are ISO 639 codes of the official or national languages.
+	Languages []string `json:"languages,omitempty"`
+}
+
+// CountryDetail is the response for GET /countries/:code: the Country fields and, for
+// sovereign countries, its CountryMetadata.
+type CountryDetail struct {
+	Country
+	CountryMetadata
+
+	// Neighbors are the alpha-2 codes of the countries and territories sharing a land border.
+	Neighbors []string `json:"neighbors"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (c CountryDetail) MarshalJSON() ([]byte, error) {
+	type fields CountryDetail
+	c.Neighbors = emptyIfNil(c.Neighbors)
+	return json.Marshal(fields(c))
+}
@@ -0,0 +1,30 @@
+package models
+
+// Country list schemes selectable with GET /countries?list=.
+const (
+	// CountryListISO is the default: sovereign countries by ISO 3166-1 alpha-2 code.
+	CountryListISO = "iso"
+	// CountryListUN is the UN M49 standard of countries and areas, with M49 regions.
+	CountryListUN = "un"
+	// CountryListTCC is the Travelers' Century Club destination list.
+	CountryListTCC = "tcc"
+)
+
+// CountryLists lists the accepted list values in documentation order.
+var CountryLists = []string{CountryListISO, CountryListUN, CountryListTCC}
+
+// Destination is one entry of the Travelers' Century Club list. Many destinations are parts of
+// a country (islands, regions or exclaves) and share its CountryCode.
+type Destination struct {
+	// Code is a stable lowercase identifier derived from Name (e.g. "canary-islands").
+	Code string `json:"code"`
+
+	// Name is the TCC destination name.
+	Name string `json:"name"`
+
+	// Region is the TCC region (e.g. "Indian Ocean").
+	Region string `json:"region"`
+
+	// CountryCode is the alpha-2 code of the country or territory the destination belongs to.
+	CountryCode string `json:"countryCode"`
+}
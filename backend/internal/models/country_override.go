@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// CountryOverride changes one entry of the bundled country lists without a redeploy, stored in
+// countries_overrides/{CountryCode} (see data-models.md). Overrides are merged over the bundled
+// lists at startup and on every refresh: an override of a bundled code renames it, moves it
+// to another region or type, or deprecates it; an override of any other code adds a country.
+type CountryOverride struct {
+	// CountryCode is the ISO 3166-1 alpha-2 code and Firestore document ID.
+	CountryCode string `firestore:"-" json:"countryCode"`
+
+	// Name replaces the bundled name; the bundled name stays resolvable as an alias. Required
+	// when adding a country.
+	Name string `firestore:"Name,omitempty" json:"name,omitempty"`
+
+	// RegionCode replaces the bundled continent code. Required when adding a country.
+	RegionCode string `firestore:"RegionCode,omitempty" json:"regionCode,omitempty"`
+
+	// Type replaces the bundled type (CountryTypeSovereign, CountryTypeTerritory or
+	// CountryTypeDisputed). Added countries default to CountryTypeSovereign.
+	Type string `firestore:"Type,omitempty" json:"type,omitempty"`
+
+	// Deprecated removes the country from the lists: it can no longer be added as a visit,
+	// but existing visits still resolve.
+	Deprecated bool `firestore:"Deprecated" json:"deprecated"`
+
+	// UpdatedAt is set by the server when the override is saved.
+	UpdatedAt time.Time `firestore:"UpdatedAt" json:"updatedAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (o CountryOverride) MarshalJSON() ([]byte, error) {
+	type fields CountryOverride
+	return json.Marshal(struct {
+		fields
+		UpdatedAt jsontime.Time `json:"updatedAt"`
+	}{
+		fields(o),
+		jsontime.New(o.UpdatedAt, o.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (o CountryOverride) WithTimeFormat(f jsontime.Format) any {
+	o.timeFormat = f
+	return o
+}
+
+// Validate checks the fields of an override received over the API and returns messages keyed
+// by API field name (see ValidationErrors), or nil when valid.
+func (o *CountryOverride) Validate() map[string]string {
+	fields := make(map[string]string)
+	if FlagEmoji(o.CountryCode) == "" {
+		fields["countryCode"] = "must be an ISO 3166-1 alpha-2 code"
+	}
+	if o.RegionCode != "" && !ValidateRegionCode(o.RegionCode) {
+		fields["regionCode"] = "must be one of AF, AN, AS, EU, NA, OC, SA"
+	}
+	switch o.Type {
+	case "", CountryTypeSovereign, CountryTypeTerritory, CountryTypeDisputed:
+	default:
+		fields["type"] = "must be one of sovereign, territory, disputed"
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -8,14 +9,33 @@ import (
 	"strings"
 	"time"
 	"unicode/utf8"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
 )
 
 // MaxTagsPerVisit is the maximum number of tags allowed on a CountryVisit (see api.md).
 const MaxTagsPerVisit = 10
 
+// MaxCompanionsPerVisit is the maximum number of companions allowed on a CountryVisit.
+const MaxCompanionsPerVisit = 20
+
 // MaxNotesLength is the maximum number of Unicode characters allowed in Notes.
 const MaxNotesLength = 1000
 
+// Visit types for CountryVisit.VisitType.
+const (
+	VisitTypeLeisure   = "leisure"
+	VisitTypeBusiness  = "business"
+	VisitTypeTransit   = "transit"
+	VisitTypeStudy     = "study"
+	VisitTypeResidence = "residence"
+)
+
+// VisitTypes lists the accepted VisitType values in documentation order.
+var VisitTypes = []string{
+	VisitTypeLeisure, VisitTypeBusiness, VisitTypeTransit, VisitTypeStudy, VisitTypeResidence,
+}
+
 var tagTokenPattern = regexp.MustCompile(`^[a-z]{2,}$`)
 
 // CountryVisit represents a visit to a country by a user, as defined in data-models.md.
@@ -36,11 +56,84 @@ type CountryVisit struct {
 	// Tags are optional lowercase [a-z] strings (min length 2); stored in Firestore as Tags.
 	Tags []string `firestore:"Tags" json:"tags"`
 
+	// IsPrivate hides the visit from share views and friend-facing endpoints. Stored only when true.
+	IsPrivate bool `firestore:"IsPrivate" json:"isPrivate"`
+
+	// SubdivisionCode is an optional ISO 3166-2 code (e.g. "US-CA") of a subdivision of CountryCode
+	// from the bundled subdivisions dataset. Stored only when non-empty.
+	SubdivisionCode string `firestore:"SubdivisionCode" json:"subdivisionCode,omitempty"`
+
+	// DestinationCode is an optional Travelers' Century Club destination code (e.g.
+	// "canary-islands") belonging to CountryCode. Stored only when non-empty.
+	DestinationCode string `firestore:"DestinationCode" json:"destinationCode,omitempty"`
+
+	// VisitType is one of VisitTypes. Optional; stored only when non-empty.
+	VisitType string `firestore:"VisitType" json:"visitType,omitempty"`
+
+	// Location is an optional point (e.g. the city visited) used by GET /visits/clusters.
+	// Stored only when set.
+	Location *Location `firestore:"Location" json:"location,omitempty"`
+
+	// Companions are the ShareTokens of friends the visit was made with; each must be in the
+	// owner's friends list when added. Stored only when non-empty.
+	Companions []string `firestore:"Companions" json:"companions,omitempty"`
+
+	// CompanionFriends resolves Companions against the owner's current friends list for display.
+	// Not stored; companions no longer in the friends list are left out.
+	CompanionFriends []Friend `firestore:"-" json:"companionFriends,omitempty"`
+
+	// Overlaps link the visit to mutual friends' visits of the same trip (see VisitOverlap). Not
+	// stored in the visit document; set for the owner only.
+	Overlaps []VisitOverlap `firestore:"-" json:"overlaps,omitempty"`
+
+	// Proofs are files attached as proof of the visit (see VisitProof). Stored only when
+	// non-empty; never exposed in share views.
+	Proofs []VisitProof `firestore:"Proofs" json:"proofs,omitempty"`
+
+	// Verified is true when the visit has at least one proof. Not stored; set when loading.
+	Verified bool `firestore:"-" json:"verified"`
+
+	// SuccessorCodes are the current countries drawn on maps in place of a historic
+	// CountryCode (see HistoricCountry). Not stored; empty for current countries.
+	SuccessorCodes []string `firestore:"-" json:"successorCodes,omitempty"`
+
+	// UpdatedAt is when the visit was last written, in whole seconds (see NextVisitUpdatedAt).
+	// Checked against If-Unmodified-Since by PATCH /visits/:id. Missing for visits not written
+	// since it was introduced.
+	UpdatedAt *time.Time `firestore:"UpdatedAt,omitempty" json:"updatedAt,omitempty"`
+
 	// UserID is the ID of the user who created this object. Set when loading; not stored in Firestore (user implied by path).
 	UserID string `firestore:"-" json:"userId"`
 
 	// ID is the Firestore document ID. Exposed in API for DELETE /visits/:id (see api.md).
 	ID string `firestore:"-" json:"id"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat and nil collections as empty ones.
+func (v CountryVisit) MarshalJSON() ([]byte, error) {
+	type fields CountryVisit
+	v.Tags = emptyIfNil(v.Tags)
+	return json.Marshal(struct {
+		fields
+		VisitedTime jsontime.Time  `json:"visitedTime"`
+		UpdatedAt   *jsontime.Time `json:"updatedAt,omitempty"`
+	}{
+		fields(v),
+		jsontime.New(v.VisitedTime, v.timeFormat),
+		jsontime.NewPtr(v.UpdatedAt, v.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (v CountryVisit) WithTimeFormat(f jsontime.Format) any {
+	v.timeFormat = f
+	v.Overlaps = jsontime.SetAll(v.Overlaps, f)
+	v.Proofs = jsontime.SetAll(v.Proofs, f)
+	return v
 }
 
 // ValidateMediaURL returns true if urlStr is empty or a well-formed URL usable as a hyperlink (http/https, non-empty host).
@@ -93,6 +186,32 @@ func ValidateTags(tags []string) error {
 	return nil
 }
 
+// MinVisitedTime is the earliest allowed VisitedTime (1900-01-01 UTC).
+var MinVisitedTime = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ValidateVisitedTime returns an error unless t is between 1900-01-01 and the end of the current day (UTC).
+func ValidateVisitedTime(t time.Time) error {
+	now := time.Now().UTC()
+	maxDate := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, time.UTC)
+	if t.Before(MinVisitedTime) || t.After(maxDate) {
+		return errors.New("visitedTime must be between 1900-01-01 and current date")
+	}
+	return nil
+}
+
+// ValidateVisitType returns an error unless t is empty or one of VisitTypes.
+func ValidateVisitType(t string) error {
+	if t == "" {
+		return nil
+	}
+	for _, v := range VisitTypes {
+		if t == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("visitType must be one of %s", strings.Join(VisitTypes, ", "))
+}
+
 // ValidateNotes returns an error if notes exceeds MaxNotesLength Unicode characters.
 func ValidateNotes(notes string) error {
 	if utf8.RuneCountInString(notes) > MaxNotesLength {
@@ -101,13 +220,47 @@ func ValidateNotes(notes string) error {
 	return nil
 }
 
+// NextVisitUpdatedAt returns the UpdatedAt of a visit written at now that was last written at
+// prev (nil for a new visit): now in whole seconds, but at least a second after prev, so that
+// If-Unmodified-Since, an HTTP date in whole seconds, tells every write apart.
+func NextVisitUpdatedAt(prev *time.Time, now time.Time) time.Time {
+	next := now.UTC().Truncate(time.Second)
+	if prev == nil {
+		return next
+	}
+	if last := prev.UTC().Truncate(time.Second); !next.After(last) {
+		next = last.Add(time.Second)
+	}
+	return next
+}
+
+// ModifiedSince reports whether the visit was written after t, compared in whole seconds.
+// A visit without UpdatedAt never was.
+func (v *CountryVisit) ModifiedSince(t time.Time) bool {
+	return v.UpdatedAt != nil && v.UpdatedAt.Truncate(time.Second).After(t)
+}
+
 // CountryVisitResponse is the response wrapper for GET /visits.
 type CountryVisitResponse struct {
 	Visits     []CountryVisit `json:"visits"`
 	ShareToken string         `json:"shareToken"`
 }
 
-// ShareProfileResponse is the response for GET /share/profile/:shareToken.
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r CountryVisitResponse) MarshalJSON() ([]byte, error) {
+	type fields CountryVisitResponse
+	r.Visits = emptyIfNil(r.Visits)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r CountryVisitResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Visits = jsontime.SetAll(r.Visits, f)
+	return r
+}
+
+// ShareProfileResponse is the response for GET /share/profile/:shareToken. Visits is empty and
+// CountryCodes omitted as the share link's Scope requires; see ShareLink.
 type ShareProfileResponse struct {
 	Visits            []CountryVisit `json:"visits"`
 	UserName          string         `json:"userName"`
@@ -115,7 +268,99 @@ type ShareProfileResponse struct {
 	HomeCountryCode   string         `json:"homeCountryCode,omitempty"`
 	InstagramUserName string         `json:"instagramUserName,omitempty"`
 	Description       string         `json:"description,omitempty"`
+
+	// VerifiedVisitCount is the number of Visits with Verified set.
+	VerifiedVisitCount int `json:"verifiedVisitCount"`
+
+	// Scope is ShareScopeFull for the ShareToken, otherwise the share link's scope.
+	Scope string `json:"scope"`
+
+	// VisitCount and CountriesCount are the numbers of shared visits and distinct countries.
+	VisitCount     int `json:"visitCount"`
+	CountriesCount int `json:"countriesCount"`
+
+	// CountryCodes are the distinct visited country codes, sorted. Omitted for
+	// ShareScopeSummary.
+	CountryCodes []string `json:"countryCodes,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r ShareProfileResponse) MarshalJSON() ([]byte, error) {
+	type fields ShareProfileResponse
+	r.Visits = emptyIfNil(r.Visits)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r ShareProfileResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Visits = jsontime.SetAll(r.Visits, f)
+	return r
+}
+
+// ImportSkipped describes an import record that was not stored.
+type ImportSkipped struct {
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+
+	// Country is the record's country as given in the export, for records whose country is
+	// unknown.
+	Country string `json:"country,omitempty"`
 }
 
+// ImportVisitsResponse is the response for POST /visits/import.
+type ImportVisitsResponse struct {
+	Visits  []CountryVisit  `json:"visits"`
+	Skipped []ImportSkipped `json:"skipped"`
+}
 
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r ImportVisitsResponse) MarshalJSON() ([]byte, error) {
+	type fields ImportVisitsResponse
+	r.Visits = emptyIfNil(r.Visits)
+	r.Skipped = emptyIfNil(r.Skipped)
+	return json.Marshal(fields(r))
+}
 
+// WithTimeFormat implements jsontime.Formatter.
+func (r ImportVisitsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Visits = jsontime.SetAll(r.Visits, f)
+	return r
+}
+
+// VisitSearchResult is a single ranked match from GET /visits/search.
+type VisitSearchResult struct {
+	Visit         CountryVisit `json:"visit"`
+	Score         int          `json:"score"`
+	MatchedFields []string     `json:"matchedFields"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (v VisitSearchResult) MarshalJSON() ([]byte, error) {
+	type fields VisitSearchResult
+	v.MatchedFields = emptyIfNil(v.MatchedFields)
+	return json.Marshal(fields(v))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r VisitSearchResult) WithTimeFormat(f jsontime.Format) any {
+	r.Visit = jsontime.Set(r.Visit, f)
+	return r
+}
+
+// VisitSearchResponse is the response for GET /visits/search.
+type VisitSearchResponse struct {
+	Results []VisitSearchResult `json:"results"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r VisitSearchResponse) MarshalJSON() ([]byte, error) {
+	type fields VisitSearchResponse
+	r.Results = emptyIfNil(r.Results)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r VisitSearchResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Results = jsontime.SetAll(r.Results, f)
+	return r
+}
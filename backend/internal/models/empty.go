@@ -0,0 +1,19 @@
+package models
+
+// emptyIfNil returns s, or an empty slice if s is nil, so that a response collection marshals
+// as [] rather than null.
+func emptyIfNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// emptyMapIfNil returns m, or an empty map if m is nil, so that it marshals as {} rather than
+// null.
+func emptyMapIfNil[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return map[K]V{}
+	}
+	return m
+}
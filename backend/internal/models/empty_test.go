@@ -0,0 +1,27 @@
+package models_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// TestNestedEmptyCollections checks that models inside a response write their own nil
+// collections as empty ones, in both time formats.
+func TestNestedEmptyCollections(t *testing.T) {
+	visit := models.CountryVisit{VisitedTime: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	resp := models.FeedResponse{Items: []models.FeedItem{{Visit: visit}}}
+	for _, f := range []jsontime.Format{jsontime.RFC3339, jsontime.Unix} {
+		body, err := json.Marshal(jsontime.Apply(resp, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), `"tags":[]`) || strings.Contains(string(body), "null") {
+			t.Errorf("%s: %s, want tags [] and no null", f, body)
+		}
+	}
+}
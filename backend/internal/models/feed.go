@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Page sizes of GET /feed.
+const (
+	DefaultFeedLimit = 20
+	MaxFeedLimit     = 50
+)
+
+// MaxFeedFriends is how many friends GET /feed reads the visits of; each costs a query per page.
+const MaxFeedFriends = 100
+
+// FeedItem is one entry of GET /feed: a friend's visit, redacted as in share views.
+type FeedItem struct {
+	Friend Friend       `json:"friend"`
+	Visit  CountryVisit `json:"visit"`
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (i FeedItem) WithTimeFormat(f jsontime.Format) any {
+	i.Visit = jsontime.Set(i.Visit, f)
+	return i
+}
+
+// FeedResponse is the response for GET /feed. NextCursor is omitted on the last page.
+type FeedResponse struct {
+	Items      []FeedItem `json:"items"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r FeedResponse) MarshalJSON() ([]byte, error) {
+	type fields FeedResponse
+	r.Items = emptyIfNil(r.Items)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r FeedResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Items = jsontime.SetAll(r.Items, f)
+	return r
+}
+
+// FeedCursor is the position of the last item of a feed page. The feed is ordered by
+// VisitedTime, most recent first, then by visit ID descending.
+type FeedCursor struct {
+	VisitedTime time.Time
+	VisitID     string
+}
+
+// ErrInvalidFeedCursor is returned by ParseFeedCursor for a malformed cursor.
+var ErrInvalidFeedCursor = errors.New("invalid cursor")
+
+// FeedCursorOf returns the cursor positioned at visit.
+func FeedCursorOf(visit CountryVisit) FeedCursor {
+	return FeedCursor{VisitedTime: visit.VisitedTime, VisitID: visit.ID}
+}
+
+// String encodes the cursor as the opaque nextCursor of FeedResponse.
+func (c FeedCursor) String() string {
+	raw := strconv.FormatInt(c.VisitedTime.UnixNano(), 10) + ":" + c.VisitID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Precedes reports whether the cursor comes before visit in feed order.
+func (c FeedCursor) Precedes(visit CountryVisit) bool {
+	if !visit.VisitedTime.Equal(c.VisitedTime) {
+		return visit.VisitedTime.Before(c.VisitedTime)
+	}
+	return visit.ID < c.VisitID
+}
+
+// ParseFeedCursor decodes a cursor made by FeedCursor.String.
+func ParseFeedCursor(s string) (FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return FeedCursor{}, ErrInvalidFeedCursor
+	}
+	nanos, visitID, ok := strings.Cut(string(raw), ":")
+	if !ok || visitID == "" {
+		return FeedCursor{}, ErrInvalidFeedCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return FeedCursor{}, ErrInvalidFeedCursor
+	}
+	return FeedCursor{VisitedTime: time.Unix(0, n).UTC(), VisitID: visitID}, nil
+}
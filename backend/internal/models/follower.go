@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Follower is a user who has the owner as a friend, as defined in data-models.md. Stored in
+// users/{userID}/followers/{UserID}, a reverse index of the other users' friends collections
+// maintained together with the Friend documents.
+type Follower struct {
+	// UserID is the follower's auth user ID and the Firestore document ID. Not sent in API.
+	UserID string `firestore:"-" json:"-"`
+
+	// ShareToken, Name and ImageURL are read from the follower's User document; not stored.
+	ShareToken string `firestore:"-" json:"shareToken"`
+	Name       string `firestore:"-" json:"name"`
+	ImageURL   string `firestore:"-" json:"imageUrl,omitempty"`
+
+	// IsFriend is true when the owner has the follower as a friend too. Not stored.
+	IsFriend bool `firestore:"-" json:"isFriend"`
+
+	// CreatedAt is when the follower added the owner as a friend.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (v Follower) MarshalJSON() ([]byte, error) {
+	type fields Follower
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+	}{
+		fields(v),
+		jsontime.New(v.CreatedAt, v.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (v Follower) WithTimeFormat(f jsontime.Format) any {
+	v.timeFormat = f
+	return v
+}
+
+// FollowersResponse is the response for GET /friends/followers.
+type FollowersResponse struct {
+	Followers []Follower `json:"followers"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r FollowersResponse) MarshalJSON() ([]byte, error) {
+	type fields FollowersResponse
+	r.Followers = emptyIfNil(r.Followers)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r FollowersResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Followers = jsontime.SetAll(r.Followers, f)
+	return r
+}
@@ -1,5 +1,25 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+const (
+	// MaxFriendNicknameLength is the maximum number of Unicode characters in Friend.Nickname.
+	MaxFriendNicknameLength = 50
+
+	// DefaultFriendsLimit is the page size of GET /friends without a limit.
+	DefaultFriendsLimit = 100
+
+	// MaxFriendsLimit is the largest page size of GET /friends.
+	MaxFriendsLimit = 500
+)
+
 // Friend represents another user added as a friend, as defined in data-models.md.
 // Stored in users/{userID}/friends. ID is Firestore document ID and is not sent over the API.
 type Friend struct {
@@ -9,14 +29,127 @@ type Friend struct {
 	// ShareToken is the friend user's ShareToken.
 	ShareToken string `firestore:"ShareToken" json:"shareToken"`
 
-	// Name is the friend user's name; duplicated for faster access.
+	// Name is the friend user's name; duplicated for faster access and refreshed by GET /friends.
 	Name string `firestore:"Name" json:"name"`
 
 	// ImageURL is the friend user's image URL; duplicated for faster access, set when the friend is created.
 	ImageURL string `firestore:"ImageURL" json:"imageUrl"`
+
+	// SyncedAt is when Name and ImageURL were last refreshed from the friend's User document;
+	// zero until the first refresh.
+	SyncedAt time.Time `firestore:"SyncedAt,omitempty" json:"-"`
+
+	// Nickname is an optional name for the friend chosen by the user, shown instead of Name.
+	// Private to the user; never overwritten from the friend's own profile.
+	Nickname string `firestore:"Nickname,omitempty" json:"nickname,omitempty"`
+
+	// Summary counts the friend's non-private visits; only set by GET /friends?include=summary.
+	Summary *FriendVisitSummary `firestore:"-" json:"summary,omitempty"`
+}
+
+// ValidateFriendNickname returns an error if nickname is longer than MaxFriendNicknameLength.
+// An empty nickname is valid and clears it.
+func ValidateFriendNickname(nickname string) error {
+	if utf8.RuneCountInString(nickname) > MaxFriendNicknameLength {
+		return fmt.Errorf("nickname must be at most %d characters", MaxFriendNicknameLength)
+	}
+	return nil
 }
 
 // LoginResponse is the response body for POST /login (includes friends list).
 type LoginResponse struct {
 	Friends []Friend `json:"friends"`
 }
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r LoginResponse) MarshalJSON() ([]byte, error) {
+	type fields LoginResponse
+	r.Friends = emptyIfNil(r.Friends)
+	return json.Marshal(fields(r))
+}
+
+// FriendsResponse is the response for GET /friends. NextCursor is set when more friends may
+// follow; pass it as cursor to get them.
+type FriendsResponse struct {
+	Friends    []Friend `json:"friends"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r FriendsResponse) MarshalJSON() ([]byte, error) {
+	type fields FriendsResponse
+	r.Friends = emptyIfNil(r.Friends)
+	return json.Marshal(fields(r))
+}
+
+// FriendVisitsResponse is the response for GET /friends/:shareToken/visits.
+type FriendVisitsResponse struct {
+	Friend  Friend             `json:"friend"`
+	Visits  []CountryVisit     `json:"visits"`
+	Summary FriendVisitSummary `json:"summary"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r FriendVisitsResponse) MarshalJSON() ([]byte, error) {
+	type fields FriendVisitsResponse
+	r.Visits = emptyIfNil(r.Visits)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r FriendVisitsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Visits = jsontime.SetAll(r.Visits, f)
+	return r
+}
+
+// FriendVisitSummary holds counts over the visits of a FriendVisitsResponse.
+type FriendVisitSummary struct {
+	// VisitCount is the number of the friend's non-private visits.
+	VisitCount int `json:"visitCount"`
+
+	// CountryCount is the number of distinct countries among them.
+	CountryCount int `json:"countryCount"`
+
+	// VerifiedVisitCount is the number of them with Verified set.
+	VerifiedVisitCount int `json:"verifiedVisitCount"`
+}
+
+// MutualFriendsResponse is the response for GET /friends/:shareToken/mutual.
+type MutualFriendsResponse struct {
+	Friend Friend `json:"friend"`
+
+	// Mutual are the current user's friends whom the friend has added too, sorted by shown name
+	// (nickname or name).
+	Mutual []Friend `json:"mutual"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r MutualFriendsResponse) MarshalJSON() ([]byte, error) {
+	type fields MutualFriendsResponse
+	r.Mutual = emptyIfNil(r.Mutual)
+	return json.Marshal(fields(r))
+}
+
+// FriendCompareResponse is the response for GET /friends/:shareToken/compare. The code lists
+// are sorted.
+type FriendCompareResponse struct {
+	Friend Friend `json:"friend"`
+
+	// Both are countries visited by both the current user and the friend.
+	Both []string `json:"both"`
+
+	// OnlyMine are countries visited by the current user but not the friend.
+	OnlyMine []string `json:"onlyMine"`
+
+	// OnlyFriend are countries visited by the friend but not the current user.
+	OnlyFriend []string `json:"onlyFriend"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r FriendCompareResponse) MarshalJSON() ([]byte, error) {
+	type fields FriendCompareResponse
+	r.Both = emptyIfNil(r.Both)
+	r.OnlyMine = emptyIfNil(r.OnlyMine)
+	r.OnlyFriend = emptyIfNil(r.OnlyFriend)
+	return json.Marshal(fields(r))
+}
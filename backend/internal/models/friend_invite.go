@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+const (
+	// FriendInviteTTL is how long a FriendInvite links the invitee automatically.
+	FriendInviteTTL = 30 * 24 * time.Hour
+
+	// MaxPendingFriendInvites is the maximum number of unexpired invites of a user.
+	MaxPendingFriendInvites = 20
+)
+
+// FriendInvite is an email invitation to a person not yet on the platform, as defined in
+// data-models.md. Stored in users/{userID}/friend_invites/{ID}. When the invitee signs up with
+// Email and sends the inviter a friend request, the request is accepted automatically.
+type FriendInvite struct {
+	// ID is the Firestore document ID. Exposed for DELETE.
+	ID string `firestore:"-" json:"id"`
+
+	// Email is the invitee's address, normalized by NormalizeInviteEmail.
+	Email string `firestore:"Email" json:"email"`
+
+	// CreatedAt is when the invite was sent.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// ExpiresAt is CreatedAt plus FriendInviteTTL.
+	ExpiresAt time.Time `firestore:"ExpiresAt" json:"expiresAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (i FriendInvite) MarshalJSON() ([]byte, error) {
+	type fields FriendInvite
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+		ExpiresAt jsontime.Time `json:"expiresAt"`
+	}{
+		fields(i),
+		jsontime.New(i.CreatedAt, i.timeFormat),
+		jsontime.New(i.ExpiresAt, i.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (i FriendInvite) WithTimeFormat(f jsontime.Format) any {
+	i.timeFormat = f
+	return i
+}
+
+// FriendInvitesResponse is the response for GET /friends/invites.
+type FriendInvitesResponse struct {
+	Invites []FriendInvite `json:"invites"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r FriendInvitesResponse) MarshalJSON() ([]byte, error) {
+	type fields FriendInvitesResponse
+	r.Invites = emptyIfNil(r.Invites)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r FriendInvitesResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Invites = jsontime.SetAll(r.Invites, f)
+	return r
+}
+
+// NormalizeInviteEmail validates a bare email address and returns it lowercased.
+func NormalizeInviteEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", fmt.Errorf("email must be a valid email address")
+	}
+	return strings.ToLower(email), nil
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// FriendRequest is a pending request from one user to become friends with another, as defined
+// in data-models.md. Stored in friend_requests/{ID}; accepting it creates a Friend on both sides
+// and deletes it. User IDs are not sent over the API.
+type FriendRequest struct {
+	// ID is the Firestore document ID.
+	ID string `firestore:"-" json:"id"`
+
+	// FromUserID is the requesting user.
+	FromUserID string `firestore:"FromUserID" json:"-"`
+
+	// FromShareToken, FromName and FromImageURL describe the requesting user; duplicated so the
+	// recipient's Friend can be created without reading the User.
+	FromShareToken string `firestore:"FromShareToken" json:"fromShareToken"`
+	FromName       string `firestore:"FromName" json:"fromName"`
+	FromImageURL   string `firestore:"FromImageURL,omitempty" json:"fromImageUrl,omitempty"`
+
+	// ToUserID is the requested user, the only one who can accept.
+	ToUserID string `firestore:"ToUserID" json:"-"`
+
+	// ToShareToken, ToName and ToImageURL describe the requested user.
+	ToShareToken string `firestore:"ToShareToken" json:"toShareToken"`
+	ToName       string `firestore:"ToName" json:"toName"`
+	ToImageURL   string `firestore:"ToImageURL,omitempty" json:"toImageUrl,omitempty"`
+
+	// CreatedAt is when the request was sent.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (r FriendRequest) MarshalJSON() ([]byte, error) {
+	type fields FriendRequest
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+	}{
+		fields(r),
+		jsontime.New(r.CreatedAt, r.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r FriendRequest) WithTimeFormat(f jsontime.Format) any {
+	r.timeFormat = f
+	return r
+}
+
+// FriendRequestsResponse is the response for GET /friends/requests.
+type FriendRequestsResponse struct {
+	Incoming []FriendRequest `json:"incoming"`
+	Outgoing []FriendRequest `json:"outgoing"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r FriendRequestsResponse) MarshalJSON() ([]byte, error) {
+	type fields FriendRequestsResponse
+	r.Incoming = emptyIfNil(r.Incoming)
+	r.Outgoing = emptyIfNil(r.Outgoing)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r FriendRequestsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Incoming = jsontime.SetAll(r.Incoming, f)
+	r.Outgoing = jsontime.SetAll(r.Outgoing, f)
+	return r
+}
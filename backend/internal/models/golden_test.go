@@ -0,0 +1,196 @@
+package models_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// responseTypes are the API response bodies checked against testdata/<name>.golden.
+var responseTypes = []reflect.Type{
+	reflect.TypeFor[models.APIKeysResponse](),
+	reflect.TypeFor[models.AccountSettings](),
+	reflect.TypeFor[models.AdminStatusResponse](),
+	reflect.TypeFor[models.AuditEventsResponse](),
+	reflect.TypeFor[models.BackupsResponse](),
+	reflect.TypeFor[models.BlockedUsersResponse](),
+	reflect.TypeFor[models.ChangelogResponse](),
+	reflect.TypeFor[models.CountryDetail](),
+	reflect.TypeFor[models.CountryResponse](),
+	reflect.TypeFor[models.CountryVisit](),
+	reflect.TypeFor[models.CountryVisitResponse](),
+	reflect.TypeFor[models.CreatedAPIKeyResponse](),
+	reflect.TypeFor[models.FeedResponse](),
+	reflect.TypeFor[models.FollowersResponse](),
+	reflect.TypeFor[models.Friend](),
+	reflect.TypeFor[models.FriendCompareResponse](),
+	reflect.TypeFor[models.FriendInvitesResponse](),
+	reflect.TypeFor[models.FriendRequestsResponse](),
+	reflect.TypeFor[models.FriendVisitsResponse](),
+	reflect.TypeFor[models.FriendsResponse](),
+	reflect.TypeFor[models.GuestUpgradeResponse](),
+	reflect.TypeFor[models.HandleResponse](),
+	reflect.TypeFor[models.HealthResponse](),
+	reflect.TypeFor[models.ImportJobResponse](),
+	reflect.TypeFor[models.ImportVisitsResponse](),
+	reflect.TypeFor[models.LoginResponse](),
+	reflect.TypeFor[models.MutualFriendsResponse](),
+	reflect.TypeFor[models.OrgLeaderboardResponse](),
+	reflect.TypeFor[models.OrgShareLinksResponse](),
+	reflect.TypeFor[models.OrgShareResponse](),
+	reflect.TypeFor[models.OrganizationGoalsResponse](),
+	reflect.TypeFor[models.OrganizationInvitationsResponse](),
+	reflect.TypeFor[models.OrganizationMembership](),
+	reflect.TypeFor[models.OrganizationResponse](),
+	reflect.TypeFor[models.OrganizationsResponse](),
+	reflect.TypeFor[models.PassportResponse](),
+	reflect.TypeFor[models.ShareLinksResponse](),
+	reflect.TypeFor[models.ShareProfileResponse](),
+	reflect.TypeFor[models.ShareStatsResponse](),
+	reflect.TypeFor[models.ShareTokenRotation](),
+	reflect.TypeFor[models.ShareWidgetResponse](),
+	reflect.TypeFor[models.SubdivisionResponse](),
+	reflect.TypeFor[models.SupportBundle](),
+	reflect.TypeFor[models.UserLimitsResponse](),
+	reflect.TypeFor[models.VisitClustersResponse](),
+	reflect.TypeFor[models.VisitHistoryResponse](),
+	reflect.TypeFor[models.VisitOverlapResponse](),
+	reflect.TypeFor[models.VisitSearchResponse](),
+	reflect.TypeFor[models.VisitSummaryResponse](),
+}
+
+// golden is the content of a golden file: a filled-in response in both formats, and the zero
+// value, whose collections must be empty rather than null.
+type golden struct {
+	RFC3339 json.RawMessage `json:"rfc3339"`
+	Unix    json.RawMessage `json:"unix"`
+	Empty   json.RawMessage `json:"empty"`
+}
+
+// TestGoldenResponses marshals a filled-in value of each response type in both formats, and its
+// zero value, and compares the result with its golden file (go test -update rewrites them). The RFC 3339 form
+// must also equal that of the value without a time format.
+func TestGoldenResponses(t *testing.T) {
+	for _, typ := range responseTypes {
+		t.Run(typ.Name(), func(t *testing.T) {
+			v := reflect.New(typ).Elem()
+			(&filler{}).fill(v, 0)
+			value := v.Interface()
+
+			rfc, err := json.Marshal(jsontime.Apply(value, jsontime.RFC3339))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := json.Marshal(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(rfc, want) {
+				t.Errorf("RFC 3339 form differs from the default:\n%s\n%s", rfc, want)
+			}
+			unix, err := json.Marshal(jsontime.Apply(value, jsontime.Unix))
+			if err != nil {
+				t.Fatal(err)
+			}
+			empty, err := json.Marshal(reflect.Zero(typ).Interface())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.MarshalIndent(golden{RFC3339: rfc, Unix: unix, Empty: empty}, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			path := filepath.Join("testdata", typ.Name()+".golden")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Errorf("%s differs (run go test -update after checking the change):\n%s",
+					path, got)
+			}
+		})
+	}
+}
+
+// filler sets every field of a value to a deterministic, non-zero value, so that golden files
+// cover all fields and change only when the types do.
+type filler struct {
+	n int
+}
+
+// maxFillDepth bounds recursive types.
+const maxFillDepth = 6
+
+var fillTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func (f *filler) fill(v reflect.Value, depth int) {
+	if depth > maxFillDepth {
+		return
+	}
+	f.n++
+	switch {
+	case v.Type() == reflect.TypeFor[time.Time]():
+		v.Set(reflect.ValueOf(fillTime.Add(time.Duration(f.n) * time.Hour)))
+		return
+	case v.Type() == reflect.TypeFor[json.RawMessage]():
+		v.SetBytes([]byte(`{"raw":true}`))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(f.n % 100))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(f.n % 100))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(f.n) + 0.5)
+	case reflect.String:
+		v.SetString("s" + string(rune('a'+f.n%26)))
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		f.fill(p.Elem(), depth+1)
+		v.Set(p)
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), 1, 1)
+		f.fill(s.Index(0), depth+1)
+		v.Set(s)
+	case reflect.Array:
+		for i := range v.Len() {
+			f.fill(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		key := reflect.New(v.Type().Key()).Elem()
+		f.fill(key, depth+1)
+		elem := reflect.New(v.Type().Elem()).Elem()
+		f.fill(elem, depth+1)
+		m.SetMapIndex(key, elem)
+		v.Set(m)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				f.fill(v.Field(i), depth+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package models
+
+// GuestUpgradeResponse is the response for POST /me/upgrade.
+type GuestUpgradeResponse struct {
+	// MovedVisits is the number of visits moved from the guest account.
+	MovedVisits int `json:"movedVisits"`
+}
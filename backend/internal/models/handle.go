@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Handle reserves a vanity handle for its owner, as defined in data-models.md. Stored in
+// handles/{handle}, keyed by the normalized handle so that each handle has one owner; the owner
+// also has it in User.Handle. Opens the owner's share view at GET /u/{handle}.
+type Handle struct {
+	// UserID is the owner's user ID.
+	UserID string `firestore:"UserID" json:"-"`
+
+	// CreatedAt is when the handle was claimed.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"-"`
+}
+
+// HandleResponse is the response for GET and PUT /me/handle; Handle is omitted when the user
+// has none.
+type HandleResponse struct {
+	Handle string `json:"handle,omitempty"`
+}
+
+var handlePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,28}[a-z0-9]$`)
+
+// reservedHandles are handles nobody can claim: app and route names, and names that could be
+// mistaken for the service itself.
+var reservedHandles = map[string]bool{
+	"about": true, "account": true, "admin": true, "administrator": true, "api": true,
+	"app": true, "blocked": true, "countries": true, "feed": true, "friends": true,
+	"help": true, "insights": true, "login": true, "logout": true, "me": true,
+	"moderator": true, "mytravel": true, "null": true, "official": true, "orgs": true,
+	"privacy": true, "profile": true, "root": true, "settings": true, "share": true,
+	"static": true, "staff": true, "support": true, "system": true, "terms": true,
+	"undefined": true, "visits": true,
+}
+
+// NormalizeHandle trims whitespace and a leading @ and lowercases s.
+func NormalizeHandle(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "@")
+	return strings.ToLower(s)
+}
+
+// ValidateHandle checks a normalized handle: 3–30 chars; lowercase letters, digits and -, not
+// at either end; no --; not reserved.
+func ValidateHandle(s string) error {
+	if s == "" {
+		return fmt.Errorf("handle is required")
+	}
+	if !handlePattern.MatchString(s) || strings.Contains(s, "--") {
+		return fmt.Errorf("handle must be 3-30 lowercase letters, digits or single hyphens, " +
+			"starting and ending with a letter or digit")
+	}
+	if reservedHandles[s] {
+		return fmt.Errorf("handle is reserved")
+	}
+	return nil
+}
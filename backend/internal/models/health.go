@@ -0,0 +1,18 @@
+package models
+
+// Health statuses for HealthResponse.Status and the values of HealthResponse.Checks.
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+	HealthFailed      = "failed"
+)
+
+// HealthResponse is the response for GET /healthz and GET /readyz.
+type HealthResponse struct {
+	// Status is HealthOK, or HealthUnavailable when a check failed.
+	Status string `json:"status"`
+
+	// Checks maps each dependency checked by GET /readyz to HealthOK or HealthFailed. Omitted
+	// by GET /healthz.
+	Checks map[string]string `json:"checks,omitempty"`
+}
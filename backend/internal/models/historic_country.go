@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// HistoricCountry is a former country that dissolved or merged into others (ISO 3166-3), e.g.
+// the Soviet Union. Visits may use its code when VisitedTime falls within its existence.
+type HistoricCountry struct {
+	// CountryCode is the former ISO 3166-1 alpha-2 code (e.g. "SU").
+	CountryCode string `json:"countryCode"`
+
+	// Alpha3 is the former ISO 3166-1 alpha-3 code (e.g. "SUN").
+	Alpha3 string `json:"alpha3"`
+
+	// Name is the common English name.
+	Name string `json:"name"`
+
+	// RegionCode is a 2-letter ISO 3166-1 continent code.
+	RegionCode string `json:"regionCode"`
+
+	// From is the first day of the country's existence.
+	From time.Time `json:"from"`
+
+	// Until is the day the country ceased to exist (exclusive).
+	Until time.Time `json:"until"`
+
+	// SuccessorCodes are the alpha-2 codes of the current countries covering its former area,
+	// drawn in its place on maps.
+	SuccessorCodes []string `json:"successorCodes"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat and nil collections as empty ones.
+func (h HistoricCountry) MarshalJSON() ([]byte, error) {
+	type fields HistoricCountry
+	h.SuccessorCodes = emptyIfNil(h.SuccessorCodes)
+	return json.Marshal(struct {
+		fields
+		From  jsontime.Time `json:"from"`
+		Until jsontime.Time `json:"until"`
+	}{
+		fields(h),
+		jsontime.New(h.From, h.timeFormat),
+		jsontime.New(h.Until, h.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (h HistoricCountry) WithTimeFormat(f jsontime.Format) any {
+	h.timeFormat = f
+	return h
+}
+
+// ValidateVisitedTime returns an error unless t falls within [From, Until).
+func (h HistoricCountry) ValidateVisitedTime(t time.Time) error {
+	if t.Before(h.From) || !t.Before(h.Until) {
+		return fmt.Errorf("%s existed only from %s until %s", h.Name,
+			h.From.Format(time.DateOnly), h.Until.Format(time.DateOnly))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// InsightsPeriod is the length of the recent period compared with the one before it.
+const InsightsPeriod = 365 * 24 * time.Hour
+
+// Insights holds travel trends of a user computed from their visits, as defined in
+// data-models.md. Stored in users/{userID}/insights/latest; regenerated weekly by the insights
+// backfill job and returned by GET /insights.
+type Insights struct {
+	// GeneratedAt is when the insights were computed; the periods end at it.
+	GeneratedAt time.Time `firestore:"GeneratedAt" json:"generatedAt"`
+
+	// RecentVisitCount is the number of visits within InsightsPeriod before GeneratedAt.
+	RecentVisitCount int `firestore:"RecentVisitCount" json:"recentVisitCount"`
+
+	// PreviousVisitCount is the number of visits in the InsightsPeriod before that.
+	PreviousVisitCount int `firestore:"PreviousVisitCount" json:"previousVisitCount"`
+
+	// FrequencyChangePercent is the change from PreviousVisitCount to RecentVisitCount in
+	// percent, e.g. 50 for 2 to 3 visits. Nil when PreviousVisitCount is zero.
+	FrequencyChangePercent *float64 `firestore:"FrequencyChangePercent" json:"frequencyChangePercent"`
+
+	// NewRegionCodes are the continent codes first visited within the recent period, in
+	// order of the first visit.
+	NewRegionCodes []string `firestore:"NewRegionCodes" json:"newRegionCodes"`
+
+	// LongestGap is the longest time between two consecutive visits. Nil with fewer than two
+	// visits.
+	LongestGap *TripGap `firestore:"LongestGap" json:"longestGap"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat and nil collections as empty ones.
+func (i Insights) MarshalJSON() ([]byte, error) {
+	type fields Insights
+	i.NewRegionCodes = emptyIfNil(i.NewRegionCodes)
+	return json.Marshal(struct {
+		fields
+		GeneratedAt jsontime.Time `json:"generatedAt"`
+	}{
+		fields(i),
+		jsontime.New(i.GeneratedAt, i.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (i Insights) WithTimeFormat(f jsontime.Format) any {
+	i.timeFormat = f
+	i.LongestGap = jsontime.SetPtr(i.LongestGap, f)
+	return i
+}
+
+// TripGap is the period between two consecutive visits.
+type TripGap struct {
+	// From is the time of the earlier visit.
+	From time.Time `firestore:"From" json:"from"`
+
+	// To is the time of the later visit.
+	To time.Time `firestore:"To" json:"to"`
+
+	// Days is the length of the gap in whole days.
+	Days int `firestore:"Days" json:"days"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (g TripGap) MarshalJSON() ([]byte, error) {
+	type fields TripGap
+	return json.Marshal(struct {
+		fields
+		From jsontime.Time `json:"from"`
+		To   jsontime.Time `json:"to"`
+	}{
+		fields(g),
+		jsontime.New(g.From, g.timeFormat),
+		jsontime.New(g.To, g.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (g TripGap) WithTimeFormat(f jsontime.Format) any {
+	g.timeFormat = f
+	return g
+}
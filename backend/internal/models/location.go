@@ -0,0 +1,24 @@
+package models
+
+import (
+	"errors"
+	"math"
+)
+
+// Location is a point (WGS 84 degrees) where a visit took place, typically a city.
+type Location struct {
+	Latitude  float64 `firestore:"Latitude" json:"latitude"`
+	Longitude float64 `firestore:"Longitude" json:"longitude"`
+}
+
+// Validate returns an error unless the latitude is within [-90, 90] and the longitude within
+// [-180, 180].
+func (l Location) Validate() error {
+	if math.IsNaN(l.Latitude) || l.Latitude < -90 || l.Latitude > 90 {
+		return errors.New("location.latitude must be between -90 and 90")
+	}
+	if math.IsNaN(l.Longitude) || l.Longitude < -180 || l.Longitude > 180 {
+		return errors.New("location.longitude must be between -180 and 180")
+	}
+	return nil
+}
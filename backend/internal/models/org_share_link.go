@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Org share link scopes (OrgShareLink.Scope): what GET /share/org/:shareToken projects.
+const (
+	// OrgShareScopeAggregate shows only the combined map: countries with member counts.
+	OrgShareScopeAggregate = "aggregate"
+
+	// OrgShareScopeMembers additionally lists each member's name and visited countries.
+	OrgShareScopeMembers = "members"
+)
+
+// MaxOrgShareLinks is the maximum number of share links of an Organization.
+const MaxOrgShareLinks = 20
+
+// OrgShareLink is a revocable share link of an Organization with a scope, minted by an owner or
+// admin. Stored in org_share_links/{Token}. The Organization's own ShareToken has scope
+// OrgShareScopeAggregate.
+type OrgShareLink struct {
+	// Token is the Firestore document ID, used in GET /share/org/:shareToken.
+	Token string `firestore:"-" json:"token"`
+
+	// OrganizationID is the shared organization.
+	OrganizationID string `firestore:"OrganizationID" json:"organizationId"`
+
+	// Scope is OrgShareScopeAggregate or OrgShareScopeMembers.
+	Scope string `firestore:"Scope" json:"scope"`
+
+	// CreatedBy is the user ID of the owner or admin who minted the link.
+	CreatedBy string `firestore:"CreatedBy" json:"createdBy"`
+
+	// CreatedAt is when the link was minted.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (l OrgShareLink) MarshalJSON() ([]byte, error) {
+	type fields OrgShareLink
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+	}{
+		fields(l),
+		jsontime.New(l.CreatedAt, l.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (l OrgShareLink) WithTimeFormat(f jsontime.Format) any {
+	l.timeFormat = f
+	return l
+}
+
+// OrgShareLinksResponse is the response for GET /orgs/:id/share-links.
+type OrgShareLinksResponse struct {
+	ShareLinks []OrgShareLink `json:"shareLinks"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r OrgShareLinksResponse) MarshalJSON() ([]byte, error) {
+	type fields OrgShareLinksResponse
+	r.ShareLinks = emptyIfNil(r.ShareLinks)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r OrgShareLinksResponse) WithTimeFormat(f jsontime.Format) any {
+	r.ShareLinks = jsontime.SetAll(r.ShareLinks, f)
+	return r
+}
+
+// OrgShareMember is a member on an org share page with scope OrgShareScopeMembers.
+type OrgShareMember struct {
+	Name         string   `json:"name"`
+	ImageURL     string   `json:"imageUrl,omitempty"`
+	CountryCodes []string `json:"countryCodes"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (o OrgShareMember) MarshalJSON() ([]byte, error) {
+	type fields OrgShareMember
+	o.CountryCodes = emptyIfNil(o.CountryCodes)
+	return json.Marshal(fields(o))
+}
+
+// ValidateOrgShareScope returns an error unless scope is a known org share link scope.
+func ValidateOrgShareScope(scope string) error {
+	if scope != OrgShareScopeAggregate && scope != OrgShareScopeMembers {
+		return fmt.Errorf("scope must be one of %s, %s", OrgShareScopeAggregate,
+			OrgShareScopeMembers)
+	}
+	return nil
+}
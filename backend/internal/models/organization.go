@@ -0,0 +1,232 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Organization roles (OrganizationMember.Role), from most to least privileged.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+const (
+	// MaxOrgNameLength is the maximum number of Unicode characters in Organization.Name.
+	MaxOrgNameLength = 100
+
+	// MaxOrgDescriptionLength is the maximum number of Unicode characters in
+	// Organization.Description.
+	MaxOrgDescriptionLength = 500
+
+	// MaxOrgMembers is the maximum number of members of an Organization.
+	MaxOrgMembers = 200
+
+	// MaxOrgPendingInvitations is the maximum number of unaccepted invitations of an
+	// Organization, expired ones included.
+	MaxOrgPendingInvitations = 50
+
+	// OrgInvitationTTL is how long an OrganizationInvitation can be accepted.
+	OrgInvitationTTL = 7 * 24 * time.Hour
+)
+
+// Organization is a group of users (a travel club, a family) sharing a combined map, as
+// defined in data-models.md. Stored in organizations/{ID}.
+type Organization struct {
+	// ID is the Firestore document ID.
+	ID string `firestore:"-" json:"id"`
+
+	// Name is the display name. Mandatory.
+	Name string `firestore:"Name" json:"name"`
+
+	// Description is an optional free-form text shown on the share page.
+	Description string `firestore:"Description" json:"description,omitempty"`
+
+	// ShareToken identifies the public org share page (GET /share/org/:shareToken).
+	ShareToken string `firestore:"ShareToken" json:"shareToken"`
+
+	// CreatedBy is the user ID of the creator, the first owner.
+	CreatedBy string `firestore:"CreatedBy" json:"createdBy"`
+
+	// CreatedAt is when the organization was created.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// MemberCount is the number of members, updated in the same transaction as membership.
+	MemberCount int `firestore:"MemberCount" json:"memberCount"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// organizationFields is Organization without its methods, for marshaling.
+type organizationFields Organization
+
+// organizationJSON is Organization as written in JSON, with the timestamps in its time format.
+type organizationJSON struct {
+	organizationFields
+	CreatedAt jsontime.Time `json:"createdAt"`
+}
+
+func (o Organization) toJSON() organizationJSON {
+	return organizationJSON{
+		organizationFields: organizationFields(o),
+		CreatedAt:          jsontime.New(o.CreatedAt, o.timeFormat),
+	}
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (o Organization) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.toJSON())
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (o Organization) WithTimeFormat(f jsontime.Format) any {
+	o.timeFormat = f
+	return o
+}
+
+// OrganizationMembership is an organization together with the current user's role in it.
+type OrganizationMembership struct {
+	Organization
+	Role string `json:"role"`
+}
+
+// organizationMembershipJSON is OrganizationMembership as written in JSON.
+type organizationMembershipJSON struct {
+	organizationJSON
+	Role string `json:"role"`
+}
+
+func (m OrganizationMembership) toJSON() organizationMembershipJSON {
+	return organizationMembershipJSON{organizationJSON: m.Organization.toJSON(), Role: m.Role}
+}
+
+// MarshalJSON implements json.Marshaler. It is needed because the promoted
+// Organization.MarshalJSON would leave out Role.
+func (m OrganizationMembership) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.toJSON())
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (m OrganizationMembership) WithTimeFormat(f jsontime.Format) any {
+	m.Organization = jsontime.Set(m.Organization, f)
+	return m
+}
+
+// OrganizationsResponse is the response for GET /orgs.
+type OrganizationsResponse struct {
+	Organizations []OrganizationMembership `json:"organizations"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r OrganizationsResponse) MarshalJSON() ([]byte, error) {
+	type fields OrganizationsResponse
+	r.Organizations = emptyIfNil(r.Organizations)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r OrganizationsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Organizations = jsontime.SetAll(r.Organizations, f)
+	return r
+}
+
+// OrganizationResponse is the response for GET /orgs/:id.
+type OrganizationResponse struct {
+	OrganizationMembership
+	Members []OrganizationMember `json:"members"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil Members as empty. It is also needed
+// because the promoted OrganizationMembership.MarshalJSON would leave out Members.
+func (r OrganizationResponse) MarshalJSON() ([]byte, error) {
+	r.Members = emptyIfNil(r.Members)
+	return json.Marshal(struct {
+		organizationMembershipJSON
+		Members []OrganizationMember `json:"members"`
+	}{r.OrganizationMembership.toJSON(), r.Members})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r OrganizationResponse) WithTimeFormat(f jsontime.Format) any {
+	r.OrganizationMembership = jsontime.Set(r.OrganizationMembership, f)
+	r.Members = jsontime.SetAll(r.Members, f)
+	return r
+}
+
+// OrganizationInvitationsResponse is the response for GET /orgs/:id/invitations.
+type OrganizationInvitationsResponse struct {
+	Invitations []OrganizationInvitation `json:"invitations"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r OrganizationInvitationsResponse) MarshalJSON() ([]byte, error) {
+	type fields OrganizationInvitationsResponse
+	r.Invitations = emptyIfNil(r.Invitations)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r OrganizationInvitationsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Invitations = jsontime.SetAll(r.Invitations, f)
+	return r
+}
+
+// OrgCountry is a country on the org share page with the number of members who visited it.
+type OrgCountry struct {
+	CountryCode string `json:"countryCode"`
+	MemberCount int    `json:"memberCount"`
+}
+
+// OrgShareResponse is the response for GET /share/org/:shareToken.
+type OrgShareResponse struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	MemberCount int          `json:"memberCount"`
+	Countries   []OrgCountry `json:"countries"`
+
+	// Scope is the share link's scope; see OrgShareLink.
+	Scope string `json:"scope"`
+
+	// Members is set only for OrgShareScopeMembers.
+	Members []OrgShareMember `json:"members,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r OrgShareResponse) MarshalJSON() ([]byte, error) {
+	type fields OrgShareResponse
+	r.Countries = emptyIfNil(r.Countries)
+	return json.Marshal(fields(r))
+}
+
+// ValidateOrgProfile returns an error unless name is non-empty and both name and description
+// are within their maximum lengths.
+func ValidateOrgProfile(name, description string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	if utf8.RuneCountInString(name) > MaxOrgNameLength {
+		return fmt.Errorf("name must be at most %d characters", MaxOrgNameLength)
+	}
+	if utf8.RuneCountInString(description) > MaxOrgDescriptionLength {
+		return fmt.Errorf("description must be at most %d characters", MaxOrgDescriptionLength)
+	}
+	return nil
+}
+
+// ValidateOrgGrantableRole returns an error unless role can be granted by invitation or role
+// change: OrgRoleAdmin or OrgRoleMember. Organizations keep their creator as the only owner.
+func ValidateOrgGrantableRole(role string) error {
+	if role != OrgRoleAdmin && role != OrgRoleMember {
+		return fmt.Errorf("role must be one of %s, %s", OrgRoleAdmin, OrgRoleMember)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+const (
+	// MaxOrgGoals is the maximum number of goals of an Organization.
+	MaxOrgGoals = 20
+
+	// MaxOrgGoalTitleLength is the maximum number of Unicode characters in
+	// OrganizationGoal.Title.
+	MaxOrgGoalTitleLength = 100
+
+	// MaxOrgGoalCountryCodes is the maximum number of OrganizationGoal.CountryCodes.
+	MaxOrgGoalCountryCodes = 100
+
+	// MaxOrgGoalTargetCount is the maximum OrganizationGoal.TargetCount.
+	MaxOrgGoalTargetCount = 300
+)
+
+// OrganizationGoal is a shared target of an Organization, e.g. "50 countries in 2026" or
+// "every Nordic country", met by the members' combined visits. Stored in
+// organizations/{orgID}/goals/{ID}.
+type OrganizationGoal struct {
+	// ID is the Firestore document ID.
+	ID string `firestore:"-" json:"id"`
+
+	// Title is the display title. Mandatory.
+	Title string `firestore:"Title" json:"title"`
+
+	// CountryCodes optionally restricts the goal to these countries (alpha-2).
+	CountryCodes []string `firestore:"CountryCodes,omitempty" json:"countryCodes,omitempty"`
+
+	// TargetCount is the number of distinct countries to visit. Defaults to all of CountryCodes.
+	TargetCount int `firestore:"TargetCount" json:"targetCount"`
+
+	// Year optionally restricts the goal to visits in that calendar year (UTC); 0 means any.
+	Year int `firestore:"Year,omitempty" json:"year,omitempty"`
+
+	// CreatedBy is the user ID of the owner or admin who set the goal.
+	CreatedBy string `firestore:"CreatedBy" json:"createdBy"`
+
+	// CreatedAt is when the goal was set.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// Progress (API only) is the number of distinct countries counted towards the goal.
+	Progress int `firestore:"-" json:"progress"`
+
+	// Completed (API only) is true when Progress has reached TargetCount.
+	Completed bool `firestore:"-" json:"completed"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (g OrganizationGoal) MarshalJSON() ([]byte, error) {
+	type fields OrganizationGoal
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+	}{
+		fields(g),
+		jsontime.New(g.CreatedAt, g.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (g OrganizationGoal) WithTimeFormat(f jsontime.Format) any {
+	g.timeFormat = f
+	return g
+}
+
+// OrganizationGoalsResponse is the response for GET /orgs/:id/goals.
+type OrganizationGoalsResponse struct {
+	Goals []OrganizationGoal `json:"goals"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r OrganizationGoalsResponse) MarshalJSON() ([]byte, error) {
+	type fields OrganizationGoalsResponse
+	r.Goals = emptyIfNil(r.Goals)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r OrganizationGoalsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Goals = jsontime.SetAll(r.Goals, f)
+	return r
+}
+
+// Validate returns an error unless the goal has a title and a reachable target. CountryCodes
+// must already be normalized; a zero TargetCount with CountryCodes means all of them.
+func (g OrganizationGoal) Validate() error {
+	if strings.TrimSpace(g.Title) == "" {
+		return errors.New("title is required")
+	}
+	if utf8.RuneCountInString(g.Title) > MaxOrgGoalTitleLength {
+		return fmt.Errorf("title must be at most %d characters", MaxOrgGoalTitleLength)
+	}
+	if len(g.CountryCodes) > MaxOrgGoalCountryCodes {
+		return fmt.Errorf("countryCodes must have at most %d entries", MaxOrgGoalCountryCodes)
+	}
+	switch {
+	case g.TargetCount < 0:
+		return errors.New("targetCount must not be negative")
+	case len(g.CountryCodes) == 0 && g.TargetCount == 0:
+		return errors.New("targetCount or countryCodes is required")
+	case len(g.CountryCodes) > 0 && g.TargetCount > len(g.CountryCodes):
+		return errors.New("targetCount must not exceed the number of countryCodes")
+	case g.TargetCount > MaxOrgGoalTargetCount:
+		return fmt.Errorf("targetCount must be at most %d", MaxOrgGoalTargetCount)
+	}
+	if g.Year != 0 && (g.Year < MinVisitedTime.Year() || g.Year > 9999) {
+		return errors.New("year is invalid")
+	}
+	return nil
+}
+
+// OrgLeaderboardEntry is one member's row on an organization leaderboard.
+type OrgLeaderboardEntry struct {
+	// Rank starts at 1; members with equal CountryCount share a rank.
+	Rank         int    `json:"rank"`
+	UserID       string `json:"userId"`
+	Name         string `json:"name"`
+	ImageURL     string `json:"imageUrl,omitempty"`
+	CountryCount int    `json:"countryCount"`
+	VisitCount   int    `json:"visitCount"`
+}
+
+// OrgLeaderboardResponse is the response for GET /orgs/:id/leaderboard.
+type OrgLeaderboardResponse struct {
+	Year    int                   `json:"year"`
+	Entries []OrgLeaderboardEntry `json:"entries"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r OrgLeaderboardResponse) MarshalJSON() ([]byte, error) {
+	type fields OrgLeaderboardResponse
+	r.Entries = emptyIfNil(r.Entries)
+	return json.Marshal(fields(r))
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// OrganizationInvitation is a single-use code granting membership with Role, stored in
+// organization_invitations/{Code}.
+type OrganizationInvitation struct {
+	// Code is the Firestore document ID, sent to the invitee.
+	Code string `firestore:"-" json:"code"`
+
+	// OrganizationID is the organization joined on acceptance.
+	OrganizationID string `firestore:"OrganizationID" json:"organizationId"`
+
+	// Role is OrgRoleAdmin or OrgRoleMember.
+	Role string `firestore:"Role" json:"role"`
+
+	// CreatedBy is the user ID of the inviting owner or admin.
+	CreatedBy string `firestore:"CreatedBy" json:"createdBy"`
+
+	// CreatedAt is when the invitation was created.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// ExpiresAt is CreatedAt plus OrgInvitationTTL.
+	ExpiresAt time.Time `firestore:"ExpiresAt" json:"expiresAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (i OrganizationInvitation) MarshalJSON() ([]byte, error) {
+	type fields OrganizationInvitation
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+		ExpiresAt jsontime.Time `json:"expiresAt"`
+	}{
+		fields(i),
+		jsontime.New(i.CreatedAt, i.timeFormat),
+		jsontime.New(i.ExpiresAt, i.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (i OrganizationInvitation) WithTimeFormat(f jsontime.Format) any {
+	i.timeFormat = f
+	return i
+}
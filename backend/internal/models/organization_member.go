@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// OrganizationMember is a user's membership, stored in organizations/{orgID}/members/{UserID}.
+type OrganizationMember struct {
+	// UserID is the member's auth user ID (also the document ID; stored for collection group
+	// queries over a user's memberships).
+	UserID string `firestore:"UserID" json:"userId"`
+
+	// Role is one of OrgRoleOwner, OrgRoleAdmin, OrgRoleMember.
+	Role string `firestore:"Role" json:"role"`
+
+	// Name is the member's name; duplicated for faster access when joining.
+	Name string `firestore:"Name" json:"name"`
+
+	// ImageURL is the member's image URL; duplicated when joining.
+	ImageURL string `firestore:"ImageURL" json:"imageUrl,omitempty"`
+
+	// JoinedAt is when the user joined.
+	JoinedAt time.Time `firestore:"JoinedAt" json:"joinedAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (m OrganizationMember) MarshalJSON() ([]byte, error) {
+	type fields OrganizationMember
+	return json.Marshal(struct {
+		fields
+		JoinedAt jsontime.Time `json:"joinedAt"`
+	}{
+		fields(m),
+		jsontime.New(m.JoinedAt, m.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (m OrganizationMember) WithTimeFormat(f jsontime.Format) any {
+	m.timeFormat = f
+	return m
+}
+
+// CanManage reports whether the member may edit the organization, invite and remove members.
+func (m OrganizationMember) CanManage() bool {
+	return m.Role == OrgRoleOwner || m.Role == OrgRoleAdmin
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// PassportContinent is one continent group in a PassportResponse.
+type PassportContinent struct {
+	RegionCode   string   `json:"regionCode"`
+	Name         string   `json:"name"`
+	Count        int      `json:"count"`
+	Flags        string   `json:"flags"`
+	CountryCodes []string `json:"countryCodes"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (p PassportContinent) MarshalJSON() ([]byte, error) {
+	type fields PassportContinent
+	p.CountryCodes = emptyIfNil(p.CountryCodes)
+	return json.Marshal(fields(p))
+}
+
+// PassportResponse is the JSON response for GET /share/:shareToken/passport.
+// Text is the same content as the text/plain representation.
+type PassportResponse struct {
+	UserName       string              `json:"userName"`
+	CountriesCount int                 `json:"countriesCount"`
+	Continents     []PassportContinent `json:"continents"`
+	Text           string              `json:"text"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r PassportResponse) MarshalJSON() ([]byte, error) {
+	type fields PassportResponse
+	r.Continents = emptyIfNil(r.Continents)
+	return json.Marshal(fields(r))
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Share link scopes (ShareLink.Scope): how much of the visits GET /share/profile/:shareToken
+// returns.
+const (
+	// ShareScopeMap returns only the distinct visited country codes.
+	ShareScopeMap = "map"
+
+	// ShareScopeSummary returns only visit and country counts.
+	ShareScopeSummary = "summary"
+
+	// ShareScopeFull returns the visits with dates and media, as for the ShareToken.
+	ShareScopeFull = "full"
+)
+
+const (
+	// MaxShareLinks is the maximum number of unexpired share links of a user.
+	MaxShareLinks = 20
+
+	// MaxShareLinkTTL is the longest a share link may stay valid.
+	MaxShareLinkTTL = 365 * 24 * time.Hour
+)
+
+// ShareLink is an expiring, revocable alternative to the user's permanent ShareToken, as
+// defined in data-models.md. Stored in users/{userID}/share_links/{Token}; its token opens the
+// same share views (GET /share/profile/:shareToken, GET /share/:shareToken/passport).
+type ShareLink struct {
+	// Token is the Firestore document ID, used in place of the ShareToken in share URLs.
+	Token string `firestore:"Token" json:"token"`
+
+	// UserID is the auth user ID of the owner. Not sent in API.
+	UserID string `firestore:"UserID" json:"-"`
+
+	// CreatedAt is when the link was created.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// ExpiresAt is when the link stops working.
+	ExpiresAt time.Time `firestore:"ExpiresAt" json:"expiresAt"`
+
+	// Scope is ShareScopeMap, ShareScopeSummary or ShareScopeFull. Links created before scopes
+	// existed have none and are ShareScopeFull.
+	Scope string `firestore:"Scope" json:"scope"`
+
+	// MaxViews is the number of share views the link allows; 0 means unlimited.
+	MaxViews int `firestore:"MaxViews" json:"maxViews,omitempty"`
+
+	// Views is the number of share views served through the link. Counted only with MaxViews.
+	Views int `firestore:"Views" json:"views"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (l ShareLink) MarshalJSON() ([]byte, error) {
+	type fields ShareLink
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+		ExpiresAt jsontime.Time `json:"expiresAt"`
+	}{
+		fields(l),
+		jsontime.New(l.CreatedAt, l.timeFormat),
+		jsontime.New(l.ExpiresAt, l.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (l ShareLink) WithTimeFormat(f jsontime.Format) any {
+	l.timeFormat = f
+	return l
+}
+
+// ShareLinksResponse is the response for GET /me/share-links.
+type ShareLinksResponse struct {
+	ShareLinks []ShareLink `json:"shareLinks"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r ShareLinksResponse) MarshalJSON() ([]byte, error) {
+	type fields ShareLinksResponse
+	r.ShareLinks = emptyIfNil(r.ShareLinks)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r ShareLinksResponse) WithTimeFormat(f jsontime.Format) any {
+	r.ShareLinks = jsontime.SetAll(r.ShareLinks, f)
+	return r
+}
+
+// Active reports whether the link still opens the share at now.
+func (l ShareLink) Active(now time.Time) bool {
+	return now.Before(l.ExpiresAt) && (l.MaxViews == 0 || l.Views < l.MaxViews)
+}
+
+// ValidateShareLink returns an error unless expiresAt is in the future, at most MaxShareLinkTTL
+// after now, and maxViews is not negative.
+func ValidateShareLink(expiresAt time.Time, maxViews int, now time.Time) error {
+	if !expiresAt.After(now) {
+		return fmt.Errorf("expiresAt must be in the future")
+	}
+	if expiresAt.Sub(now) > MaxShareLinkTTL {
+		return fmt.Errorf("expiresAt must be at most %d days ahead",
+			int(MaxShareLinkTTL.Hours()/24))
+	}
+	if maxViews < 0 {
+		return fmt.Errorf("maxViews must not be negative")
+	}
+	return nil
+}
+
+// ValidateShareScope returns an error unless scope is a known share link scope.
+func ValidateShareScope(scope string) error {
+	if scope != ShareScopeMap && scope != ShareScopeSummary && scope != ShareScopeFull {
+		return fmt.Errorf("scope must be one of %s, %s, %s", ShareScopeMap, ShareScopeSummary,
+			ShareScopeFull)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// ShareStatsDays is the number of most recent days returned in ShareStats.Daily.
+const ShareStatsDays = 30
+
+// Share token kinds (ShareStats.Kind).
+const (
+	// ShareKindShareToken is the user's permanent ShareToken.
+	ShareKindShareToken = "shareToken"
+
+	// ShareKindShareLink is one of the user's share links.
+	ShareKindShareLink = "shareLink"
+)
+
+// ShareStats counts the views of one share token of a user, as defined in data-models.md.
+// Stored in users/{userID}/share_stats/{Token}. Nothing about the viewers (IP address, user ID)
+// is stored; views by the owner are not counted.
+type ShareStats struct {
+	// Token is the Firestore document ID: the ShareToken or a ShareLink token.
+	Token string `firestore:"-" json:"token"`
+
+	// Kind is ShareKindShareToken or ShareKindShareLink. Not stored.
+	Kind string `firestore:"-" json:"kind"`
+
+	// Views is the number of share views through the token.
+	Views int64 `firestore:"Views" json:"views"`
+
+	// LastViewedAt is when the token was last viewed; nil if never.
+	LastViewedAt *time.Time `firestore:"LastViewedAt" json:"lastViewedAt,omitempty"`
+
+	// Days holds the views per UTC day, keyed by date ("2006-01-02"). Not sent in API.
+	Days map[string]int64 `firestore:"Days" json:"-"`
+
+	// Daily are the days with views among the last ShareStatsDays, oldest first. Not stored.
+	Daily []ShareStatsDay `firestore:"-" json:"daily"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat and nil collections as empty ones.
+func (s ShareStats) MarshalJSON() ([]byte, error) {
+	type fields ShareStats
+	s.Daily = emptyIfNil(s.Daily)
+	return json.Marshal(struct {
+		fields
+		LastViewedAt *jsontime.Time `json:"lastViewedAt,omitempty"`
+	}{
+		fields(s),
+		jsontime.NewPtr(s.LastViewedAt, s.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (s ShareStats) WithTimeFormat(f jsontime.Format) any {
+	s.timeFormat = f
+	return s
+}
+
+// ShareStatsDay is the number of views of a share token on one UTC day.
+type ShareStatsDay struct {
+	Date  string `json:"date"`
+	Views int64  `json:"views"`
+}
+
+// ShareStatsResponse is the response for GET /me/share-stats.
+type ShareStatsResponse struct {
+	ShareStats []ShareStats `json:"shareStats"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r ShareStatsResponse) MarshalJSON() ([]byte, error) {
+	type fields ShareStatsResponse
+	r.ShareStats = emptyIfNil(r.ShareStats)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r ShareStatsResponse) WithTimeFormat(f jsontime.Format) any {
+	r.ShareStats = jsontime.SetAll(r.ShareStats, f)
+	return r
+}
+
+// ShareStatsDate returns the Days key of the UTC day of t.
+func ShareStatsDate(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// SetDaily fills Daily from Days with the days of the ShareStatsDays ending at now.
+func (s *ShareStats) SetDaily(now time.Time) {
+	first := ShareStatsDate(now.AddDate(0, 0, -(ShareStatsDays - 1)))
+	s.Daily = []ShareStatsDay{}
+	for date, views := range s.Days {
+		// Dates in the Days format sort chronologically
+		if date >= first && views > 0 {
+			s.Daily = append(s.Daily, ShareStatsDay{Date: date, Views: views})
+		}
+	}
+	sort.Slice(s.Daily, func(i, j int) bool { return s.Daily[i].Date < s.Daily[j].Date })
+}
@@ -0,0 +1,11 @@
+package models
+
+// ShareTokenRotation is the response for POST /me/share-token/rotate.
+type ShareTokenRotation struct {
+	// ShareToken is the user's new ShareToken; the old one no longer resolves.
+	ShareToken string `json:"shareToken"`
+
+	// RemovedFromFriends is the number of users whose friends list the user was removed from;
+	// zero unless removal was requested.
+	RemovedFromFriends int `json:"removedFromFriends"`
+}
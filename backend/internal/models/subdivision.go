@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// Subdivision is an ISO 3166-2 country subdivision (state, province, region...).
+type Subdivision struct {
+	// Code is the ISO 3166-2 code, e.g. "US-CA".
+	Code string `json:"code"`
+
+	// CountryCode is the alpha-2 code of the parent country.
+	CountryCode string `json:"countryCode"`
+
+	// Name is the English (or common local) name.
+	Name string `json:"name"`
+
+	// Type is the subdivision category as named by ISO 3166-2, e.g. "state", "province".
+	Type string `json:"type"`
+}
+
+// SubdivisionResponse is the response for GET /countries/:code/subdivisions.
+type SubdivisionResponse struct {
+	Subdivisions []Subdivision `json:"subdivisions"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r SubdivisionResponse) MarshalJSON() ([]byte, error) {
+	type fields SubdivisionResponse
+	r.Subdivisions = emptyIfNil(r.Subdivisions)
+	return json.Marshal(fields(r))
+}
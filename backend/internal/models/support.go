@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// RecentRequest is non-sensitive metadata about a request made by the current user.
+type RecentRequest struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Status    int       `json:"status"`
+	TraceID   string    `json:"traceId,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (r RecentRequest) MarshalJSON() ([]byte, error) {
+	type fields RecentRequest
+	return json.Marshal(struct {
+		fields
+		Time jsontime.Time `json:"time"`
+	}{
+		fields(r),
+		jsontime.New(r.Time, r.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r RecentRequest) WithTimeFormat(f jsontime.Format) any {
+	r.timeFormat = f
+	return r
+}
+
+// SupportBundleCounts holds the user's data counts included in a support bundle.
+type SupportBundleCounts struct {
+	Visits  int `json:"visits"`
+	Friends int `json:"friends"`
+}
+
+// SupportBundle is the response for GET /support/bundle: diagnostic info a user can attach
+// to a bug report. Must not contain personal data such as name, email, notes or media URLs.
+type SupportBundle struct {
+	GeneratedAt    time.Time           `json:"generatedAt"`
+	AppVersion     string              `json:"appVersion"`
+	Revision       string              `json:"revision,omitempty"`
+	GoVersion      string              `json:"goVersion"`
+	UserID         string              `json:"userId"`
+	RecentRequests []RecentRequest     `json:"recentRequests"`
+	Counts         SupportBundleCounts `json:"counts"`
+	Flags          map[string]bool     `json:"flags"`
+
+	// DataResidency is the region user data is kept in (e.g. "eu"); omitted when unrestricted.
+	DataResidency string `json:"dataResidency,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat and nil collections as empty ones.
+func (b SupportBundle) MarshalJSON() ([]byte, error) {
+	type fields SupportBundle
+	b.RecentRequests = emptyIfNil(b.RecentRequests)
+	b.Flags = emptyMapIfNil(b.Flags)
+	return json.Marshal(struct {
+		fields
+		GeneratedAt jsontime.Time `json:"generatedAt"`
+	}{
+		fields(b),
+		jsontime.New(b.GeneratedAt, b.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (b SupportBundle) WithTimeFormat(f jsontime.Format) any {
+	b.timeFormat = f
+	b.RecentRequests = jsontime.SetAll(b.RecentRequests, f)
+	return b
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"fmt"
+	"time"
+)
+
 // User represents a system user. Data parsed from incoming authentication token.
 // Only used in the backend. Aligns with data-models.md.
 type User struct {
@@ -18,11 +23,65 @@ type User struct {
 	// Email is the user email from the token.
 	Email string `firestore:"Email" json:"-"`
 
+	// EmailVerified is true when the auth token confirms the user owns Email. Not stored.
+	EmailVerified bool `firestore:"-" json:"-"`
+
 	// ImageURL is the user's profile image URL from the token; stored at login.
 	ImageURL string `firestore:"ImageURL" json:"-"`
 
 	// Settings is optional on older documents; nil means apply DefaultUserSettings().
 	Settings *UserSettings `firestore:"Settings" json:"-"`
+
+	// IsTester is true when the auth token carries the "tester" custom claim. Not stored.
+	IsTester bool `firestore:"-" json:"-"`
+
+	// IsAdmin is true when the auth token carries the "admin": true or "role": "admin" custom
+	// claim. Not stored.
+	IsAdmin bool `firestore:"-" json:"-"`
+
+	// APIKeyID is the ID of the API key the request authenticated with; empty for ID tokens
+	// and session cookies. Not stored.
+	APIKeyID string `firestore:"-" json:"-"`
+
+	// IsAnonymous is true for a guest account from a Firebase anonymous sign-in; stored at
+	// creation. POST /me/upgrade merges a guest account into a permanent one.
+	IsAnonymous bool `firestore:"IsAnonymous,omitempty" json:"-"`
+
+	// VisitsRevision is incremented on every write to the user's country_visits; used for ETags.
+	VisitsRevision int64 `firestore:"VisitsRevision" json:"-"`
+
+	// VisitCount is the number of the user's country_visits. Derived; adjusted by every visit
+	// write and rebuilt by the visit-stats backfill job.
+	VisitCount int64 `firestore:"VisitCount" json:"-"`
+
+	// DistinctCountries is the number of distinct CountryCodes among the user's visits. Derived;
+	// adjusted by every visit write and rebuilt by the visit-stats backfill job.
+	DistinctCountries int64 `firestore:"DistinctCountries" json:"-"`
+
+	// Handle is the user's vanity handle (see Handle), unique among users; empty when none.
+	Handle string `firestore:"Handle,omitempty" json:"-"`
+
+	// SharingDisabled turns the user's share views off until cleared, keeping the ShareToken and
+	// share links; set via PATCH /me/settings.
+	SharingDisabled bool `firestore:"SharingDisabled,omitempty" json:"-"`
+
+	// TokensRevokedAt revokes the user's ID tokens and session cookies from sign-ins before it
+	// (auth_time); set via POST /me/revoke-sessions and enforced in strict auth mode. Whole
+	// seconds, like auth_time.
+	TokensRevokedAt *time.Time `firestore:"TokensRevokedAt,omitempty" json:"-"`
+
+	// Limits is the admin override of the user's document limits; nil applies the configured
+	// limits.
+	Limits *UserLimits `firestore:"Limits,omitempty" json:"-"`
+
+	// DeletionScheduledAt is when the account will be purged after DELETE /account; nil unless
+	// deletion is pending. The user's shares are disabled meanwhile.
+	DeletionScheduledAt *time.Time `firestore:"DeletionScheduledAt,omitempty" json:"-"`
+}
+
+// PendingDeletion reports whether the user's account is scheduled for deletion.
+func (u *User) PendingDeletion() bool {
+	return u.DeletionScheduledAt != nil
 }
 
 // UserSettings holds per-user preferences (see data-models.md).
@@ -31,6 +90,39 @@ type UserSettings struct {
 	HomeCountryCode   string          `firestore:"HomeCountryCode,omitempty" json:"homeCountryCode,omitempty"`
 	Description       string          `firestore:"Description,omitempty" json:"description,omitempty"`
 	Sharing           SharingSettings `firestore:"Sharing" json:"sharing"`
+	VisitDefaults     *VisitDefaults  `firestore:"VisitDefaults,omitempty" json:"visitDefaults,omitempty"`
+
+	// IncludeTerritories lets the user record visits to territories and disputed states
+	// (data.Territories) in addition to sovereign countries.
+	IncludeTerritories bool `firestore:"IncludeTerritories,omitempty" json:"includeTerritories"`
+
+	// HideFromFollowers keeps the user out of the GET /friends/followers lists of the users
+	// they added as friends.
+	HideFromFollowers bool `firestore:"HideFromFollowers,omitempty" json:"hideFromFollowers"`
+}
+
+// Dedupe modes for VisitDefaults.Dedupe and POST /visits `dedupe`.
+const (
+	// DedupeNone always creates a new visit.
+	DedupeNone = "none"
+	// DedupeSameDay returns an existing visit to the same country on the same UTC day instead.
+	DedupeSameDay = "sameDay"
+)
+
+// VisitDefaults is the per-user template applied by POST /visits to fields the request omits.
+type VisitDefaults struct {
+	IsPrivate bool   `firestore:"IsPrivate" json:"isPrivate"`
+	Dedupe    string `firestore:"Dedupe,omitempty" json:"dedupe,omitempty"`
+	VisitType string `firestore:"VisitType,omitempty" json:"visitType,omitempty"`
+}
+
+// ValidateDedupe returns an error unless mode is empty or a known dedupe mode.
+func ValidateDedupe(mode string) error {
+	switch mode {
+	case "", DedupeNone, DedupeSameDay:
+		return nil
+	}
+	return fmt.Errorf("dedupe must be one of %s, %s", DedupeNone, DedupeSameDay)
 }
 
 // SharingSettings controls what is exposed on shared visit lists.
@@ -77,6 +169,10 @@ func SettingsToResponse(s UserSettings) map[string]interface{} {
 	if s.Description != "" {
 		out["description"] = s.Description
 	}
+	if s.VisitDefaults != nil {
+		out["visitDefaults"] = s.VisitDefaults
+	}
+	out["includeTerritories"] = s.IncludeTerritories
+	out["hideFromFollowers"] = s.HideFromFollowers
 	return out
 }
-
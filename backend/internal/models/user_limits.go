@@ -0,0 +1,34 @@
+package models
+
+// UserLimits caps how many documents of a kind a user may own, so a runaway client cannot run
+// up the database's read and write costs. Stored on the User as an admin override of the
+// configured limits (PUT /admin/users/:userId/limits); 0 keeps the configured limit.
+type UserLimits struct {
+	MaxVisits  int `firestore:"MaxVisits,omitempty" json:"maxVisits"`
+	MaxFriends int `firestore:"MaxFriends,omitempty" json:"maxFriends"`
+}
+
+// Or returns l with its unset (0) limits taken from defaults. l may be nil.
+func (l *UserLimits) Or(defaults UserLimits) UserLimits {
+	if l == nil {
+		return defaults
+	}
+	out := *l
+	if out.MaxVisits <= 0 {
+		out.MaxVisits = defaults.MaxVisits
+	}
+	if out.MaxFriends <= 0 {
+		out.MaxFriends = defaults.MaxFriends
+	}
+	return out
+}
+
+// UserLimitsResponse is the response for GET and PUT /admin/users/:userId/limits.
+type UserLimitsResponse struct {
+	// Limits are the limits in effect for the user.
+	Limits UserLimits `json:"limits"`
+
+	// Override is the user's admin override; omitted when there is none.
+	Override   *UserLimits `json:"override,omitempty"`
+	VisitCount int64       `json:"visitCount"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -12,6 +13,13 @@ type ValidationErrors struct {
 	Fields map[string]string `json:"fields"`
 }
 
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (v ValidationErrors) MarshalJSON() ([]byte, error) {
+	type fields ValidationErrors
+	v.Fields = emptyMapIfNil(v.Fields)
+	return json.Marshal(fields(v))
+}
+
 // NewValidationErrors builds a ValidationErrors response.
 func NewValidationErrors(fields map[string]string) ValidationErrors {
 	return ValidationErrors{
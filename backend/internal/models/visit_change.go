@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Visit change types for VisitChange.Type, also the SSE event names of GET /visits/stream.
+const (
+	VisitChangeAdded    = "added"
+	VisitChangeModified = "modified"
+	VisitChangeRemoved  = "removed"
+)
+
+// VisitChange is a change to one of the user's CountryVisits, pushed by GET /visits/stream.
+type VisitChange struct {
+	// Type is one of VisitChangeAdded, VisitChangeModified or VisitChangeRemoved.
+	Type string `json:"type"`
+
+	// ID is the ID of the changed visit.
+	ID string `json:"id"`
+
+	// Visit is the visit after the change; nil for removed visits.
+	Visit *CountryVisit `json:"visit,omitempty"`
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (c VisitChange) WithTimeFormat(f jsontime.Format) any {
+	c.Visit = jsontime.SetPtr(c.Visit, f)
+	return c
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// VisitCluster is a group of visit locations that are close to each other at a map zoom level.
+type VisitCluster struct {
+	// Latitude and Longitude are the mean position of the clustered locations.
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+
+	// Count is the number of visits in the cluster.
+	Count int `json:"count"`
+
+	// CountryCodes are the distinct countries of the clustered visits, sorted.
+	CountryCodes []string `json:"countryCodes"`
+
+	// VisitID is set when the cluster is a single visit, so clients can render it as a marker.
+	VisitID string `json:"visitId,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (v VisitCluster) MarshalJSON() ([]byte, error) {
+	type fields VisitCluster
+	v.CountryCodes = emptyIfNil(v.CountryCodes)
+	return json.Marshal(fields(v))
+}
+
+// VisitClustersResponse is the response for GET /visits/clusters.
+type VisitClustersResponse struct {
+	Zoom     int            `json:"zoom"`
+	Clusters []VisitCluster `json:"clusters"`
+
+	// Unlocated is the number of visits without a Location, which are not clustered.
+	Unlocated int `json:"unlocated"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r VisitClustersResponse) MarshalJSON() ([]byte, error) {
+	type fields VisitClustersResponse
+	r.Clusters = emptyIfNil(r.Clusters)
+	return json.Marshal(fields(r))
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Visit history event types for VisitHistoryEvent.Type.
+const (
+	VisitEventCreated = "created"
+	VisitEventUpdated = "updated"
+	VisitEventDeleted = "deleted"
+)
+
+// VisitHistoryEvent is one immutable change to a CountryVisit, stored in the visit's history
+// subcollection (see data-models.md). Events are only ever appended, never modified.
+type VisitHistoryEvent struct {
+	// ID is the Firestore document ID of the event. Not stored.
+	ID string `firestore:"-" json:"id"`
+
+	// Type is one of VisitEventCreated, VisitEventUpdated or VisitEventDeleted.
+	Type string `firestore:"Type" json:"type"`
+
+	// ActorID is the ID of the user who made the change.
+	ActorID string `firestore:"ActorID" json:"actorId"`
+
+	// Time is the server time of the change.
+	Time time.Time `firestore:"Time" json:"time"`
+
+	// Before is the visit before the change; nil for created events.
+	Before *CountryVisit `firestore:"Before" json:"before,omitempty"`
+
+	// After is the visit after the change; nil for deleted events.
+	After *CountryVisit `firestore:"After" json:"after,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (e VisitHistoryEvent) MarshalJSON() ([]byte, error) {
+	type fields VisitHistoryEvent
+	return json.Marshal(struct {
+		fields
+		Time jsontime.Time `json:"time"`
+	}{
+		fields(e),
+		jsontime.New(e.Time, e.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (e VisitHistoryEvent) WithTimeFormat(f jsontime.Format) any {
+	e.timeFormat = f
+	e.Before = jsontime.SetPtr(e.Before, f)
+	e.After = jsontime.SetPtr(e.After, f)
+	return e
+}
+
+// VisitHistoryResponse is the JSON response for GET /visits/:id/history.
+type VisitHistoryResponse struct {
+	Events []VisitHistoryEvent `json:"events"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r VisitHistoryResponse) MarshalJSON() ([]byte, error) {
+	type fields VisitHistoryResponse
+	r.Events = emptyIfNil(r.Events)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r VisitHistoryResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Events = jsontime.SetAll(r.Events, f)
+	return r
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// VisitOverlap links one of the owner's visits to a mutual friend's visit of the same trip ("I
+// was there too"), as defined in data-models.md. Stored in users/{userID}/overlaps; both users
+// hold a copy under the same ID, each from their own point of view.
+type VisitOverlap struct {
+	// ID is the Firestore document ID, shared by both copies. Exposed for DELETE.
+	ID string `firestore:"-" json:"id"`
+
+	// VisitID is the ID of the owner's visit.
+	VisitID string `firestore:"VisitID" json:"visitId"`
+
+	// FriendUserID is the auth user ID of the friend. Not sent in API.
+	FriendUserID string `firestore:"FriendUserID" json:"-"`
+
+	// FriendShareToken is the friend's ShareToken when the overlap was added.
+	FriendShareToken string `firestore:"FriendShareToken" json:"friendShareToken"`
+
+	// FriendVisitID is the ID of the friend's visit.
+	FriendVisitID string `firestore:"FriendVisitID" json:"friendVisitId"`
+
+	// CreatedAt is when the overlap was added.
+	CreatedAt time.Time `firestore:"CreatedAt" json:"createdAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (o VisitOverlap) MarshalJSON() ([]byte, error) {
+	type fields VisitOverlap
+	return json.Marshal(struct {
+		fields
+		CreatedAt jsontime.Time `json:"createdAt"`
+	}{
+		fields(o),
+		jsontime.New(o.CreatedAt, o.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (o VisitOverlap) WithTimeFormat(f jsontime.Format) any {
+	o.timeFormat = f
+	return o
+}
+
+// VisitOverlapResponse is the response for POST /friends/:shareToken/visits/:id/overlaps: the
+// overlap from the caller's point of view and the caller's linked (possibly new) visit.
+type VisitOverlapResponse struct {
+	Overlap VisitOverlap `json:"overlap"`
+	Visit   CountryVisit `json:"visit"`
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r VisitOverlapResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Overlap = jsontime.Set(r.Overlap, f)
+	r.Visit = jsontime.Set(r.Visit, f)
+	return r
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// MaxProofsPerVisit is the maximum number of proofs attached to a CountryVisit.
+const MaxProofsPerVisit = 5
+
+// MaxProofBytes is the maximum size of an uploaded proof file.
+const MaxProofBytes = 5 << 20
+
+// Proof kinds for VisitProof.Kind.
+const (
+	ProofKindBoardingPass = "boarding_pass"
+	ProofKindStamp        = "stamp"
+	ProofKindOther        = "other"
+)
+
+// ProofKinds lists the accepted VisitProof.Kind values in documentation order.
+var ProofKinds = []string{ProofKindBoardingPass, ProofKindStamp, ProofKindOther}
+
+// ProofContentTypes lists the accepted proof file types, as detected from the file content.
+var ProofContentTypes = []string{"image/jpeg", "image/png", "application/pdf"}
+
+// VisitProof describes a file (e.g. a boarding pass or a passport stamp) attached to a visit as
+// proof that it took place. The file itself is kept in object storage and is only served to
+// the visit owner.
+type VisitProof struct {
+	// ID identifies the proof within the visit and names its stored object.
+	ID string `firestore:"ID" json:"id"`
+
+	// Kind is one of ProofKinds.
+	Kind string `firestore:"Kind" json:"kind"`
+
+	// ContentType is one of ProofContentTypes.
+	ContentType string `firestore:"ContentType" json:"contentType"`
+
+	// Size is the file size in bytes.
+	Size int64 `firestore:"Size" json:"size"`
+
+	// UploadedAt is when the proof was attached.
+	UploadedAt time.Time `firestore:"UploadedAt" json:"uploadedAt"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (p VisitProof) MarshalJSON() ([]byte, error) {
+	type fields VisitProof
+	return json.Marshal(struct {
+		fields
+		UploadedAt jsontime.Time `json:"uploadedAt"`
+	}{
+		fields(p),
+		jsontime.New(p.UploadedAt, p.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (p VisitProof) WithTimeFormat(f jsontime.Format) any {
+	p.timeFormat = f
+	return p
+}
+
+// ValidateProofKind returns an error unless kind is one of ProofKinds.
+func ValidateProofKind(kind string) error {
+	for _, k := range ProofKinds {
+		if kind == k {
+			return nil
+		}
+	}
+	return fmt.Errorf("kind must be one of %s", strings.Join(ProofKinds, ", "))
+}
+
+// IsProofContentType reports whether contentType is one of ProofContentTypes.
+func IsProofContentType(contentType string) bool {
+	for _, t := range ProofContentTypes {
+		if contentType == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// NeighborsVisited is the "borders visited" stat of a country: how many of the countries and
+// territories sharing a land border with it the user has visited.
+type NeighborsVisited struct {
+	// CountryCode is the country whose neighbors are counted.
+	CountryCode string `json:"countryCode"`
+
+	// Total is the number of neighbors; 0 for island states.
+	Total int `json:"total"`
+
+	// Visited is the number of neighbors with at least one visit.
+	Visited int `json:"visited"`
+
+	// VisitedCodes are the visited neighbors; NotVisitedCodes the others. Both in code order.
+	VisitedCodes    []string `json:"visitedCodes"`
+	NotVisitedCodes []string `json:"notVisitedCodes"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (n NeighborsVisited) MarshalJSON() ([]byte, error) {
+	type fields NeighborsVisited
+	n.VisitedCodes = emptyIfNil(n.VisitedCodes)
+	n.NotVisitedCodes = emptyIfNil(n.NotVisitedCodes)
+	return json.Marshal(fields(n))
+}
+
+// VisitSummaryResponse is the response for GET /visits/summary.
+type VisitSummaryResponse struct {
+	// VisitCount is the number of the user's visits, including private ones.
+	VisitCount int `json:"visitCount"`
+
+	// CountryCount is the number of distinct countries and territories visited.
+	CountryCount int `json:"countryCount"`
+
+	// Neighbors counts the visited neighbors of the requested country or, by default, of the
+	// user's home country. Omitted when neither is set.
+	Neighbors *NeighborsVisited `json:"neighbors,omitempty"`
+}
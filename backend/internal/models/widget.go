@@ -0,0 +1,21 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// ShareWidgetResponse is the JSON response for GET /share/widget/:shareToken.
+// CountryCodes are the distinct visited country codes, sorted; empty for summary share links.
+type ShareWidgetResponse struct {
+	UserName       string   `json:"userName"`
+	CountriesCount int      `json:"countriesCount"`
+	CountryCodes   []string `json:"countryCodes"`
+	ShareURL       string   `json:"shareUrl"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r ShareWidgetResponse) MarshalJSON() ([]byte, error) {
+	type fields ShareWidgetResponse
+	r.CountryCodes = emptyIfNil(r.CountryCodes)
+	return json.Marshal(fields(r))
+}
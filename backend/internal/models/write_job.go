@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// Write job statuses for WriteJob.Status.
+const (
+	WriteJobRunning   = "running"
+	WriteJobCompleted = "completed"
+	WriteJobFailed    = "failed"
+)
+
+// WriteJob is the progress of a batch of writes running in the background of one instance,
+// e.g. a large visit import. Kept in memory for an hour after it finishes; not stored.
+type WriteJob struct {
+	ID string `json:"id"`
+
+	// Kind names the batch, e.g. "import".
+	Kind string `json:"kind"`
+
+	// Status is one of WriteJobRunning, WriteJobCompleted or WriteJobFailed.
+	Status string `json:"status"`
+
+	// Total is the number of writes in the batch; Written of them are done.
+	Total   int `json:"total"`
+	Written int `json:"written"`
+
+	// Error is set when Status is WriteJobFailed. The writes done before it are kept.
+	Error string `json:"error,omitempty"`
+
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// timeFormat is the format of the timestamps in JSON; see WithTimeFormat.
+	timeFormat jsontime.Format
+}
+
+// MarshalJSON implements json.Marshaler, writing the timestamps in the format set by
+// WithTimeFormat.
+func (j WriteJob) MarshalJSON() ([]byte, error) {
+	type fields WriteJob
+	return json.Marshal(struct {
+		fields
+		CreatedAt   jsontime.Time  `json:"createdAt"`
+		CompletedAt *jsontime.Time `json:"completedAt,omitempty"`
+	}{
+		fields(j),
+		jsontime.New(j.CreatedAt, j.timeFormat),
+		jsontime.NewPtr(j.CompletedAt, j.timeFormat),
+	})
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (j WriteJob) WithTimeFormat(f jsontime.Format) any {
+	j.timeFormat = f
+	return j
+}
+
+// ImportJobResponse is the 202 response for POST /visits/import when the import runs in the
+// background.
+type ImportJobResponse struct {
+	Job     WriteJob        `json:"job"`
+	Skipped []ImportSkipped `json:"skipped"`
+}
+
+// MarshalJSON implements json.Marshaler, writing nil collections as empty ones.
+func (r ImportJobResponse) MarshalJSON() ([]byte, error) {
+	type fields ImportJobResponse
+	r.Skipped = emptyIfNil(r.Skipped)
+	return json.Marshal(fields(r))
+}
+
+// WithTimeFormat implements jsontime.Formatter.
+func (r ImportJobResponse) WithTimeFormat(f jsontime.Format) any {
+	r.Job = jsontime.Set(r.Job, f)
+	return r
+}
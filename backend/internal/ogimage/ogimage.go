@@ -0,0 +1,177 @@
+// Package ogimage renders the Open Graph preview image of a share: a world map with the
+// visited countries highlighted, the number of visited countries and the user's name, so share
+// links unfurl with a picture in chat apps and social media.
+package ogimage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+
+	"github.com/matti777/my-countries/backend/internal/data/geometry"
+)
+
+// Width and Height are the image size recommended for Open Graph images.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	// maxLatitude and minLatitude bound the map; Antarctica is left out.
+	maxLatitude = 84.0
+	minLatitude = -58.0
+	// mapTop is the y coordinate of maxLatitude; the text goes below the map.
+	mapTop = 12
+	// maxNameLength is the most runes of the user name shown.
+	maxNameLength = 40
+	margin        = 60
+)
+
+var (
+	background  = color.RGBA{0x10, 0x2a, 0x43, 0xff}
+	landColor   = color.RGBA{0x3d, 0x5a, 0x78, 0xff}
+	visitColor  = color.RGBA{0xf2, 0xb1, 0x34, 0xff}
+	titleColor  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	detailColor = color.RGBA{0xc8, 0xd6, 0xe5, 0xff}
+)
+
+// fonts holds the parsed fonts, created on first use. Faces are created per Render since they
+// are not safe for concurrent use.
+var fonts struct {
+	once    sync.Once
+	bold    *opentype.Font
+	regular *opentype.Font
+	err     error
+}
+
+// Render returns the PNG preview image of a share of name with countriesCount visited
+// countries. The countries in visited (alpha-2 codes) are highlighted on the map; countries
+// too small for the map are only counted.
+func Render(name string, countriesCount int, visited []string) ([]byte, error) {
+	fonts.once.Do(loadFonts)
+	if fonts.err != nil {
+		return nil, fonts.err
+	}
+	titleFace, err := newFace(fonts.bold, 56)
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+	detailFace, err := newFace(fonts.regular, 36)
+	if err != nil {
+		return nil, err
+	}
+	defer detailFace.Close()
+	features, err := geometry.All(geometry.Low)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	highlighted := make(map[string]bool, len(visited))
+	for _, code := range visited {
+		highlighted[strings.ToUpper(code)] = true
+	}
+	land := vector.NewRasterizer(Width, Height)
+	visits := vector.NewRasterizer(Width, Height)
+	for code, f := range features {
+		r := land
+		if highlighted[code] {
+			r = visits
+		}
+		for _, ring := range f.Rings {
+			addRing(r, ring)
+		}
+	}
+	land.Draw(img, img.Bounds(), image.NewUniform(landColor), image.Point{})
+	visits.Draw(img, img.Bounds(), image.NewUniform(visitColor), image.Point{})
+
+	title := fmt.Sprintf("%d countries visited", countriesCount)
+	if countriesCount == 1 {
+		title = "1 country visited"
+	}
+	drawText(img, titleFace, titleColor, title, Height-82)
+	drawText(img, detailFace, detailColor, shortName(name), Height-30)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func loadFonts() {
+	if fonts.bold, fonts.err = opentype.Parse(gobold.TTF); fonts.err != nil {
+		fonts.err = fmt.Errorf("failed to parse font: %w", fonts.err)
+		return
+	}
+	if fonts.regular, fonts.err = opentype.Parse(goregular.TTF); fonts.err != nil {
+		fonts.err = fmt.Errorf("failed to parse font: %w", fonts.err)
+	}
+}
+
+func newFace(f *opentype.Font, size float64) (font.Face, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	return face, nil
+}
+
+// addRing adds a [longitude, latitude] ring to r in an equirectangular projection spanning
+// the image width.
+func addRing(r *vector.Rasterizer, ring [][2]float64) {
+	for i, p := range ring {
+		x, y := project(p)
+		if i == 0 {
+			r.MoveTo(x, y)
+		} else {
+			r.LineTo(x, y)
+		}
+	}
+	r.ClosePath()
+}
+
+func project(p [2]float64) (float32, float32) {
+	scale := float64(Width) / 360
+	lat := min(max(p[1], minLatitude), maxLatitude)
+	return float32((p[0] + 180) * scale), float32(mapTop + (maxLatitude-lat)*scale)
+}
+
+func drawText(img draw.Image, face font.Face, c color.Color, text string, baseline int) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(margin, baseline),
+	}
+	d.DrawString(text)
+}
+
+// shortName returns name cut to maxNameLength runes.
+func shortName(name string) string {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) <= maxNameLength {
+		return name
+	}
+	return string([]rune(name)[:maxNameLength-1]) + "…"
+}
@@ -0,0 +1,83 @@
+package openapi
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"path"
+)
+
+// swaggerUI holds the Swagger UI distribution the docs page loads (see swaggerui/NOTICE),
+// embedded so the page needs no third-party CDN.
+//
+//go:embed swaggerui/swagger-ui-bundle.js swaggerui/swagger-ui.css
+var swaggerUI embed.FS
+
+// DocsAssetsPath is the URL path the files of DocsAsset are served under.
+const DocsAssetsPath = "/docs/"
+
+// docsAssetTypes are the content types of the files of swaggerUI.
+var docsAssetTypes = map[string]string{
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+	"swagger-ui.css":       "text/css; charset=utf-8",
+}
+
+// swaggerUIScript starts Swagger UI on the document served at /openapi.json.
+const swaggerUIScript = `window.ui = SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});`
+
+// DocsPage is the HTML page of Swagger UI showing the document served at /openapi.json. It loads
+// the embedded files from DocsAssetsPath with subresource integrity hashes.
+var DocsPage = []byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>API documentation</title>
+<link rel="stylesheet" href="` + DocsAssetsPath + `swagger-ui.css" integrity="` +
+	integrity("swagger-ui.css") + `">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="` + DocsAssetsPath + `swagger-ui-bundle.js" integrity="` +
+	integrity("swagger-ui-bundle.js") + `"></script>
+<script>` + swaggerUIScript + `</script>
+</body>
+</html>
+`)
+
+// DocsCSP is the Content-Security-Policy of DocsPage: scripts and styles from this origin, the
+// inline start script by hash and requests to this origin only.
+var DocsCSP = fmt.Sprintf("default-src 'none'; script-src 'self' 'sha256-%s'; "+
+	"style-src 'self' 'unsafe-inline'; img-src 'self' data:; connect-src 'self'; "+
+	"frame-ancestors 'none'", scriptHash(swaggerUIScript))
+
+// DocsAsset returns the embedded Swagger UI file name (e.g. swagger-ui.css) and its content
+// type, or false when there is no such file.
+func DocsAsset(name string) ([]byte, string, bool) {
+	contentType, ok := docsAssetTypes[name]
+	if !ok {
+		return nil, "", false
+	}
+	data, err := swaggerUI.ReadFile(path.Join("swaggerui", name))
+	if err != nil {
+		return nil, "", false
+	}
+	return data, contentType, true
+}
+
+// integrity returns the subresource integrity value of the embedded file name.
+func integrity(name string) string {
+	data, _, ok := DocsAsset(name)
+	if !ok {
+		panic("openapi: missing embedded Swagger UI file " + name)
+	}
+	sum := sha512.Sum384(data)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func scriptHash(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
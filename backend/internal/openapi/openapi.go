@@ -0,0 +1,304 @@
+// Package openapi builds the OpenAPI 3 document of the API. Operations are declared in code next
+// to the routes (see server.apiEndpoints); their request and response schemas are reflected from
+// the Go types the handlers bind and write, so the document changes with the models. Build
+// returns the document, which the server serves at /openapi.json with Swagger UI at /docs.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Version is the OpenAPI version of the documents built here.
+const Version = "3.0.3"
+
+// Security scheme names of Document.Components.SecuritySchemes.
+const (
+	SecurityBearer  = "bearerAuth"
+	SecurityAPIKey  = "apiKeyAuth"
+	SecuritySession = "sessionCookie"
+)
+
+// errorSchemaName is the component of the {"requestId": "...", "error": "..."} body of error
+// responses; some also have a machine-readable code.
+const errorSchemaName = "Error"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"` // by path, then lowercase method
+	Components Components                      `json:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components holds the schemas and security schemes referenced by operations.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme is a way of authenticating requests.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Operation is one method of a path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the body of a request.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is the response of one status code.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Endpoint declares a route for Build.
+type Endpoint struct {
+	// Method and Path are those the route is registered with, e.g. GET and /visits/:id. Path
+	// parameters become required string parameters.
+	Method, Path string
+
+	// ID is the operationId, unique in the document (e.g. the handler name without "Handler").
+	ID string
+
+	Summary string
+	Tag     string
+
+	// Public routes accept unauthenticated requests; the others require one of the security
+	// schemes.
+	Public bool
+
+	// Params are the query and header parameters.
+	Params []Parameter
+
+	// Body is a value of the JSON request body's type, or nil without a body. Its top-level
+	// schema is inlined.
+	Body any
+
+	Replies []Reply
+}
+
+// Reply is a documented response of an Endpoint.
+type Reply struct {
+	Status      int
+	Description string // defaults to the status text
+
+	// Body is a value of the JSON response body's type. Without it, error statuses get the
+	// Error schema and others no body.
+	Body any
+
+	// Text documents a text/plain alternative to the JSON body.
+	Text bool
+}
+
+// Build returns the document of endpoints, or an error when an endpoint is invalid (see
+// validate).
+func Build(info Info, endpoints []Endpoint) (*Document, error) {
+	if err := validate(endpoints); err != nil {
+		return nil, err
+	}
+	g := newGenerator()
+	g.schemas[errorSchemaName] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"requestId": {Type: "string"}, "error": {Type: "string"}, "code": {Type: "string"},
+		},
+		Required: []string{"requestId", "error"},
+	}
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]map[string]Operation),
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				SecurityBearer: {
+					Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+					Description: "Firebase ID token.",
+				},
+				SecurityAPIKey: {Type: "apiKey", In: "header", Name: "X-Api-Key"},
+				SecuritySession: {
+					Type: "apiKey", In: "cookie", Name: "__session",
+					Description: "Session cookie from POST /session.",
+				},
+			},
+		},
+	}
+	for _, e := range endpoints {
+		path, params := pathParams(e.Path)
+		op := Operation{
+			OperationID: e.ID,
+			Summary:     e.Summary,
+			Parameters:  append(params, e.Params...),
+			Responses:   make(map[string]Response, len(e.Replies)),
+		}
+		if e.Tag != "" {
+			op.Tags = []string{e.Tag}
+		}
+		if !e.Public {
+			op.Security = []map[string][]string{
+				{SecurityBearer: {}}, {SecurityAPIKey: {}}, {SecuritySession: {}},
+			}
+		}
+		if e.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"application/json": {Schema: g.inline(reflect.TypeOf(e.Body))},
+				},
+			}
+		}
+		for _, r := range e.Replies {
+			op.Responses[strconv.Itoa(r.Status)] = g.response(r)
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]Operation)
+		}
+		doc.Paths[path][strings.ToLower(e.Method)] = op
+	}
+	return doc, nil
+}
+
+// documentedMethods are the methods an Endpoint may have.
+var documentedMethods = map[string]bool{
+	http.MethodGet: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true,
+}
+
+// validate checks that each endpoint has a supported method, an absolute path, an operation ID
+// and at least one reply, that operation IDs and routes are unique, that no status is documented
+// twice and that Params are unique query or header parameters (path parameters come from Path).
+func validate(endpoints []Endpoint) error {
+	ids := make(map[string]bool, len(endpoints))
+	routes := make(map[string]bool, len(endpoints))
+	for _, e := range endpoints {
+		route := e.Method + " " + e.Path
+		switch {
+		case !documentedMethods[e.Method]:
+			return fmt.Errorf("unsupported method of %s", route)
+		case !strings.HasPrefix(e.Path, "/"):
+			return fmt.Errorf("path of %s is not absolute", route)
+		case e.ID == "":
+			return fmt.Errorf("%s has no operation ID", route)
+		case ids[e.ID]:
+			return fmt.Errorf("operation ID %s of %s is not unique", e.ID, route)
+		case routes[route]:
+			return fmt.Errorf("%s is declared twice", route)
+		case len(e.Replies) == 0:
+			return fmt.Errorf("%s has no replies", route)
+		}
+		ids[e.ID] = true
+		routes[route] = true
+
+		statuses := make(map[int]bool, len(e.Replies))
+		for _, r := range e.Replies {
+			if http.StatusText(r.Status) == "" {
+				return fmt.Errorf("%s documents invalid status %d", route, r.Status)
+			}
+			if statuses[r.Status] {
+				return fmt.Errorf("%s documents status %d twice", route, r.Status)
+			}
+			statuses[r.Status] = true
+		}
+		params := make(map[string]bool, len(e.Params))
+		for _, p := range e.Params {
+			if p.In != "query" && p.In != "header" {
+				return fmt.Errorf("parameter %s of %s is in %q, not query or header", p.Name,
+					route, p.In)
+			}
+			if p.Name == "" || p.Schema == nil {
+				return fmt.Errorf("parameter %q of %s has no name or schema", p.Name, route)
+			}
+			if params[p.In+" "+p.Name] {
+				return fmt.Errorf("parameter %s of %s is declared twice", p.Name, route)
+			}
+			params[p.In+" "+p.Name] = true
+		}
+	}
+	return nil
+}
+
+// response returns the documented response of r.
+func (g *generator) response(r Reply) Response {
+	resp := Response{Description: r.Description}
+	if resp.Description == "" {
+		resp.Description = http.StatusText(r.Status)
+	}
+	var schema *Schema
+	switch {
+	case r.Body != nil:
+		schema = g.schema(reflect.TypeOf(r.Body))
+	case r.Status >= http.StatusBadRequest:
+		schema = ref(errorSchemaName)
+	}
+	if schema != nil || r.Text {
+		resp.Content = make(map[string]MediaType)
+	}
+	if schema != nil {
+		resp.Content["application/json"] = MediaType{Schema: schema}
+	}
+	if r.Text {
+		resp.Content["text/plain"] = MediaType{Schema: &Schema{Type: "string"}}
+	}
+	return resp
+}
+
+// pathParams converts a gin path (/visits/:id) to an OpenAPI one (/visits/{id}) and returns its
+// parameters.
+func pathParams(ginPath string) (string, []Parameter) {
+	segments := strings.Split(ginPath, "/")
+	var params []Parameter
+	for i, seg := range segments {
+		name, ok := strings.CutPrefix(seg, ":")
+		if !ok {
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{
+			Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
@@ -0,0 +1,228 @@
+package openapi_test
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/openapi"
+)
+
+type testItem struct {
+	ID      string     `json:"id"`
+	Note    string     `json:"note,omitempty"`
+	Parent  *testItem  `json:"parent"`
+	Created time.Time  `json:"created"`
+	Deleted *time.Time `json:"deleted,omitempty"`
+	Size    testSize   `json:"size"`
+	testEmbedded
+	hidden string
+}
+
+type testEmbedded struct {
+	Tags []string `json:"tags"`
+}
+
+// testSize is written as a number.
+type testSize struct{ n int }
+
+func (testSize) JSONType() reflect.Type { return reflect.TypeFor[int]() }
+
+type testBody struct {
+	Name string `json:"name"`
+}
+
+func validEndpoint() openapi.Endpoint {
+	return openapi.Endpoint{
+		Method: http.MethodGet, Path: "/items/:id", ID: "GetItem",
+		Replies: []openapi.Reply{{Status: http.StatusOK}},
+	}
+}
+
+func TestBuildValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(e *openapi.Endpoint)
+		want   string
+	}{
+		{"method", func(e *openapi.Endpoint) { e.Method = "FETCH" }, "unsupported method"},
+		{"relative path", func(e *openapi.Endpoint) { e.Path = "items" }, "not absolute"},
+		{"no ID", func(e *openapi.Endpoint) { e.ID = "" }, "no operation ID"},
+		{"no replies", func(e *openapi.Endpoint) { e.Replies = nil }, "no replies"},
+		{"invalid status", func(e *openapi.Endpoint) {
+			e.Replies = []openapi.Reply{{Status: 42}}
+		}, "invalid status 42"},
+		{"status twice", func(e *openapi.Endpoint) {
+			e.Replies = []openapi.Reply{{Status: http.StatusOK}, {Status: http.StatusOK}}
+		}, "status 200 twice"},
+		{"path param in Params", func(e *openapi.Endpoint) {
+			e.Params = []openapi.Parameter{
+				{Name: "id", In: "path", Schema: &openapi.Schema{Type: "string"}},
+			}
+		}, "not query or header"},
+		{"param without schema", func(e *openapi.Endpoint) {
+			e.Params = []openapi.Parameter{{Name: "q", In: "query"}}
+		}, "no name or schema"},
+		{"param twice", func(e *openapi.Endpoint) {
+			p := openapi.Parameter{Name: "q", In: "query", Schema: &openapi.Schema{Type: "string"}}
+			e.Params = []openapi.Parameter{p, p}
+		}, "declared twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := validEndpoint()
+			tt.modify(&e)
+			_, err := openapi.Build(openapi.Info{}, []openapi.Endpoint{e})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Build error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("duplicates", func(t *testing.T) {
+		e := validEndpoint()
+		other := validEndpoint()
+		other.Method = http.MethodDelete
+		other.ID = e.ID
+		if _, err := openapi.Build(openapi.Info{}, []openapi.Endpoint{e, other}); err == nil ||
+			!strings.Contains(err.Error(), "not unique") {
+			t.Errorf("duplicate ID: err = %v", err)
+		}
+		other.ID = "Other"
+		other.Method = e.Method
+		if _, err := openapi.Build(openapi.Info{}, []openapi.Endpoint{e, other}); err == nil ||
+			!strings.Contains(err.Error(), "declared twice") {
+			t.Errorf("duplicate route: err = %v", err)
+		}
+	})
+}
+
+func TestBuild(t *testing.T) {
+	doc, err := openapi.Build(openapi.Info{Title: "Test", Version: "1.0.0"}, []openapi.Endpoint{
+		{
+			Method: http.MethodGet, Path: "/items/:id", ID: "GetItem", Tag: "items",
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: testItem{}},
+				{Status: http.StatusNotFound},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/items", ID: "PostItem", Public: true,
+			Body:    testBody{},
+			Replies: []openapi.Reply{{Status: http.StatusNoContent}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+
+	get := doc.Paths["/items/{id}"]["get"]
+	if len(get.Parameters) != 1 || get.Parameters[0].In != "path" ||
+		!get.Parameters[0].Required {
+		t.Errorf("GetItem parameters = %+v, want the required path parameter id", get.Parameters)
+	}
+	if len(get.Security) == 0 {
+		t.Error("GetItem has no security requirement")
+	}
+	if ref := get.Responses["404"].Content["application/json"].Schema.Ref; !strings.HasSuffix(
+		ref, "/Error") {
+		t.Errorf("404 schema = %q, want the Error component", ref)
+	}
+
+	item := doc.Components.Schemas["testItem"]
+	if item == nil {
+		t.Fatal("no testItem component")
+	}
+	for name, want := range map[string]string{
+		"id": "string", "note": "string", "size": "integer", "tags": "array",
+	} {
+		if got := item.Properties[name]; got == nil || got.Type != want {
+			t.Errorf("property %s = %+v, want type %s", name, got, want)
+		}
+	}
+	if _, ok := item.Properties["hidden"]; ok {
+		t.Error("unexported field documented")
+	}
+	if ref := item.Properties["created"].Ref; !strings.HasSuffix(ref, "/Time") {
+		t.Errorf("created = %q, want the Time component", ref)
+	}
+	wantRequired := []string{"id", "created", "size", "tags"}
+	if !slices.Equal(item.Required, wantRequired) {
+		t.Errorf("required = %v, want %v", item.Required, wantRequired)
+	}
+
+	post := doc.Paths["/items"]["post"]
+	if post.Security != nil {
+		t.Error("public PostItem has a security requirement")
+	}
+	if post.RequestBody == nil ||
+		post.RequestBody.Content["application/json"].Schema.Properties["name"] == nil {
+		t.Errorf("PostItem request body = %+v, want the inlined testBody", post.RequestBody)
+	}
+
+	requireResolvableRefs(t, doc)
+}
+
+// requireResolvableRefs fails the test when a $ref of doc names no component.
+func requireResolvableRefs(t *testing.T, doc *openapi.Document) {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("encoding document: %v", err)
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("decoding document: %v", err)
+	}
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				name, _ := strings.CutPrefix(ref, "#/components/schemas/")
+				if doc.Components.Schemas[name] == nil {
+					t.Errorf("unresolved $ref %q", ref)
+				}
+			}
+			for _, e := range v {
+				walk(e)
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(v)
+}
+
+func TestDocsPage(t *testing.T) {
+	page := string(openapi.DocsPage)
+	if strings.Contains(page, "https://") {
+		t.Error("docs page loads a remote resource")
+	}
+	for _, name := range []string{"swagger-ui-bundle.js", "swagger-ui.css"} {
+		data, contentType, ok := openapi.DocsAsset(name)
+		if !ok || len(data) == 0 || contentType == "" {
+			t.Fatalf("DocsAsset(%q) = %d bytes, %q, %v", name, len(data), contentType, ok)
+		}
+		sum := sha512.Sum384(data)
+		want := `"` + openapi.DocsAssetsPath + name + `" integrity="sha384-` +
+			base64.StdEncoding.EncodeToString(sum[:]) + `"`
+		if !strings.Contains(page, want) {
+			t.Errorf("docs page lacks %s", want)
+		}
+	}
+	if _, _, ok := openapi.DocsAsset("../docs.go"); ok {
+		t.Error("DocsAsset served a file outside the Swagger UI files")
+	}
+}
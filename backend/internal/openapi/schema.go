@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// timeSchemaName is the component of timestamps, which are written in the format negotiated by
+// the request (see jsontime).
+const timeSchemaName = "Time"
+
+// Schema is a JSON schema in the OpenAPI 3.0 dialect.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// JSONTyper is implemented by types whose JSON form is that of another type, e.g. a wrapper
+// recording whether a request field was sent at all.
+type JSONTyper interface {
+	JSONType() reflect.Type
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	jsonTimeType  = reflect.TypeFor[jsontime.Time]()
+	rawJSONType   = reflect.TypeFor[json.RawMessage]()
+	jsonTyperType = reflect.TypeFor[JSONTyper]()
+)
+
+// generator reflects schemas, collecting named struct types as components.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		schemas: map[string]*Schema{
+			timeSchemaName: {
+				Description: "RFC 3339 string, or Unix seconds when the request's Accept header " +
+					"has time=unix.",
+				OneOf: []*Schema{
+					{Type: "string", Format: "date-time"},
+					{Type: "integer", Format: "int64"},
+				},
+			},
+		},
+		names: make(map[reflect.Type]string),
+	}
+}
+
+// inline returns the schema of t with a named struct type described in place rather than as a
+// component.
+func (g *generator) inline(t reflect.Type) *Schema {
+	t = g.resolve(t)
+	if t.Kind() == reflect.Struct && !isTime(t) {
+		return g.object(t)
+	}
+	return g.schema(t)
+}
+
+// schema returns the schema of t; named struct types are referenced as components.
+func (g *generator) schema(t reflect.Type) *Schema {
+	t = g.resolve(t)
+	if isTime(t) {
+		return ref(timeSchemaName)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t == rawJSONType {
+			return &Schema{}
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return ref(g.component(t))
+	default:
+		// Interfaces may hold any JSON value
+		return &Schema{}
+	}
+}
+
+// resolve dereferences pointers and replaces JSONTyper implementations by their JSON type.
+func (g *generator) resolve(t reflect.Type) reflect.Type {
+	for {
+		switch {
+		case t.Kind() == reflect.Pointer:
+			t = t.Elem()
+		case jsonType(t) != t:
+			t = jsonType(t)
+		default:
+			return t
+		}
+	}
+}
+
+// component returns the component name of the named struct type t, adding its schema first.
+// Names are the Go type names, qualified by package only when two packages use the same one.
+func (g *generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken {
+		pkg := []rune(path.Base(t.PkgPath()))
+		pkg[0] = unicode.ToUpper(pkg[0])
+		name = string(pkg) + name
+	}
+	g.names[t] = name
+	g.schemas[name] = &Schema{} // placeholder for recursive types
+	*g.schemas[name] = *g.object(t)
+	return name
+}
+
+// object returns the schema of the struct type t from its exported fields and json tags, with
+// embedded structs flattened as encoding/json does. Fields without omitempty are required:
+// responses always include them.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := g.resolve(f.Type)
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && !isTime(ft) {
+			g.addFields(s, ft)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		omitempty := strings.Contains(opts, "omitempty")
+		pointer := jsonType(f.Type).Kind() == reflect.Pointer
+		fs := g.schema(f.Type)
+		if pointer && !omitempty && fs.Ref == "" {
+			fs.Nullable = true
+		}
+		s.Properties[name] = fs
+		if !omitempty && !pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// jsonType returns the type t is encoded as: its JSONType if it implements JSONTyper.
+func jsonType(t reflect.Type) reflect.Type {
+	if t.Kind() != reflect.Interface && t.Kind() != reflect.Pointer &&
+		t.Implements(jsonTyperType) {
+		return reflect.Zero(t).Interface().(JSONTyper).JSONType()
+	}
+	return t
+}
+
+func isTime(t reflect.Type) bool {
+	return t == timeType || t == jsonTimeType
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
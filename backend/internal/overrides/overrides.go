@@ -0,0 +1,97 @@
+// Package overrides keeps the country lists of package data in sync with the country overrides
+// stored in Firestore, so admins can add, rename or deprecate countries without a redeploy.
+// Every instance loads the overrides at startup and reloads them periodically; an instance that
+// saves or deletes an override applies it immediately.
+package overrides
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// Store persists country overrides. Implemented by database.Client.
+type Store interface {
+	GetCountryOverrides(ctx context.Context) ([]models.CountryOverride, error)
+	SaveCountryOverride(ctx context.Context, o *models.CountryOverride) error
+	DeleteCountryOverride(ctx context.Context, code string) error
+}
+
+// Refresher loads country overrides from a Store and applies them with data.SetOverrides.
+type Refresher struct {
+	store    Store
+	interval time.Duration
+}
+
+// NewRefresher returns a Refresher that Run reloads every interval.
+func NewRefresher(store Store, interval time.Duration) *Refresher {
+	return &Refresher{store: store, interval: interval}
+}
+
+// Refresh loads all overrides and replaces the country lists with the merged result. On error
+// the current lists are kept.
+func (r *Refresher) Refresh(ctx context.Context) error {
+	ctx, span := tracing.New(ctx, "overrides.Refresh")
+	defer span.End()
+
+	overrides, err := r.store.GetCountryOverrides(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load country overrides: %w", err)
+	}
+	previous := data.Version()
+	if skipped := data.SetOverrides(overrides); len(skipped) > 0 {
+		logging.FromContext(ctx).Warn("Skipped country overrides without name or region",
+			logging.CountryCode, strings.Join(skipped, ","))
+	}
+	if version := data.Version(); version != previous {
+		logging.FromContext(ctx).Info("Applied country overrides",
+			logging.Count, len(overrides), "countries_version", version)
+	}
+	return nil
+}
+
+// Run calls Refresh every interval until ctx is cancelled. Failures are logged and the
+// previous lists stay in use until the next successful refresh.
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				logging.FromContext(ctx).Error("Country overrides refresh failed", logging.Error, err)
+			}
+		}
+	}
+}
+
+// List returns the stored overrides, including any this instance has not applied yet.
+func (r *Refresher) List(ctx context.Context) ([]models.CountryOverride, error) {
+	return r.store.GetCountryOverrides(ctx)
+}
+
+// Save stores o, stamping UpdatedAt, and refreshes this instance's lists.
+func (r *Refresher) Save(ctx context.Context, o *models.CountryOverride) error {
+	o.UpdatedAt = time.Now().UTC()
+	if err := r.store.SaveCountryOverride(ctx, o); err != nil {
+		return err
+	}
+	return r.Refresh(ctx)
+}
+
+// Delete removes the override of code and refreshes this instance's lists. Store errors (such
+// as database.ErrCountryOverrideNotFound) are returned unwrapped.
+func (r *Refresher) Delete(ctx context.Context, code string) error {
+	if err := r.store.DeleteCountryOverride(ctx, code); err != nil {
+		return err
+	}
+	return r.Refresh(ctx)
+}
@@ -0,0 +1,92 @@
+// Package proofs stores the files attached to visits as proof (boarding passes, passport
+// stamps) in a Cloud Storage bucket. Objects are private to the bucket; the API serves them to
+// the visit owner only.
+package proofs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+	storage "google.golang.org/api/storage/v1"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// ErrNotFound is returned by Get for an object that does not exist.
+var ErrNotFound = errors.New("proof not found")
+
+// Store keeps proof files by object name.
+type Store interface {
+	Put(ctx context.Context, name, contentType string, data []byte) error
+	Get(ctx context.Context, name string) (data []byte, contentType string, err error)
+	Delete(ctx context.Context, name string) error
+}
+
+// ObjectName returns the name of the stored file of a proof.
+func ObjectName(userID, visitID, proofID string) string {
+	return "proofs/" + userID + "/" + visitID + "/" + proofID
+}
+
+// GCSStore is a Store backed by a Cloud Storage bucket.
+type GCSStore struct {
+	service *storage.Service
+	bucket  string
+}
+
+// NewGCSStore returns a GCSStore for bucket using application default credentials.
+func NewGCSStore(ctx context.Context, bucket string) (*GCSStore, error) {
+	service, err := storage.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage service: %w", err)
+	}
+	return &GCSStore{service: service, bucket: bucket}, nil
+}
+
+// Put implements Store.
+func (g *GCSStore) Put(ctx context.Context, name, contentType string, data []byte) error {
+	obj := &storage.Object{Name: name, ContentType: contentType}
+	_, err := g.service.Objects.Insert(g.bucket, obj).
+		Media(bytes.NewReader(data)).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return fmt.Errorf("failed to write proof: %w", err)
+	}
+	return nil
+}
+
+// Get implements Store.
+func (g *GCSStore) Get(ctx context.Context, name string) ([]byte, string, error) {
+	resp, err := g.service.Objects.Get(g.bucket, name).Context(ctx).Download()
+	if err != nil {
+		if isNotFound(err) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", fmt.Errorf("failed to read proof: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, models.MaxProofBytes))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read proof: %w", err)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// Delete implements Store. Deleting a missing object is not an error.
+func (g *GCSStore) Delete(ctx context.Context, name string) error {
+	err := g.service.Objects.Delete(g.bucket, name).Context(ctx).Do()
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete proof: %w", err)
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
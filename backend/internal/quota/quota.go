@@ -0,0 +1,92 @@
+// Package quota limits request rates per client key (such as an IP address) with one token
+// bucket per key. Buckets that stay idle are dropped, so memory is bounded by the number of
+// recently active clients.
+package quota
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// idleAfter is how long a bucket may go unused before it is dropped; a dropped bucket is full
+// again, which an idle client's bucket would be by then anyway.
+const idleAfter = 10 * time.Minute
+
+// Limiter allows perMinute requests per key on average, with bursts of up to a minute's worth.
+type Limiter struct {
+	perMinute float64
+
+	// limited counts the refused requests since New.
+	limited atomic.Int64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// New returns a Limiter allowing perMinute requests per key (must be positive).
+func New(perMinute float64) *Limiter {
+	return &Limiter{
+		perMinute: perMinute,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// PerMinute returns the configured requests per minute per key.
+func (l *Limiter) PerMinute() float64 {
+	return l.perMinute
+}
+
+// Allow takes a token from the bucket of key. When none is left it returns false and how long
+// until the next one is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleAfter {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > idleAfter {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		burst := max(1, int(l.perMinute))
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(l.perMinute/60), burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		l.limited.Add(1)
+		return false, delay
+	}
+	return true, 0
+}
+
+// Limited returns how many requests Allow refused since New.
+func (l *Limiter) Limited() int64 {
+	return l.limited.Load()
+}
+
+// Reset refills the bucket of key, e.g. after the client proved it is not a bot.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// A dropped bucket is recreated full
+	delete(l.buckets, key)
+}
@@ -0,0 +1,113 @@
+// Package residency implements the data residency mode (DATA_RESIDENCY): the regions where the
+// app may store and process user data. In a restricted mode the app refuses to start when
+// Firestore or a configured bucket is located elsewhere.
+package residency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admin "cloud.google.com/go/firestore/apiv1/admin"
+	"cloud.google.com/go/firestore/apiv1/admin/adminpb"
+	storage "google.golang.org/api/storage/v1"
+)
+
+// Mode is a data residency mode.
+type Mode string
+
+const (
+	// ModeNone places no restriction on data locations.
+	ModeNone Mode = ""
+
+	// ModeEU keeps user data within the European Union.
+	ModeEU Mode = "eu"
+)
+
+// ParseMode returns the Mode named by s (case-insensitive; empty for ModeNone).
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case ModeNone, ModeEU:
+		return m, nil
+	}
+	return ModeNone, fmt.Errorf("unknown data residency mode %q (valid: %s)", s, ModeEU)
+}
+
+// Restricted reports whether m limits data locations.
+func (m Mode) Restricted() bool {
+	return m != ModeNone
+}
+
+// Allows reports whether data may be stored in the Firestore or Cloud Storage location loc,
+// e.g. "eur3", "europe-north1" or "EU".
+func (m Mode) Allows(loc string) bool {
+	loc = strings.ToLower(loc)
+	switch m {
+	case ModeNone:
+		return true
+	case ModeEU:
+		if nonEULocations[loc] {
+			return false
+		}
+		return loc == "eu" || strings.HasPrefix(loc, "eur")
+	}
+	return false
+}
+
+// nonEULocations are the "europe-*" regions and "eur*" dual-regions with data outside the
+// European Union.
+var nonEULocations = map[string]bool{
+	"europe-west2": true, // London
+	"europe-west6": true, // Zurich
+	"eur7":         true, // London and Frankfurt
+	"eur8":         true, // Frankfurt and Zurich
+}
+
+// Check returns an error unless the default Firestore database of projectID and every named
+// bucket are in locations allowed by m. Empty bucket names are skipped.
+func Check(ctx context.Context, m Mode, projectID string, buckets ...string) error {
+	if !m.Restricted() {
+		return nil
+	}
+	loc, err := firestoreLocation(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if !m.Allows(loc) {
+		return fmt.Errorf("firestore database location %s is not allowed in %s mode", loc, m)
+	}
+	var service *storage.Service
+	for _, bucket := range buckets {
+		if bucket == "" {
+			continue
+		}
+		if service == nil {
+			if service, err = storage.NewService(ctx); err != nil {
+				return fmt.Errorf("failed to create storage service: %w", err)
+			}
+		}
+		b, err := service.Buckets.Get(bucket).Fields("location").Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to get location of bucket %s: %w", bucket, err)
+		}
+		if !m.Allows(b.Location) {
+			return fmt.Errorf("bucket %s location %s is not allowed in %s mode", bucket, b.Location, m)
+		}
+	}
+	return nil
+}
+
+func firestoreLocation(ctx context.Context, projectID string) (string, error) {
+	client, err := admin.NewFirestoreAdminClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create firestore admin client: %w", err)
+	}
+	defer client.Close()
+	db, err := client.GetDatabase(ctx, &adminpb.GetDatabaseRequest{
+		Name: "projects/" + projectID + "/databases/(default)",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get firestore database: %w", err)
+	}
+	return db.GetLocationId(), nil
+}
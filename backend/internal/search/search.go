@@ -0,0 +1,153 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/textnorm"
+)
+
+// MaxQueryLength is the maximum accepted length (in bytes) of a search query.
+const MaxQueryLength = 100
+
+// Field weights: a match in a more specific field ranks higher.
+const (
+	weightCountryExact  = 10
+	weightCountryPrefix = 6
+	weightCountryWord   = 4
+	weightTagExact      = 5
+	weightTagPrefix     = 3
+	weightNotes         = 2
+)
+
+// Visits ranks visits against query using an index built in memory for this call.
+// Every query term must match at least one field (country name or code, tags, notes);
+// results are ordered by score, then most recent visit first.
+func Visits(visits []models.CountryVisit, query string) []models.VisitSearchResult {
+	terms := tokenize(query)
+	results := []models.VisitSearchResult{}
+	if len(terms) == 0 {
+		return results
+	}
+	for _, v := range visits {
+		doc := newDocument(v)
+		total := 0
+		matched := map[string]struct{}{}
+		ok := true
+		for _, term := range terms {
+			score, fields := doc.match(term)
+			if score == 0 {
+				ok = false
+				break
+			}
+			total += score
+			for _, f := range fields {
+				matched[f] = struct{}{}
+			}
+		}
+		if !ok {
+			continue
+		}
+		fields := make([]string, 0, len(matched))
+		for f := range matched {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		results = append(results, models.VisitSearchResult{Visit: v, Score: total, MatchedFields: fields})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Visit.VisitedTime.After(results[j].Visit.VisitedTime)
+	})
+	return results
+}
+
+// document holds the normalized searchable text of one visit.
+type document struct {
+	countryCode  string
+	countryNames []string
+	countryWords []string
+	tags         []string
+	notesWords   []string
+}
+
+func newDocument(v models.CountryVisit) document {
+	d := document{
+		countryCode: strings.ToLower(v.CountryCode),
+		notesWords:  tokenize(v.Notes),
+	}
+	for _, name := range data.CountryNames(v.CountryCode) {
+		d.countryNames = append(d.countryNames, normalize(name))
+		d.countryWords = append(d.countryWords, tokenize(name)...)
+	}
+	for _, t := range v.Tags {
+		d.tags = append(d.tags, normalize(t))
+	}
+	return d
+}
+
+// match scores a single term against the document and returns the matched field names.
+func (d document) match(term string) (int, []string) {
+	score := 0
+	var fields []string
+	switch {
+	case term == d.countryCode || contains(d.countryNames, term):
+		score += weightCountryExact
+		fields = append(fields, "country")
+	case containsPrefix(d.countryNames, term):
+		score += weightCountryPrefix
+		fields = append(fields, "country")
+	case containsPrefix(d.countryWords, term):
+		score += weightCountryWord
+		fields = append(fields, "country")
+	}
+	for _, t := range d.tags {
+		if t == term {
+			score += weightTagExact
+			fields = append(fields, "tags")
+			break
+		}
+		if strings.HasPrefix(t, term) {
+			score += weightTagPrefix
+			fields = append(fields, "tags")
+			break
+		}
+	}
+	if containsPrefix(d.notesWords, term) {
+		score += weightNotes
+		fields = append(fields, "notes")
+	}
+	return score, fields
+}
+
+func contains(words []string, term string) bool {
+	for _, w := range words {
+		if w == term {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPrefix(words []string, term string) bool {
+	for _, w := range words {
+		if strings.HasPrefix(w, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize folds s for matching: lowercase, diacritics removed, letters transliterated.
+func normalize(s string) string {
+	return textnorm.Fold(s)
+}
+
+// tokenize splits the folded form of s into words.
+func tokenize(s string) []string {
+	return textnorm.Words(s)
+}
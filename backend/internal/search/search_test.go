@@ -0,0 +1,76 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func TestVisitsNormalizesQueries(t *testing.T) {
+	visits := []models.CountryVisit{
+		{ID: "ci", CountryCode: "CI", Tags: []string{"Plage"}},
+		{ID: "tr", CountryCode: "TR", Notes: "İstanbul in the rain"},
+		{ID: "at", CountryCode: "AT", Tags: []string{"Ski-Urlaub"}, Notes: "Großglockner"},
+		{ID: "fi", CountryCode: "FI", Notes: "Saunas, lakes"},
+	}
+	tests := []struct {
+		query string
+		want  []string // IDs in result order
+	}{
+		{"Côte d’Ivoire", []string{"ci"}},
+		{"cote divoire", []string{"ci"}},
+		{"COTE D'IVOIRE", []string{"ci"}},
+		{"turkiye", []string{"tr"}},
+		{"TÜRKİYE", []string{"tr"}},
+		{"istanbul", []string{"tr"}},
+		{"österreich", []string{"at"}},
+		{"Osterreich", []string{"at"}},
+		{"grossglockner", []string{"at"}},
+		{"SKI", []string{"at"}},
+		{"  suomi\t", []string{"fi"}},
+		{"FI", []string{"fi"}},
+		{"lakes!", []string{"fi"}},
+		{"plage   cote", []string{"ci"}},
+		{"", nil},
+		{" -- ", nil},
+		{"atlantis", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := Visits(visits, tt.query)
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Visit.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Visits(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Visits(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestVisitsRanking(t *testing.T) {
+	jan := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	visits := []models.CountryVisit{
+		{ID: "notes", CountryCode: "SE", Notes: "Finland ferry", VisitedTime: jan},
+		{ID: "old", CountryCode: "FI", VisitedTime: jan},
+		{ID: "new", CountryCode: "FI", VisitedTime: jan.AddDate(1, 0, 0)},
+		{ID: "tag", CountryCode: "NO", Tags: []string{"finland-trip"}, VisitedTime: jan},
+	}
+	results := Visits(visits, "finland")
+	want := []string{"new", "old", "tag", "notes"}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, r := range results {
+		if r.Visit.ID != want[i] {
+			t.Errorf("result %d = %s (score %d), want %s", i, r.Visit.ID, r.Score, want[i])
+		}
+	}
+}
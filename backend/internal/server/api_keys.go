@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// apiKeyHeader carries an API key (models.APIKey) in place of an Authorization header.
+const apiKeyHeader = "X-Api-Key"
+
+// apiKeyTouchInterval limits how often a key's LastUsedAt is written: at most one write per key
+// per interval, not one per request.
+const apiKeyTouchInterval = time.Hour
+
+// apiKeyUser returns the owner of API key as the current user, with the profile fields of their
+// User document since there are no token claims. Otherwise it writes 401 (unknown key or owner)
+// or 500 and returns false.
+func (s *Server) apiKeyUser(ctx context.Context, c *gin.Context, key string) (*models.User, bool) {
+	log := logging.FromContext(ctx)
+	if !auth.LooksLikeAPIKey(key) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+		return nil, false
+	}
+	apiKey, err := s.db.GetAPIKeyByHash(ctx, auth.HashAPIKey(key))
+	if err != nil {
+		log.Error("GetAPIKeyByHash failed", logging.Error, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError,
+			gin.H{"error": "failed to verify API key"})
+		return nil, false
+	}
+	if apiKey == nil {
+		log.Warn("Unknown API key refused", "prefix", key[:min(len(key), 9)])
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+		return nil, false
+	}
+	dbUser, err := s.db.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		log.Error("GetUserByID failed", logging.UserID, apiKey.UserID, logging.Error, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError,
+			gin.H{"error": "failed to verify API key"})
+		return nil, false
+	}
+	if dbUser == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+		return nil, false
+	}
+
+	now := time.Now().UTC()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.db.TouchAPIKey(ctx, apiKey.UserID, apiKey.ID, now); err != nil {
+			log.Warn("TouchAPIKey failed", logging.Error, err)
+		}
+	}
+	return &models.User{
+		ID:       dbUser.ID,
+		UserID:   dbUser.ID,
+		Name:     dbUser.Name,
+		Email:    dbUser.Email,
+		ImageURL: dbUser.ImageURL,
+		APIKeyID: apiKey.ID,
+	}, true
+}
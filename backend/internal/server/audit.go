@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// auditRejectionsPerMinute limits the rejected tokens recorded per user, so that replaying a
+// stolen or forged token cannot flood the user's audit log.
+const auditRejectionsPerMinute = 1
+
+// Details of models.AuditTokenRejected events.
+const (
+	auditDetailInvalidToken = "invalid"
+	auditDetailRevokedToken = "revoked"
+)
+
+// recordAudit adds an event of eventType to the audit log of userID with the client of c.
+// The client IP is recorded only with trusted proxies configured: without them it is the
+// address of whatever proxy is in front, which would mislead the user reading the log.
+// Auditing is best effort: failures are logged and the request goes on.
+func (s *Server) recordAudit(
+	ctx context.Context,
+	c *gin.Context,
+	userID, eventType, detail string,
+) {
+	event := &models.AuditEvent{
+		Type:      eventType,
+		Time:      time.Now().UTC(),
+		UserAgent: c.Request.UserAgent(),
+		Detail:    detail,
+	}
+	if len(s.trustedProxies) > 0 {
+		event.IP = c.ClientIP()
+	}
+	if err := s.db.RecordAuditEvent(ctx, userID, event); err != nil {
+		logging.FromContext(ctx).Error("Failed to record audit event", logging.UserID, userID,
+			"auditType", eventType, logging.Error, err)
+	}
+}
+
+// recordTokenRejected records a models.AuditTokenRejected event for the user the rejected
+// token claims to be: userID for verified claims or the unverified subject of token otherwise.
+// Only existing users get the event, at most auditRejectionsPerMinute per user.
+func (s *Server) recordTokenRejected(
+	ctx context.Context,
+	c *gin.Context,
+	userID, token, detail string,
+) {
+	if userID == "" {
+		userID = auth.UnverifiedSubject(token)
+	}
+	if userID == "" {
+		return
+	}
+	if ok, _ := s.auditRejections.Allow(userID); !ok {
+		return
+	}
+	u, err := s.db.GetUserByID(ctx, userID)
+	if err != nil || u == nil {
+		return
+	}
+	s.recordAudit(ctx, c, userID, models.AuditTokenRejected, detail)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func TestAuditClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		wantIP  string
+	}{
+		{name: "no trusted proxies", wantIP: ""},
+		{name: "trusted proxy", proxies: []string{"192.0.2.0/24"}, wantIP: "203.0.113.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, db := newTestServer(t, WithTrustedProxies(tt.proxies))
+
+			w := doAs(t, s, "u1", http.MethodPost, "/login", "",
+				"X-Forwarded-For", "203.0.113.1")
+			requireStatus(t, w, http.StatusOK)
+			events, err := db.GetAuditEvents(context.Background(), "u1", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 1 || events[0].Type != models.AuditLogin {
+				t.Fatalf("events = %+v, want one login", events)
+			}
+			if events[0].IP != tt.wantIP {
+				t.Errorf("IP = %q, want %q", events[0].IP, tt.wantIP)
+			}
+		})
+	}
+}
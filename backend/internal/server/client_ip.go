@@ -0,0 +1,30 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// WithTrustedProxies sets the addresses or CIDR ranges of the reverse proxies in front of the
+// server (see config.Config.TrustedProxies). c.ClientIP, which keys the per-IP quotas and audit
+// events, then walks X-Forwarded-For from the right past these proxies. Without it the client
+// IP is the peer address, so a forged X-Forwarded-For cannot pick a fresh quota bucket.
+func WithTrustedProxies(proxies []string) Option {
+	return func(s *Server) {
+		s.trustedProxies = proxies
+	}
+}
+
+// trustProxies makes router take the client IP only from X-Forwarded-For entries appended by
+// proxies; with none, from the peer address.
+func trustProxies(router *gin.Engine, proxies []string) error {
+	// X-Real-IP is set by some proxies but, unlike X-Forwarded-For, cannot be walked past them
+	router.RemoteIPHeaders = []string{"X-Forwarded-For"}
+	router.ForwardedByClientIP = len(proxies) > 0
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		// Trust nothing rather than everything
+		_ = router.SetTrustedProxies(nil)
+		router.ForwardedByClientIP = false
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestForgedForwardedForDoesNotResetQuota(t *testing.T) {
+	s, _ := newTestServer(t, WithCountriesAccess(nil, 1))
+
+	requireStatus(t, do(t, s, http.MethodGet, "/countries", "",
+		"X-Forwarded-For", "203.0.113.1"), http.StatusOK)
+	w := do(t, s, http.MethodGet, "/countries", "", "X-Forwarded-For", "203.0.113.2")
+	requireStatus(t, w, http.StatusTooManyRequests)
+}
+
+func TestTrustedProxyForwardedFor(t *testing.T) {
+	// The test requests come from 192.0.2.1, here a trusted proxy appending the client IP
+	s, _ := newTestServer(t, WithCountriesAccess(nil, 1),
+		WithTrustedProxies([]string{"192.0.2.0/24"}))
+
+	requireStatus(t, do(t, s, http.MethodGet, "/countries", "",
+		"X-Forwarded-For", "203.0.113.1"), http.StatusOK)
+	requireStatus(t, do(t, s, http.MethodGet, "/countries", "",
+		"X-Forwarded-For", "203.0.113.2"), http.StatusOK)
+	// A client's forged entry left of the one the proxy appended is ignored
+	requireStatus(t, do(t, s, http.MethodGet, "/countries", "",
+		"X-Forwarded-For", "198.51.100.9, 203.0.113.1"), http.StatusTooManyRequests)
+}
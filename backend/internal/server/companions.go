@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// validateCompanions dedupes companions and checks each is the ShareToken of one of friends.
+// Tokens in keep are accepted even when no longer a friend, so a PUT that resends a visit's
+// existing companions does not fail after a friend was removed.
+func validateCompanions(
+	companions []string,
+	friends []models.Friend,
+	keep []string,
+) ([]string, error) {
+	out := models.DedupeTagsPreserveOrder(companions)
+	if len(out) > models.MaxCompanionsPerVisit {
+		return nil, fmt.Errorf("at most %d companions allowed", models.MaxCompanionsPerVisit)
+	}
+	allowed := make(map[string]struct{}, len(friends)+len(keep))
+	for _, f := range friends {
+		allowed[f.ShareToken] = struct{}{}
+	}
+	for _, t := range keep {
+		allowed[t] = struct{}{}
+	}
+	for _, t := range out {
+		if _, ok := allowed[t]; !ok {
+			return nil, fmt.Errorf("companion %q is not in your friends list", t)
+		}
+	}
+	return out, nil
+}
+
+// attachCompanionFriends sets CompanionFriends on each visit from friends, in Companions order.
+func attachCompanionFriends(visits []models.CountryVisit, friends []models.Friend) {
+	byToken := make(map[string]models.Friend, len(friends))
+	for _, f := range friends {
+		byToken[f.ShareToken] = f
+	}
+	for i := range visits {
+		visits[i].CompanionFriends = nil
+		for _, t := range visits[i].Companions {
+			if f, ok := byToken[t]; ok {
+				visits[i].CompanionFriends = append(visits[i].CompanionFriends, f)
+			}
+		}
+	}
+}
+
+// attachCompanionFriendsTo is attachCompanionFriends for a single visit.
+func attachCompanionFriendsTo(visit *models.CountryVisit, friends []models.Friend) {
+	visits := []models.CountryVisit{*visit}
+	attachCompanionFriends(visits, friends)
+	visit.CompanionFriends = visits[0].CompanionFriends
+}
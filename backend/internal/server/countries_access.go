@@ -0,0 +1,75 @@
+package server
+
+import (
+	"crypto/subtle"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/quota"
+)
+
+// WithCountriesAccess configures anonymous access to the /countries routes. When appTokens is
+// non-empty, anonymous requests must send one of them in X-App-Token. When anonPerMinute is
+// positive, anonymous requests are limited to that many per minute per client IP. Requests
+// with a valid ID token skip both checks, so scraping cannot exhaust a quota signed-in users
+// depend on.
+func WithCountriesAccess(appTokens []string, anonPerMinute float64) Option {
+	return func(s *Server) {
+		s.countriesAppTokens = appTokens
+		if anonPerMinute > 0 {
+			s.countriesQuota = quota.New(anonPerMinute)
+		}
+	}
+}
+
+// countriesAccessMiddleware guards the public /countries routes. A request with a valid
+// Authorization: Bearer token or session cookie is treated as authenticated (the user is put in
+// context); any other request, including one with an invalid token, is anonymous and must pass
+// the app token check (401) and the per-IP anonymous quota (429 with Retry-After).
+func (s *Server) countriesAccessMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		log := logging.FromContext(ctx)
+		if claims := s.optionalClaims(ctx, c); claims != nil {
+			ctx = ctxkeys.WithCurrentUser(ctx, auth.UserFromClaims(claims))
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		if len(s.countriesAppTokens) > 0 && !s.validAppToken(c.GetHeader("X-App-Token")) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "app token required"})
+			return
+		}
+		if s.countriesQuota != nil {
+			ip := c.ClientIP()
+			if ok, retryAfter := s.countriesQuota.Allow(ip); !ok {
+				log.Warn("Anonymous countries quota exceeded", "client_ip", ip)
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				c.Header("Retry-After", strconv.Itoa(seconds))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+					"error": "anonymous quota exceeded; retry later or sign in",
+				})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// validAppToken reports whether token is one of the configured app tokens, in constant time.
+func (s *Server) validAppToken(token string) bool {
+	valid := false
+	for _, t := range s.countriesAppTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
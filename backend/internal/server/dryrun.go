@@ -0,0 +1,871 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// isDryRun reports whether the request in ctx asked for ?dryRun=true.
+func isDryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(ctxkeys.DryRunKey).(bool)
+	return v
+}
+
+// dryRunMiddleware parses the dryRun query parameter. When true, the flag is stored in context,
+// the request logger gets a dry_run label and the response carries X-Dry-Run: true.
+// Handlers run unchanged (full validation and authorization); dryRunDatabase skips the writes.
+func (s *Server) dryRunMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.Query("dryRun")
+		if raw == "" {
+			c.Next()
+			return
+		}
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "dryRun must be true or false"})
+			return
+		}
+		if !dryRun {
+			c.Next()
+			return
+		}
+		ctx := context.WithValue(c.Request.Context(), ctxkeys.DryRunKey, true)
+		ctx = logging.WithContext(ctx, logging.FromContext(ctx).WithParams(logging.DryRun, true))
+		c.Request = c.Request.WithContext(ctx)
+		c.Header("X-Dry-Run", "true")
+		c.Next()
+	}
+}
+
+// dryRunDatabase decorates a Database so that write methods become no-ops returning the
+// would-be result when the request is a dry run. Reads always pass through. Writes still
+// perform the reads needed to report the same errors (not found, conflict) as a real write.
+// Every write method added to Database must be overridden here.
+type dryRunDatabase struct {
+	Database
+}
+
+func (d dryRunDatabase) EnsureUser(ctx context.Context, user *models.User) error {
+	if isDryRun(ctx) {
+		return nil
+	}
+	return d.Database.EnsureUser(ctx, user)
+}
+
+func (d dryRunDatabase) UpdateUserSettings(
+	ctx context.Context,
+	userID string,
+	settings models.UserSettings,
+) error {
+	if !isDryRun(ctx) {
+		return d.Database.UpdateUserSettings(ctx, userID, settings)
+	}
+	return d.requireUser(ctx, userID)
+}
+
+// requireUser returns database.ErrUserNotFound, as user document updates do, when userID has
+// no User document.
+func (d dryRunDatabase) requireUser(ctx context.Context, userID string) error {
+	u, err := d.Database.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	return nil
+}
+
+func (d dryRunDatabase) SetSharingDisabled(
+	ctx context.Context,
+	userID string,
+	disabled bool,
+) error {
+	if !isDryRun(ctx) {
+		return d.Database.SetSharingDisabled(ctx, userID, disabled)
+	}
+	return d.requireUser(ctx, userID)
+}
+
+func (d dryRunDatabase) SetTokensRevokedAt(
+	ctx context.Context,
+	userID string,
+	at time.Time,
+) error {
+	if !isDryRun(ctx) {
+		return d.Database.SetTokensRevokedAt(ctx, userID, at)
+	}
+	return d.requireUser(ctx, userID)
+}
+
+func (d dryRunDatabase) SetUserLimits(
+	ctx context.Context,
+	userID string,
+	limits *models.UserLimits,
+) error {
+	if !isDryRun(ctx) {
+		return d.Database.SetUserLimits(ctx, userID, limits)
+	}
+	return d.requireUser(ctx, userID)
+}
+
+// RecordAuditEvent records nothing in a dry run: the audited change did not happen.
+func (d dryRunDatabase) RecordAuditEvent(
+	ctx context.Context,
+	userID string,
+	event *models.AuditEvent,
+) error {
+	if isDryRun(ctx) {
+		return nil
+	}
+	return d.Database.RecordAuditEvent(ctx, userID, event)
+}
+
+func (d dryRunDatabase) MergeGuestUser(
+	ctx context.Context,
+	guestUserID, userID string,
+	now time.Time,
+) (int, error) {
+	if !isDryRun(ctx) {
+		return d.Database.MergeGuestUser(ctx, guestUserID, userID, now)
+	}
+	if err := d.requireUser(ctx, userID); err != nil {
+		return 0, err
+	}
+	guest, err := d.Database.GetUserByID(ctx, guestUserID)
+	if err != nil {
+		return 0, err
+	}
+	if guest == nil {
+		return 0, database.ErrUserNotFound
+	}
+	if !guest.IsAnonymous {
+		return 0, database.ErrNotGuest
+	}
+	visits, err := d.Database.GetCountryVisitsByUser(ctx, guestUserID)
+	if err != nil {
+		return 0, err
+	}
+	return len(visits), nil
+}
+
+func (d dryRunDatabase) MergeUsers(
+	ctx context.Context,
+	fromUserID, toUserID string,
+	now time.Time,
+) (*models.AccountMergeResult, error) {
+	if !isDryRun(ctx) {
+		return d.Database.MergeUsers(ctx, fromUserID, toUserID, now)
+	}
+	from, err := d.Database.GetUserByID(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := d.Database.GetUserByID(ctx, toUserID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, database.ErrUserNotFound
+	}
+	// Counts what the merge would consider; duplicates it would skip are included
+	res := &models.AccountMergeResult{}
+	visits, err := d.Database.GetCountryVisitsByUser(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	friends, err := d.Database.GetFriendsByUser(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := d.Database.GetFollowers(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := d.Database.GetBlockedUsers(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := d.Database.GetShareLinks(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := d.Database.GetAPIKeys(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+	res.Visits, res.Friends, res.Followers = len(visits), len(friends), len(followers)
+	res.BlockedUsers, res.ShareLinks, res.APIKeys = len(blocked), len(links), len(keys)
+	if to.Handle == "" {
+		res.Handle = from.Handle
+	}
+	return res, nil
+}
+
+func (d dryRunDatabase) SetHandle(ctx context.Context, userID, handle string) error {
+	if !isDryRun(ctx) {
+		return d.Database.SetHandle(ctx, userID, handle)
+	}
+	if err := d.requireUser(ctx, userID); err != nil {
+		return err
+	}
+	owner, err := d.Database.GetUserByHandle(ctx, handle)
+	if err != nil {
+		return err
+	}
+	if owner != nil && owner.ID != userID {
+		return database.ErrHandleTaken
+	}
+	return nil
+}
+
+func (d dryRunDatabase) DeleteHandle(ctx context.Context, userID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteHandle(ctx, userID)
+	}
+	u, err := d.Database.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return database.ErrUserNotFound
+	}
+	if u.Handle == "" {
+		return database.ErrHandleNotFound
+	}
+	return nil
+}
+
+func (d dryRunDatabase) RecordShareView(
+	ctx context.Context,
+	userID, token string,
+	now time.Time,
+) error {
+	if isDryRun(ctx) {
+		return nil
+	}
+	return d.Database.RecordShareView(ctx, userID, token, now)
+}
+
+func (d dryRunDatabase) CreateShareLink(
+	ctx context.Context,
+	link *models.ShareLink,
+) (*models.ShareLink, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateShareLink(ctx, link)
+	}
+	existing, err := d.Database.GetShareLinks(ctx, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxShareLinks {
+		return nil, database.ErrTooManyShareLinks
+	}
+	out := *link
+	out.Token = uuid.New().String()
+	out.CreatedAt = time.Now().UTC()
+	return &out, nil
+}
+
+func (d dryRunDatabase) CountShareLinkView(
+	ctx context.Context,
+	userID, token string,
+	now time.Time,
+) error {
+	if isDryRun(ctx) {
+		return nil
+	}
+	return d.Database.CountShareLinkView(ctx, userID, token, now)
+}
+
+func (d dryRunDatabase) DeleteShareLink(ctx context.Context, userID, token string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteShareLink(ctx, userID, token)
+	}
+	links, err := d.Database.GetShareLinks(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		if l.Token == token {
+			return nil
+		}
+	}
+	return database.ErrShareLinkNotFound
+}
+
+func (d dryRunDatabase) CreateAPIKey(
+	ctx context.Context,
+	key *models.APIKey,
+) (*models.APIKey, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateAPIKey(ctx, key)
+	}
+	existing, err := d.Database.GetAPIKeys(ctx, key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxAPIKeys {
+		return nil, database.ErrTooManyAPIKeys
+	}
+	out := *key
+	out.ID = uuid.New().String()
+	out.CreatedAt = time.Now().UTC()
+	return &out, nil
+}
+
+func (d dryRunDatabase) DeleteAPIKey(ctx context.Context, userID, keyID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteAPIKey(ctx, userID, keyID)
+	}
+	keys, err := d.Database.GetAPIKeys(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k.ID == keyID {
+			return nil
+		}
+	}
+	return database.ErrAPIKeyNotFound
+}
+
+func (d dryRunDatabase) TouchAPIKey(ctx context.Context, userID, keyID string, now time.Time) error {
+	if isDryRun(ctx) {
+		return nil
+	}
+	return d.Database.TouchAPIKey(ctx, userID, keyID, now)
+}
+
+func (d dryRunDatabase) RotateShareToken(
+	ctx context.Context,
+	userID string,
+	removeFromFriends bool,
+) (string, int, error) {
+	if !isDryRun(ctx) {
+		return d.Database.RotateShareToken(ctx, userID, removeFromFriends)
+	}
+	if err := d.requireUser(ctx, userID); err != nil {
+		return "", 0, err
+	}
+	removed := 0
+	if removeFromFriends {
+		followers, err := d.Database.GetFollowers(ctx, userID)
+		if err != nil {
+			return "", 0, err
+		}
+		removed = len(followers)
+	}
+	return uuid.New().String(), removed, nil
+}
+
+func (d dryRunDatabase) ScheduleAccountDeletion(
+	ctx context.Context,
+	userID string,
+	at time.Time,
+) error {
+	if !isDryRun(ctx) {
+		return d.Database.ScheduleAccountDeletion(ctx, userID, at)
+	}
+	return d.requireUser(ctx, userID)
+}
+
+func (d dryRunDatabase) CancelAccountDeletion(ctx context.Context, userID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.CancelAccountDeletion(ctx, userID)
+	}
+	return d.requireUser(ctx, userID)
+}
+
+func (d dryRunDatabase) CreateCountryVisit(
+	ctx context.Context,
+	visit *models.CountryVisit,
+	maxVisits int,
+) (*models.CountryVisit, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateCountryVisit(ctx, visit, maxVisits)
+	}
+	u, err := d.Database.GetUserByID(ctx, visit.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u != nil {
+		limits := u.Limits.Or(models.UserLimits{MaxVisits: maxVisits})
+		if limits.MaxVisits > 0 && u.VisitCount >= int64(limits.MaxVisits) {
+			return nil, database.NewLimitError(database.ErrVisitLimitReached, u.ID,
+				limits.MaxVisits)
+		}
+	}
+	out := *visit
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	updatedAt := models.NextVisitUpdatedAt(nil, time.Now())
+	out.UpdatedAt = &updatedAt
+	return &out, nil
+}
+
+func (d dryRunDatabase) ReplaceCountryVisit(
+	ctx context.Context,
+	visit *models.CountryVisit,
+	unmodifiedSince time.Time,
+) error {
+	if !isDryRun(ctx) {
+		return d.Database.ReplaceCountryVisit(ctx, visit, unmodifiedSince)
+	}
+	before, err := d.Database.GetCountryVisit(ctx, visit.ID, visit.UserID)
+	if err != nil && !errors.Is(err, database.ErrVisitNotFound) {
+		return err
+	}
+	var prev *time.Time
+	if before != nil {
+		if !unmodifiedSince.IsZero() && before.ModifiedSince(unmodifiedSince) {
+			return database.ErrVisitModified
+		}
+		prev = before.UpdatedAt
+	}
+	updatedAt := models.NextVisitUpdatedAt(prev, time.Now())
+	visit.UpdatedAt = &updatedAt
+	return nil
+}
+
+func (d dryRunDatabase) DeleteCountryVisit(ctx context.Context, visitID string, userID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteCountryVisit(ctx, visitID, userID)
+	}
+	_, err := d.Database.GetCountryVisit(ctx, visitID, userID)
+	return err
+}
+
+func (d dryRunDatabase) CreateVisitOverlap(
+	ctx context.Context,
+	userID, shareToken string,
+	overlap *models.VisitOverlap,
+) (*models.VisitOverlap, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateVisitOverlap(ctx, userID, shareToken, overlap)
+	}
+	// VisitID is empty when the dry run skipped creating the user's visit
+	if overlap.VisitID != "" {
+		if _, err := d.Database.GetCountryVisit(ctx, overlap.VisitID, userID); err != nil {
+			return nil, err
+		}
+	}
+	_, err := d.Database.GetCountryVisit(ctx, overlap.FriendVisitID, overlap.FriendUserID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := d.Database.GetVisitOverlaps(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range existing {
+		if o.FriendUserID == overlap.FriendUserID && o.FriendVisitID == overlap.FriendVisitID {
+			return nil, database.ErrOverlapExists
+		}
+	}
+	out := *overlap
+	out.CreatedAt = time.Now().UTC()
+	return &out, nil
+}
+
+func (d dryRunDatabase) DeleteVisitOverlap(ctx context.Context, userID, overlapID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteVisitOverlap(ctx, userID, overlapID)
+	}
+	overlaps, err := d.Database.GetVisitOverlaps(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, o := range overlaps {
+		if o.ID == overlapID {
+			return nil
+		}
+	}
+	return database.ErrOverlapNotFound
+}
+
+func (d dryRunDatabase) CreateFriendInvite(
+	ctx context.Context,
+	userID string,
+	invite *models.FriendInvite,
+) (*models.FriendInvite, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateFriendInvite(ctx, userID, invite)
+	}
+	pending, err := d.Database.GetFriendInvites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) >= models.MaxPendingFriendInvites {
+		return nil, database.ErrTooManyFriendInvites
+	}
+	for _, inv := range pending {
+		if inv.Email == invite.Email {
+			return nil, database.ErrFriendInviteExists
+		}
+	}
+	out := *invite
+	out.CreatedAt = time.Now().UTC()
+	out.ExpiresAt = out.CreatedAt.Add(models.FriendInviteTTL)
+	return &out, nil
+}
+
+func (d dryRunDatabase) DeleteFriendInvite(ctx context.Context, userID, inviteID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteFriendInvite(ctx, userID, inviteID)
+	}
+	pending, err := d.Database.GetFriendInvites(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, inv := range pending {
+		if inv.ID == inviteID {
+			return nil
+		}
+	}
+	return database.ErrFriendInviteNotFound
+}
+
+func (d dryRunDatabase) SaveInsights(
+	ctx context.Context,
+	userID string,
+	ins *models.Insights,
+) error {
+	if isDryRun(ctx) {
+		return nil
+	}
+	return d.Database.SaveInsights(ctx, userID, ins)
+}
+
+func (d dryRunDatabase) UpdateFriendProfiles(
+	ctx context.Context,
+	userID string,
+	friends []models.Friend,
+) error {
+	if isDryRun(ctx) {
+		return nil
+	}
+	return d.Database.UpdateFriendProfiles(ctx, userID, friends)
+}
+
+func (d dryRunDatabase) UpdateFriendNickname(
+	ctx context.Context,
+	userID, shareToken, nickname string,
+) (*models.Friend, error) {
+	if !isDryRun(ctx) {
+		return d.Database.UpdateFriendNickname(ctx, userID, shareToken, nickname)
+	}
+	friend, err := d.Database.GetFriendByShareToken(ctx, userID, shareToken)
+	if err != nil {
+		return nil, err
+	}
+	if friend == nil {
+		return nil, database.ErrFriendNotFound
+	}
+	friend.Nickname = nickname
+	return friend, nil
+}
+
+func (d dryRunDatabase) DeleteFriendByShareToken(ctx context.Context, userID, shareToken string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteFriendByShareToken(ctx, userID, shareToken)
+	}
+	friend, err := d.Database.GetFriendByShareToken(ctx, userID, shareToken)
+	if err != nil {
+		return err
+	}
+	if friend == nil {
+		return database.ErrFriendNotFound
+	}
+	return nil
+}
+
+func (d dryRunDatabase) CreateFriendRequest(
+	ctx context.Context,
+	req *models.FriendRequest,
+) (*models.FriendRequest, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateFriendRequest(ctx, req)
+	}
+	if err := d.checkNoFriendRequest(ctx, req.FromUserID, req.ToUserID); err != nil {
+		return nil, err
+	}
+	out := *req
+	out.CreatedAt = time.Now().UTC()
+	return &out, nil
+}
+
+// checkNoFriendRequest returns database.ErrFriendRequestExists when userID has a request to or
+// from otherUserID.
+func (d dryRunDatabase) checkNoFriendRequest(ctx context.Context, userID, otherUserID string) error {
+	incoming, outgoing, err := d.Database.GetFriendRequests(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, r := range append(incoming, outgoing...) {
+		if r.FromUserID == otherUserID || r.ToUserID == otherUserID {
+			return database.ErrFriendRequestExists
+		}
+	}
+	return nil
+}
+
+func (d dryRunDatabase) AcceptFriendRequest(
+	ctx context.Context,
+	requestID, userID string,
+	maxFriends int,
+) (models.Friend, error) {
+	if !isDryRun(ctx) {
+		return d.Database.AcceptFriendRequest(ctx, requestID, userID, maxFriends)
+	}
+	r, err := d.Database.GetFriendRequest(ctx, requestID, userID)
+	if err != nil {
+		return models.Friend{}, err
+	}
+	if r.ToUserID != userID {
+		return models.Friend{}, database.ErrFriendRequestNotFound
+	}
+	return models.Friend{ShareToken: r.FromShareToken, Name: r.FromName, ImageURL: r.FromImageURL},
+		nil
+}
+
+func (d dryRunDatabase) DeleteFriendRequest(ctx context.Context, requestID, userID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteFriendRequest(ctx, requestID, userID)
+	}
+	_, err := d.Database.GetFriendRequest(ctx, requestID, userID)
+	return err
+}
+
+func (d dryRunDatabase) CreateOrganization(
+	ctx context.Context,
+	org *models.Organization,
+	owner models.OrganizationMember,
+) (*models.Organization, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateOrganization(ctx, org, owner)
+	}
+	out := *org
+	out.CreatedBy = owner.UserID
+	out.MemberCount = 1
+	return &out, nil
+}
+
+func (d dryRunDatabase) UpdateOrganizationProfile(
+	ctx context.Context,
+	orgID, name, description string,
+) error {
+	if !isDryRun(ctx) {
+		return d.Database.UpdateOrganizationProfile(ctx, orgID, name, description)
+	}
+	_, err := d.Database.GetOrganization(ctx, orgID)
+	return err
+}
+
+func (d dryRunDatabase) DeleteOrganization(ctx context.Context, orgID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteOrganization(ctx, orgID)
+	}
+	_, err := d.Database.GetOrganization(ctx, orgID)
+	return err
+}
+
+func (d dryRunDatabase) UpdateOrganizationMemberRole(
+	ctx context.Context,
+	orgID, userID, role string,
+) error {
+	if !isDryRun(ctx) {
+		return d.Database.UpdateOrganizationMemberRole(ctx, orgID, userID, role)
+	}
+	_, err := d.Database.GetOrganizationMember(ctx, orgID, userID)
+	return err
+}
+
+func (d dryRunDatabase) RemoveOrganizationMember(ctx context.Context, orgID, userID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.RemoveOrganizationMember(ctx, orgID, userID)
+	}
+	_, err := d.Database.GetOrganizationMember(ctx, orgID, userID)
+	return err
+}
+
+func (d dryRunDatabase) CreateOrganizationInvitation(
+	ctx context.Context,
+	inv *models.OrganizationInvitation,
+) (*models.OrganizationInvitation, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateOrganizationInvitation(ctx, inv)
+	}
+	pending, err := d.Database.GetOrganizationInvitations(ctx, inv.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) >= models.MaxOrgPendingInvitations {
+		return nil, database.ErrTooManyOrgInvitations
+	}
+	out := *inv
+	out.CreatedAt = time.Now().UTC()
+	out.ExpiresAt = out.CreatedAt.Add(models.OrgInvitationTTL)
+	return &out, nil
+}
+
+func (d dryRunDatabase) AcceptOrganizationInvitation(
+	ctx context.Context,
+	code string,
+	member models.OrganizationMember,
+) (*models.Organization, error) {
+	if !isDryRun(ctx) {
+		return d.Database.AcceptOrganizationInvitation(ctx, code, member)
+	}
+	inv, err := d.Database.GetOrganizationInvitation(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	org, err := d.Database.GetOrganization(ctx, inv.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = d.Database.GetOrganizationMember(ctx, org.ID, member.UserID)
+	if err == nil {
+		return nil, database.ErrOrgMemberAlreadyExists
+	}
+	if !errors.Is(err, database.ErrOrgMemberNotFound) {
+		return nil, err
+	}
+	if org.MemberCount >= models.MaxOrgMembers {
+		return nil, database.ErrOrganizationFull
+	}
+	org.MemberCount++
+	return org, nil
+}
+
+func (d dryRunDatabase) DeleteOrganizationInvitation(ctx context.Context, orgID, code string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteOrganizationInvitation(ctx, orgID, code)
+	}
+	// Listed rather than loaded by code: revoking also applies to expired invitations
+	invitations, err := d.Database.GetOrganizationInvitations(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, inv := range invitations {
+		if inv.Code == code {
+			return nil
+		}
+	}
+	return database.ErrOrgInvitationNotFound
+}
+
+func (d dryRunDatabase) CreateOrganizationGoal(
+	ctx context.Context,
+	orgID string,
+	goal *models.OrganizationGoal,
+) (*models.OrganizationGoal, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateOrganizationGoal(ctx, orgID, goal)
+	}
+	if _, err := d.Database.GetOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+	goals, err := d.Database.GetOrganizationGoals(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if len(goals) >= models.MaxOrgGoals {
+		return nil, database.ErrTooManyOrgGoals
+	}
+	out := *goal
+	out.CreatedAt = time.Now().UTC()
+	return &out, nil
+}
+
+func (d dryRunDatabase) DeleteOrganizationGoal(ctx context.Context, orgID, goalID string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteOrganizationGoal(ctx, orgID, goalID)
+	}
+	goals, err := d.Database.GetOrganizationGoals(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, g := range goals {
+		if g.ID == goalID {
+			return nil
+		}
+	}
+	return database.ErrOrgGoalNotFound
+}
+
+func (d dryRunDatabase) CreateOrgShareLink(
+	ctx context.Context,
+	link *models.OrgShareLink,
+) (*models.OrgShareLink, error) {
+	if !isDryRun(ctx) {
+		return d.Database.CreateOrgShareLink(ctx, link)
+	}
+	existing, err := d.Database.GetOrgShareLinks(ctx, link.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= models.MaxOrgShareLinks {
+		return nil, database.ErrTooManyOrgShareLinks
+	}
+	out := *link
+	out.CreatedAt = time.Now().UTC()
+	return &out, nil
+}
+
+func (d dryRunDatabase) DeleteOrgShareLink(ctx context.Context, orgID, token string) error {
+	if !isDryRun(ctx) {
+		return d.Database.DeleteOrgShareLink(ctx, orgID, token)
+	}
+	link, err := d.Database.GetOrgShareLink(ctx, token)
+	if err != nil {
+		return err
+	}
+	if link == nil || link.OrganizationID != orgID {
+		return database.ErrOrgShareLinkNotFound
+	}
+	return nil
+}
+
+func (d dryRunDatabase) BlockUser(
+	ctx context.Context,
+	userID, ownerShareToken string,
+	blocked *models.BlockedUser,
+) (*models.BlockedUser, error) {
+	if !isDryRun(ctx) {
+		return d.Database.BlockUser(ctx, userID, ownerShareToken, blocked)
+	}
+	out := *blocked
+	out.CreatedAt = time.Now().UTC()
+	return &out, nil
+}
+
+func (d dryRunDatabase) UnblockUser(ctx context.Context, userID, shareToken string) error {
+	if !isDryRun(ctx) {
+		return d.Database.UnblockUser(ctx, userID, shareToken)
+	}
+	blocked, err := d.Database.GetBlockedUsers(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, b := range blocked {
+		if b.ShareToken == shareToken {
+			return nil
+		}
+	}
+	return database.ErrBlockedUserNotFound
+}
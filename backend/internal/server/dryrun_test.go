@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+// TestDryRunAPIKeyNotTouched checks that a dry run authenticated with an API key does not write
+// the key's LastUsedAt.
+func TestDryRunAPIKeyNotTouched(t *testing.T) {
+	s, db := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+	w := doAs(t, s, "u1", http.MethodPost, "/me/api-keys", `{"name":"script"}`)
+	requireStatus(t, w, http.StatusCreated)
+	var created struct {
+		Key string `json:"key"`
+	}
+	decode(t, w, &created)
+	lastUsed := func() bool {
+		t.Helper()
+		keys, err := db.GetAPIKeys(context.Background(), "u1")
+		if err != nil || len(keys) != 1 {
+			t.Fatalf("GetAPIKeys = %v, %v", keys, err)
+		}
+		return keys[0].LastUsedAt != nil
+	}
+
+	w = do(t, s, http.MethodGet, "/visits?dryRun=true", "", "X-Api-Key", created.Key)
+	requireStatus(t, w, http.StatusOK)
+	if w.Header().Get("X-Dry-Run") != "true" {
+		t.Fatal("missing X-Dry-Run header")
+	}
+	if lastUsed() {
+		t.Error("dry run set LastUsedAt")
+	}
+
+	requireStatus(t, do(t, s, http.MethodGet, "/visits", "", "X-Api-Key", created.Key),
+		http.StatusOK)
+	if !lastUsed() {
+		t.Error("LastUsedAt not set")
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// computeETag returns a strong ETag (quoted) derived from the given parts.
+func computeETag(parts ...interface{}) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%v\x00", p)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+// matchesIfNoneMatch reports whether the request's If-None-Match header matches etag.
+// Handles "*", comma-separated lists and weak validators (W/ prefix), per RFC 9110 weak comparison.
+func matchesIfNoneMatch(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModifiedIfMatch sets the ETag header and, when If-None-Match matches, responds
+// 304 Not Modified and returns true so the handler can skip building the body.
+func writeNotModifiedIfMatch(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	if matchesIfNoneMatch(c, etag) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
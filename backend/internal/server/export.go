@@ -0,0 +1,127 @@
+package server
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+// exportPageSize is how many visits or friends the account export reads per database page, so
+// a large account is never held in memory at once.
+const exportPageSize = 200
+
+// exportWriter streams the account export as sections written one after another: keys of one
+// JSON object, or one <section>.json file each in a zip archive. List sections are written one
+// element at a time.
+type exportWriter struct {
+	format   jsontime.Format
+	json     io.Writer   // set for a JSON export
+	zip      *zip.Writer // set for a zip export
+	sections int
+}
+
+func newJSONExportWriter(w io.Writer, format jsontime.Format) *exportWriter {
+	return &exportWriter{format: format, json: w}
+}
+
+func newZipExportWriter(w io.Writer, format jsontime.Format) *exportWriter {
+	return &exportWriter{format: format, zip: zip.NewWriter(w)}
+}
+
+// section starts the section name and returns the writer for its value.
+func (e *exportWriter) section(name string) (io.Writer, error) {
+	if e.zip != nil {
+		return e.zip.Create(name + ".json")
+	}
+	sep := ","
+	if e.sections == 0 {
+		sep = "{"
+	}
+	e.sections++
+	_, err := fmt.Fprintf(e.json, "%s%q:", sep, name)
+	return e.json, err
+}
+
+// Object writes the section name holding the JSON value of v.
+func (e *exportWriter) Object(name string, v any) error {
+	w, err := e.section(name)
+	if err != nil {
+		return err
+	}
+	return e.write(w, v)
+}
+
+// List writes the section name holding a JSON array of the values fill emits.
+func (e *exportWriter) List(name string, fill func(emit func(v any) error) error) error {
+	w, err := e.section(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	n := 0
+	err = fill(func(v any) error {
+		if n > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		n++
+		return e.write(w, v)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// Close finishes the JSON object or zip archive; the underlying writer is left open.
+func (e *exportWriter) Close() error {
+	if e.zip != nil {
+		return e.zip.Close()
+	}
+	end := "}"
+	if e.sections == 0 {
+		end = "{}"
+	}
+	_, err := io.WriteString(e.json, end)
+	return err
+}
+
+func (e *exportWriter) write(w io.Writer, v any) error {
+	body, err := json.Marshal(jsontime.Apply(v, e.format))
+	if err != nil {
+		return fmt.Errorf("failed to encode export: %w", err)
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// emitPages emits the items of every page fetch returns, following its cursors until a page
+// has no next cursor.
+func emitPages[T any](
+	emit func(v any) error,
+	fetch func(cursor string) ([]T, string, error),
+) error {
+	cursor := ""
+	for {
+		items, next, err := fetch(cursor)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// friendSyncInterval is how long the Name and ImageURL duplicated on a Friend are served before
+// GET /friends refreshes them from the friend's User document.
+const friendSyncInterval = 24 * time.Hour
+
+// syncFriendProfiles refreshes Name and ImageURL of the friends not synced within
+// friendSyncInterval from their User documents, looked up in batches by ShareToken, and
+// returns friends with the fresh values. Failures are only logged, so the friends list is
+// served regardless.
+func (s *Server) syncFriendProfiles(
+	ctx context.Context,
+	userID string,
+	friends []models.Friend,
+) []models.Friend {
+	ctx, span := tracing.New(ctx, "syncFriendProfiles")
+	defer span.End()
+
+	now := time.Now().UTC()
+	var stale []int
+	var tokens []string
+	for i, f := range friends {
+		if now.Sub(f.SyncedAt) >= friendSyncInterval {
+			stale = append(stale, i)
+			tokens = append(tokens, f.ShareToken)
+		}
+	}
+	if len(stale) == 0 {
+		return friends
+	}
+
+	log := logging.FromContext(ctx)
+	users, err := s.db.GetUsersByShareTokens(ctx, tokens)
+	if err != nil {
+		log.Warn("Friend profile sync: GetUsersByShareTokens failed", logging.Error, err)
+		return friends
+	}
+	synced := make([]models.Friend, 0, len(stale))
+	for _, i := range stale {
+		f := &friends[i]
+		// A friend whose account is gone keeps its last known profile.
+		if u := users[f.ShareToken]; u != nil {
+			if u.Name != "" {
+				f.Name = u.Name
+			}
+			f.ImageURL = u.ImageURL
+		}
+		f.SyncedAt = now
+		synced = append(synced, *f)
+	}
+	if err := s.db.UpdateFriendProfiles(ctx, userID, synced); err != nil {
+		log.Warn("Friend profile sync: UpdateFriendProfiles failed", logging.Error, err)
+	} else {
+		log.Info("Synced friend profiles", logging.UserID, userID, logging.Count, len(synced))
+	}
+	return friends
+}
+
+// addFriendSummaries sets Summary of the friends whose account exists and is not pending
+// deletion, with two batched lookups: the friends' users by ShareToken, then the counts over
+// their non-private visits.
+func (s *Server) addFriendSummaries(ctx context.Context, friends []models.Friend) error {
+	ctx, span := tracing.New(ctx, "addFriendSummaries")
+	defer span.End()
+
+	if len(friends) == 0 {
+		return nil
+	}
+	tokens := make([]string, len(friends))
+	for i, f := range friends {
+		tokens[i] = f.ShareToken
+	}
+	users, err := s.db.GetUsersByShareTokens(ctx, tokens)
+	if err != nil {
+		return err
+	}
+	userIDs := make([]string, 0, len(users))
+	for _, u := range users {
+		if !u.PendingDeletion() {
+			userIDs = append(userIDs, u.ID)
+		}
+	}
+	summaries, err := s.db.GetVisitSummariesForUsers(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	for i := range friends {
+		if u := users[friends[i].ShareToken]; u != nil {
+			if summary, ok := summaries[u.ID]; ok {
+				friends[i].Summary = &summary
+			}
+		}
+	}
+	return nil
+}
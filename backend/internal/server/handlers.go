@@ -5,14 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/matti777/my-countries/backend/internal/changelog"
 	"github.com/matti777/my-countries/backend/internal/ctxkeys"
 	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/data/subdivisions"
 	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/jsontime"
 	"github.com/matti777/my-countries/backend/internal/logging"
 	"github.com/matti777/my-countries/backend/internal/models"
 	"github.com/matti777/my-countries/backend/internal/tracing"
@@ -26,18 +31,14 @@ func (s *Server) PostLoginHandler(ctx context.Context, c *gin.Context) {
 
 	log := logging.FromContext(ctx)
 	log.Info("POST /login received")
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("POST /login: user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
 	if err := s.db.EnsureUser(ctx, user); err != nil {
 		log.Error("POST /login: EnsureUser failed", logging.UserID, user.UserID, logging.Error, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "login failed"})
 		return
 	}
 	log.Info("POST /login succeeded", logging.UserID, user.UserID)
+	s.recordAudit(ctx, c, user.ID, models.AuditLogin, "")
 	c.JSON(http.StatusOK, gin.H{})
 }
 
@@ -47,12 +48,7 @@ func (s *Server) GetSettingsHandler(ctx context.Context, c *gin.Context) {
 	defer span.End()
 
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("GET /settings: user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
 
 	dbUser, err := s.db.GetUserByID(ctx, user.ID)
 	if err != nil {
@@ -66,7 +62,7 @@ func (s *Server) GetSettingsHandler(ctx context.Context, c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.SettingsToResponse(dbUser.EffectiveSettings()))
+	writeJSON(c, http.StatusOK, models.SettingsToResponse(dbUser.EffectiveSettings()))
 }
 
 // PutSettingsHandler handles PUT /settings for the authenticated user.
@@ -75,12 +71,7 @@ func (s *Server) PutSettingsHandler(ctx context.Context, c *gin.Context) {
 	defer span.End()
 
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("PUT /settings: user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
 
 	var raw map[string]json.RawMessage
 	if err := c.ShouldBindJSON(&raw); err != nil {
@@ -185,6 +176,40 @@ func (s *Server) PutSettingsHandler(ctx context.Context, c *gin.Context) {
 		settings.Description = desc
 	}
 
+	if territoriesRaw, has := raw["includeTerritories"]; has {
+		if err := json.Unmarshal(territoriesRaw, &settings.IncludeTerritories); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "includeTerritories must be a boolean"})
+			return
+		}
+	}
+
+	if hideRaw, has := raw["hideFromFollowers"]; has {
+		if err := json.Unmarshal(hideRaw, &settings.HideFromFollowers); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hideFromFollowers must be a boolean"})
+			return
+		}
+	}
+
+	if defaultsRaw, hasDefaults := raw["visitDefaults"]; hasDefaults {
+		var defaults models.VisitDefaults
+		if err := json.Unmarshal(defaultsRaw, &defaults); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid visitDefaults"})
+			return
+		}
+		fields := map[string]string{}
+		if err := models.ValidateDedupe(defaults.Dedupe); err != nil {
+			fields["visitDefaults.dedupe"] = err.Error()
+		}
+		if err := models.ValidateVisitType(defaults.VisitType); err != nil {
+			fields["visitDefaults.visitType"] = err.Error()
+		}
+		if len(fields) > 0 {
+			c.JSON(http.StatusBadRequest, models.NewValidationErrors(fields))
+			return
+		}
+		settings.VisitDefaults = &defaults
+	}
+
 	if err := s.db.UpdateUserSettings(ctx, user.ID, settings); err != nil {
 		if errors.Is(err, database.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
@@ -196,22 +221,84 @@ func (s *Server) PutSettingsHandler(ctx context.Context, c *gin.Context) {
 	}
 
 	log.Info("Updated user settings", logging.UserID, user.ID)
-	c.JSON(http.StatusOK, models.SettingsToResponse(settings))
+	writeJSON(c, http.StatusOK, models.SettingsToResponse(settings))
 }
 
 // GetCountriesHandler handles GET /countries.
-// Returns the bundled list of all sovereign countries (in-memory Go slice).
+// Returns the bundled list of all sovereign countries (in-memory, with admin country overrides
+// applied); with ?include=territories, territories and disputed states are appended, and
+// ?include=historic adds the former countries accepted on old visits. ?list=un
+// returns the UN M49 countries and areas instead and ?list=tcc the Travelers' Century Club
+// destinations. Version identifies the country data for client caches. Cacheable for a day so
+// edge caches absorb repeated anonymous reads (see countriesAccessMiddleware); the ETag lets
+// clients revalidate with If-None-Match and get 304 instead of the full list.
 func (s *Server) GetCountriesHandler(ctx context.Context, c *gin.Context) {
 	ctx, span := tracing.New(ctx, "GetCountriesHandler")
 	defer span.End()
 
-	c.JSON(http.StatusOK, models.CountryResponse{
-		Countries: data.List,
-	})
+	countries := data.Countries()
+	includeTerritories := false
+	var historic []models.HistoricCountry
+	for _, include := range strings.Split(c.Query("include"), ",") {
+		switch include {
+		case "":
+		case "territories":
+			countries = data.ListWithTerritories()
+			includeTerritories = true
+		case "historic":
+			historic = data.HistoricCountries
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "include must be a comma-separated list of territories, historic",
+			})
+			return
+		}
+	}
+	list := c.DefaultQuery("list", models.CountryListISO)
+	switch list {
+	case models.CountryListISO, models.CountryListUN, models.CountryListTCC:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "list must be one of iso, un, tcc"})
+		return
+	}
+
+	// The body only changes with the response format (API version) or the country data
+	// (data.Version, a hash of the merged lists computed when they are built), so the ETag is
+	// derived without serializing the lists
+	version := data.Version()
+	c.Header("Cache-Control", "public, max-age=86400")
+	etag := computeETag("countries", changelog.CurrentVersion(), version, list, includeTerritories,
+		historic != nil, timeFormatFromContext(ctx))
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+	switch list {
+	case models.CountryListISO:
+		writeJSON(c, http.StatusOK, models.CountryResponse{
+			List:      list,
+			Version:   version,
+			Countries: countries,
+			Historic:  historic,
+		})
+	case models.CountryListUN:
+		writeJSON(c, http.StatusOK, models.CountryResponse{
+			List:      list,
+			Version:   version,
+			Countries: data.UNList(),
+		})
+	case models.CountryListTCC:
+		writeJSON(c, http.StatusOK, models.CountryResponse{
+			List:         list,
+			Version:      version,
+			Destinations: data.TCCDestinations,
+		})
+	}
 }
 
 // GetShareProfileHandler handles GET /share/profile/:shareToken.
-// Unauthenticated; returns public profile fields and visits for that ShareToken.
+// Unauthenticated; returns public profile fields and non-private visits for that ShareToken or
+// active ShareLink token, reduced to what the link's scope exposes.
+// Answers If-None-Match with 304 based on VisitsRevision, profile fields and sharing settings.
 func (s *Server) GetShareProfileHandler(ctx context.Context, c *gin.Context) {
 	ctx, span := tracing.New(ctx, "GetShareProfileHandler")
 	defer span.End()
@@ -222,26 +309,80 @@ func (s *Server) GetShareProfileHandler(ctx context.Context, c *gin.Context) {
 		return
 	}
 	log := logging.FromContext(ctx)
-	user, err := s.db.GetUserByShareToken(ctx, shareToken)
+	user, scope, err := s.resolveShareUser(ctx, shareToken, true)
 	if err != nil {
-		log.Error("GetUserByShareToken failed", logging.Error, err)
+		log.Error("resolveShareUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	}
+	s.writeShareProfile(ctx, c, user, scope, shareToken)
+}
+
+// writeShareProfile responds with the share view of user opened by token with scope: the
+// ShareProfileResponse, 304 when If-None-Match matches, or 404 when user is nil, pending
+// deletion or blocked the signed-in viewer. Counts the view.
+func (s *Server) writeShareProfile(
+	ctx context.Context,
+	c *gin.Context,
+	user *models.User,
+	scope, token string,
+) {
+	log := logging.FromContext(ctx)
+	// Shares of accounts pending deletion are disabled at once
+	if user == nil || user.PendingDeletion() {
+		shareNotFound(c)
+		return
+	}
+	if blocked, err := s.isBlockedViewer(ctx, user.ID); err != nil {
+		log.Error("IsBlocked failed", logging.Error, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
 		return
+	} else if blocked {
+		shareNotFound(c)
+		return
 	}
-	if user == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+	s.recordShareView(ctx, user, token)
+	settings := user.EffectiveSettings()
+	c.Header("Cache-Control", "no-cache")
+	etag := computeETag("share", user.ID, user.VisitsRevision, user.Name, user.ImageURL, settings,
+		scope, timeFormatFromContext(ctx))
+	if writeNotModifiedIfMatch(c, etag) {
 		return
 	}
-	visits, err := s.db.GetCountryVisitsByUser(ctx, user.ID)
+	visits, err := s.db.GetPublicCountryVisitsByUser(ctx, user.ID)
 	if err != nil {
-		log.Error("GetCountryVisitsByUser failed for share", logging.Error, err)
+		log.Error("GetPublicCountryVisitsByUser failed for share", logging.Error, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
 		return
 	}
-	if visits == nil {
-		visits = []models.CountryVisit{}
+	verifiedCount := redactSharedVisits(visits, settings)
+	attachSuccessorCodes(visits)
+	resp := models.ShareProfileResponse{
+		Visits:             visits,
+		UserName:           user.Name,
+		ImageUrl:           user.ImageURL,
+		HomeCountryCode:    settings.HomeCountryCode,
+		InstagramUserName:  settings.InstagramUserName,
+		Description:        settings.Description,
+		VerifiedVisitCount: verifiedCount,
+	}
+	applyShareScope(&resp, scope)
+	writeJSON(c, http.StatusOK, resp)
+}
+
+// redactSharedVisits prepares visits for viewers other than their owner: it drops companions
+// and proofs and applies the owner's sharing settings. Returns the number of verified visits.
+func redactSharedVisits(visits []models.CountryVisit, settings models.UserSettings) int {
+	// Companions are friends' share tokens and proofs are private files; neither is exposed
+	// publicly. Verified stays, so viewers see which visits are backed by proof.
+	verifiedCount := 0
+	for i := range visits {
+		visits[i].Companions = nil
+		visits[i].Proofs = nil
+		if visits[i].Verified {
+			verifiedCount++
+		}
 	}
-	settings := user.EffectiveSettings()
 	if !settings.Sharing.ShareMediaURL ||
 		!settings.Sharing.ShareNotes ||
 		!settings.Sharing.ShareTags {
@@ -257,30 +398,19 @@ func (s *Server) GetShareProfileHandler(ctx context.Context, c *gin.Context) {
 			}
 		}
 	}
-	c.JSON(http.StatusOK, models.ShareProfileResponse{
-		Visits:            visits,
-		UserName:          user.Name,
-		ImageUrl:          user.ImageURL,
-		HomeCountryCode:   settings.HomeCountryCode,
-		InstagramUserName: settings.InstagramUserName,
-		Description:       settings.Description,
-	})
+	return verifiedCount
 }
 
 // GetListHandler handles GET /visits.
 // Returns a list of country visits for the current user and the user's ShareToken.
 // Requires auth middleware (user in context). Reads User from DB for ShareToken.
+// Answers If-None-Match with 304 based on the user's VisitsRevision.
 func (s *Server) GetListHandler(ctx context.Context, c *gin.Context) {
 	ctx, span := tracing.New(ctx, "GetListHandler")
 	defer span.End()
 
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
 	userID := user.ID
 
 	dbCtx, dbSpan := tracing.New(ctx, "database::GetUserByID")
@@ -297,6 +427,22 @@ func (s *Server) GetListHandler(ctx context.Context, c *gin.Context) {
 		return
 	}
 
+	friends, err := s.db.GetFriendsByUser(ctx, userID)
+	if err != nil {
+		log.Error("GetFriendsByUser failed", logging.UserID, userID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		return
+	}
+
+	// VisitsRevision changes on every visit write, so a matching ETag skips the visits read.
+	// Friends are part of the ETag since they resolve companionFriends.
+	c.Header("Cache-Control", "private, no-cache")
+	etag := computeETag("visits", userID, dbUser.VisitsRevision, dbUser.ShareToken, friends,
+		timeFormatFromContext(ctx))
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+
 	log.Info("Fetching country visits for user", logging.UserID, userID)
 	dbCtx2, dbSpan2 := tracing.New(ctx, "database::GetCountryVisitsByUser")
 	visits, err := s.db.GetCountryVisitsByUser(dbCtx2, userID)
@@ -309,39 +455,53 @@ func (s *Server) GetListHandler(ctx context.Context, c *gin.Context) {
 		return
 	}
 
-	log.Info("Successfully fetched country visits for current user", logging.Count, len(visits))
-	if visits == nil {
-		visits = []models.CountryVisit{}
+	overlaps, err := s.db.GetVisitOverlaps(ctx, userID)
+	if err != nil {
+		log.Error("GetVisitOverlaps failed", logging.UserID, userID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch overlaps"})
+		return
 	}
-	c.JSON(http.StatusOK, models.CountryVisitResponse{
+
+	log.Info("Successfully fetched country visits for current user", logging.Count, len(visits))
+	attachCompanionFriends(visits, friends)
+	attachOverlaps(visits, overlaps)
+	attachSuccessorCodes(visits)
+	writeJSON(c, http.StatusOK, models.CountryVisitResponse{
 		Visits:     visits,
 		ShareToken: dbUser.ShareToken,
 	})
 }
 
+// postVisitBody is the body of POST /visits.
+type postVisitBody struct {
+	CountryCode     string           `json:"countryCode"`
+	VisitedTime     *jsontime.Time   `json:"visitedTime"` // Unix seconds or RFC 3339; required
+	MediaURL        *string          `json:"mediaUrl,omitempty"`
+	Notes           *string          `json:"notes,omitempty"`
+	Tags            []string         `json:"tags,omitempty"`
+	IsPrivate       *bool            `json:"isPrivate,omitempty"`
+	VisitType       *string          `json:"visitType,omitempty"`
+	Dedupe          *string          `json:"dedupe,omitempty"`
+	SubdivisionCode string           `json:"subdivisionCode,omitempty"`
+	DestinationCode string           `json:"destinationCode,omitempty"`
+	Companions      []string         `json:"companions,omitempty"`
+	Location        *models.Location `json:"location,omitempty"`
+}
+
 // PostVisitsHandler handles POST /visits.
-// Creates a new country visit for the current user. Body: { "countryCode": "FI", "visitedTime": <Unix seconds> }.
+// Creates a new country visit for the current user. Body: { "countryCode": "FI", "visitedTime": <Unix seconds or RFC 3339> }.
+// countryCode may be alpha-2 or alpha-3 ("FIN"); it is stored as alpha-2.
 // visitedTime is required and must be between 1900-01-01 and current date (inclusive).
+// Omitted isPrivate, visitType and dedupe come from the user's Settings.VisitDefaults.
 // Requires auth middleware.
 func (s *Server) PostVisitsHandler(ctx context.Context, c *gin.Context) {
 	ctx, span := tracing.New(ctx, "PostVisitsHandler")
 	defer span.End()
 
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
 
-	var body struct {
-		CountryCode string   `json:"countryCode"`
-		VisitedTime *int64   `json:"visitedTime"` // Unix seconds; required
-		MediaURL    *string  `json:"mediaUrl,omitempty"`
-		Notes       *string  `json:"notes,omitempty"`
-		Tags        []string `json:"tags,omitempty"`
-	}
+	var body postVisitBody
 	if err := c.ShouldBindJSON(&body); err != nil {
 		log.Warn("Invalid POST /visits body", logging.Error, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
@@ -351,28 +511,61 @@ func (s *Server) PostVisitsHandler(ctx context.Context, c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "countryCode is required"})
 		return
 	}
-	countryCode := strings.ToUpper(strings.TrimSpace(body.CountryCode))
-	if !data.IsListedCountry(countryCode) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid countryCode"})
-		return
+	// Former countries (e.g. the Soviet Union) are accepted for visits from their time
+	historic, isHistoric := data.HistoricCountryByCode(body.CountryCode)
+	countryCode := historic.CountryCode
+	if !isHistoric {
+		country, ok := data.CountryByCode(body.CountryCode)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid countryCode"})
+			return
+		}
+		countryCode = country.CountryCode
 	}
 	if body.VisitedTime == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "visitedTime is required"})
 		return
 	}
+	subdivisionCode := ""
+	if body.SubdivisionCode != "" {
+		code, ok := subdivisions.Validate(countryCode, body.SubdivisionCode)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subdivisionCode for countryCode"})
+			return
+		}
+		subdivisionCode = code
+	}
+	destinationCode := ""
+	if body.DestinationCode != "" {
+		code, ok := data.ValidateTCCDestination(countryCode, body.DestinationCode)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid destinationCode for countryCode"})
+			return
+		}
+		destinationCode = code
+	}
 
-	t := time.Unix(*body.VisitedTime, 0).UTC()
-	minDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
-	now := time.Now().UTC()
-	maxDate := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, time.UTC)
-	if t.Before(minDate) || t.After(maxDate) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "visitedTime must be between 1900-01-01 and current date"})
+	t := body.VisitedTime.Time
+	if err := models.ValidateVisitedTime(t); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if isHistoric {
+		if err := historic.ValidateVisitedTime(t); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 	if body.MediaURL != nil && *body.MediaURL != "" && !models.ValidateMediaURL(*body.MediaURL) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "mediaUrl must be a well-formed URL (e.g. https://...)"})
 		return
 	}
+	if body.Location != nil {
+		if err := body.Location.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	notes := ""
 	if body.Notes != nil {
@@ -389,57 +582,206 @@ func (s *Server) PostVisitsHandler(ctx context.Context, c *gin.Context) {
 		return
 	}
 
+	// Fields the request omits come from the user's Settings.VisitDefaults; territories
+	// are only accepted with Settings.IncludeTerritories
+	var defaults models.VisitDefaults
+	if body.IsPrivate == nil || body.VisitType == nil || body.Dedupe == nil ||
+		(!isHistoric && !data.IsListedCountry(countryCode)) {
+		dbUser, err := s.db.GetUserByID(ctx, user.ID)
+		if err != nil {
+			log.Error("GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+			return
+		}
+		settings := dbUser.EffectiveSettings()
+		if settings.VisitDefaults != nil {
+			defaults = *settings.VisitDefaults
+		}
+		if !isHistoric && !data.IsVisitableCountry(countryCode, settings.IncludeTerritories) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "countryCode is a territory; enable includeTerritories in settings",
+			})
+			return
+		}
+	}
+	isPrivate := defaults.IsPrivate
+	if body.IsPrivate != nil {
+		isPrivate = *body.IsPrivate
+	}
+	visitType := defaults.VisitType
+	if body.VisitType != nil {
+		visitType = *body.VisitType
+	}
+	if err := models.ValidateVisitType(visitType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dedupe := defaults.Dedupe
+	if body.Dedupe != nil {
+		dedupe = *body.Dedupe
+	}
+	if err := models.ValidateDedupe(dedupe); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var companions []string
+	var friends []models.Friend
+	if len(body.Companions) > 0 {
+		var err error
+		friends, err = s.db.GetFriendsByUser(ctx, user.ID)
+		if err != nil {
+			log.Error("GetFriendsByUser failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+			return
+		}
+		companions, err = validateCompanions(body.Companions, friends, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	visit := &models.CountryVisit{
-		CountryCode: countryCode,
-		VisitedTime: t,
-		MediaURL:    body.MediaURL,
-		Notes:       notes,
-		Tags:        tags,
-		UserID:      user.ID,
+		CountryCode:     countryCode,
+		VisitedTime:     t,
+		MediaURL:        body.MediaURL,
+		Notes:           notes,
+		Tags:            tags,
+		IsPrivate:       isPrivate,
+		VisitType:       visitType,
+		SubdivisionCode: subdivisionCode,
+		DestinationCode: destinationCode,
+		Companions:      companions,
+		Location:        body.Location,
+		UserID:          user.ID,
 	}
 
-	created, err := s.db.CreateCountryVisit(ctx, visit)
+	if dedupe == models.DedupeSameDay {
+		visits, err := s.db.GetCountryVisitsByUser(ctx, user.ID)
+		if err != nil {
+			log.Error("GetCountryVisitsByUser failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch country visits"})
+			return
+		}
+		if existing := findSameDayVisit(visits, countryCode, t); existing != nil {
+			if friends == nil && len(existing.Companions) > 0 {
+				if friends, err = s.db.GetFriendsByUser(ctx, user.ID); err != nil {
+					log.Error("GetFriendsByUser failed", logging.Error, err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+					return
+				}
+			}
+			attachCompanionFriendsTo(existing, friends)
+			attachSuccessorCodesTo(existing)
+			log.Info("Returning existing same-day visit", logging.VisitID, existing.ID)
+			writeJSON(c, http.StatusOK, existing)
+			return
+		}
+	}
+
+	created, err := s.db.CreateCountryVisit(ctx, visit, s.maxVisits)
 	if err != nil {
+		if writeLimitError(c, err, visitLimitReachedCode, "visits") {
+			return
+		}
 		log.Error("CreateCountryVisit failed", logging.Error, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create visit"})
 		return
 	}
+	attachCompanionFriendsTo(created, friends)
+	attachSuccessorCodesTo(created)
 	log.Info("Created country visit", logging.VisitID, created.ID, logging.UserID, user.ID)
-	c.JSON(http.StatusCreated, created)
+	writeJSON(c, http.StatusCreated, created)
+}
+
+// optionalLocation is a PUT body field that tells an omitted location (keep) from null (clear).
+type optionalLocation struct {
+	set   bool
+	value *models.Location
 }
 
-// PutVisitHandler handles PUT /visits/:id — partial update per api.md.
+// UnmarshalJSON implements json.Unmarshaler; it is also called for null.
+func (o *optionalLocation) UnmarshalJSON(data []byte) error {
+	o.set = true
+	return json.Unmarshal(data, &o.value)
+}
+
+// JSONType implements openapi.JSONTyper.
+func (optionalLocation) JSONType() reflect.Type {
+	return reflect.TypeFor[*models.Location]()
+}
+
+// visitUpdateBody is the body of PUT and PATCH /visits/:id; omitted fields are kept.
+type visitUpdateBody struct {
+	VisitedTime     *jsontime.Time   `json:"visitedTime"`
+	Tags            *[]string        `json:"tags"`
+	MediaURL        *string          `json:"mediaUrl"`
+	Notes           *string          `json:"notes"`
+	IsPrivate       *bool            `json:"isPrivate"`
+	VisitType       *string          `json:"visitType"`
+	SubdivisionCode *string          `json:"subdivisionCode"`
+	DestinationCode *string          `json:"destinationCode"`
+	Companions      *[]string        `json:"companions"`
+	Location        optionalLocation `json:"location"`
+}
+
+// PutVisitHandler handles PUT /visits/:id — partial update per api.md. If-Unmodified-Since is
+// honored when sent.
 func (s *Server) PutVisitHandler(ctx context.Context, c *gin.Context) {
 	ctx, span := tracing.New(ctx, "PutVisitHandler")
 	defer span.End()
 
+	s.updateVisit(ctx, c, false)
+}
+
+// PatchVisitHandler handles PATCH /visits/:id: the update of PUT /visits/:id, but requiring
+// If-Unmodified-Since, so that of two devices editing the same visit the later one gets 412
+// instead of silently overwriting the other's change.
+func (s *Server) PatchVisitHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PatchVisitHandler")
+	defer span.End()
+
+	s.updateVisit(ctx, c, true)
+}
+
+// updateVisit applies the PUT or PATCH /visits/:id body to the visit. requirePrecondition makes
+// the If-Unmodified-Since header mandatory (428 without it).
+func (s *Server) updateVisit(ctx context.Context, c *gin.Context, requirePrecondition bool) {
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
 	visitID := c.Param("id")
 	if visitID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "visit id required"})
 		return
 	}
-
-	var body struct {
-		VisitedTime *int64    `json:"visitedTime"`
-		Tags        *[]string `json:"tags"`
-		MediaURL    *string   `json:"mediaUrl"`
-		Notes       *string   `json:"notes"`
+	var unmodifiedSince time.Time
+	if raw := c.GetHeader("If-Unmodified-Since"); raw != "" {
+		t, err := http.ParseTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid If-Unmodified-Since header"})
+			return
+		}
+		unmodifiedSince = t
+	} else if requirePrecondition {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"error": "If-Unmodified-Since header is required",
+		})
+		return
 	}
+
+	var body visitUpdateBody
 	if err := c.ShouldBindJSON(&body); err != nil {
-		log.Warn("Invalid PUT /visits/:id body", logging.Error, err)
+		log.Warn("Invalid visit update body", logging.Error, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
-	if body.VisitedTime == nil && body.Tags == nil && body.MediaURL == nil && body.Notes == nil {
+	if body.VisitedTime == nil && body.Tags == nil && body.MediaURL == nil && body.Notes == nil &&
+		body.IsPrivate == nil && body.VisitType == nil && body.SubdivisionCode == nil &&
+		body.DestinationCode == nil && body.Companions == nil && !body.Location.set {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "at least one of visitedTime, tags, mediaUrl, notes is required",
+			"error": "at least one of visitedTime, tags, mediaUrl, notes, isPrivate, visitType, " +
+				"subdivisionCode, destinationCode, companions, location is required",
 		})
 		return
 	}
@@ -454,18 +796,25 @@ func (s *Server) PutVisitHandler(ctx context.Context, c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load visit"})
 		return
 	}
+	if !unmodifiedSince.IsZero() && existing.ModifiedSince(unmodifiedSince) {
+		writeVisitModified(c, existing.UpdatedAt)
+		return
+	}
 
 	merged := *existing
 
 	if body.VisitedTime != nil {
-		t := time.Unix(*body.VisitedTime, 0).UTC()
-		minDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
-		now := time.Now().UTC()
-		maxDate := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, time.UTC)
-		if t.Before(minDate) || t.After(maxDate) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "visitedTime must be between 1900-01-01 and current date"})
+		t := body.VisitedTime.Time
+		if err := models.ValidateVisitedTime(t); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if historic, ok := data.HistoricCountryByCode(merged.CountryCode); ok {
+			if err := historic.ValidateVisitedTime(t); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
 		merged.VisitedTime = t
 	}
 
@@ -499,34 +848,122 @@ func (s *Server) PutVisitHandler(ctx context.Context, c *gin.Context) {
 		merged.Notes = *body.Notes
 	}
 
-	if err := s.db.ReplaceCountryVisit(ctx, &merged); err != nil {
+	if body.IsPrivate != nil {
+		merged.IsPrivate = *body.IsPrivate
+	}
+
+	if body.VisitType != nil {
+		if err := models.ValidateVisitType(*body.VisitType); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		merged.VisitType = *body.VisitType
+	}
+
+	if body.SubdivisionCode != nil {
+		if *body.SubdivisionCode == "" {
+			merged.SubdivisionCode = ""
+		} else {
+			code, ok := subdivisions.Validate(merged.CountryCode, *body.SubdivisionCode)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subdivisionCode for countryCode"})
+				return
+			}
+			merged.SubdivisionCode = code
+		}
+	}
+
+	if body.DestinationCode != nil {
+		if *body.DestinationCode == "" {
+			merged.DestinationCode = ""
+		} else {
+			code, ok := data.ValidateTCCDestination(merged.CountryCode, *body.DestinationCode)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid destinationCode for countryCode"})
+				return
+			}
+			merged.DestinationCode = code
+		}
+	}
+
+	if body.Location.set {
+		if body.Location.value != nil {
+			if err := body.Location.value.Validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		merged.Location = body.Location.value
+	}
+
+	friends, err := s.db.GetFriendsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetFriendsByUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		return
+	}
+	if body.Companions != nil {
+		companions, err := validateCompanions(*body.Companions, friends, existing.Companions)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		merged.Companions = companions
+	}
+
+	if err := s.db.ReplaceCountryVisit(ctx, &merged, unmodifiedSince); err != nil {
+		if errors.Is(err, database.ErrVisitModified) {
+			writeVisitModified(c, nil)
+			return
+		}
 		log.Error("ReplaceCountryVisit failed", logging.Error, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update visit"})
 		return
 	}
+	attachCompanionFriendsTo(&merged, friends)
+	attachSuccessorCodesTo(&merged)
+	if merged.UpdatedAt != nil {
+		c.Header("Last-Modified", merged.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
 	log.Info("Updated country visit", logging.VisitID, merged.ID, logging.UserID, user.ID)
-	c.JSON(http.StatusOK, &merged)
+	writeJSON(c, http.StatusOK, &merged)
+}
+
+// writeVisitModified responds 412 to a visit update whose If-Unmodified-Since precondition
+// failed, with Last-Modified set to updatedAt when known.
+func writeVisitModified(c *gin.Context, updatedAt *time.Time) {
+	if updatedAt != nil {
+		c.Header("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	}
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error": "the visit was modified after If-Unmodified-Since; reload it and retry",
+	})
 }
 
 // DeleteVisitHandler handles DELETE /visits/:id.
-// Deletes the country visit if it belongs to the current user. Returns 204 on success.
+// Deletes the country visit if it belongs to the current user, along with its proof files.
+// Returns 204 on success.
 func (s *Server) DeleteVisitHandler(ctx context.Context, c *gin.Context) {
 	ctx, span := tracing.New(ctx, "DeleteVisitHandler")
 	defer span.End()
 
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
 	visitID := c.Param("id")
 	if visitID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "visit id required"})
 		return
 	}
 
+	// Proof files go with the visit; look them up while the document still exists. A failed
+	// lookup surfaces again in DeleteCountryVisit.
+	var visitProofs []models.VisitProof
+	if s.proofs != nil {
+		if existing, err := s.db.GetCountryVisit(ctx, visitID, user.ID); err == nil {
+			visitProofs = existing.Proofs
+		}
+	}
+
 	err := s.db.DeleteCountryVisit(ctx, visitID, user.ID)
 	if err != nil {
 		if errors.Is(err, database.ErrVisitNotFound) {
@@ -537,63 +974,51 @@ func (s *Server) DeleteVisitHandler(ctx context.Context, c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete visit"})
 		return
 	}
+	s.deleteProofObjects(ctx, user.ID, visitID, visitProofs)
 	c.Status(http.StatusNoContent)
 }
 
-// PostFriendsHandler handles POST /friends.
-// Adds a friend by ShareToken and Name. Returns 201 with the created friend, 409 if already added, 404 if share token invalid.
-func (s *Server) PostFriendsHandler(ctx context.Context, c *gin.Context) {
-	ctx, span := tracing.New(ctx, "PostFriendsHandler")
+// patchFriendBody is the body of PATCH /friends/:shareToken.
+type patchFriendBody struct {
+	Nickname *string `json:"nickname"`
+}
+
+// PatchFriendHandler handles PATCH /friends/:shareToken.
+// Body: { "nickname" }; an empty nickname clears it. Returns 200 with the updated Friend, 400
+// for an invalid body and 404 if the friend is not in the user's list.
+func (s *Server) PatchFriendHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PatchFriendHandler")
 	defer span.End()
 
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("POST /friends: user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
-	var body struct {
-		ShareToken string `json:"shareToken"`
-		Name       string `json:"name"`
-		ImageUrl   string `json:"imageUrl"`
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
+	shareToken := c.Param("shareToken")
+	var body patchFriendBody
 	if err := c.ShouldBindJSON(&body); err != nil {
-		log.Warn("Invalid POST /friends body", logging.Error, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
-	if body.ShareToken == "" || body.Name == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "shareToken and name are required"})
+	if body.Nickname == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nickname is required"})
 		return
 	}
-	// Validate that the share token corresponds to an existing user
-	shareUser, err := s.db.GetUserByShareToken(ctx, body.ShareToken)
-	if err != nil {
-		log.Error("GetUserByShareToken failed", logging.Error, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate share token"})
-		return
-	}
-	if shareUser == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+	nickname := strings.TrimSpace(*body.Nickname)
+	if err := models.ValidateFriendNickname(nickname); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	imageURL := body.ImageUrl
-	if imageURL == "" && shareUser.ImageURL != "" {
-		imageURL = shareUser.ImageURL
-	}
-	friend, err := s.db.AddFriend(ctx, user.ID, body.ShareToken, body.Name, imageURL)
+	friend, err := s.db.UpdateFriendNickname(ctx, user.ID, shareToken, nickname)
 	if err != nil {
-		if errors.Is(err, database.ErrFriendAlreadyExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": "friend already added"})
+		if errors.Is(err, database.ErrFriendNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "friend not found"})
 			return
 		}
-		log.Error("AddFriend failed", logging.Error, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add friend"})
+		log.Error("UpdateFriendNickname failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update friend"})
 		return
 	}
-	log.Info("Added friend", logging.UserID, user.ID, "shareToken", body.ShareToken)
-	c.JSON(http.StatusCreated, friend)
+	log.Info("Updated friend nickname", logging.UserID, user.ID, "shareToken", shareToken)
+	writeJSON(c, http.StatusOK, friend)
 }
 
 // DeleteFriendHandler handles DELETE /friends/:shareToken.
@@ -603,12 +1028,7 @@ func (s *Server) DeleteFriendHandler(ctx context.Context, c *gin.Context) {
 	defer span.End()
 
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("DELETE /friends: user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
-		return
-	}
+	user := ctxkeys.MustCurrentUser(ctx)
 	shareToken := c.Param("shareToken")
 	if shareToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "shareToken is required"})
@@ -628,28 +1048,83 @@ func (s *Server) DeleteFriendHandler(ctx context.Context, c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// GetFriendsHandler handles GET /friends. Returns the list of Friend objects for the current user.
+// GetFriendsHandler handles GET /friends?limit=<n>&cursor=<cursor>&include=summary. Returns a
+// page of the current user's Friend objects, refreshing stale names and avatars from the
+// friends' profiles (see syncFriendProfiles). limit defaults to models.DefaultFriendsLimit (at
+// most models.MaxFriendsLimit); nextCursor fetches the next page. include=summary adds each
+// friend's visit counts (see addFriendSummaries).
 func (s *Server) GetFriendsHandler(ctx context.Context, c *gin.Context) {
 	ctx, span := tracing.New(ctx, "GetFriendsHandler")
 	defer span.End()
 
 	log := logging.FromContext(ctx)
-	user, _ := ctx.Value(ctxkeys.CurrentUserKey).(*models.User)
-	if user == nil {
-		log.Warn("GET /friends: user not in context")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
+	user := ctxkeys.MustCurrentUser(ctx)
+	limit := models.DefaultFriendsLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > models.MaxFriendsLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be an integer between 1 and " +
+					strconv.Itoa(models.MaxFriendsLimit),
+			})
+			return
+		}
+		limit = n
+	}
+	// The cursor is a friend document ID
+	cursor := c.Query("cursor")
+	if strings.Contains(cursor, "/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
 		return
 	}
-	friends, err := s.db.GetFriendsByUser(ctx, user.ID)
+	include := c.Query("include")
+	if include != "" && include != "summary" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "include must be summary"})
+		return
+	}
+	friends, nextCursor, err := s.db.GetFriendsPage(ctx, user.ID, cursor, limit)
 	if err != nil {
-		log.Error("GetFriendsByUser failed", logging.Error, err)
+		log.Error("GetFriendsPage failed", logging.Error, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
 		return
 	}
-	if friends == nil {
-		friends = []models.Friend{}
+	friends = s.syncFriendProfiles(ctx, user.ID, friends)
+	if include == "summary" {
+		if err := s.addFriendSummaries(ctx, friends); err != nil {
+			log.Error("Adding friend summaries failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+			return
+		}
 	}
-	c.JSON(http.StatusOK, models.LoginResponse{Friends: friends})
+	writeJSON(c, http.StatusOK, models.FriendsResponse{Friends: friends, NextCursor: nextCursor})
 }
 
+// GetFollowersHandler handles GET /friends/followers. Returns the users who added the current
+// user as a friend, marking those the current user has as friends too.
+func (s *Server) GetFollowersHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetFollowersHandler")
+	defer span.End()
 
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	followers, err := s.db.GetFollowers(ctx, user.ID)
+	if err != nil {
+		log.Error("GetFollowers failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch followers"})
+		return
+	}
+	friends, err := s.db.GetFriendsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetFriendsByUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		return
+	}
+	friendTokens := make(map[string]bool, len(friends))
+	for _, f := range friends {
+		friendTokens[f.ShareToken] = true
+	}
+	for i := range followers {
+		followers[i].IsFriend = friendTokens[followers[i].ShareToken]
+	}
+	writeJSON(c, http.StatusOK, models.FollowersResponse{Followers: followers})
+}
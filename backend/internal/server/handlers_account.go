@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/mailer"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetAccountHandler handles GET /account. Returns the AccountStatus of the current user.
+func (s *Server) GetAccountHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetAccountHandler")
+	defer span.End()
+
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	writeJSON(c, http.StatusOK, models.AccountStatusOf(dbUser))
+}
+
+// DeleteAccountHandler handles DELETE /account. Schedules the account for deletion after
+// models.AccountDeletionGracePeriod and returns 202 with the AccountStatus; the user's shares
+// are disabled at once. Repeating the request keeps the original date. API keys get 403.
+func (s *Server) DeleteAccountHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteAccountHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	if !dbUser.PendingDeletion() {
+		at := time.Now().UTC().Add(models.AccountDeletionGracePeriod)
+		if err := s.db.ScheduleAccountDeletion(ctx, dbUser.ID, at); err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+				return
+			}
+			log.Error("ScheduleAccountDeletion failed", logging.UserID, dbUser.ID,
+				logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete account"})
+			return
+		}
+		dbUser.DeletionScheduledAt = &at
+		log.Info("Scheduled account deletion", logging.UserID, dbUser.ID)
+		s.recordAudit(ctx, c, dbUser.ID, models.AuditAccountDeletionScheduled, "")
+		s.sendAccountNotice(ctx, dbUser, s.deletionScheduledMessage(dbUser, at))
+	}
+	writeJSON(c, http.StatusAccepted, models.AccountStatusOf(dbUser))
+}
+
+// PostCancelAccountDeletionHandler handles POST /account/cancel-deletion. Cancels a pending
+// deletion and re-enables the user's shares. Returns 200 with the AccountStatus; 403 for API
+// keys and 409 when no deletion is pending.
+func (s *Server) PostCancelAccountDeletionHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostCancelAccountDeletionHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	if !dbUser.PendingDeletion() {
+		c.JSON(http.StatusConflict, gin.H{"error": "account deletion is not pending"})
+		return
+	}
+	if err := s.db.CancelAccountDeletion(ctx, dbUser.ID); err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+			return
+		}
+		log.Error("CancelAccountDeletion failed", logging.UserID, dbUser.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel account deletion"})
+		return
+	}
+	dbUser.DeletionScheduledAt = nil
+	log.Info("Cancelled account deletion", logging.UserID, dbUser.ID)
+	s.recordAudit(ctx, c, dbUser.ID, models.AuditAccountDeletionCancelled, "")
+	writeJSON(c, http.StatusOK, models.AccountStatusOf(dbUser))
+}
+
+// deletionScheduledMessage returns the email telling user that their account will be deleted
+// at at, in case the request was not theirs.
+func (s *Server) deletionScheduledMessage(user *models.User, at time.Time) mailer.Message {
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Your My Travel: Visited Countries account will be deleted",
+		Body: fmt.Sprintf("Your account and all your visits will be deleted permanently on %s. "+
+			"Your shares have been disabled.\n\n"+
+			"Changed your mind, or did not ask for this? Sign in before then and cancel the "+
+			"deletion:\n%s\n",
+			at.Format("January 2, 2006"), s.publicBaseURL+"/"),
+	}
+}
+
+// sendAccountNotice emails user msg about a change of their account, when email is configured
+// and the user has an address. The change is made already, so failures are only logged.
+func (s *Server) sendAccountNotice(ctx context.Context, user *models.User, msg mailer.Message) {
+	if s.mailer == nil || user.Email == "" || isDryRun(ctx) {
+		return
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		logging.FromContext(ctx).Warn("Failed to send account notice", logging.UserID, user.ID,
+			logging.Error, err)
+	}
+}
+
+// currentDBUser returns the User document of the current user. Otherwise it writes 404 (login
+// not completed) or 500 and returns false.
+func (s *Server) currentDBUser(ctx context.Context, c *gin.Context) (*models.User, bool) {
+	user := ctxkeys.MustCurrentUser(ctx)
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetUserByID failed", logging.UserID, user.ID,
+			logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return nil, false
+	}
+	if dbUser == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		return nil, false
+	}
+	return dbUser, true
+}
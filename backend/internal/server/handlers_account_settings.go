@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetAccountSettingsHandler handles GET /me/settings.
+// Returns the current user's AccountSettings; 404 without a user document.
+func (s *Server) GetAccountSettingsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetAccountSettingsHandler")
+	defer span.End()
+
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	writeJSON(c, http.StatusOK, models.AccountSettings{SharingDisabled: dbUser.SharingDisabled})
+}
+
+// PatchAccountSettingsHandler handles PATCH /me/settings.
+// Body: { "sharingDisabled" }. Disabling sharing makes the share views 404 for the user's
+// ShareToken and share links until enabled again; neither is changed. Returns 200 with the
+// AccountSettings, 400 for an invalid body and 404 without a user document.
+func (s *Server) PatchAccountSettingsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PatchAccountSettingsHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		SharingDisabled *bool `json:"sharingDisabled"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if body.SharingDisabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sharingDisabled is required"})
+		return
+	}
+	if err := s.db.SetSharingDisabled(ctx, user.ID, *body.SharingDisabled); err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+			return
+		}
+		log.Error("SetSharingDisabled failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update settings"})
+		return
+	}
+	log.Info("Updated account settings", logging.UserID, user.ID,
+		"sharingDisabled", *body.SharingDisabled)
+	writeJSON(c, http.StatusOK, models.AccountSettings{SharingDisabled: *body.SharingDisabled})
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/mailer"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// testMailer records the messages sent, failing with err when set.
+type testMailer struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mailer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestDeleteAccountNotice(t *testing.T) {
+	mail := &testMailer{}
+	s, db := newTestServer(t, WithMailer(mail, "https://example.com"))
+	err := db.EnsureUser(context.Background(), &models.User{ID: "u1", Email: "u1@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requireStatus(t, doAs(t, s, "u1", http.MethodDelete, "/account", ""), http.StatusAccepted)
+	if len(mail.sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(mail.sent))
+	}
+	msg := mail.sent[0]
+	if msg.To != "u1@example.com" {
+		t.Errorf("To = %q, want u1@example.com", msg.To)
+	}
+	if !strings.Contains(msg.Body, "https://example.com/") {
+		t.Errorf("Body does not link to the app: %q", msg.Body)
+	}
+
+	// Repeating keeps the original date, so the user is not told again
+	requireStatus(t, doAs(t, s, "u1", http.MethodDelete, "/account", ""), http.StatusAccepted)
+	if len(mail.sent) != 1 {
+		t.Errorf("sent %d messages after repeating, want 1", len(mail.sent))
+	}
+}
+
+func TestDeleteAccountNoticeFailure(t *testing.T) {
+	mail := &testMailer{err: errors.New("smtp down")}
+	s, db := newTestServer(t, WithMailer(mail, "https://example.com"))
+	err := db.EnsureUser(context.Background(), &models.User{ID: "u1", Email: "u1@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requireStatus(t, doAs(t, s, "u1", http.MethodDelete, "/account", ""), http.StatusAccepted)
+	u, err := db.GetUserByID(context.Background(), "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !u.PendingDeletion() {
+		t.Error("deletion not scheduled after the notice failed")
+	}
+}
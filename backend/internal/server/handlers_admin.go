@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/backfill"
+	"github.com/matti777/my-countries/backend/internal/buildinfo"
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetBackfillHandler handles GET /admin/backfills/:name.
+// Returns the job's progress checkpoint (status, cursor, processed and failed counts).
+func (s *Server) GetBackfillHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetBackfillHandler")
+	defer span.End()
+
+	if s.backfill == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backfill not configured"})
+		return
+	}
+	job, err := s.backfill.Status(ctx, c.Param("name"))
+	if err != nil {
+		s.writeBackfillError(ctx, c, err)
+		return
+	}
+	writeJSON(c, http.StatusOK, job)
+}
+
+// PostBackfillStartHandler handles POST /admin/backfills/:name/start.
+// Starts the job in the background on this instance, resuming a paused or interrupted job
+// after its cursor; ?restart=true starts over from the first user. 202 with the checkpoint.
+func (s *Server) PostBackfillStartHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostBackfillStartHandler")
+	defer span.End()
+
+	if s.backfill == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backfill not configured"})
+		return
+	}
+	restart := false
+	if raw := c.Query("restart"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "restart must be true or false"})
+			return
+		}
+		restart = v
+	}
+
+	job, err := s.backfill.Start(ctx, c.Param("name"), restart)
+	if err != nil {
+		s.writeBackfillError(ctx, c, err)
+		return
+	}
+	logging.FromContext(ctx).Info("Backfill job start requested", logging.BackfillJob, job.Name)
+	writeJSON(c, http.StatusAccepted, job)
+}
+
+// PostBackfillPauseHandler handles POST /admin/backfills/:name/pause.
+// Stops the job on this instance after the current user; progress up to the last checkpoint is
+// kept and POST .../start resumes from it. 202 with the checkpoint as of the request.
+func (s *Server) PostBackfillPauseHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostBackfillPauseHandler")
+	defer span.End()
+
+	if s.backfill == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backfill not configured"})
+		return
+	}
+	name := c.Param("name")
+	if err := s.backfill.Pause(name); err != nil {
+		s.writeBackfillError(ctx, c, err)
+		return
+	}
+	job, err := s.backfill.Status(ctx, name)
+	if err != nil {
+		s.writeBackfillError(ctx, c, err)
+		return
+	}
+	writeJSON(c, http.StatusAccepted, job)
+}
+
+// writeBackfillError maps backfill.Runner errors to responses.
+func (s *Server) writeBackfillError(ctx context.Context, c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, backfill.ErrUnknownJob):
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown backfill job"})
+	case errors.Is(err, backfill.ErrAlreadyRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": "backfill job already running"})
+	case errors.Is(err, backfill.ErrNotRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": "backfill job not running on this instance"})
+	default:
+		logging.FromContext(ctx).Error("Backfill request failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load backfill job"})
+	}
+}
+
+// GetCountryOverridesHandler handles GET /admin/country-overrides.
+// Returns the stored overrides and the version of the country lists on this instance.
+func (s *Server) GetCountryOverridesHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetCountryOverridesHandler")
+	defer span.End()
+
+	if s.overrides == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "country overrides not configured"})
+		return
+	}
+	overrides, err := s.overrides.List(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to list country overrides", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load country overrides"})
+		return
+	}
+	overrides = jsontime.SetAll(overrides, timeFormatFromContext(ctx))
+	writeJSON(c, http.StatusOK, gin.H{"version": data.Version(), "overrides": overrides})
+}
+
+// PutCountryOverrideHandler handles PUT /admin/country-overrides/:code.
+// Replaces the override of the alpha-2 code and applies it on this instance at once; other
+// instances pick it up on their next refresh. Adding a country requires name and regionCode.
+func (s *Server) PutCountryOverrideHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PutCountryOverrideHandler")
+	defer span.End()
+
+	if s.overrides == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "country overrides not configured"})
+		return
+	}
+	var override models.CountryOverride
+	if err := c.ShouldBindJSON(&override); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	override.CountryCode = strings.ToUpper(c.Param("code"))
+	fields := override.Validate()
+	if !data.IsEmbeddedCountry(override.CountryCode) {
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		if override.Name == "" {
+			fields["name"] = "is required when adding a country"
+		}
+		if override.RegionCode == "" {
+			fields["regionCode"] = "is required when adding a country"
+		}
+	}
+	if len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(fields))
+		return
+	}
+
+	if err := s.overrides.Save(ctx, &override); err != nil {
+		logging.FromContext(ctx).Error("Failed to save country override", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save country override"})
+		return
+	}
+	logging.FromContext(ctx).Info("Saved country override",
+		logging.CountryCode, override.CountryCode)
+	writeJSON(c, http.StatusOK, override)
+}
+
+// DeleteCountryOverrideHandler handles DELETE /admin/country-overrides/:code.
+// Restores the bundled entry (or removes an added country) on this instance at once. 204 on
+// success, 404 if the code has no override.
+func (s *Server) DeleteCountryOverrideHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteCountryOverrideHandler")
+	defer span.End()
+
+	if s.overrides == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "country overrides not configured"})
+		return
+	}
+	code := strings.ToUpper(c.Param("code"))
+	if err := s.overrides.Delete(ctx, code); err != nil {
+		if errors.Is(err, database.ErrCountryOverrideNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "country override not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("Failed to delete country override", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete country override"})
+		return
+	}
+	logging.FromContext(ctx).Info("Deleted country override", logging.CountryCode, code)
+	c.Status(http.StatusNoContent)
+}
+
+// GetAdminStatusHandler handles GET /admin/status.
+// Aggregates the live operational signals of this instance (request error rates over the last
+// five minutes, cache hit rates, running background jobs, JWKS age, build and injected faults)
+// into one document. Reads no data from Firestore, so it answers even when the database is
+// degraded.
+func (s *Server) GetAdminStatusHandler(ctx context.Context, c *gin.Context) {
+	_, span := tracing.New(ctx, "GetAdminStatusHandler")
+	defer span.End()
+
+	now := time.Now().UTC()
+	total, clientErrors, serverErrors := s.requestStats.snapshot(now)
+	status := models.AdminStatusResponse{
+		Build: models.BuildStatus{
+			Version:       buildinfo.Version,
+			Revision:      buildinfo.Revision(),
+			GoVersion:     buildinfo.GoVersion(),
+			StartedAt:     s.startedAt,
+			UptimeSeconds: int64(now.Sub(s.startedAt) / time.Second),
+		},
+		Requests: models.RequestStatus{
+			WindowSeconds: int(requestStatsWindow / time.Second),
+			Total:         total,
+			ClientErrors:  clientErrors,
+			ServerErrors:  serverErrors,
+		},
+		Caches:      map[string]models.CacheStatus{},
+		Queues:      map[string]int{},
+		ReadOnly:    s.readOnly,
+		RateLimited: s.rateLimitedCounts(),
+	}
+	if total > 0 {
+		status.Requests.ErrorRate = float64(serverErrors) / float64(total)
+	}
+
+	hits, misses, entries := s.orgVisits.stats()
+	orgCache := models.NewCacheStatus(hits, misses)
+	orgCache.Entries = &entries
+	status.Caches["orgVisits"] = orgCache
+	if s.shareTokens != nil {
+		hits, misses := s.shareTokens.stats()
+		status.Caches["shareTokens"] = models.NewCacheStatus(hits, misses)
+	}
+	if s.imageProxy != nil {
+		st := s.imageProxy.CacheStats()
+		status.Caches["images"] = models.NewCacheStatus(st.Hits, st.Misses)
+	}
+	if s.backfill != nil {
+		status.Queues["backfillJobsRunning"] = s.backfill.Running()
+	}
+	if s.writes != nil {
+		jobs, writes := s.writes.Pending()
+		status.Queues["writeJobsRunning"] = jobs
+		status.Queues["batchWritesPending"] = writes
+	}
+	if s.auth != nil {
+		if fetched := s.auth.JWKSFetchedAt(); !fetched.IsZero() {
+			age := int64(now.Sub(fetched) / time.Second)
+			status.Auth.JWKSFetchedAt = &fetched
+			status.Auth.JWKSAgeSeconds = &age
+		}
+	}
+	if s.faults != nil {
+		cfg := s.faults.Config()
+		delayed, failed := s.faults.Counts()
+		status.Faults = &models.FaultStatus{
+			LatencyMaxMs:            cfg.Latency.Milliseconds(),
+			LatencyPercent:          cfg.LatencyRate * 100,
+			FirestoreErrorPercent:   cfg.FirestoreErrorRate * 100,
+			DelayedRequests:         delayed,
+			FailedFirestoreRequests: failed,
+		}
+	}
+
+	c.Header("Cache-Control", "no-store")
+	writeJSON(c, http.StatusOK, status)
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetAPIKeysHandler handles GET /me/api-keys.
+// Returns the current user's API keys, newest first, without the keys themselves.
+func (s *Server) GetAPIKeysHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetAPIKeysHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	keys, err := s.db.GetAPIKeys(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetAPIKeys failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch API keys"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.APIKeysResponse{APIKeys: keys})
+}
+
+// PostAPIKeyHandler handles POST /me/api-keys.
+// Body: { "name" }. Creates an API key for the current user; the response is the only time the
+// key is returned. Returns 201 with the CreatedAPIKeyResponse; 400 for an invalid name, 403 when
+// the request itself authenticated with an API key, 404 without a user document and 409 when
+// the user has models.MaxAPIKeys keys.
+func (s *Server) PostAPIKeyHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostAPIKeyHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if err := models.ValidateAPIKeyName(name); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"name": err.Error(),
+		}))
+		return
+	}
+	if _, ok := s.currentDBUser(ctx, c); !ok {
+		return
+	}
+	key, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		log.Error("NewAPIKey failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API key"})
+		return
+	}
+	apiKey, err := s.db.CreateAPIKey(ctx, &models.APIKey{
+		UserID:  user.ID,
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: auth.HashAPIKey(key),
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrTooManyAPIKeys) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Error("CreateAPIKey failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API key"})
+		return
+	}
+	log.Info("Created API key", logging.UserID, user.ID, "apiKeyId", apiKey.ID)
+	s.recordAudit(ctx, c, user.ID, models.AuditAPIKeyCreated, apiKey.ID)
+	writeJSON(c, http.StatusCreated, models.CreatedAPIKeyResponse{APIKey: *apiKey, Key: key})
+}
+
+// DeleteAPIKeyHandler handles DELETE /me/api-keys/:id.
+// Revokes an API key at once. Returns 204; 403 when the request itself authenticated with an
+// API key and 404 when not found.
+func (s *Server) DeleteAPIKeyHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteAPIKeyHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if err := s.db.DeleteAPIKey(ctx, user.ID, c.Param("id")); err != nil {
+		if errors.Is(err, database.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("DeleteAPIKey failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete API key"})
+		return
+	}
+	logging.FromContext(ctx).Info("Revoked API key", logging.UserID, user.ID,
+		"apiKeyId", c.Param("id"))
+	s.recordAudit(ctx, c, user.ID, models.AuditAPIKeyRevoked, c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetAuditHandler handles GET /me/audit?limit=<n>.
+// Returns the current user's most recent audit events, newest first. limit defaults to
+// models.DefaultAuditLimit (max models.MaxAuditLimit).
+func (s *Server) GetAuditHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetAuditHandler")
+	defer span.End()
+
+	s.writeAuditEvents(ctx, c, ctxkeys.MustCurrentUser(ctx).ID)
+}
+
+// GetAdminUserAuditHandler handles GET /admin/users/:userId/audit?limit=<n>.
+// Returns the audit events of any user, as GET /me/audit does for the current user; 404 when
+// the user does not exist.
+func (s *Server) GetAdminUserAuditHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetAdminUserAuditHandler")
+	defer span.End()
+
+	userID := c.Param("userId")
+	u, err := s.db.GetUserByID(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetUserByID failed", logging.UserID, userID,
+			logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return
+	}
+	if u == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	s.writeAuditEvents(ctx, c, userID)
+}
+
+// writeAuditEvents responds with the audit events of userID, limited by the limit query
+// parameter.
+func (s *Server) writeAuditEvents(ctx context.Context, c *gin.Context, userID string) {
+	limit := models.DefaultAuditLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > models.MaxAuditLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be an integer between 1 and " +
+					strconv.Itoa(models.MaxAuditLimit),
+			})
+			return
+		}
+		limit = n
+	}
+	events, err := s.db.GetAuditEvents(ctx, userID, limit)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetAuditEvents failed", logging.UserID, userID,
+			logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch audit events"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.AuditEventsResponse{Events: events})
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/backup"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetBackupsHandler handles GET /admin/backup.
+// Lists the backups in the backup bucket, newest first, with the status of their export.
+func (s *Server) GetBackupsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetBackupsHandler")
+	defer span.End()
+
+	if s.backups == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backups not configured"})
+		return
+	}
+	backups, err := s.backups.List(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to list backups", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list backups"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	writeJSON(c, http.StatusOK, models.BackupsResponse{Backups: backups})
+}
+
+// PostBackupHandler handles POST /admin/backup.
+// Starts an export of the users collection tree; 202 with the running Backup. Firestore runs
+// the export, so it continues when this instance stops.
+func (s *Server) PostBackupHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostBackupHandler")
+	defer span.End()
+
+	if s.backups == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backups not configured"})
+		return
+	}
+	b, err := s.backups.Start(ctx, models.BackupTriggerAdmin, time.Now())
+	if errors.Is(err, backup.ErrAlreadyStarted) {
+		c.JSON(http.StatusConflict, gin.H{"error": "backup already started"})
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to start backup", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start backup"})
+		return
+	}
+	writeJSON(c, http.StatusAccepted, b)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetBlockedHandler handles GET /blocked. Returns the users blocked by the current user, most
+// recently blocked first.
+func (s *Server) GetBlockedHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetBlockedHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	blocked, err := s.db.GetBlockedUsers(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetBlockedUsers failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch blocked users"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.BlockedUsersResponse{Blocked: blocked})
+}
+
+// PostBlockedHandler handles POST /blocked.
+// Body: { "shareToken" } of the user to block. Removes the friendship on both sides and pending
+// friend requests between the two, then returns 201 with the BlockedUser. 404 for an unknown
+// share token, 400 for the user's own token.
+func (s *Server) PostBlockedHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostBlockedHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		ShareToken string `json:"shareToken"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.ShareToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "shareToken is required"})
+		return
+	}
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error("GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return
+	}
+	if dbUser == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		return
+	}
+	target, err := s.db.GetUserByShareToken(ctx, body.ShareToken)
+	if err != nil {
+		log.Error("GetUserByShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate share token"})
+		return
+	}
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+	if target.ID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot block yourself"})
+		return
+	}
+	blocked, err := s.db.BlockUser(ctx, user.ID, dbUser.ShareToken, &models.BlockedUser{
+		UserID:     target.ID,
+		ShareToken: target.ShareToken,
+		Name:       target.Name,
+		ImageURL:   target.ImageURL,
+	})
+	if err != nil {
+		log.Error("BlockUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to block user"})
+		return
+	}
+	log.Info("Blocked user", logging.UserID, user.ID, "shareToken", body.ShareToken)
+	writeJSON(c, http.StatusCreated, blocked)
+}
+
+// DeleteBlockedHandler handles DELETE /blocked/:shareToken. Unblocks the user; the removed
+// friendship is not restored. Returns 204, or 404 if the share token is not blocked.
+func (s *Server) DeleteBlockedHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteBlockedHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	shareToken := c.Param("shareToken")
+	if err := s.db.UnblockUser(ctx, user.ID, shareToken); err != nil {
+		if errors.Is(err, database.ErrBlockedUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "blocked user not found"})
+			return
+		}
+		log.Error("UnblockUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unblock user"})
+		return
+	}
+	log.Info("Unblocked user", logging.UserID, user.ID, "shareToken", shareToken)
+	c.Status(http.StatusNoContent)
+}
+
+// isBlockedViewer reports whether the signed-in user of the request, if any, was blocked by the
+// user ownerID. Public share handlers answer such viewers as if the share did not exist.
+func (s *Server) isBlockedViewer(ctx context.Context, ownerID string) (bool, error) {
+	viewer, ok := ctxkeys.CurrentUser(ctx)
+	if !ok || viewer.ID == ownerID {
+		return false, nil
+	}
+	return s.db.IsBlocked(ctx, ownerID, viewer.ID)
+}
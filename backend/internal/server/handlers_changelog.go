@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/buildinfo"
+	"github.com/matti777/my-countries/backend/internal/changelog"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetChangelogHandler handles GET /api/changelog.
+// Returns the API changelog embedded at build time. Unauthenticated.
+func (s *Server) GetChangelogHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetChangelogHandler")
+	defer span.End()
+
+	entries, err := changelog.Entries()
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to load changelog", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load changelog"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	writeJSON(c, http.StatusOK, models.ChangelogResponse{
+		APIVersion: changelog.CurrentVersion(),
+		AppVersion: buildinfo.Version,
+		Changes:    entries,
+	})
+}
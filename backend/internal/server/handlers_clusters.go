@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/cluster"
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetVisitClustersHandler handles GET /visits/clusters?zoom=.
+// Loads the current user's visits and groups those with a location into map clusters for the
+// zoom level, so clients do not need to cluster thousands of points themselves.
+func (s *Server) GetVisitClustersHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetVisitClustersHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	zoom, err := strconv.Atoi(c.Query("zoom"))
+	if err != nil || zoom < 0 || zoom > cluster.MaxZoom {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("zoom must be an integer between 0 and %d", cluster.MaxZoom),
+		})
+		return
+	}
+
+	visits, err := s.db.GetCountryVisitsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetCountryVisitsByUser failed for clusters", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch country visits"})
+		return
+	}
+	clusters, unlocated := cluster.Visits(visits, zoom)
+	log.Info("Clustered country visits", logging.Count, len(clusters))
+	writeJSON(c, http.StatusOK, models.VisitClustersResponse{
+		Zoom:      zoom,
+		Clusters:  clusters,
+		Unlocated: unlocated,
+	})
+}
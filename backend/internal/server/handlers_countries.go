@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/data/geometry"
+	"github.com/matti777/my-countries/backend/internal/data/subdivisions"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetSubdivisionsHandler handles GET /countries/:code/subdivisions.
+// Returns the bundled ISO 3166-2 subdivisions of a listed country or territory (empty when
+// none are bundled).
+func (s *Server) GetSubdivisionsHandler(ctx context.Context, c *gin.Context) {
+	_, span := tracing.New(ctx, "GetSubdivisionsHandler")
+	defer span.End()
+
+	country, ok := data.CountryByCode(c.Param("code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "country not found"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	writeJSON(c, http.StatusOK, models.SubdivisionResponse{
+		Subdivisions: subdivisions.ByCountry(country.CountryCode),
+	})
+}
+
+// GetCountryHandler handles GET /countries/:code.
+// Returns a listed country or territory with its bundled metadata (capital, population,
+// currencies, languages; territories have none) and land neighbors. 404 for an unknown code.
+func (s *Server) GetCountryHandler(ctx context.Context, c *gin.Context) {
+	_, span := tracing.New(ctx, "GetCountryHandler")
+	defer span.End()
+
+	country, ok := data.CountryByCode(c.Param("code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "country not found"})
+		return
+	}
+	detail := models.CountryDetail{Country: country, Neighbors: data.Neighbors(country.CountryCode)}
+	if metadata, ok := data.CountryMetadata(country.CountryCode); ok {
+		detail.CountryMetadata = metadata
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	writeJSON(c, http.StatusOK, detail)
+}
+
+// GetCountryGeometryHandler handles GET /countries/:code/geometry.
+// Returns the country's simplified boundary as a GeoJSON Feature (application/geo+json) from the
+// embedded dataset, gzip-encoded when the client accepts it. ?detail=full|medium|low selects
+// the simplification level (default full). 404 for an unknown code or a country without
+// geometry (small states and territories).
+func (s *Server) GetCountryGeometryHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetCountryGeometryHandler")
+	defer span.End()
+
+	level, err := geometry.ParseLevel(c.Query("detail"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	country, ok := data.CountryByCode(c.Param("code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "country not found"})
+		return
+	}
+	feature, ok, err := geometry.Lookup(country.CountryCode, level)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to load geometry dataset", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load geometry"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "geometry not available for country"})
+		return
+	}
+
+	body, encoding := feature.JSON, "identity"
+	if strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		body, encoding = feature.Gzip, "gzip"
+	}
+	c.Header("Cache-Control", "public, max-age=604800")
+	c.Header("Vary", "Accept-Encoding")
+	// Strong ETags differ per content coding
+	if writeNotModifiedIfMatch(c, computeETag("geometry", encoding, string(feature.JSON))) {
+		return
+	}
+	if encoding == "gzip" {
+		c.Header("Content-Encoding", "gzip")
+	}
+	c.Data(http.StatusOK, "application/geo+json", body)
+}
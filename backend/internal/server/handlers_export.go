@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetExportHandler handles GET /me/export.
+// Streams all data of the current user (user document, settings, visits, friends, blocked users
+// and share links) as a JSON document, or with ?format=zip as a zip archive of one JSON file
+// per section, for download. Visits and friends are read page by page while writing. Returns
+// 400 for an unknown format and 404 without a user document. Once streaming has started,
+// errors can only cut the response short, leaving an invalid JSON document or zip archive.
+func (s *Server) GetExportHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetExportHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	blocked, err := s.db.GetBlockedUsers(ctx, dbUser.ID)
+	if err != nil {
+		log.Error("GetBlockedUsers failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export account"})
+		return
+	}
+	links, err := s.db.GetShareLinks(ctx, dbUser.ID)
+	if err != nil {
+		log.Error("GetShareLinks failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export account"})
+		return
+	}
+
+	now := time.Now().UTC()
+	filename := fmt.Sprintf("my-countries-export-%s.%s", now.Format(time.DateOnly), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	timeFormat := timeFormatFromContext(ctx)
+	var export *exportWriter
+	if format == "zip" {
+		c.Header("Content-Type", "application/zip")
+		export = newZipExportWriter(c.Writer, timeFormat)
+	} else {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		export = newJSONExportWriter(c.Writer, timeFormat)
+	}
+	c.Status(http.StatusOK)
+
+	err = s.writeExport(ctx, export, dbUser, blocked, links, now)
+	if err == nil {
+		err = export.Close()
+	}
+	if err != nil {
+		log.Error("Account export failed", logging.UserID, dbUser.ID, logging.Error, err)
+		return
+	}
+	log.Info("Exported account", logging.UserID, dbUser.ID, "format", format)
+}
+
+// writeExport writes the sections of the account export of dbUser.
+func (s *Server) writeExport(
+	ctx context.Context,
+	export *exportWriter,
+	dbUser *models.User,
+	blocked []models.BlockedUser,
+	links []models.ShareLink,
+	now time.Time,
+) error {
+	err := export.Object("user", models.AccountExportUser{
+		ExportedAt:          now,
+		UserID:              dbUser.ID,
+		Name:                dbUser.Name,
+		Email:               dbUser.Email,
+		ImageURL:            dbUser.ImageURL,
+		ShareToken:          dbUser.ShareToken,
+		Handle:              dbUser.Handle,
+		IsAnonymous:         dbUser.IsAnonymous,
+		SharingDisabled:     dbUser.SharingDisabled,
+		DeletionScheduledAt: dbUser.DeletionScheduledAt,
+	})
+	if err != nil {
+		return err
+	}
+	settings := models.SettingsToResponse(dbUser.EffectiveSettings())
+	if err := export.Object("settings", settings); err != nil {
+		return err
+	}
+	err = export.List("visits", func(emit func(v any) error) error {
+		return emitPages(emit, func(cursor string) ([]models.CountryVisit, string, error) {
+			return s.db.GetCountryVisitsPage(ctx, dbUser.ID, cursor, exportPageSize)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	err = export.List("friends", func(emit func(v any) error) error {
+		return emitPages(emit, func(cursor string) ([]models.Friend, string, error) {
+			return s.db.GetFriendsPage(ctx, dbUser.ID, cursor, exportPageSize)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := export.Object("blocked", blocked); err != nil {
+		return err
+	}
+	return export.Object("shareLinks", links)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetFeedHandler handles GET /feed?limit=<n>&cursor=<cursor>.
+// Returns the friends' non-private visits, most recent visitedTime first, each redacted by the
+// friend's sharing settings as in GET /friends/:shareToken/visits. limit defaults to
+// models.DefaultFeedLimit (at most models.MaxFeedLimit); nextCursor fetches the next page. Only
+// the first models.MaxFeedFriends friends with an account are included.
+func (s *Server) GetFeedHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetFeedHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+
+	limit := models.DefaultFeedLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > models.MaxFeedLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be an integer between 1 and " +
+					strconv.Itoa(models.MaxFeedLimit),
+			})
+			return
+		}
+		limit = n
+	}
+	var after *models.FeedCursor
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := models.ParseFeedCursor(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		after = &cursor
+	}
+
+	friends, err := s.db.GetFriendsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetFriendsByUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		return
+	}
+	shareTokens := make([]string, len(friends))
+	for i, f := range friends {
+		shareTokens[i] = f.ShareToken
+	}
+	friendUsers, err := s.db.GetUsersByShareTokens(ctx, shareTokens)
+	if err != nil {
+		log.Error("GetUsersByShareTokens failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		return
+	}
+	// Friends whose account is gone or being deleted are left out, as in their visits view
+	friendsByUserID := make(map[string]models.Friend, len(friends))
+	settingsByUserID := make(map[string]models.UserSettings, len(friends))
+	userIDs := make([]string, 0, len(friends))
+	for _, f := range friends {
+		u := friendUsers[f.ShareToken]
+		if u == nil || u.PendingDeletion() {
+			continue
+		}
+		if _, ok := friendsByUserID[u.ID]; ok {
+			continue
+		}
+		if len(userIDs) == models.MaxFeedFriends {
+			log.Warn("Feed limited to the first friends", "friends", len(friends),
+				"maxFeedFriends", models.MaxFeedFriends)
+			break
+		}
+		friendsByUserID[u.ID] = f
+		settingsByUserID[u.ID] = u.EffectiveSettings()
+		userIDs = append(userIDs, u.ID)
+	}
+
+	visits, err := s.db.GetFeedVisits(ctx, userIDs, after, limit)
+	if err != nil {
+		log.Error("GetFeedVisits failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch feed"})
+		return
+	}
+	attachSuccessorCodes(visits)
+	resp := models.FeedResponse{Items: make([]models.FeedItem, len(visits))}
+	for i := range visits {
+		redactSharedVisits(visits[i:i+1], settingsByUserID[visits[i].UserID])
+		resp.Items[i] = models.FeedItem{Friend: friendsByUserID[visits[i].UserID], Visit: visits[i]}
+	}
+	if len(visits) == limit {
+		resp.NextCursor = models.FeedCursorOf(visits[len(visits)-1]).String()
+	}
+	c.Header("Cache-Control", "private, no-cache")
+	writeJSON(c, http.StatusOK, resp)
+}
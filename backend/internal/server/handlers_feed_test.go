@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// TestFeedFriendLimit checks that GET /feed reads the visits of at most models.MaxFeedFriends
+// friends.
+func TestFeedFriendLimit(t *testing.T) {
+	s, db := newTestServer(t)
+	ctx := context.Background()
+	tester := "Bearer " + testTokenWithClaims("u1", `"tester":true`)
+	requireStatus(t, do(t, s, http.MethodPost, "/login", "", "Authorization", tester),
+		http.StatusOK)
+	me, err := db.GetUserByID(ctx, "u1")
+	if err != nil || me == nil {
+		t.Fatalf("GetUserByID = %v, %v", me, err)
+	}
+	for i := range models.MaxFeedFriends + 1 {
+		id := fmt.Sprintf("friend-%03d", i)
+		if err := db.EnsureUser(ctx, &models.User{ID: id, Name: id}); err != nil {
+			t.Fatal(err)
+		}
+		friend, err := db.GetUserByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := db.CreateFriendRequest(ctx, &models.FriendRequest{
+			FromUserID: id, FromShareToken: friend.ShareToken, FromName: id,
+			ToUserID: me.ID, ToShareToken: me.ShareToken, ToName: me.Name,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.AcceptFriendRequest(ctx, req.ID, me.ID, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.CreateCountryVisit(ctx, &models.CountryVisit{
+			UserID: id, CountryCode: "FI",
+			VisitedTime: time.Date(2020, 1, 1, 0, 0, i, 0, time.UTC),
+		}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	friends := make(map[string]bool)
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > models.MaxFeedFriends {
+			t.Fatal("no last page")
+		}
+		w := do(t, s, http.MethodGet, "/feed?limit=50&cursor="+url.QueryEscape(cursor), "",
+			"Authorization", tester, "X-Feature-Preview", "feed")
+		requireStatus(t, w, http.StatusOK)
+		var resp struct {
+			Items []struct {
+				Friend struct {
+					ShareToken string `json:"shareToken"`
+				} `json:"friend"`
+			} `json:"items"`
+			NextCursor string `json:"nextCursor"`
+		}
+		decode(t, w, &resp)
+		for _, item := range resp.Items {
+			friends[item.Friend.ShareToken] = true
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+	if len(friends) != models.MaxFeedFriends {
+		t.Errorf("feed has visits of %d friends, want %d", len(friends), models.MaxFeedFriends)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/mailer"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// PostFriendInviteHandler handles POST /friends/invite.
+// Body: { "email" }. Emails the invitee the current user's share link and stores a FriendInvite,
+// so that the invitee's friend request to the user is accepted automatically once they sign up
+// with that (verified) email. Returns 201 with the invite; 400 for an invalid email, 409 when an
+// invite to the email is pending or the user has too many, 502 when sending fails and 503
+// without a mailer.
+func (s *Server) PostFriendInviteHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostFriendInviteHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	if s.mailer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "email is not configured"})
+		return
+	}
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		return
+	}
+	email, err := models.NormalizeInviteEmail(body.Email)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	if strings.EqualFold(dbUser.Email, email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot invite yourself"})
+		return
+	}
+
+	invite, err := s.db.CreateFriendInvite(ctx, dbUser.ID, &models.FriendInvite{Email: email})
+	if err != nil {
+		if errors.Is(err, database.ErrFriendInviteExists) ||
+			errors.Is(err, database.ErrTooManyFriendInvites) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Error("CreateFriendInvite failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invite"})
+		return
+	}
+	if !isDryRun(ctx) {
+		if err := s.mailer.Send(ctx, s.friendInviteMessage(dbUser, invite)); err != nil {
+			log.Error("Failed to send friend invite", logging.Error, err)
+			// Removed so the invite can be retried
+			if err := s.db.DeleteFriendInvite(ctx, dbUser.ID, invite.ID); err != nil {
+				log.Warn("Failed to delete unsent friend invite", logging.Error, err)
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to send invite email"})
+			return
+		}
+	}
+	log.Info("Sent friend invite", logging.UserID, dbUser.ID)
+	writeJSON(c, http.StatusCreated, invite)
+}
+
+// friendInviteMessage returns the email inviting invite.Email to view from's share.
+func (s *Server) friendInviteMessage(
+	from *models.User,
+	invite *models.FriendInvite,
+) mailer.Message {
+	name := from.Name
+	if name == "" {
+		name = "A friend"
+	}
+	shareURL := s.publicBaseURL + "/share/" + url.PathEscape(from.ShareToken)
+	return mailer.Message{
+		To:      invite.Email,
+		Subject: name + " invited you to My Travel: Visited Countries",
+		Body: fmt.Sprintf("%s wants to share the countries they have visited with you.\n\n"+
+			"See their map and add them as a friend after signing in:\n%s\n\n"+
+			"When you sign in with this email address before %s, you and %s become friends "+
+			"as soon as you add them.\n",
+			name, shareURL, invite.ExpiresAt.Format("January 2, 2006"), name),
+	}
+}
+
+// GetFriendInvitesHandler handles GET /friends/invites.
+// Returns the current user's unexpired invites, oldest first.
+func (s *Server) GetFriendInvitesHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetFriendInvitesHandler")
+	defer span.End()
+
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	invites, err := s.db.GetFriendInvites(ctx, dbUser.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetFriendInvites failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invites"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.FriendInvitesResponse{Invites: invites})
+}
+
+// DeleteFriendInviteHandler handles DELETE /friends/invites/:id.
+// Revokes an invite; the email already sent still links to the share. Returns 204, or 404.
+func (s *Server) DeleteFriendInviteHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteFriendInviteHandler")
+	defer span.End()
+
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	err := s.db.DeleteFriendInvite(ctx, dbUser.ID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, database.ErrFriendInviteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("DeleteFriendInvite failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete invite"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// acceptFriendInvite accepts req (from the current user to target) on target's behalf when
+// target has a pending invite to the user's verified email, and deletes the invite. Returns
+// whether the request was accepted.
+func (s *Server) acceptFriendInvite(
+	ctx context.Context,
+	user *models.User,
+	target *models.User,
+	req *models.FriendRequest,
+) (bool, error) {
+	if !user.EmailVerified || user.Email == "" {
+		return false, nil
+	}
+	email, err := models.NormalizeInviteEmail(user.Email)
+	if err != nil {
+		return false, nil
+	}
+	invite, err := s.db.FindFriendInvite(ctx, target.ID, email)
+	if err != nil || invite == nil {
+		return false, err
+	}
+	// A dry run created no request to accept
+	if isDryRun(ctx) {
+		return true, nil
+	}
+	if _, err := s.db.AcceptFriendRequest(ctx, req.ID, target.ID, s.maxFriends); err != nil {
+		return false, err
+	}
+	err = s.db.DeleteFriendInvite(ctx, target.ID, invite.ID)
+	if err != nil && !errors.Is(err, database.ErrFriendInviteNotFound) {
+		logging.FromContext(ctx).Warn("Failed to delete accepted friend invite", logging.Error, err)
+	}
+	return true, nil
+}
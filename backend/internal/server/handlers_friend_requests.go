@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// friendLimitReachedCode is the "code" of the 422 response when a friendship would exceed the
+// friend limit, so clients can tell it from other errors.
+const friendLimitReachedCode = "friend_limit_reached"
+
+// friendRequestBody is the body of POST /friends/requests.
+type friendRequestBody struct {
+	ShareToken string `json:"shareToken"`
+}
+
+// PostFriendRequestHandler handles POST /friends/requests.
+// Body: { "shareToken" } of the user to befriend. Returns 201 with the FriendRequest; 404 for an
+// unknown share token or a user who blocked the sender, 400 for the user's own token, 409 when
+// already friends, the sender blocked the user or a request between the two exists. When the
+// user invited the sender's verified email (POST /friends/invite), the request is accepted at
+// once and 200 returns the user as the sender's new Friend.
+func (s *Server) PostFriendRequestHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostFriendRequestHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body friendRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil || body.ShareToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "shareToken is required"})
+		return
+	}
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error("GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return
+	}
+	if dbUser == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		return
+	}
+	target, err := s.db.GetUserByShareToken(ctx, body.ShareToken)
+	if err != nil {
+		log.Error("GetUserByShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate share token"})
+		return
+	}
+	// Accounts pending deletion take no friend requests
+	if target == nil || target.PendingDeletion() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+	if target.ID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot send a friend request to yourself"})
+		return
+	}
+	// Users who blocked the sender are indistinguishable from unknown share tokens
+	blockedBy, err := s.db.IsBlocked(ctx, target.ID, user.ID)
+	if err != nil {
+		log.Error("IsBlocked failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate share token"})
+		return
+	}
+	if blockedBy {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+	blocking, err := s.db.IsBlocked(ctx, user.ID, target.ID)
+	if err != nil {
+		log.Error("IsBlocked failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate share token"})
+		return
+	}
+	if blocking {
+		c.JSON(http.StatusConflict, gin.H{"error": "user is blocked; unblock first"})
+		return
+	}
+	existing, err := s.db.GetFriendByShareToken(ctx, user.ID, body.ShareToken)
+	if err != nil {
+		log.Error("GetFriendByShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friend"})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "friend already added"})
+		return
+	}
+
+	req, err := s.db.CreateFriendRequest(ctx, &models.FriendRequest{
+		FromUserID:     user.ID,
+		FromShareToken: dbUser.ShareToken,
+		FromName:       dbUser.Name,
+		FromImageURL:   dbUser.ImageURL,
+		ToUserID:       target.ID,
+		ToShareToken:   target.ShareToken,
+		ToName:         target.Name,
+		ToImageURL:     target.ImageURL,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrFriendRequestExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Error("CreateFriendRequest failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create friend request"})
+		return
+	}
+	accepted, err := s.acceptFriendInvite(ctx, user, target, req)
+	if err != nil {
+		// The request stays pending for the target to accept
+		log.Error("Failed to accept friend invite", logging.Error, err)
+	}
+	if accepted {
+		log.Info("Accepted friend invite", logging.UserID, user.ID, "shareToken", body.ShareToken)
+		writeJSON(c, http.StatusOK, models.Friend{
+			ShareToken: target.ShareToken,
+			Name:       target.Name,
+			ImageURL:   target.ImageURL,
+		})
+		return
+	}
+	log.Info("Sent friend request", logging.UserID, user.ID, "shareToken", body.ShareToken)
+	writeJSON(c, http.StatusCreated, req)
+}
+
+// GetFriendRequestsHandler handles GET /friends/requests.
+// Returns the current user's pending incoming and outgoing friend requests, oldest first.
+func (s *Server) GetFriendRequestsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetFriendRequestsHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	incoming, outgoing, err := s.db.GetFriendRequests(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetFriendRequests failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friend requests"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.FriendRequestsResponse{
+		Incoming: incoming,
+		Outgoing: outgoing,
+	})
+}
+
+// PostAcceptFriendRequestHandler handles POST /friends/requests/:id/accept.
+// Only the recipient may accept. Both users become each other's friends; returns 200 with the
+// requester as the new Friend, 404 for a request the user did not receive and 422 with code
+// friend_limit_reached when either user already has the most friends allowed.
+func (s *Server) PostAcceptFriendRequestHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostAcceptFriendRequestHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	friend, err := s.db.AcceptFriendRequest(ctx, c.Param("id"), user.ID, s.maxFriends)
+	if err != nil {
+		if errors.Is(err, database.ErrFriendRequestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "friend request not found"})
+			return
+		}
+		if writeLimitError(c, err, friendLimitReachedCode, "friends") {
+			return
+		}
+		log.Error("AcceptFriendRequest failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to accept friend request"})
+		return
+	}
+	log.Info("Accepted friend request", logging.UserID, user.ID, "shareToken", friend.ShareToken)
+	writeJSON(c, http.StatusOK, friend)
+}
+
+// PostDeclineFriendRequestHandler handles POST /friends/requests/:id/decline.
+// The recipient declines or the sender withdraws the request; no friends are created. Returns
+// 204, or 404 for a request the user neither sent nor received.
+func (s *Server) PostDeclineFriendRequestHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostDeclineFriendRequestHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if err := s.db.DeleteFriendRequest(ctx, c.Param("id"), user.ID); err != nil {
+		if errors.Is(err, database.ErrFriendRequestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "friend request not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("DeleteFriendRequest failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to decline friend request"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// loadFriendUser resolves the :shareToken friend of userID and the friend's User. Writes a 404
+// unless the share token is in the user's friends list and still belongs to a user.
+func (s *Server) loadFriendUser(
+	ctx context.Context,
+	c *gin.Context,
+	userID string,
+) (*models.Friend, *models.User, bool) {
+	log := logging.FromContext(ctx)
+	shareToken := c.Param("shareToken")
+	friend, err := s.db.GetFriendByShareToken(ctx, userID, shareToken)
+	if err != nil {
+		log.Error("GetFriendByShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friend"})
+		return nil, nil, false
+	}
+	if friend == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "friend not found"})
+		return nil, nil, false
+	}
+	friendUser, err := s.db.GetUserByShareToken(ctx, shareToken)
+	if err != nil {
+		log.Error("GetUserByShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friend"})
+		return nil, nil, false
+	}
+	if friendUser == nil || friendUser.PendingDeletion() {
+		// The friend's account is gone or being deleted; the Friend entry stays until the user
+		// removes it.
+		c.JSON(http.StatusNotFound, gin.H{"error": "friend not found"})
+		return nil, nil, false
+	}
+	return friend, friendUser, true
+}
+
+// GetFriendVisitsHandler handles GET /friends/:shareToken/visits.
+// Returns a friend's non-private visits, redacted as on the shared profile, with summary counts.
+// The share token must be in the current user's friends list (404 otherwise).
+func (s *Server) GetFriendVisitsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetFriendVisitsHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	friend, friendUser, ok := s.loadFriendUser(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+
+	settings := friendUser.EffectiveSettings()
+	c.Header("Cache-Control", "private, no-cache")
+	etag := computeETag("friend-visits", friendUser.ID, friendUser.VisitsRevision, *friend,
+		settings, timeFormatFromContext(ctx))
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+	visits, err := s.db.GetPublicCountryVisitsByUser(ctx, friendUser.ID)
+	if err != nil {
+		log.Error("GetPublicCountryVisitsByUser failed for friend", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
+		return
+	}
+	verifiedCount := redactSharedVisits(visits, settings)
+	attachSuccessorCodes(visits)
+
+	writeJSON(c, http.StatusOK, models.FriendVisitsResponse{
+		Friend: *friend,
+		Visits: visits,
+		Summary: models.FriendVisitSummary{
+			VisitCount:         len(visits),
+			CountryCount:       len(visitedCountryCodes(visits)),
+			VerifiedVisitCount: verifiedCount,
+		},
+	})
+}
+
+// GetFriendCompareHandler handles GET /friends/:shareToken/compare.
+// Splits the distinct countries of the current user's visits (private ones included) and the
+// friend's non-private visits into both, only mine and only the friend's.
+func (s *Server) GetFriendCompareHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetFriendCompareHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error("GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return
+	}
+	if dbUser == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		return
+	}
+	friend, friendUser, ok := s.loadFriendUser(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-cache")
+	etag := computeETag("friend-compare", user.ID, dbUser.VisitsRevision, friendUser.ID,
+		friendUser.VisitsRevision, *friend)
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+	mine, err := s.db.GetCountryVisitsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetCountryVisitsByUser failed for compare", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch country visits"})
+		return
+	}
+	theirs, err := s.db.GetPublicCountryVisitsByUser(ctx, friendUser.ID)
+	if err != nil {
+		log.Error("GetPublicCountryVisitsByUser failed for compare", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
+		return
+	}
+
+	myCodes := visitedCountryCodes(mine)
+	theirCodes := visitedCountryCodes(theirs)
+	resp := models.FriendCompareResponse{
+		Friend:     *friend,
+		Both:       []string{},
+		OnlyMine:   []string{},
+		OnlyFriend: []string{},
+	}
+	for code := range myCodes {
+		if _, ok := theirCodes[code]; ok {
+			resp.Both = append(resp.Both, code)
+		} else {
+			resp.OnlyMine = append(resp.OnlyMine, code)
+		}
+	}
+	for code := range theirCodes {
+		if _, ok := myCodes[code]; !ok {
+			resp.OnlyFriend = append(resp.OnlyFriend, code)
+		}
+	}
+	sort.Strings(resp.Both)
+	sort.Strings(resp.OnlyMine)
+	sort.Strings(resp.OnlyFriend)
+	writeJSON(c, http.StatusOK, resp)
+}
+
+// GetMutualFriendsHandler handles GET /friends/:shareToken/mutual.
+// Returns the current user's friends whom the friend has added too, as the current user's
+// Friend entries (with their nicknames). The friend must have the current user as a friend as
+// well; otherwise, or when the share token is not in the user's friends, 404. Friends whose
+// account is gone or being deleted are left out.
+func (s *Server) GetMutualFriendsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetMutualFriendsHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	friend, friendUser, ok := s.loadFriendUser(ctx, c, dbUser.ID)
+	if !ok {
+		return
+	}
+	// A one-sided Friend entry does not grant a look at the other user's friends
+	back, err := s.db.GetFriendByShareToken(ctx, friendUser.ID, dbUser.ShareToken)
+	if err != nil {
+		log.Error("GetFriendByShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friend"})
+		return
+	}
+	if back == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "friend not found"})
+		return
+	}
+
+	mine, err := s.db.GetFriendsByUser(ctx, dbUser.ID)
+	if err != nil {
+		log.Error("GetFriendsByUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		return
+	}
+	theirs, err := s.db.GetFriendsByUser(ctx, friendUser.ID)
+	if err != nil {
+		log.Error("GetFriendsByUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		return
+	}
+	theirTokens := make(map[string]bool, len(theirs))
+	for _, f := range theirs {
+		theirTokens[f.ShareToken] = true
+	}
+	var mutual []models.Friend
+	var tokens []string
+	for _, f := range mine {
+		if theirTokens[f.ShareToken] && f.ShareToken != friend.ShareToken {
+			mutual = append(mutual, f)
+			tokens = append(tokens, f.ShareToken)
+		}
+	}
+	users, err := s.db.GetUsersByShareTokens(ctx, tokens)
+	if err != nil {
+		log.Error("GetUsersByShareTokens failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		return
+	}
+	resp := models.MutualFriendsResponse{Friend: *friend, Mutual: []models.Friend{}}
+	for _, f := range mutual {
+		if u := users[f.ShareToken]; u != nil && !u.PendingDeletion() {
+			resp.Mutual = append(resp.Mutual, f)
+		}
+	}
+	sort.SliceStable(resp.Mutual, func(i, j int) bool {
+		return strings.ToLower(shownFriendName(resp.Mutual[i])) <
+			strings.ToLower(shownFriendName(resp.Mutual[j]))
+	})
+	c.Header("Cache-Control", "private, no-cache")
+	writeJSON(c, http.StatusOK, resp)
+}
+
+// shownFriendName is the name clients show for f: the nickname when set.
+func shownFriendName(f models.Friend) string {
+	if f.Nickname != "" {
+		return f.Nickname
+	}
+	return f.Name
+}
+
+// visitedCountryCodes returns the distinct country codes of visits.
+func visitedCountryCodes(visits []models.CountryVisit) map[string]struct{} {
+	codes := make(map[string]struct{}, len(visits))
+	for _, v := range visits {
+		codes[v.CountryCode] = struct{}{}
+	}
+	return codes
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetHandleProfileHandler handles GET /u/:handle.
+// Unauthenticated alias of GET /share/profile/:shareToken for the owner of the vanity handle,
+// with the scope of the ShareToken. 404 as for an unknown share token.
+func (s *Server) GetHandleProfileHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetHandleProfileHandler")
+	defer span.End()
+
+	handle := models.NormalizeHandle(c.Param("handle"))
+	if models.ValidateHandle(handle) != nil {
+		shareNotFound(c)
+		return
+	}
+	user, err := s.db.GetUserByHandle(ctx, handle)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetUserByHandle failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	}
+	if user == nil || user.SharingDisabled {
+		shareNotFound(c)
+		return
+	}
+	s.writeShareProfile(ctx, c, user, models.ShareScopeFull, user.ShareToken)
+}
+
+// GetHandleHandler handles GET /me/handle.
+// Returns the current user's HandleResponse; 404 without a user document.
+func (s *Server) GetHandleHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetHandleHandler")
+	defer span.End()
+
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	writeJSON(c, http.StatusOK, models.HandleResponse{Handle: dbUser.Handle})
+}
+
+// PutHandleHandler handles PUT /me/handle.
+// Body: { "handle" }, normalized by models.NormalizeHandle. Claims the handle for the current
+// user, releasing their previous one. Returns 200 with the HandleResponse; 400 for an invalid or
+// reserved handle, 403 for API keys, 404 without a user document and 409 when another user has
+// it.
+func (s *Server) PutHandleHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PutHandleHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		Handle string `json:"handle"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	handle := models.NormalizeHandle(body.Handle)
+	if err := models.ValidateHandle(handle); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"handle": err.Error(),
+		}))
+		return
+	}
+	if err := s.db.SetHandle(ctx, user.ID, handle); err != nil {
+		switch {
+		case errors.Is(err, database.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		case errors.Is(err, database.ErrHandleTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("SetHandle failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set handle"})
+		}
+		return
+	}
+	log.Info("Set handle", logging.UserID, user.ID, "handle", handle)
+	writeJSON(c, http.StatusOK, models.HandleResponse{Handle: handle})
+}
+
+// DeleteHandleHandler handles DELETE /me/handle.
+// Releases the current user's handle; GET /u/:handle stops working at once. Returns 204; 403
+// for API keys and 404 when the user has no handle or no user document.
+func (s *Server) DeleteHandleHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteHandleHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if err := s.db.DeleteHandle(ctx, user.ID); err != nil {
+		switch {
+		case errors.Is(err, database.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		case errors.Is(err, database.ErrHandleNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			logging.FromContext(ctx).Error("DeleteHandle failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete handle"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// readinessTimeout bounds each dependency check of GET /readyz.
+const readinessTimeout = 2 * time.Second
+
+// GetHealthzHandler handles GET /healthz (liveness): 200 while the process serves requests. It
+// checks no dependencies, so an outage of one does not get every instance restarted.
+func (s *Server) GetHealthzHandler(ctx context.Context, c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	writeJSON(c, http.StatusOK, models.HealthResponse{Status: models.HealthOK})
+}
+
+// GetReadyzHandler handles GET /readyz (readiness): 200 when the database answers and the ID
+// token signing keys are cached (fetching them if not), otherwise 503. The checks run
+// concurrently, each within readinessTimeout.
+func (s *Server) GetReadyzHandler(ctx context.Context, c *gin.Context) {
+	checks := map[string]func(ctx context.Context) error{
+		"database": s.db.Ping,
+		"jwks":     s.auth.PrimeJWKS,
+	}
+	resp := models.HealthResponse{Status: models.HealthOK, Checks: map[string]string{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+			defer cancel()
+			err := check(checkCtx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logging.FromContext(ctx).Warn("Readiness check failed", "check", name,
+					logging.Error, err)
+				resp.Status = models.HealthUnavailable
+				resp.Checks[name] = models.HealthFailed
+				return
+			}
+			resp.Checks[name] = models.HealthOK
+		}()
+	}
+	wg.Wait()
+
+	c.Header("Cache-Control", "no-store")
+	code := http.StatusOK
+	if resp.Status != models.HealthOK {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(c, code, resp)
+}
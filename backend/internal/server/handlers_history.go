@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetVisitHistoryHandler handles GET /visits/:id/history.
+// Returns the created/updated/deleted events of one of the current user's visits, oldest first.
+// Deleted visits keep their history. 404 when the visit has neither history nor a document.
+func (s *Server) GetVisitHistoryHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetVisitHistoryHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	visitID := c.Param("id")
+	if visitID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visit id required"})
+		return
+	}
+
+	events, err := s.db.GetCountryVisitHistory(ctx, visitID, user.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrVisitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "visit not found"})
+			return
+		}
+		log.Error("GetCountryVisitHistory failed", logging.Error, err, logging.VisitID, visitID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get visit history"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.VisitHistoryResponse{Events: events})
+}
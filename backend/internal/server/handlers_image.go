@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/imageproxy"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// defaultImageWidth is used when GET /img has no w parameter.
+const defaultImageWidth = 128
+
+// GetImageHandler handles GET /img?url=<https URL>&w=<width>.
+// Unauthenticated; fetches an image from an allowlisted host, resizes it and serves it with
+// long-lived caching. Used for avatars (Google profile images) and thumbnails.
+func (s *Server) GetImageHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetImageHandler")
+	defer span.End()
+
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+	width := defaultImageWidth
+	if w := c.Query("w"); w != "" {
+		n, err := strconv.Atoi(w)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "w must be a positive integer"})
+			return
+		}
+		width = n
+	}
+	if s.imageProxy == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "image proxy not configured"})
+		return
+	}
+
+	log := logging.FromContext(ctx)
+	img, err := s.imageProxy.Fetch(ctx, rawURL, width)
+	if err != nil {
+		if errors.Is(err, imageproxy.ErrInvalidURL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "url is not allowed"})
+			return
+		}
+		log.Warn("Image proxy fetch failed", logging.Error, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch image"})
+		return
+	}
+
+	etag := computeETag("img", string(img.Data))
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'")
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
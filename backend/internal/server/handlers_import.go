@@ -0,0 +1,217 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/importer"
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// maxImportBodyBytes caps the size of an uploaded export file.
+const maxImportBodyBytes = 5 << 20
+
+// maxImportRecords caps the number of records accepted from one export file.
+const maxImportRecords = 1000
+
+// maxSyncImportVisits is the largest import written within the request; larger ones run as a
+// background write job.
+const maxSyncImportVisits = 50
+
+// PostImportVisitsHandler handles POST /visits/import?source=nomadlist|been|polarsteps.
+// The request body is the raw export file from the source app. Each parsed record is validated
+// like POST /visits; invalid records are reported in `skipped` rather than failing the import.
+// Records without a date use the optional `visitedTime` query parameter (Unix seconds or RFC 3339).
+// Writes are throttled by the write queue; an import of more than maxSyncImportVisits visits
+// returns 202 with a job to poll at GET /visits/import/jobs/:id.
+func (s *Server) PostImportVisitsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostImportVisitsHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+
+	source := c.Query("source")
+	imp, ok := importer.Get(source)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "source must be one of: " + strings.Join(importer.Sources(), ", "),
+		})
+		return
+	}
+
+	var fallbackTime time.Time
+	if raw := c.Query("visitedTime"); raw != "" {
+		t, err := jsontime.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "visitedTime must be Unix seconds or RFC 3339"})
+			return
+		}
+		fallbackTime = t
+		if err := models.ValidateVisitedTime(fallbackTime); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+	records, err := imp.Parse(body)
+	if err != nil {
+		log.Warn("Invalid import file", "source", source, logging.Error, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(records) > maxImportRecords {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("at most %d records can be imported at once", maxImportRecords),
+		})
+		return
+	}
+
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error("GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return
+	}
+	includeTerritories := dbUser.EffectiveSettings().IncludeTerritories
+
+	resp := models.ImportVisitsResponse{
+		Visits:  []models.CountryVisit{},
+		Skipped: []models.ImportSkipped{},
+	}
+	var toCreate []models.CountryVisit
+	for i, rec := range records {
+		if rec.CountryCode == "" {
+			resp.Skipped = append(resp.Skipped,
+				models.ImportSkipped{Index: i, Reason: "unknown country name", Country: rec.Name})
+			continue
+		}
+		historic, isHistoric := data.HistoricCountryByCode(rec.CountryCode)
+		countryCode := historic.CountryCode
+		if !isHistoric {
+			country, ok := data.CountryByCode(rec.CountryCode)
+			if !ok || !data.IsVisitableCountry(country.CountryCode, includeTerritories) {
+				resp.Skipped = append(resp.Skipped, models.ImportSkipped{
+					Index: i, Reason: "invalid countryCode", Country: rec.CountryCode,
+				})
+				continue
+			}
+			countryCode = country.CountryCode
+		}
+		t := rec.VisitedTime
+		if t.IsZero() {
+			t = fallbackTime
+		}
+		if t.IsZero() {
+			resp.Skipped = append(resp.Skipped, models.ImportSkipped{Index: i, Reason: "visitedTime is required"})
+			continue
+		}
+		if err := models.ValidateVisitedTime(t); err != nil {
+			resp.Skipped = append(resp.Skipped, models.ImportSkipped{Index: i, Reason: err.Error()})
+			continue
+		}
+		if isHistoric {
+			if err := historic.ValidateVisitedTime(t); err != nil {
+				resp.Skipped = append(resp.Skipped,
+					models.ImportSkipped{Index: i, Reason: err.Error()})
+				continue
+			}
+		}
+		toCreate = append(toCreate, models.CountryVisit{
+			CountryCode: countryCode,
+			VisitedTime: t,
+			Tags:        []string{},
+			UserID:      user.ID,
+		})
+	}
+
+	// Checked up front so an import over the limit writes nothing; each write checks it again
+	if dbUser != nil {
+		limit := dbUser.Limits.Or(s.defaultLimits()).MaxVisits
+		if limit > 0 && dbUser.VisitCount+int64(len(toCreate)) > int64(limit) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": fmt.Sprintf("the import would exceed the limit of %d visits per user",
+					limit),
+				"code": visitLimitReachedCode,
+			})
+			return
+		}
+	}
+
+	if s.writes != nil && len(toCreate) > maxSyncImportVisits {
+		job, err := s.writes.Submit(ctx, user.ID, "import", len(toCreate),
+			func(ctx context.Context, i int) error {
+				_, err := s.db.CreateCountryVisit(ctx, &toCreate[i], s.maxVisits)
+				return err
+			})
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "an import is already running"})
+			return
+		}
+		log.Info("Started import job", logging.UserID, user.ID, "source", source,
+			logging.WriteJob, job.ID, logging.Count, job.Total)
+		c.Header("Location", "/visits/import/jobs/"+job.ID)
+		writeJSON(c, http.StatusAccepted, models.ImportJobResponse{Job: job, Skipped: resp.Skipped})
+		return
+	}
+
+	for i := range toCreate {
+		if s.writes != nil {
+			if err := s.writes.Wait(ctx); err != nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "import cancelled"})
+				return
+			}
+		}
+		created, err := s.db.CreateCountryVisit(ctx, &toCreate[i], s.maxVisits)
+		if err != nil {
+			if writeLimitError(c, err, visitLimitReachedCode, "visits") {
+				return
+			}
+			log.Error("CreateCountryVisit failed during import", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import visits"})
+			return
+		}
+		resp.Visits = append(resp.Visits, *created)
+	}
+	attachSuccessorCodes(resp.Visits)
+
+	log.Info("Imported country visits", logging.UserID, user.ID, "source", source,
+		logging.Count, len(resp.Visits))
+	status := http.StatusOK
+	if len(resp.Visits) > 0 {
+		status = http.StatusCreated
+	}
+	writeJSON(c, status, resp)
+}
+
+// GetImportJobHandler handles GET /visits/import/jobs/:id.
+// Returns the WriteJob of a background import started by the current user on this instance;
+// 404 when unknown, another user's or finished more than an hour ago.
+func (s *Server) GetImportJobHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetImportJobHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if s.writes == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "import job not found"})
+		return
+	}
+	job, ok := s.writes.Job(user.ID, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "import job not found"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	writeJSON(c, http.StatusOK, job)
+}
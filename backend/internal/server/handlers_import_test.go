@@ -0,0 +1,38 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+func TestImportReportsUnknownCountries(t *testing.T) {
+	s, _ := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+
+	body := "Finland\nAtlantis\nCzech Republic\nXX\n"
+	w := doAs(t, s, "u1", http.MethodPost, "/visits/import?source=been&visitedTime=1577836800",
+		body)
+	requireStatus(t, w, http.StatusCreated)
+	var resp models.ImportVisitsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Visits) != 2 {
+		t.Errorf("imported %d visits, want 2", len(resp.Visits))
+	}
+	want := []models.ImportSkipped{
+		{Index: 1, Reason: "unknown country name", Country: "Atlantis"},
+		{Index: 3, Reason: "unknown country name", Country: "XX"},
+	}
+	if len(resp.Skipped) != len(want) {
+		t.Fatalf("skipped = %+v, want %+v", resp.Skipped, want)
+	}
+	for i := range want {
+		if resp.Skipped[i] != want[i] {
+			t.Errorf("skipped[%d] = %+v, want %+v", i, resp.Skipped[i], want[i])
+		}
+	}
+}
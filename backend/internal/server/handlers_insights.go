@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/insights"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetInsightsHandler handles GET /insights.
+// Returns the current user's Insights as generated by the weekly insights job. Users the job
+// has not reached yet get them computed and saved on first request.
+func (s *Server) GetInsightsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetInsightsHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	ins, err := s.db.GetInsights(ctx, user.ID)
+	if err != nil {
+		log.Error("GetInsights failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch insights"})
+		return
+	}
+	if ins == nil {
+		visits, err := s.db.GetCountryVisitsByUser(ctx, user.ID)
+		if err != nil {
+			log.Error("GetCountryVisitsByUser failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch country visits"})
+			return
+		}
+		computed := insights.Compute(visits, time.Now().UTC())
+		ins = &computed
+		if err := s.db.SaveInsights(ctx, user.ID, ins); err != nil {
+			// Computed again on the next request
+			log.Warn("SaveInsights failed", logging.Error, err)
+		}
+	}
+	c.Header("Cache-Control", "private, no-cache")
+	writeJSON(c, http.StatusOK, ins)
+}
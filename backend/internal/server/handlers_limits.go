@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// visitLimitReachedCode is the "code" of the 422 response when a visit would exceed the user's
+// visit limit.
+const visitLimitReachedCode = "visit_limit_reached"
+
+// WithMaxVisits limits how many visits a user may have; creating one beyond it responds 422.
+// Without it the number is unlimited.
+func WithMaxVisits(n int) Option {
+	return func(s *Server) {
+		s.maxVisits = n
+	}
+}
+
+// defaultLimits returns the configured per-user limits, before admin overrides.
+func (s *Server) defaultLimits() models.UserLimits {
+	return models.UserLimits{MaxVisits: s.maxVisits, MaxFriends: s.maxFriends}
+}
+
+// writeLimitError responds 422 with code when err is a database.LimitError, naming the limit
+// in effect for the user, and reports whether it did.
+func writeLimitError(c *gin.Context, err error, code, what string) bool {
+	var limitErr *database.LimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error": fmt.Sprintf("a user may have at most %d %s", limitErr.Limit, what),
+		"code":  code,
+	})
+	return true
+}
+
+// GetUserLimitsHandler handles GET /admin/users/:userId/limits.
+// Returns the limits in effect for the user, their override and visit count.
+func (s *Server) GetUserLimitsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetUserLimitsHandler")
+	defer span.End()
+
+	u, ok := s.adminTargetUser(ctx, c)
+	if !ok {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	writeJSON(c, http.StatusOK, s.userLimitsResponse(u))
+}
+
+// PutUserLimitsHandler handles PUT /admin/users/:userId/limits.
+// Replaces the user's override of the configured limits; a 0 field keeps the configured limit,
+// and all zeros remove the override. Lowering a limit below the user's count blocks new
+// documents only.
+func (s *Server) PutUserLimitsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PutUserLimitsHandler")
+	defer span.End()
+
+	var body models.UserLimits
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	fields := map[string]string{}
+	if body.MaxVisits < 0 {
+		fields["maxVisits"] = "must not be negative"
+	}
+	if body.MaxFriends < 0 {
+		fields["maxFriends"] = "must not be negative"
+	}
+	if len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(fields))
+		return
+	}
+	u, ok := s.adminTargetUser(ctx, c)
+	if !ok {
+		return
+	}
+
+	var override *models.UserLimits
+	if body.MaxVisits > 0 || body.MaxFriends > 0 {
+		override = &body
+	}
+	log := logging.FromContext(ctx)
+	if err := s.db.SetUserLimits(ctx, u.ID, override); err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		log.Error("SetUserLimits failed", logging.UserID, u.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save user limits"})
+		return
+	}
+	u.Limits = override
+	log.Info("Saved user limits", logging.UserID, u.ID, "maxVisits", body.MaxVisits,
+		"maxFriends", body.MaxFriends)
+	writeJSON(c, http.StatusOK, s.userLimitsResponse(u))
+}
+
+// adminTargetUser returns the user of the userId path parameter, or responds 404 (or 500) and
+// returns false.
+func (s *Server) adminTargetUser(ctx context.Context, c *gin.Context) (*models.User, bool) {
+	userID := c.Param("userId")
+	u, err := s.db.GetUserByID(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetUserByID failed", logging.UserID, userID,
+			logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return nil, false
+	}
+	if u == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return nil, false
+	}
+	return u, true
+}
+
+func (s *Server) userLimitsResponse(u *models.User) models.UserLimitsResponse {
+	return models.UserLimitsResponse{
+		Limits:     u.Limits.Or(s.defaultLimits()),
+		Override:   u.Limits,
+		VisitCount: u.VisitCount,
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// mergeMaxSignInAge is how recent the sign-in of the account to merge must be: merging deletes
+// that account, so an old token that leaked must not be enough.
+const mergeMaxSignInAge = 10 * time.Minute
+
+// PostMergeHandler handles POST /me/merge.
+// Body: { "idToken" }, an ID token of another account of the same person (e.g. signed in with
+// another provider) from a sign-in within mergeMaxSignInAge. Merges that account into the
+// current one with database.MergeUsers; the current account and its ShareToken are kept.
+// Returns 200 with the AccountMergeResult; 400 for a missing, invalid, stale or revoked token
+// or the current account's own token (revocation is checked in strict auth mode only), 403
+// for API keys and guest accounts, 404 without a user document or when the other account has
+// none.
+func (s *Server) PostMergeHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostMergeHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	if dbUser.IsAnonymous {
+		c.JSON(http.StatusForbidden, gin.H{"error": "sign in with a permanent account first"})
+		return
+	}
+	var body struct {
+		IDToken string `json:"idToken"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if body.IDToken == "" {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"idToken": "is required",
+		}))
+		return
+	}
+	now := time.Now().UTC()
+	claims, err := s.auth.VerifyIDToken(ctx, body.IDToken)
+	if err != nil {
+		log.Warn("Merge token verification failed", logging.Error, err)
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"idToken": "must be a valid ID token",
+		}))
+		return
+	}
+	if claims.Sub == dbUser.ID {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"idToken": "must be of another account",
+		}))
+		return
+	}
+	if claims.AuthTime.IsZero() || now.Sub(claims.AuthTime) > mergeMaxSignInAge {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"idToken": "sign in to the other account again",
+		}))
+		return
+	}
+	revoked, err := s.tokenRevoked(ctx, claims)
+	if err != nil {
+		log.Error("Token revocation check failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+		return
+	}
+	if revoked {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"idToken": "token revoked",
+		}))
+		return
+	}
+
+	res, err := s.db.MergeUsers(ctx, claims.Sub, dbUser.ID, now)
+	if err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "account to merge not found"})
+			return
+		}
+		log.Error("MergeUsers failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to merge accounts"})
+		return
+	}
+	log.Info("Merged accounts", logging.UserID, dbUser.ID, "mergedUserId", claims.Sub,
+		"visits", res.Visits, "friends", res.Friends)
+	s.recordAudit(ctx, c, dbUser.ID, models.AuditAccountsMerged, claims.Sub)
+	writeJSON(c, http.StatusOK, res)
+}
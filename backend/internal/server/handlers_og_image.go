@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/imageproxy"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/ogimage"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetShareOGImageHandler handles GET /share/og/:shareToken.png.
+// Unauthenticated; returns the Open Graph preview PNG of the share (world map with the visited
+// countries, their count and the owner's name). Summary share links show no countries on the
+// map. Rendered images are cached by the owner's VisitsRevision, name and the share scope.
+// Fetching the image does not count as a share link view.
+func (s *Server) GetShareOGImageHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetShareOGImageHandler")
+	defer span.End()
+
+	shareToken, ok := strings.CutSuffix(c.Param("file"), ".png")
+	if !ok || shareToken == "" {
+		shareNotFound(c)
+		return
+	}
+	log := logging.FromContext(ctx)
+	user, scope, err := s.resolveShareUser(ctx, shareToken, false)
+	if err != nil {
+		log.Error("resolveShareUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	}
+	if user == nil || user.PendingDeletion() {
+		shareNotFound(c)
+		return
+	}
+	if blocked, err := s.isBlockedViewer(ctx, user.ID); err != nil {
+		log.Error("IsBlocked failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	} else if blocked {
+		shareNotFound(c)
+		return
+	}
+	etag := computeETag("og", user.ID, user.VisitsRevision, user.Name, scope)
+	c.Header("Cache-Control", "no-cache")
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+
+	key := strings.Trim(etag, `"`) + ".png"
+	if s.ogImages != nil {
+		img, ok, err := s.ogImages.Get(ctx, key)
+		if err != nil {
+			log.Warn("Failed to read cached preview image", logging.Error, err)
+		} else if ok {
+			c.Data(http.StatusOK, img.ContentType, img.Data)
+			return
+		}
+	}
+	visits, err := s.db.GetPublicCountryVisitsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetPublicCountryVisitsByUser failed for preview", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
+		return
+	}
+	resp := models.ShareProfileResponse{Visits: visits}
+	applyShareScope(&resp, scope)
+	data, err := ogimage.Render(user.Name, resp.CountriesCount, resp.CountryCodes)
+	if err != nil {
+		log.Error("Failed to render preview image", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render image"})
+		return
+	}
+	img := &imageproxy.Image{Data: data, ContentType: "image/png"}
+	if s.ogImages != nil {
+		if err := s.ogImages.Put(ctx, key, img); err != nil {
+			log.Warn("Failed to cache preview image", logging.Error, err)
+		}
+	}
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
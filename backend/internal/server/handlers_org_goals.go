@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetOrgGoalsHandler handles GET /orgs/:id/goals.
+// Returns the organization's goals with progress computed from the members' non-private visits.
+// Members only.
+func (s *Server) GetOrgGoalsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetOrgGoalsHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	if _, ok := s.loadOrgMember(ctx, c, user.ID); !ok {
+		return
+	}
+	orgID := c.Param("id")
+	goals, err := s.db.GetOrganizationGoals(ctx, orgID)
+	if err != nil {
+		log.Error("GetOrganizationGoals failed", logging.Error, err, logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch goals"})
+		return
+	}
+	if len(goals) > 0 {
+		ov, err := s.loadOrgVisits(ctx, orgID)
+		if err != nil {
+			log.Error("loadOrgVisits failed for goals", logging.Error, err,
+				logging.OrganizationID, orgID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
+			return
+		}
+		for i := range goals {
+			setGoalProgress(&goals[i], ov)
+		}
+	}
+	c.Header("Cache-Control", "private, max-age=60")
+	writeJSON(c, http.StatusOK, models.OrganizationGoalsResponse{Goals: goals})
+}
+
+// PostOrgGoalHandler handles POST /orgs/:id/goals.
+// Body: { "title", "countryCodes", "targetCount", "year" }. Owners and admins only. Returns 201
+// with the goal and its current progress.
+func (s *Server) PostOrgGoalHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostOrgGoalHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		Title        string   `json:"title"`
+		CountryCodes []string `json:"countryCodes"`
+		TargetCount  int      `json:"targetCount"`
+		Year         int      `json:"year"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	codes, err := normalizeGoalCountryCodes(body.CountryCodes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	goal := models.OrganizationGoal{
+		Title:        strings.TrimSpace(body.Title),
+		CountryCodes: codes,
+		TargetCount:  body.TargetCount,
+		Year:         body.Year,
+		CreatedBy:    user.ID,
+	}
+	if goal.TargetCount == 0 {
+		goal.TargetCount = len(codes)
+	}
+	if err := goal.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := s.loadOrgManager(ctx, c, user.ID); !ok {
+		return
+	}
+
+	orgID := c.Param("id")
+	created, err := s.db.CreateOrganizationGoal(ctx, orgID, &goal)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrOrganizationNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		case errors.Is(err, database.ErrTooManyOrgGoals):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("CreateOrganizationGoal failed", logging.Error, err,
+				logging.OrganizationID, orgID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create goal"})
+		}
+		return
+	}
+	if ov, err := s.loadOrgVisits(ctx, orgID); err == nil {
+		setGoalProgress(created, ov)
+	} else {
+		log.Warn("loadOrgVisits failed; returning goal without progress", logging.Error, err,
+			logging.OrganizationID, orgID)
+	}
+	writeJSON(c, http.StatusCreated, created)
+}
+
+// DeleteOrgGoalHandler handles DELETE /orgs/:id/goals/:goalId.
+// Owners and admins only. Returns 204.
+func (s *Server) DeleteOrgGoalHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteOrgGoalHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if _, ok := s.loadOrgManager(ctx, c, user.ID); !ok {
+		return
+	}
+	orgID := c.Param("id")
+	if err := s.db.DeleteOrganizationGoal(ctx, orgID, c.Param("goalId")); err != nil {
+		if errors.Is(err, database.ErrOrgGoalNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "goal not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("DeleteOrganizationGoal failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete goal"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// normalizeGoalCountryCodes resolves alpha-2 or alpha-3 codes to alpha-2, dropping duplicates.
+func normalizeGoalCountryCodes(codes []string) ([]string, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(codes))
+	seen := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		country, ok := data.CountryByCode(code)
+		if !ok {
+			return nil, fmt.Errorf("invalid country code: %q", code)
+		}
+		if _, dup := seen[country.CountryCode]; !dup {
+			seen[country.CountryCode] = struct{}{}
+			out = append(out, country.CountryCode)
+		}
+	}
+	return out, nil
+}
+
+// setGoalProgress counts the distinct countries the members have visited towards goal.
+func setGoalProgress(goal *models.OrganizationGoal, ov *orgVisits) {
+	var allowed map[string]struct{}
+	if len(goal.CountryCodes) > 0 {
+		allowed = make(map[string]struct{}, len(goal.CountryCodes))
+		for _, code := range goal.CountryCodes {
+			allowed[code] = struct{}{}
+		}
+	}
+	visited := map[string]struct{}{}
+	for _, visits := range ov.visits {
+		for _, v := range visits {
+			if goal.Year != 0 && v.VisitedTime.UTC().Year() != goal.Year {
+				continue
+			}
+			if allowed != nil {
+				if _, ok := allowed[v.CountryCode]; !ok {
+					continue
+				}
+			}
+			visited[v.CountryCode] = struct{}{}
+		}
+	}
+	goal.Progress = len(visited)
+	goal.Completed = goal.Progress >= goal.TargetCount
+}
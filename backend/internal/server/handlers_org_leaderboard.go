@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetOrgLeaderboardHandler handles GET /orgs/:id/leaderboard?year=.
+// Ranks the organization's members by distinct countries visited in ?year (default: the current
+// year, UTC), counting non-private visits only. Members only.
+func (s *Server) GetOrgLeaderboardHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetOrgLeaderboardHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	year := time.Now().UTC().Year()
+	if raw := c.Query("year"); raw != "" {
+		y, err := strconv.Atoi(raw)
+		if err != nil || y < models.MinVisitedTime.Year() || y > 9999 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+		year = y
+	}
+	if _, ok := s.loadOrgMember(ctx, c, user.ID); !ok {
+		return
+	}
+	orgID := c.Param("id")
+	ov, err := s.loadOrgVisits(ctx, orgID)
+	if err != nil {
+		logging.FromContext(ctx).Error("loadOrgVisits failed for leaderboard", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
+		return
+	}
+
+	entries := make([]models.OrgLeaderboardEntry, 0, len(ov.members))
+	for _, m := range ov.members {
+		entry := models.OrgLeaderboardEntry{UserID: m.UserID, Name: m.Name, ImageURL: m.ImageURL}
+		countries := map[string]struct{}{}
+		for _, v := range ov.visits[m.UserID] {
+			if v.VisitedTime.UTC().Year() != year {
+				continue
+			}
+			entry.VisitCount++
+			countries[v.CountryCode] = struct{}{}
+		}
+		entry.CountryCount = len(countries)
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].CountryCount != entries[j].CountryCount {
+			return entries[i].CountryCount > entries[j].CountryCount
+		}
+		if entries[i].VisitCount != entries[j].VisitCount {
+			return entries[i].VisitCount > entries[j].VisitCount
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	for i := range entries {
+		if i > 0 && entries[i].CountryCount == entries[i-1].CountryCount {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	c.Header("Cache-Control", "private, max-age=60")
+	writeJSON(c, http.StatusOK, models.OrgLeaderboardResponse{Year: year, Entries: entries})
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetOrgShareHandler handles GET /share/org/:shareToken.
+// Unauthenticated; returns the organization's combined map as allowed by the token's scope: the
+// organization's own ShareToken and aggregate share links show only countries with member
+// counts, members share links also list members. Built from members' non-private visits only
+// (cached for orgVisitsTTL).
+func (s *Server) GetOrgShareHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetOrgShareHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	token := c.Param("shareToken")
+	org, err := s.db.GetOrganizationByShareToken(ctx, token)
+	if err != nil {
+		log.Error("GetOrganizationByShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	}
+	scope := models.OrgShareScopeAggregate
+	if org == nil {
+		link, err := s.db.GetOrgShareLink(ctx, token)
+		if err != nil {
+			log.Error("GetOrgShareLink failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+			return
+		}
+		if link == nil {
+			shareNotFound(c)
+			return
+		}
+		org, err = s.db.GetOrganization(ctx, link.OrganizationID)
+		if errors.Is(err, database.ErrOrganizationNotFound) {
+			shareNotFound(c)
+			return
+		}
+		if err != nil {
+			log.Error("GetOrganization failed", logging.Error, err,
+				logging.OrganizationID, link.OrganizationID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+			return
+		}
+		scope = link.Scope
+	}
+	ov, err := s.loadOrgVisits(ctx, org.ID)
+	if err != nil {
+		log.Error("loadOrgVisits failed for org share", logging.Error, err,
+			logging.OrganizationID, org.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	writeJSON(c, http.StatusOK, projectOrgShare(org, ov, scope))
+}
+
+// projectOrgShare builds the org share page for scope. It is the only place deciding what a
+// share link exposes: member data is included for OrgShareScopeMembers only, and user IDs never.
+func projectOrgShare(
+	org *models.Organization,
+	ov *orgVisits,
+	scope string,
+) models.OrgShareResponse {
+	resp := models.OrgShareResponse{
+		Name:        org.Name,
+		Description: org.Description,
+		MemberCount: len(ov.members),
+		Scope:       scope,
+	}
+	memberCounts := map[string]int{}
+	for _, m := range ov.members {
+		codes := visitedCountryCodes(ov.visits[m.UserID])
+		for code := range codes {
+			memberCounts[code]++
+		}
+		if scope == models.OrgShareScopeMembers {
+			member := models.OrgShareMember{
+				Name:         m.Name,
+				ImageURL:     m.ImageURL,
+				CountryCodes: make([]string, 0, len(codes)),
+			}
+			for code := range codes {
+				member.CountryCodes = append(member.CountryCodes, code)
+			}
+			sort.Strings(member.CountryCodes)
+			resp.Members = append(resp.Members, member)
+		}
+	}
+	resp.Countries = make([]models.OrgCountry, 0, len(memberCounts))
+	for code, n := range memberCounts {
+		resp.Countries = append(resp.Countries,
+			models.OrgCountry{CountryCode: code, MemberCount: n})
+	}
+	sort.Slice(resp.Countries, func(i, j int) bool {
+		if resp.Countries[i].MemberCount != resp.Countries[j].MemberCount {
+			return resp.Countries[i].MemberCount > resp.Countries[j].MemberCount
+		}
+		return resp.Countries[i].CountryCode < resp.Countries[j].CountryCode
+	})
+	return resp
+}
+
+// GetOrgShareLinksHandler handles GET /orgs/:id/share-links.
+// Lists the organization's share links, oldest first. Owners and admins only.
+func (s *Server) GetOrgShareLinksHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetOrgShareLinksHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if _, ok := s.loadOrgManager(ctx, c, user.ID); !ok {
+		return
+	}
+	orgID := c.Param("id")
+	links, err := s.db.GetOrgShareLinks(ctx, orgID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetOrgShareLinks failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share links"})
+		return
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].CreatedAt.Before(links[j].CreatedAt) })
+	writeJSON(c, http.StatusOK, models.OrgShareLinksResponse{ShareLinks: links})
+}
+
+// PostOrgShareLinkHandler handles POST /orgs/:id/share-links.
+// Mints a share link with body { "scope" } (default aggregate). Owners and admins only. Returns
+// 201 with the link.
+func (s *Server) PostOrgShareLinkHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostOrgShareLinkHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		Scope string `json:"scope"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if body.Scope == "" {
+		body.Scope = models.OrgShareScopeAggregate
+	}
+	if err := models.ValidateOrgShareScope(body.Scope); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := s.loadOrgManager(ctx, c, user.ID); !ok {
+		return
+	}
+
+	orgID := c.Param("id")
+	link, err := s.db.CreateOrgShareLink(ctx, &models.OrgShareLink{
+		OrganizationID: orgID,
+		Scope:          body.Scope,
+		CreatedBy:      user.ID,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrTooManyOrgShareLinks) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Error("CreateOrgShareLink failed", logging.Error, err, logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create share link"})
+		return
+	}
+	log.Info("Created organization share link", logging.OrganizationID, orgID,
+		logging.UserID, user.ID, "scope", link.Scope)
+	writeJSON(c, http.StatusCreated, link)
+}
+
+// DeleteOrgShareLinkHandler handles DELETE /orgs/:id/share-links/:token.
+// Revokes a share link. Owners and admins only. Returns 204.
+func (s *Server) DeleteOrgShareLinkHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteOrgShareLinkHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if _, ok := s.loadOrgManager(ctx, c, user.ID); !ok {
+		return
+	}
+	orgID := c.Param("id")
+	if err := s.db.DeleteOrgShareLink(ctx, orgID, c.Param("token")); err != nil {
+		if errors.Is(err, database.ErrOrgShareLinkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "share link not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("DeleteOrgShareLink failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete share link"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
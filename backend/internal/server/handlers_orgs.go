@@ -0,0 +1,515 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// orgProfileBody is the request body of POST /orgs and PUT /orgs/:id.
+type orgProfileBody struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// bindOrgProfile parses and validates an orgProfileBody, writing a 400 response on failure.
+func bindOrgProfile(c *gin.Context) (orgProfileBody, bool) {
+	var body orgProfileBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return body, false
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	body.Description = strings.TrimSpace(body.Description)
+	if err := models.ValidateOrgProfile(body.Name, body.Description); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return body, false
+	}
+	return body, true
+}
+
+// loadOrgMember loads the current user's membership of the :id organization. Non-members get
+// 404, so organization IDs do not reveal whether an organization exists.
+func (s *Server) loadOrgMember(
+	ctx context.Context,
+	c *gin.Context,
+	userID string,
+) (*models.OrganizationMember, bool) {
+	orgID := c.Param("id")
+	member, err := s.db.GetOrganizationMember(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrOrgMemberNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return nil, false
+		}
+		logging.FromContext(ctx).Error("GetOrganizationMember failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load organization"})
+		return nil, false
+	}
+	return member, true
+}
+
+// loadOrgManager is loadOrgMember that also requires an owner or admin (403 otherwise).
+func (s *Server) loadOrgManager(
+	ctx context.Context,
+	c *gin.Context,
+	userID string,
+) (*models.OrganizationMember, bool) {
+	member, ok := s.loadOrgMember(ctx, c, userID)
+	if !ok {
+		return nil, false
+	}
+	if !member.CanManage() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "organization owner or admin required"})
+		return nil, false
+	}
+	return member, true
+}
+
+// GetOrgsHandler handles GET /orgs.
+// Returns the organizations the current user is a member of, with the user's role in each.
+func (s *Server) GetOrgsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetOrgsHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	memberships, err := s.db.GetOrganizationsByUser(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetOrganizationsByUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch organizations"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.OrganizationsResponse{Organizations: memberships})
+}
+
+// PostOrgHandler handles POST /orgs.
+// Creates an organization with the current user as its owner. Returns 201 with the organization.
+func (s *Server) PostOrgHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostOrgHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	body, ok := bindOrgProfile(c)
+	if !ok {
+		return
+	}
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error("GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return
+	}
+	if dbUser == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		return
+	}
+
+	now := time.Now().UTC()
+	org, err := s.db.CreateOrganization(ctx, &models.Organization{
+		Name:        body.Name,
+		Description: body.Description,
+		CreatedAt:   now,
+	}, models.OrganizationMember{
+		UserID:   user.ID,
+		Name:     dbUser.Name,
+		ImageURL: dbUser.ImageURL,
+		JoinedAt: now,
+	})
+	if err != nil {
+		log.Error("CreateOrganization failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create organization"})
+		return
+	}
+	log.Info("Created organization", logging.OrganizationID, org.ID, logging.UserID, user.ID)
+	writeJSON(c, http.StatusCreated, models.OrganizationMembership{
+		Organization: *org,
+		Role:         models.OrgRoleOwner,
+	})
+}
+
+// GetOrgHandler handles GET /orgs/:id.
+// Returns the organization, the current user's role and its members. Members only.
+func (s *Server) GetOrgHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetOrgHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	member, ok := s.loadOrgMember(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+	orgID := c.Param("id")
+	org, err := s.db.GetOrganization(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, database.ErrOrganizationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return
+		}
+		log.Error("GetOrganization failed", logging.Error, err, logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load organization"})
+		return
+	}
+	members, err := s.db.GetOrganizationMembers(ctx, orgID)
+	if err != nil {
+		log.Error("GetOrganizationMembers failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch members"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.OrganizationResponse{
+		OrganizationMembership: models.OrganizationMembership{
+			Organization: *org,
+			Role:         member.Role,
+		},
+		Members: members,
+	})
+}
+
+// PutOrgHandler handles PUT /orgs/:id.
+// Replaces the organization's name and description. Owners and admins only. Returns 204.
+func (s *Server) PutOrgHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PutOrgHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	body, ok := bindOrgProfile(c)
+	if !ok {
+		return
+	}
+	if _, ok := s.loadOrgManager(ctx, c, user.ID); !ok {
+		return
+	}
+	orgID := c.Param("id")
+	err := s.db.UpdateOrganizationProfile(ctx, orgID, body.Name, body.Description)
+	if err != nil {
+		if errors.Is(err, database.ErrOrganizationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("UpdateOrganizationProfile failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update organization"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteOrgHandler handles DELETE /orgs/:id.
+// Deletes the organization with its memberships and invitations. Owner only, and not with an
+// API key (403). Returns 204.
+func (s *Server) DeleteOrgHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteOrgHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	member, ok := s.loadOrgMember(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+	if member.Role != models.OrgRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "organization owner required"})
+		return
+	}
+	orgID := c.Param("id")
+	if err := s.db.DeleteOrganization(ctx, orgID); err != nil {
+		if errors.Is(err, database.ErrOrganizationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return
+		}
+		log.Error("DeleteOrganization failed", logging.Error, err, logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete organization"})
+		return
+	}
+	s.orgVisits.invalidate(orgID)
+	log.Info("Deleted organization", logging.OrganizationID, orgID, logging.UserID, user.ID)
+	c.Status(http.StatusNoContent)
+}
+
+// GetOrgInvitationsHandler handles GET /orgs/:id/invitations.
+// Lists the organization's pending invitations (expired ones included). Owners and admins only.
+func (s *Server) GetOrgInvitationsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetOrgInvitationsHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if _, ok := s.loadOrgManager(ctx, c, user.ID); !ok {
+		return
+	}
+	orgID := c.Param("id")
+	invitations, err := s.db.GetOrganizationInvitations(ctx, orgID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetOrganizationInvitations failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invitations"})
+		return
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.Before(invitations[j].CreatedAt)
+	})
+	writeJSON(c, http.StatusOK, models.OrganizationInvitationsResponse{Invitations: invitations})
+}
+
+// PostOrgInvitationHandler handles POST /orgs/:id/invitations.
+// Creates a single-use invitation code for role (default member). Owners and admins may invite
+// members; only the owner may invite admins. Returns 201 with the invitation.
+func (s *Server) PostOrgInvitationHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostOrgInvitationHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if body.Role == "" {
+		body.Role = models.OrgRoleMember
+	}
+	if err := models.ValidateOrgGrantableRole(body.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	member, ok := s.loadOrgManager(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+	if body.Role == models.OrgRoleAdmin && member.Role != models.OrgRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "only the organization owner can invite admins",
+		})
+		return
+	}
+
+	orgID := c.Param("id")
+	inv, err := s.db.CreateOrganizationInvitation(ctx, &models.OrganizationInvitation{
+		OrganizationID: orgID,
+		Role:           body.Role,
+		CreatedBy:      user.ID,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrTooManyOrgInvitations) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Error("CreateOrganizationInvitation failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+	log.Info("Created organization invitation", logging.OrganizationID, orgID,
+		logging.UserID, user.ID)
+	writeJSON(c, http.StatusCreated, inv)
+}
+
+// DeleteOrgInvitationHandler handles DELETE /orgs/:id/invitations/:code.
+// Revokes an invitation. Owners and admins only. Returns 204.
+func (s *Server) DeleteOrgInvitationHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteOrgInvitationHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if _, ok := s.loadOrgManager(ctx, c, user.ID); !ok {
+		return
+	}
+	orgID := c.Param("id")
+	if err := s.db.DeleteOrganizationInvitation(ctx, orgID, c.Param("code")); err != nil {
+		if errors.Is(err, database.ErrOrgInvitationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("DeleteOrganizationInvitation failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete invitation"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PostOrgJoinHandler handles POST /orgs/join.
+// Accepts an invitation code, making the current user a member with the invited role. Returns
+// 200 with the organization and role; 404 for an unknown or expired code, 409 when already a
+// member or the organization is full.
+func (s *Server) PostOrgJoinHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostOrgJoinHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error("GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return
+	}
+	if dbUser == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		return
+	}
+
+	org, err := s.db.AcceptOrganizationInvitation(ctx, body.Code, models.OrganizationMember{
+		UserID:   user.ID,
+		Name:     dbUser.Name,
+		ImageURL: dbUser.ImageURL,
+		JoinedAt: time.Now().UTC(),
+	})
+	switch {
+	case errors.Is(err, database.ErrOrgInvitationNotFound),
+		errors.Is(err, database.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found or expired"})
+		return
+	case errors.Is(err, database.ErrOrgMemberAlreadyExists),
+		errors.Is(err, database.ErrOrganizationFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Error("AcceptOrganizationInvitation failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to join organization"})
+		return
+	}
+
+	s.orgVisits.invalidate(org.ID)
+
+	// The role is not returned by the transaction; read it back like GET /orgs/:id would
+	member, err := s.db.GetOrganizationMember(ctx, org.ID, user.ID)
+	role := ""
+	if err == nil {
+		role = member.Role
+	}
+	log.Info("Joined organization", logging.OrganizationID, org.ID, logging.UserID, user.ID)
+	writeJSON(c, http.StatusOK, models.OrganizationMembership{Organization: *org, Role: role})
+}
+
+// PutOrgMemberHandler handles PUT /orgs/:id/members/:userId.
+// Sets a member's role to admin or member. Owner only; the owner's own role cannot change.
+// Returns 204.
+func (s *Server) PutOrgMemberHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PutOrgMemberHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if err := models.ValidateOrgGrantableRole(body.Role); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	member, ok := s.loadOrgMember(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+	if member.Role != models.OrgRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "organization owner required"})
+		return
+	}
+	targetID := c.Param("userId")
+	if targetID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the owner's role cannot be changed"})
+		return
+	}
+
+	orgID := c.Param("id")
+	if err := s.db.UpdateOrganizationMemberRole(ctx, orgID, targetID, body.Role); err != nil {
+		if errors.Is(err, database.ErrOrgMemberNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("UpdateOrganizationMemberRole failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update member"})
+		return
+	}
+	s.orgVisits.invalidate(orgID)
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteOrgMemberHandler handles DELETE /orgs/:id/members/:userId.
+// Members may remove themselves (leave), except the owner, who deletes the organization
+// instead. Owners may remove anyone else and admins may remove members. Returns 204.
+func (s *Server) DeleteOrgMemberHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteOrgMemberHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	member, ok := s.loadOrgMember(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+	orgID := c.Param("id")
+	targetID := c.Param("userId")
+	if targetID == user.ID {
+		if member.Role == models.OrgRoleOwner {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "the owner cannot leave; delete the organization instead",
+			})
+			return
+		}
+	} else {
+		if !member.CanManage() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "organization owner or admin required"})
+			return
+		}
+		target, err := s.db.GetOrganizationMember(ctx, orgID, targetID)
+		if err != nil {
+			if errors.Is(err, database.ErrOrgMemberNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+				return
+			}
+			log.Error("GetOrganizationMember failed", logging.Error, err,
+				logging.OrganizationID, orgID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load member"})
+			return
+		}
+		if target.Role == models.OrgRoleOwner ||
+			(target.Role == models.OrgRoleAdmin && member.Role != models.OrgRoleOwner) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "cannot remove a member of equal or higher role",
+			})
+			return
+		}
+	}
+
+	if err := s.db.RemoveOrganizationMember(ctx, orgID, targetID); err != nil {
+		if errors.Is(err, database.ErrOrgMemberNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+			return
+		}
+		log.Error("RemoveOrganizationMember failed", logging.Error, err,
+			logging.OrganizationID, orgID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove member"})
+		return
+	}
+	s.orgVisits.invalidate(orgID)
+	log.Info("Removed organization member", logging.OrganizationID, orgID, logging.UserID, targetID)
+	c.Status(http.StatusNoContent)
+}
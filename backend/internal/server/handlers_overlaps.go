@@ -0,0 +1,228 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// PostVisitOverlapHandler handles POST /friends/:shareToken/visits/:id/overlaps ("I was there
+// too"). Body: { "visitId"? }. Links the friend's visit :id to the current user's visit visitId
+// (same country), or to a new visit copied from the friend's (country, date, subdivision and
+// destination; privacy and type from the user's VisitDefaults) when visitId is omitted. Only
+// mutual friends may add overlaps: 404 unless both users have each other in their friends list
+// and the friend's visit exists and is not private. 409 if the friend's visit is already linked.
+func (s *Server) PostVisitOverlapHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostVisitOverlapHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	var body struct {
+		VisitID string `json:"visitId,omitempty"`
+	}
+	// An empty body links a new visit
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			log.Warn("Invalid POST overlaps body", logging.Error, err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	_, friendUser, ok := s.loadFriendUser(ctx, c, dbUser.ID)
+	if !ok {
+		return
+	}
+	mutual, err := s.db.GetFriendByShareToken(ctx, friendUser.ID, dbUser.ShareToken)
+	if err != nil {
+		log.Error("GetFriendByShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch friend"})
+		return
+	}
+	if mutual == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "friend not found"})
+		return
+	}
+
+	friendVisit, err := s.db.GetCountryVisit(ctx, c.Param("id"), friendUser.ID)
+	if err != nil && !errors.Is(err, database.ErrVisitNotFound) {
+		log.Error("GetCountryVisit failed for friend", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load visit"})
+		return
+	}
+	if friendVisit == nil || friendVisit.IsPrivate {
+		c.JSON(http.StatusNotFound, gin.H{"error": "visit not found"})
+		return
+	}
+
+	// Checked up front so that a duplicate does not leave a new visit behind
+	overlaps, err := s.db.GetVisitOverlaps(ctx, dbUser.ID)
+	if err != nil {
+		log.Error("GetVisitOverlaps failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch overlaps"})
+		return
+	}
+	for _, o := range overlaps {
+		if o.FriendUserID == friendUser.ID && o.FriendVisitID == friendVisit.ID {
+			c.JSON(http.StatusConflict, gin.H{"error": "visit already linked"})
+			return
+		}
+	}
+
+	var visit *models.CountryVisit
+	created := false
+	if body.VisitID != "" {
+		visit, err = s.db.GetCountryVisit(ctx, body.VisitID, dbUser.ID)
+		if err != nil {
+			if errors.Is(err, database.ErrVisitNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "visit not found"})
+				return
+			}
+			log.Error("GetCountryVisit failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load visit"})
+			return
+		}
+		if visit.CountryCode != friendVisit.CountryCode {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "visitId must be a visit to the same country",
+			})
+			return
+		}
+	} else {
+		settings := dbUser.EffectiveSettings()
+		code := friendVisit.CountryCode
+		_, isHistoric := data.HistoricCountryByCode(code)
+		if !isHistoric && !data.IsVisitableCountry(code, settings.IncludeTerritories) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "countryCode is a territory; enable includeTerritories in settings",
+			})
+			return
+		}
+		var defaults models.VisitDefaults
+		if settings.VisitDefaults != nil {
+			defaults = *settings.VisitDefaults
+		}
+		visit, err = s.db.CreateCountryVisit(ctx, &models.CountryVisit{
+			CountryCode:     friendVisit.CountryCode,
+			VisitedTime:     friendVisit.VisitedTime,
+			Tags:            []string{},
+			IsPrivate:       defaults.IsPrivate,
+			VisitType:       defaults.VisitType,
+			SubdivisionCode: friendVisit.SubdivisionCode,
+			DestinationCode: friendVisit.DestinationCode,
+			UserID:          dbUser.ID,
+		}, s.maxVisits)
+		if err != nil {
+			if writeLimitError(c, err, visitLimitReachedCode, "visits") {
+				return
+			}
+			log.Error("CreateCountryVisit failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create visit"})
+			return
+		}
+		created = true
+	}
+
+	overlap, err := s.db.CreateVisitOverlap(ctx, dbUser.ID, dbUser.ShareToken,
+		&models.VisitOverlap{
+			VisitID:          visit.ID,
+			FriendUserID:     friendUser.ID,
+			FriendShareToken: friendUser.ShareToken,
+			FriendVisitID:    friendVisit.ID,
+		})
+	if err != nil {
+		// Best effort; the visit was created for this overlap only (and not at all in a dry run)
+		if created && visit.ID != "" {
+			if err := s.db.DeleteCountryVisit(ctx, visit.ID, dbUser.ID); err != nil {
+				log.Warn("Failed to delete visit of failed overlap", logging.VisitID, visit.ID,
+					logging.Error, err)
+			}
+		}
+		switch {
+		case errors.Is(err, database.ErrOverlapExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "visit already linked"})
+		case errors.Is(err, database.ErrVisitNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "visit not found"})
+		default:
+			log.Error("CreateVisitOverlap failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create overlap"})
+		}
+		return
+	}
+	attachOverlapsTo(visit, append(overlaps, *overlap))
+	attachSuccessorCodesTo(visit)
+	log.Info("Created visit overlap", logging.VisitID, visit.ID, logging.UserID, dbUser.ID)
+	writeJSON(c, http.StatusCreated, models.VisitOverlapResponse{Overlap: *overlap, Visit: *visit})
+}
+
+// DeleteVisitOverlapHandler handles DELETE /visits/:id/overlaps/:overlapId.
+// Removes the overlap from both users, so either side can undo an "I was there too". Returns
+// 204, or 404 if the current user's visit :id has no such overlap.
+func (s *Server) DeleteVisitOverlapHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteVisitOverlapHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	overlaps, err := s.db.GetVisitOverlaps(ctx, dbUser.ID)
+	if err != nil {
+		log.Error("GetVisitOverlaps failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch overlaps"})
+		return
+	}
+	found := false
+	for _, o := range overlaps {
+		if o.ID == c.Param("overlapId") && o.VisitID == c.Param("id") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "overlap not found"})
+		return
+	}
+	err = s.db.DeleteVisitOverlap(ctx, dbUser.ID, c.Param("overlapId"))
+	if err != nil {
+		if errors.Is(err, database.ErrOverlapNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "overlap not found"})
+			return
+		}
+		log.Error("DeleteVisitOverlap failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete overlap"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// attachOverlaps sets Overlaps on each visit from overlaps, which are in creation order.
+func attachOverlaps(visits []models.CountryVisit, overlaps []models.VisitOverlap) {
+	byVisit := make(map[string][]models.VisitOverlap, len(overlaps))
+	for _, o := range overlaps {
+		byVisit[o.VisitID] = append(byVisit[o.VisitID], o)
+	}
+	for i := range visits {
+		visits[i].Overlaps = byVisit[visits[i].ID]
+	}
+}
+
+// attachOverlapsTo is attachOverlaps for a single visit.
+func attachOverlapsTo(visit *models.CountryVisit, overlaps []models.VisitOverlap) {
+	visits := []models.CountryVisit{*visit}
+	attachOverlaps(visits, overlaps)
+	visit.Overlaps = visits[0].Overlaps
+}
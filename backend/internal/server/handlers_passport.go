@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetSharePassportHandler handles GET /share/:shareToken/passport.
+// Unauthenticated; returns the owner's distinct non-private visited countries as flag emoji
+// grouped by continent. Responds text/plain when ?format=text or the Accept header prefers it.
+func (s *Server) GetSharePassportHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetSharePassportHandler")
+	defer span.End()
+
+	shareToken := c.Param("shareToken")
+	if shareToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "share token required"})
+		return
+	}
+	format := c.Query("format")
+	if format == "" {
+		if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain) == gin.MIMEPlain {
+			format = "text"
+		} else {
+			format = "json"
+		}
+	}
+	if format != "json" && format != "text" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or text"})
+		return
+	}
+
+	log := logging.FromContext(ctx)
+	user, scope, err := s.resolveShareUser(ctx, shareToken, true)
+	if err != nil {
+		log.Error("resolveShareUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	}
+	// The passport lists countries, which summary links do not expose
+	if user == nil || user.PendingDeletion() || scope == models.ShareScopeSummary {
+		shareNotFound(c)
+		return
+	}
+	if blocked, err := s.isBlockedViewer(ctx, user.ID); err != nil {
+		log.Error("IsBlocked failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	} else if blocked {
+		shareNotFound(c)
+		return
+	}
+	s.recordShareView(ctx, user, shareToken)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Vary", "Accept")
+	etag := computeETag("passport", format, user.ID, user.VisitsRevision, user.Name)
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+	visits, err := s.db.GetPublicCountryVisitsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetPublicCountryVisitsByUser failed for passport", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
+		return
+	}
+
+	passport := buildPassport(user.Name, visits)
+	if format == "text" {
+		c.String(http.StatusOK, passport.Text)
+		return
+	}
+	writeJSON(c, http.StatusOK, passport)
+}
+
+// buildPassport groups the distinct countries of visits by continent (data.Regions order),
+// each continent listing countries in order of their first visit.
+func buildPassport(userName string, visits []models.CountryVisit) models.PassportResponse {
+	sorted := make([]models.CountryVisit, len(visits))
+	copy(sorted, visits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].VisitedTime.Before(sorted[j].VisitedTime)
+	})
+
+	byRegion := map[string]*models.PassportContinent{}
+	seen := map[string]struct{}{}
+	for _, v := range sorted {
+		if _, ok := seen[v.CountryCode]; ok {
+			continue
+		}
+		country, ok := data.CountryByCode(v.CountryCode)
+		if !ok {
+			continue
+		}
+		seen[v.CountryCode] = struct{}{}
+		group, ok := byRegion[country.RegionCode]
+		if !ok {
+			group = &models.PassportContinent{
+				RegionCode:   country.RegionCode,
+				Name:         data.RegionName(country.RegionCode),
+				CountryCodes: []string{},
+			}
+			byRegion[country.RegionCode] = group
+		}
+		group.Count++
+		group.Flags += country.FlagEmoji
+		group.CountryCodes = append(group.CountryCodes, country.CountryCode)
+	}
+
+	out := models.PassportResponse{
+		UserName:       userName,
+		CountriesCount: len(seen),
+		Continents:     []models.PassportContinent{},
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "🌍 %s: %d %s\n", userName, len(seen), pluralCountries(len(seen)))
+	for _, region := range data.Regions {
+		group, ok := byRegion[region]
+		if !ok {
+			continue
+		}
+		out.Continents = append(out.Continents, *group)
+		fmt.Fprintf(&text, "%s (%d): %s\n", group.Name, group.Count, group.Flags)
+	}
+	out.Text = text.String()
+	return out
+}
+
+func pluralCountries(n int) string {
+	if n == 1 {
+		return "country"
+	}
+	return "countries"
+}
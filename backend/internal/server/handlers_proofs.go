@@ -0,0 +1,221 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/proofs"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// PostVisitProofHandler handles POST /visits/:id/proofs?kind=<kind>.
+// The request body is the raw file (JPEG, PNG or PDF, at most models.MaxProofBytes); its type is
+// detected from the content. Stores the file and attaches it to the current user's visit, which
+// makes the visit verified. Returns 201 with the updated visit.
+func (s *Server) PostVisitProofHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostVisitProofHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	visitID := c.Param("id")
+	if visitID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visit id required"})
+		return
+	}
+	kind := c.Query("kind")
+	if err := models.ValidateProofKind(kind); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if s.proofs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "proof storage not configured"})
+		return
+	}
+
+	fileData, err := io.ReadAll(io.LimitReader(c.Request.Body, models.MaxProofBytes+1))
+	if err != nil {
+		log.Warn("Failed to read proof upload", logging.Error, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if len(fileData) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "proof file required"})
+		return
+	}
+	if len(fileData) > models.MaxProofBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "proof file too large"})
+		return
+	}
+	contentType := http.DetectContentType(fileData)
+	if !models.IsProofContentType(contentType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "proof file must be a JPEG, PNG or PDF",
+		})
+		return
+	}
+
+	visit, err := s.db.GetCountryVisit(ctx, visitID, user.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrVisitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "visit not found"})
+			return
+		}
+		log.Error("GetCountryVisit failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load visit"})
+		return
+	}
+	if len(visit.Proofs) >= models.MaxProofsPerVisit {
+		c.JSON(http.StatusConflict, gin.H{"error": "visit already has the maximum number of proofs"})
+		return
+	}
+
+	proof := models.VisitProof{
+		ID:          uuid.New().String(),
+		Kind:        kind,
+		ContentType: contentType,
+		Size:        int64(len(fileData)),
+		UploadedAt:  time.Now().UTC(),
+	}
+	objectName := proofs.ObjectName(user.ID, visit.ID, proof.ID)
+	if !isDryRun(ctx) {
+		if err := s.proofs.Put(ctx, objectName, contentType, fileData); err != nil {
+			log.Error("Failed to store proof", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store proof"})
+			return
+		}
+	}
+
+	visit.Proofs = append(visit.Proofs, proof)
+	visit.Verified = true
+	if err := s.db.ReplaceCountryVisit(ctx, visit, time.Time{}); err != nil {
+		log.Error("ReplaceCountryVisit failed", logging.Error, err)
+		s.deleteProofObjects(ctx, user.ID, visit.ID, []models.VisitProof{proof})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update visit"})
+		return
+	}
+	log.Info("Attached visit proof", logging.VisitID, visit.ID, logging.UserID, user.ID)
+	writeJSON(c, http.StatusCreated, visit)
+}
+
+// GetVisitProofHandler handles GET /visits/:id/proofs/:proofId.
+// Serves a proof file of one of the current user's visits. Proof files are never public.
+func (s *Server) GetVisitProofHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetVisitProofHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	if s.proofs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "proof storage not configured"})
+		return
+	}
+	visit, proof, ok := s.loadVisitProof(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+
+	fileData, _, err := s.proofs.Get(ctx, proofs.ObjectName(user.ID, visit.ID, proof.ID))
+	if err != nil {
+		if errors.Is(err, proofs.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "proof not found"})
+			return
+		}
+		log.Error("Failed to read proof", logging.Error, err, logging.VisitID, visit.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read proof"})
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'")
+	c.Data(http.StatusOK, proof.ContentType, fileData)
+}
+
+// DeleteVisitProofHandler handles DELETE /visits/:id/proofs/:proofId.
+// Detaches the proof from the current user's visit and deletes its file. Returns 204; the visit
+// stops being verified when its last proof is removed.
+func (s *Server) DeleteVisitProofHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteVisitProofHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	visit, proof, ok := s.loadVisitProof(ctx, c, user.ID)
+	if !ok {
+		return
+	}
+
+	visit.Proofs = slices.DeleteFunc(visit.Proofs, func(p models.VisitProof) bool {
+		return p.ID == proof.ID
+	})
+	visit.Verified = len(visit.Proofs) > 0
+	if err := s.db.ReplaceCountryVisit(ctx, visit, time.Time{}); err != nil {
+		log.Error("ReplaceCountryVisit failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update visit"})
+		return
+	}
+	s.deleteProofObjects(ctx, user.ID, visit.ID, []models.VisitProof{proof})
+	log.Info("Removed visit proof", logging.VisitID, visit.ID, logging.UserID, user.ID)
+	c.Status(http.StatusNoContent)
+}
+
+// loadVisitProof loads the visit and proof named by the :id and :proofId parameters. It writes
+// the error response and returns false when either is missing.
+func (s *Server) loadVisitProof(
+	ctx context.Context,
+	c *gin.Context,
+	userID string,
+) (*models.CountryVisit, models.VisitProof, bool) {
+	visitID := c.Param("id")
+	proofID := c.Param("proofId")
+	if visitID == "" || proofID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visit id and proof id required"})
+		return nil, models.VisitProof{}, false
+	}
+	visit, err := s.db.GetCountryVisit(ctx, visitID, userID)
+	if err != nil {
+		if errors.Is(err, database.ErrVisitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "visit not found"})
+			return nil, models.VisitProof{}, false
+		}
+		logging.FromContext(ctx).Error("GetCountryVisit failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load visit"})
+		return nil, models.VisitProof{}, false
+	}
+	for _, p := range visit.Proofs {
+		if p.ID == proofID {
+			return visit, p, true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "proof not found"})
+	return nil, models.VisitProof{}, false
+}
+
+// deleteProofObjects deletes the stored files of proofs. Failures are logged only: an orphaned
+// file is never served since no visit refers to it. Skipped on dry runs.
+func (s *Server) deleteProofObjects(
+	ctx context.Context,
+	userID, visitID string,
+	visitProofs []models.VisitProof,
+) {
+	if s.proofs == nil || isDryRun(ctx) {
+		return
+	}
+	for _, p := range visitProofs {
+		if err := s.proofs.Delete(ctx, proofs.ObjectName(userID, visitID, p.ID)); err != nil {
+			logging.FromContext(ctx).Warn("Failed to delete proof file", logging.Error, err,
+				logging.VisitID, visitID)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/search"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetVisitSearchHandler handles GET /visits/search?q=.
+// Loads the current user's visits and ranks them in memory against q
+// (country name or code, tags, notes).
+func (s *Server) GetVisitSearchHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetVisitSearchHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if len(q) > search.MaxQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("q must be at most %d characters", search.MaxQueryLength),
+		})
+		return
+	}
+
+	visits, err := s.db.GetCountryVisitsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetCountryVisitsByUser failed for search", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search visits"})
+		return
+	}
+	results := search.Visits(visits, q)
+	log.Info("Searched country visits", logging.Count, len(results))
+	writeJSON(c, http.StatusOK, models.VisitSearchResponse{Results: results})
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// PostSessionHandler handles POST /session.
+// Exchanges the ID token in Authorization: Bearer for an HttpOnly session cookie, so the
+// browser stays signed in without refreshing ID tokens. Returns 204 with the cookie; 400 when
+// the request authenticated with a session cookie instead of a token, 401 when the token is
+// refused for the session, 403 for API keys and 503 without session support.
+func (s *Server) PostSessionHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostSessionHandler")
+	defer span.End()
+
+	if s.sessions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "session cookies are not enabled"})
+		return
+	}
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || strings.TrimSpace(token) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID token required"})
+		return
+	}
+	cookie, err := s.sessions.Create(ctx, strings.TrimSpace(token), s.sessionTTL)
+	if err != nil {
+		// The token was verified by authMiddleware; Firebase may still refuse e.g. an old sign-in
+		logging.FromContext(ctx).Warn("Failed to create session cookie", logging.Error, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "failed to create session"})
+		return
+	}
+	s.setSessionCookie(c, cookie, int(s.sessionTTL.Seconds()))
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteSessionHandler handles DELETE /session.
+// Signs out by clearing the session cookie, whether or not it is valid. Returns 204. The cookie
+// itself stays valid until it expires, like an ID token.
+func (s *Server) DeleteSessionHandler(ctx context.Context, c *gin.Context) {
+	_, span := tracing.New(ctx, "DeleteSessionHandler")
+	defer span.End()
+
+	s.setSessionCookie(c, "", -1)
+	c.Status(http.StatusNoContent)
+}
+
+// PostRevokeSessionsHandler handles POST /me/revoke-sessions.
+// Revokes the current user's ID tokens and session cookies from all sign-ins so far, including
+// the current one, and clears the session cookie. Enforced only in strict auth mode (see
+// WithRevocationCheck). Returns 204; 403 for API keys and 404 without a user document.
+func (s *Server) PostRevokeSessionsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostRevokeSessionsHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	// auth_time has whole seconds; a sign-in later within this second stays valid
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := s.db.SetTokensRevokedAt(ctx, user.ID, now); err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+			return
+		}
+		log.Error("SetTokensRevokedAt failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+	log.Info("Revoked sessions", logging.UserID, user.ID, "strict", s.checkRevoked)
+	s.recordAudit(ctx, c, user.ID, models.AuditSessionsRevoked, "")
+	if !isDryRun(ctx) {
+		s.setSessionCookie(c, "", -1)
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// resolveShareUser returns the user whose share token opens, with the scope of the share: the
+// user's permanent ShareToken (models.ShareScopeFull) or an active ShareLink, whose view is
+// counted when countView is set and the link has MaxViews. Returns a nil user (not error) when
+// the token opens nothing or the user has disabled sharing.
+func (s *Server) resolveShareUser(
+	ctx context.Context,
+	token string,
+	countView bool,
+) (*models.User, string, error) {
+	user, err := s.db.GetUserByShareToken(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+	if user != nil {
+		if user.SharingDisabled {
+			return nil, "", nil
+		}
+		return user, models.ShareScopeFull, nil
+	}
+	link, err := s.db.GetShareLinkByToken(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now().UTC()
+	if link == nil || !link.Active(now) {
+		return nil, "", nil
+	}
+	if countView && link.MaxViews > 0 {
+		err := s.db.CountShareLinkView(ctx, link.UserID, link.Token, now)
+		if errors.Is(err, database.ErrShareLinkNotFound) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	scope := link.Scope
+	if scope == "" {
+		scope = models.ShareScopeFull
+	}
+	user, err = s.db.GetUserByID(ctx, link.UserID)
+	if err != nil || user == nil || user.SharingDisabled {
+		return nil, "", err
+	}
+	return user, scope, nil
+}
+
+// applyShareScope fills the counts and country codes of resp from its visits and removes what
+// scope does not expose.
+func applyShareScope(resp *models.ShareProfileResponse, scope string) {
+	seen := make(map[string]bool)
+	codes := []string{}
+	for _, v := range resp.Visits {
+		if !seen[v.CountryCode] {
+			seen[v.CountryCode] = true
+			codes = append(codes, v.CountryCode)
+		}
+	}
+	sort.Strings(codes)
+	resp.Scope = scope
+	resp.VisitCount = len(resp.Visits)
+	resp.CountriesCount = len(codes)
+	resp.CountryCodes = codes
+	if scope == models.ShareScopeFull {
+		return
+	}
+	resp.Visits = []models.CountryVisit{}
+	if scope == models.ShareScopeSummary {
+		resp.CountryCodes = nil
+	}
+}
+
+// GetShareLinksHandler handles GET /me/share-links.
+// Returns the current user's unexpired share links, newest first.
+func (s *Server) GetShareLinksHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetShareLinksHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	links, err := s.db.GetShareLinks(ctx, user.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("GetShareLinks failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share links"})
+		return
+	}
+	writeJSON(c, http.StatusOK, models.ShareLinksResponse{ShareLinks: links})
+}
+
+// PostShareLinkHandler handles POST /me/share-links.
+// Body: { "expiresAt", "scope"?, "maxViews"? }. Creates a share link opening the current user's
+// share views in scope (default models.ShareScopeFull) until expiresAt or maxViews views.
+// Returns 201 with the ShareLink; 400 for invalid values and 409 when the user has
+// models.MaxShareLinks links.
+func (s *Server) PostShareLinkHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostShareLinkHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		ExpiresAt *jsontime.Time `json:"expiresAt"`
+		Scope     string         `json:"scope"`
+		MaxViews  int            `json:"maxViews"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.ExpiresAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expiresAt is required"})
+		return
+	}
+	if err := models.ValidateShareLink(body.ExpiresAt.Time, body.MaxViews,
+		time.Now().UTC()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if body.Scope == "" {
+		body.Scope = models.ShareScopeFull
+	}
+	if err := models.ValidateShareScope(body.Scope); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	link, err := s.db.CreateShareLink(ctx, &models.ShareLink{
+		UserID:    user.ID,
+		ExpiresAt: body.ExpiresAt.Time,
+		Scope:     body.Scope,
+		MaxViews:  body.MaxViews,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrTooManyShareLinks) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Error("CreateShareLink failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create share link"})
+		return
+	}
+	log.Info("Created share link", logging.UserID, user.ID)
+	writeJSON(c, http.StatusCreated, link)
+}
+
+// DeleteShareLinkHandler handles DELETE /me/share-links/:token.
+// Revokes a share link at once. Returns 204, or 404.
+func (s *Server) DeleteShareLinkHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "DeleteShareLinkHandler")
+	defer span.End()
+
+	user := ctxkeys.MustCurrentUser(ctx)
+	if err := s.db.DeleteShareLink(ctx, user.ID, c.Param("token")); err != nil {
+		if errors.Is(err, database.ErrShareLinkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "share link not found"})
+			return
+		}
+		logging.FromContext(ctx).Error("DeleteShareLink failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete share link"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetShareStatsHandler handles GET /me/share-stats.
+// Returns the view statistics of the current user's ShareToken followed by those of their
+// unexpired share links, newest first. Returns 404 without a user document.
+func (s *Server) GetShareStatsHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetShareStatsHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	links, err := s.db.GetShareLinks(ctx, dbUser.ID)
+	if err != nil {
+		log.Error("GetShareLinks failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share stats"})
+		return
+	}
+	tokens := []string{dbUser.ShareToken}
+	for _, l := range links {
+		tokens = append(tokens, l.Token)
+	}
+	stats, err := s.db.GetShareStats(ctx, dbUser.ID, tokens)
+	if err != nil {
+		log.Error("GetShareStats failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share stats"})
+		return
+	}
+	now := time.Now().UTC()
+	for i := range stats {
+		stats[i].Kind = models.ShareKindShareLink
+		if i == 0 {
+			stats[i].Kind = models.ShareKindShareToken
+		}
+		stats[i].SetDaily(now)
+	}
+	writeJSON(c, http.StatusOK, models.ShareStatsResponse{ShareStats: stats})
+}
+
+// recordShareView counts a view of the share token of owner for GET /me/share-stats unless the
+// signed-in viewer is the owner. Failures are logged; they do not fail the view.
+func (s *Server) recordShareView(ctx context.Context, owner *models.User, token string) {
+	if viewer, ok := ctxkeys.CurrentUser(ctx); ok && viewer.ID == owner.ID {
+		return
+	}
+	if err := s.db.RecordShareView(ctx, owner.ID, token, time.Now().UTC()); err != nil {
+		logging.FromContext(ctx).Warn("Failed to record share view", logging.Error, err)
+	}
+}
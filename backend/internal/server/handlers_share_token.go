@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// QR code sizes of GET /me/share-token/qr in pixels.
+const (
+	defaultQRSize = 512
+	minQRSize     = 128
+	maxQRSize     = 1024
+)
+
+// PostRotateShareTokenHandler handles POST /me/share-token/rotate.
+// Optional body: { "removeFromFriends" }. Replaces the current user's ShareToken so a leaked
+// share link stops working. Friends keep the user under the new token unless removeFromFriends
+// is set, in which case the user is removed from their friends lists. Returns 200 with the
+// ShareTokenRotation; 403 for API keys and 404 without a user document.
+func (s *Server) PostRotateShareTokenHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostRotateShareTokenHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+	var body struct {
+		RemoveFromFriends bool `json:"removeFromFriends"`
+	}
+	// An empty body keeps the user in the friends lists
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	token, removed, err := s.db.RotateShareToken(ctx, user.ID, body.RemoveFromFriends)
+	if err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+			return
+		}
+		log.Error("RotateShareToken failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate share token"})
+		return
+	}
+	log.Info("Rotated share token", logging.UserID, user.ID, logging.Count, removed)
+	s.recordAudit(ctx, c, user.ID, models.AuditShareTokenRotated, "")
+	writeJSON(c, http.StatusOK, models.ShareTokenRotation{
+		ShareToken:         token,
+		RemovedFromFriends: removed,
+	})
+}
+
+// GetShareTokenQRHandler handles GET /me/share-token/qr.
+// Returns a PNG QR code of the current user's share URL, for adding friends in person; ?size=
+// sets the width and height in pixels (128-1024, default 512). The URL starts with
+// PUBLIC_BASE_URL, or the request's origin when unset. Returns 400 for an invalid size and 404
+// without a user document.
+func (s *Server) GetShareTokenQRHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetShareTokenQRHandler")
+	defer span.End()
+
+	size := defaultQRSize
+	if raw := c.Query("size"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < minQRSize || n > maxQRSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "size must be an integer from 128 to 1024"})
+			return
+		}
+		size = n
+	}
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	shareURL := s.baseURL(c) + "/share/" + url.PathEscape(dbUser.ShareToken)
+	etag := computeETag("qr", shareURL, size)
+	c.Header("Cache-Control", "private, no-cache")
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+	png, err := qrcode.Encode(shareURL, qrcode.Medium, size)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to encode QR code", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create QR code"})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// baseURL returns the frontend origin for links: PUBLIC_BASE_URL, or the origin of the request
+// (honoring X-Forwarded-Proto from the load balancer) when unset.
+func (s *Server) baseURL(c *gin.Context) string {
+	if s.publicBaseURL != "" {
+		return s.publicBaseURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "https" || proto == "http" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetStaticManifestHandler handles GET /static/manifest.json.
+// Returns the manifest of the embedded frontend files (size, SHA-256 and, for scripts and
+// styles, Subresource Integrity) so clients can detect a new build and bust their caches.
+// Unauthenticated.
+func (s *Server) GetStaticManifestHandler(ctx context.Context, c *gin.Context) {
+	_, span := tracing.New(ctx, "GetStaticManifestHandler")
+	defer span.End()
+
+	if s.staticManifestJSON == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "static manifest not available"})
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	if writeNotModifiedIfMatch(c, computeETag("static-manifest", string(s.staticManifestJSON))) {
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", s.staticManifestJSON)
+}
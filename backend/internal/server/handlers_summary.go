@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetVisitSummaryHandler handles GET /visits/summary?country=.
+// Returns visit and country counts for the current user and how many neighbors of ?country
+// (default: the user's home country) they have visited. The counts are the User's VisitCount
+// and DistinctCountries, so only the neighbors' visits are read.
+func (s *Server) GetVisitSummaryHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetVisitSummaryHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+
+	countryCode := ""
+	if raw := c.Query("country"); raw != "" {
+		country, ok := data.CountryByCode(raw)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid country"})
+			return
+		}
+		countryCode = country.CountryCode
+	}
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error("GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user"})
+		return
+	}
+	if countryCode == "" {
+		countryCode = dbUser.EffectiveSettings().HomeCountryCode
+	}
+
+	summary := models.VisitSummaryResponse{}
+	if dbUser != nil {
+		summary.VisitCount = int(dbUser.VisitCount)
+		summary.CountryCount = int(dbUser.DistinctCountries)
+	}
+	if countryCode != "" {
+		codes, err := s.db.GetVisitedCountryCodes(ctx, user.ID, data.Neighbors(countryCode))
+		if err != nil {
+			log.Error("GetVisitedCountryCodes failed for summary", logging.Error, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch country visits"})
+			return
+		}
+		visited := make(map[string]struct{}, len(codes))
+		for _, code := range codes {
+			visited[code] = struct{}{}
+		}
+		summary.Neighbors = neighborsVisited(countryCode, visited)
+	}
+	writeJSON(c, http.StatusOK, summary)
+}
+
+// neighborsVisited splits the neighbors of countryCode into visited and not visited ones.
+func neighborsVisited(countryCode string, visited map[string]struct{}) *models.NeighborsVisited {
+	stat := &models.NeighborsVisited{
+		CountryCode:     countryCode,
+		VisitedCodes:    []string{},
+		NotVisitedCodes: []string{},
+	}
+	for _, n := range data.Neighbors(countryCode) {
+		stat.Total++
+		if _, ok := visited[n]; ok {
+			stat.Visited++
+			stat.VisitedCodes = append(stat.VisitedCodes, n)
+		} else {
+			stat.NotVisitedCodes = append(stat.NotVisitedCodes, n)
+		}
+	}
+	return stat
+}
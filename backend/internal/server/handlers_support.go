@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/buildinfo"
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/features"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// GetSupportBundleHandler handles GET /support/bundle.
+// Returns non-sensitive diagnostics (app version, recent requests, data counts, enabled flags)
+// for the current user to attach to a bug report.
+func (s *Server) GetSupportBundleHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetSupportBundleHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	user := ctxkeys.MustCurrentUser(ctx)
+
+	dbUser, err := s.db.GetUserByID(ctx, user.ID)
+	if err != nil {
+		log.Error("GET /support/bundle: GetUserByID failed", logging.UserID, user.ID, logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build support bundle"})
+		return
+	}
+	if dbUser == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found; complete login first"})
+		return
+	}
+	visits, err := s.db.GetCountryVisitsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GET /support/bundle: GetCountryVisitsByUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build support bundle"})
+		return
+	}
+	friends, err := s.db.GetFriendsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GET /support/bundle: GetFriendsByUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build support bundle"})
+		return
+	}
+
+	settings := dbUser.EffectiveSettings()
+	flags := map[string]bool{
+		"sharing.shareMediaUrl": settings.Sharing.ShareMediaURL,
+		"sharing.shareNotes":    settings.Sharing.ShareNotes,
+		"sharing.shareTags":     settings.Sharing.ShareTags,
+		"homeCountryCode":       settings.HomeCountryCode != "",
+		"instagramUserName":     settings.InstagramUserName != "",
+		"description":           settings.Description != "",
+		"tester":                user.IsTester,
+	}
+	for _, name := range features.FromContext(ctx).Names() {
+		flags["preview."+name] = true
+	}
+	writeJSON(c, http.StatusOK, models.SupportBundle{
+		GeneratedAt:    time.Now().UTC(),
+		AppVersion:     buildinfo.Version,
+		Revision:       buildinfo.Revision(),
+		GoVersion:      buildinfo.GoVersion(),
+		UserID:         user.ID,
+		RecentRequests: s.recentRequests.list(user.ID),
+		Counts: models.SupportBundleCounts{
+			Visits:  len(visits),
+			Friends: len(friends),
+		},
+		Flags:         flags,
+		DataResidency: string(s.residency),
+	})
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// decode unmarshals the body of w into v.
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body.String(), err)
+	}
+}
+
+// postVisit adds a visit to countryCode as userID and returns it.
+func postVisit(t *testing.T, s *Server, userID, countryCode string) models.CountryVisit {
+	t.Helper()
+	w := doAs(t, s, userID, http.MethodPost, "/visits",
+		`{"countryCode":"`+countryCode+`","visitedTime":1577836800}`)
+	requireStatus(t, w, http.StatusCreated)
+	var visit models.CountryVisit
+	decode(t, w, &visit)
+	return visit
+}
+
+func TestVisitsCRUD(t *testing.T) {
+	s, _ := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+
+	fi := postVisit(t, s, "u1", "FIN")
+	if fi.ID == "" || fi.CountryCode != "FI" {
+		t.Fatalf("created %+v, want an FI visit with an ID", fi)
+	}
+	se := postVisit(t, s, "u1", "SE")
+
+	w := doAs(t, s, "u1", http.MethodGet, "/visits", "")
+	requireStatus(t, w, http.StatusOK)
+	var list models.CountryVisitResponse
+	decode(t, w, &list)
+	if len(list.Visits) != 2 {
+		t.Fatalf("listed %d visits, want 2", len(list.Visits))
+	}
+
+	w = doAs(t, s, "u1", http.MethodPut, "/visits/"+fi.ID, `{"notes":"Sauna"}`)
+	requireStatus(t, w, http.StatusOK)
+	var updated models.CountryVisit
+	decode(t, w, &updated)
+	if updated.Notes != "Sauna" || updated.CountryCode != "FI" {
+		t.Errorf("updated %+v, want FI with notes", updated)
+	}
+
+	requireStatus(t, doAs(t, s, "u1", http.MethodDelete, "/visits/"+se.ID, ""),
+		http.StatusNoContent)
+	requireStatus(t, doAs(t, s, "u1", http.MethodDelete, "/visits/"+se.ID, ""),
+		http.StatusNotFound)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPut, "/visits/"+se.ID, `{"notes":"x"}`),
+		http.StatusNotFound)
+
+	// Visits are only the owner's
+	requireStatus(t, doAs(t, s, "u2", http.MethodPost, "/login", ""), http.StatusOK)
+	requireStatus(t, doAs(t, s, "u2", http.MethodDelete, "/visits/"+fi.ID, ""),
+		http.StatusNotFound)
+
+	w = doAs(t, s, "u1", http.MethodGet, "/visits", "")
+	requireStatus(t, w, http.StatusOK)
+	decode(t, w, &list)
+	if len(list.Visits) != 1 || list.Visits[0].ID != fi.ID {
+		t.Errorf("listed %+v, want only %s", list.Visits, fi.ID)
+	}
+}
+
+func TestPostVisitValidation(t *testing.T) {
+	s, _ := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+
+	for _, body := range []string{
+		`{"countryCode":"FI"}`,
+		`{"countryCode":"XX","visitedTime":1577836800}`,
+		`{"countryCode":"FI","visitedTime":-2208988801}`,
+	} {
+		requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/visits", body),
+			http.StatusBadRequest)
+	}
+	requireStatus(t, do(t, s, http.MethodPost, "/visits",
+		`{"countryCode":"FI","visitedTime":1577836800}`), http.StatusUnauthorized)
+}
+
+func TestGetVisitsETag(t *testing.T) {
+	s, _ := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+	postVisit(t, s, "u1", "FI")
+
+	w := doAs(t, s, "u1", http.MethodGet, "/visits", "")
+	requireStatus(t, w, http.StatusOK)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+
+	w = doAs(t, s, "u1", http.MethodGet, "/visits", "", "If-None-Match", etag)
+	requireStatus(t, w, http.StatusNotModified)
+	if w.Body.Len() != 0 {
+		t.Errorf("304 body = %q, want empty", w.Body.String())
+	}
+
+	// Another user's list has its own ETag
+	requireStatus(t, doAs(t, s, "u2", http.MethodPost, "/login", ""), http.StatusOK)
+	requireStatus(t, doAs(t, s, "u2", http.MethodGet, "/visits", "", "If-None-Match", etag),
+		http.StatusOK)
+
+	// A visit write changes the list
+	postVisit(t, s, "u1", "SE")
+	w = doAs(t, s, "u1", http.MethodGet, "/visits", "", "If-None-Match", etag)
+	requireStatus(t, w, http.StatusOK)
+	if w.Header().Get("ETag") == etag {
+		t.Error("ETag unchanged after adding a visit")
+	}
+}
+
+func TestVisitUnmodifiedSince(t *testing.T) {
+	s, _ := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+	visit := postVisit(t, s, "u1", "FI")
+	path := "/visits/" + visit.ID
+
+	requireStatus(t, doAs(t, s, "u1", http.MethodPatch, path, `{"notes":"a"}`),
+		http.StatusPreconditionRequired)
+
+	w := doAs(t, s, "u1", http.MethodPatch, path, `{"notes":"a"}`,
+		"If-Unmodified-Since", visit.UpdatedAt.UTC().Format(http.TimeFormat))
+	requireStatus(t, w, http.StatusOK)
+	lastModified := w.Header().Get("Last-Modified")
+	if lastModified == "" {
+		t.Fatal("no Last-Modified")
+	}
+
+	// The first read is stale now, for PATCH and for PUT sending the header
+	stale := visit.UpdatedAt.UTC().Format(http.TimeFormat)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPatch, path, `{"notes":"b"}`,
+		"If-Unmodified-Since", stale), http.StatusPreconditionFailed)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPut, path, `{"notes":"b"}`,
+		"If-Unmodified-Since", stale), http.StatusPreconditionFailed)
+
+	w = doAs(t, s, "u1", http.MethodPatch, path, `{"notes":"b"}`,
+		"If-Unmodified-Since", lastModified)
+	requireStatus(t, w, http.StatusOK)
+	var updated models.CountryVisit
+	decode(t, w, &updated)
+	if updated.Notes != "b" {
+		t.Errorf("notes = %q, want b", updated.Notes)
+	}
+}
+
+func TestEmptyListsAreNotNull(t *testing.T) {
+	s, _ := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+	for path, want := range map[string]string{
+		"/visits":          `"visits":[]`,
+		"/friends":         `"friends":[]`,
+		"/friends/invites": `"invites":[]`,
+	} {
+		w := doAs(t, s, "u1", http.MethodGet, path, "")
+		requireStatus(t, w, http.StatusOK)
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET %s = %s, want %s", path, w.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/database"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// PostUpgradeHandler handles POST /me/upgrade.
+// Body: { "anonymousIdToken" }, an ID token of the guest account (Firebase anonymous sign-in)
+// the user had before signing in with a provider. Moves the guest's visits to the current user
+// and deletes the guest account. Returns 200 with the GuestUpgradeResponse (0 moved visits when
+// the guest account was linked and so is the current user); 400 for an invalid or non-guest
+// token, 403 for API keys and when the current user is a guest, 404 without a user document or
+// guest account.
+func (s *Server) PostUpgradeHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "PostUpgradeHandler")
+	defer span.End()
+
+	log := logging.FromContext(ctx)
+	dbUser, ok := s.currentDBUser(ctx, c)
+	if !ok {
+		return
+	}
+	if dbUser.IsAnonymous {
+		c.JSON(http.StatusForbidden, gin.H{"error": "sign in with a permanent account first"})
+		return
+	}
+	var body struct {
+		AnonymousIDToken string `json:"anonymousIdToken"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if body.AnonymousIDToken == "" {
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"anonymousIdToken": "is required",
+		}))
+		return
+	}
+	claims, err := s.auth.VerifyIDToken(ctx, body.AnonymousIDToken)
+	if err != nil || !claims.Anonymous {
+		if err != nil {
+			log.Warn("Guest token verification failed", logging.Error, err)
+		}
+		c.JSON(http.StatusBadRequest, models.NewValidationErrors(map[string]string{
+			"anonymousIdToken": "must be a valid ID token of a guest sign-in",
+		}))
+		return
+	}
+	if claims.Sub == dbUser.ID {
+		writeJSON(c, http.StatusOK, models.GuestUpgradeResponse{})
+		return
+	}
+
+	moved, err := s.db.MergeGuestUser(ctx, claims.Sub, dbUser.ID, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "guest account not found"})
+		case errors.Is(err, database.ErrNotGuest):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Error("MergeGuestUser failed", logging.Error, err)
+			c.JSON(http.StatusInternalServerError,
+				gin.H{"error": "failed to upgrade guest account"})
+		}
+		return
+	}
+	log.Info("Merged guest account", logging.UserID, dbUser.ID, "guestUserId", claims.Sub,
+		"movedVisits", moved)
+	s.recordAudit(ctx, c, dbUser.ID, models.AuditAccountsMerged, claims.Sub)
+	writeJSON(c, http.StatusOK, models.GuestUpgradeResponse{MovedVisits: moved})
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// visitStreamHeartbeat is how often GET /visits/stream writes a comment line, so proxies do not
+// close an idle stream.
+const visitStreamHeartbeat = 25 * time.Second
+
+// maxVisitStreamsPerUser bounds the open visit streams of a user on one instance; each holds a
+// Firestore listener.
+const maxVisitStreamsPerUser = 5
+
+// visitStreamReadyEvent is the first event of a visit stream, sent once changes are listened to.
+const visitStreamReadyEvent = "ready"
+
+// VisitWatcher listens to changes of a user's country visits (see
+// database.Client.WatchCountryVisits).
+type VisitWatcher interface {
+	WatchCountryVisits(
+		ctx context.Context,
+		userID string,
+		ready func() error,
+		fn func(changes []models.VisitChange) error,
+	) error
+}
+
+// WithVisitWatcher sets the listener behind GET /visits/stream. Without it the route responds
+// 503.
+func WithVisitWatcher(w VisitWatcher) Option {
+	return func(s *Server) {
+		s.visitWatcher = w
+	}
+}
+
+// visitStreams counts the open visit streams per user and ends them on shutdown.
+type visitStreams struct {
+	mu        sync.Mutex
+	open      map[string]int
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+func newVisitStreams() *visitStreams {
+	return &visitStreams{open: make(map[string]int), closing: make(chan struct{})}
+}
+
+// acquire counts a new stream of userID, or returns false when the user has too many open.
+func (v *visitStreams) acquire(userID string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.open[userID] >= maxVisitStreamsPerUser {
+		return false
+	}
+	v.open[userID]++
+	return true
+}
+
+// count returns the number of open streams.
+func (v *visitStreams) count() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	n := 0
+	for _, open := range v.open {
+		n += open
+	}
+	return n
+}
+
+func (v *visitStreams) release(userID string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.open[userID]--; v.open[userID] <= 0 {
+		delete(v.open, userID)
+	}
+}
+
+// CloseStreams ends the open visit streams, whose clients then reconnect to another instance.
+// Call it when the HTTP server shuts down (http.Server.RegisterOnShutdown), which otherwise
+// waits for the streams to end.
+func (s *Server) CloseStreams() {
+	s.visitStreams.closeOnce.Do(func() { close(s.visitStreams.closing) })
+}
+
+// sseEvent is a Server-Sent Event with a JSON data line.
+type sseEvent struct {
+	name string
+	data any
+}
+
+// GetVisitStreamHandler handles GET /visits/stream.
+// Streams changes of the current user's visits as Server-Sent Events: "ready" once listening,
+// then "added", "modified" and "removed" with a models.VisitChange each.
+func (s *Server) GetVisitStreamHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetVisitStreamHandler")
+	defer span.End()
+
+	if s.visitWatcher == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "visit streams not configured"})
+		return
+	}
+	log := logging.FromContext(ctx)
+	userID := ctxkeys.MustCurrentUser(ctx).ID
+	if !s.visitStreams.acquire(userID) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many open visit streams"})
+		return
+	}
+	defer s.visitStreams.release(userID)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan sseEvent)
+	done := make(chan error, 1)
+	send := func(ev sseEvent) error {
+		select {
+		case events <- ev:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	go func() {
+		done <- s.visitWatcher.WatchCountryVisits(ctx, userID,
+			func() error {
+				return send(sseEvent{name: visitStreamReadyEvent, data: gin.H{}})
+			},
+			func(changes []models.VisitChange) error {
+				for _, ch := range changes {
+					if ch.Visit != nil {
+						attachSuccessorCodesTo(ch.Visit)
+					}
+					if err := send(sseEvent{name: ch.Type, data: ch}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+	}()
+
+	heartbeat := time.NewTicker(visitStreamHeartbeat)
+	defer heartbeat.Stop()
+	started := false
+	for {
+		select {
+		case ev := <-events:
+			if !started {
+				c.Header("Content-Type", "text/event-stream")
+				c.Header("Cache-Control", "no-store")
+				c.Header("X-Accel-Buffering", "no")
+				c.Status(http.StatusOK)
+				started = true
+				log.Info("Visit stream opened", logging.UserID, userID)
+			}
+			if err := writeSSEvent(c, ev); err != nil {
+				log.Info("Visit stream closed", logging.UserID, userID, logging.Error, err)
+				return
+			}
+		case <-heartbeat.C:
+			if !started {
+				continue
+			}
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case err := <-done:
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			log.Error("WatchCountryVisits failed", logging.UserID, userID, logging.Error, err)
+			if !started {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to watch visits"})
+			}
+			return
+		case <-s.visitStreams.closing:
+			return
+		}
+	}
+}
+
+// writeSSEvent writes ev with its data in the request's negotiated time format and flushes it.
+func writeSSEvent(c *gin.Context, ev sseEvent) error {
+	data, err := json.Marshal(jsontime.Apply(ev.data, timeFormatFromContext(c.Request.Context())))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", ev.name, data); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
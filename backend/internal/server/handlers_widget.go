@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+	"github.com/matti777/my-countries/backend/internal/widget"
+)
+
+// widgetCSP allows the widget page to be framed by any site while it loads nothing itself.
+const widgetCSP = "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors *"
+
+// GetShareWidgetHandler handles GET /share/widget/:shareToken.
+// Unauthenticated; returns the embeddable widget of the share: a self-contained HTML page with
+// the visited countries on a world map, their count and the owner's name, which any site may
+// frame. Responds JSON (readable cross-origin) when ?format=json or the Accept header prefers
+// it. Summary share links show only the count.
+func (s *Server) GetShareWidgetHandler(ctx context.Context, c *gin.Context) {
+	ctx, span := tracing.New(ctx, "GetShareWidgetHandler")
+	defer span.End()
+
+	shareToken := c.Param("shareToken")
+	if shareToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "share token required"})
+		return
+	}
+	format := c.Query("format")
+	if format == "" {
+		if c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+			format = "json"
+		} else {
+			format = "html"
+		}
+	}
+	if format != "html" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be html or json"})
+		return
+	}
+
+	log := logging.FromContext(ctx)
+	user, scope, err := s.resolveShareUser(ctx, shareToken, true)
+	if err != nil {
+		log.Error("resolveShareUser failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	}
+	if user == nil || user.PendingDeletion() {
+		shareNotFound(c)
+		return
+	}
+	if blocked, err := s.isBlockedViewer(ctx, user.ID); err != nil {
+		log.Error("IsBlocked failed", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch share"})
+		return
+	} else if blocked {
+		shareNotFound(c)
+		return
+	}
+	s.recordShareView(ctx, user, shareToken)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Vary", "Accept")
+	c.Header("Access-Control-Allow-Origin", "*")
+	etag := computeETag("widget", format, user.ID, user.VisitsRevision, user.Name, scope)
+	if writeNotModifiedIfMatch(c, etag) {
+		return
+	}
+	visits, err := s.db.GetPublicCountryVisitsByUser(ctx, user.ID)
+	if err != nil {
+		log.Error("GetPublicCountryVisitsByUser failed for widget", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch visits"})
+		return
+	}
+	profile := models.ShareProfileResponse{Visits: visits}
+	applyShareScope(&profile, scope)
+	resp := models.ShareWidgetResponse{
+		UserName:       user.Name,
+		CountriesCount: profile.CountriesCount,
+		CountryCodes:   profile.CountryCodes,
+		ShareURL:       s.baseURL(c) + "/share/" + url.PathEscape(shareToken),
+	}
+	if resp.CountryCodes == nil {
+		resp.CountryCodes = []string{}
+	}
+	if format == "json" {
+		writeJSON(c, http.StatusOK, resp)
+		return
+	}
+
+	var page bytes.Buffer
+	err = widget.Render(&page, widget.Data{
+		UserName:       resp.UserName,
+		CountriesCount: resp.CountriesCount,
+		CountryCodes:   resp.CountryCodes,
+		ShareURL:       resp.ShareURL,
+	})
+	if err != nil {
+		log.Error("Failed to render widget", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render widget"})
+		return
+	}
+	c.Header("Content-Security-Policy", widgetCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"github.com/matti777/my-countries/backend/internal/data"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// attachSuccessorCodes sets SuccessorCodes on each visit to a historic country so clients can
+// draw it on today's map.
+func attachSuccessorCodes(visits []models.CountryVisit) {
+	for i := range visits {
+		visits[i].SuccessorCodes = data.SuccessorCodes(visits[i].CountryCode)
+	}
+}
+
+// attachSuccessorCodesTo is attachSuccessorCodes for a single visit.
+func attachSuccessorCodesTo(visit *models.CountryVisit) {
+	visit.SuccessorCodes = data.SuccessorCodes(visit.CountryCode)
+}
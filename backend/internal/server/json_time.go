@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+	"github.com/matti777/my-countries/backend/internal/logging"
+)
+
+// jsonTimeMiddleware negotiates the response time format from the Accept header's time
+// parameter (e.g. "Accept: application/json; time=unix"), falling back to the server default.
+// Unknown values yield 406.
+func (s *Server) jsonTimeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := s.jsonTimeFormat
+		if raw, ok := acceptTimeParam(c.GetHeader("Accept")); ok {
+			f, err := jsontime.ParseFormat(raw)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{"error": err.Error()})
+				return
+			}
+			format = f
+		}
+		ctx := context.WithValue(c.Request.Context(), ctxkeys.JSONTimeFormatKey, format)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// timeFormatFromContext returns the response time format negotiated by jsonTimeMiddleware
+// (jsontime.RFC3339 outside it).
+func timeFormatFromContext(ctx context.Context) jsontime.Format {
+	if f, ok := ctx.Value(ctxkeys.JSONTimeFormatKey).(jsontime.Format); ok {
+		return f
+	}
+	return jsontime.RFC3339
+}
+
+// acceptTimeParam returns the time parameter of the first media range in accept that has one.
+func acceptTimeParam(accept string) (string, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if v, ok := params["time"]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// writeJSON writes obj as the JSON response body with times in the request's negotiated format
+// (see jsontime.Apply). Use it instead of c.JSON for every success response.
+func writeJSON(c *gin.Context, status int, obj any) {
+	format := timeFormatFromContext(c.Request.Context())
+	body, err := json.Marshal(jsontime.Apply(obj, format))
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("Failed to encode response", logging.Error, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to encode response"})
+		return
+	}
+	c.Data(status, "application/json; charset=utf-8", body)
+}
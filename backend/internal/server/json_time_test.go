@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/matti777/my-countries/backend/internal/jsontime"
+)
+
+func TestJSONTimeFormat(t *testing.T) {
+	s, _ := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+	postVisit(t, s, "u1", "FI")
+
+	tests := []struct {
+		accept string
+		want   any
+	}{
+		{"", "2020-01-01T00:00:00Z"},
+		{"application/json; time=rfc3339", "2020-01-01T00:00:00Z"},
+		{"application/json; time=unix", float64(1577836800)},
+		{"text/html, application/json; time=UNIX", float64(1577836800)},
+	}
+	for _, tt := range tests {
+		w := doAs(t, s, "u1", http.MethodGet, "/visits", "", "Accept", tt.accept)
+		requireStatus(t, w, http.StatusOK)
+		var body struct {
+			Visits []map[string]any `json:"visits"`
+		}
+		decode(t, w, &body)
+		if len(body.Visits) != 1 || body.Visits[0]["visitedTime"] != tt.want {
+			t.Errorf("Accept %q: visits = %v, want visitedTime %v", tt.accept, body.Visits, tt.want)
+		}
+	}
+
+	w := doAs(t, s, "u1", http.MethodGet, "/visits", "", "Accept", "application/json; time=iso")
+	requireStatus(t, w, http.StatusNotAcceptable)
+}
+
+func TestJSONTimeFormatServerDefault(t *testing.T) {
+	s, _ := newTestServer(t, WithJSONTimeFormat(jsontime.Unix))
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+	visit := map[string]any{}
+	w := doAs(t, s, "u1", http.MethodPost, "/visits",
+		`{"countryCode":"FI","visitedTime":"2020-01-01T00:00:00Z"}`)
+	requireStatus(t, w, http.StatusCreated)
+	decode(t, w, &visit)
+	if visit["visitedTime"] != float64(1577836800) {
+		t.Errorf("visitedTime = %v, want Unix seconds", visit["visitedTime"])
+	}
+}
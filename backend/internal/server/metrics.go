@@ -0,0 +1,44 @@
+package server
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/metrics"
+)
+
+// unmatchedRoute is the route label of requests matching no route (e.g. static files and
+// scanners), so arbitrary paths do not each create a series.
+const unmatchedRoute = "unmatched"
+
+// WithMetrics records request metrics and the server's gauges (open visit streams, JWKS
+// fetches) in m. main serves m on the separate METRICS_PORT listener, never on the API router.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *Server) {
+		s.metrics = m
+	}
+}
+
+// registerMetrics registers the metrics read from the server on scrape. Called by NewServer
+// once the options are applied.
+func (s *Server) registerMetrics() {
+	s.metrics.GaugeFunc("visit_streams_open", "Open GET /visits/stream connections.",
+		func() float64 { return float64(s.visitStreams.count()) })
+	s.metrics.CounterFunc("auth_jwks_fetches_total", "Fetches of the ID token signing keys.",
+		func() float64 { return float64(s.auth.JWKSFetches()) })
+}
+
+// metricsMiddleware records every request by route template, method and status after it
+// completes. A visit stream is recorded when it ends.
+func (s *Server) metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		s.metrics.ObserveRequest(route, c.Request.Method, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/changelog"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/openapi"
+)
+
+// OpenAPI tags of the documented endpoints.
+const (
+	apiTagAccount = "account"
+	apiTagVisits  = "visits"
+	apiTagFriends = "friends"
+	apiTagShare   = "share"
+)
+
+// apiEndpoints are the routes documented at /openapi.json: those integrators use for visits,
+// friends, shares and login. Each must be registered (see TestOpenAPIRoutes); request and
+// response schemas come from the types the handlers bind and write.
+func apiEndpoints() []openapi.Endpoint {
+	ifNoneMatchParam := openapi.Parameter{
+		Name: "If-None-Match", In: "header", Schema: &openapi.Schema{Type: "string"},
+		Description: "ETag of an earlier response; 304 when unchanged.",
+	}
+	notModified := openapi.Reply{Status: http.StatusNotModified}
+	unauthorized := openapi.Reply{Status: http.StatusUnauthorized}
+	notFound := openapi.Reply{Status: http.StatusNotFound}
+	badRequest := openapi.Reply{Status: http.StatusBadRequest}
+	noContent := openapi.Reply{Status: http.StatusNoContent}
+	shareNotFound := openapi.Reply{
+		Status: http.StatusNotFound, Description: "Unknown or disabled share token.",
+	}
+	visitLimit := openapi.Reply{
+		Status:      http.StatusUnprocessableEntity,
+		Description: "The user has the most visits allowed (code visit_limit_reached).",
+	}
+	friendLimit := openapi.Reply{
+		Status:      http.StatusUnprocessableEntity,
+		Description: "Either user has the most friends allowed (code friend_limit_reached).",
+	}
+	ifUnmodifiedSince := openapi.Parameter{
+		Name: "If-Unmodified-Since", In: "header", Schema: &openapi.Schema{Type: "string"},
+		Description: "Last-Modified of the visit as read; 412 when it changed since.",
+	}
+	visitUpdateReplies := []openapi.Reply{
+		{Status: http.StatusOK, Body: models.CountryVisit{}},
+		badRequest, unauthorized, notFound,
+		{Status: http.StatusPreconditionFailed, Description: "The visit changed since."},
+	}
+
+	return []openapi.Endpoint{
+		{
+			Method: http.MethodPost, Path: "/login", ID: "PostLogin", Tag: apiTagAccount,
+			Summary: "Create the user on first sign-in",
+			Replies: []openapi.Reply{{Status: http.StatusOK, Body: struct{}{}}, unauthorized},
+		},
+		{
+			Method: http.MethodGet, Path: "/visits", ID: "GetVisits", Tag: apiTagVisits,
+			Summary: "List the user's visits",
+			Params:  []openapi.Parameter{ifNoneMatchParam},
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.CountryVisitResponse{}},
+				notModified, unauthorized,
+				{Status: http.StatusNotFound, Description: "User not found; call POST /login."},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/visits", ID: "PostVisits", Tag: apiTagVisits,
+			Summary: "Add a visit (countryCode and visitedTime are required)",
+			Body:    postVisitBody{},
+			Replies: []openapi.Reply{
+				{Status: http.StatusCreated, Body: models.CountryVisit{}},
+				{
+					Status: http.StatusOK, Body: models.CountryVisit{},
+					Description: "The existing visit, when dedupe matched one.",
+				},
+				badRequest, unauthorized, visitLimit,
+			},
+		},
+		{
+			Method: http.MethodPut, Path: "/visits/:id", ID: "PutVisit", Tag: apiTagVisits,
+			Summary: "Update a visit; omitted fields are kept",
+			Params:  []openapi.Parameter{ifUnmodifiedSince},
+			Body:    visitUpdateBody{},
+			Replies: visitUpdateReplies,
+		},
+		{
+			Method: http.MethodPatch, Path: "/visits/:id", ID: "PatchVisit", Tag: apiTagVisits,
+			Summary: "Update a visit unless it changed since it was read",
+			Params: []openapi.Parameter{
+				{
+					Name: ifUnmodifiedSince.Name, In: "header", Required: true,
+					Schema: ifUnmodifiedSince.Schema, Description: ifUnmodifiedSince.Description,
+				},
+			},
+			Body: visitUpdateBody{},
+			Replies: append(visitUpdateReplies, openapi.Reply{
+				Status: http.StatusPreconditionRequired, Description: "No If-Unmodified-Since.",
+			}),
+		},
+		{
+			Method: http.MethodDelete, Path: "/visits/:id", ID: "DeleteVisit", Tag: apiTagVisits,
+			Summary: "Delete a visit and its proofs",
+			Replies: []openapi.Reply{noContent, unauthorized, notFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/friends", ID: "GetFriends", Tag: apiTagFriends,
+			Summary: "List a page of the user's friends",
+			Params: []openapi.Parameter{
+				{
+					Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer"},
+					Description: fmt.Sprintf("Page size, %d by default and at most %d.",
+						models.DefaultFriendsLimit, models.MaxFriendsLimit),
+				},
+				{
+					Name: "cursor", In: "query", Schema: &openapi.Schema{Type: "string"},
+					Description: "nextCursor of the previous page.",
+				},
+				{
+					Name: "include", In: "query",
+					Schema:      &openapi.Schema{Type: "string", Enum: []string{"summary"}},
+					Description: "summary adds each friend's visit counts.",
+				},
+			},
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.FriendsResponse{}}, badRequest, unauthorized,
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/friends/followers", ID: "GetFollowers",
+			Tag: apiTagFriends, Summary: "List the users who added the user as a friend",
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.FollowersResponse{}}, unauthorized,
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/friends/requests", ID: "GetFriendRequests",
+			Tag: apiTagFriends, Summary: "List pending incoming and outgoing friend requests",
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.FriendRequestsResponse{}}, unauthorized,
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/friends/requests", ID: "PostFriendRequest",
+			Tag: apiTagFriends, Summary: "Send a friend request to the owner of a share token",
+			Body: friendRequestBody{},
+			Replies: []openapi.Reply{
+				{Status: http.StatusCreated, Body: models.FriendRequest{}},
+				{
+					Status: http.StatusOK, Body: models.Friend{},
+					Description: "Accepted at once: the user had invited the sender.",
+				},
+				badRequest, unauthorized, notFound,
+				{Status: http.StatusConflict, Description: "Already friends or requested."},
+				friendLimit,
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/friends/requests/:id/accept",
+			ID: "PostAcceptFriendRequest", Tag: apiTagFriends,
+			Summary: "Accept a received friend request",
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.Friend{}}, unauthorized, notFound, friendLimit,
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/friends/requests/:id/decline",
+			ID: "PostDeclineFriendRequest", Tag: apiTagFriends,
+			Summary: "Decline a received or withdraw a sent friend request",
+			Replies: []openapi.Reply{noContent, unauthorized, notFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/friends/:shareToken/visits", ID: "GetFriendVisits",
+			Tag: apiTagFriends, Summary: "List a friend's shared visits",
+			Params: []openapi.Parameter{ifNoneMatchParam},
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.FriendVisitsResponse{}},
+				notModified, unauthorized, notFound,
+			},
+		},
+		{
+			Method: http.MethodPatch, Path: "/friends/:shareToken", ID: "PatchFriend",
+			Tag: apiTagFriends, Summary: "Set or clear a friend's nickname",
+			Body: patchFriendBody{},
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.Friend{}}, badRequest, unauthorized, notFound,
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/friends/:shareToken", ID: "DeleteFriend",
+			Tag: apiTagFriends, Summary: "Remove a friend",
+			Replies: []openapi.Reply{noContent, unauthorized, notFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/share/profile/:shareToken", ID: "GetShareProfile",
+			Tag: apiTagShare, Public: true, Summary: "Read a shared profile and its visits",
+			Params: []openapi.Parameter{ifNoneMatchParam},
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.ShareProfileResponse{}},
+				notModified, shareNotFound,
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/share/:shareToken/passport", ID: "GetSharePassport",
+			Tag: apiTagShare, Public: true,
+			Summary: "Read a share's visited countries as flags by continent",
+			Params: []openapi.Parameter{
+				{
+					Name: "format", In: "query",
+					Schema:      &openapi.Schema{Type: "string", Enum: []string{"json", "text"}},
+					Description: "Response format; negotiated from Accept when omitted.",
+				},
+				ifNoneMatchParam,
+			},
+			Replies: []openapi.Reply{
+				{Status: http.StatusOK, Body: models.PassportResponse{}, Text: true},
+				badRequest, notModified, shareNotFound,
+			},
+		},
+	}
+}
+
+// openAPIDocument returns the encoded OpenAPI document of apiEndpoints. That each documented
+// endpoint is registered, and each registered route documented or deliberately left out, is
+// checked by TestOpenAPIRoutes.
+func openAPIDocument() ([]byte, error) {
+	doc, err := openapi.Build(openapi.Info{
+		Title:       "My Travel: Visited Countries API",
+		Version:     changelog.CurrentVersion(),
+		Description: "Visits, friends, shares and login. Changes are listed at /api/changelog.",
+	}, apiEndpoints())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI document: %w", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+	return data, nil
+}
+
+// GetOpenAPIHandler handles GET /openapi.json: the OpenAPI 3 document of the API.
+func (s *Server) GetOpenAPIHandler(ctx context.Context, c *gin.Context) {
+	if s.openAPIErr != nil {
+		logging.FromContext(ctx).Error("OpenAPI document unavailable", logging.Error, s.openAPIErr)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OpenAPI document unavailable"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", s.openAPI)
+}
+
+// GetDocsHandler handles GET /docs: Swagger UI showing GET /openapi.json.
+func (s *Server) GetDocsHandler(ctx context.Context, c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Header("Content-Security-Policy", openapi.DocsCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage)
+}
+
+// GetDocsAssetHandler handles GET /docs/:file: the embedded Swagger UI files DocsPage loads.
+// Returns 404 for other names.
+func (s *Server) GetDocsAssetHandler(ctx context.Context, c *gin.Context) {
+	data, contentType, ok := openapi.DocsAsset(c.Param("file"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// undocumentedAreas are the first path segments of routes outside the scope of /openapi.json
+// (visits, friends, shares and login): account management, organizations, reference data and
+// operational routes.
+var undocumentedAreas = []string{
+	"account", "admin", "api", "blocked", "countries", "docs", "feed", "healthz", "img",
+	"insights", "me", "openapi.json", "orgs", "readyz", "session", "settings", "static",
+	"support",
+}
+
+// undocumentedRoutes are the routes of the documented areas left out of /openapi.json: web app
+// features, binary or streaming responses and pages for browsers.
+var undocumentedRoutes = map[string]bool{
+	"GET /visits/search":                            true,
+	"GET /visits/clusters":                          true,
+	"GET /visits/summary":                           true,
+	"GET /visits/stream":                            true,
+	"POST /visits/import":                           true,
+	"GET /visits/import/jobs/:id":                   true,
+	"GET /visits/:id/history":                       true,
+	"POST /visits/:id/proofs":                       true,
+	"GET /visits/:id/proofs/:proofId":               true,
+	"DELETE /visits/:id/proofs/:proofId":            true,
+	"DELETE /visits/:id/overlaps/:overlapId":        true,
+	"POST /friends/invite":                          true,
+	"GET /friends/invites":                          true,
+	"DELETE /friends/invites/:id":                   true,
+	"GET /friends/:shareToken/compare":              true,
+	"GET /friends/:shareToken/mutual":               true,
+	"POST /friends/:shareToken/visits/:id/overlaps": true,
+	"GET /share/og/:file":                           true,
+	"GET /share/org/:shareToken":                    true,
+	"GET /share/widget/:shareToken":                 true,
+	"GET /u/:handle":                                true,
+}
+
+// TestOpenAPIRoutes checks that every documented endpoint is registered and that every
+// registered route is documented or listed in undocumentedAreas or undocumentedRoutes.
+func TestOpenAPIRoutes(t *testing.T) {
+	s, _ := newTestServer(t)
+	registered := make(map[string]bool)
+	for _, r := range s.Router.Routes() {
+		registered[r.Method+" "+r.Path] = true
+	}
+	documented := make(map[string]bool)
+	for _, e := range apiEndpoints() {
+		route := e.Method + " " + e.Path
+		documented[route] = true
+		if !registered[route] {
+			t.Errorf("documented route %s is not registered", route)
+		}
+		if undocumentedRoutes[route] {
+			t.Errorf("documented route %s is listed in undocumentedRoutes", route)
+		}
+	}
+	for route := range registered {
+		_, path, _ := strings.Cut(route, " ")
+		area, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		if documented[route] || undocumentedRoutes[route] ||
+			slices.Contains(undocumentedAreas, area) {
+			continue
+		}
+		t.Errorf("route %s is neither documented in apiEndpoints nor listed in "+
+			"undocumentedRoutes", route)
+	}
+	for route := range undocumentedRoutes {
+		if !registered[route] {
+			t.Errorf("undocumentedRoutes lists %s, which is not registered", route)
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	s, _ := newTestServer(t)
+	w := do(t, s, http.MethodGet, "/openapi.json", "")
+	requireStatus(t, w, http.StatusOK)
+	var doc struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	decode(t, w, &doc)
+	if doc.OpenAPI == "" || len(doc.Paths) == 0 {
+		t.Fatal("document has no version or paths")
+	}
+
+	// Every $ref names a component
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				name, _ := strings.CutPrefix(ref, "#/components/schemas/")
+				if doc.Components.Schemas[name] == nil {
+					t.Errorf("unresolved $ref %q", ref)
+				}
+			}
+			for _, e := range v {
+				walk(e)
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	var raw any
+	if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil {
+		t.Fatalf("decoding document: %v", err)
+	}
+	walk(raw)
+
+	// Every {param} of a path is declared, and every operation has responses
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			if responses, _ := op["responses"].(map[string]any); len(responses) == 0 {
+				t.Errorf("%s %s has no responses", method, path)
+			}
+			params, _ := op["parameters"].([]any)
+			for _, seg := range strings.Split(path, "/") {
+				name, ok := strings.CutPrefix(seg, "{")
+				if !ok {
+					continue
+				}
+				name = strings.TrimSuffix(name, "}")
+				found := false
+				for _, p := range params {
+					p, _ := p.(map[string]any)
+					found = found || p["name"] == name && p["in"] == "path"
+				}
+				if !found {
+					t.Errorf("%s %s does not declare path parameter %s", method, path, name)
+				}
+			}
+		}
+	}
+}
+
+func TestDocs(t *testing.T) {
+	s, _ := newTestServer(t)
+	w := do(t, s, http.MethodGet, "/docs", "")
+	requireStatus(t, w, http.StatusOK)
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp,
+		"script-src 'self'") {
+		t.Errorf("Content-Security-Policy = %q", csp)
+	}
+	w = do(t, s, http.MethodGet, "/docs/swagger-ui-bundle.js", "")
+	requireStatus(t, w, http.StatusOK)
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/javascript") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	requireStatus(t, do(t, s, http.MethodGet, "/docs/swagger-ui.css", ""), http.StatusOK)
+	requireStatus(t, do(t, s, http.MethodGet, "/docs/index.html", ""), http.StatusNotFound)
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+const (
+	// orgVisitsTTL is how long an organization's member visits are served from memory. Org
+	// pages read every member's visits, so visit edits show up there after at most this long.
+	orgVisitsTTL = 5 * time.Minute
+
+	// maxOrgVisitsEntries bounds the number of organizations cached per instance.
+	maxOrgVisitsEntries = 500
+)
+
+// orgVisits is a snapshot of an organization's members and their non-private visits. Shared by
+// concurrent requests; must not be modified.
+type orgVisits struct {
+	members  []models.OrganizationMember
+	visits   map[string][]models.CountryVisit // by member user ID
+	loadedAt time.Time
+}
+
+// orgVisitsCache keeps orgVisits in memory per instance (lost on restart). Membership changes
+// on this instance invalidate their organization; other instances catch up within the TTL.
+type orgVisitsCache struct {
+	mu      sync.Mutex
+	entries map[string]*orgVisits
+	hits    int64
+	misses  int64
+}
+
+func newOrgVisitsCache() *orgVisitsCache {
+	return &orgVisitsCache{entries: make(map[string]*orgVisits)}
+}
+
+func (oc *orgVisitsCache) get(orgID string, now time.Time) (*orgVisits, bool) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	e, ok := oc.entries[orgID]
+	if !ok || now.Sub(e.loadedAt) >= orgVisitsTTL {
+		oc.misses++
+		return nil, false
+	}
+	oc.hits++
+	return e, true
+}
+
+// stats returns the hit and miss counts since startup and the number of cached organizations.
+func (oc *orgVisitsCache) stats() (hits, misses int64, entries int) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	return oc.hits, oc.misses, len(oc.entries)
+}
+
+func (oc *orgVisitsCache) put(orgID string, e *orgVisits) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if len(oc.entries) >= maxOrgVisitsEntries {
+		for id, old := range oc.entries {
+			if e.loadedAt.Sub(old.loadedAt) >= orgVisitsTTL {
+				delete(oc.entries, id)
+			}
+		}
+		if len(oc.entries) >= maxOrgVisitsEntries {
+			oc.entries = make(map[string]*orgVisits)
+		}
+	}
+	oc.entries[orgID] = e
+}
+
+func (oc *orgVisitsCache) invalidate(orgID string) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	delete(oc.entries, orgID)
+}
+
+// loadOrgVisits returns the members of orgID and their non-private visits, from the cache when
+// fresh.
+func (s *Server) loadOrgVisits(ctx context.Context, orgID string) (*orgVisits, error) {
+	now := time.Now()
+	if e, ok := s.orgVisits.get(orgID, now); ok {
+		return e, nil
+	}
+	members, err := s.db.GetOrganizationMembers(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	e := &orgVisits{
+		members:  members,
+		visits:   make(map[string][]models.CountryVisit, len(members)),
+		loadedAt: now,
+	}
+	for _, m := range members {
+		visits, err := s.db.GetPublicCountryVisitsByUser(ctx, m.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch visits of member %s: %w", m.UserID, err)
+		}
+		e.visits[m.UserID] = visits
+	}
+	s.orgVisits.put(orgID, e)
+	return e, nil
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/quota"
+)
+
+// WithRateLimits limits authenticated requests to userPerMinute per minute per user and
+// requests to the public routes without a quota of their own (images, changelog, static
+// manifest) to ipPerMinute per minute per client IP, so a misbehaving client cannot use up the
+// Firestore quota. A limit that is not positive is disabled.
+func WithRateLimits(userPerMinute, ipPerMinute float64) Option {
+	return func(s *Server) {
+		if userPerMinute > 0 {
+			s.userQuota = quota.New(userPerMinute)
+		}
+		if ipPerMinute > 0 {
+			s.ipQuota = quota.New(ipPerMinute)
+		}
+	}
+}
+
+// userRateLimitMiddleware takes a request of the current user from the per-user quota, or
+// answers 429 with Retry-After. Runs after the auth middleware; requests without a user pass.
+func (s *Server) userRateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := ctxkeys.CurrentUser(c.Request.Context())
+		if s.userQuota == nil || !ok {
+			c.Next()
+			return
+		}
+		if allowed, retryAfter := s.userQuota.Allow(user.ID); !allowed {
+			logging.FromContext(c.Request.Context()).Warn("User rate limit exceeded",
+				logging.UserID, user.ID)
+			abortRateLimited(c, retryAfter)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ipRateLimitMiddleware takes a request of the client IP from the per-IP quota, or answers 429
+// with Retry-After. The IP is the peer's unless it is a trusted proxy (see WithTrustedProxies),
+// so a client cannot reset its quota with X-Forwarded-For.
+func (s *Server) ipRateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.ipQuota == nil {
+			c.Next()
+			return
+		}
+		ip := c.ClientIP()
+		if allowed, retryAfter := s.ipQuota.Allow(ip); !allowed {
+			logging.FromContext(c.Request.Context()).Warn("IP rate limit exceeded",
+				"client_ip", ip)
+			abortRateLimited(c, retryAfter)
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortRateLimited answers 429 with Retry-After in whole seconds.
+func abortRateLimited(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests,
+		gin.H{"error": "too many requests; retry later"})
+}
+
+// rateLimitedCounts returns how many requests each configured quota refused since startup.
+func (s *Server) rateLimitedCounts() map[string]int64 {
+	counts := map[string]int64{}
+	for name, l := range map[string]*quota.Limiter{
+		"user":      s.userQuota,
+		"ip":        s.ipQuota,
+		"countries": s.countriesQuota,
+		"share":     s.shareQuota,
+	} {
+		if l != nil {
+			counts[name] = l.Limited()
+		}
+	}
+	return counts
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIPRateLimitIgnoresForgedForwardedFor(t *testing.T) {
+	s, _ := newTestServer(t, WithRateLimits(0, 1))
+
+	requireStatus(t, do(t, s, http.MethodGet, "/api/changelog", "",
+		"X-Forwarded-For", "203.0.113.1"), http.StatusOK)
+	w := do(t, s, http.MethodGet, "/api/changelog", "", "X-Forwarded-For", "203.0.113.2")
+	requireStatus(t, w, http.StatusTooManyRequests)
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+}
+
+func TestUserRateLimitPerUser(t *testing.T) {
+	s, _ := newTestServer(t, WithRateLimits(1, 0))
+
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+	requireStatus(t, doAs(t, s, "u1", http.MethodGet, "/visits", ""),
+		http.StatusTooManyRequests)
+	requireStatus(t, doAs(t, s, "u2", http.MethodPost, "/login", ""), http.StatusOK)
+}
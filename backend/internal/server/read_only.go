@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+)
+
+// maintenanceCode is the "code" of the 503 response to a mutating request in read-only mode, so
+// clients can tell a maintenance window from an outage.
+const maintenanceCode = "maintenance"
+
+// WithReadOnly puts the API into read-only mode for maintenance windows (e.g. migrations):
+// GET, HEAD and OPTIONS requests are served as usual, other requests get 503 with
+// maintenanceCode. Admin routes stay writable so operators can run backfill jobs and backups.
+func WithReadOnly(enabled bool) Option {
+	return func(s *Server) {
+		s.readOnly = enabled
+	}
+}
+
+// readOnlyMiddleware refuses mutating requests outside /admin. Installed by NewServer only in
+// read-only mode.
+func (s *Server) readOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if p := c.Request.URL.Path; p == "/admin" || strings.HasPrefix(p, "/admin/") {
+			c.Next()
+			return
+		}
+		logging.FromContext(c.Request.Context()).Info("Refused request in read-only mode")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error": "the service is in read-only maintenance mode; changes cannot be saved " +
+				"right now",
+			"code": maintenanceCode,
+		})
+	}
+}
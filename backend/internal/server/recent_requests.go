@@ -0,0 +1,72 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// recentRequestsPerUser is how many requests are remembered per user for support bundles.
+const recentRequestsPerUser = 20
+
+// recentRequestLog keeps the last few requests of each authenticated user in memory
+// (per instance; lost on restart). Only non-sensitive request metadata is stored.
+type recentRequestLog struct {
+	mu     sync.Mutex
+	byUser map[string][]models.RecentRequest
+}
+
+func newRecentRequestLog() *recentRequestLog {
+	return &recentRequestLog{byUser: make(map[string][]models.RecentRequest)}
+}
+
+func (l *recentRequestLog) add(userID string, r models.RecentRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	list := append(l.byUser[userID], r)
+	if len(list) > recentRequestsPerUser {
+		list = list[len(list)-recentRequestsPerUser:]
+	}
+	l.byUser[userID] = list
+}
+
+// list returns the user's recent requests, most recent first.
+func (l *recentRequestLog) list(userID string) []models.RecentRequest {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	list := l.byUser[userID]
+	out := make([]models.RecentRequest, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		out = append(out, list[i])
+	}
+	return out
+}
+
+// recentRequestsMiddleware records each authenticated request after it completes.
+// Must run after authMiddleware so the current user is in context.
+func (s *Server) recentRequestsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		ctx := c.Request.Context()
+		user, _ := ctxkeys.CurrentUser(ctx)
+		if user == nil {
+			return
+		}
+		r := models.RecentRequest{
+			Time:      time.Now().UTC(),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Status:    c.Writer.Status(),
+			RequestID: requestIDFromContext(ctx),
+		}
+		if tc, _ := ctx.Value(ctxkeys.TraceContextKey).(*ctxkeys.TraceContext); tc != nil {
+			r.TraceID = tc.TraceID
+		}
+		s.recentRequests.add(user.ID, r)
+	}
+}
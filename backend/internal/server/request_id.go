@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+)
+
+// requestIDHeader carries the ID of a request: accepted from the client (or a proxy in front)
+// when valid, generated otherwise, and echoed on every response.
+const requestIDHeader = "X-Request-ID"
+
+// requestIDPattern is what a client-supplied request ID must match; others are replaced, so
+// IDs are safe in headers, logs and JSON.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDMiddleware gives each request an ID, puts it in the context for contextMiddleware
+// to label logs with, sets the X-Request-ID response header and adds "requestId" to JSON error
+// bodies. Tracing may be sampled out; the ID lets a user-reported error be found in the logs
+// regardless.
+func (s *Server) requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+		c.Header(requestIDHeader, id)
+		ctx := context.WithValue(c.Request.Context(), ctxkeys.RequestIDKey, id)
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &requestIDWriter{ResponseWriter: c.Writer, id: id}
+		c.Next()
+	}
+}
+
+// requestIDFromContext returns the request's ID, or "" outside requestIDMiddleware.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxkeys.RequestIDKey).(string)
+	return id
+}
+
+// requestIDWriter inserts "requestId" as the first member of a JSON object written as the body
+// of an error response (status 400 or above), e.g. {"requestId": "...", "error": "..."}.
+// Handlers write error bodies in a single Write (c.JSON), so only the first one is inspected.
+type requestIDWriter struct {
+	gin.ResponseWriter
+	id      string
+	started bool
+}
+
+func (w *requestIDWriter) Write(b []byte) (int, error) {
+	if w.started || w.Status() < http.StatusBadRequest || !isJSONContent(w.Header()) {
+		w.started = true
+		return w.ResponseWriter.Write(b)
+	}
+	w.started = true
+	body := bytes.TrimLeft(b, " \t\r\n")
+	if len(body) == 0 || body[0] != '{' {
+		return w.ResponseWriter.Write(b)
+	}
+	id, err := json.Marshal(w.id)
+	if err != nil {
+		return w.ResponseWriter.Write(b)
+	}
+	var out bytes.Buffer
+	out.WriteString(`{"requestId":`)
+	out.Write(id)
+	if rest := bytes.TrimLeft(body[1:], " \t\r\n"); len(rest) > 0 && rest[0] != '}' {
+		out.WriteByte(',')
+	}
+	out.Write(body[1:])
+	if _, err := w.ResponseWriter.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *requestIDWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// isJSONContent reports whether the response's Content-Type is application/json.
+func isJSONContent(h http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && mediaType == gin.MIMEJSON
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// requestStatsWindow is the period GET /admin/status reports request counts for.
+	requestStatsWindow = 5 * time.Minute
+
+	// requestStatsBucket is the resolution of the window.
+	requestStatsBucket = 10 * time.Second
+
+	requestStatsBuckets = int(requestStatsWindow / requestStatsBucket)
+)
+
+// requestStats counts responses by class over the last requestStatsWindow on this instance.
+type requestStats struct {
+	mu      sync.Mutex
+	buckets [requestStatsBuckets]requestBucket
+}
+
+type requestBucket struct {
+	start        int64 // Unix seconds of the bucket start
+	total        int
+	clientErrors int
+	serverErrors int
+}
+
+func (r *requestStats) record(now time.Time, status int) {
+	start := now.Truncate(requestStatsBucket).Unix()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := &r.buckets[(start/int64(requestStatsBucket/time.Second))%int64(requestStatsBuckets)]
+	if b.start != start {
+		*b = requestBucket{start: start}
+	}
+	b.total++
+	switch {
+	case status >= 500:
+		b.serverErrors++
+	case status >= 400:
+		b.clientErrors++
+	}
+}
+
+// snapshot sums the buckets within requestStatsWindow of now.
+func (r *requestStats) snapshot(now time.Time) (total, clientErrors, serverErrors int) {
+	oldest := now.Add(-requestStatsWindow).Unix()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, b := range r.buckets {
+		if b.start > oldest {
+			total += b.total
+			clientErrors += b.clientErrors
+			serverErrors += b.serverErrors
+		}
+	}
+	return total, clientErrors, serverErrors
+}
+
+// requestStatsMiddleware records the status of every request after it completes.
+func (s *Server) requestStatsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		s.requestStats.record(time.Now(), c.Writer.Status())
+	}
+}
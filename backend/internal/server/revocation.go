@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+
+	"github.com/matti777/my-countries/backend/internal/auth"
+)
+
+// WithRevocationCheck enables strict auth mode: every authenticated request reads the user's
+// TokensRevokedAt and refuses tokens and session cookies from earlier sign-ins, so POST
+// /me/revoke-sessions invalidates stolen credentials before they expire. Costs a User document
+// read per request.
+func WithRevocationCheck(enabled bool) Option {
+	return func(s *Server) {
+		s.checkRevoked = enabled
+	}
+}
+
+// tokenRevoked reports whether claims come from a sign-in before the user's TokensRevokedAt.
+// Always false without WithRevocationCheck; a user without a document is not revoked.
+func (s *Server) tokenRevoked(ctx context.Context, claims *auth.Claims) (bool, error) {
+	if !s.checkRevoked {
+		return false, nil
+	}
+	u, err := s.db.GetUserByID(ctx, claims.Sub)
+	if err != nil {
+		return false, err
+	}
+	return u != nil && u.TokensRevokedAt != nil && claims.AuthTime.Before(*u.TokensRevokedAt),
+		nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/features"
+	"github.com/matti777/my-countries/backend/internal/logging"
+)
+
+// handlerFunc is the signature of Server handlers: request context first, then the gin context.
+type handlerFunc func(ctx context.Context, c *gin.Context)
+
+// routeConfig collects the RouteOptions of one route.
+type routeConfig struct {
+	requireUser bool
+	denyAPIKeys bool
+	feature     features.Flag
+}
+
+// RouteOption configures a route registered with routeGroup.Handle.
+type RouteOption func(*routeConfig)
+
+// RequireUser rejects requests without an authenticated user with the standard 401 before the
+// handler runs, so the handler may use ctxkeys.MustCurrentUser.
+func RequireUser(cfg *routeConfig) {
+	cfg.requireUser = true
+}
+
+// DenyAPIKeys rejects requests authenticated with an API key with 403, for routes that sign
+// in, change credentials or delete or restore the account, so a leaked key cannot take over or
+// remove the account it belongs to.
+func DenyAPIKeys(cfg *routeConfig) {
+	cfg.denyAPIKeys = true
+}
+
+// RequireFeature hides the route behind the preview flag: requests that have not enabled it in
+// X-Feature-Preview (see featurePreviewMiddleware) get 404, as if the route did not exist.
+func RequireFeature(flag features.Flag) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.feature = flag
+	}
+}
+
+// routeGroup registers Server handlers on a gin router or group.
+type routeGroup struct {
+	routes gin.IRoutes
+}
+
+// Handle registers h for method and path, preceded by the checks requested in opts.
+func (g routeGroup) Handle(method, path string, h handlerFunc, opts ...RouteOption) {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	chain := make([]gin.HandlerFunc, 0, 4)
+	if cfg.requireUser {
+		chain = append(chain, requireUserMiddleware)
+	}
+	if cfg.denyAPIKeys {
+		chain = append(chain, denyAPIKeysMiddleware)
+	}
+	if cfg.feature != "" {
+		chain = append(chain, requireFeature(cfg.feature))
+	}
+	chain = append(chain, func(c *gin.Context) {
+		h(c.Request.Context(), c)
+	})
+	g.routes.Handle(method, path, chain...)
+}
+
+// requireUserMiddleware aborts with 401 {"error": "user_id required"} when the request context
+// has no authenticated user.
+func requireUserMiddleware(c *gin.Context) {
+	ctx := c.Request.Context()
+	if _, ok := ctxkeys.CurrentUser(ctx); !ok {
+		logging.FromContext(ctx).Warn(c.Request.Method + " " + c.FullPath() + ": user not in context")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
+		return
+	}
+	c.Next()
+}
+
+// denyAPIKeysMiddleware aborts with 403 when the current user authenticated with an API key.
+func denyAPIKeysMiddleware(c *gin.Context) {
+	if user, ok := ctxkeys.CurrentUser(c.Request.Context()); ok && user.APIKeyID != "" {
+		c.AbortWithStatusJSON(http.StatusForbidden,
+			gin.H{"error": "API keys cannot call this route"})
+		return
+	}
+	c.Next()
+}
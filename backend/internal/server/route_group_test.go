@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestRequireFeature(t *testing.T) {
+	s, _ := newTestServer(t)
+	tester := "Bearer " + testTokenWithClaims("u1", `"tester":true`)
+	requireStatus(t, do(t, s, http.MethodPost, "/login", "", "Authorization", tester),
+		http.StatusOK)
+	w := do(t, s, http.MethodPost, "/orgs", `{"name":"Club"}`, "Authorization", tester)
+	requireStatus(t, w, http.StatusCreated)
+	var org struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &org); err != nil || org.ID == "" {
+		t.Fatalf("decoding org %s: %v", w.Body.String(), err)
+	}
+
+	tests := []struct {
+		name, path, flag string
+	}{
+		{name: "feed", path: "/feed", flag: "feed"},
+		{name: "goals", path: "/orgs/" + org.ID + "/goals", flag: "goals"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requireStatus(t, do(t, s, http.MethodGet, tt.path, "", "Authorization", tester),
+				http.StatusNotFound)
+			requireStatus(t, do(t, s, http.MethodGet, tt.path, "", "Authorization", tester,
+				"X-Feature-Preview", "feed,goals"), http.StatusOK)
+			requireStatus(t, do(t, s, http.MethodGet, tt.path, "", "Authorization", tester,
+				"X-Feature-Preview", tt.flag), http.StatusOK)
+
+			// The header is ignored for users without the tester claim
+			requireStatus(t, doAs(t, s, "u1", http.MethodGet, tt.path, "",
+				"X-Feature-Preview", tt.flag), http.StatusNotFound)
+		})
+	}
+}
+
+func TestDenyAPIKeys(t *testing.T) {
+	s, _ := newTestServer(t)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/login", ""), http.StatusOK)
+	w := doAs(t, s, "u1", http.MethodPost, "/orgs", `{"name":"Club"}`)
+	requireStatus(t, w, http.StatusCreated)
+	var org struct {
+		ID string `json:"id"`
+	}
+	decode(t, w, &org)
+	w = doAs(t, s, "u1", http.MethodPost, "/me/api-keys", `{"name":"script"}`)
+	requireStatus(t, w, http.StatusCreated)
+	var created struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	decode(t, w, &created)
+
+	// The key works elsewhere
+	requireStatus(t, do(t, s, http.MethodGet, "/visits", "", "X-Api-Key", created.Key),
+		http.StatusOK)
+
+	routes := []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/session", ""},
+		{http.MethodPost, "/me/revoke-sessions", ""},
+		{http.MethodPost, "/me/upgrade", `{"anonymousIdToken":"x"}`},
+		{http.MethodPost, "/me/merge", `{"idToken":"x"}`},
+		{http.MethodPut, "/me/handle", `{"handle":"taken-over"}`},
+		{http.MethodDelete, "/me/handle", ""},
+		{http.MethodPost, "/me/share-token/rotate", ""},
+		{http.MethodPost, "/me/api-keys", `{"name":"another"}`},
+		{http.MethodDelete, "/me/api-keys/" + created.ID, ""},
+		{http.MethodDelete, "/account", ""},
+		{http.MethodPost, "/account/cancel-deletion", ""},
+		{http.MethodDelete, "/orgs/" + org.ID, ""},
+	}
+	for _, r := range routes {
+		t.Run(r.method+" "+r.path, func(t *testing.T) {
+			requireStatus(t, do(t, s, r.method, r.path, r.body, "X-Api-Key", created.Key),
+				http.StatusForbidden)
+		})
+	}
+
+	// Nothing was changed: the key and the organization remain, and no deletion is pending
+	requireStatus(t, do(t, s, http.MethodGet, "/visits", "", "X-Api-Key", created.Key),
+		http.StatusOK)
+	requireStatus(t, doAs(t, s, "u1", http.MethodGet, "/orgs/"+org.ID, ""), http.StatusOK)
+	requireStatus(t, doAs(t, s, "u1", http.MethodPost, "/account/cancel-deletion", ""),
+		http.StatusConflict)
+}
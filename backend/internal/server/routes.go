@@ -1,57 +1,214 @@
 package server
 
 import (
-	"github.com/gin-gonic/gin"
+	"net/http"
+
+	"github.com/matti777/my-countries/backend/internal/features"
 )
 
 // RegisterRoutes registers all HTTP routes.
-// GET /countries is public; authenticated visit and friend routes use auth middleware.
+// GET /countries is public (anonymous requests subject to countriesAccessMiddleware);
+// authenticated visit and friend routes use auth middleware and
+// RequireUser, so their handlers can rely on ctxkeys.MustCurrentUser.
 // Unmatched GET/HEAD requests are served from embedded static files (SPA fallback to index.html).
 func (s *Server) RegisterRoutes() {
-	s.Router.GET("/countries", func(c *gin.Context) {
-		s.GetCountriesHandler(c.Request.Context(), c)
-	})
-	s.Router.GET("/share/profile/:shareToken", func(c *gin.Context) {
-		s.GetShareProfileHandler(c.Request.Context(), c)
-	})
+	// Health checks are exempt from rate limits: probes come from the platform's few addresses
+	health := routeGroup{routes: s.Router.Group("")}
+	health.Handle(http.MethodGet, "/healthz", s.GetHealthzHandler)
+	health.Handle(http.MethodGet, "/readyz", s.GetReadyzHandler)
+	public := routeGroup{routes: s.Router.Group("", s.ipRateLimitMiddleware())}
+	countries := routeGroup{routes: s.Router.Group("/countries", s.countriesAccessMiddleware())}
+	countries.Handle(http.MethodGet, "", s.GetCountriesHandler)
+	countries.Handle(http.MethodGet, "/:code", s.GetCountryHandler)
+	countries.Handle(http.MethodGet, "/:code/subdivisions", s.GetSubdivisionsHandler)
+	countries.Handle(http.MethodGet, "/:code/geometry", s.GetCountryGeometryHandler)
+	// Share routes recognize signed-in viewers so users can block them
+	share := routeGroup{routes: s.Router.Group("", s.shareAccessMiddleware(),
+		s.optionalAuthMiddleware())}
+	share.Handle(http.MethodGet, "/share/profile/:shareToken", s.GetShareProfileHandler)
+	share.Handle(http.MethodGet, "/share/:shareToken/passport", s.GetSharePassportHandler)
+	share.Handle(http.MethodGet, "/share/og/:file", s.GetShareOGImageHandler)
+	share.Handle(http.MethodGet, "/share/widget/:shareToken", s.GetShareWidgetHandler)
+	share.Handle(http.MethodGet, "/u/:handle", s.GetHandleProfileHandler)
+	orgShare := routeGroup{routes: s.Router.Group("", s.shareAccessMiddleware())}
+	orgShare.Handle(http.MethodGet, "/share/org/:shareToken", s.GetOrgShareHandler)
+	public.Handle(http.MethodGet, "/img", s.GetImageHandler)
+	public.Handle(http.MethodGet, "/api/changelog", s.GetChangelogHandler)
+	public.Handle(http.MethodGet, "/openapi.json", s.GetOpenAPIHandler)
+	public.Handle(http.MethodGet, "/docs", s.GetDocsHandler)
+	public.Handle(http.MethodGet, "/docs/:file", s.GetDocsAssetHandler)
+	public.Handle(http.MethodGet, "/static/manifest.json", s.GetStaticManifestHandler)
+	public.Handle(http.MethodDelete, "/session", s.DeleteSessionHandler)
+
+	// Protected routes: require a valid ID token, API key or session cookie. Dry runs are
+	// detected first so that authentication writes nothing either (API key LastUsedAt).
+	protected := routeGroup{routes: s.Router.Group("",
+		s.dryRunMiddleware(),
+		s.authMiddleware(),
+		s.userRateLimitMiddleware(),
+		s.featurePreviewMiddleware(),
+		s.recentRequestsMiddleware(),
+	)}
+	{
+		protected.Handle(http.MethodPost, "/login", s.PostLoginHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/session", s.PostSessionHandler,
+			RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodGet, "/visits", s.GetListHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/visits", s.PostVisitsHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/visits/search", s.GetVisitSearchHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/visits/clusters", s.GetVisitClustersHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/visits/summary", s.GetVisitSummaryHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/visits/stream", s.GetVisitStreamHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/visits/import", s.PostImportVisitsHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/visits/import/jobs/:id", s.GetImportJobHandler,
+			RequireUser)
+		protected.Handle(http.MethodPut, "/visits/:id", s.PutVisitHandler, RequireUser)
+		protected.Handle(http.MethodPatch, "/visits/:id", s.PatchVisitHandler, RequireUser)
+		protected.Handle(http.MethodDelete, "/visits/:id", s.DeleteVisitHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/visits/:id/history", s.GetVisitHistoryHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/visits/:id/proofs", s.PostVisitProofHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/visits/:id/proofs/:proofId", s.GetVisitProofHandler,
+			RequireUser)
+		protected.Handle(http.MethodDelete, "/visits/:id/proofs/:proofId",
+			s.DeleteVisitProofHandler, RequireUser)
+		protected.Handle(http.MethodDelete, "/visits/:id/overlaps/:overlapId",
+			s.DeleteVisitOverlapHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/settings", s.GetSettingsHandler, RequireUser)
+		protected.Handle(http.MethodPut, "/settings", s.PutSettingsHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/insights", s.GetInsightsHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/friends", s.GetFriendsHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/friends/followers", s.GetFollowersHandler,
+			RequireUser)
+		protected.Handle(http.MethodPost, "/friends/invite", s.PostFriendInviteHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/friends/invites", s.GetFriendInvitesHandler,
+			RequireUser)
+		protected.Handle(http.MethodDelete, "/friends/invites/:id", s.DeleteFriendInviteHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/friends/requests", s.GetFriendRequestsHandler,
+			RequireUser)
+		protected.Handle(http.MethodPost, "/friends/requests", s.PostFriendRequestHandler,
+			RequireUser)
+		protected.Handle(http.MethodPost, "/friends/requests/:id/accept",
+			s.PostAcceptFriendRequestHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/friends/requests/:id/decline",
+			s.PostDeclineFriendRequestHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/friends/:shareToken/visits", s.GetFriendVisitsHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/friends/:shareToken/compare", s.GetFriendCompareHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/friends/:shareToken/mutual", s.GetMutualFriendsHandler,
+			RequireUser)
+		protected.Handle(http.MethodPost, "/friends/:shareToken/visits/:id/overlaps",
+			s.PostVisitOverlapHandler, RequireUser)
+		protected.Handle(http.MethodPatch, "/friends/:shareToken", s.PatchFriendHandler,
+			RequireUser)
+		protected.Handle(http.MethodDelete, "/friends/:shareToken", s.DeleteFriendHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/feed", s.GetFeedHandler, RequireUser,
+			RequireFeature(features.Feed))
+		protected.Handle(http.MethodGet, "/me/settings", s.GetAccountSettingsHandler, RequireUser)
+		protected.Handle(http.MethodPatch, "/me/settings", s.PatchAccountSettingsHandler,
+			RequireUser)
+		protected.Handle(http.MethodPost, "/me/revoke-sessions", s.PostRevokeSessionsHandler,
+			RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodPost, "/me/upgrade", s.PostUpgradeHandler, RequireUser,
+			DenyAPIKeys)
+		protected.Handle(http.MethodPost, "/me/merge", s.PostMergeHandler, RequireUser,
+			DenyAPIKeys)
+		protected.Handle(http.MethodGet, "/me/handle", s.GetHandleHandler, RequireUser)
+		protected.Handle(http.MethodPut, "/me/handle", s.PutHandleHandler, RequireUser,
+			DenyAPIKeys)
+		protected.Handle(http.MethodDelete, "/me/handle", s.DeleteHandleHandler,
+			RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodGet, "/me/share-token/qr", s.GetShareTokenQRHandler,
+			RequireUser)
+		protected.Handle(http.MethodPost, "/me/share-token/rotate", s.PostRotateShareTokenHandler,
+			RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodGet, "/me/share-links", s.GetShareLinksHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/me/share-stats", s.GetShareStatsHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/me/share-links", s.PostShareLinkHandler, RequireUser)
+		protected.Handle(http.MethodDelete, "/me/share-links/:token", s.DeleteShareLinkHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/me/export", s.GetExportHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/me/audit", s.GetAuditHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/me/api-keys", s.GetAPIKeysHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/me/api-keys", s.PostAPIKeyHandler,
+			RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodDelete, "/me/api-keys/:id", s.DeleteAPIKeyHandler,
+			RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodGet, "/account", s.GetAccountHandler, RequireUser)
+		protected.Handle(http.MethodDelete, "/account", s.DeleteAccountHandler,
+			RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodPost, "/account/cancel-deletion",
+			s.PostCancelAccountDeletionHandler, RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodGet, "/blocked", s.GetBlockedHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/blocked", s.PostBlockedHandler, RequireUser)
+		protected.Handle(http.MethodDelete, "/blocked/:shareToken", s.DeleteBlockedHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/support/bundle", s.GetSupportBundleHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/orgs", s.GetOrgsHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/orgs", s.PostOrgHandler, RequireUser)
+		protected.Handle(http.MethodPost, "/orgs/join", s.PostOrgJoinHandler, RequireUser)
+		protected.Handle(http.MethodGet, "/orgs/:id", s.GetOrgHandler, RequireUser)
+		protected.Handle(http.MethodPut, "/orgs/:id", s.PutOrgHandler, RequireUser)
+		protected.Handle(http.MethodDelete, "/orgs/:id", s.DeleteOrgHandler,
+			RequireUser, DenyAPIKeys)
+		protected.Handle(http.MethodGet, "/orgs/:id/invitations", s.GetOrgInvitationsHandler,
+			RequireUser)
+		protected.Handle(http.MethodPost, "/orgs/:id/invitations", s.PostOrgInvitationHandler,
+			RequireUser)
+		protected.Handle(http.MethodDelete, "/orgs/:id/invitations/:code",
+			s.DeleteOrgInvitationHandler, RequireUser)
+		protected.Handle(http.MethodPut, "/orgs/:id/members/:userId", s.PutOrgMemberHandler,
+			RequireUser)
+		protected.Handle(http.MethodDelete, "/orgs/:id/members/:userId", s.DeleteOrgMemberHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/orgs/:id/leaderboard", s.GetOrgLeaderboardHandler,
+			RequireUser)
+		protected.Handle(http.MethodGet, "/orgs/:id/goals", s.GetOrgGoalsHandler, RequireUser,
+			RequireFeature(features.Goals))
+		protected.Handle(http.MethodPost, "/orgs/:id/goals", s.PostOrgGoalHandler, RequireUser,
+			RequireFeature(features.Goals))
+		protected.Handle(http.MethodDelete, "/orgs/:id/goals/:goalId", s.DeleteOrgGoalHandler,
+			RequireUser, RequireFeature(features.Goals))
+		protected.Handle(http.MethodGet, "/orgs/:id/share-links", s.GetOrgShareLinksHandler,
+			RequireUser)
+		protected.Handle(http.MethodPost, "/orgs/:id/share-links", s.PostOrgShareLinkHandler,
+			RequireUser)
+		protected.Handle(http.MethodDelete, "/orgs/:id/share-links/:token",
+			s.DeleteOrgShareLinkHandler, RequireUser)
+	}
 
-	// Protected routes: require valid Firebase ID token
-	protected := s.Router.Group("")
-	protected.Use(s.authMiddleware())
+	// Admin routes: authenticated users on the admin allowlist (ADMIN_USER_IDS) or with the
+	// admin custom claim
+	admin := routeGroup{routes: s.Router.Group("/admin", s.authMiddleware(),
+		s.userRateLimitMiddleware(), s.adminMiddleware())}
 	{
-		protected.POST("/login", func(c *gin.Context) {
-			s.PostLoginHandler(c.Request.Context(), c)
-		})
-		protected.GET("/visits", func(c *gin.Context) {
-			s.GetListHandler(c.Request.Context(), c)
-		})
-		protected.POST("/visits", func(c *gin.Context) {
-			s.PostVisitsHandler(c.Request.Context(), c)
-		})
-		protected.PUT("/visits/:id", func(c *gin.Context) {
-			s.PutVisitHandler(c.Request.Context(), c)
-		})
-		protected.DELETE("/visits/:id", func(c *gin.Context) {
-			s.DeleteVisitHandler(c.Request.Context(), c)
-		})
-		protected.GET("/settings", func(c *gin.Context) {
-			s.GetSettingsHandler(c.Request.Context(), c)
-		})
-		protected.PUT("/settings", func(c *gin.Context) {
-			s.PutSettingsHandler(c.Request.Context(), c)
-		})
-		protected.GET("/friends", func(c *gin.Context) {
-			s.GetFriendsHandler(c.Request.Context(), c)
-		})
-		protected.POST("/friends", func(c *gin.Context) {
-			s.PostFriendsHandler(c.Request.Context(), c)
-		})
-		protected.DELETE("/friends/:shareToken", func(c *gin.Context) {
-			s.DeleteFriendHandler(c.Request.Context(), c)
-		})
+		admin.Handle(http.MethodGet, "/status", s.GetAdminStatusHandler, RequireUser)
+		admin.Handle(http.MethodGet, "/users/:userId/audit", s.GetAdminUserAuditHandler,
+			RequireUser)
+		admin.Handle(http.MethodGet, "/users/:userId/limits", s.GetUserLimitsHandler, RequireUser)
+		admin.Handle(http.MethodPut, "/users/:userId/limits", s.PutUserLimitsHandler, RequireUser)
+		admin.Handle(http.MethodGet, "/backfills/:name", s.GetBackfillHandler, RequireUser)
+		admin.Handle(http.MethodPost, "/backfills/:name/start", s.PostBackfillStartHandler,
+			RequireUser)
+		admin.Handle(http.MethodPost, "/backfills/:name/pause", s.PostBackfillPauseHandler,
+			RequireUser)
+		admin.Handle(http.MethodGet, "/backup", s.GetBackupsHandler, RequireUser)
+		admin.Handle(http.MethodPost, "/backup", s.PostBackupHandler, RequireUser)
+		admin.Handle(http.MethodGet, "/country-overrides", s.GetCountryOverridesHandler,
+			RequireUser)
+		admin.Handle(http.MethodPut, "/country-overrides/:code", s.PutCountryOverrideHandler,
+			RequireUser)
+		admin.Handle(http.MethodDelete, "/country-overrides/:code",
+			s.DeleteCountryOverrideHandler, RequireUser)
 	}
 
 	// Static frontend: serve embedded files; unknown paths serve index.html (SPA fallback)
 	s.Router.NoRoute(s.staticHandler)
-}
 
+	s.openAPI, s.openAPIErr = openAPIDocument()
+}
@@ -10,62 +10,448 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/backfill"
+	"github.com/matti777/my-countries/backend/internal/backup"
+	"github.com/matti777/my-countries/backend/internal/changelog"
 	"github.com/matti777/my-countries/backend/internal/ctxkeys"
+	"github.com/matti777/my-countries/backend/internal/faults"
+	"github.com/matti777/my-countries/backend/internal/features"
+	"github.com/matti777/my-countries/backend/internal/imageproxy"
+	"github.com/matti777/my-countries/backend/internal/jsontime"
 	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/mailer"
+	"github.com/matti777/my-countries/backend/internal/metrics"
 	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/overrides"
+	"github.com/matti777/my-countries/backend/internal/proofs"
+	"github.com/matti777/my-countries/backend/internal/quota"
+	"github.com/matti777/my-countries/backend/internal/residency"
+	"github.com/matti777/my-countries/backend/internal/staticmanifest"
 	"github.com/matti777/my-countries/backend/internal/tracing"
+	"github.com/matti777/my-countries/backend/internal/turnstile"
+	"github.com/matti777/my-countries/backend/internal/writequeue"
 )
 
 // Server wraps the Gin engine and dependencies
 type Server struct {
-	Router   *gin.Engine
-	db       Database
-	auth     *auth.Authenticator
-	StaticFS embed.FS
+	Router         *gin.Engine
+	db             Database
+	auth           auth.TokenVerifier
+	StaticFS       embed.FS
+	imageProxy     *imageproxy.Proxy
+	recentRequests *recentRequestLog
+	orgVisits      *orgVisitsCache
+	requestStats   *requestStats
+	startedAt      time.Time
+	jsonTimeFormat jsontime.Format
+	adminUserIDs   map[string]struct{}
+	backfill       *backfill.Runner
+	backups        *backup.Service
+	overrides      *overrides.Refresher
+	proofs         proofs.Store
+	faults         *faults.Injector
+	residency      residency.Mode
+	writes         *writequeue.Queue
+	mailer         mailer.Mailer
+	publicBaseURL  string
+	maxFriends     int
+	maxVisits      int
+	ogImages       imageproxy.Cache
+	shareTokens    *shareTokenDatabase
+	sessions       auth.SessionCookies
+	sessionTTL     time.Duration
+	secureCookies  bool
+	checkRevoked   bool
+	readOnly       bool
+	visitWatcher   VisitWatcher
+	visitStreams   *visitStreams
+	metrics        *metrics.Metrics
+	openAPI        []byte // encoded document of GET /openapi.json, set by RegisterRoutes
+	openAPIErr     error  // why openAPI could not be built
+	trustedProxies []string
+
+	countriesAppTokens []string
+	countriesQuota     *quota.Limiter
+	shareQuota         *quota.Limiter
+	userQuota          *quota.Limiter
+	ipQuota            *quota.Limiter
+	auditRejections    *quota.Limiter
+	turnstile          *turnstile.Verifier
+
+	// staticManifest, staticManifestJSON and indexHTML are set by loadStaticFiles.
+	staticManifest     *staticmanifest.Manifest
+	staticManifestJSON []byte
+	indexHTML          []byte
 }
 
-// Database interface for database operations
+// Option configures optional Server behavior in NewServer.
+type Option func(*Server)
+
+// WithJSONTimeFormat sets the response time format used when the request's Accept header has
+// no time parameter (default jsontime.RFC3339).
+func WithJSONTimeFormat(f jsontime.Format) Option {
+	return func(s *Server) {
+		s.jsonTimeFormat = f
+	}
+}
+
+// WithAdminUserIDs sets the auth user IDs allowed to call the /admin routes (none by default).
+func WithAdminUserIDs(ids []string) Option {
+	return func(s *Server) {
+		s.adminUserIDs = make(map[string]struct{}, len(ids))
+		for _, id := range ids {
+			s.adminUserIDs[id] = struct{}{}
+		}
+	}
+}
+
+// WithBackfillRunner sets the runner of the admin backfill jobs. Without it the backfill
+// routes respond 503.
+func WithBackfillRunner(r *backfill.Runner) Option {
+	return func(s *Server) {
+		s.backfill = r
+	}
+}
+
+// WithBackups sets the service behind the admin backup routes. Without it those routes respond
+// 503.
+func WithBackups(svc *backup.Service) Option {
+	return func(s *Server) {
+		s.backups = svc
+	}
+}
+
+// WithCountryOverrides sets the refresher behind the admin country override routes. Without it
+// those routes respond 503.
+func WithCountryOverrides(r *overrides.Refresher) Option {
+	return func(s *Server) {
+		s.overrides = r
+	}
+}
+
+// WithProofStore sets the storage of visit proof files. Without it proof uploads and downloads
+// respond 503.
+func WithProofStore(store proofs.Store) Option {
+	return func(s *Server) {
+		s.proofs = store
+	}
+}
+
+// WithFaultInjector delays requests as configured in inj (staging only; see internal/faults).
+// Firestore faults are injected by the database client, not here.
+func WithFaultInjector(inj *faults.Injector) Option {
+	return func(s *Server) {
+		s.faults = inj
+	}
+}
+
+// WithDataResidency sets the data residency mode reported to users (see internal/residency).
+func WithDataResidency(m residency.Mode) Option {
+	return func(s *Server) {
+		s.residency = m
+	}
+}
+
+// WithWriteQueue sets the throttle of batch writes (visit imports). Without it imports write
+// unthrottled and always within the request.
+func WithWriteQueue(q *writequeue.Queue) Option {
+	return func(s *Server) {
+		s.writes = q
+	}
+}
+
+// WithMaxFriends limits how many friends a user may have; accepting a friend request beyond it
+// responds 422. Without it the number is unlimited. Admins may override it per user, as the
+// visit limit of WithMaxVisits.
+func WithMaxFriends(n int) Option {
+	return func(s *Server) {
+		s.maxFriends = n
+	}
+}
+
+// WithOGImageCache sets the cache of rendered share preview images (GET /share/og/...); without
+// it every request renders the image.
+func WithOGImageCache(cache imageproxy.Cache) Option {
+	return func(s *Server) {
+		s.ogImages = cache
+	}
+}
+
+// WithMailer sets the outbound email used by friend invitations and account deletion notices,
+// whose links start with publicBaseURL (the frontend origin). Without it POST /friends/invite
+// responds 503 and no notices are sent.
+func WithMailer(m mailer.Mailer, publicBaseURL string) Option {
+	return func(s *Server) {
+		s.mailer = m
+		s.publicBaseURL = publicBaseURL
+	}
+}
+
+// Database interface for database operations.
+// Write methods must also be overridden in dryRunDatabase (see dryrun.go).
 type Database interface {
+	Ping(ctx context.Context) error
 	GetCountryVisitsByUser(ctx context.Context, userID string) ([]models.CountryVisit, error)
+	GetPublicCountryVisitsByUser(ctx context.Context, userID string) ([]models.CountryVisit, error)
+	GetCountryVisitsPage(
+		ctx context.Context,
+		userID, cursor string,
+		limit int,
+	) ([]models.CountryVisit, string, error)
+	GetFeedVisits(
+		ctx context.Context,
+		userIDs []string,
+		after *models.FeedCursor,
+		limit int,
+	) ([]models.CountryVisit, error)
+	GetVisitedCountryCodes(
+		ctx context.Context,
+		userID string,
+		countryCodes []string,
+	) ([]string, error)
+	GetVisitSummariesForUsers(
+		ctx context.Context,
+		userIDs []string,
+	) (map[string]models.FriendVisitSummary, error)
 	GetUserByID(ctx context.Context, userID string) (*models.User, error)
 	GetUserByShareToken(ctx context.Context, shareToken string) (*models.User, error)
+	GetUsersByShareTokens(
+		ctx context.Context,
+		shareTokens []string,
+	) (map[string]*models.User, error)
 	EnsureUser(ctx context.Context, user *models.User) error
 	UpdateUserSettings(ctx context.Context, userID string, settings models.UserSettings) error
-	CreateCountryVisit(ctx context.Context, visit *models.CountryVisit) (*models.CountryVisit, error)
+	ScheduleAccountDeletion(ctx context.Context, userID string, at time.Time) error
+	CancelAccountDeletion(ctx context.Context, userID string) error
+	SetSharingDisabled(ctx context.Context, userID string, disabled bool) error
+	SetTokensRevokedAt(ctx context.Context, userID string, at time.Time) error
+	SetUserLimits(ctx context.Context, userID string, limits *models.UserLimits) error
+	MergeGuestUser(ctx context.Context, guestUserID, userID string, now time.Time) (int, error)
+	MergeUsers(
+		ctx context.Context,
+		fromUserID, toUserID string,
+		now time.Time,
+	) (*models.AccountMergeResult, error)
+	SetHandle(ctx context.Context, userID, handle string) error
+	DeleteHandle(ctx context.Context, userID string) error
+	GetUserByHandle(ctx context.Context, handle string) (*models.User, error)
+	RecordShareView(ctx context.Context, userID, token string, now time.Time) error
+	GetShareStats(
+		ctx context.Context,
+		userID string,
+		tokens []string,
+	) ([]models.ShareStats, error)
+	CreateShareLink(ctx context.Context, link *models.ShareLink) (*models.ShareLink, error)
+	GetShareLinks(ctx context.Context, userID string) ([]models.ShareLink, error)
+	GetShareLinkByToken(ctx context.Context, token string) (*models.ShareLink, error)
+	CountShareLinkView(ctx context.Context, userID, token string, now time.Time) error
+	DeleteShareLink(ctx context.Context, userID, token string) error
+	CreateAPIKey(ctx context.Context, key *models.APIKey) (*models.APIKey, error)
+	GetAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	TouchAPIKey(ctx context.Context, userID, keyID string, now time.Time) error
+	DeleteAPIKey(ctx context.Context, userID, keyID string) error
+	RecordAuditEvent(ctx context.Context, userID string, event *models.AuditEvent) error
+	GetAuditEvents(ctx context.Context, userID string, limit int) ([]models.AuditEvent, error)
+	RotateShareToken(
+		ctx context.Context,
+		userID string,
+		removeFromFriends bool,
+	) (string, int, error)
+	CreateCountryVisit(
+		ctx context.Context,
+		visit *models.CountryVisit,
+		maxVisits int,
+	) (*models.CountryVisit, error)
 	GetCountryVisit(ctx context.Context, visitID, userID string) (*models.CountryVisit, error)
-	ReplaceCountryVisit(ctx context.Context, visit *models.CountryVisit) error
+	ReplaceCountryVisit(
+		ctx context.Context,
+		visit *models.CountryVisit,
+		unmodifiedSince time.Time,
+	) error
 	DeleteCountryVisit(ctx context.Context, visitID string, userID string) error
+	GetCountryVisitHistory(ctx context.Context, visitID, userID string) ([]models.VisitHistoryEvent, error)
+	CreateVisitOverlap(
+		ctx context.Context,
+		userID, shareToken string,
+		overlap *models.VisitOverlap,
+	) (*models.VisitOverlap, error)
+	GetVisitOverlaps(ctx context.Context, userID string) ([]models.VisitOverlap, error)
+	DeleteVisitOverlap(ctx context.Context, userID, overlapID string) error
+	GetInsights(ctx context.Context, userID string) (*models.Insights, error)
+	SaveInsights(ctx context.Context, userID string, ins *models.Insights) error
 	GetFriendsByUser(ctx context.Context, userID string) ([]models.Friend, error)
-	AddFriend(ctx context.Context, userID string, shareToken, name, imageURL string) (models.Friend, error)
+	GetFriendsPage(
+		ctx context.Context,
+		userID, cursor string,
+		limit int,
+	) ([]models.Friend, string, error)
+	GetFriendByShareToken(ctx context.Context, userID, shareToken string) (*models.Friend, error)
+	UpdateFriendProfiles(ctx context.Context, userID string, friends []models.Friend) error
+	UpdateFriendNickname(
+		ctx context.Context,
+		userID, shareToken, nickname string,
+	) (*models.Friend, error)
 	DeleteFriendByShareToken(ctx context.Context, userID, shareToken string) error
+	GetFollowers(ctx context.Context, userID string) ([]models.Follower, error)
+	CreateFriendInvite(
+		ctx context.Context,
+		userID string,
+		invite *models.FriendInvite,
+	) (*models.FriendInvite, error)
+	GetFriendInvites(ctx context.Context, userID string) ([]models.FriendInvite, error)
+	FindFriendInvite(ctx context.Context, userID, email string) (*models.FriendInvite, error)
+	DeleteFriendInvite(ctx context.Context, userID, inviteID string) error
+	CreateFriendRequest(ctx context.Context, req *models.FriendRequest) (*models.FriendRequest, error)
+	GetFriendRequests(
+		ctx context.Context,
+		userID string,
+	) (incoming, outgoing []models.FriendRequest, err error)
+	GetFriendRequest(ctx context.Context, requestID, userID string) (*models.FriendRequest, error)
+	AcceptFriendRequest(
+		ctx context.Context,
+		requestID, userID string,
+		maxFriends int,
+	) (models.Friend, error)
+	DeleteFriendRequest(ctx context.Context, requestID, userID string) error
+
+	BlockUser(
+		ctx context.Context,
+		userID, ownerShareToken string,
+		blocked *models.BlockedUser,
+	) (*models.BlockedUser, error)
+	GetBlockedUsers(ctx context.Context, userID string) ([]models.BlockedUser, error)
+	IsBlocked(ctx context.Context, userID, otherUserID string) (bool, error)
+	UnblockUser(ctx context.Context, userID, shareToken string) error
+
+	CreateOrganization(
+		ctx context.Context,
+		org *models.Organization,
+		owner models.OrganizationMember,
+	) (*models.Organization, error)
+	GetOrganization(ctx context.Context, orgID string) (*models.Organization, error)
+	GetOrganizationByShareToken(ctx context.Context, shareToken string) (*models.Organization, error)
+	UpdateOrganizationProfile(ctx context.Context, orgID, name, description string) error
+	DeleteOrganization(ctx context.Context, orgID string) error
+	GetOrganizationMembers(ctx context.Context, orgID string) ([]models.OrganizationMember, error)
+	GetOrganizationMember(
+		ctx context.Context,
+		orgID, userID string,
+	) (*models.OrganizationMember, error)
+	GetOrganizationsByUser(
+		ctx context.Context,
+		userID string,
+	) ([]models.OrganizationMembership, error)
+	UpdateOrganizationMemberRole(ctx context.Context, orgID, userID, role string) error
+	RemoveOrganizationMember(ctx context.Context, orgID, userID string) error
+	CreateOrganizationInvitation(
+		ctx context.Context,
+		inv *models.OrganizationInvitation,
+	) (*models.OrganizationInvitation, error)
+	GetOrganizationInvitations(
+		ctx context.Context,
+		orgID string,
+	) ([]models.OrganizationInvitation, error)
+	GetOrganizationInvitation(
+		ctx context.Context,
+		code string,
+	) (*models.OrganizationInvitation, error)
+	AcceptOrganizationInvitation(
+		ctx context.Context,
+		code string,
+		member models.OrganizationMember,
+	) (*models.Organization, error)
+	DeleteOrganizationInvitation(ctx context.Context, orgID, code string) error
+	GetOrganizationGoals(ctx context.Context, orgID string) ([]models.OrganizationGoal, error)
+	CreateOrganizationGoal(
+		ctx context.Context,
+		orgID string,
+		goal *models.OrganizationGoal,
+	) (*models.OrganizationGoal, error)
+	DeleteOrganizationGoal(ctx context.Context, orgID, goalID string) error
+	CreateOrgShareLink(ctx context.Context, link *models.OrgShareLink) (*models.OrgShareLink, error)
+	GetOrgShareLinks(ctx context.Context, orgID string) ([]models.OrgShareLink, error)
+	GetOrgShareLink(ctx context.Context, token string) (*models.OrgShareLink, error)
+	DeleteOrgShareLink(ctx context.Context, orgID, token string) error
 }
 
 // NewServer creates a new server instance
-func NewServer(ctx context.Context, db Database, authenticator *auth.Authenticator, staticFS embed.FS) *Server {
+func NewServer(
+	ctx context.Context,
+	db Database,
+	authenticator auth.TokenVerifier,
+	staticFS embed.FS,
+	imageProxy *imageproxy.Proxy,
+	opts ...Option,
+) *Server {
 	router := gin.Default()
 
 	s := &Server{
-		Router:   router,
-		db:       db,
-		auth:     authenticator,
-		StaticFS: staticFS,
+		Router:         router,
+		db:             dryRunDatabase{Database: db},
+		auth:           authenticator,
+		StaticFS:       staticFS,
+		imageProxy:     imageProxy,
+		recentRequests: newRecentRequestLog(),
+		orgVisits:      newOrgVisitsCache(),
+		requestStats:   &requestStats{},
+		visitStreams:   newVisitStreams(),
+		startedAt:      time.Now().UTC(),
+		jsonTimeFormat: jsontime.RFC3339,
+	}
+	s.auditRejections = quota.New(auditRejectionsPerMinute)
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := trustProxies(router, s.trustedProxies); err != nil {
+		logging.FromContext(ctx).Error("Invalid trusted proxies; trusting none", logging.Error, err)
+		s.trustedProxies = nil
+	}
+	if s.shareTokens != nil {
+		// Under dryRunDatabase, so dry-run rotations keep the cached token
+		s.shareTokens.Database = db
+		s.db = dryRunDatabase{Database: s.shareTokens}
 	}
+	s.loadStaticFiles(ctx)
 
-	// COOP: allow Firebase Auth popup to check window.closed without console error
+	// COOP: allow Firebase Auth popup to check window.closed without console error.
+	// X-API-Version lets clients detect a newer backend (see GET /api/changelog).
+	apiVersion := changelog.CurrentVersion()
 	s.Router.Use(func(c *gin.Context) {
 		c.Header("Cross-Origin-Opener-Policy", "unsafe-none")
+		c.Header("X-API-Version", apiVersion)
 		c.Next()
 	})
+	// Before any middleware that may respond, so every response carries the request ID
+	s.Router.Use(s.requestIDMiddleware())
+	// Counts every response, including ones aborted by later middleware, for GET /admin/status
+	s.Router.Use(s.requestStatsMiddleware())
+	if s.metrics != nil {
+		s.registerMetrics()
+		s.Router.Use(s.metricsMiddleware())
+	}
 	// Traceparent first so trace is in context before any logging
 	s.Router.Use(s.traceparentMiddleware())
 	// Then context: tracer and request-scoped logger (with trace from Traceparent)
 	s.Router.Use(s.contextMiddleware(ctx))
 	// Then tracing (span creation)
 	s.Router.Use(s.tracingMiddleware())
+	// Response time format (Accept: application/json; time=unix|rfc3339)
+	s.Router.Use(s.jsonTimeMiddleware())
+	if s.readOnly {
+		s.Router.Use(s.readOnlyMiddleware())
+	}
+	if s.faults != nil {
+		s.Router.Use(func(c *gin.Context) {
+			s.faults.Delay(c.Request.Context())
+			c.Next()
+		})
+	}
 
 	return s
 }
@@ -83,7 +469,8 @@ func (s *Server) traceparentMiddleware() gin.HandlerFunc {
 	}
 }
 
-// contextMiddleware injects tracer and logger into request context.
+// contextMiddleware injects tracer and logger into request context. The logger is labeled
+// with the request ID (see requestIDMiddleware).
 // Logger is always present (set at startup in main); nil checks are not used.
 func (s *Server) contextMiddleware(ctx context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -93,6 +480,9 @@ func (s *Server) contextMiddleware(ctx context.Context) gin.HandlerFunc {
 		}
 		logger := logging.FromContext(ctx)
 		reqLogger := logger.WithTraceFromContext(reqCtx)
+		if id := requestIDFromContext(reqCtx); id != "" {
+			reqLogger = reqLogger.WithParams(logging.RequestID, id)
+		}
 		reqCtx = logging.WithContext(reqCtx, reqLogger)
 		c.Request = c.Request.WithContext(reqCtx)
 		c.Next()
@@ -123,53 +513,206 @@ func (s *Server) tracingMiddleware() gin.HandlerFunc {
 	}
 }
 
-// authMiddleware requires a valid Firebase ID token in Authorization: Bearer <token>.
-// On success it injects *models.User (from token claims only; no DB lookup) into request context.
+// authMiddleware requires a valid ID token in Authorization: Bearer <token> or, when no
+// Authorization header is sent, a valid API key in X-Api-Key or (when enabled by
+// WithSessionCookies) a valid session cookie.
+// On success it injects *models.User (from token claims only; no DB lookup except for API keys)
+// into request context.
 // User document in DB is created by POST /login (EnsureUser), not by this middleware.
 // On failure it returns 401 and does not call next.
 func (s *Server) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
+		log := logging.FromContext(ctx)
 		authz := c.GetHeader("Authorization")
+		if key := c.GetHeader(apiKeyHeader); authz == "" && key != "" {
+			user, ok := s.apiKeyUser(ctx, c, key)
+			if !ok {
+				return
+			}
+			setCurrentUser(c, user)
+			c.Next()
+			return
+		}
+		var claims *auth.Claims
 		if authz == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing Authorization header"})
+			claims = s.sessionClaims(ctx, c)
+			if claims == nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized,
+					gin.H{"error": "missing Authorization header"})
+				return
+			}
+		} else {
+			const prefix = "Bearer "
+			if !strings.HasPrefix(authz, prefix) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized,
+					gin.H{"error": "invalid Authorization format"})
+				return
+			}
+			token := strings.TrimSpace(authz[len(prefix):])
+			if token == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+				return
+			}
+			var err error
+			claims, err = s.auth.VerifyIDToken(ctx, token)
+			if err != nil {
+				log.Warn("Token verification failed", logging.Error, err)
+				s.recordTokenRejected(ctx, c, "", token, auditDetailInvalidToken)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+				return
+			}
+		}
+		if revoked, err := s.tokenRevoked(ctx, claims); err != nil {
+			log.Error("Token revocation check failed", logging.Error, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError,
+				gin.H{"error": "failed to verify token"})
+			return
+		} else if revoked {
+			log.Warn("Revoked token refused", logging.UserID, claims.Sub)
+			s.recordTokenRejected(ctx, c, claims.Sub, "", auditDetailRevokedToken)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
 			return
 		}
-		const prefix = "Bearer "
-		if !strings.HasPrefix(authz, prefix) {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid Authorization format"})
+		setCurrentUser(c, auth.UserFromClaims(claims))
+		c.Next()
+	}
+}
+
+// setCurrentUser puts the authenticated user in the request context and its logger.
+func setCurrentUser(c *gin.Context, user *models.User) {
+	ctx := c.Request.Context()
+	ctx = ctxkeys.WithCurrentUser(ctx, user)
+	ctx = logging.WithContext(ctx,
+		logging.FromContext(ctx).WithParams(logging.CurrentUserID, user.UserID))
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// optionalAuthMiddleware puts the user of a valid Authorization: Bearer token (or session
+// cookie) in context, like authMiddleware, but lets requests without one (or with an invalid
+// one) through anonymously. Used by public routes that treat signed-in viewers differently,
+// e.g. blocked users.
+func (s *Server) optionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if claims := s.optionalClaims(ctx, c); claims != nil {
+			ctx = ctxkeys.WithCurrentUser(ctx, auth.UserFromClaims(claims))
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
+
+// optionalClaims returns the claims of the request's Bearer token or, without an Authorization
+// header, its session cookie; nil when there is neither or the credentials are invalid or
+// revoked (logged), so the request is anonymous.
+func (s *Server) optionalClaims(ctx context.Context, c *gin.Context) *auth.Claims {
+	log := logging.FromContext(ctx)
+	var claims *auth.Claims
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		var err error
+		claims, err = s.auth.VerifyIDToken(ctx, strings.TrimSpace(token))
+		if err != nil {
+			log.Warn("Token verification failed; treating request as anonymous",
+				logging.Error, err)
+			return nil
+		}
+	} else if claims = s.sessionClaims(ctx, c); claims == nil {
+		return nil
+	}
+	if revoked, err := s.tokenRevoked(ctx, claims); err != nil || revoked {
+		log.Warn("Token revoked or revocation check failed; treating request as anonymous",
+			logging.Error, err)
+		return nil
+	}
+	return claims
+}
+
+// adminMiddleware allows only users listed by WithAdminUserIDs or whose token carries the admin
+// custom claim (models.User.IsAdmin); others, and requests authenticated with an API key, get
+// 403. Must run after authMiddleware so the current user is in context.
+func (s *Server) adminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		user, ok := ctxkeys.CurrentUser(ctx)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user_id required"})
+			return
+		}
+		if user.APIKeyID != "" {
+			c.AbortWithStatusJSON(http.StatusForbidden,
+				gin.H{"error": "API keys cannot call admin routes"})
+			return
+		}
+		if _, ok := s.adminUserIDs[user.UserID]; !ok && !user.IsAdmin {
+			logging.FromContext(ctx).Warn("Admin route denied", logging.UserID, user.UserID)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin required"})
 			return
 		}
-		token := strings.TrimSpace(authz[len(prefix):])
-		if token == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+		c.Next()
+	}
+}
+
+// featurePreviewMiddleware enables experimental behavior for trusted testers via the
+// X-Feature-Preview header (comma-separated flag names from features.Catalog).
+// Must run after authMiddleware. Unknown flags yield 400; the header is ignored (and logged)
+// for users without the tester claim. Enabled flags are stored in context and logged.
+func (s *Server) featurePreviewMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("X-Feature-Preview")
+		if header == "" {
+			c.Next()
 			return
 		}
+		ctx := c.Request.Context()
 		log := logging.FromContext(ctx)
-		claims, err := s.auth.VerifyIDToken(ctx, token)
-		if err != nil {
-			log.Warn("Token verification failed", logging.Error, err)
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		user, _ := ctxkeys.CurrentUser(ctx)
+		if user == nil || !user.IsTester {
+			log.Warn("Ignoring X-Feature-Preview from non-tester", logging.FeaturePreview, header)
+			c.Next()
 			return
 		}
-		user := auth.UserFromClaims(claims)
-		ctx = context.WithValue(ctx, ctxkeys.CurrentUserKey, user)
-		ctx = logging.WithContext(ctx, log.WithParams(logging.CurrentUserID, user.UserID))
+		set, unknown := features.Parse(header)
+		if len(unknown) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "unknown feature preview flag(s): " + strings.Join(unknown, ", ") +
+					"; valid flags: " + strings.Join(features.CatalogNames(), ", "),
+			})
+			return
+		}
+		names := strings.Join(set.Names(), ",")
+		log = log.WithParams(logging.FeaturePreview, names)
+		log.Info("Feature preview enabled")
+		ctx = features.WithContext(ctx, set)
+		ctx = logging.WithContext(ctx, log)
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
 
+// requireFeature hides a route behind a preview flag: requests without the flag enabled get 404.
+func requireFeature(flag features.Flag) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !features.Enabled(c.Request.Context(), flag) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.Next()
+	}
+}
+
 // staticHandler serves embedded frontend files. "/" and missing paths serve index.html (SPA fallback).
-// Cache: index.html not cached; assets (JS, CSS, images) heavily cached.
+// Cache: index.html not cached; assets (JS, CSS, images) heavily cached. Files carry the ETag
+// of their manifest hash and answer If-None-Match with 304.
 func (s *Server) staticHandler(c *gin.Context) {
 	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
 		c.Status(http.StatusNotFound)
 		return
 	}
 	reqPath := strings.TrimPrefix(c.Request.URL.Path, "/")
-	if reqPath == "" {
-		reqPath = "index.html"
+	if reqPath == "" || reqPath == "index.html" {
+		s.serveIndex(c)
+		return
 	}
 	// Open from embedded FS (paths under static/)
 	fsPath := path.Join("static", reqPath)
@@ -177,18 +720,7 @@ func (s *Server) staticHandler(c *gin.Context) {
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			// SPA fallback: serve index.html
-			f, err = s.StaticFS.Open("static/index.html")
-			if err != nil {
-				c.Status(http.StatusInternalServerError)
-				return
-			}
-			defer f.Close()
-			stat, _ := f.Stat()
-			c.Header("Cache-Control", "no-store")
-			c.Header("Content-Type", "text/html; charset=utf-8")
-			c.Header("Content-Length", strconv.FormatInt(stat.Size(), 10))
-			c.Status(http.StatusOK)
-			io.Copy(c.Writer, f)
+			s.serveIndex(c)
 			return
 		}
 		c.Status(http.StatusInternalServerError)
@@ -202,27 +734,16 @@ func (s *Server) staticHandler(c *gin.Context) {
 	}
 	if stat.IsDir() {
 		// Don't list directories; treat as not found and fallback to index.html
-		f.Close()
-		f, _ = s.StaticFS.Open("static/index.html")
-		if f == nil {
-			c.Status(http.StatusNotFound)
-			return
-		}
-		defer f.Close()
-		stat, _ = f.Stat()
-		c.Header("Cache-Control", "no-store")
-		c.Header("Content-Type", "text/html; charset=utf-8")
-		c.Header("Content-Length", strconv.FormatInt(stat.Size(), 10))
-		c.Status(http.StatusOK)
-		io.Copy(c.Writer, f)
+		s.serveIndex(c)
 		return
 	}
-	// Set cache headers: index.html no-store; assets heavy cache (reqPath is URL path, not fs path)
-	if reqPath == "index.html" {
-		c.Header("Cache-Control", "no-store")
-	} else if strings.HasPrefix(reqPath, "assets/") || isHeavyCacheExt(path.Ext(reqPath)) {
+	// Set cache headers: assets heavy cache (reqPath is URL path, not fs path)
+	if strings.HasPrefix(reqPath, "assets/") || isHeavyCacheExt(path.Ext(reqPath)) {
 		c.Header("Cache-Control", "public, max-age=31536000, immutable")
 	}
+	if file, ok := s.staticManifest.Files[reqPath]; ok && writeNotModifiedIfMatch(c, file.ETag()) {
+		return
+	}
 	contentType := contentTypeByExt(path.Ext(reqPath))
 	if contentType != "" {
 		c.Header("Content-Type", contentType)
@@ -232,6 +753,46 @@ func (s *Server) staticHandler(c *gin.Context) {
 	io.Copy(c.Writer, f)
 }
 
+// serveIndex serves index.html with Subresource Integrity attributes injected (see
+// loadStaticFiles). Never cached.
+func (s *Server) serveIndex(c *gin.Context) {
+	if s.indexHTML == nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Content-Length", strconv.Itoa(len(s.indexHTML)))
+	c.Status(http.StatusOK)
+	if c.Request.Method != http.MethodHead {
+		c.Writer.Write(s.indexHTML)
+	}
+}
+
+// loadStaticFiles loads the static file manifest (built from the files when the build did not
+// generate it) and renders index.html with integrity attributes for its scripts and styles.
+// Failures are logged; the files are then served without ETags or integrity attributes.
+func (s *Server) loadStaticFiles(ctx context.Context) {
+	log := logging.FromContext(ctx)
+	s.staticManifest = &staticmanifest.Manifest{Files: map[string]staticmanifest.File{}}
+	m, err := staticmanifest.Load(s.StaticFS, "static")
+	if err != nil {
+		log.Error("Failed to load static manifest", logging.Error, err)
+	} else {
+		s.staticManifest = m
+	}
+	if s.staticManifestJSON, err = s.staticManifest.Marshal(); err != nil {
+		log.Error("Failed to encode static manifest", logging.Error, err)
+	}
+
+	index, err := fs.ReadFile(s.StaticFS, "static/index.html")
+	if err != nil {
+		log.Error("Failed to read static/index.html", logging.Error, err)
+		return
+	}
+	s.indexHTML = s.staticManifest.InjectIntegrity(index)
+}
+
 func isHeavyCacheExt(ext string) bool {
 	switch strings.ToLower(ext) {
 	case ".js", ".css", ".jpg", ".jpeg", ".png", ".gif", ".webp", ".ico", ".woff", ".woff2":
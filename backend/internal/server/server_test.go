@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/database/memory"
+)
+
+// testProjectID is the Firebase project of the emulator tokens made by testToken.
+const testProjectID = "demo-test"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// newTestServer returns a server with routes on an empty in-memory database, accepting the
+// unsigned tokens of testToken.
+func newTestServer(t *testing.T, opts ...Option) (*Server, *memory.DB) {
+	t.Helper()
+	verifier, err := auth.NewEmulatorVerifier(testProjectID)
+	if err != nil {
+		t.Fatalf("NewEmulatorVerifier: %v", err)
+	}
+	db := memory.New()
+	s := NewServer(context.Background(), db, verifier, embed.FS{}, nil, opts...)
+	s.RegisterRoutes()
+	return s, db
+}
+
+// testToken returns an unsigned Firebase emulator ID token of userID.
+func testToken(userID string) string {
+	return testTokenWithClaims(userID, "")
+}
+
+// testTokenWithClaims returns testToken with the JSON members claims (e.g. `"tester":true`)
+// added to its payload.
+func testTokenWithClaims(userID, claims string) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	now := time.Now().Unix()
+	if claims != "" {
+		claims = "," + claims
+	}
+	header := enc([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload := enc(fmt.Appendf(nil, `{"iss":"https://securetoken.google.com/%s","aud":"%s",`+
+		`"sub":"%s","user_id":"%s","iat":%d,"exp":%d,"auth_time":%d,"name":"Test User",`+
+		`"firebase":{"sign_in_provider":"google.com"}%s}`,
+		testProjectID, testProjectID, userID, userID, now, now+3600, now, claims))
+	return header + "." + payload + "."
+}
+
+// do serves a request to s; headers are name-value pairs.
+func do(
+	t *testing.T,
+	s *Server,
+	method, path, body string,
+	headers ...string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, r)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.Router.ServeHTTP(w, req)
+	return w
+}
+
+// doAs serves a request to s authenticated as userID.
+func doAs(
+	t *testing.T,
+	s *Server,
+	userID, method, path, body string,
+	headers ...string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+	return do(t, s, method, path, body,
+		append([]string{"Authorization", "Bearer " + testToken(userID)}, headers...)...)
+}
+
+// requireStatus fails the test unless w has status want.
+func requireStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		body := w.Body.String()
+		if len(body) > 500 {
+			body = body[:500] + "..."
+		}
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, want, body)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/auth"
+	"github.com/matti777/my-countries/backend/internal/logging"
+)
+
+// sessionCookieName is the session cookie; Firebase Hosting forwards only a cookie of this name.
+const sessionCookieName = "__session"
+
+// WithSessionCookies enables session cookie authentication (POST /session) with cookies valid
+// for ttl. secure sets the cookie's Secure attribute; it is off only for local http development.
+// Without it POST /session responds 503 and requests authenticate with Bearer tokens only.
+func WithSessionCookies(sessions auth.SessionCookies, ttl time.Duration, secure bool) Option {
+	return func(s *Server) {
+		s.sessions = sessions
+		s.sessionTTL = ttl
+		s.secureCookies = secure
+	}
+}
+
+// setSessionCookie sets (or with maxAge < 0 clears) the HttpOnly session cookie. SameSite=Strict
+// keeps other sites from sending it.
+func (s *Server) setSessionCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   s.secureCookies,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// sessionClaims returns the claims of the request's session cookie, or nil without session
+// support, without a cookie or when it is invalid (logged). Requests with unsafe methods are
+// also refused when the browser reports them as cross-site (Sec-Fetch-Site).
+func (s *Server) sessionClaims(ctx context.Context, c *gin.Context) *auth.Claims {
+	if s.sessions == nil {
+		return nil
+	}
+	cookie, err := c.Cookie(sessionCookieName)
+	if err != nil || cookie == "" {
+		return nil
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		if site := c.GetHeader("Sec-Fetch-Site"); site != "" && site != "same-origin" &&
+			site != "none" {
+			logging.FromContext(ctx).Warn("Cross-site request with session cookie refused",
+				"sec_fetch_site", site)
+			return nil
+		}
+	}
+	claims, err := s.sessions.Verify(ctx, cookie)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Session cookie verification failed", logging.Error, err)
+		return nil
+	}
+	return claims
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/quota"
+	"github.com/matti777/my-countries/backend/internal/turnstile"
+)
+
+// shareNotFoundLatency is the least time a share route takes to answer 404, so response times
+// do not tell which lookup failed, e.g. an unknown token from an expired share link.
+const shareNotFoundLatency = 200 * time.Millisecond
+
+// shareStartKey is the gin context key of the time shareAccessMiddleware saw the request.
+const shareStartKey = "shareStart"
+
+// WithShareAccess limits the public share routes to perMinute requests per minute per client
+// IP (none when not positive). With verifier, a client over the limit may go on by sending a
+// solved Turnstile challenge in X-Turnstile-Token, which refills its quota.
+func WithShareAccess(perMinute float64, verifier *turnstile.Verifier) Option {
+	return func(s *Server) {
+		if perMinute > 0 {
+			s.shareQuota = quota.New(perMinute)
+		}
+		s.turnstile = verifier
+	}
+}
+
+// shareAccessMiddleware guards the public share routes, which take guessable tokens, against
+// enumeration: the per-IP quota answers 429 with Retry-After (and the Turnstile site key when a
+// challenge can lift it), and the request start is kept for shareNotFound.
+func (s *Server) shareAccessMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(shareStartKey, time.Now())
+		if s.shareQuota == nil {
+			c.Next()
+			return
+		}
+		ip := c.ClientIP()
+		ok, retryAfter := s.shareQuota.Allow(ip)
+		if !ok && s.turnstile != nil {
+			ok = s.passedChallenge(c, ip)
+		}
+		if !ok {
+			logging.FromContext(c.Request.Context()).Warn("Share quota exceeded", "client_ip", ip)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			body := gin.H{"error": "too many share requests; retry later"}
+			if s.turnstile != nil {
+				body["captcha"] = "turnstile"
+				body["siteKey"] = s.turnstile.SiteKey()
+			}
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, body)
+			return
+		}
+		c.Next()
+	}
+}
+
+// passedChallenge reports whether the request carries a valid Turnstile token, and if so
+// refills the quota of the client at ip and takes this request from it.
+func (s *Server) passedChallenge(c *gin.Context, ip string) bool {
+	token := c.GetHeader("X-Turnstile-Token")
+	if token == "" {
+		return false
+	}
+	ctx := c.Request.Context()
+	valid, err := s.turnstile.Verify(ctx, token)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Turnstile verification failed", logging.Error, err)
+		return false
+	}
+	if !valid {
+		return false
+	}
+	s.shareQuota.Reset(ip)
+	ok, _ := s.shareQuota.Allow(ip)
+	return ok
+}
+
+// shareNotFound answers a share route with 404 once shareNotFoundLatency has passed since the
+// request started, or at once when the client has gone.
+func shareNotFound(c *gin.Context) {
+	if start, ok := c.Get(shareStartKey); ok {
+		wait := time.Until(start.(time.Time).Add(shareNotFoundLatency))
+		if wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-c.Request.Context().Done():
+			}
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+}
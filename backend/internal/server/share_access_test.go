@@ -0,0 +1,16 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestShareQuotaIgnoresForgedForwardedFor(t *testing.T) {
+	s, _ := newTestServer(t, WithShareAccess(1, nil))
+
+	requireStatus(t, do(t, s, http.MethodGet, "/share/profile/unknown", "",
+		"X-Forwarded-For", "203.0.113.1"), http.StatusNotFound)
+	w := do(t, s, http.MethodGet, "/share/profile/unknown", "",
+		"X-Forwarded-For", "203.0.113.2")
+	requireStatus(t, w, http.StatusTooManyRequests)
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/matti777/my-countries/backend/internal/cache"
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// WithShareTokenCache caches the user ID of share tokens in c, so GetUserByShareToken (every
+// public share view and friend request) reads the user's document directly instead of querying
+// users by ShareToken. Without it (or with nil) every lookup runs the query.
+func WithShareTokenCache(c cache.Cache) Option {
+	return func(s *Server) {
+		if c != nil {
+			s.shareTokens = &shareTokenDatabase{cache: c}
+		}
+	}
+}
+
+// shareTokenDatabase decorates a Database with a cache of share token -> user ID. Only the ID
+// is cached, never the user: a hit reads the user by ID and is used only while the user still
+// has that token, so a token rotated or deleted through another instance misses instead of
+// serving a stale user. Rotations on this instance also drop the old token right away.
+type shareTokenDatabase struct {
+	Database
+	cache cache.Cache
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func (d *shareTokenDatabase) GetUserByShareToken(
+	ctx context.Context,
+	shareToken string,
+) (*models.User, error) {
+	if userID, ok := d.cache.Get(shareToken); ok {
+		user, err := d.Database.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil && user.ShareToken == shareToken {
+			d.hits.Add(1)
+			return user, nil
+		}
+		d.cache.Delete(shareToken)
+	}
+	d.misses.Add(1)
+	user, err := d.Database.GetUserByShareToken(ctx, shareToken)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		d.cache.Set(shareToken, user.ID)
+	}
+	return user, nil
+}
+
+func (d *shareTokenDatabase) RotateShareToken(
+	ctx context.Context,
+	userID string,
+	removeFromFriends bool,
+) (string, int, error) {
+	user, err := d.Database.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", 0, err
+	}
+	token, removed, err := d.Database.RotateShareToken(ctx, userID, removeFromFriends)
+	if user != nil {
+		d.cache.Delete(user.ShareToken)
+	}
+	return token, removed, err
+}
+
+// stats returns the hit and miss counts of GetUserByShareToken since startup. A cached ID whose
+// user no longer has the token counts as a miss.
+func (d *shareTokenDatabase) stats() (hits, misses int64) {
+	return d.hits.Load(), d.misses.Load()
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"time"
+
+	"github.com/matti777/my-countries/backend/internal/models"
+)
+
+// findSameDayVisit returns the first visit to countryCode on the same UTC calendar day as t,
+// or nil. Used by the sameDay dedupe mode of POST /visits.
+func findSameDayVisit(visits []models.CountryVisit, countryCode string, t time.Time) *models.CountryVisit {
+	y, m, d := t.UTC().Date()
+	for i := range visits {
+		if visits[i].CountryCode != countryCode {
+			continue
+		}
+		vy, vm, vd := visits[i].VisitedTime.UTC().Date()
+		if vy == y && vm == m && vd == d {
+			return &visits[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package staticmanifest describes the embedded frontend files: a SHA-256 per file for ETags
+// and cache-busting clients, and a Subresource Integrity value for scripts and stylesheets
+// that is injected into the served index.html. The manifest is generated at build time into
+// static/manifest.json (go generate in the backend module root) and computed at startup when
+// a build skipped that step.
+package staticmanifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FileName is the manifest's path relative to the static root.
+const FileName = "manifest.json"
+
+// Manifest lists the static files by path relative to the static root (e.g.
+// "assets/index-3f2a.js").
+type Manifest struct {
+	Files map[string]File `json:"files"`
+}
+
+// File describes one static file.
+type File struct {
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+
+	// SHA256 is the hex-encoded SHA-256 of the contents.
+	SHA256 string `json:"sha256"`
+
+	// Integrity is the Subresource Integrity value ("sha384-..."), set for .js and .css files.
+	Integrity string `json:"integrity,omitempty"`
+}
+
+// ETag returns the strong entity tag of the file.
+func (f File) ETag() string {
+	return `"` + f.SHA256[:32] + `"`
+}
+
+// Build hashes every file under root in fsys, except the manifest itself.
+func Build(fsys fs.FS, root string) (*Manifest, error) {
+	m := &Manifest{Files: make(map[string]File)}
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+		if rel == FileName {
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		f := File{Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])}
+		switch path.Ext(rel) {
+		case ".js", ".css":
+			sri := sha512.Sum384(content)
+			f.Integrity = "sha384-" + base64.StdEncoding.EncodeToString(sri[:])
+		}
+		m.Files[rel] = f
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash static files: %w", err)
+	}
+	return m, nil
+}
+
+// Load reads root/manifest.json from fsys, or builds the manifest when the file is missing.
+func Load(fsys fs.FS, root string) (*Manifest, error) {
+	raw, err := fs.ReadFile(fsys, path.Join(root, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Build(fsys, root)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read static manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse static manifest: %w", err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]File)
+	}
+	return &m, nil
+}
+
+// Marshal encodes the manifest with files in path order, so regenerating an unchanged build
+// yields the same bytes.
+func (m *Manifest) Marshal() ([]byte, error) {
+	paths := make([]string, 0, len(m.Files))
+	for p := range m.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	buf.WriteString("{\n  \"files\": {")
+	for i, p := range paths {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(p)
+		value, err := json.Marshal(m.Files[p])
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "\n    %s: %s", key, value)
+	}
+	buf.WriteString("\n  }\n}\n")
+	return buf.Bytes(), nil
+}
+
+// resourceTag matches <script src="..."> and <link href="..."> start tags.
+var resourceTag = regexp.MustCompile(`<(?:script|link)\b[^>]*>`)
+
+// resourceURL matches the src or href attribute of a resourceTag.
+var resourceURL = regexp.MustCompile(`\s(?:src|href)="/?([^"?#]+)"`)
+
+// InjectIntegrity adds integrity and crossorigin attributes to the script and link tags of
+// html that reference a file with an Integrity value. Tags that already carry integrity and
+// external URLs are left unchanged.
+func (m *Manifest) InjectIntegrity(html []byte) []byte {
+	return resourceTag.ReplaceAllFunc(html, func(tag []byte) []byte {
+		if bytes.Contains(tag, []byte(" integrity=")) {
+			return tag
+		}
+		match := resourceURL.FindSubmatch(tag)
+		if match == nil || bytes.Contains(match[1], []byte("://")) {
+			return tag
+		}
+		f, ok := m.Files[string(match[1])]
+		if !ok || f.Integrity == "" {
+			return tag
+		}
+		attrs := ` integrity="` + f.Integrity + `"`
+		if !bytes.Contains(tag, []byte(" crossorigin")) {
+			attrs += ` crossorigin="anonymous"`
+		}
+		end := len(tag) - 1
+		if bytes.HasSuffix(tag, []byte("/>")) {
+			end--
+		}
+		out := make([]byte, 0, len(tag)+len(attrs))
+		out = append(out, tag[:end]...)
+		out = append(out, attrs...)
+		return append(out, tag[end:]...)
+	})
+}
@@ -0,0 +1,63 @@
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations maps letters that do not decompose into a base letter + diacritic.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d",
+	'þ': "th", 'ı': "i", 'ħ': "h", 'ŧ': "t", 'ŋ': "ng", 'ĸ': "k",
+}
+
+// apostrophes are removed rather than turned into word breaks ("d'Ivoire" → "divoire"). The
+// modifier letters ʼ and ʻ (as in "Oʻzbekiston") count too, though Unicode makes them letters.
+var apostrophes = map[rune]struct{}{
+	'\'': {}, '’': {}, '‘': {}, '`': {}, 'ʼ': {}, 'ʻ': {}, '´': {},
+}
+
+// Fold normalizes s for matching: Unicode NFKD with combining marks removed (diacritic folding),
+// lowercase, transliteration of non-decomposable letters (ß → ss, ø → o, ...), apostrophes
+// dropped, other punctuation turned into spaces, and whitespace collapsed.
+// For example "Côte d’Ivoire" and "Cote d'Ivoire" both fold to "cote divoire".
+func Fold(s string) string {
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(t, s)
+	if err != nil {
+		stripped = s
+	}
+	var b strings.Builder
+	b.Grow(len(stripped))
+	space := false
+	for _, r := range stripped {
+		r = unicode.ToLower(r)
+		if _, ok := apostrophes[r]; ok {
+			continue
+		}
+		if tr, ok := transliterations[r]; ok {
+			b.WriteString(tr)
+			space = false
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		if !space && b.Len() > 0 {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// Words returns the folded words of s.
+func Words(s string) []string {
+	return strings.Fields(Fold(s))
+}
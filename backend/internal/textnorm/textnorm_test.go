@@ -0,0 +1,121 @@
+package textnorm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		// Diacritics
+		{"acute", "Perú", "peru"},
+		{"circumflex", "Côte", "cote"},
+		{"umlaut", "Österreich", "osterreich"},
+		{"ring", "Åland", "aland"},
+		{"tilde", "São Tomé", "sao tome"},
+		{"cedilla", "Curaçao", "curacao"},
+		{"caron", "Česká republika", "ceska republika"},
+		{"ogonek", "Polska Rzeczpospolita Ludowa ąę", "polska rzeczpospolita ludowa ae"},
+		{"vietnamese stacked marks", "Việt Nam", "viet nam"},
+		{"decomposed input", "Côte", "cote"},
+		{"turkish dotted capital i", "İstanbul", "istanbul"},
+		{"turkish dotless i", "Kıbrıs", "kibris"},
+		{"okina", "Oʻzbekiston", "ozbekiston"},
+
+		// Letters without a decomposition
+		{"sharp s", "Großbritannien", "grossbritannien"},
+		{"capital sharp s", "GROẞ", "gross"},
+		{"ae ligature", "Færøerne", "faeroerne"},
+		{"oe ligature", "Œuvre", "oeuvre"},
+		{"stroke o", "Ø", "o"},
+		{"stroke l", "Łódź", "lodz"},
+		{"stroke d", "Đà Nẵng", "da nang"},
+		{"eth and thorn", "Ðór Þórsmörk", "dor thorsmork"},
+		{"compatibility ligature", "ﬁnland", "finland"},
+		{"fullwidth", "ＦＩ", "fi"},
+
+		// Case folding
+		{"upper", "FINLAND", "finland"},
+		{"mixed", "FiNlAnD", "finland"},
+		{"greek", "ΕΛΛΆΔΑ", "ελλαδα"},
+		{"cyrillic", "Россия", "россия"},
+
+		// Apostrophes and punctuation
+		{"ascii apostrophe", "Cote d'Ivoire", "cote divoire"},
+		{"typographic apostrophe", "Côte d’Ivoire", "cote divoire"},
+		{"backtick", "d`Ivoire", "divoire"},
+		{"hyphen", "Guinea-Bissau", "guinea bissau"},
+		{"comma", "Korea, Republic of", "korea republic of"},
+		{"ampersand", "Trinidad & Tobago", "trinidad tobago"},
+		{"dots", "U.S.A.", "u s a"},
+		{"parentheses", "Congo (Kinshasa)", "congo kinshasa"},
+		{"digits kept", "Route 66", "route 66"},
+
+		// Whitespace
+		{"leading and trailing", "  Finland\t", "finland"},
+		{"inner runs", "Saint   Lucia", "saint lucia"},
+		{"tabs and newlines", "Saint\t\nLucia", "saint lucia"},
+		{"no-break space", "Saint Lucia", "saint lucia"},
+		{"punctuation runs", "Bosnia -- & -- Herzegovina", "bosnia herzegovina"},
+		{"trailing punctuation", "Finland!!!", "finland"},
+
+		// Degenerate input
+		{"empty", "", ""},
+		{"only spaces", "   ", ""},
+		{"only punctuation", "-'.,", ""},
+		{"invalid utf-8", "Fi\xffnland", "fi nland"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fold(tt.in); got != tt.want {
+				t.Errorf("Fold(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if got := Fold(Fold(tt.in)); got != tt.want {
+				t.Errorf("Fold is not idempotent for %q: %q", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestFoldEquivalents(t *testing.T) {
+	// Each group folds to one key, as country name lookups rely on
+	groups := [][]string{
+		{"Côte d’Ivoire", "Cote d'Ivoire", "COTE DIVOIRE", "côte-d'ivoire"},
+		{"Türkiye", "Turkiye", "TÜRKIYE", "türkiye"},
+		{"São Tomé and Príncipe", "Sao Tome and Principe", "sao  tome and principe"},
+		{"Curaçao", "Curacao", "CURAÇAO"},
+		{"Oʻzbekiston", "O'zbekiston", "Ozbekiston"},
+	}
+	for _, group := range groups {
+		want := Fold(group[0])
+		for _, s := range group[1:] {
+			if got := Fold(s); got != want {
+				t.Errorf("Fold(%q) = %q, want %q like %q", s, got, want, group[0])
+			}
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Bosnia and Herzegovina", []string{"bosnia", "and", "herzegovina"}},
+		{"  Côte d’Ivoire ", []string{"cote", "divoire"}},
+		{"Guinea-Bissau", []string{"guinea", "bissau"}},
+		{"", nil},
+		{" - ", nil},
+	}
+	for _, tt := range tests {
+		got := Words(tt.in)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Package ttl expires the documents of ephemeral collections so they do not accumulate storage
+// costs. Each Policy names a timestamp field after which a document may be deleted. At startup
+// Apply enables a Firestore TTL policy on every field, which deletes expired documents within
+// about a day; a Purger on every instance deletes them too, as a fallback while a TTL policy is
+// being created or when it could not be enabled (e.g. missing permissions).
+//
+// A new ephemeral collection (undo tokens, idempotency keys, notifications, outbox entries)
+// sets its expiry field when writing a document and adds its Policy to Policies. Readers must
+// still treat expired documents as missing, since deletion is not immediate.
+package ttl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	admin "cloud.google.com/go/firestore/apiv1/admin"
+	"cloud.google.com/go/firestore/apiv1/admin/adminpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// purgeBatchSize is the most documents of one policy deleted per purge.
+const purgeBatchSize = 200
+
+// Policy expires the documents of a collection group once the timestamp in Field has passed.
+type Policy struct {
+	CollectionGroup string
+	Field           string
+}
+
+// Policies lists the ephemeral collections.
+var Policies = []Policy{
+	// Expired invitations cannot be accepted and only count against the pending limit
+	{CollectionGroup: "organization_invitations", Field: "ExpiresAt"},
+	// Expired friend invites no longer link the invitee
+	{CollectionGroup: "friend_invites", Field: "ExpiresAt"},
+	// Expired share links no longer open the share
+	{CollectionGroup: "share_links", Field: "ExpiresAt"},
+	// Audit events are kept for models.AuditRetention
+	{CollectionGroup: "audit", Field: "ExpiresAt"},
+}
+
+// Apply enables a Firestore TTL policy on the field of each policy in the default database of
+// projectID. Fields whose TTL policy is already active or being created are left alone. The
+// TTL policy is created in the background; Apply does not wait for it.
+func Apply(ctx context.Context, projectID string, policies []Policy) error {
+	ctx, span := tracing.New(ctx, "ttl.Apply")
+	defer span.End()
+
+	client, err := admin.NewFirestoreAdminClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create firestore admin client: %w", err)
+	}
+	defer client.Close()
+	for _, p := range policies {
+		name := fmt.Sprintf("projects/%s/databases/(default)/collectionGroups/%s/fields/%s",
+			projectID, p.CollectionGroup, p.Field)
+		field, err := client.GetField(ctx, &adminpb.GetFieldRequest{Name: name})
+		if err != nil {
+			return fmt.Errorf("failed to get field %s.%s: %w", p.CollectionGroup, p.Field, err)
+		}
+		switch field.GetTtlConfig().GetState() {
+		case adminpb.Field_TtlConfig_ACTIVE, adminpb.Field_TtlConfig_CREATING:
+			continue
+		}
+		_, err = client.UpdateField(ctx, &adminpb.UpdateFieldRequest{
+			Field:      &adminpb.Field{Name: name, TtlConfig: &adminpb.Field_TtlConfig{}},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"ttl_config"}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to enable TTL on %s.%s: %w", p.CollectionGroup, p.Field, err)
+		}
+		logging.FromContext(ctx).Info("Enabling TTL policy",
+			"collection_group", p.CollectionGroup, "field", p.Field)
+	}
+	return nil
+}
+
+// Store deletes expired documents. Implemented by database.Client.
+type Store interface {
+	DeleteExpiredDocuments(
+		ctx context.Context,
+		collectionGroup, field string,
+		now time.Time,
+		limit int,
+	) (int, error)
+}
+
+// Purger deletes expired documents of Policies periodically.
+type Purger struct {
+	store    Store
+	policies []Policy
+	interval time.Duration
+}
+
+// NewPurger returns a Purger that Run calls Purge on every interval.
+func NewPurger(store Store, policies []Policy, interval time.Duration) *Purger {
+	return &Purger{store: store, policies: policies, interval: interval}
+}
+
+// Purge deletes up to purgeBatchSize expired documents per policy and returns how many it
+// deleted; the rest wait for the next call. A failing policy does not stop the others.
+func (p *Purger) Purge(ctx context.Context) (int, error) {
+	ctx, span := tracing.New(ctx, "ttl.Purge")
+	defer span.End()
+
+	now := time.Now().UTC()
+	total := 0
+	var firstErr error
+	for _, policy := range p.policies {
+		n, err := p.store.DeleteExpiredDocuments(ctx, policy.CollectionGroup, policy.Field, now,
+			purgeBatchSize)
+		total += n
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to purge %s: %w", policy.CollectionGroup, err)
+		}
+	}
+	return total, firstErr
+}
+
+// Run calls Purge every interval until ctx is cancelled. Failures are logged.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := p.Purge(ctx)
+			if err != nil {
+				logging.FromContext(ctx).Error("TTL purge failed", logging.Error, err)
+			}
+			if n > 0 {
+				logging.FromContext(ctx).Info("Purged expired documents", logging.Count, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package widget renders the embeddable share widget: a self-contained HTML page (inline SVG
+// world map and styles, no scripts or external resources) showing the visited countries, their
+// count and the user's name, for iframes on personal sites.
+package widget
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/matti777/my-countries/backend/internal/data/geometry"
+)
+
+const (
+	// maxLatitude and minLatitude bound the map; Antarctica is left out. The SVG user space is
+	// one unit per degree.
+	maxLatitude = 84.0
+	minLatitude = -58.0
+)
+
+// Data is the content of the widget.
+type Data struct {
+	UserName       string
+	CountriesCount int
+	CountryCodes   []string
+
+	// ShareURL is the share page the widget links to.
+	ShareURL string
+}
+
+var page = template.Must(template.New("widget").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{.UserName}}: {{.Countries}}</title>
+<style>
+body{margin:0;background:#102a43;color:#fff;font:14px/1.4 system-ui,sans-serif}
+svg{display:block;width:100%;height:auto}
+.land{fill:#3d5a78}.visited{fill:#f2b134}
+p{margin:8px 12px}a{color:#c8d6e5;text-decoration:none}
+</style>
+</head>
+<body>
+<svg viewBox="0 0 360 {{.Height}}" role="img" aria-label="{{.Countries}}">
+<path class="land" d="{{.Land}}"/>
+<path class="visited" d="{{.Visited}}"/>
+</svg>
+<p><strong>{{.Countries}}</strong> ·
+<a href="{{.ShareURL}}" target="_blank" rel="noopener">{{.UserName}}</a></p>
+</body>
+</html>
+`))
+
+// Render writes the widget page of d to w.
+func Render(w io.Writer, d Data) error {
+	features, err := geometry.All(geometry.Low)
+	if err != nil {
+		return err
+	}
+	visited := make(map[string]bool, len(d.CountryCodes))
+	for _, code := range d.CountryCodes {
+		visited[strings.ToUpper(code)] = true
+	}
+	codes := make([]string, 0, len(features))
+	for code := range features {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	var land, visits strings.Builder
+	for _, code := range codes {
+		b := &land
+		if visited[code] {
+			b = &visits
+		}
+		for _, ring := range features[code].Rings {
+			writeRing(b, ring)
+		}
+	}
+	countries := fmt.Sprintf("%d countries visited", d.CountriesCount)
+	if d.CountriesCount == 1 {
+		countries = "1 country visited"
+	}
+	return page.Execute(w, map[string]interface{}{
+		"UserName":  d.UserName,
+		"Countries": countries,
+		"ShareURL":  d.ShareURL,
+		"Height":    maxLatitude - minLatitude,
+		"Land":      land.String(),
+		"Visited":   visits.String(),
+	})
+}
+
+// writeRing appends a [longitude, latitude] ring to b as SVG path data in an equirectangular
+// projection.
+func writeRing(b *strings.Builder, ring [][2]float64) {
+	for i, p := range ring {
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+		}
+		lat := min(max(p[1], minLatitude), maxLatitude)
+		fmt.Fprintf(b, "%s%.1f %.1f", cmd, p[0]+180, maxLatitude-lat)
+	}
+	b.WriteString("Z")
+}
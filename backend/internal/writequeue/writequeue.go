@@ -0,0 +1,159 @@
+// Package writequeue smooths bursts of Firestore writes from batch paths such as visit imports.
+// All writes pass one token bucket per instance, so a large import cannot exhaust the write
+// quota shared with request traffic. Batches too large to finish within a request run as
+// background jobs whose progress clients poll.
+package writequeue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+
+	"github.com/matti777/my-countries/backend/internal/logging"
+	"github.com/matti777/my-countries/backend/internal/models"
+	"github.com/matti777/my-countries/backend/internal/tracing"
+)
+
+// retainFinished is how long a finished job can still be polled.
+const retainFinished = time.Hour
+
+// ErrJobRunning is returned by Submit when the user already has a running job.
+var ErrJobRunning = errors.New("a write job is already running")
+
+// WriteFunc performs write i (0 <= i < total) of a job.
+type WriteFunc func(ctx context.Context, i int) error
+
+// Queue throttles batch writes and tracks the background jobs of this instance.
+type Queue struct {
+	limiter *rate.Limiter
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+type job struct {
+	userID string
+	state  models.WriteJob
+}
+
+// New returns a Queue allowing writesPerSecond batch writes per second on average (must be
+// positive), with bursts of up to one second's worth.
+func New(writesPerSecond float64) *Queue {
+	return &Queue{
+		limiter: rate.NewLimiter(rate.Limit(writesPerSecond), max(1, int(writesPerSecond))),
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Wait blocks until the next batch write may proceed or ctx is done.
+func (q *Queue) Wait(ctx context.Context) error {
+	return q.limiter.Wait(ctx)
+}
+
+// Submit starts a background job of total throttled writes for userID and returns its state,
+// or ErrJobRunning while another job of the user runs.
+// fn runs with a context detached from ctx's cancellation but keeping its values (logger,
+// dry run), since the job outlives the request. The job stops at the first failing write.
+func (q *Queue) Submit(
+	ctx context.Context,
+	userID, kind string,
+	total int,
+	fn WriteFunc,
+) (models.WriteJob, error) {
+	now := time.Now().UTC()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, j := range q.jobs {
+		if j.state.CompletedAt != nil && now.Sub(*j.state.CompletedAt) > retainFinished {
+			delete(q.jobs, id)
+			continue
+		}
+		if j.userID == userID && j.state.Status == models.WriteJobRunning {
+			return models.WriteJob{}, ErrJobRunning
+		}
+	}
+	j := &job{
+		userID: userID,
+		state: models.WriteJob{
+			ID:        uuid.New().String(),
+			Kind:      kind,
+			Status:    models.WriteJobRunning,
+			Total:     total,
+			CreatedAt: now,
+		},
+	}
+	q.jobs[j.state.ID] = j
+	go q.run(context.WithoutCancel(ctx), j, fn)
+	return j.state, nil
+}
+
+// Job returns the state of userID's job id; false when unknown, expired or another user's.
+func (q *Queue) Job(userID, id string) (models.WriteJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok || j.userID != userID {
+		return models.WriteJob{}, false
+	}
+	return j.state, true
+}
+
+// Pending returns the number of running jobs and their writes not yet done.
+func (q *Queue) Pending() (jobs, writes int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		if j.state.Status == models.WriteJobRunning {
+			jobs++
+			writes += j.state.Total - j.state.Written
+		}
+	}
+	return jobs, writes
+}
+
+func (q *Queue) run(ctx context.Context, j *job, fn WriteFunc) {
+	ctx, span := tracing.New(ctx, "writequeue."+j.state.Kind)
+	defer span.End()
+
+	log := logging.FromContext(ctx).WithParams(logging.WriteJob, j.state.ID)
+	ctx = logging.WithContext(ctx, log)
+
+	err := q.write(ctx, j, fn)
+	completed := time.Now().UTC()
+	q.mu.Lock()
+	j.state.CompletedAt = &completed
+	if err != nil {
+		j.state.Status = models.WriteJobFailed
+		j.state.Error = err.Error()
+	} else {
+		j.state.Status = models.WriteJobCompleted
+	}
+	written := j.state.Written
+	q.mu.Unlock()
+
+	if err != nil {
+		log.Error("Write job failed", logging.Count, written, logging.Error, err)
+		return
+	}
+	log.Info("Write job completed", logging.Count, written)
+}
+
+func (q *Queue) write(ctx context.Context, j *job, fn WriteFunc) error {
+	for i := range j.state.Total {
+		if err := q.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := fn(ctx, i); err != nil {
+			return fmt.Errorf("write %d failed: %w", i, err)
+		}
+		q.mu.Lock()
+		j.state.Written++
+		q.mu.Unlock()
+	}
+	return nil
+}
@@ -1,8 +1,34 @@
 import { errorToast } from "Components/toast";
 import type { Country, CountriesResponse } from "./types/country";
-import type { Friend, FriendsResponse } from "./types/friend";
+import type {
+  Friend,
+  FriendCompareResponse,
+  FriendRequest,
+  FriendRequestsResponse,
+  FriendsResponse,
+  FriendVisitsResponse,
+  MutualFriendsResponse,
+} from "./types/friend";
+import type { Insights } from "./types/insights";
+import type {
+  HandleResponse,
+  ShareLink,
+  ShareLinksResponse,
+  ShareScope,
+  ShareStats,
+  ShareStatsResponse,
+} from "./types/share";
 import type { CountryVisit, ShareProfileResponse, VisitsResponse } from "./types/visit";
-import type { UserSettings } from "./types/settings";
+import type {
+  AccountMergeResult,
+  AccountSettings,
+  ApiKey,
+  ApiKeysResponse,
+  AuditEvent,
+  AuditEventsResponse,
+  CreatedApiKey,
+  UserSettings,
+} from "./types/settings";
 
 
 const COUNTRIES_CACHE_KEY = "app:countries:cache";
@@ -35,17 +61,21 @@ export class ApiError extends Error {
   cause?: any;
   /** Field-level validation errors from 400 ValidationErrors responses. */
   fields?: Record<string, string>;
+  /** X-Request-ID of the failed request, for matching a reported error to backend logs. */
+  requestId?: string;
 
   constructor({
     message,
     responseCode,
     cause,
     fields,
+    requestId,
   }: {
     message: string;
     responseCode?: number;
     cause?: any;
     fields?: Record<string, string>;
+    requestId?: string;
   }) {
     super();
 
@@ -53,6 +83,7 @@ export class ApiError extends Error {
     this.responseCode = responseCode;
     this.cause = cause;
     this.fields = fields;
+    this.requestId = requestId;
   }
 }
 
@@ -92,7 +123,10 @@ export default class Api {
         }
       }
       localStorage.removeItem(COUNTRIES_CACHE_KEY);
-      const response = await this.performRequest("/countries", { method: "GET" }) as CountriesResponse;
+      const response = await this.performRequest("/countries", {
+        method: "GET",
+        headers: this.countriesHeaders(),
+      }) as CountriesResponse;
       const countries = response?.countries ?? [];
       const cache: CountriesCache = { countries, cachedAt: Date.now() };
       localStorage.setItem(COUNTRIES_CACHE_KEY, JSON.stringify(cache));
@@ -104,6 +138,19 @@ export default class Api {
     }
   }
 
+  /**
+   * Headers for the /countries routes: the ID token when signed in (skips the anonymous quota),
+   * otherwise the static app token (VITE_APP_TOKEN) when the backend requires one.
+   */
+  private countriesHeaders(): Record<string, string> {
+    const token = this.getAuthToken();
+    if (token) {
+      return { Authorization: `Bearer ${token}` };
+    }
+    const appToken = import.meta.env.VITE_APP_TOKEN;
+    return appToken ? { "X-App-Token": appToken } : {};
+  }
+
   async postLogin(): Promise<void> {
     const token = this.getAuthToken();
     if (!token) {
@@ -115,6 +162,74 @@ export default class Api {
     });
   }
 
+  /** Exchanges the ID token for an HttpOnly session cookie (when the backend enables them). */
+  async createSession(): Promise<void> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    await this.performRequest("/session", {
+      method: "POST",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+  }
+
+  /** Signs out everywhere; the current sign-in is revoked too, so sign out afterwards. */
+  async revokeSessions(): Promise<void> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    await this.performRequest("/me/revoke-sessions", {
+      method: "POST",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+  }
+
+  /**
+   * Moves the visits of the guest account (anonymous sign-in) with anonymousIdToken to the
+   * signed-in user and deletes the guest account. Returns the number of visits moved.
+   */
+  async upgradeGuest(anonymousIdToken: string): Promise<number> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest("/me/upgrade", {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ anonymousIdToken }),
+    })) as { movedVisits: number };
+    return response.movedVisits;
+  }
+
+  /**
+   * Merges the user's other account (idToken from a fresh sign-in to it) into the signed-in
+   * one, which is kept.
+   */
+  async mergeAccount(idToken: string): Promise<AccountMergeResult> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    return (await this.performRequest("/me/merge", {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ idToken }),
+    })) as AccountMergeResult;
+  }
+
+  /** Clears the session cookie on sign-out. */
+  async deleteSession(): Promise<void> {
+    await this.performRequest("/session", { method: "DELETE" });
+  }
+
   async getVisits(): Promise<{ visits: CountryVisit[]; shareToken?: string }> {
     const token = this.getAuthToken();
     if (!token) {
@@ -142,6 +257,35 @@ export default class Api {
     return response;
   }
 
+  async getAccountSettings(): Promise<AccountSettings> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest("/me/settings", {
+      method: "GET",
+      headers: { Authorization: `Bearer ${token}` },
+    })) as AccountSettings;
+    return response;
+  }
+
+  /** Turns all share views off (or back on) without changing the share token or links. */
+  async setSharingDisabled(sharingDisabled: boolean): Promise<AccountSettings> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest("/me/settings", {
+      method: "PATCH",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ sharingDisabled }),
+    })) as AccountSettings;
+    return response;
+  }
+
   async updateSettings(settings: UserSettings): Promise<UserSettings> {
     const token = this.getAuthToken();
     if (!token) {
@@ -158,22 +302,95 @@ export default class Api {
     return response;
   }
 
+  /** All friends of the current user, following GET /friends pages. */
   async getFriends(): Promise<{ friends: Friend[] }> {
     const token = this.getAuthToken();
     if (!token) {
       return { friends: [] };
     }
-    const response = (await this.performRequest("/friends", {
+    const friends: Friend[] = [];
+    let cursor: string | undefined;
+    do {
+      const query = cursor ? `?cursor=${encodeURIComponent(cursor)}` : "";
+      const response = (await this.performRequest(`/friends${query}`, {
+        method: "GET",
+        headers: { Authorization: `Bearer ${token}` },
+      })) as FriendsResponse;
+      friends.push(...(response?.friends ?? []));
+      cursor = response?.nextCursor;
+    } while (cursor);
+    return { friends };
+  }
+
+  /** Travel trends of the current user, regenerated weekly by the backend. */
+  async getInsights(): Promise<Insights> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest("/insights", {
       method: "GET",
       headers: { Authorization: `Bearer ${token}` },
-    })) as FriendsResponse;
-    return { friends: response?.friends ?? [] };
+    })) as Insights;
+    return response;
   }
 
-  async getShareProfile(shareToken: string): Promise<ShareProfileResponse> {
+  /** A friend's visits and summary counts; the share token must be in the user's friends. */
+  async getFriendVisits(shareToken: string): Promise<FriendVisitsResponse> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest(
+      `/friends/${encodeURIComponent(shareToken)}/visits`,
+      { method: "GET", headers: { Authorization: `Bearer ${token}` } }
+    )) as FriendVisitsResponse;
+    return response;
+  }
+
+  /** The current user's friends whom the friend has added too. */
+  async getMutualFriends(shareToken: string): Promise<MutualFriendsResponse> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest(
+      `/friends/${encodeURIComponent(shareToken)}/mutual`,
+      { method: "GET", headers: { Authorization: `Bearer ${token}` } }
+    )) as MutualFriendsResponse;
+    return response;
+  }
+
+  /** Countries visited by both, only the current user and only the friend. */
+  async compareWithFriend(shareToken: string): Promise<FriendCompareResponse> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest(
+      `/friends/${encodeURIComponent(shareToken)}/compare`,
+      { method: "GET", headers: { Authorization: `Bearer ${token}` } }
+    )) as FriendCompareResponse;
+    return response;
+  }
+
+  /** Signed-in viewers send their token so owners who blocked them are honored (404). */
+  /**
+   * turnstileToken is a solved Turnstile challenge, sent after a 429 response carrying a
+   * `siteKey` to continue past the share rate limit.
+   */
+  async getShareProfile(
+    shareToken: string,
+    turnstileToken?: string
+  ): Promise<ShareProfileResponse> {
+    const token = this.getAuthToken();
+    const headers: Record<string, string> = token ? { Authorization: `Bearer ${token}` } : {};
+    if (turnstileToken) {
+      headers["X-Turnstile-Token"] = turnstileToken;
+    }
     const response = (await this.performRequest(
       `/share/profile/${encodeURIComponent(shareToken)}`,
-      { method: "GET" }
+      { method: "GET", headers }
     )) as ShareProfileResponse;
     return response;
   }
@@ -268,22 +485,262 @@ export default class Api {
     });
   }
 
-  async postFriend(shareToken: string, name: string, imageUrl?: string): Promise<Friend> {
+  async getFriendRequests(): Promise<FriendRequestsResponse> {
+    const token = this.getAuthToken();
+    if (!token) {
+      return { incoming: [], outgoing: [] };
+    }
+    const response = (await this.performRequest("/friends/requests", {
+      method: "GET",
+      headers: { Authorization: `Bearer ${token}` },
+    })) as FriendRequestsResponse;
+    return { incoming: response?.incoming ?? [], outgoing: response?.outgoing ?? [] };
+  }
+
+  /** Replaces the user's share token; the old share link stops working. */
+  async rotateShareToken(
+    removeFromFriends = false
+  ): Promise<{ shareToken: string; removedFromFriends: number }> {
     const token = this.getAuthToken();
     if (!token) {
       throw new ApiError({ message: "Not authenticated" });
     }
-    const body: { shareToken: string; name: string; imageUrl?: string } = { shareToken, name };
-    if (imageUrl != null && imageUrl !== "") {
-      body.imageUrl = imageUrl;
+    const response = (await this.performRequest("/me/share-token/rotate", {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ removeFromFriends }),
+    })) as { shareToken: string; removedFromFriends: number };
+    return response;
+  }
+
+  async getShareLinks(): Promise<ShareLink[]> {
+    const token = this.getAuthToken();
+    if (!token) {
+      return [];
+    }
+    const response = (await this.performRequest("/me/share-links", {
+      method: "GET",
+      headers: { Authorization: `Bearer ${token}` },
+    })) as ShareLinksResponse;
+    return response?.shareLinks ?? [];
+  }
+
+  /** Creates a share link working until expiresAt, or maxViews share views when given. */
+  async createShareLink(
+    expiresAt: string,
+    scope: ShareScope = "full",
+    maxViews?: number
+  ): Promise<ShareLink> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
     }
-    const response = (await this.performRequest("/friends", {
+    const response = (await this.performRequest("/me/share-links", {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
         Authorization: `Bearer ${token}`,
       },
-      body: JSON.stringify(body),
+      body: JSON.stringify({ expiresAt, scope, maxViews }),
+    })) as ShareLink;
+    return response;
+  }
+
+  /** View counts of the share token and share links. */
+  async getShareStats(): Promise<ShareStats[]> {
+    const token = this.getAuthToken();
+    if (!token) {
+      return [];
+    }
+    const response = (await this.performRequest("/me/share-stats", {
+      method: "GET",
+      headers: { Authorization: `Bearer ${token}` },
+    })) as ShareStatsResponse;
+    return response?.shareStats ?? [];
+  }
+
+  async deleteShareLink(linkToken: string): Promise<void> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    await this.performRequest(`/me/share-links/${encodeURIComponent(linkToken)}`, {
+      method: "DELETE",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+  }
+
+  async getApiKeys(): Promise<ApiKey[]> {
+    const token = this.getAuthToken();
+    if (!token) {
+      return [];
+    }
+    const response = (await this.performRequest("/me/api-keys", {
+      method: "GET",
+      headers: { Authorization: `Bearer ${token}` },
+    })) as ApiKeysResponse;
+    return response?.apiKeys ?? [];
+  }
+
+  /** Creates an API key; show the returned key to the user now, it cannot be fetched again. */
+  async createApiKey(name: string): Promise<CreatedApiKey> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    return (await this.performRequest("/me/api-keys", {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ name }),
+    })) as CreatedApiKey;
+  }
+
+  async deleteApiKey(id: string): Promise<void> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    await this.performRequest(`/me/api-keys/${encodeURIComponent(id)}`, {
+      method: "DELETE",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+  }
+
+  /** The user's most recent audit events (logins, rejected tokens, ...), newest first. */
+  async getAuditEvents(limit?: number): Promise<AuditEvent[]> {
+    const token = this.getAuthToken();
+    if (!token) {
+      return [];
+    }
+    const query = limit ? `?limit=${limit}` : "";
+    const response = (await this.performRequest(`/me/audit${query}`, {
+      method: "GET",
+      headers: { Authorization: `Bearer ${token}` },
+    })) as AuditEventsResponse;
+    return response?.events ?? [];
+  }
+
+  /** The user's vanity handle (share view at /u/<handle>), or undefined when none. */
+  async getHandle(): Promise<string | undefined> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest("/me/handle", {
+      method: "GET",
+      headers: { Authorization: `Bearer ${token}` },
+    })) as HandleResponse;
+    return response?.handle;
+  }
+
+  /** Claims a vanity handle, releasing the previous one; 409 when taken. */
+  async setHandle(handle: string): Promise<string | undefined> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest("/me/handle", {
+      method: "PUT",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ handle }),
+    })) as HandleResponse;
+    return response?.handle;
+  }
+
+  async deleteHandle(): Promise<void> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    await this.performRequest("/me/handle", {
+      method: "DELETE",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+  }
+
+  /** Blocks the owner of shareToken; removes the friendship and pending friend requests. */
+  async blockUser(shareToken: string): Promise<void> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    await this.performRequest("/blocked", {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ shareToken }),
+    });
+  }
+
+  /** Sends a friend request to the owner of shareToken; they become friends once accepted. */
+  async sendFriendRequest(shareToken: string): Promise<FriendRequest> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest("/friends/requests", {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ shareToken }),
+    })) as FriendRequest;
+    return response;
+  }
+
+  /** Accepts an incoming friend request; returns the sender as the new friend. */
+  async acceptFriendRequest(requestId: string): Promise<Friend> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest(
+      `/friends/requests/${encodeURIComponent(requestId)}/accept`,
+      {
+        method: "POST",
+        headers: { Authorization: `Bearer ${token}` },
+      },
+    )) as Friend;
+    return response;
+  }
+
+  /** Declines an incoming friend request or cancels an outgoing one. */
+  async declineFriendRequest(requestId: string): Promise<void> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    await this.performRequest(`/friends/requests/${encodeURIComponent(requestId)}/decline`, {
+      method: "POST",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+  }
+
+  /** Sets the nickname of a friend; an empty nickname clears it. */
+  async updateFriendNickname(shareToken: string, nickname: string): Promise<Friend> {
+    const token = this.getAuthToken();
+    if (!token) {
+      throw new ApiError({ message: "Not authenticated" });
+    }
+    const response = (await this.performRequest(`/friends/${encodeURIComponent(shareToken)}`, {
+      method: "PATCH",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ nickname }),
     })) as Friend;
     return response;
   }
@@ -329,14 +786,19 @@ export default class Api {
     if (response.status < 200 || response.status >= 400) {
       let message = `Invalid status ${response.status}: ${response.statusText}`;
       let fields: Record<string, string> | undefined;
+      let requestId = response.headers.get("X-Request-ID") ?? undefined;
       try {
         const body = (await response.json()) as {
           error?: string;
           fields?: Record<string, string>;
+          requestId?: string;
         };
         if (body?.error) {
           message = body.error;
         }
+        if (body?.requestId) {
+          requestId = body.requestId;
+        }
         if (body?.fields && typeof body.fields === "object") {
           fields = body.fields;
         }
@@ -347,11 +809,12 @@ export default class Api {
         message,
         responseCode: response.status,
         fields,
+        requestId,
       });
       const isFieldValidation =
         response.status === 400 && fields && Object.keys(fields).length > 0;
       if (response.status !== 401 && !isFieldValidation) {
-        errorToast(apiError.message);
+        errorToast(requestId ? `${apiError.message} (request ID ${requestId})` : apiError.message);
       }
       throw apiError;
     }
@@ -20,7 +20,7 @@ import {
   createVisitListEditFloat,
   updateVisitListEditFloat,
 } from "Components/visit-list-edit-float";
-import { confirmDialog, openModal } from "Components/modal";
+import { confirmDialog, openModal, promptDialog } from "Components/modal";
 import {
   auth,
   completeRedirectSignIn,
@@ -30,7 +30,7 @@ import {
 } from "./firebase";
 import { api, ApiError } from "./api";
 import type { Country } from "./types/country";
-import type { Friend } from "./types/friend";
+import type { Friend, FriendRequestsResponse } from "./types/friend";
 import type { CountryVisit } from "./types/visit";
 import type firebase from "firebase/compat/app";
 import firebaseApp from "firebase/compat/app";
@@ -49,6 +49,7 @@ let shareToken: string | null = null;
 
 /** Friends list from GET /friends, filled when user is authenticated (on load and on login). */
 let friends: Friend[] = [];
+let friendRequests: FriendRequestsResponse = { incoming: [], outgoing: [] };
 
 /** Shared visit list when URL path is /share/<token>. */
 let sharedVisits: CountryVisit[] = [];
@@ -750,8 +751,13 @@ export interface RenderOptions {
   onVisitListTabChange: (tab: "alphabetical" | "byContinent" | "map" | "timeline" | "statistics") => void;
   onLogin: () => void;
   friends: Friend[];
+  friendRequests: FriendRequestsResponse;
   onAddFriend: () => void;
   onDeleteFriend: (shareToken: string) => void;
+  onRenameFriend: (shareToken: string, nickname: string) => void;
+  onAcceptFriendRequest: (requestId: string) => void;
+  onDeclineFriendRequest: (requestId: string) => void;
+  onBlockUser: (shareToken: string, name: string) => void;
   onViewMediaUrl?: (visit: CountryVisit) => void;
   onCountryVisitEditorSubmit: (payload: CountryVisitEditorSubmitPayload) => Promise<void>;
 }
@@ -954,7 +960,10 @@ function fillVisitListContent(params: FillVisitListContentParams): void {
   } = params;
 
   if (visitListTab === "map") {
-    const uniqueCodes = [...new Set(displayList.map((v) => v.countryCode))];
+    // Former countries (e.g. SU) are drawn as their successor states
+    const uniqueCodes = [
+      ...new Set(displayList.flatMap((v) => v.successorCodes ?? [v.countryCode])),
+    ];
     void import("Components/visit-list-map-shell")
       .then(({ createVisitListMapShell }) => {
         if (!contentArea.isConnected) return;
@@ -1350,28 +1359,43 @@ function renderSharedVisitSection(container: HTMLElement, options: RenderOptions
 
 function renderAddFriendSection(container: HTMLElement, options: RenderOptions): void {
   const currentShareToken = getShareTokenFromPath();
-  const { sharedUserName: name, friends: friendsList, onAddFriend } = options;
+  const {
+    sharedUserName: name,
+    friends: friendsList,
+    friendRequests: requests,
+    onAddFriend,
+    onAcceptFriendRequest,
+  } = options;
   if (!currentShareToken || name == null) return;
   const isAlreadyFriend = friendsList.some((f) => f.shareToken === currentShareToken);
+  const outgoing = requests.outgoing.find((r) => r.toShareToken === currentShareToken);
+  const incoming = requests.incoming.find((r) => r.fromShareToken === currentShareToken);
   const section = document.createElement("section");
   section.className = "app-section add-friend-section";
-  if (isAlreadyFriend) {
+  if (isAlreadyFriend || outgoing) {
     const text = document.createElement("p");
     text.className = "add-friend-section__text";
-    text.textContent = `${name} is in your friend list.`;
+    text.textContent = isAlreadyFriend
+      ? `${name} is in your friend list.`
+      : `Friend request sent to ${name}.`;
     section.appendChild(text);
   } else {
     const box = document.createElement("div");
     box.className = "add-friend-section__box";
     const text = document.createElement("p");
     text.className = "add-friend-section__text";
-    text.textContent = `Would you like to add ${name} to your friends list?`;
+    text.textContent = incoming
+      ? `${name} sent you a friend request.`
+      : `Would you like to send ${name} a friend request?`;
     box.appendChild(text);
     const btn = document.createElement("button");
     btn.type = "button";
     btn.className = "add-friend-section__btn";
-    btn.textContent = "Add friend";
-    btn.addEventListener("click", () => onAddFriend());
+    btn.textContent = incoming ? "Accept" : "Send friend request";
+    btn.addEventListener("click", () => {
+      if (incoming) onAcceptFriendRequest(incoming.id);
+      else onAddFriend();
+    });
     box.appendChild(btn);
     section.appendChild(box);
   }
@@ -1609,10 +1633,68 @@ function renderAppContent(container: HTMLElement, options: RenderOptions): void
   renderFriendsListSection(container, options);
 }
 
+function renderFriendRequestsList(section: HTMLElement, options: RenderOptions): void {
+  const {
+    friendRequests: requests,
+    onAcceptFriendRequest,
+    onDeclineFriendRequest,
+    onBlockUser,
+  } = options;
+  if (requests.incoming.length === 0) return;
+  const title = document.createElement("h2");
+  title.className = "friends-section__title";
+  title.textContent = "Friend requests";
+  section.appendChild(title);
+  const list = document.createElement("div");
+  list.className = "friends-list";
+  for (const request of requests.incoming) {
+    const cell = document.createElement("div");
+    cell.className = "friend-cell";
+    const info = document.createElement("div");
+    info.className = "friend-cell__link-area";
+    if (request.fromImageUrl) {
+      const img = document.createElement("img");
+      img.src = request.fromImageUrl;
+      img.alt = "";
+      img.className = "friend-cell__avatar";
+      info.appendChild(img);
+    }
+    const nameEl = document.createElement("span");
+    nameEl.className = "friend-cell__name";
+    nameEl.textContent = request.fromName;
+    info.appendChild(nameEl);
+    cell.appendChild(info);
+    const acceptBtn = document.createElement("button");
+    acceptBtn.type = "button";
+    acceptBtn.className = "add-friend-section__btn";
+    acceptBtn.textContent = "Accept";
+    acceptBtn.addEventListener("click", () => onAcceptFriendRequest(request.id));
+    cell.appendChild(acceptBtn);
+    const declineBtn = document.createElement("button");
+    declineBtn.type = "button";
+    declineBtn.className = "friend-cell__delete";
+    declineBtn.textContent = "✕";
+    declineBtn.setAttribute("aria-label", "Decline friend request");
+    declineBtn.addEventListener("click", () => onDeclineFriendRequest(request.id));
+    attachTooltip(declineBtn, `Click to decline the friend request from ${request.fromName}`);
+    cell.appendChild(declineBtn);
+    const blockBtn = document.createElement("button");
+    blockBtn.type = "button";
+    blockBtn.className = "friend-cell__block";
+    blockBtn.textContent = "Block";
+    blockBtn.addEventListener("click", () => onBlockUser(request.fromShareToken, request.fromName));
+    attachTooltip(blockBtn, `Click to block ${request.fromName} from seeing your profile`);
+    cell.appendChild(blockBtn);
+    list.appendChild(cell);
+  }
+  section.appendChild(list);
+}
+
 function renderFriendsListSection(container: HTMLElement, options: RenderOptions): void {
-  const { friends: friendsList, onDeleteFriend } = options;
+  const { friends: friendsList, onDeleteFriend, onRenameFriend } = options;
   const section = document.createElement("section");
   section.className = "app-section friends-section";
+  renderFriendRequestsList(section, options);
   const title = document.createElement("h2");
   title.className = "friends-section__title";
   title.textContent = "Friends";
@@ -1626,6 +1708,7 @@ function renderFriendsListSection(container: HTMLElement, options: RenderOptions
     list.appendChild(empty);
   } else {
     for (const friend of friendsList) {
+      const displayName = friend.nickname || friend.name;
       const cell = document.createElement("div");
       cell.className = "friend-cell";
       const linkArea = document.createElement("div");
@@ -1639,7 +1722,7 @@ function renderFriendsListSection(container: HTMLElement, options: RenderOptions
       }
       const nameEl = document.createElement("span");
       nameEl.className = "friend-cell__name";
-      nameEl.textContent = friend.name;
+      nameEl.textContent = displayName;
       linkArea.appendChild(nameEl);
       const viewLink = document.createElement("span");
       viewLink.className = "friend-cell__view";
@@ -1653,8 +1736,29 @@ function renderFriendsListSection(container: HTMLElement, options: RenderOptions
         );
         window.dispatchEvent(new PopStateEvent("popstate"));
       });
-      attachTooltip(linkArea, `Click to view country visits by ${friend.name}`);
+      attachTooltip(linkArea, `Click to view country visits by ${displayName}`);
       cell.appendChild(linkArea);
+      const editBtn = document.createElement("button");
+      editBtn.type = "button";
+      editBtn.className = "friend-cell__edit";
+      editBtn.textContent = "✎";
+      editBtn.setAttribute("aria-label", "Rename friend");
+      editBtn.addEventListener("click", (e) => {
+        e.stopPropagation();
+        void (async () => {
+          const nickname = await promptDialog({
+            title: "Rename friend",
+            message: `Nickname for ${friend.name} (leave empty to use their own name)`,
+            value: friend.nickname ?? "",
+            placeholder: friend.name,
+            maxLength: 50,
+          });
+          if (nickname == null || nickname === (friend.nickname ?? "")) return;
+          onRenameFriend(friend.shareToken, nickname);
+        })();
+      });
+      attachTooltip(editBtn, `Click to rename ${displayName}`);
+      cell.appendChild(editBtn);
       const deleteBtn = document.createElement("button");
       deleteBtn.type = "button";
       deleteBtn.className = "friend-cell__delete";
@@ -1665,7 +1769,7 @@ function renderFriendsListSection(container: HTMLElement, options: RenderOptions
         void (async () => {
           const ok = await confirmDialog({
             title: "Confirm removal",
-            message: `Are you sure you want to remove friend ${friend.name}?`,
+            message: `Are you sure you want to remove friend ${displayName}?`,
             danger: true,
             confirmText: "Yes, remove",
             cancelText: "No",
@@ -1674,7 +1778,7 @@ function renderFriendsListSection(container: HTMLElement, options: RenderOptions
           onDeleteFriend(friend.shareToken);
         })();
       });
-      attachTooltip(deleteBtn, `Click to remove ${friend.name} as friend`);
+      attachTooltip(deleteBtn, `Click to remove ${displayName} as friend`);
       cell.appendChild(deleteBtn);
       list.appendChild(cell);
     }
@@ -1963,23 +2067,58 @@ export async function main(): Promise<void> {
       },
       onLogin,
       friends,
+      friendRequests,
       onAddFriend: async () => {
         const token = getShareTokenFromPath();
         if (!token || sharedUserName == null) return;
         try {
-          await api.postFriend(token, sharedUserName, sharedUserImageUrl ?? undefined);
-          logAnalyticsEvent("add_friend", { share_token: token });
-          friends = [
-            ...friends,
-            { shareToken: token, name: sharedUserName, imageUrl: sharedUserImageUrl ?? undefined },
-          ];
+          const request = await api.sendFriendRequest(token);
+          logAnalyticsEvent("send_friend_request", { share_token: token });
+          friendRequests = { ...friendRequests, outgoing: [...friendRequests.outgoing, request] };
           refreshAppContent();
         } catch (err) {
           if (err instanceof ApiError && err.responseCode === 401) {
             signOut();
             errorToast("Session expired");
           } else {
-            errorToast(err instanceof Error ? err.message : "Failed to add friend");
+            errorToast(err instanceof Error ? err.message : "Failed to send friend request");
+          }
+        }
+      },
+      onAcceptFriendRequest: async (requestId: string) => {
+        try {
+          const friend = await api.acceptFriendRequest(requestId);
+          logAnalyticsEvent("accept_friend_request", { share_token: friend.shareToken });
+          friends = [...friends, friend];
+          friendRequests = {
+            ...friendRequests,
+            incoming: friendRequests.incoming.filter((r) => r.id !== requestId),
+          };
+          refreshAppContent();
+        } catch (err) {
+          if (err instanceof ApiError && err.responseCode === 401) {
+            signOut();
+            errorToast("Session expired");
+          } else {
+            errorToast(err instanceof Error ? err.message : "Failed to accept friend request");
+          }
+        }
+      },
+      onDeclineFriendRequest: async (requestId: string) => {
+        try {
+          await api.declineFriendRequest(requestId);
+          logAnalyticsEvent("decline_friend_request");
+          friendRequests = {
+            ...friendRequests,
+            incoming: friendRequests.incoming.filter((r) => r.id !== requestId),
+          };
+          refreshAppContent();
+        } catch (err) {
+          if (err instanceof ApiError && err.responseCode === 401) {
+            signOut();
+            errorToast("Session expired");
+          } else {
+            errorToast(err instanceof Error ? err.message : "Failed to decline friend request");
           }
         }
       },
@@ -1998,6 +2137,48 @@ export async function main(): Promise<void> {
           }
         }
       },
+      onBlockUser: async (shareTokenToBlock: string, name: string) => {
+        const ok = await confirmDialog({
+          title: "Confirm block",
+          message: `Block ${name}? They can no longer see your profile or send you friend requests.`,
+          danger: true,
+          confirmText: "Yes, block",
+          cancelText: "No",
+        });
+        if (!ok) return;
+        try {
+          await api.blockUser(shareTokenToBlock);
+          logAnalyticsEvent("block_user");
+          friends = friends.filter((f) => f.shareToken !== shareTokenToBlock);
+          friendRequests = {
+            incoming: friendRequests.incoming.filter((r) => r.fromShareToken !== shareTokenToBlock),
+            outgoing: friendRequests.outgoing.filter((r) => r.toShareToken !== shareTokenToBlock),
+          };
+          refreshAppContent();
+        } catch (err) {
+          if (err instanceof ApiError && err.responseCode === 401) {
+            signOut();
+            errorToast("Session expired");
+          } else {
+            errorToast(err instanceof Error ? err.message : "Failed to block user");
+          }
+        }
+      },
+      onRenameFriend: async (shareTokenToRename: string, nickname: string) => {
+        try {
+          const updated = await api.updateFriendNickname(shareTokenToRename, nickname);
+          logAnalyticsEvent("rename_friend", { share_token: shareTokenToRename });
+          friends = friends.map((f) => (f.shareToken === shareTokenToRename ? updated : f));
+          refreshAppContent();
+        } catch (err) {
+          if (err instanceof ApiError && err.responseCode === 401) {
+            signOut();
+            errorToast("Session expired");
+          } else {
+            errorToast(err instanceof Error ? err.message : "Failed to rename friend");
+          }
+        }
+      },
       onViewMediaUrl: (visit) => {
         const d = parseVisitDateToYMD(visit.visitedTime);
         logAnalyticsEvent("view_media_url", {
@@ -2128,7 +2309,11 @@ export async function main(): Promise<void> {
         }
         if (!getShareTokenFromPath()) {
           console.log("[auth] loadUserData: start");
-          const [visitsSettled, friendsSettled] = await Promise.allSettled([api.getVisits(), api.getFriends()]);
+          const [visitsSettled, friendsSettled, requestsSettled] = await Promise.allSettled([
+            api.getVisits(),
+            api.getFriends(),
+            api.getFriendRequests(),
+          ]);
           if (visitsSettled.status === "fulfilled") {
             visits = visitsSettled.value.visits;
             shareToken = visitsSettled.value.shareToken ?? null;
@@ -2149,6 +2334,12 @@ export async function main(): Promise<void> {
             friends = [];
             console.error("Failed to load friends", friendsSettled.reason);
           }
+          if (requestsSettled.status === "fulfilled") {
+            friendRequests = requestsSettled.value;
+          } else {
+            friendRequests = { incoming: [], outgoing: [] };
+            console.error("Failed to load friend requests", requestsSettled.reason);
+          }
           console.log("[auth] loadUserData: done");
         }
       } else {
@@ -2156,6 +2347,7 @@ export async function main(): Promise<void> {
         visits = [];
         shareToken = null;
         friends = [];
+        friendRequests = { incoming: [], outgoing: [] };
       }
       const showHome = !!getShareTokenFromPath() || isOwnProfilePath();
       renderAuthHeader(authHeaderEl, user, onLogin, onLogout, showHome, navigateHome, openProfile);
@@ -56,8 +56,9 @@ export function buildCountryVisitInfoTooltipHtml(
   const tags = visit.tags ?? [];
   const hasTags = tags.length > 0;
   const hasMedia = Boolean(visit.mediaUrl);
+  const overlapCount = visit.overlaps?.length ?? 0;
 
-  if (!hasNotes && !hasTags && !hasMedia) {
+  if (!hasNotes && !hasTags && !hasMedia && overlapCount === 0) {
     return null;
   }
 
@@ -82,6 +83,13 @@ export function buildCountryVisitInfoTooltipHtml(
     );
   }
 
+  if (overlapCount > 0) {
+    const friends = overlapCount === 1 ? "1 friend" : `${overlapCount} friends`;
+    parts.push(
+      `<p class="country-visit-info-tooltip__overlap-hint">${friends} also there</p>`,
+    );
+  }
+
   if (hasMedia) {
     parts.push(
       `<p class="country-visit-info-tooltip__media-hint">Click to view attached media</p>`,
@@ -182,3 +182,73 @@ export async function confirmDialog(options: {
   });
 }
 
+/** Asks for a line of text; resolves to the trimmed value, or null when cancelled. */
+export async function promptDialog(options: {
+  title?: string;
+  message: string;
+  value?: string;
+  placeholder?: string;
+  maxLength?: number;
+  confirmText?: string;
+  cancelText?: string;
+}): Promise<string | null> {
+  return await new Promise<string | null>((resolve) => {
+    const body = document.createElement("div");
+    body.className = "app-confirm";
+    const p = document.createElement("p");
+    p.className = "app-confirm__message";
+    p.textContent = options.message;
+    body.appendChild(p);
+    const input = document.createElement("input");
+    input.type = "text";
+    input.className = "app-confirm__input";
+    input.value = options.value ?? "";
+    if (options.placeholder) input.placeholder = options.placeholder;
+    if (options.maxLength) input.maxLength = options.maxLength;
+    body.appendChild(input);
+
+    const footer = document.createElement("div");
+    footer.className = "app-confirm__actions";
+
+    const cancelBtn = document.createElement("button");
+    cancelBtn.type = "button";
+    cancelBtn.className = "app-confirm__btn app-confirm__btn--secondary";
+    cancelBtn.textContent = options.cancelText ?? "Cancel";
+
+    const okBtn = document.createElement("button");
+    okBtn.type = "button";
+    okBtn.className = "app-confirm__btn app-confirm__btn--primary";
+    okBtn.textContent = options.confirmText ?? "Save";
+
+    footer.appendChild(cancelBtn);
+    footer.appendChild(okBtn);
+
+    const { close, overlay } = openModal({
+      title: options.title,
+      ariaLabel: options.message,
+      body,
+      footer,
+      closeOnOutsideClick: true,
+      showCloseButton: false,
+      onClose: () => resolve(null),
+    });
+
+    const resolveAndClose = (val: string | null): void => {
+      resolve(val);
+      close("programmatic");
+    };
+
+    cancelBtn.addEventListener("click", () => resolveAndClose(null));
+    okBtn.addEventListener("click", () => resolveAndClose(input.value.trim()));
+    input.addEventListener("keydown", (e) => {
+      if (e.key === "Enter") resolveAndClose(input.value.trim());
+    });
+
+    requestAnimationFrame(() => {
+      input.focus();
+      input.select();
+    });
+
+    overlay.addEventListener("app-modal:close", () => resolve(null), { once: true });
+  });
+}
@@ -44,8 +44,9 @@ function buildPutBody(
   instagramUserName: string,
   description: string,
   sharing: UserSettings["sharing"],
+  hideFromFollowers: boolean,
 ): UserSettings {
-  const settings: UserSettings = { sharing };
+  const settings: UserSettings = { sharing, hideFromFollowers };
   const home = homeCountryCode.trim();
   if (home) {
     settings.homeCountryCode = home;
@@ -198,6 +199,19 @@ export function openUserSettingsDialog(
   tagsLabel.appendChild(tagsText);
   body.appendChild(tagsLabel);
 
+  const followersLabel = document.createElement("label");
+  followersLabel.className = "user-settings-dialog__row";
+  const followersCb = document.createElement("input");
+  followersCb.type = "checkbox";
+  followersCb.className = "user-settings-dialog__checkbox";
+  followersCb.disabled = true;
+  const followersText = document.createElement("span");
+  followersText.className = "user-settings-dialog__label-text";
+  followersText.textContent = 'Hide me from the "who added me" lists of my friends';
+  followersLabel.appendChild(followersCb);
+  followersLabel.appendChild(followersText);
+  body.appendChild(followersLabel);
+
   const saveRow = document.createElement("div");
   saveRow.className = "user-settings-dialog__save-row";
   const saveBtn = document.createElement("button");
@@ -224,6 +238,7 @@ export function openUserSettingsDialog(
     mediaCb.disabled = !enabled;
     notesCb.disabled = !enabled;
     tagsCb.disabled = !enabled;
+    followersCb.disabled = !enabled;
     descInput.disabled = !enabled;
     igInput.disabled = !enabled;
     const homeInput = homeDropdown.element.querySelector(
@@ -275,6 +290,7 @@ export function openUserSettingsDialog(
         shareNotes: notesCb.checked,
         shareTags: tagsCb.checked,
       },
+      followersCb.checked,
     );
     try {
       const saved = await api.updateSettings(settings);
@@ -315,6 +331,7 @@ export function openUserSettingsDialog(
       mediaCb.checked = Boolean(settings?.sharing?.shareMediaUrl);
       notesCb.checked = Boolean(settings?.sharing?.shareNotes);
       tagsCb.checked = Boolean(settings?.sharing?.shareTags);
+      followersCb.checked = Boolean(settings.hideFromFollowers);
       setControlsEnabled(true);
     } catch (err) {
       console.error("Failed to load settings", err);
@@ -26,6 +26,11 @@ firebase.initializeApp(firebaseConfig);
 const analytics = firebase.analytics();
 export const auth = firebase.auth();
 
+// Local development against the Firebase Auth emulator (backend: FIREBASE_AUTH_EMULATOR_HOST).
+if (env.VITE_FIREBASE_AUTH_EMULATOR_HOST) {
+  auth.useEmulator(`http://${env.VITE_FIREBASE_AUTH_EMULATOR_HOST}`);
+}
+
 const LINK_SESSION_EMAIL_KEY = "link:email";
 const LINK_SESSION_PENDING_CRED_KEY = "link:pendingCredentialJSON";
 const LINK_SESSION_ATTEMPTED_PROVIDER_KEY = "link:attemptedProviderId";
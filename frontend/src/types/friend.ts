@@ -1,3 +1,5 @@
+import type { CountryVisit } from "./visit";
+
 /**
  * Types matching GET /friends API response (backend Friend model, data-models.md).
  */
@@ -5,8 +7,55 @@ export interface Friend {
   shareToken: string;
   name: string;
   imageUrl?: string;
+  /** Name chosen by the current user, shown instead of `name`. */
+  nickname?: string;
 }
 
+/** GET /friends page; `nextCursor` is set when more friends may follow. */
 export interface FriendsResponse {
   friends: Friend[];
+  nextCursor?: string;
+}
+
+/** GET /friends/:shareToken/visits response. */
+export interface FriendVisitsResponse {
+  friend: Friend;
+  visits: CountryVisit[];
+  summary: {
+    visitCount: number;
+    countryCount: number;
+    verifiedVisitCount: number;
+  };
+}
+
+/** GET /friends/:shareToken/mutual response; `mutual` is sorted by shown name. */
+export interface MutualFriendsResponse {
+  friend: Friend;
+  mutual: Friend[];
+}
+
+/** GET /friends/:shareToken/compare response; code lists are sorted. */
+export interface FriendCompareResponse {
+  friend: Friend;
+  both: string[];
+  onlyMine: string[];
+  onlyFriend: string[];
+}
+
+/** A pending friend request (backend FriendRequest model). */
+export interface FriendRequest {
+  id: string;
+  fromShareToken: string;
+  fromName: string;
+  fromImageUrl?: string;
+  toShareToken: string;
+  toName: string;
+  toImageUrl?: string;
+  createdAt: string;
+}
+
+/** GET /friends/requests response. */
+export interface FriendRequestsResponse {
+  incoming: FriendRequest[];
+  outgoing: FriendRequest[];
 }
@@ -0,0 +1,20 @@
+/** Period between two consecutive visits. */
+export interface TripGap {
+  from: string;
+  to: string;
+  days: number;
+}
+
+/** GET /insights response (backend Insights model, data-models.md). */
+export interface Insights {
+  generatedAt: string;
+  /** Visits in the 365 days before `generatedAt`. */
+  recentVisitCount: number;
+  /** Visits in the 365 days before those. */
+  previousVisitCount: number;
+  /** Change from previous to recent visit count in percent; null without previous visits. */
+  frequencyChangePercent: number | null;
+  /** Continent codes first visited in the recent period. */
+  newRegionCodes: string[];
+  longestGap: TripGap | null;
+}
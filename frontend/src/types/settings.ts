@@ -9,5 +9,66 @@ export interface UserSettings {
   instagramUserName?: string;
   description?: string;
   sharing: SharingSettings;
+  hideFromFollowers?: boolean;
 }
 
+
+/** GET/PATCH /me/settings: account-wide switches (backend AccountSettings). */
+export interface AccountSettings {
+  /** While true, the share token and share links open nothing. */
+  sharingDisabled: boolean;
+}
+
+/** Personal access token for scripts (backend APIKey model, data-models.md). */
+export interface ApiKey {
+  id: string;
+  name: string;
+  /** Start of the key, to tell keys apart. */
+  prefix: string;
+  createdAt: string;
+  lastUsedAt?: string;
+}
+
+export interface ApiKeysResponse {
+  apiKeys: ApiKey[];
+}
+
+/** POST /me/api-keys: the key itself is only returned here. */
+export interface CreatedApiKey extends ApiKey {
+  key: string;
+}
+
+/** POST /me/merge: what was moved from the merged account. */
+export interface AccountMergeResult {
+  visits: number;
+  friends: number;
+  followers: number;
+  blockedUsers: number;
+  shareLinks: number;
+  apiKeys: number;
+  /** Handle taken over from the merged account. */
+  handle?: string;
+}
+
+/** GET /me/audit: a security event on the user's account. */
+export interface AuditEvent {
+  id: string;
+  type:
+    | "login"
+    | "token_rejected"
+    | "share_token_rotated"
+    | "account_deletion_scheduled"
+    | "account_deletion_cancelled"
+    | "sessions_revoked"
+    | "api_key_created"
+    | "api_key_revoked"
+    | "accounts_merged";
+  time: string;
+  ip?: string;
+  userAgent?: string;
+  detail?: string;
+}
+
+export interface AuditEventsResponse {
+  events: AuditEvent[];
+}
@@ -0,0 +1,44 @@
+/** What a share link's profile shows: country codes, counts, or visits with dates and media. */
+export type ShareScope = "map" | "summary" | "full";
+
+/** Expiring share link (backend ShareLink model, data-models.md). */
+export interface ShareLink {
+  token: string;
+  createdAt: string;
+  expiresAt: string;
+  scope: ShareScope;
+  /** Share views allowed; absent for unlimited. */
+  maxViews?: number;
+  views: number;
+}
+
+/** GET /me/share-links response. */
+export interface ShareLinksResponse {
+  shareLinks: ShareLink[];
+}
+
+/** Views of one share token on a UTC day. */
+export interface ShareStatsDay {
+  date: string;
+  views: number;
+}
+
+/** View counts of a share token (backend ShareStats model, data-models.md). */
+export interface ShareStats {
+  token: string;
+  kind: "shareToken" | "shareLink";
+  views: number;
+  lastViewedAt?: string;
+  /** Days with views among the last 30, oldest first. */
+  daily: ShareStatsDay[];
+}
+
+/** GET /me/share-stats response. */
+export interface ShareStatsResponse {
+  shareStats: ShareStats[];
+}
+
+/** GET/PUT /me/handle response; handle is absent when the user has none. */
+export interface HandleResponse {
+  handle?: string;
+}
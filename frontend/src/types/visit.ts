@@ -1,3 +1,5 @@
+import type { ShareScope } from "./share";
+
 /**
  * Types matching GET /visits API response (backend CountryVisit, data-models.md).
  */
@@ -8,9 +10,24 @@ export interface CountryVisit {
   mediaUrl?: string;
   notes?: string;
   tags?: string[];
+  /** True when the visit has proof attached (boarding pass, stamp). */
+  verified?: boolean;
+  /** Current countries drawn on maps in place of a former country (e.g. SU). */
+  successorCodes?: string[];
+  /** Mutual friends' visits linked as "I was there too" (own visits only). */
+  overlaps?: VisitOverlap[];
   userId: string;
 }
 
+/** Link between an own visit and a mutual friend's visit of the same trip. */
+export interface VisitOverlap {
+  id: string;
+  visitId: string;
+  friendShareToken: string;
+  friendVisitId: string;
+  createdAt: string;
+}
+
 export interface VisitsResponse {
   visits: CountryVisit[];
   shareToken?: string;
@@ -24,5 +41,12 @@ export interface ShareProfileResponse {
   homeCountryCode?: string;
   instagramUserName?: string;
   description?: string;
+  verifiedVisitCount?: number;
+  /** "full" for the share token; share links may return only counts or country codes. */
+  scope?: ShareScope;
+  visitCount?: number;
+  countriesCount?: number;
+  /** Distinct visited country codes; absent for "summary" share links. */
+  countryCodes?: string[];
 }
 
@@ -3,7 +3,13 @@ import { defineConfig } from "vite";
 import { resolve } from "path";
 import { viteStaticCopy } from "vite-plugin-static-copy";
 
-/** Serve index.html for client routes /share/<token> and /profile (not API /share/profile/...). */
+/** Backend API paths under /share/<token>/ (everything else there is a client route). */
+const SHARE_API_PATH = /^\/share\/[^/]+\/passport$/;
+
+/**
+ * Serve index.html for client routes /share/<token> and /profile
+ * (not API /share/profile/..., /share/org/..., /share/og/..., /share/widget/... or /share/<token>/passport).
+ */
 function spaShareRoutesMiddleware(): Connect.NextHandleFunction {
   return (req, _res, next) => {
     const raw = req.url ?? "";
@@ -11,7 +17,11 @@ function spaShareRoutesMiddleware(): Connect.NextHandleFunction {
     if (
       req.method === "GET" &&
       ((pathOnly.startsWith("/share/") &&
-        !pathOnly.startsWith("/share/profile/")) ||
+        !pathOnly.startsWith("/share/profile/") &&
+        !pathOnly.startsWith("/share/org/") &&
+        !pathOnly.startsWith("/share/og/") &&
+        !pathOnly.startsWith("/share/widget/") &&
+        !SHARE_API_PATH.test(pathOnly)) ||
         pathOnly === "/profile" ||
         pathOnly === "/profile/")
     ) {
@@ -52,10 +62,31 @@ export default defineConfig({
     proxy: {
       "/countries": { target: "http://localhost:8080", changeOrigin: true },
       "/login": { target: "http://localhost:8080", changeOrigin: true },
+      "/session": { target: "http://localhost:8080", changeOrigin: true },
       "/share/profile": { target: "http://localhost:8080", changeOrigin: true },
+      "/share/org": { target: "http://localhost:8080", changeOrigin: true },
+      "/share/og": { target: "http://localhost:8080", changeOrigin: true },
+      "/share/widget": { target: "http://localhost:8080", changeOrigin: true },
+      "^/share/[^/]+/passport": { target: "http://localhost:8080", changeOrigin: true },
+      "^/u/": { target: "http://localhost:8080", changeOrigin: true },
       "/visits": { target: "http://localhost:8080", changeOrigin: true },
       "/friends": { target: "http://localhost:8080", changeOrigin: true },
+      "/feed": { target: "http://localhost:8080", changeOrigin: true },
+      "/insights": { target: "http://localhost:8080", changeOrigin: true },
       "/settings": { target: "http://localhost:8080", changeOrigin: true },
+      "/orgs": { target: "http://localhost:8080", changeOrigin: true },
+      "/account": { target: "http://localhost:8080", changeOrigin: true },
+      "/me": { target: "http://localhost:8080", changeOrigin: true },
+      "/blocked": { target: "http://localhost:8080", changeOrigin: true },
+      "/support": { target: "http://localhost:8080", changeOrigin: true },
+      "/api": { target: "http://localhost:8080", changeOrigin: true },
+      "/img": { target: "http://localhost:8080", changeOrigin: true },
+      "/admin": { target: "http://localhost:8080", changeOrigin: true },
+      "/static/manifest.json": { target: "http://localhost:8080", changeOrigin: true },
+      "/healthz": { target: "http://localhost:8080", changeOrigin: true },
+      "/readyz": { target: "http://localhost:8080", changeOrigin: true },
+      "/openapi.json": { target: "http://localhost:8080", changeOrigin: true },
+      "/docs": { target: "http://localhost:8080", changeOrigin: true },
     },
   },
   build: {